@@ -0,0 +1,87 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predict_test
+
+import (
+	"context"
+	"fmt"
+
+	aiplatform "cloud.google.com/go/aiplatform/apiv1"
+	"cloud.google.com/go/aiplatform/predict"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+func ExamplePredict() {
+	ctx := context.Background()
+	// The endpoint of the client is in the location of the Vertex AI
+	// endpoint.
+	client, err := aiplatform.NewPredictionClient(ctx, option.WithEndpoint("us-central1-aiplatform.googleapis.com:443"))
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	type Instance struct {
+		Text string `json:"text"`
+	}
+	type Prediction struct {
+		Label string  `json:"label"`
+		Score float64 `json:"score"`
+	}
+	res, err := predict.Predict[Instance, Prediction](ctx, client,
+		"projects/my-project/locations/us-central1/endpoints/1234",
+		[]Instance{{Text: "a great movie"}, {Text: "a boring movie"}},
+		map[string]any{"threshold": 0.5})
+	if err != nil {
+		// TODO: Handle error.
+	}
+	for _, p := range res.Predictions {
+		fmt.Println(p.Label, p.Score)
+	}
+}
+
+func ExampleStreamRaw() {
+	ctx := context.Background()
+	client, err := aiplatform.NewPredictionClient(ctx, option.WithEndpoint("us-central1-aiplatform.googleapis.com:443"))
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	type Request struct {
+		Prompt    string `json:"prompt"`
+		MaxTokens int    `json:"max_tokens"`
+	}
+	type Chunk struct {
+		Text string `json:"text"`
+	}
+	s, err := predict.StreamRaw[Chunk](ctx, client,
+		"projects/my-project/locations/us-central1/endpoints/1234",
+		Request{Prompt: "Tell me a story", MaxTokens: 256})
+	if err != nil {
+		// TODO: Handle error.
+	}
+	for {
+		c, err := s.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			// TODO: Handle error.
+		}
+		fmt.Print(c.Text)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package predict calls Vertex AI endpoints with Go values, using the
+// PredictionClient in cloud.google.com/go/aiplatform/apiv1.
+//
+// Instances, parameters and predictions are converted to and from
+// structpb.Values through their JSON encoding, so struct tags of
+// encoding/json apply:
+//
+//	type Instance struct {
+//		Text string `json:"text"`
+//	}
+//	type Prediction struct {
+//		Label string  `json:"label"`
+//		Score float64 `json:"score"`
+//	}
+//
+//	res, err := predict.Predict[Instance, Prediction](ctx, client, endpoint, []Instance{{Text: "great movie"}}, nil)
+//
+// Raw and StreamRaw send any JSON request to a custom container, and decode
+// its JSON responses.
+package predict // import "cloud.google.com/go/aiplatform/predict"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	aiplatform "cloud.google.com/go/aiplatform/apiv1"
+	"cloud.google.com/go/aiplatform/apiv1/aiplatformpb"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// predictionClient is implemented by *aiplatform.PredictionClient.
+type predictionClient interface {
+	Predict(ctx context.Context, req *aiplatformpb.PredictRequest, opts ...gax.CallOption) (*aiplatformpb.PredictResponse, error)
+	RawPredict(ctx context.Context, req *aiplatformpb.RawPredictRequest, opts ...gax.CallOption) (*httpbody.HttpBody, error)
+	StreamRawPredict(ctx context.Context, req *aiplatformpb.StreamRawPredictRequest, opts ...gax.CallOption) (aiplatformpb.PredictionService_StreamRawPredictClient, error)
+}
+
+// ToValue returns the structpb.Value of the JSON encoding of v.
+func ToValue(v any) (*structpb.Value, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	pv := &structpb.Value{}
+	if err := protojson.Unmarshal(b, pv); err != nil {
+		return nil, err
+	}
+	return pv, nil
+}
+
+// FromValue decodes a structpb.Value into the value pointed to by dst, as
+// json.Unmarshal decodes its JSON encoding.
+func FromValue(v *structpb.Value, dst any) error {
+	b, err := protojson.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+// A Response is the response to a Predict request.
+type Response[P any] struct {
+	// Predictions are the predictions, one per instance, in order.
+	Predictions []P
+
+	// Proto is the response, whose predictions are those decoded.
+	Proto *aiplatformpb.PredictResponse
+}
+
+// Predict sends instances, and params if it is not nil, to an endpoint, of
+// the form projects/{project}/locations/{location}/endpoints/{endpoint},
+// and decodes the predictions of the response into values of type P.
+func Predict[I, P any](ctx context.Context, client *aiplatform.PredictionClient, endpoint string, instances []I, params any, opts ...gax.CallOption) (*Response[P], error) {
+	return predict[I, P](ctx, client, endpoint, instances, params, opts...)
+}
+
+func predict[I, P any](ctx context.Context, client predictionClient, endpoint string, instances []I, params any, opts ...gax.CallOption) (*Response[P], error) {
+	req := &aiplatformpb.PredictRequest{Endpoint: endpoint}
+	for i, inst := range instances {
+		v, err := ToValue(inst)
+		if err != nil {
+			return nil, fmt.Errorf("predict: instance %d: %w", i, err)
+		}
+		req.Instances = append(req.Instances, v)
+	}
+	if params != nil {
+		v, err := ToValue(params)
+		if err != nil {
+			return nil, fmt.Errorf("predict: parameters: %w", err)
+		}
+		req.Parameters = v
+	}
+	res, err := client.Predict(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	preds := make([]P, len(res.Predictions))
+	for i, v := range res.Predictions {
+		if err := FromValue(v, &preds[i]); err != nil {
+			return nil, fmt.Errorf("predict: prediction %d: %w", i, err)
+		}
+	}
+	return &Response[P]{Predictions: preds, Proto: res}, nil
+}
+
+// Raw sends the JSON encoding of req to an endpoint with RawPredict, and
+// decodes the JSON response into a value of type R. The request is sent as
+// is to the container of the deployed model.
+func Raw[R any](ctx context.Context, client *aiplatform.PredictionClient, endpoint string, req any, opts ...gax.CallOption) (R, error) {
+	return raw[R](ctx, client, endpoint, req, opts...)
+}
+
+func raw[R any](ctx context.Context, client predictionClient, endpoint string, req any, opts ...gax.CallOption) (R, error) {
+	var r R
+	body, err := jsonBody(req)
+	if err != nil {
+		return r, err
+	}
+	res, err := client.RawPredict(ctx, &aiplatformpb.RawPredictRequest{Endpoint: endpoint, HttpBody: body}, opts...)
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(res.Data, &r); err != nil {
+		return r, fmt.Errorf("predict: decoding response of type %q: %w", res.ContentType, err)
+	}
+	return r, nil
+}
+
+func jsonBody(req any) (*httpbody.HttpBody, error) {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("predict: encoding request: %w", err)
+	}
+	return &httpbody.HttpBody{ContentType: "application/json", Data: b}, nil
+}
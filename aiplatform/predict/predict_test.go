@@ -0,0 +1,179 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predict
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/aiplatform/apiv1/aiplatformpb"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/iterator"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type instance struct {
+	Text string `json:"text"`
+	N    int    `json:"n,omitempty"`
+}
+
+type prediction struct {
+	Label string  `json:"label"`
+	Score float64 `json:"score"`
+}
+
+// fakeClient predicts the label of each instance as its text in upper case,
+// and answers raw requests with their body, split into chunks for streams.
+type fakeClient struct {
+	req    *aiplatformpb.PredictRequest
+	chunks []*httpbody.HttpBody
+}
+
+func (f *fakeClient) Predict(ctx context.Context, req *aiplatformpb.PredictRequest, _ ...gax.CallOption) (*aiplatformpb.PredictResponse, error) {
+	f.req = req
+	res := &aiplatformpb.PredictResponse{DeployedModelId: "m"}
+	for _, inst := range req.Instances {
+		text := inst.GetStructValue().Fields["text"].GetStringValue()
+		res.Predictions = append(res.Predictions, structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+			"label": structpb.NewStringValue(strings.ToUpper(text)),
+			"score": structpb.NewNumberValue(0.5),
+			"extra": structpb.NewBoolValue(true),
+		}}))
+	}
+	return res, nil
+}
+
+func (f *fakeClient) RawPredict(ctx context.Context, req *aiplatformpb.RawPredictRequest, _ ...gax.CallOption) (*httpbody.HttpBody, error) {
+	return req.HttpBody, nil
+}
+
+func (f *fakeClient) StreamRawPredict(ctx context.Context, req *aiplatformpb.StreamRawPredictRequest, _ ...gax.CallOption) (aiplatformpb.PredictionService_StreamRawPredictClient, error) {
+	return &fakeStream{chunks: f.chunks}, nil
+}
+
+type fakeStream struct {
+	grpc.ClientStream
+	chunks []*httpbody.HttpBody
+}
+
+func (s *fakeStream) Recv() (*httpbody.HttpBody, error) {
+	if len(s.chunks) == 0 {
+		return nil, io.EOF
+	}
+	c := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return c, nil
+}
+
+func TestPredict(t *testing.T) {
+	f := &fakeClient{}
+	res, err := predict[instance, prediction](context.Background(), f, "e", []instance{{Text: "a", N: 1}, {Text: "b"}}, map[string]int{"top_k": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []prediction{{"A", 0.5}, {"B", 0.5}}
+	if fmt.Sprint(res.Predictions) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", res.Predictions, want)
+	}
+	if res.Proto.DeployedModelId != "m" {
+		t.Errorf("got deployed model %q, want m", res.Proto.DeployedModelId)
+	}
+	wantInst, _ := structpb.NewValue(map[string]any{"text": "a", "n": 1})
+	if !proto.Equal(f.req.Instances[0], wantInst) {
+		t.Errorf("got instance %v, want %v", f.req.Instances[0], wantInst)
+	}
+	if got := f.req.Parameters.GetStructValue().Fields["top_k"].GetNumberValue(); got != 3 {
+		t.Errorf("got top_k %v, want 3", got)
+	}
+
+	if _, err := predict[any, prediction](context.Background(), f, "e", []any{make(chan int)}, nil); err == nil {
+		t.Error("got nil, want error for an instance that can't be encoded")
+	}
+}
+
+func TestValues(t *testing.T) {
+	v, err := ToValue(instance{Text: "x", N: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got instance
+	if err := FromValue(v, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != (instance{Text: "x", N: 2}) {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestRaw(t *testing.T) {
+	got, err := raw[instance](context.Background(), &fakeClient{}, "e", instance{Text: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Text != "x" {
+		t.Errorf("got %+v", got)
+	}
+	if _, err := raw[int](context.Background(), &fakeClient{}, "e", instance{}); err == nil {
+		t.Error("got nil, want error decoding an object into an int")
+	}
+}
+
+func TestStreamRaw(t *testing.T) {
+	for _, test := range []struct {
+		contentType string
+		body        string
+	}{
+		{"application/json", `{"label":"a"}` + "\n" + `{"label":"b"}` + "\n"},
+		{"application/json", `{"label":"a"}{"label":"b"}`},
+		{"text/event-stream; charset=utf-8", "data: {\"label\":\"a\"}\n\n: comment\n\ndata: {\"label\":\ndata: \"b\"}\n\ndata: [DONE]\n\n"},
+		{"text/event-stream", "data: {\"label\":\"a\"}\r\n\r\ndata: {\"label\":\"b\"}"},
+	} {
+		// Split the body into chunks of 5 bytes.
+		var chunks []*httpbody.HttpBody
+		for b := []byte(test.body); len(b) > 0; {
+			n := 5
+			if n > len(b) {
+				n = len(b)
+			}
+			chunks = append(chunks, &httpbody.HttpBody{ContentType: test.contentType, Data: b[:n]})
+			b = b[n:]
+		}
+		s, err := streamRaw[prediction](context.Background(), &fakeClient{chunks: chunks}, "e", json.RawMessage(`{}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var labels []string
+		for {
+			p, err := s.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%q: %v", test.body, err)
+			}
+			labels = append(labels, p.Label)
+		}
+		if got := strings.Join(labels, ","); got != "a,b" {
+			t.Errorf("%q: got labels %q, want a,b", test.body, got)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predict
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+
+	aiplatform "cloud.google.com/go/aiplatform/apiv1"
+	"cloud.google.com/go/aiplatform/apiv1/aiplatformpb"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/iterator"
+)
+
+// A Stream is a stream of responses of a custom container, decoded into
+// values of type R.
+type Stream[R any] struct {
+	chunks *chunkReader
+	dec    *json.Decoder // for a sequence of JSON values
+	events *bufio.Reader // for server-sent events
+	err    error
+}
+
+// StreamRaw sends the JSON encoding of req to an endpoint with
+// StreamRawPredict, and returns the stream of responses of the container.
+// The container responds with a sequence of JSON values, such as
+// newline-delimited JSON, or with server-sent events, of content type
+// text/event-stream, whose data are JSON values.
+func StreamRaw[R any](ctx context.Context, client *aiplatform.PredictionClient, endpoint string, req any, opts ...gax.CallOption) (*Stream[R], error) {
+	return streamRaw[R](ctx, client, endpoint, req, opts...)
+}
+
+func streamRaw[R any](ctx context.Context, client predictionClient, endpoint string, req any, opts ...gax.CallOption) (*Stream[R], error) {
+	body, err := jsonBody(req)
+	if err != nil {
+		return nil, err
+	}
+	s, err := client.StreamRawPredict(ctx, &aiplatformpb.StreamRawPredictRequest{Endpoint: endpoint, HttpBody: body}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream[R]{chunks: &chunkReader{stream: s}}, nil
+}
+
+// Next returns the next response. It returns iterator.Done at the end of the
+// stream.
+func (s *Stream[R]) Next() (R, error) {
+	var r R
+	if s.err != nil {
+		return r, s.err
+	}
+	if s.dec == nil && s.events == nil {
+		contentType, err := s.chunks.contentType()
+		if err != nil {
+			s.err = err
+			return r, err
+		}
+		if mt, _, _ := mime.ParseMediaType(contentType); mt == "text/event-stream" {
+			s.events = bufio.NewReader(s.chunks)
+		} else {
+			s.dec = json.NewDecoder(s.chunks)
+		}
+	}
+	if s.events != nil {
+		s.err = s.nextEvent(&r)
+	} else {
+		s.err = s.dec.Decode(&r)
+		if s.err == io.EOF {
+			s.err = iterator.Done
+		}
+	}
+	return r, s.err
+}
+
+// nextEvent decodes the data of the next server-sent event with data.
+func (s *Stream[R]) nextEvent(r *R) error {
+	var data []byte
+	for {
+		line, err := s.events.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		line = bytes.TrimRight(line, "\r\n")
+		if d, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			if len(data) > 0 {
+				data = append(data, '\n')
+			}
+			data = append(data, bytes.TrimPrefix(d, []byte(" "))...)
+		}
+		// An event ends with an empty line, or the end of the stream.
+		if len(line) == 0 || err == io.EOF {
+			if len(data) > 0 && string(data) != "[DONE]" {
+				if err := json.Unmarshal(data, r); err != nil {
+					return fmt.Errorf("predict: decoding event: %w", err)
+				}
+				return nil
+			}
+			data = data[:0]
+			if err == io.EOF {
+				return iterator.Done
+			}
+		}
+	}
+}
+
+// chunkReader reads the data of the HttpBody messages of a stream.
+type chunkReader struct {
+	stream aiplatformpb.PredictionService_StreamRawPredictClient
+	buf    []byte
+	ctype  string
+	first  bool // the first message was received
+}
+
+// contentType returns the content type of the first message.
+func (c *chunkReader) contentType() (string, error) {
+	if !c.first {
+		if err := c.recv(); err != nil && err != io.EOF {
+			return "", err
+		}
+	}
+	return c.ctype, nil
+}
+
+func (c *chunkReader) recv() error {
+	m, err := c.stream.Recv()
+	if err != nil {
+		return err
+	}
+	if !c.first {
+		c.first = true
+		c.ctype = m.ContentType
+	}
+	c.buf = m.Data
+	return nil
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for len(c.buf) == 0 {
+		if err := c.recv(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2024 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bigtable
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	btpb "google.golang.org/genproto/googleapis/bigtable/v2"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrBatcherClosed is returned by MutationBatcher.Add after the batcher is
+// closed.
+var ErrBatcherClosed = errors.New("bigtable: MutationBatcher is closed")
+
+// MutationBatcherConfig configures a MutationBatcher. Zero values are replaced
+// by the defaults given for each field.
+type MutationBatcherConfig struct {
+	// CountThreshold is the number of mutations that triggers the sending of
+	// a batch. The default is 100.
+	CountThreshold int
+
+	// ByteThreshold is the encoded size, in bytes, of the mutations that
+	// triggers the sending of a batch. The default is 20 MiB.
+	ByteThreshold int
+
+	// DelayThreshold is the time after which a batch is sent even if it has
+	// not reached the other thresholds. The default is one second.
+	DelayThreshold time.Duration
+
+	// MaxInFlightRequests is the maximum number of ApplyBulk calls made
+	// concurrently. The default is 10.
+	MaxInFlightRequests int
+
+	// MaxOutstandingBytes is the maximum encoded size, in bytes, of the
+	// mutations added and not yet completed. Add blocks when it would be
+	// exceeded. The default is 100 MiB.
+	MaxOutstandingBytes int
+}
+
+var defaultMutationBatcherConfig = MutationBatcherConfig{
+	CountThreshold:      100,
+	ByteThreshold:       20 << 20,
+	DelayThreshold:      time.Second,
+	MaxInFlightRequests: 10,
+	MaxOutstandingBytes: 100 << 20,
+}
+
+// A MutationBatcher coalesces mutations added by any number of goroutines
+// into ApplyBulk calls that are made in the background. Create one with
+// Table.NewMutationBatcher.
+type MutationBatcher struct {
+	t    *Table
+	ctx  context.Context
+	cfg  MutationBatcherConfig
+	opts []ApplyOption
+	sem  chan struct{} // limits the requests in flight
+
+	mu       sync.Mutex
+	pending  *mutationBatch
+	inFlight map[*mutationBatch]bool
+	// outstanding is the size of the mutations added and not completed.
+	outstanding int
+	// released is closed and replaced whenever outstanding decreases.
+	released chan struct{}
+	timer    *time.Timer
+	closed   bool
+}
+
+type batchedMutation struct {
+	rowKey   string
+	mut      *Mutation
+	callback func(error)
+}
+
+type mutationBatch struct {
+	muts []batchedMutation
+	size int
+	done chan struct{}
+}
+
+// NewMutationBatcher returns a MutationBatcher that applies mutations to the
+// table with ApplyBulk calls made with ctx and opts. Close must be called to
+// send the last mutations and release the resources of the batcher.
+func (t *Table) NewMutationBatcher(ctx context.Context, cfg MutationBatcherConfig, opts ...ApplyOption) *MutationBatcher {
+	d := defaultMutationBatcherConfig
+	if cfg.CountThreshold <= 0 {
+		cfg.CountThreshold = d.CountThreshold
+	}
+	if cfg.ByteThreshold <= 0 {
+		cfg.ByteThreshold = d.ByteThreshold
+	}
+	if cfg.DelayThreshold <= 0 {
+		cfg.DelayThreshold = d.DelayThreshold
+	}
+	if cfg.MaxInFlightRequests <= 0 {
+		cfg.MaxInFlightRequests = d.MaxInFlightRequests
+	}
+	if cfg.MaxOutstandingBytes <= 0 {
+		cfg.MaxOutstandingBytes = d.MaxOutstandingBytes
+	}
+	return &MutationBatcher{
+		t:        t,
+		ctx:      ctx,
+		cfg:      cfg,
+		opts:     opts,
+		sem:      make(chan struct{}, cfg.MaxInFlightRequests),
+		inFlight: map[*mutationBatch]bool{},
+		released: make(chan struct{}),
+	}
+}
+
+// Add adds a mutation of the row with the given key to the current batch.
+// callback, if not nil, is called from another goroutine with the result of
+// the mutation once its batch completes.
+//
+// Add blocks while the mutations that are outstanding exceed
+// MaxOutstandingBytes, until enough of them complete or ctx is done. It
+// returns ErrBatcherClosed after Close is called. Conditional mutations
+// cannot be batched.
+func (b *MutationBatcher) Add(ctx context.Context, rowKey string, m *Mutation, callback func(error)) error {
+	if m.cond != nil {
+		return errors.New("bigtable: conditional mutations cannot be batched")
+	}
+	size := proto.Size(&btpb.MutateRowsRequest_Entry{RowKey: []byte(rowKey), Mutations: m.ops})
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for {
+		if b.closed {
+			return ErrBatcherClosed
+		}
+		// A mutation larger than the limit is let through when nothing else
+		// is outstanding.
+		if b.outstanding == 0 || b.outstanding+size <= b.cfg.MaxOutstandingBytes {
+			break
+		}
+		released := b.released
+		b.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			b.mu.Lock()
+			return ctx.Err()
+		case <-released:
+		}
+		b.mu.Lock()
+	}
+
+	b.outstanding += size
+	if b.pending == nil {
+		p := &mutationBatch{done: make(chan struct{})}
+		b.pending = p
+		b.timer = time.AfterFunc(b.cfg.DelayThreshold, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			// The batch may have been sent by a threshold already.
+			if b.pending == p {
+				b.sendLocked()
+			}
+		})
+	}
+	p := b.pending
+	p.muts = append(p.muts, batchedMutation{rowKey: rowKey, mut: m, callback: callback})
+	p.size += size
+	if len(p.muts) >= b.cfg.CountThreshold || p.size >= b.cfg.ByteThreshold {
+		b.sendLocked()
+	}
+	return nil
+}
+
+// sendLocked sends the pending batch, if any. b.mu must be held.
+func (b *MutationBatcher) sendLocked() {
+	p := b.pending
+	if p == nil {
+		return
+	}
+	b.pending = nil
+	b.timer.Stop()
+	b.timer = nil
+	b.inFlight[p] = true
+	go b.send(p)
+}
+
+func (b *MutationBatcher) send(p *mutationBatch) {
+	rowKeys := make([]string, len(p.muts))
+	muts := make([]*Mutation, len(p.muts))
+	for i, bm := range p.muts {
+		rowKeys[i] = bm.rowKey
+		muts[i] = bm.mut
+	}
+	b.sem <- struct{}{}
+	errs, err := b.t.ApplyBulk(b.ctx, rowKeys, muts, b.opts...)
+	<-b.sem
+
+	for i, bm := range p.muts {
+		if bm.callback == nil {
+			continue
+		}
+		switch {
+		case err != nil:
+			bm.callback(err)
+		case errs != nil:
+			bm.callback(errs[i])
+		default:
+			bm.callback(nil)
+		}
+	}
+
+	b.mu.Lock()
+	b.outstanding -= p.size
+	close(b.released)
+	b.released = make(chan struct{})
+	delete(b.inFlight, p)
+	b.mu.Unlock()
+	close(p.done)
+}
+
+// Flush sends the current batch without waiting for its thresholds, and waits
+// until all the mutations added before Flush was called have completed and
+// their callbacks have returned, or until ctx is done.
+func (b *MutationBatcher) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	b.sendLocked()
+	var dones []chan struct{}
+	for p := range b.inFlight {
+		dones = append(dones, p.done)
+	}
+	b.mu.Unlock()
+	for _, done := range dones {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-done:
+		}
+	}
+	return nil
+}
+
+// Close stops the batcher from accepting mutations, sends the current batch
+// and waits until all the mutations have completed and their callbacks have
+// returned.
+func (b *MutationBatcher) Close() {
+	b.mu.Lock()
+	b.closed = true
+	// Wake up the Add calls waiting for flow control, so that they return
+	// ErrBatcherClosed.
+	close(b.released)
+	b.released = make(chan struct{})
+	b.mu.Unlock()
+	b.Flush(context.Background())
+}
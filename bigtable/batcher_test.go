@@ -0,0 +1,136 @@
+/*
+Copyright 2024 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bigtable
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestMutationBatcher(t *testing.T) {
+	ctx := context.Background()
+	var calls atomic.Int32
+	countCalls := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasSuffix(info.FullMethod, "MutateRows") {
+			calls.Add(1)
+		}
+		return handler(srv, ss)
+	}
+	tbl, cleanup, err := setupFakeServer(grpc.StreamInterceptor(countCalls))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	b := tbl.NewMutationBatcher(ctx, MutationBatcherConfig{CountThreshold: 10, DelayThreshold: time.Hour})
+	var (
+		mu      sync.Mutex
+		results []error
+	)
+	for i := 0; i < 25; i++ {
+		mut := NewMutation()
+		mut.Set("cf", "col", 1000, []byte("v"))
+		if err := b.Add(ctx, fmt.Sprintf("row%02d", i), mut, func(err error) {
+			mu.Lock()
+			results = append(results, err)
+			mu.Unlock()
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Two batches were sent by the count threshold, and Close sends the last
+	// one.
+	b.Close()
+	if got := calls.Load(); got != 3 {
+		t.Errorf("got %d MutateRows calls, want 3", got)
+	}
+	if len(results) != 25 {
+		t.Fatalf("got %d callbacks, want 25", len(results))
+	}
+	for _, err := range results {
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+	}
+	n := 0
+	if err := tbl.ReadRows(ctx, PrefixRange("row"), func(Row) bool { n++; return true }); err != nil {
+		t.Fatal(err)
+	}
+	if n != 25 {
+		t.Errorf("got %d rows, want 25", n)
+	}
+
+	if err := b.Add(ctx, "row", NewMutation(), nil); err != ErrBatcherClosed {
+		t.Errorf("got %v, want ErrBatcherClosed", err)
+	}
+	b = tbl.NewMutationBatcher(ctx, MutationBatcherConfig{})
+	defer b.Close()
+	cond := NewCondMutation(RowKeyFilter("r"), NewMutation(), nil)
+	if err := b.Add(ctx, "row", cond, nil); err == nil {
+		t.Error("got nil, want error for conditional mutation")
+	}
+}
+
+func TestMutationBatcherDelayAndFlowControl(t *testing.T) {
+	ctx := context.Background()
+	release := make(chan struct{})
+	block := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasSuffix(info.FullMethod, "MutateRows") {
+			<-release
+		}
+		return handler(srv, ss)
+	}
+	tbl, cleanup, err := setupFakeServer(grpc.StreamInterceptor(block))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	b := tbl.NewMutationBatcher(ctx, MutationBatcherConfig{DelayThreshold: time.Millisecond, MaxOutstandingBytes: 1})
+	mut := NewMutation()
+	mut.Set("cf", "col", 1000, []byte("v"))
+	done := make(chan error, 1)
+	// The first mutation is let through even though it exceeds
+	// MaxOutstandingBytes, and is sent after the delay.
+	if err := b.Add(ctx, "row1", mut, func(err error) { done <- err }); err != nil {
+		t.Fatal(err)
+	}
+	// The second one waits for the first one to complete.
+	tctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := b.Add(tctx, "row2", mut, nil); err != context.DeadlineExceeded {
+		t.Errorf("got %v, want DeadlineExceeded", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Add(ctx, "row2", mut, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	b.Close()
+}
@@ -29,6 +29,7 @@ import (
 	btopt "cloud.google.com/go/bigtable/internal/option"
 	"cloud.google.com/go/internal/trace"
 	gax "github.com/googleapis/gax-go/v2"
+	"go.opentelemetry.io/otel/metric"
 	"google.golang.org/api/option"
 	"google.golang.org/api/option/internaloption"
 	gtransport "google.golang.org/api/transport/grpc"
@@ -56,6 +57,7 @@ type Client struct {
 	client            btpb.BigtableClient
 	project, instance string
 	appProfile        string
+	metrics           *builtinMetrics
 }
 
 // ClientConfig has configurations for the client.
@@ -63,6 +65,11 @@ type ClientConfig struct {
 	// The id of the app profile to associate with all data operations sent from this client.
 	// If unspecified, the default app profile for the instance will be used.
 	AppProfile string
+
+	// MeterProvider is the OpenTelemetry meter provider used to record the
+	// client-side metrics of data operations. If nil, the global meter
+	// provider is used. Use a noop.MeterProvider to turn metrics off.
+	MeterProvider metric.MeterProvider
 }
 
 // NewClient creates a new Client for a given project and instance.
@@ -94,18 +101,28 @@ func NewClientWithConfig(ctx context.Context, project, instance string, config C
 	if err != nil {
 		return nil, fmt.Errorf("dialing: %w", err)
 	}
+	metrics, err := newBuiltinMetrics(config.MeterProvider, project, instance, config.AppProfile)
+	if err == nil {
+		err = metrics.observePool(connPool.Num())
+	}
+	if err != nil {
+		connPool.Close()
+		return nil, fmt.Errorf("creating metrics: %w", err)
+	}
 
 	return &Client{
 		connPool:   connPool,
-		client:     btpb.NewBigtableClient(connPool),
+		client:     btpb.NewBigtableClient(meteredConn{connPool}),
 		project:    project,
 		instance:   instance,
 		appProfile: config.AppProfile,
+		metrics:    metrics,
 	}, nil
 }
 
 // Close closes the Client.
 func (c *Client) Close() error {
+	c.metrics.close()
 	return c.connPool.Close()
 }
 
@@ -298,6 +315,8 @@ func (t *Table) ReadRows(ctx context.Context, arg RowSet, f func(Row) bool, opts
 	ctx = mergeOutgoingMetadata(ctx, t.md)
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigtable.ReadRows")
 	defer func() { trace.EndSpan(ctx, err) }()
+	ctx, op := t.c.metrics.startOperation(ctx, t.table, "ReadRows", true)
+	defer func() { op.end(ctx, err) }()
 
 	var prevRowKey string
 	attrMap := make(map[string]interface{})
@@ -333,6 +352,17 @@ func (t *Table) ReadRows(ctx context.Context, arg RowSet, f func(Row) bool, opts
 			return err
 		}
 
+		// drain cancels and drains the stream, so that its last receive
+		// fails and ends the metered attempt.
+		drain := func() {
+			cancel()
+			for {
+				if _, err := stream.Recv(); err != nil {
+					return
+				}
+			}
+		}
+
 		var cr *chunkReader
 		if req.Reversed {
 			cr = newReverseChunkReader()
@@ -370,6 +400,7 @@ func (t *Table) ReadRows(ctx context.Context, arg RowSet, f func(Row) bool, opts
 				row, err := cr.Process(cc)
 				if err != nil {
 					// No need to prepare for a retry, this is an unretryable error.
+					drain()
 					return err
 				}
 				if row == nil {
@@ -377,15 +408,10 @@ func (t *Table) ReadRows(ctx context.Context, arg RowSet, f func(Row) bool, opts
 				}
 				prevRowKey = row.Key()
 				if !f(row) {
-					// Cancel and drain stream.
-					cancel()
-					for {
-						if _, err := stream.Recv(); err != nil {
-							// The stream has ended. We don't return an error
-							// because the caller has intentionally interrupted the scan.
-							return nil
-						}
-					}
+					// We don't return an error because the caller has
+					// intentionally interrupted the scan.
+					drain()
+					return nil
 				}
 			}
 
@@ -401,6 +427,7 @@ func (t *Table) ReadRows(ctx context.Context, arg RowSet, f func(Row) bool, opts
 
 			if err := cr.Close(); err != nil {
 				// No need to prepare for a retry, this is an unretryable error.
+				drain()
 				return err
 			}
 		}
@@ -922,6 +949,12 @@ func (t *Table) Apply(ctx context.Context, row string, m *Mutation, opts ...Appl
 	ctx = mergeOutgoingMetadata(ctx, t.md)
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigtable/Apply")
 	defer func() { trace.EndSpan(ctx, err) }()
+	method := "MutateRow"
+	if m.cond != nil {
+		method = "CheckAndMutateRow"
+	}
+	ctx, op := t.c.metrics.startOperation(ctx, t.table, method, false)
+	defer func() { op.end(ctx, err) }()
 
 	after := func(res proto.Message) {
 		for _, o := range opts {
@@ -945,7 +978,7 @@ func (t *Table) Apply(ctx context.Context, row string, m *Mutation, opts ...Appl
 			callOptions = retryOptions
 		}
 		var res *btpb.MutateRowResponse
-		err := gax.Invoke(ctx, func(ctx context.Context, _ gax.CallSettings) error {
+		err = gax.Invoke(ctx, func(ctx context.Context, _ gax.CallSettings) error {
 			var err error
 			res, err = t.c.client.MutateRow(ctx, req)
 			return err
@@ -1137,7 +1170,10 @@ func (t *Table) ApplyBulk(ctx context.Context, rowKeys []string, muts []*Mutatio
 
 	for _, group := range groupEntries(origEntries, maxMutations) {
 		attrMap := make(map[string]interface{})
-		err = gax.Invoke(ctx, func(ctx context.Context, _ gax.CallSettings) error {
+		// Each group is a separate operation, as it is sent and retried on
+		// its own.
+		gctx, op := t.c.metrics.startOperation(ctx, t.table, "MutateRows", false)
+		err = gax.Invoke(gctx, func(ctx context.Context, _ gax.CallSettings) error {
 			attrMap["rowCount"] = len(group)
 			trace.TracePrintf(ctx, attrMap, "Row count in ApplyBulk")
 			err := t.doApplyBulk(ctx, group, opts...)
@@ -1153,6 +1189,7 @@ func (t *Table) ApplyBulk(ctx context.Context, rowKeys []string, muts []*Mutatio
 			}
 			return nil
 		}, retryOptions...)
+		op.end(gctx, err)
 		if err != nil {
 			return nil, err
 		}
@@ -1286,8 +1323,12 @@ func (ts Timestamp) TruncateToMilliseconds() Timestamp {
 
 // ApplyReadModifyWrite applies a ReadModifyWrite to a specific row.
 // It returns the newly written cells.
-func (t *Table) ApplyReadModifyWrite(ctx context.Context, row string, m *ReadModifyWrite) (Row, error) {
+func (t *Table) ApplyReadModifyWrite(ctx context.Context, row string, m *ReadModifyWrite) (_ Row, err error) {
 	ctx = mergeOutgoingMetadata(ctx, t.md)
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigtable/ApplyReadModifyWrite")
+	defer func() { trace.EndSpan(ctx, err) }()
+	ctx, op := t.c.metrics.startOperation(ctx, t.table, "ReadModifyWriteRow", false)
+	defer func() { op.end(ctx, err) }()
 	req := &btpb.ReadModifyWriteRowRequest{
 		AppProfileId: t.c.appProfile,
 		RowKey:       []byte(row),
@@ -1351,10 +1392,14 @@ func (m *ReadModifyWrite) Increment(family, column string, delta int64) {
 
 // SampleRowKeys returns a sample of row keys in the table. The returned row keys will delimit contiguous sections of
 // the table of approximately equal size, which can be used to break up the data for distributed tasks like mapreduces.
-func (t *Table) SampleRowKeys(ctx context.Context) ([]string, error) {
+func (t *Table) SampleRowKeys(ctx context.Context) (_ []string, err error) {
 	ctx = mergeOutgoingMetadata(ctx, t.md)
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigtable/SampleRowKeys")
+	defer func() { trace.EndSpan(ctx, err) }()
+	ctx, op := t.c.metrics.startOperation(ctx, t.table, "SampleRowKeys", false)
+	defer func() { op.end(ctx, err) }()
 	var sampledRowKeys []string
-	err := gax.Invoke(ctx, func(ctx context.Context, _ gax.CallSettings) error {
+	err = gax.Invoke(ctx, func(ctx context.Context, _ gax.CallSettings) error {
 		sampledRowKeys = nil
 		req := &btpb.SampleRowKeysRequest{
 			AppProfileId: t.c.appProfile,
@@ -1389,5 +1434,6 @@ func (t *Table) SampleRowKeys(ctx context.Context) ([]string, error) {
 		}
 		return nil
 	}, retryOptions...)
-	return sampledRowKeys, convertToGrpcStatusErr(err)
+	err = convertToGrpcStatusErr(err)
+	return sampledRowKeys, err
 }
@@ -0,0 +1,371 @@
+/*
+Copyright 2024 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bigtable
+
+import (
+	"context"
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// A Cell holds a value decoded from a cell, with the cell's timestamp and
+// labels. Use a field of type Cell[T] with Row.Decode to get the timestamp of
+// the latest version of a column, and a field of type []Cell[T] to get all
+// the versions that were read, newest first.
+type Cell[T any] struct {
+	Value     T
+	Timestamp Timestamp
+	Labels    []string
+}
+
+func (c *Cell[T]) setCell(it ReadItem, asJSON bool) error {
+	c.Timestamp = it.Timestamp
+	c.Labels = it.Labels
+	return decodeValue(it.Value, reflect.ValueOf(&c.Value).Elem(), asJSON)
+}
+
+func (c *Cell[T]) valueType() reflect.Type { return reflect.TypeOf(&c.Value).Elem() }
+
+// cellSetter is implemented by pointers to Cell types.
+type cellSetter interface {
+	setCell(it ReadItem, asJSON bool) error
+	valueType() reflect.Type
+}
+
+var (
+	cellSetterType        = reflect.TypeOf((*cellSetter)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+	bytesType             = reflect.TypeOf([]byte(nil))
+)
+
+// Decode sets the fields of the struct pointed to by dst from the cells of r,
+// as described by the fields' "bigtable" tags:
+//
+//	type User struct {
+//		ID      string            `bigtable:",rowkey"`
+//		Name    string            `bigtable:"info:name"`
+//		Visits  int64             `bigtable:"stats:visits"`
+//		Email   Cell[string]      `bigtable:"info:email"`
+//		Logins  []Cell[time.Time] `bigtable:"events:login,json"`
+//		Profile map[string]string `bigtable:"info:profile,json"`
+//	}
+//
+// A tag of the form "family:qualifier" maps the field to a column. A field
+// whose type is not a Cell type is set from the latest version of the column
+// in r. A field of type Cell[T] is set from the latest version along with its
+// timestamp and labels, and a field of type []Cell[T] is set from all the
+// versions in r, newest first. Fields whose column is not in r are left
+// unchanged. The "rowkey" option sets a string or []byte field to the key of
+// the row. Fields without a tag are ignored.
+//
+// Cell values are decoded according to the type of the field (or of the Value
+// of a Cell):
+//   - string and []byte are set to the value.
+//   - Integer types are decoded from 64-bit big-endian values, the encoding
+//     used by AddIntToCell and ReadModifyWrite.Increment.
+//   - float32 and float64 are decoded from 64-bit big-endian IEEE 754 values.
+//   - bool is true if the value is a single non-zero byte.
+//   - Types implementing encoding.BinaryUnmarshaler decode themselves.
+//
+// With the "json" option, the value is decoded as JSON into a field of any
+// type.
+func (r Row) Decode(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bigtable: Decode needs a non-nil pointer to a struct, got %T", dst)
+	}
+	codec, err := codecFor(v.Elem().Type())
+	if err != nil {
+		return err
+	}
+	return codec.decode(r, v.Elem())
+}
+
+// DecodeRow returns a new T, which must be a struct type, with the fields set
+// from r as described in Row.Decode.
+func DecodeRow[T any](r Row) (*T, error) {
+	v := new(T)
+	if err := r.Decode(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ReadRowsAs reads the rows of tbl in arg like TableAPI.ReadRows, decodes
+// each into a new T as described in Row.Decode, and calls f with it. Reading
+// stops when f returns false or a row can't be decoded.
+func ReadRowsAs[T any](ctx context.Context, tbl TableAPI, arg RowSet, f func(*T) bool, opts ...ReadOption) error {
+	if _, err := codecFor(reflect.TypeOf((*T)(nil)).Elem()); err != nil {
+		return err
+	}
+	var decodeErr error
+	err := tbl.ReadRows(ctx, arg, func(r Row) bool {
+		v, err := DecodeRow[T](r)
+		if err != nil {
+			decodeErr = fmt.Errorf("bigtable: row %q: %w", r.Key(), err)
+			return false
+		}
+		return f(v)
+	}, opts...)
+	if decodeErr != nil {
+		return decodeErr
+	}
+	return err
+}
+
+// ReadRowAs reads a single row of tbl like TableAPI.ReadRow, and decodes it
+// into a new T as described in Row.Decode. It returns nil and no error if the
+// row does not exist.
+func ReadRowAs[T any](ctx context.Context, tbl TableAPI, row string, opts ...ReadOption) (*T, error) {
+	if _, err := codecFor(reflect.TypeOf((*T)(nil)).Elem()); err != nil {
+		return nil, err
+	}
+	r, err := tbl.ReadRow(ctx, row, opts...)
+	if err != nil || r == nil {
+		return nil, err
+	}
+	return DecodeRow[T](r)
+}
+
+type fieldKind int
+
+const (
+	valueField fieldKind = iota // the latest value
+	cellField                   // Cell[T]
+	cellsField                  // []Cell[T]
+	keyField                    // the row key
+)
+
+type fieldCodec struct {
+	name   string
+	index  int
+	kind   fieldKind
+	family string
+	column string // "family:qualifier", as in ReadItem.Column
+	asJSON bool
+}
+
+type structCodec struct {
+	fields []fieldCodec
+}
+
+var structCodecs sync.Map // map[reflect.Type]*structCodec or error
+
+func codecFor(t reflect.Type) (*structCodec, error) {
+	if c, ok := structCodecs.Load(t); ok {
+		if err, ok := c.(error); ok {
+			return nil, err
+		}
+		return c.(*structCodec), nil
+	}
+	c, err := newStructCodec(t)
+	if err != nil {
+		structCodecs.Store(t, err)
+		return nil, err
+	}
+	structCodecs.Store(t, c)
+	return c, nil
+}
+
+func newStructCodec(t reflect.Type) (*structCodec, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("bigtable: cannot decode a row into %s; it is not a struct", t)
+	}
+	c := &structCodec{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("bigtable")
+		if !ok || tag == "-" {
+			continue
+		}
+		if !sf.IsExported() {
+			return nil, fmt.Errorf("bigtable: field %s of %s has a bigtable tag but is not exported", sf.Name, t)
+		}
+		fc, err := newFieldCodec(sf, tag)
+		if err != nil {
+			return nil, fmt.Errorf("bigtable: field %s of %s: %w", sf.Name, t, err)
+		}
+		fc.index = i
+		c.fields = append(c.fields, fc)
+	}
+	return c, nil
+}
+
+func newFieldCodec(sf reflect.StructField, tag string) (fieldCodec, error) {
+	col, opts, _ := strings.Cut(tag, ",")
+	fc := fieldCodec{name: sf.Name}
+	var rowKey bool
+	for _, o := range strings.Split(opts, ",") {
+		switch o {
+		case "":
+		case "json":
+			fc.asJSON = true
+		case "rowkey":
+			rowKey = true
+		default:
+			return fc, fmt.Errorf("unknown option %q in bigtable tag", o)
+		}
+	}
+	if rowKey {
+		if col != "" || fc.asJSON {
+			return fc, fmt.Errorf("the rowkey option can't be used with a column or other options")
+		}
+		if sf.Type.Kind() != reflect.String && sf.Type != bytesType {
+			return fc, fmt.Errorf("row key field has type %s; it must be a string or []byte", sf.Type)
+		}
+		fc.kind = keyField
+		return fc, nil
+	}
+	family, _, ok := strings.Cut(col, ":")
+	if !ok || family == "" {
+		return fc, fmt.Errorf("bigtable tag %q must name a column as family:qualifier", tag)
+	}
+	fc.family = family
+	fc.column = col
+
+	vt := sf.Type
+	switch {
+	case reflect.PtrTo(vt).Implements(cellSetterType):
+		fc.kind = cellField
+		vt = reflect.New(vt).Interface().(cellSetter).valueType()
+	case vt.Kind() == reflect.Slice && reflect.PtrTo(vt.Elem()).Implements(cellSetterType):
+		fc.kind = cellsField
+		vt = reflect.New(vt.Elem()).Interface().(cellSetter).valueType()
+	}
+	if !fc.asJSON && !decodable(vt) {
+		return fc, fmt.Errorf("can't decode a cell into %s; use the json option or a type implementing encoding.BinaryUnmarshaler", vt)
+	}
+	return fc, nil
+}
+
+func decodable(t reflect.Type) bool {
+	if t == bytesType || reflect.PtrTo(t).Implements(binaryUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func (c *structCodec) decode(r Row, v reflect.Value) error {
+	for _, fc := range c.fields {
+		f := v.Field(fc.index)
+		if fc.kind == keyField {
+			if key := r.Key(); f.Kind() == reflect.String {
+				f.SetString(key)
+			} else {
+				f.SetBytes([]byte(key))
+			}
+			continue
+		}
+		var items []ReadItem
+		for _, it := range r[fc.family] {
+			if it.Column == fc.column {
+				items = append(items, it)
+			}
+		}
+		if len(items) == 0 {
+			continue
+		}
+		var err error
+		switch fc.kind {
+		case valueField:
+			err = decodeValue(items[0].Value, f, fc.asJSON)
+		case cellField:
+			err = f.Addr().Interface().(cellSetter).setCell(items[0], fc.asJSON)
+		case cellsField:
+			cells := reflect.MakeSlice(f.Type(), len(items), len(items))
+			for i, it := range items {
+				if err = cells.Index(i).Addr().Interface().(cellSetter).setCell(it, fc.asJSON); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				f.Set(cells)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("field %s, column %s: %w", fc.name, fc.column, err)
+		}
+	}
+	return nil
+}
+
+// decodeValue decodes the value of a cell into v, as described in Row.Decode.
+func decodeValue(b []byte, v reflect.Value, asJSON bool) error {
+	if asJSON {
+		p := reflect.New(v.Type())
+		if err := json.Unmarshal(b, p.Interface()); err != nil {
+			return err
+		}
+		v.Set(p.Elem())
+		return nil
+	}
+	if u, ok := v.Addr().Interface().(encoding.BinaryUnmarshaler); ok {
+		return u.UnmarshalBinary(b)
+	}
+	if v.Type() == bytesType {
+		v.SetBytes(append([]byte(nil), b...))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(string(b))
+		return nil
+	case reflect.Bool:
+		if len(b) != 1 {
+			return fmt.Errorf("got %d bytes, want 1 for a bool", len(b))
+		}
+		v.SetBool(b[0] != 0)
+		return nil
+	}
+	if len(b) != 8 {
+		return fmt.Errorf("got %d bytes, want 8 for %s", len(b), v.Type())
+	}
+	n := binary.BigEndian.Uint64(b)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.OverflowInt(int64(n)) {
+			return fmt.Errorf("value %d overflows %s", int64(n), v.Type())
+		}
+		v.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.OverflowUint(n) {
+			return fmt.Errorf("value %d overflows %s", n, v.Type())
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f := math.Float64frombits(n)
+		if v.OverflowFloat(f) {
+			return fmt.Errorf("value %g overflows %s", f, v.Type())
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("can't decode a cell into %s", v.Type())
+	}
+	return nil
+}
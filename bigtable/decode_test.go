@@ -0,0 +1,264 @@
+/*
+Copyright 2024 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bigtable
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/internal/testutil"
+	btpb "google.golang.org/genproto/googleapis/bigtable/v2"
+	"google.golang.org/protobuf/proto"
+)
+
+func int64Bytes(n int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(n))
+	return b
+}
+
+type testUser struct {
+	ID      []byte            `bigtable:",rowkey"`
+	Name    string            `bigtable:"info:name"`
+	Visits  int32             `bigtable:"stats:visits"`
+	Score   float64           `bigtable:"stats:score"`
+	Admin   bool              `bigtable:"info:admin"`
+	Email   Cell[string]      `bigtable:"info:email"`
+	Logins  []Cell[int64]     `bigtable:"events:login"`
+	Profile map[string]string `bigtable:"info:profile,json"`
+	Missing string            `bigtable:"info:missing"`
+	Ignored string
+}
+
+func TestDecodeRow(t *testing.T) {
+	row := Row{
+		"info": {
+			{Row: "u1", Column: "info:name", Timestamp: 2000, Value: []byte("new")},
+			{Row: "u1", Column: "info:name", Timestamp: 1000, Value: []byte("old")},
+			{Row: "u1", Column: "info:admin", Value: []byte{1}},
+			{Row: "u1", Column: "info:email", Timestamp: 3000, Value: []byte("a@b"), Labels: []string{"l"}},
+			{Row: "u1", Column: "info:profile", Value: []byte(`{"k":"v"}`)},
+		},
+		"stats": {
+			{Row: "u1", Column: "stats:visits", Value: int64Bytes(7)},
+			{Row: "u1", Column: "stats:score", Value: int64Bytes(int64(math.Float64bits(1.5)))},
+		},
+		"events": {
+			{Row: "u1", Column: "events:login", Timestamp: 5000, Value: int64Bytes(2)},
+			{Row: "u1", Column: "events:login", Timestamp: 4000, Value: int64Bytes(1)},
+		},
+	}
+	got, err := DecodeRow[testUser](row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &testUser{
+		ID:      []byte("u1"),
+		Name:    "new",
+		Visits:  7,
+		Score:   1.5,
+		Admin:   true,
+		Email:   Cell[string]{Value: "a@b", Timestamp: 3000, Labels: []string{"l"}},
+		Logins:  []Cell[int64]{{Value: 2, Timestamp: 5000}, {Value: 1, Timestamp: 4000}},
+		Profile: map[string]string{"k": "v"},
+	}
+	if !testutil.Equal(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+
+	// Decoding errors.
+	for _, test := range []struct {
+		row  Row
+		want string
+	}{
+		{Row{"stats": {{Column: "stats:visits", Value: []byte{1}}}}, "want 8 for int32"},
+		{Row{"stats": {{Column: "stats:visits", Value: int64Bytes(math.MaxInt64)}}}, "overflows"},
+		{Row{"info": {{Column: "info:profile", Value: []byte("{")}}}, "info:profile"},
+		{Row{"info": {{Column: "info:admin", Value: []byte("yes")}}}, "bool"},
+	} {
+		if _, err := DecodeRow[testUser](test.row); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%v: got %v, want error containing %q", test.row, err, test.want)
+		}
+	}
+}
+
+func TestDecodeInvalidStructs(t *testing.T) {
+	for _, dst := range []interface{}{
+		nil,
+		testUser{},
+		new(int),
+		&struct {
+			A string `bigtable:"nocolon"`
+		}{},
+		&struct {
+			A string `bigtable:":q"`
+		}{},
+		&struct {
+			A time.Duration `bigtable:"f:q,gzip"`
+		}{},
+		&struct {
+			A map[string]int `bigtable:"f:q"`
+		}{},
+		&struct {
+			A []Cell[struct{}] `bigtable:"f:q"`
+		}{},
+		&struct {
+			A int `bigtable:",rowkey"`
+		}{},
+		&struct {
+			a string `bigtable:"f:q"`
+		}{},
+	} {
+		if err := (Row{}).Decode(dst); err == nil {
+			t.Errorf("%T: got nil, want error", dst)
+		}
+	}
+}
+
+func TestReadRowsAs(t *testing.T) {
+	ctx := context.Background()
+	tbl, cleanup, err := setupFakeServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	type item struct {
+		Key   string `bigtable:",rowkey"`
+		Name  string `bigtable:"cf:name"`
+		Count int64  `bigtable:"cf:count"`
+	}
+	for i, key := range []string{"a", "b", "c"} {
+		m := NewMutation()
+		m.Set("cf", "name", 1000, []byte("name-"+key))
+		m.Set("cf", "count", 1000, int64Bytes(int64(i)))
+		if err := tbl.Apply(ctx, key, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var got []item
+	if err := ReadRowsAs(ctx, tbl, InfiniteRange(""), func(it *item) bool {
+		got = append(got, *it)
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []item{{"a", "name-a", 0}, {"b", "name-b", 1}, {"c", "name-c", 2}}
+	if !testutil.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	it, err := ReadRowAs[item](ctx, tbl, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *it != want[1] {
+		t.Errorf("got %v, want %v", *it, want[1])
+	}
+	if it, err := ReadRowAs[item](ctx, tbl, "z"); it != nil || err != nil {
+		t.Errorf("got %v, %v; want nil, nil", it, err)
+	}
+
+	// A value that can't be decoded stops the read.
+	type badItem struct {
+		Name int64 `bigtable:"cf:name"`
+	}
+	n := 0
+	err = ReadRowsAs(ctx, tbl, InfiniteRange(""), func(*badItem) bool { n++; return true })
+	if err == nil || !strings.Contains(err.Error(), `row "a"`) || n != 0 {
+		t.Errorf("got %v after %d rows, want error for row a", err, n)
+	}
+
+	// Filters built with FilterBuilder are applied by the server.
+	f, err := NewFilterBuilder().RowKeyPrefix("b").Columns("cf", "name").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	if err := ReadRowsAs(ctx, tbl, InfiniteRange(""), func(it *item) bool {
+		got = append(got, *it)
+		return true
+	}, RowFilter(f)); err != nil {
+		t.Fatal(err)
+	}
+	if want := []item{{Key: "b", Name: "name-b"}}; !testutil.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFilterBuilder(t *testing.T) {
+	start := time.UnixMilli(1000)
+	f, err := NewFilterBuilder().
+		Family("f.1").
+		Columns("cf", "a", "b*").
+		TimeRange(start, time.Time{}).
+		LatestN(2).
+		Any(NewFilterBuilder().ValueRegexp("x+"), NewFilterBuilder().StripValues()).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ChainFilters(
+		FamilyFilter(`f\.1`),
+		FamilyFilter("cf"),
+		InterleaveFilters(ColumnFilter("(?s)a"), ColumnFilter(`(?s)b\*`)),
+		TimestampRangeFilter(start, time.Time{}),
+		LatestNFilter(2),
+		InterleaveFilters(ValueFilter("x+"), StripValueFilter()),
+	)
+	if !proto.Equal(f.proto(), want.proto()) {
+		t.Errorf("got  %s\nwant %s", f, want)
+	}
+
+	if f, err := NewFilterBuilder().Build(); err != nil || !proto.Equal(f.proto(), PassAllFilter().proto()) {
+		t.Errorf("empty builder: got %v, %v", f, err)
+	}
+	f, err = NewFilterBuilder().ColumnPrefix("cf", "p").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantProto := &btpb.RowFilter{Filter: &btpb.RowFilter_Chain_{Chain: &btpb.RowFilter_Chain{Filters: []*btpb.RowFilter{
+		{Filter: &btpb.RowFilter_FamilyNameRegexFilter{FamilyNameRegexFilter: "cf"}},
+		{Filter: &btpb.RowFilter_ColumnQualifierRegexFilter{ColumnQualifierRegexFilter: []byte("(?s)p.*")}},
+	}}}}
+	if !proto.Equal(f.proto(), wantProto) {
+		t.Errorf("got %v, want %v", f.proto(), wantProto)
+	}
+
+	_, err = NewFilterBuilder().
+		Family("a:b").
+		Columns("cf").
+		RowKeyRegexp("(").
+		ValueRange([]byte("b"), []byte("a")).
+		TimeRange(start, start).
+		LatestN(0).
+		Sample(1).
+		Any(NewFilterBuilder().CellsPerRowLimit(-1)).
+		Build()
+	if err == nil {
+		t.Fatal("got nil, want error")
+	}
+	for _, method := range []string{"Family", "Columns", "RowKeyRegexp", "ValueRange", "TimeRange", "LatestN", "Sample", "Any: bigtable: invalid filter: CellsPerRowLimit"} {
+		if !strings.Contains(err.Error(), method+":") {
+			t.Errorf("error %q does not mention %s", err, method)
+		}
+	}
+}
@@ -48,6 +48,26 @@ To read a single row, use the ReadRow helper method:
 	}
 	// TODO: use r.
 
+ReadRowsAs and ReadRowAs decode rows into structs whose fields are mapped to
+columns with "bigtable" tags; see Row.Decode for details. A FilterBuilder
+builds a filter from conditions that are validated before the filter is sent:
+
+	type Link struct {
+		URL   string                `bigtable:",rowkey"`
+		Title bigtable.Cell[string] `bigtable:"links:title"`
+	}
+	f, err := bigtable.NewFilterBuilder().Columns("links", "title").LatestN(1).Build()
+	if err != nil {
+		// TODO: handle err.
+	}
+	err = bigtable.ReadRowsAs(ctx, tbl, rr, func(l *Link) bool {
+		// TODO: do something with l.
+		return true
+	}, bigtable.RowFilter(f))
+	if err != nil {
+		// TODO: handle err.
+	}
+
 # Writing
 
 This API exposes two distinct forms of writing to a Bigtable: a Mutation and a
@@ -86,6 +106,19 @@ To increment an encoded value in one cell:
 	}
 	// TODO: use r.
 
+For high-throughput writers, a MutationBatcher coalesces mutations added from
+any number of goroutines into ApplyBulk calls made in the background, and
+limits the requests and bytes in flight:
+
+	b := tbl.NewMutationBatcher(ctx, bigtable.MutationBatcherConfig{})
+	err := b.Add(ctx, "com.google.cloud", mut, func(err error) {
+		// TODO: handle the result of the mutation.
+	})
+	if err != nil {
+		// TODO: handle err.
+	}
+	b.Close() // Sends the last mutations and waits for them.
+
 # Retries
 
 If a read or write operation encounters a transient error it will be retried
@@ -93,6 +126,18 @@ until a successful response, an unretryable error or the context deadline is
 reached. Non-idempotent writes (where the timestamp is set to ServerTime) will
 not be retried. In the case of ReadRows, retried calls will not re-scan rows
 that have already been processed.
+
+# Metrics and Tracing
+
+The data client records OpenTelemetry metrics for its operations with the
+meter provider of ClientConfig, or the global meter provider. They include the
+latency of each operation and of each of its RPC attempts, the number of
+retries, the latency measured by the Google front end, the latency of the first
+ReadRows response, the number of attempts that failed before reaching Google,
+and the number of RPCs in flight on the connection pool. Measurements carry the
+project, instance, app profile, table, method, status, and the cluster and zone
+that served the request. Operations are also traced with spans, as described
+in the package documentation of cloud.google.com/go.
 */
 package bigtable // import "cloud.google.com/go/bigtable"
 
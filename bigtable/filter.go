@@ -18,6 +18,8 @@ package bigtable
 
 import (
 	"fmt"
+	"math"
+	"regexp"
 	"strings"
 	"time"
 
@@ -362,3 +364,235 @@ func (baf blockAllFilter) String() string { return "blockAllFilter()" }
 func (baf blockAllFilter) proto() *btpb.RowFilter {
 	return &btpb.RowFilter{Filter: &btpb.RowFilter_BlockAllFilter{BlockAllFilter: true}}
 }
+
+// A FilterBuilder builds a filter from a sequence of conditions, which must all
+// hold for a cell to match. Names given to its methods match exactly, rather
+// than as regular expressions, and its arguments are validated when Build is
+// called, instead of by the service when the filter is used.
+//
+//	f, err := bigtable.NewFilterBuilder().
+//		Columns("info", "name", "email").
+//		TimeRange(start, time.Time{}).
+//		LatestN(1).
+//		Build()
+//
+// The zero value is not usable; use NewFilterBuilder.
+type FilterBuilder struct {
+	filters []Filter
+	errs    []string
+}
+
+// NewFilterBuilder returns a FilterBuilder with no conditions.
+func NewFilterBuilder() *FilterBuilder { return &FilterBuilder{} }
+
+func (b *FilterBuilder) add(f Filter) *FilterBuilder {
+	b.filters = append(b.filters, f)
+	return b
+}
+
+func (b *FilterBuilder) errorf(format string, args ...interface{}) *FilterBuilder {
+	b.errs = append(b.errs, fmt.Sprintf(format, args...))
+	return b
+}
+
+func (b *FilterBuilder) checkFamily(method, family string) bool {
+	if family == "" || strings.Contains(family, ":") {
+		b.errorf("%s: invalid column family %q", method, family)
+		return false
+	}
+	return true
+}
+
+func (b *FilterBuilder) checkRegexp(method, pattern string) bool {
+	if _, err := regexp.Compile(pattern); err != nil {
+		b.errorf("%s: %v", method, err)
+		return false
+	}
+	return true
+}
+
+// exactly returns a regular expression that matches s exactly. The (?s) flag
+// lets it match keys and qualifiers that contain newlines.
+func exactly(s string) string { return "(?s)" + regexp.QuoteMeta(s) }
+
+// Family restricts the filter to cells in the given column family.
+func (b *FilterBuilder) Family(family string) *FilterBuilder {
+	if !b.checkFamily("Family", family) {
+		return b
+	}
+	return b.add(FamilyFilter(regexp.QuoteMeta(family)))
+}
+
+// Columns restricts the filter to cells in the given columns of family.
+func (b *FilterBuilder) Columns(family string, qualifiers ...string) *FilterBuilder {
+	if !b.checkFamily("Columns", family) {
+		return b
+	}
+	if len(qualifiers) == 0 {
+		return b.errorf("Columns: no qualifiers for family %q", family)
+	}
+	var cols []Filter
+	for _, q := range qualifiers {
+		cols = append(cols, ColumnFilter(exactly(q)))
+	}
+	b.add(FamilyFilter(regexp.QuoteMeta(family)))
+	if len(cols) == 1 {
+		return b.add(cols[0])
+	}
+	return b.add(InterleaveFilters(cols...))
+}
+
+// ColumnPrefix restricts the filter to cells in the columns of family whose
+// qualifiers begin with prefix.
+func (b *FilterBuilder) ColumnPrefix(family, prefix string) *FilterBuilder {
+	if !b.checkFamily("ColumnPrefix", family) {
+		return b
+	}
+	return b.add(FamilyFilter(regexp.QuoteMeta(family))).add(ColumnFilter(exactly(prefix) + ".*"))
+}
+
+// ColumnRange restricts the filter to cells in the columns of family with
+// qualifiers in [start, end). An empty start or end means no bound.
+func (b *FilterBuilder) ColumnRange(family, start, end string) *FilterBuilder {
+	if !b.checkFamily("ColumnRange", family) {
+		return b
+	}
+	if start != "" && end != "" && start >= end {
+		return b.errorf("ColumnRange: empty range [%q, %q)", start, end)
+	}
+	return b.add(ColumnRangeFilter(family, start, end))
+}
+
+// RowKeyPrefix restricts the filter to rows whose keys begin with prefix.
+// To read only those rows, prefer passing a PrefixRange to ReadRows.
+func (b *FilterBuilder) RowKeyPrefix(prefix string) *FilterBuilder {
+	return b.add(RowKeyFilter(exactly(prefix) + ".*"))
+}
+
+// RowKeyRegexp restricts the filter to rows whose keys match the RE2 pattern.
+func (b *FilterBuilder) RowKeyRegexp(pattern string) *FilterBuilder {
+	if !b.checkRegexp("RowKeyRegexp", pattern) {
+		return b
+	}
+	return b.add(RowKeyFilter(pattern))
+}
+
+// ValueRegexp restricts the filter to cells whose values match the RE2
+// pattern.
+func (b *FilterBuilder) ValueRegexp(pattern string) *FilterBuilder {
+	if !b.checkRegexp("ValueRegexp", pattern) {
+		return b
+	}
+	return b.add(ValueFilter(pattern))
+}
+
+// ValueRange restricts the filter to cells with values in [start, end). A nil
+// start or end means no bound.
+func (b *FilterBuilder) ValueRange(start, end []byte) *FilterBuilder {
+	if start != nil && end != nil && string(start) >= string(end) {
+		return b.errorf("ValueRange: empty range [%q, %q)", start, end)
+	}
+	return b.add(ValueRangeFilter(start, end))
+}
+
+// TimeRange restricts the filter to cells with timestamps in [start, end). A
+// zero start or end means no bound. Times are truncated to milliseconds.
+func (b *FilterBuilder) TimeRange(start, end time.Time) *FilterBuilder {
+	if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+		return b.errorf("TimeRange: empty range [%v, %v)", start, end)
+	}
+	return b.add(TimestampRangeFilter(start, end))
+}
+
+// LatestN restricts the filter to the n most recent cells of each column.
+func (b *FilterBuilder) LatestN(n int) *FilterBuilder {
+	if n <= 0 || n > math.MaxInt32 {
+		return b.errorf("LatestN: invalid number of cells %d", n)
+	}
+	return b.add(LatestNFilter(n))
+}
+
+// CellsPerRowLimit restricts the filter to the first n cells of each row.
+func (b *FilterBuilder) CellsPerRowLimit(n int) *FilterBuilder {
+	if n <= 0 || n > math.MaxInt32 {
+		return b.errorf("CellsPerRowLimit: invalid number of cells %d", n)
+	}
+	return b.add(CellsPerRowLimitFilter(n))
+}
+
+// CellsPerRowOffset skips the first n cells of each row.
+func (b *FilterBuilder) CellsPerRowOffset(n int) *FilterBuilder {
+	if n < 0 || n > math.MaxInt32 {
+		return b.errorf("CellsPerRowOffset: invalid number of cells %d", n)
+	}
+	return b.add(CellsPerRowOffsetFilter(n))
+}
+
+// Sample restricts the filter to a random sample of rows, each chosen with
+// probability p, which must be in the interval (0, 1).
+func (b *FilterBuilder) Sample(p float64) *FilterBuilder {
+	if !(p > 0 && p < 1) {
+		return b.errorf("Sample: probability %g is not in (0, 1)", p)
+	}
+	return b.add(RowSampleFilter(p))
+}
+
+// StripValues replaces the value of each matching cell with the empty string.
+func (b *FilterBuilder) StripValues() *FilterBuilder { return b.add(StripValueFilter()) }
+
+// Label applies label to each matching cell.
+func (b *FilterBuilder) Label(label string) *FilterBuilder {
+	if label == "" {
+		return b.errorf("Label: empty label")
+	}
+	return b.add(LabelFilter(label))
+}
+
+// Any adds a condition that holds for the cells matched by any of the given
+// builders. The results of each builder are interleaved.
+func (b *FilterBuilder) Any(subs ...*FilterBuilder) *FilterBuilder {
+	if len(subs) == 0 {
+		return b.errorf("Any: no filters")
+	}
+	var fs []Filter
+	for _, sub := range subs {
+		f, err := sub.Build()
+		if err != nil {
+			b.errs = append(b.errs, "Any: "+err.Error())
+			continue
+		}
+		fs = append(fs, f)
+	}
+	if len(fs) != len(subs) {
+		return b
+	}
+	if len(fs) == 1 {
+		return b.add(fs[0])
+	}
+	return b.add(InterleaveFilters(fs...))
+}
+
+// Filter adds an arbitrary filter as a condition.
+func (b *FilterBuilder) Filter(f Filter) *FilterBuilder {
+	if f == nil {
+		return b.errorf("Filter: nil filter")
+	}
+	return b.add(f)
+}
+
+// Build returns the filter that applies the conditions of b in order, or an
+// error describing every invalid argument passed to b. A builder without
+// conditions matches everything.
+func (b *FilterBuilder) Build() (Filter, error) {
+	if len(b.errs) > 0 {
+		return nil, fmt.Errorf("bigtable: invalid filter: %s", strings.Join(b.errs, "; "))
+	}
+	switch len(b.filters) {
+	case 0:
+		return PassAllFilter(), nil
+	case 1:
+		return b.filters[0], nil
+	default:
+		return ChainFilters(append([]Filter(nil), b.filters...)...), nil
+	}
+}
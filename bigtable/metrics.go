@@ -0,0 +1,406 @@
+/*
+Copyright 2024 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bigtable
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"cloud.google.com/go/bigtable/internal"
+	"cloud.google.com/go/internal/trace"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	btpb "google.golang.org/genproto/googleapis/bigtable/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// OtInstrumentationScope is the instrumentation name that will be associated
+// with the emitted telemetry.
+const OtInstrumentationScope = "cloud.google.com/go/bigtable"
+
+const metricsPrefix = "bigtable/"
+
+const (
+	// serverTimingHeader carries the latency measured by the Google front
+	// end, as "gfet4t7; dur=<milliseconds>".
+	serverTimingHeader = "server-timing"
+	serverTimingPrefix = "gfet4t7; dur="
+	// responseParamsHeader carries a serialized btpb.ResponseParams with the
+	// cluster and zone that served the request.
+	responseParamsHeader = "x-goog-ext-425905942-bin"
+)
+
+var (
+	attributeKeyProject    = attribute.Key("project_id")
+	attributeKeyInstance   = attribute.Key("instance")
+	attributeKeyAppProfile = attribute.Key("app_profile")
+	attributeKeyClientName = attribute.Key("client_name")
+	attributeKeyMethod     = attribute.Key("method")
+	attributeKeyTable      = attribute.Key("table")
+	attributeKeyCluster    = attribute.Key("cluster")
+	attributeKeyZone       = attribute.Key("zone")
+	attributeKeyStatus     = attribute.Key("status")
+	attributeKeyStreaming  = attribute.Key("streaming")
+
+	// latencyBoundaries are the histogram buckets, in milliseconds, used by
+	// the other Bigtable clients for their built-in metrics.
+	latencyBoundaries = []float64{0, 0.01, 0.05, 0.1, 0.3, 0.6, 0.8, 1, 2, 3, 4, 5, 6, 8, 10, 13, 16, 20, 25, 30, 40,
+		50, 65, 80, 100, 130, 160, 200, 250, 300, 400, 500, 650, 800, 1000, 2000, 5000, 10000, 20000, 50000, 100000}
+)
+
+// builtinMetrics records the client-side metrics of a Client. A nil
+// *builtinMetrics records nothing.
+type builtinMetrics struct {
+	meter metric.Meter
+	attrs []attribute.KeyValue
+
+	operationLatencies     metric.Float64Histogram
+	attemptLatencies       metric.Float64Histogram
+	serverLatencies        metric.Float64Histogram
+	firstResponseLatencies metric.Float64Histogram
+	retryCount             metric.Int64Counter
+	connectivityErrorCount metric.Int64Counter
+	outstandingRPCs        metric.Int64ObservableGauge
+	connectionPoolSize     metric.Int64ObservableGauge
+
+	outstanding  atomic.Int64
+	registration metric.Registration
+}
+
+func newBuiltinMetrics(mp metric.MeterProvider, project, instance, appProfile string) (*builtinMetrics, error) {
+	// Fall back to the global meter provider in OpenTelemetry.
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+	meter := mp.Meter(OtInstrumentationScope, metric.WithInstrumentationVersion(internal.Version))
+	m := &builtinMetrics{
+		meter: meter,
+		attrs: []attribute.KeyValue{
+			attributeKeyProject.String(project),
+			attributeKeyInstance.String(instance),
+			attributeKeyAppProfile.String(appProfile),
+			attributeKeyClientName.String("go-bigtable/" + internal.Version),
+		},
+	}
+	latency := func(name, desc string) (metric.Float64Histogram, error) {
+		return meter.Float64Histogram(
+			metricsPrefix+name,
+			metric.WithDescription(desc),
+			metric.WithUnit("ms"),
+			metric.WithExplicitBucketBoundaries(latencyBoundaries...),
+		)
+	}
+	var err error
+	if m.operationLatencies, err = latency("operation_latencies",
+		"The total end-to-end latency across all RPC attempts associated with a Bigtable operation."); err != nil {
+		return nil, err
+	}
+	if m.attemptLatencies, err = latency("attempt_latencies",
+		"The latency of each client RPC attempt."); err != nil {
+		return nil, err
+	}
+	if m.serverLatencies, err = latency("server_latencies",
+		"The latency measured from the moment that the RPC entered the Google data center until the RPC was completed."); err != nil {
+		return nil, err
+	}
+	if m.firstResponseLatencies, err = latency("first_response_latencies",
+		"The latency from the start of a ReadRows operation until the first response is received."); err != nil {
+		return nil, err
+	}
+	if m.retryCount, err = meter.Int64Counter(
+		metricsPrefix+"retry_count",
+		metric.WithDescription("The number of additional RPCs sent after the initial attempt."),
+		metric.WithUnit("1"),
+	); err != nil {
+		return nil, err
+	}
+	if m.connectivityErrorCount, err = meter.Int64Counter(
+		metricsPrefix+"connectivity_error_count",
+		metric.WithDescription("The number of failed RPC attempts that did not reach the Google data center."),
+		metric.WithUnit("1"),
+	); err != nil {
+		return nil, err
+	}
+	if m.outstandingRPCs, err = meter.Int64ObservableGauge(
+		metricsPrefix+"outstanding_rpcs",
+		metric.WithDescription("The number of RPC attempts in flight on the connection pool."),
+		metric.WithUnit("1"),
+	); err != nil {
+		return nil, err
+	}
+	if m.connectionPoolSize, err = meter.Int64ObservableGauge(
+		metricsPrefix+"connection_pool_size",
+		metric.WithDescription("The number of connections in the connection pool."),
+		metric.WithUnit("1"),
+	); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// observePool starts reporting the saturation of a connection pool of the
+// given size.
+func (m *builtinMetrics) observePool(size int) error {
+	attrs := metric.WithAttributes(m.attrs...)
+	reg, err := m.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(m.outstandingRPCs, m.outstanding.Load(), attrs)
+		o.ObserveInt64(m.connectionPoolSize, int64(size), attrs)
+		return nil
+	}, m.outstandingRPCs, m.connectionPoolSize)
+	if err != nil {
+		return err
+	}
+	m.registration = reg
+	return nil
+}
+
+func (m *builtinMetrics) close() error {
+	if m == nil || m.registration == nil {
+		return nil
+	}
+	return m.registration.Unregister()
+}
+
+type operationKey struct{}
+
+// operation tracks the RPC attempts of one Bigtable operation, such as a
+// ReadRows call including its retries.
+type operation struct {
+	m         *builtinMetrics
+	method    string
+	table     string
+	streaming bool
+	start     time.Time
+
+	mu            sync.Mutex
+	attempts      int
+	firstResponse time.Duration // zero until the first response message
+	cluster, zone string
+}
+
+// startOperation returns a context that carries a new operation for method on
+// table. The attempts made with the context through a meteredConn are
+// recorded.
+func (m *builtinMetrics) startOperation(ctx context.Context, table, method string, streaming bool) (context.Context, *operation) {
+	if m == nil {
+		return ctx, nil
+	}
+	op := &operation{
+		m:         m,
+		method:    "Bigtable." + method,
+		table:     table,
+		streaming: streaming,
+		start:     time.Now(),
+	}
+	return context.WithValue(ctx, operationKey{}, op), op
+}
+
+// end records the metrics of the operation, which failed with err if it is
+// not nil. Operations that made no RPC attempt are not recorded.
+func (op *operation) end(ctx context.Context, err error) {
+	if op == nil {
+		return
+	}
+	op.mu.Lock()
+	attempts, firstResponse := op.attempts, op.firstResponse
+	attrs := op.attributes(status.Code(err))
+	op.mu.Unlock()
+	if attempts == 0 {
+		return
+	}
+	opts := metric.WithAttributes(attrs...)
+	op.m.operationLatencies.Record(ctx, millis(time.Since(op.start)), opts)
+	op.m.retryCount.Add(ctx, int64(attempts-1), opts)
+	if op.method == "Bigtable.ReadRows" && firstResponse > 0 {
+		op.m.firstResponseLatencies.Record(ctx, millis(firstResponse), opts)
+	}
+}
+
+// attributes returns the attributes of a measurement with the given status.
+// op.mu must be held.
+func (op *operation) attributes(code codes.Code) []attribute.KeyValue {
+	cluster, zone := op.cluster, op.zone
+	if cluster == "" {
+		cluster = "unspecified"
+	}
+	if zone == "" {
+		zone = "global"
+	}
+	return append(op.m.attrs[:len(op.m.attrs):len(op.m.attrs)],
+		attributeKeyMethod.String(op.method),
+		attributeKeyTable.String(op.table),
+		attributeKeyCluster.String(cluster),
+		attributeKeyZone.String(zone),
+		attributeKeyStatus.String(code.String()),
+		attributeKeyStreaming.Bool(op.streaming),
+	)
+}
+
+func (op *operation) gotResponse() {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	if op.firstResponse == 0 {
+		op.firstResponse = time.Since(op.start)
+	}
+}
+
+// attempt is one RPC of an operation.
+type attempt struct {
+	op    *operation
+	n     int
+	start time.Time
+	once  sync.Once
+}
+
+func (op *operation) startAttempt() *attempt {
+	op.m.outstanding.Add(1)
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	op.attempts++
+	return &attempt{op: op, n: op.attempts, start: time.Now()}
+}
+
+// end records the metrics of the attempt from the headers and trailers of its
+// response. An io.EOF err marks the successful end of a stream.
+func (a *attempt) end(ctx context.Context, header, trailer metadata.MD, err error) {
+	a.once.Do(func() {
+		a.op.m.outstanding.Add(-1)
+		if err == io.EOF {
+			err = nil
+		}
+		code := status.Code(err)
+		serverLatency, hasServerLatency := serverTiming(header, trailer)
+		op := a.op
+		op.mu.Lock()
+		if params := responseParams(header, trailer); params != nil {
+			op.cluster, op.zone = params.GetClusterId(), params.GetZoneId()
+		}
+		attrs := op.attributes(code)
+		op.mu.Unlock()
+
+		opts := metric.WithAttributes(attrs...)
+		op.m.attemptLatencies.Record(ctx, millis(time.Since(a.start)), opts)
+		if hasServerLatency {
+			op.m.serverLatencies.Record(ctx, millis(serverLatency), opts)
+		} else if code != codes.OK {
+			op.m.connectivityErrorCount.Add(ctx, 1, opts)
+		}
+		if code != codes.OK {
+			trace.TracePrintf(ctx, map[string]interface{}{
+				"attempt": a.n,
+				"status":  code.String(),
+			}, "Attempt failed in %s", op.method)
+		}
+	})
+}
+
+// serverTiming returns the server latency reported in the server-timing
+// header, if any.
+func serverTiming(mds ...metadata.MD) (time.Duration, bool) {
+	for _, md := range mds {
+		for _, v := range md.Get(serverTimingHeader) {
+			if !strings.HasPrefix(v, serverTimingPrefix) {
+				continue
+			}
+			ms, err := strconv.ParseFloat(strings.TrimPrefix(v, serverTimingPrefix), 64)
+			if err == nil {
+				return time.Duration(ms * float64(time.Millisecond)), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// responseParams returns the response params carried by the metadata, if
+// any.
+func responseParams(mds ...metadata.MD) *btpb.ResponseParams {
+	for _, md := range mds {
+		for _, v := range md.Get(responseParamsHeader) {
+			var p btpb.ResponseParams
+			if err := proto.Unmarshal([]byte(v), &p); err == nil {
+				return &p
+			}
+		}
+	}
+	return nil
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// meteredConn records the metrics of the RPCs made on a connection with the
+// context of an operation.
+type meteredConn struct {
+	grpc.ClientConnInterface
+}
+
+func (c meteredConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	op, _ := ctx.Value(operationKey{}).(*operation)
+	if op == nil {
+		return c.ClientConnInterface.Invoke(ctx, method, args, reply, opts...)
+	}
+	var header, trailer metadata.MD
+	opts = append(opts, grpc.Header(&header), grpc.Trailer(&trailer))
+	a := op.startAttempt()
+	err := c.ClientConnInterface.Invoke(ctx, method, args, reply, opts...)
+	a.end(ctx, header, trailer, err)
+	return err
+}
+
+func (c meteredConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	op, _ := ctx.Value(operationKey{}).(*operation)
+	if op == nil {
+		return c.ClientConnInterface.NewStream(ctx, desc, method, opts...)
+	}
+	a := op.startAttempt()
+	s, err := c.ClientConnInterface.NewStream(ctx, desc, method, opts...)
+	if err != nil {
+		a.end(ctx, nil, nil, err)
+		return nil, err
+	}
+	return &meteredStream{ClientStream: s, ctx: ctx, attempt: a}, nil
+}
+
+// meteredStream ends its attempt when a receive fails, which includes the
+// io.EOF at the end of the stream.
+type meteredStream struct {
+	grpc.ClientStream
+	ctx     context.Context
+	attempt *attempt
+}
+
+func (s *meteredStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.attempt.op.gotResponse()
+		return nil
+	}
+	header, _ := s.ClientStream.Header()
+	s.attempt.end(s.ctx, header, s.ClientStream.Trailer(), err)
+	return err
+}
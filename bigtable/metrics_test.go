@@ -0,0 +1,231 @@
+/*
+Copyright 2024 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bigtable
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/bigtable/bttest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"google.golang.org/api/option"
+	btpb "google.golang.org/genproto/googleapis/bigtable/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type fakeMeasurement struct {
+	value float64
+	attrs attribute.Set
+}
+
+// fakeMeterProvider keeps the measurements of the histograms and counters
+// created from it.
+type fakeMeterProvider struct {
+	noop.MeterProvider
+
+	mu           sync.Mutex
+	measurements map[string][]fakeMeasurement
+}
+
+func (p *fakeMeterProvider) Meter(string, ...metric.MeterOption) metric.Meter {
+	return fakeMeter{p: p}
+}
+
+func (p *fakeMeterProvider) record(name string, v float64, attrs attribute.Set) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.measurements == nil {
+		p.measurements = map[string][]fakeMeasurement{}
+	}
+	p.measurements[name] = append(p.measurements[name], fakeMeasurement{v, attrs})
+}
+
+// get returns the measurements of the named instrument for method.
+func (p *fakeMeterProvider) get(name, method string) []fakeMeasurement {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var ms []fakeMeasurement
+	for _, m := range p.measurements[metricsPrefix+name] {
+		if v, _ := m.attrs.Value(attributeKeyMethod); v.AsString() == method {
+			ms = append(ms, m)
+		}
+	}
+	return ms
+}
+
+type fakeMeter struct {
+	noop.Meter
+	p *fakeMeterProvider
+}
+
+func (m fakeMeter) Float64Histogram(name string, _ ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	return fakeHistogram{p: m.p, name: name}, nil
+}
+
+func (m fakeMeter) Int64Counter(name string, _ ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return fakeCounter{p: m.p, name: name}, nil
+}
+
+type fakeHistogram struct {
+	noop.Float64Histogram
+	p    *fakeMeterProvider
+	name string
+}
+
+func (h fakeHistogram) Record(_ context.Context, v float64, opts ...metric.RecordOption) {
+	h.p.record(h.name, v, metric.NewRecordConfig(opts).Attributes())
+}
+
+type fakeCounter struct {
+	noop.Int64Counter
+	p    *fakeMeterProvider
+	name string
+}
+
+func (c fakeCounter) Add(_ context.Context, v int64, opts ...metric.AddOption) {
+	c.p.record(c.name, float64(v), metric.NewAddConfig(opts).Attributes())
+}
+
+func TestBuiltinMetrics(t *testing.T) {
+	ctx := context.Background()
+
+	// The first ReadRows fails before reaching the server. The other RPCs
+	// return the headers of the Google front end.
+	params, err := proto.Marshal(&btpb.ResponseParams{ClusterId: proto.String("c1"), ZoneId: proto.String("z1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := metadata.Pairs(serverTimingHeader, "gfet4t7; dur=12.5", responseParamsHeader, string(params))
+	failed := false
+	streamInjector := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasSuffix(info.FullMethod, "ReadRows") && !failed {
+			failed = true
+			return status.Error(codes.Unavailable, "unavailable")
+		}
+		if err := ss.SetHeader(header); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+	unaryInjector := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := grpc.SetHeader(ctx, header); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+	srv, err := bttest.NewServer("localhost:0", grpc.StreamInterceptor(streamInjector), grpc.UnaryInterceptor(unaryInjector))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	conn, err := grpc.Dial(srv.Addr, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	adminClient, err := NewAdminClient(ctx, "project", "instance", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer adminClient.Close()
+	if err := adminClient.CreateTable(ctx, "table"); err != nil {
+		t.Fatal(err)
+	}
+	if err := adminClient.CreateColumnFamily(ctx, "table", "cf"); err != nil {
+		t.Fatal(err)
+	}
+
+	mp := &fakeMeterProvider{}
+	client, err := NewClientWithConfig(ctx, "project", "instance", ClientConfig{AppProfile: "profile", MeterProvider: mp}, option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	tbl := client.Open("table")
+
+	mut := NewMutation()
+	mut.Set("cf", "col", 1000, []byte("v"))
+	if err := tbl.Apply(ctx, "row", mut); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tbl.ReadRow(ctx, "row"); err != nil {
+		t.Fatal(err)
+	}
+
+	ops := mp.get("operation_latencies", "Bigtable.MutateRow")
+	if len(ops) != 1 {
+		t.Fatalf("got %d MutateRow operation latencies, want 1", len(ops))
+	}
+	for k, want := range map[attribute.Key]string{
+		attributeKeyProject:    "project",
+		attributeKeyInstance:   "instance",
+		attributeKeyAppProfile: "profile",
+		attributeKeyTable:      "table",
+		attributeKeyCluster:    "c1",
+		attributeKeyZone:       "z1",
+		attributeKeyStatus:     "OK",
+	} {
+		if v, _ := ops[0].attrs.Value(k); v.AsString() != want {
+			t.Errorf("MutateRow attribute %s: got %q, want %q", k, v.AsString(), want)
+		}
+	}
+	if got := mp.get("server_latencies", "Bigtable.MutateRow"); len(got) != 1 || got[0].value != 12.5 {
+		t.Errorf("got MutateRow server latencies %v, want one of 12.5", got)
+	}
+
+	if got := mp.get("attempt_latencies", "Bigtable.ReadRows"); len(got) != 2 {
+		t.Errorf("got %d ReadRows attempt latencies, want 2", len(got))
+	}
+	if got := mp.get("retry_count", "Bigtable.ReadRows"); len(got) != 1 || got[0].value != 1 {
+		t.Errorf("got ReadRows retry counts %v, want one of 1", got)
+	}
+	if got := mp.get("connectivity_error_count", "Bigtable.ReadRows"); len(got) != 1 {
+		t.Errorf("got %d ReadRows connectivity errors, want 1", len(got))
+	}
+	if got := mp.get("first_response_latencies", "Bigtable.ReadRows"); len(got) != 1 {
+		t.Errorf("got %d ReadRows first response latencies, want 1", len(got))
+	}
+	if got := client.metrics.outstanding.Load(); got != 0 {
+		t.Errorf("got %d outstanding RPCs, want 0", got)
+	}
+}
+
+func TestServerTiming(t *testing.T) {
+	for _, test := range []struct {
+		md   metadata.MD
+		want float64
+		ok   bool
+	}{
+		{metadata.Pairs(serverTimingHeader, "gfet4t7; dur=3"), 3, true},
+		{metadata.Pairs(serverTimingHeader, "other; dur=3", serverTimingHeader, "gfet4t7; dur=0.5"), 0.5, true},
+		{metadata.Pairs(serverTimingHeader, "gfet4t7; dur=x"), 0, false},
+		{nil, 0, false},
+	} {
+		got, ok := serverTiming(test.md)
+		if millis(got) != test.want || ok != test.ok {
+			t.Errorf("serverTiming(%v) = %v, %t, want %vms, %t", test.md, got, ok, test.want, test.ok)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package callpolicy installs a default retry, timeout and header policy on
+// every call made by a generated Google Cloud client, instead of configuring
+// the client's gax.CallOptions method by method.
+//
+// A Policy has two parts. The options returned by ClientOptions are passed to
+// the client's constructor; they add gRPC interceptors that bound each
+// attempt of a call and add static headers, such as audit tags, to every
+// request. Apply is called on the constructed client; it sets the retry and
+// overall timeout of each of its methods:
+//
+//	pol := &callpolicy.Policy{
+//		Retry: func() gax.Retryer {
+//			return gax.OnCodes([]codes.Code{codes.Unavailable}, gax.Backoff{})
+//		},
+//		Timeout:        time.Minute,
+//		AttemptTimeout: 10 * time.Second,
+//		Headers:        map[string]string{"x-audit-tag": "nightly-job"},
+//	}
+//	client, err := secretmanager.NewClient(ctx, pol.ClientOptions()...)
+//	if err != nil {
+//		// TODO: handle error.
+//	}
+//	if err := pol.Apply(client); err != nil {
+//		// TODO: handle error.
+//	}
+//
+// The policy replaces the defaults of the generated client, but options
+// passed to an individual call still take precedence over it.
+//
+// The interceptors are only used by clients that use gRPC. Apply works with
+// clients of either transport.
+package callpolicy // import "cloud.google.com/go/callpolicy"
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// A Policy describes the defaults to install on a client. The zero value of
+// each field leaves the corresponding behavior of the client unchanged.
+type Policy struct {
+	// Retry, if non-nil, replaces the retry settings of every method of the
+	// client. It may return nil to disable retries.
+	Retry func() gax.Retryer
+
+	// Timeout, if positive, bounds each call to a unary method, including
+	// retries. It is not applied to streaming methods, or to calls whose
+	// context already has a deadline.
+	Timeout time.Duration
+
+	// AttemptTimeout, if positive, bounds each attempt of a call to a unary
+	// method. An attempt that times out fails with codes.DeadlineExceeded,
+	// which is retried if the retry settings of the method allow it.
+	AttemptTimeout time.Duration
+
+	// Headers are added to the metadata of every request, unary or
+	// streaming.
+	Headers map[string]string
+}
+
+var callOptionsType = reflect.TypeOf([]gax.CallOption(nil))
+
+// Apply installs the retry settings and timeout of p on every method of
+// client, which must be a pointer to a generated client, with an exported
+// CallOptions field. Call Apply before the client is used; it is not safe to
+// call concurrently with the client's methods.
+func (p *Policy) Apply(client interface{}) error {
+	cv := reflect.ValueOf(client)
+	if cv.Kind() != reflect.Ptr || cv.IsNil() || cv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("callpolicy: Apply needs a pointer to a client, got %T", client)
+	}
+	ov := cv.Elem().FieldByName("CallOptions")
+	if !ov.IsValid() || ov.Kind() != reflect.Ptr || ov.Type().Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("callpolicy: %T has no CallOptions field", client)
+	}
+	if ov.IsNil() {
+		return fmt.Errorf("callpolicy: %T has nil CallOptions", client)
+	}
+	ov = ov.Elem()
+	for i := 0; i < ov.NumField(); i++ {
+		sf := ov.Type().Field(i)
+		if sf.Type != callOptionsType || !sf.IsExported() {
+			continue
+		}
+		var opts []gax.CallOption
+		if p.Retry != nil {
+			opts = append(opts, gax.WithRetry(p.Retry))
+		}
+		if p.Timeout > 0 && !isStreaming(cv, sf.Name) {
+			opts = append(opts, gax.WithTimeout(p.Timeout))
+		}
+		if len(opts) == 0 {
+			continue
+		}
+		f := ov.Field(i)
+		old := f.Interface().([]gax.CallOption)
+		// Copy, so as not to modify an array shared with another client.
+		f.Set(reflect.ValueOf(append(old[:len(old):len(old)], opts...)))
+	}
+	return nil
+}
+
+// isStreaming reports whether the method of client with the given name
+// returns a stream. Generated clients do not set a timeout on streaming
+// methods, since it would apply to the whole stream.
+func isStreaming(client reflect.Value, name string) bool {
+	m := client.MethodByName(name)
+	if !m.IsValid() || m.Type().NumOut() == 0 {
+		return false
+	}
+	_, ok := m.Type().Out(0).MethodByName("CloseSend")
+	return ok
+}
+
+// ClientOptions returns the options to pass to the constructor of a client to
+// enforce the AttemptTimeout and Headers of p.
+func (p *Policy) ClientOptions() []option.ClientOption {
+	return []option.ClientOption{
+		option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(p.interceptUnary)),
+		option.WithGRPCDialOption(grpc.WithChainStreamInterceptor(p.interceptStream)),
+	}
+}
+
+func (p *Policy) interceptUnary(ctx context.Context, method string, req, res interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx = p.withHeaders(ctx)
+	if p.AttemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.AttemptTimeout)
+		defer cancel()
+	}
+	return invoker(ctx, method, req, res, cc, opts...)
+}
+
+func (p *Policy) interceptStream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(p.withHeaders(ctx), desc, cc, method, opts...)
+}
+
+func (p *Policy) withHeaders(ctx context.Context) context.Context {
+	if len(p.Headers) == 0 {
+		return ctx
+	}
+	kv := make([]string, 0, 2*len(p.Headers))
+	for k, v := range p.Headers {
+		kv = append(kv, k, v)
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package callpolicy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeCallOptions and fakeClient mimic a generated client.
+type fakeCallOptions struct {
+	Get   []gax.CallOption
+	Watch []gax.CallOption
+	Other int
+}
+
+type fakeStream interface {
+	grpc.ClientStream
+	Recv() (string, error)
+}
+
+type fakeClient struct {
+	CallOptions *fakeCallOptions
+}
+
+func (c *fakeClient) Get(ctx context.Context, opts ...gax.CallOption) error {
+	return gax.Invoke(ctx, func(ctx context.Context, _ gax.CallSettings) error {
+		if _, ok := ctx.Deadline(); !ok {
+			return status.Error(codes.Internal, "no deadline")
+		}
+		return status.Error(codes.Unavailable, "unavailable")
+	}, append(c.CallOptions.Get, opts...)...)
+}
+
+func (c *fakeClient) Watch(context.Context, ...gax.CallOption) (fakeStream, error) {
+	return nil, nil
+}
+
+func TestApply(t *testing.T) {
+	defaults := &fakeCallOptions{Get: []gax.CallOption{gax.WithTimeout(time.Hour)}}
+	c := &fakeClient{CallOptions: &fakeCallOptions{Get: defaults.Get}}
+	retries := 0
+	p := &Policy{
+		Retry: func() gax.Retryer {
+			return gax.OnCodes([]codes.Code{codes.Unavailable}, gax.Backoff{Initial: time.Millisecond})
+		},
+		Timeout: 100 * time.Millisecond,
+	}
+	if err := p.Apply(c); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(c.CallOptions.Get), 3; got != want {
+		t.Errorf("got %d options for Get, want %d", got, want)
+	}
+	if got, want := len(c.CallOptions.Watch), 1; got != want {
+		t.Errorf("got %d options for Watch, want %d", got, want)
+	}
+	if len(defaults.Get) != 1 {
+		t.Error("Apply modified the original options")
+	}
+
+	// Unavailable is retried until the timeout.
+	start := time.Now()
+	err := c.Get(context.Background(), gax.WithRetry(func() gax.Retryer {
+		retries++
+		return gax.OnCodes([]codes.Code{codes.Unavailable}, gax.Backoff{Initial: time.Millisecond})
+	}))
+	if err != context.DeadlineExceeded && status.Code(err) != codes.Unavailable {
+		t.Errorf("got %v, want a timeout", err)
+	}
+	if d := time.Since(start); d < 100*time.Millisecond || d > time.Minute {
+		t.Errorf("call took %s, want about 100ms", d)
+	}
+	// Options passed to the call take precedence.
+	if retries != 1 {
+		t.Errorf("got %d calls to the call's retryer, want 1", retries)
+	}
+
+	for _, bad := range []interface{}{nil, c.CallOptions, &struct{ CallOptions int }{}, &fakeClient{}} {
+		if err := p.Apply(bad); err == nil {
+			t.Errorf("Apply(%#v): got nil, want error", bad)
+		}
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	p := &Policy{
+		AttemptTimeout: time.Minute,
+		Headers:        map[string]string{"x-audit": "tag"},
+	}
+	check := func(ctx context.Context, wantDeadline bool) {
+		t.Helper()
+		md, _ := metadata.FromOutgoingContext(ctx)
+		if got := md.Get("x-audit"); len(got) != 1 || got[0] != "tag" {
+			t.Errorf("got x-audit %v, want [tag]", got)
+		}
+		if got := md.Get("other"); len(got) != 1 || got[0] != "v" {
+			t.Errorf("got other %v, want [v]", got)
+		}
+		if _, ok := ctx.Deadline(); ok != wantDeadline {
+			t.Errorf("got deadline %t, want %t", ok, wantDeadline)
+		}
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "other", "v")
+	err := p.interceptUnary(ctx, "/m", nil, nil, nil, func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		check(ctx, true)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.interceptStream(ctx, nil, nil, "/m", func(ctx context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+		check(ctx, false)
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -22,6 +22,7 @@
 package civil
 
 import (
+	"database/sql/driver"
 	"fmt"
 	"time"
 )
@@ -348,3 +349,227 @@ func (dt *DateTime) UnmarshalText(data []byte) error {
 	*dt, err = ParseDateTime(string(data))
 	return err
 }
+
+// ParseDateLayout parses a date formatted with the given layout, as described
+// in the documentation of time.Parse, and returns the date value it
+// represents. The time of day and the time zone in the value, if any, are
+// ignored.
+func ParseDateLayout(layout, value string) (Date, error) {
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return Date{}, err
+	}
+	return DateOf(t), nil
+}
+
+// Format returns the date formatted with the given layout, as described in
+// the documentation of time.Time.Format. Elements of the layout for the time
+// of day are formatted as midnight, and those for the time zone as UTC.
+func (d Date) Format(layout string) string {
+	return d.In(time.UTC).Format(layout)
+}
+
+// ParseTimeLayout parses a time formatted with the given layout, as described
+// in the documentation of time.Parse, and returns the time value it
+// represents. The date and the time zone in the value, if any, are ignored.
+func ParseTimeLayout(layout, value string) (Time, error) {
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return Time{}, err
+	}
+	return TimeOf(t), nil
+}
+
+// Format returns the time formatted with the given layout, as described in
+// the documentation of time.Time.Format. Elements of the layout for the date
+// are formatted as January 1 of year 0, and those for the time zone as UTC.
+func (t Time) Format(layout string) string {
+	return time.Date(0, time.January, 1, t.Hour, t.Minute, t.Second, t.Nanosecond, time.UTC).Format(layout)
+}
+
+// ParseDateTimeLayout parses a datetime formatted with the given layout, as
+// described in the documentation of time.Parse, and returns the DateTime it
+// represents. The time zone in the value, if any, is ignored.
+func ParseDateTimeLayout(layout, value string) (DateTime, error) {
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return DateTime{}, err
+	}
+	return DateTimeOf(t), nil
+}
+
+// Format returns the datetime formatted with the given layout, as described
+// in the documentation of time.Time.Format. Elements of the layout for the
+// time zone are formatted as UTC.
+func (dt DateTime) Format(layout string) string {
+	return dt.In(time.UTC).Format(layout)
+}
+
+// An EndOfMonthPolicy determines the result of Date.AddMonths and
+// Date.AddYears when the day of the month doesn't exist in the resulting
+// month, as when adding a month to January 31.
+type EndOfMonthPolicy int
+
+const (
+	// EndOfMonthClamp uses the last day of the resulting month: January 31
+	// plus one month is February 28, or February 29 in leap years.
+	EndOfMonthClamp EndOfMonthPolicy = iota
+
+	// EndOfMonthOverflow carries the extra days over to the following
+	// month, like time.Time.AddDate: January 31 plus one month is March 3,
+	// or March 2 in leap years.
+	EndOfMonthOverflow
+
+	// EndOfMonthPreserve maps the last day of a month to the last day of
+	// the resulting month, and otherwise clamps like EndOfMonthClamp:
+	// February 28, 2023 plus one month is March 31, 2023.
+	EndOfMonthPreserve
+)
+
+// AddMonths returns the date that is n months in the future, or in the past
+// if n is negative. The policy determines the result when the day doesn't
+// exist in the resulting month.
+func (d Date) AddMonths(n int, policy EndOfMonthPolicy) Date {
+	if policy == EndOfMonthOverflow {
+		return DateOf(d.In(time.UTC).AddDate(0, n, 0))
+	}
+	// Normalize the month with time.Date, on the first day.
+	first := DateOf(time.Date(d.Year, d.Month+time.Month(n), 1, 0, 0, 0, 0, time.UTC))
+	last := daysIn(first.Year, first.Month)
+	day := d.Day
+	if day > last || (policy == EndOfMonthPreserve && d.Day == daysIn(d.Year, d.Month)) {
+		day = last
+	}
+	first.Day = day
+	return first
+}
+
+// AddYears returns the date that is n years in the future, or in the past if
+// n is negative. The policy determines the result for February 29 when the
+// resulting year is not a leap year.
+func (d Date) AddYears(n int, policy EndOfMonthPolicy) Date {
+	return d.AddMonths(12*n, policy)
+}
+
+// daysIn returns the number of days in the month of the year.
+func daysIn(year int, month time.Month) int {
+	// Day 0 of the next month is the last day of the month.
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// EndOfMonth returns the last day of the month of the date.
+func (d Date) EndOfMonth() Date {
+	return Date{Year: d.Year, Month: d.Month, Day: daysIn(d.Year, d.Month)}
+}
+
+// Weekday returns the day of the week of the date.
+func (d Date) Weekday() time.Weekday {
+	return d.In(time.UTC).Weekday()
+}
+
+// YearDay returns the day of the year of the date, in the range [1,365] for
+// non-leap years, and [1,366] in leap years.
+func (d Date) YearDay() int {
+	return d.In(time.UTC).YearDay()
+}
+
+// ISOWeek returns the ISO 8601 year and week number in which the date occurs.
+// Week ranges from 1 to 53. Jan 01 to Jan 03 of year n might belong to
+// week 52 or 53 of year n-1, and Dec 29 to Dec 31 might belong to week 1 of
+// year n+1.
+func (d Date) ISOWeek() (year, week int) {
+	return d.In(time.UTC).ISOWeek()
+}
+
+// StartOfWeek returns the first day of the week of the date, for weeks that
+// start on the given day, such as time.Monday.
+func (d Date) StartOfWeek(first time.Weekday) Date {
+	return d.AddDays(-((int(d.Weekday()) - int(first) + 7) % 7))
+}
+
+// Quarter returns the quarter of the year of the date, from 1 to 4.
+func (d Date) Quarter() int {
+	return (int(d.Month)-1)/3 + 1
+}
+
+// StartOfQuarter returns the first day of the quarter of the date.
+func (d Date) StartOfQuarter() Date {
+	return Date{Year: d.Year, Month: time.Month(3*(d.Quarter()-1) + 1), Day: 1}
+}
+
+// Value implements the driver.Valuer interface. The value is the result of
+// d.String(), which databases accept for DATE columns.
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan implements the sql.Scanner interface. It accepts a time.Time, whose
+// date is used, or a string or []byte in a format accepted by ParseDate. For
+// nullable columns, scan into a variable of type *Date, which is set to nil
+// for NULL.
+func (d *Date) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case time.Time:
+		*d = DateOf(v)
+		return nil
+	case string:
+		return d.UnmarshalText([]byte(v))
+	case []byte:
+		return d.UnmarshalText(v)
+	}
+	return fmt.Errorf("civil: cannot scan %T into a Date", src)
+}
+
+// Value implements the driver.Valuer interface. The value is the result of
+// t.String().
+func (t Time) Value() (driver.Value, error) {
+	return t.String(), nil
+}
+
+// Scan implements the sql.Scanner interface. It accepts a time.Time, whose
+// time of day is used, or a string or []byte in a format accepted by
+// ParseTime.
+func (t *Time) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case time.Time:
+		*t = TimeOf(v)
+		return nil
+	case string:
+		return t.UnmarshalText([]byte(v))
+	case []byte:
+		return t.UnmarshalText(v)
+	}
+	return fmt.Errorf("civil: cannot scan %T into a Time", src)
+}
+
+// Value implements the driver.Valuer interface. The value is the datetime
+// with a space separating the date and the time, as in
+// "2006-01-02 15:04:05.999999999", which databases accept for DATETIME and
+// TIMESTAMP WITHOUT TIME ZONE columns.
+func (dt DateTime) Value() (driver.Value, error) {
+	return dt.Date.String() + " " + dt.Time.String(), nil
+}
+
+// Scan implements the sql.Scanner interface. It accepts a time.Time, whose
+// date and time of day are used, or a string or []byte in a format accepted
+// by ParseDateTime, where the 'T' may also be a space.
+func (dt *DateTime) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case time.Time:
+		*dt = DateTimeOf(v)
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("civil: cannot scan %T into a DateTime", src)
+	}
+	if len(s) > 10 && s[10] == ' ' {
+		s = s[:10] + "T" + s[11:]
+	}
+	var err error
+	*dt, err = ParseDateTime(s)
+	return err
+}
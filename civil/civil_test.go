@@ -15,6 +15,7 @@
 package civil
 
 import (
+	"database/sql/driver"
 	"encoding/json"
 	"testing"
 	"time"
@@ -589,3 +590,153 @@ func TestUnmarshalJSON(t *testing.T) {
 		}
 	}
 }
+
+func TestLayouts(t *testing.T) {
+	d, err := ParseDateLayout("02/01/2006", "29/07/2014")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Date{2014, 7, 29}); d != want {
+		t.Errorf("got %v, want %v", d, want)
+	}
+	if got, want := d.Format("Jan 2, 2006 (Mon)"), "Jul 29, 2014 (Tue)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	tm, err := ParseTimeLayout("3:04:05.000 PM", "1:02:03.500 PM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Time{13, 2, 3, 500000000}); tm != want {
+		t.Errorf("got %v, want %v", tm, want)
+	}
+	if got, want := tm.Format(time.Kitchen), "1:02PM"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	dt, err := ParseDateTimeLayout(time.RFC1123Z, "Tue, 29 Jul 2014 13:02:03 +0200")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (DateTime{Date{2014, 7, 29}, Time{13, 2, 3, 0}}); dt != want {
+		t.Errorf("got %v, want %v", dt, want)
+	}
+	if got, want := dt.Format("2006-01-02 15:04"), "2014-07-29 13:02"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := ParseDateLayout("2006-01-02", "2014-02-30"); err == nil {
+		t.Error("got nil, want error for an invalid date")
+	}
+}
+
+func TestAddMonths(t *testing.T) {
+	for _, test := range []struct {
+		date   Date
+		months int
+		policy EndOfMonthPolicy
+		want   Date
+	}{
+		{Date{2014, 7, 29}, 1, EndOfMonthClamp, Date{2014, 8, 29}},
+		{Date{2014, 1, 31}, 1, EndOfMonthClamp, Date{2014, 2, 28}},
+		{Date{2016, 1, 31}, 1, EndOfMonthClamp, Date{2016, 2, 29}},
+		{Date{2014, 1, 31}, 1, EndOfMonthOverflow, Date{2014, 3, 3}},
+		{Date{2016, 1, 31}, 1, EndOfMonthOverflow, Date{2016, 3, 2}},
+		{Date{2023, 2, 28}, 1, EndOfMonthPreserve, Date{2023, 3, 31}},
+		{Date{2023, 2, 27}, 1, EndOfMonthPreserve, Date{2023, 3, 27}},
+		{Date{2023, 3, 31}, -1, EndOfMonthPreserve, Date{2023, 2, 28}},
+		{Date{2014, 3, 31}, -13, EndOfMonthClamp, Date{2013, 2, 28}},
+		{Date{2014, 11, 15}, 3, EndOfMonthClamp, Date{2015, 2, 15}},
+	} {
+		if got := test.date.AddMonths(test.months, test.policy); got != test.want {
+			t.Errorf("%v.AddMonths(%d, %d) = %v, want %v", test.date, test.months, test.policy, got, test.want)
+		}
+	}
+	leap := Date{2016, 2, 29}
+	if got, want := leap.AddYears(1, EndOfMonthClamp), (Date{2017, 2, 28}); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := leap.AddYears(1, EndOfMonthOverflow), (Date{2017, 3, 1}); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := leap.AddYears(4, EndOfMonthClamp), (Date{2020, 2, 29}); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestWeeksAndQuarters(t *testing.T) {
+	d := Date{2021, 1, 1} // a Friday
+	if got := d.Weekday(); got != time.Friday {
+		t.Errorf("got %v, want Friday", got)
+	}
+	if y, w := d.ISOWeek(); y != 2020 || w != 53 {
+		t.Errorf("got ISO week %d-%d, want 2020-53", y, w)
+	}
+	if got, want := d.StartOfWeek(time.Monday), (Date{2020, 12, 28}); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := d.StartOfWeek(time.Friday); got != d {
+		t.Errorf("got %v, want %v", got, d)
+	}
+	d = Date{2020, 8, 15}
+	if got := d.Quarter(); got != 3 {
+		t.Errorf("got quarter %d, want 3", got)
+	}
+	if got, want := d.StartOfQuarter(), (Date{2020, 7, 1}); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := (Date{2020, 2, 3}).EndOfMonth(), (Date{2020, 2, 29}); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := (Date{2020, 12, 31}).YearDay(); got != 366 {
+		t.Errorf("got %d, want 366", got)
+	}
+}
+
+func TestSQL(t *testing.T) {
+	d := Date{2014, 7, 29}
+	tm := Time{13, 2, 3, 500}
+	dt := DateTime{d, tm}
+	for _, test := range []struct {
+		v    driver.Valuer
+		want driver.Value
+	}{
+		{d, "2014-07-29"},
+		{tm, "13:02:03.000000500"},
+		{dt, "2014-07-29 13:02:03.000000500"},
+	} {
+		got, err := test.v.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("%v: got %v, want %v", test.v, got, test.want)
+		}
+	}
+
+	ts := time.Date(2014, 7, 29, 13, 2, 3, 500, time.UTC)
+	for _, src := range []interface{}{"2014-07-29", []byte("2014-07-29"), ts} {
+		var got Date
+		if err := got.Scan(src); err != nil || got != d {
+			t.Errorf("Date.Scan(%v) = %v, %v, want %v", src, got, err, d)
+		}
+	}
+	for _, src := range []interface{}{"13:02:03.000000500", []byte("13:02:03.000000500"), ts} {
+		var got Time
+		if err := got.Scan(src); err != nil || got != tm {
+			t.Errorf("Time.Scan(%v) = %v, %v, want %v", src, got, err, tm)
+		}
+	}
+	for _, src := range []interface{}{"2014-07-29 13:02:03.000000500", []byte("2014-07-29T13:02:03.000000500"), ts} {
+		var got DateTime
+		if err := got.Scan(src); err != nil || got != dt {
+			t.Errorf("DateTime.Scan(%v) = %v, %v, want %v", src, got, err, dt)
+		}
+	}
+	if err := new(Date).Scan(int64(1)); err == nil {
+		t.Error("got nil, want error for an int64")
+	}
+	if err := new(DateTime).Scan(nil); err == nil {
+		t.Error("got nil, want error for nil")
+	}
+}
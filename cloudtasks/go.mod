@@ -3,6 +3,8 @@ module cloud.google.com/go/cloudtasks
 go 1.20
 
 require (
+	cloud.google.com/go/auth v0.5.1
+	cloud.google.com/go/compute/metadata v0.5.0
 	cloud.google.com/go/iam v1.1.8
 	github.com/googleapis/gax-go/v2 v2.12.4
 	google.golang.org/api v0.183.0
@@ -14,9 +16,7 @@ require (
 )
 
 require (
-	cloud.google.com/go/auth v0.5.1 // indirect
 	cloud.google.com/go/auth/oauth2adapt v0.2.2 // indirect
-	cloud.google.com/go/compute/metadata v0.3.0 // indirect
 	github.com/felixge/httpsnoop v1.0.4 // indirect
 	github.com/go-logr/logr v1.4.1 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
@@ -34,7 +34,7 @@ require (
 	golang.org/x/net v0.25.0 // indirect
 	golang.org/x/oauth2 v0.21.0 // indirect
 	golang.org/x/sync v0.7.0 // indirect
-	golang.org/x/sys v0.20.0 // indirect
+	golang.org/x/sys v0.22.0 // indirect
 	golang.org/x/text v0.15.0 // indirect
 	golang.org/x/time v0.5.0 // indirect
 )
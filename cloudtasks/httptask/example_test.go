@@ -0,0 +1,52 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httptask_test
+
+import (
+	"context"
+	"time"
+
+	cloudtasks "cloud.google.com/go/cloudtasks/apiv2"
+	"cloud.google.com/go/cloudtasks/httptask"
+)
+
+func ExampleBuilder_NewRequest() {
+	ctx := context.Background()
+	client, err := cloudtasks.NewClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	b, err := httptask.NewBuilder(ctx, "projects/my-project/locations/us-central1/queues/my-queue", nil)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	req, err := b.NewRequest(ctx, &httptask.Task{
+		URL:          "https://my-service-abc123-uc.a.run.app/orders",
+		Payload:      map[string]string{"order": "42"},
+		ID:           httptask.HashID("order-42"),
+		ScheduleTime: time.Now().Add(time.Hour),
+		Token:        &httptask.OIDCToken{},
+	})
+	if err != nil {
+		// TODO: Handle error.
+	}
+	task, err := client.CreateTask(ctx, req)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	_ = task // TODO: Use task.
+}
@@ -0,0 +1,358 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httptask builds Cloud Tasks tasks that send HTTP requests, for use
+// with the client in cloud.google.com/go/cloudtasks/apiv2.
+//
+// A Builder fills in the parts of an HTTP task that are easy to get wrong:
+// the service account and audience of OIDC tokens, the scope of OAuth tokens,
+// the full name of tasks that are deduplicated, the schedule time and the
+// encoding of the body:
+//
+//	b, err := httptask.NewBuilder(ctx, "projects/p/locations/us-central1/queues/q", nil)
+//	if err != nil {
+//		// TODO: Handle error.
+//	}
+//	req, err := b.NewRequest(ctx, &httptask.Task{
+//		URL:     "https://my-service-abc123-uc.a.run.app/work",
+//		Payload: job,
+//		Token:   &httptask.OIDCToken{},
+//	})
+//	if err != nil {
+//		// TODO: Handle error.
+//	}
+//	task, err := client.CreateTask(ctx, req)
+//
+// Tokens that don't name a service account use the service account of the
+// credentials given to NewBuilder, or of the Application Default Credentials,
+// which is looked up when the first such token is built.
+// The caller creating the task needs the iam.serviceAccounts.actAs permission
+// on that service account, and the Cloud Tasks service agent needs the
+// roles/iam.serviceAccountTokenCreator role on it.
+package httptask // import "cloud.google.com/go/cloudtasks/httptask"
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/auth"
+	"cloud.google.com/go/cloudtasks/apiv2/cloudtaskspb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// cloudPlatformScope is the default scope of OAuth tokens.
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+	// maxScheduleDelay is how far in the future a task can be scheduled.
+	maxScheduleDelay = 30 * 24 * time.Hour
+
+	minDispatchDeadline = 15 * time.Second
+	maxDispatchDeadline = 30 * time.Minute
+)
+
+var (
+	queueNameRE = regexp.MustCompile(`^projects/[^/]+/locations/[^/]+/queues/[A-Za-z0-9-]+$`)
+	taskIDRE    = regexp.MustCompile(`^[A-Za-z0-9_-]{1,500}$`)
+
+	// now is replaced in tests.
+	now = time.Now
+)
+
+// Task describes an HTTP task.
+type Task struct {
+	// URL is the full URL that the task sends its request to. It must use the
+	// http or https scheme. Required.
+	URL string
+
+	// Method is the HTTP method of the request. The default is POST.
+	Method string
+
+	// Header holds the headers of the request. Cloud Tasks overrides some of
+	// them, such as User-Agent and Authorization.
+	Header http.Header
+
+	// Body is the body of the request. At most one of Body and Payload can be
+	// set.
+	Body []byte
+
+	// Payload, if not nil, is encoded as JSON to make the body of the
+	// request: with protojson if it is a proto.Message, and with
+	// encoding/json otherwise. The Content-Type header defaults to
+	// application/json.
+	Payload interface{}
+
+	// ID, if not empty, is the ID of the task within its queue. Cloud Tasks
+	// rejects a task whose ID was used by another task of the queue in the
+	// last hour, with the AlreadyExists code, which deduplicates tasks. See
+	// HashID to make IDs from arbitrary keys.
+	ID string
+
+	// ScheduleTime is the time when the task is attempted. If zero, the task
+	// is attempted immediately. It can be at most 30 days in the future.
+	ScheduleTime time.Time
+
+	// DispatchDeadline is how long Cloud Tasks waits for the response to a
+	// request before it cancels it and retries the task. If zero, the
+	// service default of 10 minutes is used. Otherwise it must be between 15
+	// seconds and 30 minutes.
+	DispatchDeadline time.Duration
+
+	// Token, if not nil, is the authorization token that Cloud Tasks sends
+	// with the request.
+	Token Token
+}
+
+// A Token is an authorization token sent with the request of a task. It is
+// either an *OIDCToken or an *OAuthToken.
+type Token interface {
+	set(req *cloudtaskspb.HttpRequest, u *url.URL, serviceAccount func() (string, error)) error
+}
+
+// OIDCToken makes Cloud Tasks send an OpenID Connect token with the request.
+// Use it for targets that validate ID tokens themselves or through Cloud Run,
+// Cloud Functions or Identity-Aware Proxy.
+type OIDCToken struct {
+	// ServiceAccount is the email of the service account that the token is
+	// generated for. If empty, the service account of the Builder is used.
+	ServiceAccount string
+
+	// Audience is the audience of the token. If empty, it is the scheme and
+	// host of the URL of the task, such as https://my-service-abc123-uc.a.run.app,
+	// which is what Cloud Run and Cloud Functions expect. The audience chosen
+	// by Cloud Tasks itself would be the full URL, including its path.
+	Audience string
+}
+
+func (t *OIDCToken) set(req *cloudtaskspb.HttpRequest, u *url.URL, serviceAccount func() (string, error)) error {
+	sa, err := tokenServiceAccount(t.ServiceAccount, serviceAccount)
+	if err != nil {
+		return err
+	}
+	if sa == "" {
+		return errors.New("httptask: OIDC token needs a service account")
+	}
+	aud := t.Audience
+	if aud == "" {
+		aud = u.Scheme + "://" + u.Host
+	}
+	req.AuthorizationHeader = &cloudtaskspb.HttpRequest_OidcToken{
+		OidcToken: &cloudtaskspb.OidcToken{ServiceAccountEmail: sa, Audience: aud},
+	}
+	return nil
+}
+
+// OAuthToken makes Cloud Tasks send an OAuth access token with the request.
+// Use it only for targets on *.googleapis.com.
+type OAuthToken struct {
+	// ServiceAccount is the email of the service account that the token is
+	// generated for. If empty, the service account of the Builder is used.
+	ServiceAccount string
+
+	// Scope is the scope of the token. The default is
+	// https://www.googleapis.com/auth/cloud-platform.
+	Scope string
+}
+
+func (t *OAuthToken) set(req *cloudtaskspb.HttpRequest, u *url.URL, serviceAccount func() (string, error)) error {
+	if !strings.HasSuffix(u.Hostname(), ".googleapis.com") {
+		return fmt.Errorf("httptask: OAuth tokens are only accepted by Google APIs, not by %s; use an OIDC token", u.Host)
+	}
+	sa, err := tokenServiceAccount(t.ServiceAccount, serviceAccount)
+	if err != nil {
+		return err
+	}
+	if sa == "" {
+		return errors.New("httptask: OAuth token needs a service account")
+	}
+	scope := t.Scope
+	if scope == "" {
+		scope = cloudPlatformScope
+	}
+	req.AuthorizationHeader = &cloudtaskspb.HttpRequest_OauthToken{
+		OauthToken: &cloudtaskspb.OAuthToken{ServiceAccountEmail: sa, Scope: scope},
+	}
+	return nil
+}
+
+// tokenServiceAccount returns sa, or the service account of the Builder if sa
+// is empty.
+func tokenServiceAccount(sa string, serviceAccount func() (string, error)) (string, error) {
+	if sa != "" {
+		return sa, nil
+	}
+	return serviceAccount()
+}
+
+// A Builder builds requests that create HTTP tasks in a queue.
+type Builder struct {
+	// Queue is the name of the queue, of the form
+	// projects/PROJECT_ID/locations/LOCATION_ID/queues/QUEUE_ID.
+	Queue string
+
+	// ServiceAccount is the email of the service account of the tokens that
+	// don't name one. If it is empty in a Builder returned by NewBuilder, the
+	// service account of the credentials of the Builder is used.
+	ServiceAccount string
+
+	lookup bool // look up the service account of creds
+	creds  *auth.Credentials
+
+	mu    sync.Mutex
+	found string // the service account of creds, once looked up
+}
+
+// NewBuilder returns a Builder for the named queue whose service account is
+// the one of creds. If creds is nil, the Application Default Credentials are
+// used. See ServiceAccount for the credentials that have a service account.
+// The service account is only looked up when a token doesn't name one, so
+// credentials without a service account, such as user credentials, can build
+// tasks whose tokens name their service account, or that have no token.
+func NewBuilder(ctx context.Context, queue string, creds *auth.Credentials) (*Builder, error) {
+	if !queueNameRE.MatchString(queue) {
+		return nil, fmt.Errorf("httptask: invalid queue name %q", queue)
+	}
+	return &Builder{Queue: queue, lookup: true, creds: creds}, nil
+}
+
+// serviceAccount returns the service account of the tokens that don't name
+// one, or "" if b has none.
+func (b *Builder) serviceAccount(ctx context.Context) (string, error) {
+	if b.ServiceAccount != "" || !b.lookup {
+		return b.ServiceAccount, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.found == "" {
+		sa, err := ServiceAccount(ctx, b.creds)
+		if err != nil {
+			return "", err
+		}
+		b.found = sa
+	}
+	return b.found, nil
+}
+
+// NewRequest returns a request that creates the task in the queue of b. It
+// validates the task as the service would, so that mistakes are reported
+// before the task is created rather than when it is dispatched. If the token
+// of the task doesn't name a service account, and b has none, the service
+// account of the credentials of b is looked up, and NewRequest returns the
+// error of the lookup.
+func (b *Builder) NewRequest(ctx context.Context, t *Task) (*cloudtaskspb.CreateTaskRequest, error) {
+	if !queueNameRE.MatchString(b.Queue) {
+		return nil, fmt.Errorf("httptask: invalid queue name %q", b.Queue)
+	}
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		return nil, fmt.Errorf("httptask: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("httptask: URL %q must be an absolute http or https URL", t.URL)
+	}
+	req := &cloudtaskspb.HttpRequest{Url: t.URL, HttpMethod: cloudtaskspb.HttpMethod_POST}
+	if t.Method != "" {
+		m, ok := cloudtaskspb.HttpMethod_value[strings.ToUpper(t.Method)]
+		if !ok || m == int32(cloudtaskspb.HttpMethod_HTTP_METHOD_UNSPECIFIED) {
+			return nil, fmt.Errorf("httptask: unsupported method %q", t.Method)
+		}
+		req.HttpMethod = cloudtaskspb.HttpMethod(m)
+	}
+	if len(t.Header) > 0 {
+		req.Headers = map[string]string{}
+		for k, vs := range t.Header {
+			req.Headers[http.CanonicalHeaderKey(k)] = strings.Join(vs, ", ")
+		}
+	}
+	if err := setBody(req, t); err != nil {
+		return nil, err
+	}
+	if t.Token != nil {
+		serviceAccount := func() (string, error) { return b.serviceAccount(ctx) }
+		if err := t.Token.set(req, u, serviceAccount); err != nil {
+			return nil, err
+		}
+	}
+
+	task := &cloudtaskspb.Task{
+		MessageType: &cloudtaskspb.Task_HttpRequest{HttpRequest: req},
+	}
+	if t.ID != "" {
+		if !taskIDRE.MatchString(t.ID) {
+			return nil, fmt.Errorf("httptask: invalid task ID %q: it must have 1 to 500 letters, digits, hyphens or underscores", t.ID)
+		}
+		task.Name = b.Queue + "/tasks/" + t.ID
+	}
+	if !t.ScheduleTime.IsZero() {
+		if t.ScheduleTime.After(now().Add(maxScheduleDelay)) {
+			return nil, fmt.Errorf("httptask: schedule time %v is more than 30 days in the future", t.ScheduleTime)
+		}
+		task.ScheduleTime = timestamppb.New(t.ScheduleTime)
+	}
+	if t.DispatchDeadline != 0 {
+		if t.DispatchDeadline < minDispatchDeadline || t.DispatchDeadline > maxDispatchDeadline {
+			return nil, fmt.Errorf("httptask: dispatch deadline %v is not between %v and %v", t.DispatchDeadline, minDispatchDeadline, maxDispatchDeadline)
+		}
+		task.DispatchDeadline = durationpb.New(t.DispatchDeadline)
+	}
+	return &cloudtaskspb.CreateTaskRequest{Parent: b.Queue, Task: task}, nil
+}
+
+func setBody(req *cloudtaskspb.HttpRequest, t *Task) error {
+	if t.Payload == nil {
+		req.Body = t.Body
+		return nil
+	}
+	if t.Body != nil {
+		return errors.New("httptask: Body and Payload are both set")
+	}
+	var err error
+	if m, ok := t.Payload.(proto.Message); ok {
+		req.Body, err = protojson.Marshal(m)
+	} else {
+		req.Body, err = json.Marshal(t.Payload)
+	}
+	if err != nil {
+		return fmt.Errorf("httptask: encoding payload: %w", err)
+	}
+	if req.Headers == nil {
+		req.Headers = map[string]string{}
+	}
+	if _, ok := req.Headers["Content-Type"]; !ok {
+		req.Headers["Content-Type"] = "application/json"
+	}
+	return nil
+}
+
+// HashID returns a task ID made from a hash of key, such as an idempotency
+// key or the ID of the work that the task does. Tasks created with the same
+// key are deduplicated, and hashed IDs avoid the slowdowns that Cloud Tasks
+// has with sequential IDs.
+func HashID(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,197 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httptask
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/auth"
+	"cloud.google.com/go/cloudtasks/apiv2/cloudtaskspb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const queue = "projects/p/locations/us-central1/queues/q"
+
+func TestNewRequest(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return start }
+
+	b := &Builder{Queue: queue, ServiceAccount: "sa@p.iam.gserviceaccount.com"}
+	got, err := b.NewRequest(ctx, &Task{
+		URL:              "https://svc-abc-uc.a.run.app/work?x=1",
+		Header:           http.Header{"x-trace": {"1"}},
+		Payload:          map[string]int{"n": 1},
+		ID:               "job-1",
+		ScheduleTime:     start.Add(time.Hour),
+		DispatchDeadline: time.Minute,
+		Token:            &OIDCToken{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &cloudtaskspb.CreateTaskRequest{
+		Parent: queue,
+		Task: &cloudtaskspb.Task{
+			Name: queue + "/tasks/job-1",
+			MessageType: &cloudtaskspb.Task_HttpRequest{HttpRequest: &cloudtaskspb.HttpRequest{
+				Url:        "https://svc-abc-uc.a.run.app/work?x=1",
+				HttpMethod: cloudtaskspb.HttpMethod_POST,
+				Headers:    map[string]string{"X-Trace": "1", "Content-Type": "application/json"},
+				Body:       []byte(`{"n":1}`),
+				AuthorizationHeader: &cloudtaskspb.HttpRequest_OidcToken{OidcToken: &cloudtaskspb.OidcToken{
+					ServiceAccountEmail: "sa@p.iam.gserviceaccount.com",
+					Audience:            "https://svc-abc-uc.a.run.app",
+				}},
+			}},
+			ScheduleTime:     timestamppb.New(start.Add(time.Hour)),
+			DispatchDeadline: durationpb.New(time.Minute),
+		},
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("got  %v\nwant %v", got, want)
+	}
+
+	// A proto payload, an explicit method and an OAuth token.
+	got, err = b.NewRequest(ctx, &Task{
+		URL:     "https://pubsub.googleapis.com/v1/projects/p/topics/t:publish",
+		Method:  "put",
+		Payload: durationpb.New(time.Second),
+		Token:   &OAuthToken{ServiceAccount: "other@p.iam.gserviceaccount.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := got.Task.GetHttpRequest()
+	if req.HttpMethod != cloudtaskspb.HttpMethod_PUT || string(req.Body) != `"1s"` {
+		t.Errorf("got method %v and body %s", req.HttpMethod, req.Body)
+	}
+	wantToken := &cloudtaskspb.OAuthToken{ServiceAccountEmail: "other@p.iam.gserviceaccount.com", Scope: cloudPlatformScope}
+	if !proto.Equal(req.GetOauthToken(), wantToken) {
+		t.Errorf("got token %v, want %v", req.GetOauthToken(), wantToken)
+	}
+}
+
+func TestNewRequestErrors(t *testing.T) {
+	ctx := context.Background()
+	b := &Builder{Queue: queue}
+	for _, test := range []struct {
+		task *Task
+		want string
+	}{
+		{&Task{URL: "/relative"}, "absolute"},
+		{&Task{URL: "ftp://host/x"}, "absolute"},
+		{&Task{URL: "https://h", Method: "TRACE"}, "unsupported method"},
+		{&Task{URL: "https://h", Body: []byte("x"), Payload: 1}, "both set"},
+		{&Task{URL: "https://h", Token: &OIDCToken{}}, "needs a service account"},
+		{&Task{URL: "https://h.run.app", Token: &OAuthToken{ServiceAccount: "sa"}}, "use an OIDC token"},
+		{&Task{URL: "https://h", ID: "a/b"}, "invalid task ID"},
+		{&Task{URL: "https://h", ScheduleTime: time.Now().Add(31 * 24 * time.Hour)}, "30 days"},
+		{&Task{URL: "https://h", DispatchDeadline: time.Second}, "dispatch deadline"},
+	} {
+		_, err := b.NewRequest(ctx, test.task)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%+v: got %v, want error containing %q", test.task, err, test.want)
+		}
+	}
+	if _, err := (&Builder{Queue: "queues/q"}).NewRequest(ctx, &Task{URL: "https://h"}); err == nil {
+		t.Error("got nil, want error for invalid queue name")
+	}
+}
+
+func TestServiceAccount(t *testing.T) {
+	ctx := context.Background()
+	defer func(f func(context.Context, string) (string, error)) { metadataEmail = f }(metadataEmail)
+	metadataEmail = func(context.Context, string) (string, error) { return "gce@p.iam.gserviceaccount.com", nil }
+
+	for _, test := range []struct {
+		json string
+		want string
+	}{
+		{`{"type": "service_account", "client_email": "key@p.iam.gserviceaccount.com"}`, "key@p.iam.gserviceaccount.com"},
+		{
+			`{"type": "impersonated_service_account", "service_account_impersonation_url": "https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/imp@p.iam.gserviceaccount.com:generateAccessToken"}`,
+			"imp@p.iam.gserviceaccount.com",
+		},
+		{"", "gce@p.iam.gserviceaccount.com"},
+		{`{"type": "authorized_user"}`, ""},
+	} {
+		var b []byte
+		if test.json != "" {
+			b = []byte(test.json)
+		}
+		got, err := ServiceAccount(ctx, auth.NewCredentials(&auth.CredentialsOptions{JSON: b}))
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("%s: got %q, want error", test.json, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("%s: got %q, %v, want %q", test.json, got, err, test.want)
+		}
+	}
+}
+
+func TestNewBuilderUserCredentials(t *testing.T) {
+	ctx := context.Background()
+	creds := auth.NewCredentials(&auth.CredentialsOptions{JSON: []byte(`{"type": "authorized_user"}`)})
+	b, err := NewBuilder(ctx, queue, creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Tasks without a token, or whose token names its service account, don't
+	// need the service account of the credentials.
+	if _, err := b.NewRequest(ctx, &Task{URL: "https://h"}); err != nil {
+		t.Errorf("no token: %v", err)
+	}
+	got, err := b.NewRequest(ctx, &Task{URL: "https://h", Token: &OIDCToken{ServiceAccount: "sa@p.iam.gserviceaccount.com"}})
+	if err != nil {
+		t.Fatalf("token with a service account: %v", err)
+	}
+	if sa := got.Task.GetHttpRequest().GetOidcToken().GetServiceAccountEmail(); sa != "sa@p.iam.gserviceaccount.com" {
+		t.Errorf("got service account %q", sa)
+	}
+	if _, err := b.NewRequest(ctx, &Task{URL: "https://h", Token: &OIDCToken{}}); err == nil || !strings.Contains(err.Error(), "no service account") {
+		t.Errorf("token without a service account: got %v, want the error of the lookup", err)
+	}
+
+	// The service account of the credentials is looked up when needed.
+	b, err = NewBuilder(ctx, queue, auth.NewCredentials(&auth.CredentialsOptions{JSON: []byte(`{"type": "service_account", "client_email": "key@p.iam.gserviceaccount.com"}`)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = b.NewRequest(ctx, &Task{URL: "https://h", Token: &OIDCToken{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sa := got.Task.GetHttpRequest().GetOidcToken().GetServiceAccountEmail(); sa != "key@p.iam.gserviceaccount.com" {
+		t.Errorf("got service account %q, want the one of the credentials", sa)
+	}
+}
+
+func TestHashID(t *testing.T) {
+	id := HashID("order-42")
+	if !taskIDRE.MatchString(id) || id != HashID("order-42") || id == HashID("order-43") {
+		t.Errorf("HashID: got %q", id)
+	}
+}
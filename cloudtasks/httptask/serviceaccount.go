@@ -0,0 +1,76 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httptask
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/auth"
+	"cloud.google.com/go/auth/credentials"
+	"cloud.google.com/go/compute/metadata"
+)
+
+// metadataEmail is replaced in tests.
+var metadataEmail = metadata.EmailWithContext
+
+// ServiceAccount returns the email of the service account of creds, or of
+// the Application Default Credentials if creds is nil. It is the service
+// account of a service account key, the target of impersonated or external
+// account credentials, or the service account of the metadata server when
+// running on Google Cloud. User credentials have no service account, and
+// ServiceAccount returns an error for them.
+func ServiceAccount(ctx context.Context, creds *auth.Credentials) (string, error) {
+	if creds == nil {
+		var err error
+		creds, err = credentials.DetectDefault(&credentials.DetectOptions{
+			Scopes: []string{cloudPlatformScope},
+		})
+		if err != nil {
+			return "", fmt.Errorf("httptask: %w", err)
+		}
+	}
+	b := creds.JSON()
+	if b == nil {
+		// The credentials of the metadata server.
+		email, err := metadataEmail(ctx, "default")
+		if err != nil {
+			return "", fmt.Errorf("httptask: getting the service account from the metadata server: %w", err)
+		}
+		return email, nil
+	}
+	var f struct {
+		Type                           string `json:"type"`
+		ClientEmail                    string `json:"client_email"`
+		ServiceAccountImpersonationURL string `json:"service_account_impersonation_url"`
+	}
+	if err := json.Unmarshal(b, &f); err != nil {
+		return "", fmt.Errorf("httptask: parsing credentials: %w", err)
+	}
+	switch {
+	case f.ClientEmail != "":
+		return f.ClientEmail, nil
+	case f.ServiceAccountImpersonationURL != "":
+		// The URL ends with serviceAccounts/EMAIL:generateAccessToken.
+		_, after, ok := strings.Cut(f.ServiceAccountImpersonationURL, "/serviceAccounts/")
+		email, _, _ := strings.Cut(after, ":")
+		if ok && email != "" {
+			return email, nil
+		}
+	}
+	return "", fmt.Errorf("httptask: credentials of type %q have no service account; set the service account of the token or the Builder", f.Type)
+}
@@ -17,6 +17,7 @@ package metadata_test
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"cloud.google.com/go/compute/metadata"
 )
@@ -46,3 +47,31 @@ func (t userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error)
 	req.Header.Set("User-Agent", t.userAgent)
 	return t.base.RoundTrip(req)
 }
+
+// This example shows how to react to maintenance events of the instance.
+func ExampleWatch() {
+	ctx := context.Background()
+	err := metadata.Watch(ctx, "instance/maintenance-event", func(old, new string) {
+		if new == "MIGRATE_ON_HOST_MAINTENANCE" {
+			// TODO: Prepare for the live migration.
+		}
+	})
+	if err != nil {
+		// TODO: Handle error.
+	}
+}
+
+// This example shows how to fail fast when not running on GCE.
+func ExampleNewWithOptions() {
+	ctx := context.Background()
+	c := metadata.NewWithOptions(&metadata.Options{
+		DialTimeout:      200 * time.Millisecond,
+		RequestTimeout:   time.Second,
+		NegativeCacheTTL: time.Minute,
+	})
+	zone, err := c.ZoneWithContext(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	_ = zone // TODO: Use zone.
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingTransport serves the values in values, and fails with err if it
+// is set.
+type countingTransport struct {
+	values map[string]string
+	err    error
+	calls  int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls++
+	if t.err != nil {
+		return nil, t.err
+	}
+	v, ok := t.values[strings.TrimPrefix(req.URL.Path, "/computeMetadata/v1/")]
+	if !ok {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(v))}, nil
+}
+
+func TestNewWithOptions(t *testing.T) {
+	ctx := context.Background()
+	tr := &countingTransport{values: map[string]string{"instance/zone": "projects/1/zones/us-central1-b\n"}}
+	c := NewWithOptions(&Options{Transport: tr, RequestTimeout: time.Second})
+	if c.hc.Timeout != time.Second {
+		t.Errorf("got timeout %v, want 1s", c.hc.Timeout)
+	}
+	zone, err := c.ZoneWithContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zone != "us-central1-b" {
+		t.Errorf("got zone %q, want us-central1-b", zone)
+	}
+	if c := NewWithOptions(nil); c.hc.Timeout != defaultRequestTimeout {
+		t.Errorf("got default timeout %v, want %v", c.hc.Timeout, defaultRequestTimeout)
+	}
+}
+
+func TestNegativeCache(t *testing.T) {
+	ctx := context.Background()
+	tr := &countingTransport{values: map[string]string{}}
+	c := NewWithOptions(&Options{Transport: tr, NegativeCacheTTL: time.Hour})
+	for i := 0; i < 3; i++ {
+		_, err := c.InstanceAttributeValueWithContext(ctx, "a")
+		if _, ok := err.(NotDefinedError); !ok {
+			t.Fatalf("got %v, want NotDefinedError", err)
+		}
+	}
+	if tr.calls != 1 {
+		t.Errorf("got %d requests for an undefined value, want 1", tr.calls)
+	}
+
+	// Without a TTL, nothing is cached.
+	tr.calls = 0
+	c = NewWithOptions(&Options{Transport: tr})
+	for i := 0; i < 3; i++ {
+		c.InstanceAttributeValueWithContext(ctx, "a")
+	}
+	if tr.calls != 3 {
+		t.Errorf("got %d requests without a cache, want 3", tr.calls)
+	}
+
+	// A server that can't be reached fails all the calls.
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("no route to host")}
+	tr = &countingTransport{err: dialErr}
+	c = NewWithOptions(&Options{Transport: tr, NegativeCacheTTL: time.Hour})
+	for _, suffix := range []string{"instance/id", "instance/zone", "instance/hostname"} {
+		if _, err := c.GetWithContext(ctx, suffix); !errors.Is(err, dialErr) {
+			t.Errorf("%s: got %v, want %v", suffix, err, dialErr)
+		}
+	}
+	if tr.calls != 1 {
+		t.Errorf("got %d requests to an unreachable server, want 1", tr.calls)
+	}
+}
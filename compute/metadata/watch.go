@@ -0,0 +1,104 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	// watchUndefinedPoll is how often Watch checks whether a value that is
+	// not defined has been defined, since the metadata server doesn't wait
+	// for changes of undefined values.
+	watchUndefinedPoll = 5 * time.Second
+
+	newWatchBackoff = func() backoff {
+		return &defaultBackoff{cur: time.Second, max: 30 * time.Second, mul: 2}
+	}
+)
+
+// Watch calls Client.Watch on the default client.
+func Watch(ctx context.Context, suffix string, fn func(old, new string)) error {
+	return defaultClient.Watch(ctx, suffix, fn)
+}
+
+// Watch watches a value of the metadata service, such as
+// "instance/maintenance-event" or "instance/attributes/my-config", until ctx
+// is done. The suffix is appended to
+// "http://${GCE_METADATA_HOST}/computeMetadata/v1/".
+//
+// fn is first called with old set to the empty string and new set to the
+// current value, and then each time the value changes. A value that is not
+// defined is reported as the empty string. Watch uses the long polling of
+// the metadata server, with ETags so that no change is missed between
+// requests. If the server can't be reached, Watch retries with exponential
+// backoff. Watch returns ctx.Err().
+//
+// Unlike SubscribeWithContext, Watch doesn't stop when the value is deleted,
+// and fn can't stop it: cancel ctx instead.
+func (c *Client) Watch(ctx context.Context, suffix string, fn func(old, new string)) error {
+	wait := "?wait_for_change=true"
+	if strings.ContainsRune(suffix, '?') {
+		wait = "&wait_for_change=true"
+	}
+	// Make the server answer before the HTTP client times out. The server
+	// then returns the current value.
+	if t := c.hc.Timeout; t > 0 {
+		sec := int((t - time.Second) / time.Second)
+		if sec < 1 {
+			sec = 1
+		}
+		wait += fmt.Sprintf("&timeout_sec=%d", sec)
+	}
+
+	var cur, etag string
+	first := true
+	var bo backoff
+	for {
+		s := suffix
+		if !first && etag != "" {
+			s += wait + "&last_etag=" + url.QueryEscape(etag)
+		}
+		val, newETag, err := c.getETag(ctx, s)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		_, undefined := err.(NotDefinedError)
+		if err != nil && !undefined {
+			if bo == nil {
+				bo = newWatchBackoff()
+			}
+			if err := sleep(ctx, bo.Pause()); err != nil {
+				return err
+			}
+			continue
+		}
+		bo = nil
+		if first || val != cur {
+			fn(cur, val)
+		}
+		first = false
+		cur, etag = val, newETag
+		if undefined || etag == "" {
+			if err := sleep(ctx, watchUndefinedPoll); err != nil {
+				return err
+			}
+		}
+	}
+}
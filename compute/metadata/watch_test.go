@@ -0,0 +1,135 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// watchServer serves a single metadata value, which is not defined if
+// empty, and implements wait_for_change.
+type watchServer struct {
+	mu      sync.Mutex
+	value   string
+	version int
+	changed chan struct{} // closed when the value changes
+	fail    int           // number of requests to fail
+}
+
+func newWatchServer(t *testing.T, value string) *watchServer {
+	s := &watchServer{value: value, changed: make(chan struct{})}
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	t.Setenv(metadataHostEnv, strings.TrimPrefix(ts.URL, "http://"))
+	return s
+}
+
+func (s *watchServer) set(v string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.value = v
+	s.version++
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *watchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	if s.fail > 0 {
+		s.fail--
+		s.mu.Unlock()
+		http.Error(w, "unavailable", http.StatusBadRequest)
+		return
+	}
+	etag := fmt.Sprint(s.version)
+	changed := s.changed
+	s.mu.Unlock()
+	if r.URL.Query().Get("wait_for_change") == "true" && r.URL.Query().Get("last_etag") == etag {
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.value == "" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Etag", fmt.Sprint(s.version))
+	fmt.Fprint(w, s.value)
+}
+
+func TestWatch(t *testing.T) {
+	defer func(d time.Duration) { watchUndefinedPoll = d }(watchUndefinedPoll)
+	watchUndefinedPoll = time.Millisecond
+	defer func(f func() backoff) { newWatchBackoff = f }(newWatchBackoff)
+	newWatchBackoff = func() backoff { return constantBackoff{} }
+
+	s := newWatchServer(t, "v1")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	type change struct{ old, new string }
+	changes := make(chan change, 10)
+	done := make(chan error)
+	go func() {
+		done <- NewClient(&http.Client{}).Watch(ctx, "instance/attributes/a", func(old, new string) {
+			changes <- change{old, new}
+		})
+	}()
+	for _, want := range []change{{"", "v1"}, {"v1", "v2"}, {"v2", ""}, {"", "v3"}} {
+		if want.old != "" || want.new != "v1" {
+			s.set(want.new)
+		}
+		if got := <-changes; got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+
+	// The requests following the change to v4 fail, and are retried.
+	s.mu.Lock()
+	s.fail = 2
+	s.mu.Unlock()
+	s.set("v4")
+	if got, want := <-changes, (change{"v3", "v4"}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	for {
+		s.mu.Lock()
+		fail := s.fail
+		s.mu.Unlock()
+		if fail == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	s.set("v5")
+	if got, want := <-changes, (change{"v4", "v5"}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
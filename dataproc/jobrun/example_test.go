@@ -0,0 +1,58 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobrun_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	dataproc "cloud.google.com/go/dataproc/v2/apiv1"
+	"cloud.google.com/go/dataproc/v2/apiv1/dataprocpb"
+	"cloud.google.com/go/dataproc/v2/jobrun"
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+func ExampleRun() {
+	ctx := context.Background()
+	region := "us-central1"
+	client, err := dataproc.NewJobControllerClient(ctx, option.WithEndpoint(region+"-dataproc.googleapis.com:443"))
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+	storageClient, err := storage.NewClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer storageClient.Close()
+
+	job := jobrun.SparkJob("my-cluster", "org.apache.spark.examples.SparkPi",
+		[]string{"file:///usr/lib/spark/examples/jars/spark-examples.jar"}, "1000")
+	job, err = jobrun.Run(ctx, client, "my-project", region, job, &jobrun.Config{
+		OnState: func(j *dataprocpb.Job) { fmt.Fprintln(os.Stderr, "job is", j.Status.State) },
+		Output:  os.Stdout,
+		Storage: storageClient,
+	})
+	var je *jobrun.JobError
+	if errors.As(err, &je) {
+		// TODO: Handle the failure of the job, whose cause is je.Cause.
+	} else if err != nil {
+		// TODO: Handle error.
+	}
+	_ = job
+}
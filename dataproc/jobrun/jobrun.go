@@ -0,0 +1,242 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jobrun submits Dataproc jobs with the JobControllerClient in
+// cloud.google.com/go/dataproc/v2/apiv1, and waits for them to finish while
+// streaming the output of their driver.
+//
+//	job := jobrun.PySparkJob("my-cluster", "gs://my-bucket/wordcount.py", nil, "gs://my-bucket/input/")
+//	job, err := jobrun.Run(ctx, client, "my-project", "us-central1", job, &jobrun.Config{
+//		Output:  os.Stdout,
+//		Storage: storageClient,
+//	})
+//	var je *jobrun.JobError
+//	if errors.As(err, &je) && je.Cause == jobrun.CauseDriver {
+//		// TODO: Handle the failure of the driver.
+//	}
+package jobrun // import "cloud.google.com/go/dataproc/v2/jobrun"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	dataproc "cloud.google.com/go/dataproc/v2/apiv1"
+	"cloud.google.com/go/dataproc/v2/apiv1/dataprocpb"
+	"cloud.google.com/go/storage"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+// Config configures the waiting for a job.
+type Config struct {
+	// Backoff is the backoff between two checks of the state of the job. The
+	// default starts at one second, and doubles up to 30 seconds. It restarts
+	// when the state of the job changes, or the driver writes output.
+	Backoff *gax.Backoff
+
+	// OnState, if set, is called with the job each time its state changes.
+	OnState func(*dataprocpb.Job)
+
+	// Output, if set, is written the output of the driver of the job, as it
+	// is written to the DriverOutputResourceUri of the job.
+	Output io.Writer
+
+	// Storage is the client that reads the output of the driver. It is
+	// required if Output is set.
+	Storage *storage.Client
+}
+
+// A Cause is the cause of the failure of a job.
+type Cause int
+
+const (
+	// CauseUnknown is the cause of failures that aren't otherwise known.
+	CauseUnknown Cause = iota
+
+	// CauseCancelled means that the job was cancelled.
+	CauseCancelled
+
+	// CauseNotStarted means that the job failed before its driver ran, for
+	// example because a file of the job doesn't exist.
+	CauseNotStarted
+
+	// CauseDriver means that the driver of the job failed.
+	CauseDriver
+
+	// CauseAgentLost means that the Dataproc agent stopped reporting the
+	// status of the job, for example because the master node of the cluster
+	// failed.
+	CauseAgentLost
+)
+
+func (c Cause) String() string {
+	switch c {
+	case CauseCancelled:
+		return "cancelled"
+	case CauseNotStarted:
+		return "failed before the driver ran"
+	case CauseDriver:
+		return "driver failed"
+	case CauseAgentLost:
+		return "agent lost"
+	default:
+		return "unknown"
+	}
+}
+
+// A JobError is returned when a job doesn't succeed.
+type JobError struct {
+	// Job is the finished job.
+	Job *dataprocpb.Job
+
+	// Cause is the cause of the failure.
+	Cause Cause
+}
+
+func (e *JobError) Error() string {
+	msg := fmt.Sprintf("jobrun: job %s %s (%s)", e.Job.GetReference().GetJobId(), e.Job.GetStatus().GetState(), e.Cause)
+	if d := e.Job.GetStatus().GetDetails(); d != "" {
+		msg += ": " + d
+	}
+	return msg
+}
+
+// cause returns the cause of the failure of a job, from its status history.
+func cause(job *dataprocpb.Job) Cause {
+	if job.GetStatus().GetState() == dataprocpb.JobStatus_CANCELLED {
+		return CauseCancelled
+	}
+	if job.GetStatus().GetState() != dataprocpb.JobStatus_ERROR {
+		return CauseUnknown
+	}
+	statuses := append([]*dataprocpb.JobStatus{job.Status}, job.StatusHistory...)
+	for _, s := range statuses {
+		if s.GetSubstate() == dataprocpb.JobStatus_STALE_STATUS {
+			return CauseAgentLost
+		}
+	}
+	for _, s := range statuses {
+		if s.GetState() == dataprocpb.JobStatus_RUNNING {
+			return CauseDriver
+		}
+	}
+	return CauseNotStarted
+}
+
+// jobClient is implemented by *dataproc.JobControllerClient.
+type jobClient interface {
+	SubmitJob(ctx context.Context, req *dataprocpb.SubmitJobRequest, opts ...gax.CallOption) (*dataprocpb.Job, error)
+	GetJob(ctx context.Context, req *dataprocpb.GetJobRequest, opts ...gax.CallOption) (*dataprocpb.Job, error)
+}
+
+// Run submits a job to a project and region, and waits for it to finish as
+// Wait does. cfg may be nil.
+func Run(ctx context.Context, client *dataproc.JobControllerClient, project, region string, job *dataprocpb.Job, cfg *Config) (*dataprocpb.Job, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	objects, err := cfg.objects()
+	if err != nil {
+		return nil, err
+	}
+	return run(ctx, client, objects, project, region, job, cfg)
+}
+
+func run(ctx context.Context, client jobClient, objects objectReader, project, region string, job *dataprocpb.Job, cfg *Config) (*dataprocpb.Job, error) {
+	job, err := client.SubmitJob(ctx, &dataprocpb.SubmitJobRequest{ProjectId: project, Region: region, Job: job})
+	if err != nil {
+		return nil, err
+	}
+	return wait(ctx, client, objects, project, region, job.GetReference().GetJobId(), cfg)
+}
+
+// Wait waits for a job to finish, and returns it. It returns a *JobError if
+// the job doesn't succeed. If it returns another error, such as when ctx is
+// done, the job keeps running. cfg may be nil.
+func Wait(ctx context.Context, client *dataproc.JobControllerClient, project, region, jobID string, cfg *Config) (*dataprocpb.Job, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	objects, err := cfg.objects()
+	if err != nil {
+		return nil, err
+	}
+	return wait(ctx, client, objects, project, region, jobID, cfg)
+}
+
+func (cfg *Config) objects() (objectReader, error) {
+	if cfg.Output == nil {
+		return nil, nil
+	}
+	if cfg.Storage == nil {
+		return nil, errors.New("jobrun: Config.Storage is required with Config.Output")
+	}
+	return storageReader{cfg.Storage}, nil
+}
+
+func (cfg *Config) backoff() gax.Backoff {
+	if cfg.Backoff != nil {
+		return *cfg.Backoff
+	}
+	return gax.Backoff{Initial: time.Second, Max: 30 * time.Second, Multiplier: 2}
+}
+
+func wait(ctx context.Context, client jobClient, objects objectReader, project, region, jobID string, cfg *Config) (*dataprocpb.Job, error) {
+	bo := cfg.backoff()
+	var (
+		state dataprocpb.JobStatus_State
+		out   *outputStream
+	)
+	for {
+		job, err := client.GetJob(ctx, &dataprocpb.GetJobRequest{ProjectId: project, Region: region, JobId: jobID})
+		if err != nil {
+			return nil, err
+		}
+		progressed := job.GetStatus().GetState() != state
+		state = job.GetStatus().GetState()
+		if progressed && cfg.OnState != nil {
+			cfg.OnState(job)
+		}
+		if cfg.Output != nil && job.DriverOutputResourceUri != "" {
+			if out == nil {
+				if out, err = newOutputStream(objects, job.DriverOutputResourceUri, cfg.Output); err != nil {
+					return nil, err
+				}
+			}
+			n, err := out.copy(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("jobrun: reading driver output: %w", err)
+			}
+			progressed = progressed || n > 0
+		}
+		if done(state) {
+			if state != dataprocpb.JobStatus_DONE {
+				return job, &JobError{Job: job, Cause: cause(job)}
+			}
+			return job, nil
+		}
+		if progressed {
+			bo = cfg.backoff()
+		}
+		if err := gax.Sleep(ctx, bo.Pause()); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func done(s dataprocpb.JobStatus_State) bool {
+	return s == dataprocpb.JobStatus_DONE || s == dataprocpb.JobStatus_ERROR || s == dataprocpb.JobStatus_CANCELLED
+}
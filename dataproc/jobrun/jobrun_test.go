@@ -0,0 +1,186 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobrun
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/dataproc/v2/apiv1/dataprocpb"
+	"cloud.google.com/go/storage"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+const outputURI = "gs://bucket/metainfo/jobs/j1/driveroutput"
+
+// fakeObjects is an objectReader of objects in memory.
+type fakeObjects map[string][]byte
+
+func (f fakeObjects) size(_ context.Context, bucket, name string) (int64, error) {
+	b, ok := f[bucket+"/"+name]
+	if !ok {
+		return 0, fmt.Errorf("%s: %w", name, storage.ErrObjectNotExist)
+	}
+	return int64(len(b)), nil
+}
+
+func (f fakeObjects) read(_ context.Context, bucket, name string, offset, length int64) ([]byte, error) {
+	return f[bucket+"/"+name][offset : offset+length], nil
+}
+
+// fakeJobs returns jobs in turn from GetJob, after calling the step of each
+// job, which may write driver output.
+type fakeJobs struct {
+	submitted *dataprocpb.SubmitJobRequest
+	jobs      []*dataprocpb.Job
+	steps     []func()
+	gets      int
+}
+
+func (f *fakeJobs) SubmitJob(_ context.Context, req *dataprocpb.SubmitJobRequest, _ ...gax.CallOption) (*dataprocpb.Job, error) {
+	f.submitted = req
+	return &dataprocpb.Job{Reference: &dataprocpb.JobReference{JobId: "j1"}}, nil
+}
+
+func (f *fakeJobs) GetJob(_ context.Context, req *dataprocpb.GetJobRequest, _ ...gax.CallOption) (*dataprocpb.Job, error) {
+	if req.JobId != "j1" {
+		return nil, fmt.Errorf("unknown job %q", req.JobId)
+	}
+	i := f.gets
+	if i >= len(f.jobs) {
+		i = len(f.jobs) - 1
+	}
+	f.gets++
+	if i < len(f.steps) && f.steps[i] != nil {
+		f.steps[i]()
+	}
+	return f.jobs[i], nil
+}
+
+func status(state dataprocpb.JobStatus_State) *dataprocpb.JobStatus {
+	return &dataprocpb.JobStatus{State: state}
+}
+
+func job(state dataprocpb.JobStatus_State, history ...*dataprocpb.JobStatus) *dataprocpb.Job {
+	return &dataprocpb.Job{
+		Reference:               &dataprocpb.JobReference{JobId: "j1"},
+		Status:                  status(state),
+		StatusHistory:           history,
+		DriverOutputResourceUri: outputURI,
+	}
+}
+
+var fastBackoff = &gax.Backoff{Initial: time.Microsecond, Max: time.Microsecond}
+
+func TestRun(t *testing.T) {
+	objects := fakeObjects{}
+	obj := func(i int) string { return fmt.Sprintf("bucket/metainfo/jobs/j1/driveroutput.%09d", i) }
+	jobs := &fakeJobs{
+		jobs: []*dataprocpb.Job{
+			job(dataprocpb.JobStatus_PENDING),
+			job(dataprocpb.JobStatus_RUNNING),
+			job(dataprocpb.JobStatus_RUNNING),
+			job(dataprocpb.JobStatus_RUNNING),
+			job(dataprocpb.JobStatus_DONE),
+		},
+		steps: []func(){
+			nil,
+			func() { objects[obj(0)] = []byte("starting\n") },
+			func() { objects[obj(0)] = []byte("starting\nstage 1\n") },
+			func() {
+				objects[obj(0)] = []byte("starting\nstage 1\nstage 2\n")
+				objects[obj(1)] = []byte("stage 3\n")
+			},
+			func() { objects[obj(1)] = []byte("stage 3\ndone\n") },
+		},
+	}
+	var (
+		out    bytes.Buffer
+		states []dataprocpb.JobStatus_State
+	)
+	cfg := &Config{
+		Backoff: fastBackoff,
+		OnState: func(j *dataprocpb.Job) { states = append(states, j.Status.State) },
+		Output:  &out,
+	}
+	spark := SparkJob("cluster", "org.example.Main", []string{"gs://bucket/main.jar"}, "a")
+	got, err := run(context.Background(), jobs, objects, "project", "region", spark, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.State != dataprocpb.JobStatus_DONE {
+		t.Errorf("got state %v, want DONE", got.Status.State)
+	}
+	if jobs.submitted.Job != spark || jobs.submitted.ProjectId != "project" || jobs.submitted.Region != "region" {
+		t.Errorf("submitted %v", jobs.submitted)
+	}
+	if want := "starting\nstage 1\nstage 2\nstage 3\ndone\n"; out.String() != want {
+		t.Errorf("got output %q, want %q", out.String(), want)
+	}
+	wantStates := []dataprocpb.JobStatus_State{dataprocpb.JobStatus_PENDING, dataprocpb.JobStatus_RUNNING, dataprocpb.JobStatus_DONE}
+	if fmt.Sprint(states) != fmt.Sprint(wantStates) {
+		t.Errorf("got states %v, want %v", states, wantStates)
+	}
+}
+
+func TestWaitFailure(t *testing.T) {
+	for _, test := range []struct {
+		job  *dataprocpb.Job
+		want Cause
+	}{
+		{job(dataprocpb.JobStatus_CANCELLED, status(dataprocpb.JobStatus_RUNNING)), CauseCancelled},
+		{job(dataprocpb.JobStatus_ERROR, status(dataprocpb.JobStatus_PENDING)), CauseNotStarted},
+		{job(dataprocpb.JobStatus_ERROR, status(dataprocpb.JobStatus_PENDING), status(dataprocpb.JobStatus_RUNNING)), CauseDriver},
+		{job(dataprocpb.JobStatus_ERROR, status(dataprocpb.JobStatus_PENDING), &dataprocpb.JobStatus{State: dataprocpb.JobStatus_RUNNING, Substate: dataprocpb.JobStatus_STALE_STATUS}), CauseAgentLost},
+	} {
+		test.job.Status.Details = "boom"
+		jobs := &fakeJobs{jobs: []*dataprocpb.Job{test.job}}
+		_, err := wait(context.Background(), jobs, nil, "p", "r", "j1", &Config{Backoff: fastBackoff})
+		var je *JobError
+		if !errors.As(err, &je) {
+			t.Errorf("%v: got error %v, want a *JobError", test.want, err)
+			continue
+		}
+		if je.Cause != test.want {
+			t.Errorf("got cause %v, want %v", je.Cause, test.want)
+		}
+		if !strings.HasSuffix(je.Error(), ": boom") {
+			t.Errorf("got error %q, want the details of the status", je.Error())
+		}
+	}
+}
+
+func TestWaitContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	jobs := &fakeJobs{
+		jobs:  []*dataprocpb.Job{job(dataprocpb.JobStatus_RUNNING)},
+		steps: []func(){cancel},
+	}
+	_, err := wait(ctx, jobs, nil, "p", "r", "j1", &Config{Backoff: &gax.Backoff{Initial: time.Hour}})
+	if err != context.Canceled {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
+
+func TestConfigObjects(t *testing.T) {
+	if _, err := (&Config{Output: &bytes.Buffer{}}).objects(); err == nil {
+		t.Error("got no error for Output without Storage")
+	}
+}
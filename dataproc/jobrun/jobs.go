@@ -0,0 +1,48 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobrun
+
+import "cloud.google.com/go/dataproc/v2/apiv1/dataprocpb"
+
+// SparkJob returns a Spark job that runs on a cluster the main method of
+// mainClass, which is in one of jarURIs, with args.
+func SparkJob(cluster, mainClass string, jarURIs []string, args ...string) *dataprocpb.Job {
+	return &dataprocpb.Job{
+		Placement: &dataprocpb.JobPlacement{ClusterName: cluster},
+		TypeJob: &dataprocpb.Job_SparkJob{
+			SparkJob: &dataprocpb.SparkJob{
+				Driver:      &dataprocpb.SparkJob_MainClass{MainClass: mainClass},
+				JarFileUris: jarURIs,
+				Args:        args,
+			},
+		},
+	}
+}
+
+// PySparkJob returns a PySpark job that runs on a cluster the Python file at
+// mainURI, with the Python files at pyURIs, such as .py, .egg or .zip files,
+// and with args.
+func PySparkJob(cluster, mainURI string, pyURIs []string, args ...string) *dataprocpb.Job {
+	return &dataprocpb.Job{
+		Placement: &dataprocpb.JobPlacement{ClusterName: cluster},
+		TypeJob: &dataprocpb.Job_PysparkJob{
+			PysparkJob: &dataprocpb.PySparkJob{
+				MainPythonFileUri: mainURI,
+				PythonFileUris:    pyURIs,
+				Args:              args,
+			},
+		},
+	}
+}
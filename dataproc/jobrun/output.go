@@ -0,0 +1,116 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobrun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"cloud.google.com/go/storage"
+)
+
+// objectReader reads the objects of Cloud Storage.
+type objectReader interface {
+	// size returns the size of an object, or an error wrapping
+	// storage.ErrObjectNotExist if it doesn't exist.
+	size(ctx context.Context, bucket, name string) (int64, error)
+	read(ctx context.Context, bucket, name string, offset, length int64) ([]byte, error)
+}
+
+type storageReader struct {
+	c *storage.Client
+}
+
+func (s storageReader) size(ctx context.Context, bucket, name string) (int64, error) {
+	attrs, err := s.c.Bucket(bucket).Object(name).Attrs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return attrs.Size, nil
+}
+
+func (s storageReader) read(ctx context.Context, bucket, name string, offset, length int64) ([]byte, error) {
+	r, err := s.c.Bucket(bucket).Object(name).NewRangeReader(ctx, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// outputStream copies the output of a driver, which Dataproc writes to the
+// objects {uri}.000000000, {uri}.000000001, and so on. An object is complete
+// once the next one exists.
+type outputStream struct {
+	objects      objectReader
+	bucket, name string
+	w            io.Writer
+	index        int   // of the object being read
+	offset       int64 // in the object being read
+}
+
+func newOutputStream(objects objectReader, uri string, w io.Writer) (*outputStream, error) {
+	rest, ok := strings.CutPrefix(uri, "gs://")
+	if !ok {
+		return nil, fmt.Errorf("jobrun: driver output %q is not a gs:// URI", uri)
+	}
+	bucket, name, _ := strings.Cut(rest, "/")
+	return &outputStream{objects: objects, bucket: bucket, name: name, w: w}, nil
+}
+
+func (s *outputStream) object(i int) string {
+	return fmt.Sprintf("%s.%09d", s.name, i)
+}
+
+// copy copies the output written since the last call, and returns its
+// number of bytes.
+func (s *outputStream) copy(ctx context.Context) (int64, error) {
+	var n int64
+	for {
+		// Whether the next object exists is checked first, so that the
+		// object being read is complete if it does.
+		_, err := s.objects.size(ctx, s.bucket, s.object(s.index+1))
+		next := err == nil
+		if err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+			return n, err
+		}
+		size, err := s.objects.size(ctx, s.bucket, s.object(s.index))
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if size > s.offset {
+			b, err := s.objects.read(ctx, s.bucket, s.object(s.index), s.offset, size-s.offset)
+			if err != nil {
+				return n, err
+			}
+			if _, err := s.w.Write(b); err != nil {
+				return n, err
+			}
+			s.offset += int64(len(b))
+			n += int64(len(b))
+		}
+		if !next {
+			return n, nil
+		}
+		s.index++
+		s.offset = 0
+	}
+}
@@ -247,9 +247,10 @@ func keyToProto(k *Key) *pb.Key {
 	}
 
 	key := &pb.Key{Path: path}
-	if k.Namespace != "" {
+	if k.Namespace != "" || k.Database != "" {
 		key.PartitionId = &pb.PartitionId{
 			NamespaceId: k.Namespace,
+			DatabaseId:  k.Database,
 		}
 	}
 	return key
@@ -263,6 +264,9 @@ func protoToKey(p *pb.Key) (*Key, error) {
 	var namespace string
 	if partition := p.PartitionId; partition != nil {
 		namespace = partition.NamespaceId
+		// The database ID is not copied: the service only returns keys of the
+		// database of the request, which is the database of the Client. Leaving
+		// it empty keeps the keys equal to the ones built by the caller.
 	}
 	for _, el := range p.Path {
 		key = &Key{
@@ -362,6 +366,35 @@ func checkMultiArg(v reflect.Value) (m multiArgType, elemType reflect.Type) {
 	return multiArgTypeInvalid, nil
 }
 
+// Database returns a client for the database with the given ID in the same
+// project. Use DefaultDatabaseID for the default database.
+//
+// The returned client shares the connection of c, so it is cheap to create one
+// per call. It starts with a copy of the read options of c; changing them with
+// WithReadOptions on either client does not affect the other. Transactions, queries and key allocations
+// made with the returned client use the given database. Closing either client
+// closes the shared connection; only close the client that was created with
+// NewClient or NewClientWithDatabase.
+func (c *Client) Database(databaseID string) *Client {
+	rs := &readSettings{}
+	if c.readSettings != nil {
+		*rs = *c.readSettings
+	}
+	return &Client{
+		connPool:     c.connPool,
+		client:       newDatastoreClient(c.connPool, c.dataset, databaseID),
+		dataset:      c.dataset,
+		databaseID:   databaseID,
+		readSettings: rs,
+	}
+}
+
+// DatabaseID returns the ID of the database used by the client. The default
+// database has the ID DefaultDatabaseID.
+func (c *Client) DatabaseID() string {
+	return c.databaseID
+}
+
 // Close closes the Client. Call Close to clean up resources when done with the
 // Client.
 func (c *Client) Close() error {
@@ -388,15 +421,7 @@ func (c *Client) Get(ctx context.Context, key *Key, dst interface{}) (err error)
 		return fmt.Errorf("%w: dst cannot be nil", ErrInvalidEntityType)
 	}
 
-	var opts *pb.ReadOptions
-	if !c.readSettings.readTime.IsZero() {
-		opts = &pb.ReadOptions{
-			ConsistencyType: &pb.ReadOptions_ReadTime{
-				// Timestamp cannot be less than microseconds accuracy. See #6938
-				ReadTime: &timestamppb.Timestamp{Seconds: c.readSettings.readTime.Unix()},
-			},
-		}
-	}
+	opts := c.readOptions()
 
 	// Since opts does not contain Transaction option, 'get' call below will return nil
 	// as transaction id which can be ignored
@@ -423,15 +448,7 @@ func (c *Client) GetMulti(ctx context.Context, keys []*Key, dst interface{}) (er
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/datastore.GetMulti")
 	defer func() { trace.EndSpan(ctx, err) }()
 
-	var opts *pb.ReadOptions
-	if c.readSettings != nil && !c.readSettings.readTime.IsZero() {
-		opts = &pb.ReadOptions{
-			ConsistencyType: &pb.ReadOptions_ReadTime{
-				// Timestamp cannot be less than microseconds accuracy. See #6938
-				ReadTime: &timestamppb.Timestamp{Seconds: c.readSettings.readTime.Unix()},
-			},
-		}
-	}
+	opts := c.readOptions()
 
 	// Since opts does not contain Transaction option, 'get' call below will return nil
 	// as transaction id which can be ignored
@@ -439,6 +456,20 @@ func (c *Client) GetMulti(ctx context.Context, keys []*Key, dst interface{}) (er
 	return err
 }
 
+// readOptions returns the read options for non-transactional reads, or nil
+// if the client has no read settings.
+func (c *Client) readOptions() *pb.ReadOptions {
+	if c.readSettings == nil || c.readSettings.readTime.IsZero() {
+		return nil
+	}
+	return &pb.ReadOptions{
+		ConsistencyType: &pb.ReadOptions_ReadTime{
+			// Timestamp cannot be less than microseconds accuracy. See #6938
+			ReadTime: &timestamppb.Timestamp{Seconds: c.readSettings.readTime.Unix()},
+		},
+	}
+}
+
 func (c *Client) get(ctx context.Context, keys []*Key, dst interface{}, opts *pb.ReadOptions) ([]byte, error) {
 	v := reflect.ValueOf(dst)
 
@@ -477,6 +508,22 @@ func (c *Client) get(ctx context.Context, keys []*Key, dst interface{}, opts *pb
 		return nil, fmt.Errorf("%w: key length = %d, dst length = %d", ErrDifferentKeyAndDstLength, keysLen, v.Len())
 	}
 
+	return c.lookup(ctx, keys, opts, func(index int, e *pb.Entity) error {
+		elem := v.Index(index)
+		if multiArgType == multiArgTypePropertyLoadSaver || multiArgType == multiArgTypeStruct {
+			elem = elem.Addr()
+		}
+		if multiArgType == multiArgTypeStructPtr && elem.IsNil() {
+			elem.Set(reflect.New(elem.Type().Elem()))
+		}
+		return loadEntityProto(elem.Interface(), e)
+	})
+}
+
+// lookup looks up the entities for keys and calls load with the index of each
+// key whose entity was found. Errors returned by load are reported in a
+// MultiError, at the index of the key.
+func (c *Client) lookup(ctx context.Context, keys []*Key, opts *pb.ReadOptions, load func(index int, e *pb.Entity) error) ([]byte, error) {
 	if len(keys) == 0 {
 		return nil, nil
 	}
@@ -540,14 +587,7 @@ func (c *Client) get(ctx context.Context, keys []*Key, dst interface{}, opts *pb
 		}
 		filled += len(keyMap[k.String()])
 		for _, index := range keyMap[k.String()] {
-			elem := v.Index(index)
-			if multiArgType == multiArgTypePropertyLoadSaver || multiArgType == multiArgTypeStruct {
-				elem = elem.Addr()
-			}
-			if multiArgType == multiArgTypeStructPtr && elem.IsNil() {
-				elem.Set(reflect.New(elem.Type().Elem()))
-			}
-			if err := loadEntityProto(elem.Interface(), e.Entity); err != nil {
+			if err := load(index, e.Entity); err != nil {
 				multiErr[index] = err
 				any = true
 			}
@@ -83,6 +83,84 @@ func TestNewClientWithDatabase(t *testing.T) {
 	}
 }
 
+func TestClientDatabaseReadOptions(t *testing.T) {
+	client, _, cleanup := newMock(t)
+	defer cleanup()
+
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	client.WithReadOptions(ReadTime(tm))
+	db1 := client.Database("db1")
+	if got := db1.readSettings.readTime; !got.Equal(tm) {
+		t.Errorf("got read time %v for the derived client, want %v", got, tm)
+	}
+	db1.WithReadOptions(ReadTime(tm.Add(time.Hour)))
+	if got := client.readSettings.readTime; !got.Equal(tm) {
+		t.Errorf("got read time %v for the original client, want %v", got, tm)
+	}
+}
+
+func TestClientDatabase(t *testing.T) {
+	ctx := context.Background()
+	client, srv, cleanup := newMock(t)
+	defer cleanup()
+
+	db1 := client.Database("db1")
+	if got := db1.DatabaseID(); got != "db1" {
+		t.Errorf("got database %q, want db1", got)
+	}
+	if got := client.DatabaseID(); got != DefaultDatabaseID {
+		t.Errorf("got database %q for the original client, want the default database", got)
+	}
+
+	k := NameKey("Gopher", "g", nil)
+	srv.addRPC(&pb.LookupRequest{
+		ProjectId:  "projectID",
+		DatabaseId: "db1",
+		Keys:       []*pb.Key{keyToProto(k)},
+	}, &pb.LookupResponse{
+		Found: []*pb.EntityResult{{
+			Entity: &pb.Entity{
+				Key: &pb.Key{
+					PartitionId: &pb.PartitionId{ProjectId: "projectID", DatabaseId: "db1"},
+					Path:        keyToProto(k).Path,
+				},
+				Properties: map[string]*pb.Value{"A": {ValueType: &pb.Value_IntegerValue{IntegerValue: 1}}},
+			},
+			Version: 1,
+		}},
+	})
+	var dst struct {
+		A int
+		K *Key `datastore:"__key__"`
+	}
+	if err := db1.Get(ctx, k, &dst); err != nil {
+		t.Fatal(err)
+	}
+	// Keys returned by the service are relative to the database of the client.
+	if !dst.K.Equal(k) {
+		t.Errorf("got key %+v, want %+v", dst.K, k)
+	}
+	if child := (&Key{Kind: "Child", Name: "c", Parent: dst.K}); !child.valid() {
+		t.Errorf("got invalid child key %+v of a returned key", child)
+	}
+
+	// Keys with an explicit database are sent with it.
+	k = &Key{Kind: "Gopher", Name: "g", Database: "db1"}
+	srv.addRPC(&pb.LookupRequest{
+		ProjectId:  "projectID",
+		DatabaseId: "db1",
+		Keys:       []*pb.Key{{PartitionId: &pb.PartitionId{DatabaseId: "db1"}, Path: keyToProto(k).Path}},
+	}, &pb.LookupResponse{
+		Missing: []*pb.EntityResult{{Entity: &pb.Entity{Key: keyToProto(k)}}},
+	})
+	if err := db1.Get(ctx, k, &dst); err != ErrNoSuchEntity {
+		t.Errorf("got %v, want ErrNoSuchEntity", err)
+	}
+	if child := NameKey("Child", "c", k); child.Database != "db1" {
+		t.Errorf("got database %q for child key, want db1", child.Database)
+	}
+}
+
 func TestQueryConstruction(t *testing.T) {
 	tests := []struct {
 		q, exp *Query
@@ -94,6 +94,20 @@ NewUpsert and NewDelete and applies them. Datastore.Mutate uses
 non-transactional mode; if atomicity is required, use Transaction.Mutate
 instead.
 
+A Client reads and writes the default database of a project, unless it was
+created with NewClientWithDatabase. Client.Database returns a client for another
+named database that shares the original client's connection, so it can be used
+for individual calls:
+
+	archive := dsClient.Database("archive")
+	if _, err := archive.Put(ctx, k, e); err != nil {
+		// Handle error.
+	}
+
+Keys are relative to the database of the client they are used with, so keys
+read from a named database have an empty Database field, like the keys built
+with NameKey or IDKey.
+
 # Properties
 
 An entity's contents can be represented by a variety of types. These are
@@ -454,6 +468,31 @@ Example code:
 		}
 	}
 
+Aggregation queries compute a count, sum or average over the results of a
+query without retrieving the entities. Create one with Query.NewAggregationQuery
+and add aggregations with WithCount, WithCountUpTo, WithSum and WithAvg. The
+result of Client.RunAggregationQuery maps each alias to a *datastorepb.Value.
+Aggregation queries run in the transaction of the underlying query, if any,
+and otherwise honor the read time set with Client.WithReadOptions.
+
+The generic functions GetTyped, GetMultiTyped, PutTyped and Iterate work with
+values of a concrete struct type rather than interface{} destinations, so the
+type of the values is checked at compile time. They encode and decode entities
+with the same reflection-based code and struct tag rules as Get and Put, and
+set a "__key__" field, if there is one, to the entity's key:
+
+	it := datastore.Iterate[Widget](ctx, client, q)
+	for {
+		key, w, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			// Handle error.
+		}
+		fmt.Printf("Key=%v\nWidget=%#v\n\n", key, w)
+	}
+
 # Transactions
 
 Client.RunInTransaction runs a function in a transaction.
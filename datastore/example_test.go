@@ -22,6 +22,7 @@ import (
 
 	"cloud.google.com/go/datastore"
 	"google.golang.org/api/iterator"
+	datastorepb "google.golang.org/genproto/googleapis/datastore/v1"
 )
 
 func ExampleNewClient() {
@@ -115,6 +116,25 @@ func ExampleClient_Put_flatten() {
 	}
 }
 
+func ExampleClient_Database() {
+	ctx := context.Background()
+	client, err := datastore.NewClient(ctx, "project-id")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	// Copy a post from the default database to the "archive" database.
+	key := datastore.NameKey("Post", "post1", nil)
+	var p Post
+	if err := client.Get(ctx, key, &p); err != nil {
+		// TODO: Handle error.
+	}
+	if _, err := client.Database("archive").Put(ctx, key, &p); err != nil {
+		// TODO: Handle error.
+	}
+}
+
 func ExampleClient_Delete() {
 	ctx := context.Background()
 	client, err := datastore.NewClient(ctx, "project-id")
@@ -263,6 +283,26 @@ func ExampleClient_Count() {
 	fmt.Printf("There are %d posts.", n)
 }
 
+func ExampleClient_RunAggregationQuery() {
+	ctx := context.Background()
+	client, err := datastore.NewClient(ctx, "project-id")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	// Count the posts with comments, up to 1000, and the total number of comments.
+	aq := datastore.NewQuery("Post").FilterField("Comments", ">", 0).
+		NewAggregationQuery().
+		WithCountUpTo("count", 1000).
+		WithSum("Comments", "comments")
+	res, err := client.RunAggregationQuery(ctx, aq)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	count := res["count"].(*datastorepb.Value).GetIntegerValue()
+	comments := res["comments"].(*datastorepb.Value).GetIntegerValue()
+	fmt.Println(count, comments)
+}
+
 func ExampleClient_Run() {
 	ctx := context.Background()
 	client, err := datastore.NewClient(ctx, "project-id")
@@ -494,6 +534,48 @@ func ExampleIterator_Next() {
 	}
 }
 
+func ExampleIterate() {
+	ctx := context.Background()
+	client, err := datastore.NewClient(ctx, "project-id")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	it := datastore.Iterate[Post](ctx, client, datastore.NewQuery("Post"))
+	for {
+		key, p, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			// TODO: Handle error.
+		}
+		fmt.Println(key, p.Title)
+	}
+}
+
+func ExamplePutTyped() {
+	ctx := context.Background()
+	client, err := datastore.NewClient(ctx, "project-id")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	type Article struct {
+		Title string
+		Key   *datastore.Key `datastore:"__key__"`
+	}
+	a := &Article{Title: "The title", Key: datastore.IncompleteKey("Article", nil)}
+	// A nil key uses the value of the __key__ field, which is then set to the
+	// key allocated by the datastore.
+	if _, err := datastore.PutTyped(ctx, client, nil, a); err != nil {
+		// TODO: Handle error.
+	}
+	got, err := datastore.GetTyped[Article](ctx, client, a.Key)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	fmt.Println(got.Title)
+}
+
 func ExampleIterator_Cursor() {
 	ctx := context.Background()
 	client, err := datastore.NewClient(ctx, "project-id")
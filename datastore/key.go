@@ -43,6 +43,13 @@ type Key struct {
 	// See docs on datastore multitenancy for details:
 	// https://cloud.google.com/datastore/docs/concepts/multitenancy
 	Namespace string
+
+	// Database is the ID of the database that the key belongs to. An empty
+	// Database refers to the database of the Client the key is used with. A
+	// key with Database set can only be used with a Client for that database.
+	// Keys returned by the service always belong to the database of the Client
+	// that returned them, so their Database is left empty.
+	Database string
 }
 
 // Incomplete reports whether the key does not refer to a stored entity.
@@ -66,7 +73,7 @@ func (k *Key) valid() bool {
 			if k.Parent.Incomplete() {
 				return false
 			}
-			if k.Parent.Namespace != k.Namespace {
+			if k.Parent.Namespace != k.Namespace || k.Parent.Database != k.Database {
 				return false
 			}
 		}
@@ -75,13 +82,14 @@ func (k *Key) valid() bool {
 }
 
 // Equal reports whether two keys are equal. Two keys are equal if they are
-// both nil, or if their kinds, IDs, names, namespaces and parents are equal.
+// both nil, or if their kinds, IDs, names, namespaces, databases and parents
+// are equal.
 func (k *Key) Equal(o *Key) bool {
 	for {
 		if k == nil || o == nil {
 			return k == o // if either is nil, both must be nil
 		}
-		if k.Namespace != o.Namespace || k.Name != o.Name || k.ID != o.ID || k.Kind != o.Kind {
+		if k.Namespace != o.Namespace || k.Database != o.Database || k.Name != o.Name || k.ID != o.ID || k.Kind != o.Kind {
 			return false
 		}
 		if k.Parent == nil && o.Parent == nil {
@@ -130,6 +138,7 @@ type gobKey struct {
 	Parent    *gobKey
 	AppID     string
 	Namespace string
+	Database  string
 }
 
 func keyToGobKey(k *Key) *gobKey {
@@ -142,6 +151,7 @@ func keyToGobKey(k *Key) *gobKey {
 		IntID:     k.ID,
 		Parent:    keyToGobKey(k.Parent),
 		Namespace: k.Namespace,
+		Database:  k.Database,
 	}
 }
 
@@ -155,6 +165,7 @@ func gobKeyToKey(gk *gobKey) *Key {
 		ID:        gk.IntID,
 		Parent:    gobKeyToKey(gk.Parent),
 		Namespace: gk.Namespace,
+		Database:  gk.Database,
 	}
 }
 
@@ -240,7 +251,15 @@ func decodeCloudKey(encoded string) (*Key, error) {
 	if err := proto.Unmarshal(b, pKey); err != nil {
 		return nil, err
 	}
-	return protoToKey(pKey)
+	k, err := protoToKey(pKey)
+	// Unlike the keys returned by the service, encoded keys keep their
+	// database.
+	if database := pKey.GetPartitionId().GetDatabaseId(); database != "" {
+		for p := k; p != nil; p = p.Parent {
+			p.Database = database
+		}
+	}
+	return k, err
 }
 
 // AllocateIDs accepts a slice of incomplete keys and returns a
@@ -281,34 +300,45 @@ func (c *Client) ReserveIDs(ctx context.Context, keys []*Key) error {
 
 // IncompleteKey creates a new incomplete key.
 // The supplied kind cannot be empty.
-// The namespace of the new key is empty.
+// The namespace of the new key is empty, and its database is that of parent.
 func IncompleteKey(kind string, parent *Key) *Key {
 	return &Key{
-		Kind:   kind,
-		Parent: parent,
+		Kind:     kind,
+		Parent:   parent,
+		Database: parentDatabase(parent),
 	}
 }
 
 // NameKey creates a new key with a name.
 // The supplied kind cannot be empty.
 // The supplied parent must either be a complete key or nil.
-// The namespace of the new key is empty.
+// The namespace of the new key is empty, and its database is that of parent.
 func NameKey(kind, name string, parent *Key) *Key {
 	return &Key{
-		Kind:   kind,
-		Name:   name,
-		Parent: parent,
+		Kind:     kind,
+		Name:     name,
+		Parent:   parent,
+		Database: parentDatabase(parent),
 	}
 }
 
 // IDKey creates a new key with an ID.
 // The supplied kind cannot be empty.
 // The supplied parent must either be a complete key or nil.
-// The namespace of the new key is empty.
+// The namespace of the new key is empty, and its database is that of parent.
 func IDKey(kind string, id int64, parent *Key) *Key {
 	return &Key{
-		Kind:   kind,
-		ID:     id,
-		Parent: parent,
+		Kind:     kind,
+		ID:       id,
+		Parent:   parent,
+		Database: parentDatabase(parent),
+	}
+}
+
+// parentDatabase returns the database of parent, which may be nil.
+func parentDatabase(parent *Key) string {
+	if parent == nil {
+		return ""
 	}
+	return parent.Database
 }
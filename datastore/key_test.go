@@ -71,6 +71,11 @@ func TestEqual(t *testing.T) {
 			y:     &Key{Kind: "kindA", Name: "nameA", Namespace: "gopherspace"},
 			equal: false,
 		},
+		{
+			x:     &Key{Kind: "kindA", Name: "nameA", Database: "db1"},
+			y:     &Key{Kind: "kindA", Name: "nameA"},
+			equal: false,
+		},
 		{
 			x:     &Key{Kind: "kindA", ID: 1337, Parent: &Key{Kind: "kindX", Name: "nameX"}},
 			y:     &Key{Kind: "kindA", ID: 1337, Parent: &Key{Kind: "kindY", Name: "nameX"}},
@@ -138,6 +143,18 @@ func TestEncoding(t *testing.T) {
 			k:     &Key{Kind: "kindA", Parent: &Key{Kind: "kindB", Name: "nameB", Namespace: "gopherspace"}},
 			valid: false,
 		},
+		{
+			k:     &Key{Kind: "kindA", Name: "nameA", Namespace: "gopherspace", Database: "db1"},
+			valid: true,
+		},
+		{
+			k:     NameKey("kindA", "nameA", &Key{Kind: "kindB", Name: "nameB", Database: "db1"}),
+			valid: true,
+		},
+		{
+			k:     &Key{Kind: "kindA", Parent: &Key{Kind: "kindB", Name: "nameB", Database: "db1"}},
+			valid: false,
+		},
 	}
 
 	for _, tt := range testCases {
@@ -53,7 +53,7 @@ const (
 	mockProjectID = "projectID"
 )
 
-func newMock(t *testing.T) (_ *Client, _ *mockServer, _ func()) {
+func newMock(t testing.TB) (_ *Client, _ *mockServer, _ func()) {
 	srv, cleanup, err := newMockServer()
 	if err != nil {
 		t.Fatal(err)
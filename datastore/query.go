@@ -886,6 +886,10 @@ func (c *Client) run(ctx context.Context, q *Query, opts ...RunOption) *Iterator
 }
 
 // RunAggregationQuery gets aggregation query (e.g. COUNT) results from the service.
+//
+// If the underlying query is bound to a transaction with Query.Transaction, the
+// aggregation is run in that transaction. Otherwise it reads at the time set
+// with Client.WithReadOptions, if any, or at the current time.
 func (c *Client) RunAggregationQuery(ctx context.Context, aq *AggregationQuery) (ar AggregationResult, err error) {
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/datastore.Query.RunAggregationQuery")
 	defer func() { trace.EndSpan(ctx, err) }()
@@ -910,6 +914,12 @@ func (c *Client) RunAggregationQueryWithOptions(ctx context.Context, aq *Aggrega
 		return ar, errors.New("datastore: aggregation query must contain one or more operators (e.g. count)")
 	}
 
+	for _, a := range aq.aggregationQueries {
+		if upTo := a.GetCount().GetUpTo(); upTo != nil && upTo.Value < 0 {
+			return ar, fmt.Errorf("datastore: count up to %d: the limit cannot be negative", upTo.Value)
+		}
+	}
+
 	q, err := aq.query.toProto()
 	if err != nil {
 		return ar, err
@@ -952,6 +962,11 @@ func (c *Client) RunAggregationQueryWithOptions(ctx context.Context, aq *Aggrega
 	if err != nil {
 		return ar, err
 	}
+	if req.ReadOptions == nil {
+		// Outside of transactions, aggregations honor the client's read
+		// options, like Get and GetMulti.
+		req.ReadOptions = c.readOptions()
+	}
 
 	resp, err := c.client.RunAggregationQuery(ctx, req)
 	if err != nil {
@@ -1312,6 +1327,21 @@ func (aq *AggregationQuery) WithCount(alias string) *AggregationQuery {
 	return aq
 }
 
+// WithCountUpTo is like WithCount, but counts at most upTo of the results
+// returned by the underlying Query. Bounding the count limits the latency and
+// cost of counting the results of a query that matches many entities.
+// upTo must not be negative; if it is zero, the count is always zero.
+func (aq *AggregationQuery) WithCountUpTo(alias string, upTo int64) *AggregationQuery {
+	aq.WithCount(alias)
+	count := aq.aggregationQueries[len(aq.aggregationQueries)-1]
+	count.Operator = &pb.AggregationQuery_Aggregation_Count_{
+		Count: &pb.AggregationQuery_Aggregation_Count{
+			UpTo: &wrapperspb.Int64Value{Value: upTo},
+		},
+	}
+	return aq
+}
+
 // WithSum specifies that the aggregation query should provide a sum of the values
 // of the provided field in the results returned by the underlying Query.
 // The alias argument can be empty or a valid Datastore entity property name. It can be used
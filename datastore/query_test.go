@@ -22,12 +22,15 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/internal/testutil"
 	"github.com/google/go-cmp/cmp"
 	pb "google.golang.org/genproto/googleapis/datastore/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 var (
@@ -886,6 +889,71 @@ func TestAggregationQueryIsNil(t *testing.T) {
 	}
 }
 
+func TestAggregationQueryCountUpToAndReadTime(t *testing.T) {
+	ctx := context.Background()
+	client, srv, cleanup := newMock(t)
+	defer cleanup()
+
+	tm := time.Unix(1700000000, 0)
+	client.WithReadOptions(ReadTime(tm))
+	aq := NewQuery("Gopher").NewAggregationQuery().
+		WithCountUpTo("count", 10).
+		WithSum("Age", "sum")
+	srv.addRPC(&pb.RunAggregationQueryRequest{
+		ProjectId: "projectID",
+		QueryType: &pb.RunAggregationQueryRequest_AggregationQuery{
+			AggregationQuery: &pb.AggregationQuery{
+				QueryType: &pb.AggregationQuery_NestedQuery{
+					NestedQuery: &pb.Query{Kind: []*pb.KindExpression{{Name: "Gopher"}}},
+				},
+				Aggregations: []*pb.AggregationQuery_Aggregation{
+					{
+						Alias: "count",
+						Operator: &pb.AggregationQuery_Aggregation_Count_{
+							Count: &pb.AggregationQuery_Aggregation_Count{UpTo: &wrapperspb.Int64Value{Value: 10}},
+						},
+					},
+					{
+						Alias: "sum",
+						Operator: &pb.AggregationQuery_Aggregation_Sum_{
+							Sum: &pb.AggregationQuery_Aggregation_Sum{Property: &pb.PropertyReference{Name: "Age"}},
+						},
+					},
+				},
+			},
+		},
+		ReadOptions: &pb.ReadOptions{
+			ConsistencyType: &pb.ReadOptions_ReadTime{
+				ReadTime: &timestamppb.Timestamp{Seconds: tm.Unix()},
+			},
+		},
+	}, &pb.RunAggregationQueryResponse{
+		Batch: &pb.AggregationResultBatch{
+			AggregationResults: []*pb.AggregationResult{{
+				AggregateProperties: map[string]*pb.Value{
+					"count": {ValueType: &pb.Value_IntegerValue{IntegerValue: 10}},
+					"sum":   {ValueType: &pb.Value_IntegerValue{IntegerValue: 42}},
+				},
+			}},
+		},
+	})
+	res, err := client.RunAggregationQuery(ctx, aq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res["count"].(*pb.Value).GetIntegerValue(); got != 10 {
+		t.Errorf("got count %d, want 10", got)
+	}
+	if got := res["sum"].(*pb.Value).GetIntegerValue(); got != 42 {
+		t.Errorf("got sum %d, want 42", got)
+	}
+
+	aq = NewQuery("Gopher").NewAggregationQuery().WithCountUpTo("count", -1)
+	if _, err := client.RunAggregationQuery(ctx, aq); err == nil {
+		t.Error("got nil, want error for negative count limit")
+	}
+}
+
 func TestExplainOptionsApply(t *testing.T) {
 	pbExplainOptions := pb.ExplainOptions{
 		Analyze: true,
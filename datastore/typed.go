@@ -0,0 +1,272 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"cloud.google.com/go/internal/fields"
+	"cloud.google.com/go/internal/trace"
+	"google.golang.org/api/iterator"
+	pb "google.golang.org/genproto/googleapis/datastore/v1"
+)
+
+// typedCodec loads entities into values of type *T.
+//
+// If *T implements PropertyLoadSaver, entities are loaded through it.
+// Otherwise T must be a struct type, and entities are loaded with the same
+// structPLS reflection as Get. The codec is only a generic wrapper, so it is
+// no faster than Get.
+type typedCodec[T any] struct {
+	pls      bool
+	fields   fields.List
+	keyField *fields.Field
+}
+
+func newTypedCodec[T any]() (*typedCodec[T], error) {
+	if _, ok := any(new(T)).(PropertyLoadSaver); ok {
+		return &typedCodec[T]{pls: true}, nil
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, ErrInvalidEntityType
+	}
+	f, err := structCache.Fields(t)
+	if err != nil {
+		return nil, err
+	}
+	c := &typedCodec[T]{fields: f, keyField: f.Match(keyFieldName)}
+	if c.keyField != nil && c.keyField.Type != typeOfKeyPtr {
+		return nil, fmt.Errorf("datastore: %s field on struct %v is not a *datastore.Key", keyFieldName, t)
+	}
+	return c, nil
+}
+
+// load loads src into dst. As with Get, dst is fully loaded even if an
+// *ErrFieldMismatch is returned.
+func (c *typedCodec[T]) load(dst *T, src *pb.Entity) error {
+	if c.pls {
+		return loadEntityProto(dst, src)
+	}
+	ent, err := protoToEntity(src)
+	if err != nil {
+		return err
+	}
+	pls := structPLS{v: reflect.ValueOf(dst).Elem(), codec: c.fields}
+	if c.keyField != nil && ent.Key != nil {
+		pls.v.FieldByIndex(c.keyField.Index).Set(reflect.ValueOf(ent.Key))
+	}
+	return pls.Load(ent.Properties)
+}
+
+// key returns the value of src's "__key__" field, or nil if T has no such
+// field.
+func (c *typedCodec[T]) key(src *T) *Key {
+	if c.keyField == nil {
+		return nil
+	}
+	return reflect.ValueOf(src).Elem().FieldByIndex(c.keyField.Index).Interface().(*Key)
+}
+
+// setKey sets src's "__key__" field, if T has one, to k.
+func (c *typedCodec[T]) setKey(src *T, k *Key) {
+	if c.keyField == nil {
+		return
+	}
+	reflect.ValueOf(src).Elem().FieldByIndex(c.keyField.Index).Set(reflect.ValueOf(k))
+}
+
+// GetTyped loads the entity stored for key into a new value of type T and
+// returns a pointer to it. T must be a struct type, or a type such that *T
+// implements PropertyLoadSaver. If there is no such entity for the key,
+// GetTyped returns ErrNoSuchEntity.
+//
+// If T has a *Key field tagged with "__key__", it is set to key.
+//
+// As with Get, ErrFieldMismatch is returned, together with the loaded value,
+// when a property cannot be loaded into a field of T.
+//
+// GetTyped, GetMultiTyped, PutTyped and Iterate check the type of the values
+// at compile time, but they encode and decode entities with reflection, as
+// Get and Put do, so they are not faster.
+func GetTyped[T any](ctx context.Context, c *Client, key *Key) (_ *T, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/datastore.GetTyped")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	vs, err := getMultiTyped[T](ctx, c, []*Key{key})
+	if me, ok := err.(MultiError); ok {
+		return vs[0], me[0]
+	}
+	if err != nil {
+		return nil, err
+	}
+	return vs[0], nil
+}
+
+// GetMultiTyped is a batch version of GetTyped. The returned slice has one
+// element per key. The elements for keys that could not be loaded are nil.
+//
+// err may be a MultiError. See ExampleMultiError to check it.
+func GetMultiTyped[T any](ctx context.Context, c *Client, keys []*Key) (_ []*T, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/datastore.GetMultiTyped")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	return getMultiTyped[T](ctx, c, keys)
+}
+
+func getMultiTyped[T any](ctx context.Context, c *Client, keys []*Key) ([]*T, error) {
+	codec, err := newTypedCodec[T]()
+	if err != nil {
+		return nil, err
+	}
+	vs := make([]*T, len(keys))
+	for i := range vs {
+		vs[i] = new(T)
+	}
+	_, err = c.lookup(ctx, keys, c.readOptions(), func(i int, e *pb.Entity) error {
+		return codec.load(vs[i], e)
+	})
+	if me, ok := err.(MultiError); ok {
+		for i, e := range me {
+			var fm *ErrFieldMismatch
+			if e != nil && !errors.As(e, &fm) {
+				vs[i] = nil
+			}
+		}
+		return vs, me
+	}
+	if err != nil {
+		return nil, err
+	}
+	return vs, nil
+}
+
+// PutTyped saves the entity src into the datastore with the given key and
+// returns the key. If key is nil, the value of src's "__key__" field is used
+// instead. If key is incomplete, the returned key will be a unique key
+// generated by the datastore.
+//
+// If T has a *Key field tagged with "__key__", it is set to the returned key.
+func PutTyped[T any](ctx context.Context, c *Client, key *Key, src *T) (_ *Key, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/datastore.PutTyped")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	if src == nil {
+		return nil, fmt.Errorf("%w: src cannot be nil", ErrInvalidEntityType)
+	}
+	codec, err := newTypedCodec[T]()
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		if key = codec.key(src); key == nil {
+			return nil, fmt.Errorf("datastore: PutTyped: key is nil and %T has no %s field set", src, keyFieldName)
+		}
+	}
+	k, err := c.Put(ctx, key, src)
+	if err != nil {
+		return nil, err
+	}
+	codec.setKey(src, k)
+	return k, nil
+}
+
+// Iterate runs the given query and returns an iterator over its results,
+// loaded into values of type T. T must satisfy the same conditions as for
+// GetTyped.
+func Iterate[T any](ctx context.Context, c *Client, q *Query, opts ...RunOption) *TypedIterator[T] {
+	codec, err := newTypedCodec[T]()
+	if err != nil {
+		return &TypedIterator[T]{err: err}
+	}
+	return &TypedIterator[T]{it: c.run(ctx, q, opts...), codec: codec}
+}
+
+// TypedIterator is the result of running a query with Iterate.
+//
+// It is not safe to call a TypedIterator's methods concurrently.
+type TypedIterator[T any] struct {
+	it    *Iterator
+	codec *typedCodec[T]
+	err   error
+}
+
+// Next returns the key and the entity of the next result. When there are no
+// more results, iterator.Done is returned as the error.
+//
+// For keys-only queries, the returned entity is nil. As with Iterator.Next,
+// ErrFieldMismatch is returned, together with the loaded entity, when a
+// property cannot be loaded into a field of T.
+func (t *TypedIterator[T]) Next() (*Key, *T, error) {
+	if t.err != nil {
+		return nil, nil, t.err
+	}
+	k, e, err := t.it.next()
+	if err != nil {
+		return nil, nil, err
+	}
+	if t.it.keysOnly {
+		return k, nil, nil
+	}
+	v := new(T)
+	if err := t.codec.load(v, e); err != nil {
+		var fm *ErrFieldMismatch
+		if !errors.As(err, &fm) {
+			return k, nil, err
+		}
+		return k, v, err
+	}
+	return k, v, nil
+}
+
+// GetAll returns the keys and entities of all remaining results. As with
+// Client.GetAll, ErrFieldMismatch errors do not stop the iteration; the first
+// one is returned once all results have been loaded.
+func (t *TypedIterator[T]) GetAll() ([]*Key, []*T, error) {
+	var (
+		keys     []*Key
+		vs       []*T
+		errFirst error
+	)
+	for {
+		k, v, err := t.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			var fm *ErrFieldMismatch
+			if !errors.As(err, &fm) {
+				return keys, vs, err
+			}
+			if errFirst == nil {
+				errFirst = err
+			}
+		}
+		keys = append(keys, k)
+		vs = append(vs, v)
+	}
+	return keys, vs, errFirst
+}
+
+// Cursor returns a cursor for the iterator's current location.
+func (t *TypedIterator[T]) Cursor() (Cursor, error) {
+	if t.err != nil {
+		return Cursor{}, t.err
+	}
+	return t.it.Cursor()
+}
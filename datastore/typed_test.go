@@ -0,0 +1,207 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/internal/testutil"
+	pb "google.golang.org/genproto/googleapis/datastore/v1"
+)
+
+type typedEnt struct {
+	A int
+	B string `datastore:"b"`
+	K *Key   `datastore:"__key__"`
+}
+
+func typedEntProto(k *Key, a int64, b string) *pb.Entity {
+	return &pb.Entity{
+		Key: keyToProto(k),
+		Properties: map[string]*pb.Value{
+			"A": {ValueType: &pb.Value_IntegerValue{IntegerValue: a}},
+			"b": {ValueType: &pb.Value_StringValue{StringValue: b}},
+		},
+	}
+}
+
+func TestGetTyped(t *testing.T) {
+	ctx := context.Background()
+	client, srv, cleanup := newMock(t)
+	defer cleanup()
+
+	k1 := NameKey("Entity", "one", nil)
+	k2 := NameKey("Entity", "two", nil)
+	srv.addRPC(&pb.LookupRequest{
+		ProjectId: "projectID",
+		Keys:      []*pb.Key{keyToProto(k1)},
+	}, &pb.LookupResponse{
+		Found: []*pb.EntityResult{{Entity: typedEntProto(k1, 1, "x"), Version: 1}},
+	})
+	got, err := GetTyped[typedEnt](ctx, client, k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&typedEnt{A: 1, B: "x", K: k1}); !testutil.Equal(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	srv.addRPC(&pb.LookupRequest{
+		ProjectId: "projectID",
+		Keys:      []*pb.Key{keyToProto(k1), keyToProto(k2)},
+	}, &pb.LookupResponse{
+		Found:   []*pb.EntityResult{{Entity: typedEntProto(k2, 2, "y"), Version: 1}},
+		Missing: []*pb.EntityResult{{Entity: &pb.Entity{Key: keyToProto(k1)}, Version: 1}},
+	})
+	gots, err := GetMultiTyped[typedEnt](ctx, client, []*Key{k1, k2})
+	var me MultiError
+	if !errors.As(err, &me) || me[0] != ErrNoSuchEntity || me[1] != nil {
+		t.Fatalf("got %v, want MultiError{ErrNoSuchEntity, nil}", err)
+	}
+	if want := []*typedEnt{nil, {A: 2, B: "y", K: k2}}; !testutil.Equal(gots, want) {
+		t.Errorf("got %+v, want %+v", gots, want)
+	}
+
+	if _, err := GetTyped[int](ctx, client, k1); err != ErrInvalidEntityType {
+		t.Errorf("got %v, want ErrInvalidEntityType", err)
+	}
+}
+
+func TestPutTyped(t *testing.T) {
+	ctx := context.Background()
+	client, srv, cleanup := newMock(t)
+	defer cleanup()
+
+	incomplete := IncompleteKey("Entity", nil)
+	completed := IDKey("Entity", 7, nil)
+	e := &typedEnt{A: 1, B: "x", K: incomplete}
+	p, err := saveEntity(incomplete, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.addRPC(&pb.CommitRequest{
+		ProjectId: "projectID",
+		Mode:      pb.CommitRequest_NON_TRANSACTIONAL,
+		Mutations: []*pb.Mutation{{Operation: &pb.Mutation_Insert{Insert: p}}},
+	}, &pb.CommitResponse{
+		MutationResults: []*pb.MutationResult{{Key: keyToProto(completed)}},
+	})
+	// The key is taken from the __key__ field and set to the completed key.
+	k, err := PutTyped(ctx, client, nil, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !k.Equal(completed) || !e.K.Equal(completed) {
+		t.Errorf("got key %v and field %v, want %v", k, e.K, completed)
+	}
+
+	if _, err := PutTyped(ctx, client, nil, &typedEnt{}); err == nil {
+		t.Error("got nil, want error for nil key without __key__ field")
+	}
+	if _, err := PutTyped[typedEnt](ctx, client, completed, nil); !errors.Is(err, ErrInvalidEntityType) {
+		t.Errorf("got %v, want ErrInvalidEntityType", err)
+	}
+}
+
+func TestIterate(t *testing.T) {
+	ctx := context.Background()
+	client, srv, cleanup := newMock(t)
+	defer cleanup()
+
+	k1 := NameKey("Entity", "one", nil)
+	k2 := NameKey("Entity", "two", nil)
+	bad := &pb.Entity{
+		Key: keyToProto(k2),
+		Properties: map[string]*pb.Value{
+			"A": {ValueType: &pb.Value_StringValue{StringValue: "not an int"}},
+			"b": {ValueType: &pb.Value_StringValue{StringValue: "y"}},
+		},
+	}
+	srv.addRPC(nil, &pb.RunQueryResponse{
+		Batch: &pb.QueryResultBatch{
+			EntityResultType: pb.EntityResult_FULL,
+			EntityResults: []*pb.EntityResult{
+				{Entity: typedEntProto(k1, 1, "x")},
+				{Entity: bad},
+			},
+			MoreResults: pb.QueryResultBatch_NO_MORE_RESULTS,
+		},
+	})
+	keys, got, err := Iterate[typedEnt](ctx, client, NewQuery("Entity")).GetAll()
+	var fm *ErrFieldMismatch
+	if !errors.As(err, &fm) || fm.FieldName != "A" {
+		t.Errorf("got %v, want ErrFieldMismatch for field A", err)
+	}
+	if want := []*Key{k1, k2}; !testutil.Equal(keys, want) {
+		t.Errorf("got keys %v, want %v", keys, want)
+	}
+	if want := []*typedEnt{{A: 1, B: "x", K: k1}, {B: "y", K: k2}}; !testutil.Equal(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	it := Iterate[PropertyList](ctx, client, NewQuery("Entity"))
+	if it.err != nil {
+		t.Errorf("got %v, want nil error for PropertyLoadSaver type", it.err)
+	}
+	if _, _, err := Iterate[string](ctx, client, NewQuery("Entity")).Next(); err != ErrInvalidEntityType {
+		t.Errorf("got %v, want ErrInvalidEntityType", err)
+	}
+}
+
+func benchmarkLookups(b *testing.B, srv *mockServer, k *Key) {
+	for i := 0; i < b.N; i++ {
+		srv.addRPC(&pb.LookupRequest{
+			ProjectId: "projectID",
+			Keys:      []*pb.Key{keyToProto(k)},
+		}, &pb.LookupResponse{
+			Found: []*pb.EntityResult{{Entity: typedEntProto(k, 1, "x"), Version: 1}},
+		})
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	ctx := context.Background()
+	client, srv, cleanup := newMock(b)
+	defer cleanup()
+
+	k := NameKey("Entity", "one", nil)
+	benchmarkLookups(b, srv, k)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var e typedEnt
+		if err := client.Get(ctx, k, &e); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetTyped(b *testing.B) {
+	ctx := context.Background()
+	client, srv, cleanup := newMock(b)
+	defer cleanup()
+
+	k := NameKey("Entity", "one", nil)
+	benchmarkLookups(b, srv, k)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := GetTyped[typedEnt](ctx, client, k); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
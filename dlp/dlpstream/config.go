@@ -0,0 +1,90 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dlpstream
+
+import "cloud.google.com/go/dlp/apiv2/dlppb"
+
+// InfoTypes returns the info types of the given names, such as
+// "EMAIL_ADDRESS".
+func InfoTypes(names ...string) []*dlppb.InfoType {
+	var its []*dlppb.InfoType
+	for _, n := range names {
+		its = append(its, &dlppb.InfoType{Name: n})
+	}
+	return its
+}
+
+// NewInspectConfig returns an InspectConfig that finds the info types of the
+// given names with at least the given likelihood. With no names, the
+// default info types of the API are found.
+func NewInspectConfig(minLikelihood dlppb.Likelihood, names ...string) *dlppb.InspectConfig {
+	return &dlppb.InspectConfig{InfoTypes: InfoTypes(names...), MinLikelihood: minLikelihood}
+}
+
+// ReplaceWithInfoType returns a DeidentifyConfig that replaces the findings
+// of the info types of the given names, or of all info types if there are
+// none, with the name of their info type, such as [EMAIL_ADDRESS].
+func ReplaceWithInfoType(names ...string) *dlppb.DeidentifyConfig {
+	return transform(&dlppb.PrimitiveTransformation{
+		Transformation: &dlppb.PrimitiveTransformation_ReplaceWithInfoTypeConfig{
+			ReplaceWithInfoTypeConfig: &dlppb.ReplaceWithInfoTypeConfig{},
+		},
+	}, names)
+}
+
+// Replace returns a DeidentifyConfig that replaces the findings of the info
+// types of the given names, or of all info types if there are none, with
+// value.
+func Replace(value string, names ...string) *dlppb.DeidentifyConfig {
+	return transform(&dlppb.PrimitiveTransformation{
+		Transformation: &dlppb.PrimitiveTransformation_ReplaceConfig{
+			ReplaceConfig: &dlppb.ReplaceValueConfig{
+				NewValue: &dlppb.Value{Type: &dlppb.Value_StringValue{StringValue: value}},
+			},
+		},
+	}, names)
+}
+
+// Mask returns a DeidentifyConfig that masks each character of the findings
+// of the info types of the given names, or of all info types if there are
+// none, with maskingChar, such as "*".
+func Mask(maskingChar string, names ...string) *dlppb.DeidentifyConfig {
+	return transform(&dlppb.PrimitiveTransformation{
+		Transformation: &dlppb.PrimitiveTransformation_CharacterMaskConfig{
+			CharacterMaskConfig: &dlppb.CharacterMaskConfig{MaskingCharacter: maskingChar},
+		},
+	}, names)
+}
+
+// Redact returns a DeidentifyConfig that removes the findings of the info
+// types of the given names, or of all info types if there are none.
+func Redact(names ...string) *dlppb.DeidentifyConfig {
+	return transform(&dlppb.PrimitiveTransformation{
+		Transformation: &dlppb.PrimitiveTransformation_RedactConfig{RedactConfig: &dlppb.RedactConfig{}},
+	}, names)
+}
+
+func transform(pt *dlppb.PrimitiveTransformation, names []string) *dlppb.DeidentifyConfig {
+	return &dlppb.DeidentifyConfig{
+		Transformation: &dlppb.DeidentifyConfig_InfoTypeTransformations{
+			InfoTypeTransformations: &dlppb.InfoTypeTransformations{
+				Transformations: []*dlppb.InfoTypeTransformations_InfoTypeTransformation{{
+					InfoTypes:               InfoTypes(names...),
+					PrimitiveTransformation: pt,
+				}},
+			},
+		},
+	}
+}
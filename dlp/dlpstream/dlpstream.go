@@ -0,0 +1,320 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dlpstream inspects and de-identifies text read from an io.Reader
+// with the client in cloud.google.com/go/dlp/apiv2, however large the text
+// is.
+//
+// The text is sent in chunks that fit in a request, which end at a line
+// boundary when there is one. Findings that span the end of a chunk are
+// not lost: Inspect sends each chunk with the text around it, and Deidentify
+// moves the end of each chunk to the start of such findings.
+//
+//	err := dlpstream.Deidentify(ctx, client, os.Stdout, logs, &dlpstream.Config{
+//		Parent:           "projects/my-project/locations/global",
+//		InspectConfig:    dlpstream.NewInspectConfig(dlppb.Likelihood_POSSIBLE, "EMAIL_ADDRESS", "PHONE_NUMBER"),
+//		DeidentifyConfig: dlpstream.ReplaceWithInfoType(),
+//	})
+//
+// The text must be UTF-8.
+package dlpstream // import "cloud.google.com/go/dlp/dlpstream"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	dlp "cloud.google.com/go/dlp/apiv2"
+	"cloud.google.com/go/dlp/apiv2/dlppb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+const (
+	// MaxContentBytes is the maximum number of bytes of text sent in one
+	// request. The API limits requests to 0.5 MB.
+	MaxContentBytes = 450 << 10
+
+	// DefaultChunkSize is the default maximum size of a chunk.
+	DefaultChunkSize = 256 << 10
+
+	// DefaultOverlap is the default number of bytes of text around a chunk
+	// that are inspected for findings spanning its start or end.
+	DefaultOverlap = 1 << 10
+)
+
+// Config configures Inspect and Deidentify.
+type Config struct {
+	// Parent is the parent resource of the requests, of the form
+	// projects/{project}/locations/{location}. Required.
+	Parent string
+
+	// InspectConfig configures the inspection. It is merged with
+	// InspectTemplate, if that is set.
+	InspectConfig *dlppb.InspectConfig
+
+	// InspectTemplate is the name of an inspection template.
+	InspectTemplate string
+
+	// DeidentifyConfig configures the de-identification of Deidentify. It is
+	// merged with DeidentifyTemplate, if that is set.
+	DeidentifyConfig *dlppb.DeidentifyConfig
+
+	// DeidentifyTemplate is the name of a de-identification template.
+	DeidentifyTemplate string
+
+	// ChunkSize is the maximum number of bytes of a chunk. The default is
+	// DefaultChunkSize.
+	ChunkSize int
+
+	// Overlap is the number of bytes of text on each side of the end of a
+	// chunk that are inspected for findings spanning it. The default is
+	// DefaultOverlap. A negative Overlap disables this, so that findings
+	// spanning chunks may be split or missed.
+	//
+	// ChunkSize plus twice Overlap must be at most MaxContentBytes.
+	Overlap int
+}
+
+func (cfg *Config) sizes() (size, overlap int, err error) {
+	if cfg.Parent == "" {
+		return 0, 0, errors.New("dlpstream: Config.Parent is required")
+	}
+	size, overlap = cfg.ChunkSize, cfg.Overlap
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	switch {
+	case overlap == 0:
+		overlap = DefaultOverlap
+	case overlap < 0:
+		overlap = 0
+	}
+	if size+2*overlap > MaxContentBytes {
+		return 0, 0, fmt.Errorf("dlpstream: a chunk of %d bytes with an overlap of %d bytes is over %d bytes", size, overlap, MaxContentBytes)
+	}
+	return size, overlap, nil
+}
+
+func (cfg *Config) inspectRequest(text []byte) *dlppb.InspectContentRequest {
+	return &dlppb.InspectContentRequest{
+		Parent:              cfg.Parent,
+		InspectConfig:       cfg.InspectConfig,
+		InspectTemplateName: cfg.InspectTemplate,
+		Item:                &dlppb.ContentItem{DataItem: &dlppb.ContentItem_Value{Value: string(text)}},
+	}
+}
+
+// dlpClient is implemented by *dlp.Client.
+type dlpClient interface {
+	InspectContent(ctx context.Context, req *dlppb.InspectContentRequest, opts ...gax.CallOption) (*dlppb.InspectContentResponse, error)
+	DeidentifyContent(ctx context.Context, req *dlppb.DeidentifyContentRequest, opts ...gax.CallOption) (*dlppb.DeidentifyContentResponse, error)
+}
+
+// Inspect inspects the text read from r, and calls f with each finding, in
+// the order of the chunks. The byte and codepoint ranges of the location of
+// a finding are relative to the start of the text. Inspect stops at the
+// first error of f, and returns it.
+//
+// The limits of cfg.InspectConfig, such as the maximum number of findings,
+// apply to each chunk.
+func Inspect(ctx context.Context, client *dlp.Client, r io.Reader, cfg *Config, f func(*dlppb.Finding) error) error {
+	return inspect(ctx, client, r, cfg, f)
+}
+
+func inspect(ctx context.Context, client dlpClient, r io.Reader, cfg *Config, f func(*dlppb.Finding) error) error {
+	size, overlap, err := cfg.sizes()
+	if err != nil {
+		return err
+	}
+	c := &chunker{r: r}
+	var (
+		prev   []byte // the end of the text before the chunk
+		offset int64  // the byte offset of the chunk
+		runes  int64  // the codepoint offset of the chunk
+	)
+	for {
+		if err := c.fill(size + overlap); err != nil {
+			return err
+		}
+		if len(c.buf) == 0 {
+			return nil
+		}
+		n := c.cut(size)
+		next := c.buf[n:runeStart(c.buf, n+overlap)]
+		text := make([]byte, 0, len(prev)+n+len(next))
+		text = append(append(append(text, prev...), c.buf[:n]...), next...)
+
+		res, err := client.InspectContent(ctx, cfg.inspectRequest(text))
+		if err != nil {
+			return err
+		}
+		// A finding belongs to the chunk that it starts in.
+		start, end := int64(len(prev)), int64(len(prev)+n)
+		byteShift := offset - start
+		runeShift := runes - int64(utf8.RuneCount(prev))
+		for _, fd := range res.GetResult().GetFindings() {
+			if s := fd.GetLocation().GetByteRange().GetStart(); s < start || s >= end {
+				continue
+			}
+			shift(fd.GetLocation().GetByteRange(), byteShift)
+			shift(fd.GetLocation().GetCodepointRange(), runeShift)
+			if err := f(fd); err != nil {
+				return err
+			}
+		}
+
+		offset += int64(n)
+		runes += int64(utf8.RuneCount(c.buf[:n]))
+		prev = text[:end]
+		if len(prev) > overlap {
+			prev = prev[runeStart(prev, len(prev)-overlap):]
+		}
+		c.buf = c.buf[n:]
+	}
+}
+
+func shift(r *dlppb.Range, d int64) {
+	if r != nil {
+		r.Start += d
+		r.End += d
+	}
+}
+
+// Deidentify de-identifies the text read from r, and writes the result to w.
+// The de-identification is configured by cfg.DeidentifyConfig or
+// cfg.DeidentifyTemplate.
+func Deidentify(ctx context.Context, client *dlp.Client, w io.Writer, r io.Reader, cfg *Config) error {
+	return deidentify(ctx, client, w, r, cfg)
+}
+
+func deidentify(ctx context.Context, client dlpClient, w io.Writer, r io.Reader, cfg *Config) error {
+	size, overlap, err := cfg.sizes()
+	if err != nil {
+		return err
+	}
+	c := &chunker{r: r}
+	for {
+		if err := c.fill(size + overlap); err != nil {
+			return err
+		}
+		if len(c.buf) == 0 {
+			return nil
+		}
+		n := c.cut(size)
+		if n < len(c.buf) && overlap > 0 {
+			if n, err = safeCut(ctx, client, cfg, c.buf, n, overlap); err != nil {
+				return err
+			}
+		}
+		res, err := client.DeidentifyContent(ctx, &dlppb.DeidentifyContentRequest{
+			Parent:                 cfg.Parent,
+			DeidentifyConfig:       cfg.DeidentifyConfig,
+			InspectConfig:          cfg.InspectConfig,
+			DeidentifyTemplateName: cfg.DeidentifyTemplate,
+			InspectTemplateName:    cfg.InspectTemplate,
+			Item:                   &dlppb.ContentItem{DataItem: &dlppb.ContentItem_Value{Value: string(c.buf[:n])}},
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, res.GetItem().GetValue()); err != nil {
+			return err
+		}
+		c.buf = c.buf[n:]
+	}
+}
+
+// safeCut inspects the overlap bytes of buf on each side of n, the end of a
+// chunk, and returns n moved back to the start of the findings that span it.
+// A finding that starts at the start of the chunk is left to span it.
+func safeCut(ctx context.Context, client dlpClient, cfg *Config, buf []byte, n, overlap int) (int, error) {
+	lo := 0
+	if n > overlap {
+		lo = runeStart(buf, n-overlap)
+	}
+	hi := runeStart(buf, n+overlap)
+	res, err := client.InspectContent(ctx, cfg.inspectRequest(buf[lo:hi]))
+	if err != nil {
+		return 0, err
+	}
+	findings := res.GetResult().GetFindings()
+	// Moving n may make it fall within another finding.
+	for moved := true; moved; {
+		moved = false
+		for _, fd := range findings {
+			br := fd.GetLocation().GetByteRange()
+			start, end := lo+int(br.GetStart()), lo+int(br.GetEnd())
+			if start > 0 && start < n && n < end {
+				n = start
+				moved = true
+			}
+		}
+	}
+	return n, nil
+}
+
+// chunker reads the text of chunks.
+type chunker struct {
+	r   io.Reader
+	buf []byte // the text read and not yet sent
+	eof bool
+}
+
+// fill reads from r until buf holds n bytes, or r is at its end.
+func (c *chunker) fill(n int) error {
+	if c.eof || len(c.buf) >= n {
+		return nil
+	}
+	p := make([]byte, n-len(c.buf))
+	m, err := io.ReadFull(c.r, p)
+	c.buf = append(c.buf, p[:m]...)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		c.eof = true
+		return nil
+	}
+	return err
+}
+
+// cut returns the length of the next chunk, of at most size bytes of buf. A
+// chunk that isn't the last one ends after the last newline of the second
+// half of its maximum size, or else at a rune boundary.
+func (c *chunker) cut(size int) int {
+	if len(c.buf) <= size {
+		if c.eof {
+			return len(c.buf)
+		}
+		size = len(c.buf)
+	}
+	if i := bytes.LastIndexByte(c.buf[size/2:size], '\n'); i >= 0 {
+		return size/2 + i + 1
+	}
+	return runeStart(c.buf, size)
+}
+
+// runeStart returns the start of the rune of b at i, or len(b) if i is past
+// the end of b. Invalid UTF-8 is cut at i.
+func runeStart(b []byte, i int) int {
+	if i >= len(b) {
+		return len(b)
+	}
+	for j := i; j > 0 && j > i-utf8.UTFMax; j-- {
+		if utf8.RuneStart(b[j]) {
+			return j
+		}
+	}
+	return i
+}
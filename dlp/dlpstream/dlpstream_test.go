@@ -0,0 +1,199 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dlpstream
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"cloud.google.com/go/dlp/apiv2/dlppb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+var emailRE = regexp.MustCompile(`[a-zé]+@[a-z]+\.com`)
+
+// fakeClient finds email addresses, and replaces them with [EMAIL_ADDRESS].
+type fakeClient struct {
+	maxLen int // the length of the longest text sent
+}
+
+func (c *fakeClient) find(text string) []*dlppb.Finding {
+	if len(text) > c.maxLen {
+		c.maxLen = len(text)
+	}
+	var fs []*dlppb.Finding
+	for _, m := range emailRE.FindAllStringIndex(text, -1) {
+		fs = append(fs, &dlppb.Finding{
+			Quote:    text[m[0]:m[1]],
+			InfoType: &dlppb.InfoType{Name: "EMAIL_ADDRESS"},
+			Location: &dlppb.Location{
+				ByteRange:      &dlppb.Range{Start: int64(m[0]), End: int64(m[1])},
+				CodepointRange: &dlppb.Range{Start: int64(utf8.RuneCountInString(text[:m[0]])), End: int64(utf8.RuneCountInString(text[:m[1]]))},
+			},
+		})
+	}
+	return fs
+}
+
+func (c *fakeClient) InspectContent(_ context.Context, req *dlppb.InspectContentRequest, _ ...gax.CallOption) (*dlppb.InspectContentResponse, error) {
+	return &dlppb.InspectContentResponse{Result: &dlppb.InspectResult{Findings: c.find(req.Item.GetValue())}}, nil
+}
+
+func (c *fakeClient) DeidentifyContent(_ context.Context, req *dlppb.DeidentifyContentRequest, _ ...gax.CallOption) (*dlppb.DeidentifyContentResponse, error) {
+	c.find(req.Item.GetValue())
+	v := emailRE.ReplaceAllString(req.Item.GetValue(), "[EMAIL_ADDRESS]")
+	return &dlppb.DeidentifyContentResponse{Item: &dlppb.ContentItem{DataItem: &dlppb.ContentItem_Value{Value: v}}}, nil
+}
+
+// testText returns lines with email addresses, some of them longer than
+// the chunks of the tests.
+func testText() string {
+	var b strings.Builder
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(&b, "%d: mail from josé@example.com to user%c@example.com", i, 'a'+i%26)
+		if i%7 == 0 {
+			b.WriteString(strings.Repeat(" pad", 20) + " admin@example.com")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func TestInspect(t *testing.T) {
+	text := testText()
+	want := (&fakeClient{}).find(text)
+	for _, size := range []int{64, 100, 333, 4096} {
+		t.Run(fmt.Sprint(size), func(t *testing.T) {
+			c := &fakeClient{}
+			var got []*dlppb.Finding
+			err := inspect(context.Background(), c, strings.NewReader(text), &Config{Parent: "p", ChunkSize: size, Overlap: 32}, func(f *dlppb.Finding) error {
+				got = append(got, f)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got %d findings, want %d", len(got), len(want))
+			}
+			for i, f := range got {
+				br, cr := f.Location.ByteRange, f.Location.CodepointRange
+				wbr, wcr := want[i].Location.ByteRange, want[i].Location.CodepointRange
+				if br.Start != wbr.Start || br.End != wbr.End || cr.Start != wcr.Start || cr.End != wcr.End {
+					t.Errorf("finding %d (%q): got bytes %v codepoints %v, want %v %v", i, f.Quote, br, cr, wbr, wcr)
+				}
+			}
+			if c.maxLen > size+64 {
+				t.Errorf("sent %d bytes, want at most %d", c.maxLen, size+64)
+			}
+		})
+	}
+}
+
+func TestInspectNoOverlap(t *testing.T) {
+	// Without overlap, findings spanning chunks are missed.
+	text := "a alice@example.com b\n"
+	n := 0
+	err := inspect(context.Background(), &fakeClient{}, strings.NewReader(text), &Config{Parent: "p", ChunkSize: 10, Overlap: -1}, func(*dlppb.Finding) error {
+		n++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("got %d findings, want 0", n)
+	}
+}
+
+func TestDeidentify(t *testing.T) {
+	text := testText()
+	want := emailRE.ReplaceAllString(text, "[EMAIL_ADDRESS]")
+	for _, size := range []int{64, 100, 333, 4096} {
+		t.Run(fmt.Sprint(size), func(t *testing.T) {
+			c := &fakeClient{}
+			var buf bytes.Buffer
+			if err := deidentify(context.Background(), c, &buf, strings.NewReader(text), &Config{Parent: "p", ChunkSize: size, Overlap: 32}); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != want {
+				t.Errorf("got\n%s\nwant\n%s", got, want)
+			}
+			if c.maxLen > size+64 {
+				t.Errorf("sent %d bytes, want at most %d", c.maxLen, size+64)
+			}
+		})
+	}
+}
+
+func TestSizes(t *testing.T) {
+	for _, test := range []struct {
+		cfg       Config
+		size, ovl int
+		wantErr   bool
+	}{
+		{Config{Parent: "p"}, DefaultChunkSize, DefaultOverlap, false},
+		{Config{Parent: "p", ChunkSize: 100, Overlap: -1}, 100, 0, false},
+		{Config{Parent: "p", ChunkSize: MaxContentBytes}, 0, 0, true},
+		{Config{}, 0, 0, true},
+	} {
+		size, ovl, err := test.cfg.sizes()
+		if (err != nil) != test.wantErr {
+			t.Errorf("%+v: got error %v, want error %t", test.cfg, err, test.wantErr)
+			continue
+		}
+		if size != test.size || ovl != test.ovl {
+			t.Errorf("%+v: got %d, %d, want %d, %d", test.cfg, size, ovl, test.size, test.ovl)
+		}
+	}
+}
+
+func TestCut(t *testing.T) {
+	for _, test := range []struct {
+		text string
+		eof  bool
+		want int
+	}{
+		{"abc", true, 3},
+		{"ab\ncd\nefgh", false, 6},
+		{"abcdefghij", false, 8},
+		{"abcdefgé", false, 7},    // é doesn't fit
+		{"a\nbcdefghi", false, 8}, // the newline is in the first half
+	} {
+		c := &chunker{buf: []byte(test.text), eof: test.eof}
+		if got := c.cut(8); got != test.want {
+			t.Errorf("cut(%q) = %d, want %d", test.text, got, test.want)
+		}
+	}
+}
+
+func TestReplaceWithInfoType(t *testing.T) {
+	cfg := ReplaceWithInfoType("EMAIL_ADDRESS", "PHONE_NUMBER")
+	tr := cfg.GetInfoTypeTransformations().GetTransformations()
+	if len(tr) != 1 {
+		t.Fatalf("got %d transformations, want 1", len(tr))
+	}
+	if got := tr[0].InfoTypes; len(got) != 2 || got[0].Name != "EMAIL_ADDRESS" || got[1].Name != "PHONE_NUMBER" {
+		t.Errorf("got info types %v", got)
+	}
+	if tr[0].PrimitiveTransformation.GetReplaceWithInfoTypeConfig() == nil {
+		t.Errorf("got %v, want a ReplaceWithInfoTypeConfig", tr[0].PrimitiveTransformation)
+	}
+}
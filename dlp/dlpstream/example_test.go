@@ -0,0 +1,80 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dlpstream_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	dlp "cloud.google.com/go/dlp/apiv2"
+	"cloud.google.com/go/dlp/apiv2/dlppb"
+	"cloud.google.com/go/dlp/dlpstream"
+)
+
+func ExampleDeidentify() {
+	ctx := context.Background()
+	client, err := dlp.NewClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	in, err := os.Open("app.log")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer in.Close()
+	out, err := os.Create("app.scrubbed.log")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer out.Close()
+
+	err = dlpstream.Deidentify(ctx, client, out, in, &dlpstream.Config{
+		Parent:           "projects/my-project/locations/global",
+		InspectConfig:    dlpstream.NewInspectConfig(dlppb.Likelihood_LIKELY, "EMAIL_ADDRESS", "CREDIT_CARD_NUMBER"),
+		DeidentifyConfig: dlpstream.Mask("#", "CREDIT_CARD_NUMBER"),
+	})
+	if err != nil {
+		// TODO: Handle error.
+	}
+}
+
+func ExampleInspect() {
+	ctx := context.Background()
+	client, err := dlp.NewClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	in, err := os.Open("app.log")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer in.Close()
+
+	err = dlpstream.Inspect(ctx, client, in, &dlpstream.Config{
+		Parent:          "projects/my-project/locations/global",
+		InspectTemplate: "projects/my-project/locations/global/inspectTemplates/logs",
+	}, func(f *dlppb.Finding) error {
+		fmt.Println(f.InfoType.Name, f.Location.ByteRange.Start)
+		return nil
+	})
+	if err != nil {
+		// TODO: Handle error.
+	}
+}
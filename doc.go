@@ -161,6 +161,9 @@ setting a timeout on the context passed to NewClient. Dialing is non-blocking,
 so timeouts would be ineffective and would only interfere with credential
 refreshing, which uses the same context.
 
+To install default retry settings, timeouts and headers on every method of a
+client at once, see [cloud.google.com/go/callpolicy].
+
 # Headers
 
 Regardless of which transport is used, request headers can be set in the same
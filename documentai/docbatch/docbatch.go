@@ -0,0 +1,300 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package docbatch processes the documents under a Cloud Storage prefix with
+// the batch processing of the client in cloud.google.com/go/documentai/apiv1,
+// and reads the results back.
+//
+// Process starts the batch, waits for it to complete, and downloads the
+// sharded Document JSON files written for each input document, merging them
+// into one Document:
+//
+//	results, err := docbatch.Process(ctx, client, storageClient, &docbatch.Config{
+//		Processor: "projects/my-project/locations/us/processors/my-processor",
+//		Input:     "gs://my-bucket/invoices/",
+//		Output:    "gs://my-bucket/invoices-out/",
+//	})
+//	if err != nil {
+//		// TODO: Handle error.
+//	}
+//	for _, r := range results {
+//		if r.Err != nil {
+//			// TODO: Handle the failure of r.Input.
+//			continue
+//		}
+//		for _, a := range docbatch.EntityAnchors(r.Document) {
+//			fmt.Println(a.Entity.Type, a.Text, a.PageNumber)
+//		}
+//	}
+//
+// MergeShards and EntityAnchors are also useful for documents read by other
+// means.
+package docbatch // import "cloud.google.com/go/documentai/docbatch"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	documentai "cloud.google.com/go/documentai/apiv1"
+	"cloud.google.com/go/documentai/apiv1/documentaipb"
+	"cloud.google.com/go/storage"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	defaultPollInterval = 30 * time.Second
+	defaultConcurrency  = 8
+)
+
+// Config configures Process.
+type Config struct {
+	// Processor is the processor, or processor version, that processes the
+	// documents, of the form
+	// projects/{project}/locations/{location}/processors/{processor}, with
+	// an optional /processorVersions/{version} suffix. Required.
+	Processor string
+
+	// Input is the gs:// prefix of the documents to process. Required.
+	Input string
+
+	// Output is the gs:// URI of the directory that the results are written
+	// to. Required.
+	Output string
+
+	// ProcessOptions are the options of the processing. Optional.
+	ProcessOptions *documentaipb.ProcessOptions
+
+	// SkipHumanReview is as in BatchProcessRequest.
+	SkipHumanReview bool
+
+	// PollInterval is the time between two checks of the operation. The
+	// default is 30 seconds.
+	PollInterval time.Duration
+
+	// OnProgress, if set, is called with the metadata of the operation each
+	// time it is checked.
+	OnProgress func(*documentaipb.BatchProcessMetadata)
+
+	// Concurrency is the maximum number of documents downloaded at a time.
+	// The default is 8.
+	Concurrency int
+}
+
+// A Result is the result of processing one document.
+type Result struct {
+	// Input is the gs:// URI of the document.
+	Input string
+
+	// Document is the processed document, with its shards merged. It is nil
+	// if Err is set.
+	Document *documentaipb.Document
+
+	// Err is the error processing the document, as returned by
+	// status.ErrorProto, or the error reading its result.
+	Err error
+}
+
+// batchOperation is implemented by *documentai.BatchProcessDocumentsOperation.
+type batchOperation interface {
+	Poll(ctx context.Context, opts ...gax.CallOption) (*documentaipb.BatchProcessResponse, error)
+	Metadata() (*documentaipb.BatchProcessMetadata, error)
+	Done() bool
+}
+
+// objectReader reads the objects of Cloud Storage.
+type objectReader interface {
+	// list returns the names of the objects of bucket with a prefix.
+	list(ctx context.Context, bucket, prefix string) ([]string, error)
+	read(ctx context.Context, bucket, name string) ([]byte, error)
+}
+
+type storageReader struct {
+	c *storage.Client
+}
+
+func (s storageReader) list(ctx context.Context, bucket, prefix string) ([]string, error) {
+	var names []string
+	it := s.c.Bucket(bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return names, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, attrs.Name)
+	}
+}
+
+func (s storageReader) read(ctx context.Context, bucket, name string) ([]byte, error) {
+	r, err := s.c.Bucket(bucket).Object(name).NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// Process processes the documents under cfg.Input with BatchProcessDocuments,
+// waits for the operation to complete, and reads the results from
+// cfg.Output with gcs. It returns a result for each document. It returns an
+// error if the operation fails or ctx is done, in which case the operation
+// keeps running.
+func Process(ctx context.Context, client *documentai.DocumentProcessorClient, gcs *storage.Client, cfg *Config) ([]*Result, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	op, err := client.BatchProcessDocuments(ctx, &documentaipb.BatchProcessRequest{
+		Name: cfg.Processor,
+		InputDocuments: &documentaipb.BatchDocumentsInputConfig{
+			Source: &documentaipb.BatchDocumentsInputConfig_GcsPrefix{
+				GcsPrefix: &documentaipb.GcsPrefix{GcsUriPrefix: cfg.Input},
+			},
+		},
+		DocumentOutputConfig: &documentaipb.DocumentOutputConfig{
+			Destination: &documentaipb.DocumentOutputConfig_GcsOutputConfig_{
+				GcsOutputConfig: &documentaipb.DocumentOutputConfig_GcsOutputConfig{GcsUri: cfg.Output},
+			},
+		},
+		ProcessOptions:  cfg.ProcessOptions,
+		SkipHumanReview: cfg.SkipHumanReview,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return wait(ctx, op, storageReader{gcs}, cfg)
+}
+
+func (cfg *Config) validate() error {
+	if cfg.Processor == "" {
+		return errors.New("docbatch: Config.Processor is required")
+	}
+	for _, uri := range []string{cfg.Input, cfg.Output} {
+		if !strings.HasPrefix(uri, "gs://") {
+			return fmt.Errorf("docbatch: %q is not a gs:// URI", uri)
+		}
+	}
+	return nil
+}
+
+func wait(ctx context.Context, op batchOperation, r objectReader, cfg *Config) ([]*Result, error) {
+	interval := cfg.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	for {
+		_, err := op.Poll(ctx)
+		if md, _ := op.Metadata(); md != nil && cfg.OnProgress != nil {
+			cfg.OnProgress(md)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if op.Done() {
+			break
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	md, err := op.Metadata()
+	if err != nil {
+		return nil, err
+	}
+	if md == nil {
+		return nil, errors.New("docbatch: the operation has no metadata")
+	}
+
+	statuses := md.IndividualProcessStatuses
+	results := make([]*Result, len(statuses))
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, s := range statuses {
+		results[i] = &Result{Input: s.InputGcsSource}
+		if s.Status.GetCode() != 0 {
+			results[i].Err = status.ErrorProto(s.Status)
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(res *Result, dest string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			res.Document, res.Err = readDocument(ctx, r, dest)
+		}(results[i], s.OutputGcsDestination)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// readDocument reads the Document JSON files under a gs:// URI, and merges
+// them.
+func readDocument(ctx context.Context, r objectReader, uri string) (*documentaipb.Document, error) {
+	bucket, prefix, err := parseGCSURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	names, err := r.list(ctx, bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var shards []*documentaipb.Document
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		b, err := r.read(ctx, bucket, name)
+		if err != nil {
+			return nil, err
+		}
+		doc := &documentaipb.Document{}
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(b, doc); err != nil {
+			return nil, fmt.Errorf("docbatch: parsing gs://%s/%s: %w", bucket, name, err)
+		}
+		shards = append(shards, doc)
+	}
+	if len(shards) == 0 {
+		return nil, fmt.Errorf("docbatch: no Document JSON files under %s", uri)
+	}
+	return MergeShards(shards)
+}
+
+func parseGCSURI(uri string) (bucket, name string, err error) {
+	rest, ok := strings.CutPrefix(uri, "gs://")
+	if !ok {
+		return "", "", fmt.Errorf("docbatch: %q is not a gs:// URI", uri)
+	}
+	bucket, name, _ = strings.Cut(rest, "/")
+	return bucket, name, nil
+}
@@ -0,0 +1,217 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docbatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/documentai/apiv1/documentaipb"
+	gax "github.com/googleapis/gax-go/v2"
+	statuspb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeOperation is done after polls polls.
+type fakeOperation struct {
+	md    *documentaipb.BatchProcessMetadata
+	polls int
+	err   error
+}
+
+func (op *fakeOperation) Poll(ctx context.Context, _ ...gax.CallOption) (*documentaipb.BatchProcessResponse, error) {
+	op.polls--
+	if op.Done() && op.err != nil {
+		return nil, op.err
+	}
+	return nil, nil
+}
+
+func (op *fakeOperation) Metadata() (*documentaipb.BatchProcessMetadata, error) {
+	return op.md, nil
+}
+
+func (op *fakeOperation) Done() bool {
+	return op.polls <= 0
+}
+
+// fakeBucket holds objects by their gs:// URI.
+type fakeBucket map[string][]byte
+
+func (b fakeBucket) list(ctx context.Context, bucket, prefix string) ([]string, error) {
+	var names []string
+	for uri := range b {
+		if name := strings.TrimPrefix(uri, "gs://"+bucket+"/"); name != uri && strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (b fakeBucket) read(ctx context.Context, bucket, name string) ([]byte, error) {
+	data, ok := b["gs://"+bucket+"/"+name]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+func textAnchor(start, end int64) *documentaipb.Document_TextAnchor {
+	return &documentaipb.Document_TextAnchor{TextSegments: []*documentaipb.Document_TextAnchor_TextSegment{{StartIndex: start, EndIndex: end}}}
+}
+
+// shards returns a document of two pages in two shards, with an entity on
+// each page.
+func shards() []*documentaipb.Document {
+	page := func(n int32, text string) *documentaipb.Document_Page {
+		return &documentaipb.Document_Page{PageNumber: n, Layout: &documentaipb.Document_Page_Layout{TextAnchor: textAnchor(0, int64(len(text)))}}
+	}
+	entity := func(typ string, start, end int64) *documentaipb.Document_Entity {
+		return &documentaipb.Document_Entity{
+			Type:       typ,
+			TextAnchor: textAnchor(start, end),
+			PageAnchor: &documentaipb.Document_PageAnchor{PageRefs: []*documentaipb.Document_PageAnchor_PageRef{{Page: 0}}},
+		}
+	}
+	return []*documentaipb.Document{
+		{
+			Text:      "Invoice 42\n",
+			Pages:     []*documentaipb.Document_Page{page(1, "Invoice 42\n")},
+			Entities:  []*documentaipb.Document_Entity{entity("invoice_id", 8, 10)},
+			ShardInfo: &documentaipb.Document_ShardInfo{ShardIndex: 0, ShardCount: 2},
+		},
+		{
+			Text:      "Total 10\n",
+			Pages:     []*documentaipb.Document_Page{page(2, "Total 10\n")},
+			Entities:  []*documentaipb.Document_Entity{entity("total", 6, 8)},
+			ShardInfo: &documentaipb.Document_ShardInfo{ShardIndex: 1, ShardCount: 2, TextOffset: 11},
+		},
+	}
+}
+
+func TestWait(t *testing.T) {
+	bucket := fakeBucket{}
+	for i, s := range shards() {
+		b, err := protojson.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bucket[fmt.Sprintf("gs://out/op/0/a-%d.json", i)] = b
+	}
+	// Another document with a similar prefix.
+	bucket["gs://out/op/01/a-0.json"] = []byte("{}")
+	op := &fakeOperation{polls: 3, md: &documentaipb.BatchProcessMetadata{
+		IndividualProcessStatuses: []*documentaipb.BatchProcessMetadata_IndividualProcessStatus{
+			{InputGcsSource: "gs://in/a.pdf", Status: &statuspb.Status{}, OutputGcsDestination: "gs://out/op/0"},
+			{InputGcsSource: "gs://in/b.pdf", Status: &statuspb.Status{Code: int32(codes.InvalidArgument), Message: "bad file"}},
+			{InputGcsSource: "gs://in/c.pdf", Status: &statuspb.Status{}, OutputGcsDestination: "gs://out/op/2"},
+		},
+	}}
+	progress := 0
+	cfg := &Config{PollInterval: time.Millisecond, OnProgress: func(*documentaipb.BatchProcessMetadata) { progress++ }}
+	results, err := wait(context.Background(), op, bucket, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress != 3 {
+		t.Errorf("got %d progress calls, want 3", progress)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	if r := results[0]; r.Err != nil || r.Input != "gs://in/a.pdf" || r.Document.Text != "Invoice 42\nTotal 10\n" {
+		t.Errorf("got %+v, want the merged document", r)
+	}
+	if status.Code(results[1].Err) != codes.InvalidArgument {
+		t.Errorf("got %v, want InvalidArgument", results[1].Err)
+	}
+	if results[2].Err == nil {
+		t.Error("got nil, want error for a missing output")
+	}
+
+	opErr := status.Error(codes.Internal, "failed")
+	if _, err := wait(context.Background(), &fakeOperation{polls: 1, err: opErr}, bucket, cfg); err != opErr {
+		t.Errorf("got %v, want %v", err, opErr)
+	}
+}
+
+func TestMergeShards(t *testing.T) {
+	in := shards()
+	// The shards may be in any order.
+	got, err := MergeShards([]*documentaipb.Document{in[1], in[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &documentaipb.Document{
+		Text: "Invoice 42\nTotal 10\n",
+		Pages: []*documentaipb.Document_Page{
+			{PageNumber: 1, Layout: &documentaipb.Document_Page_Layout{TextAnchor: textAnchor(0, 11)}},
+			{PageNumber: 2, Layout: &documentaipb.Document_Page_Layout{TextAnchor: textAnchor(11, 20)}},
+		},
+		Entities: []*documentaipb.Document_Entity{
+			{Type: "invoice_id", TextAnchor: textAnchor(8, 10), PageAnchor: &documentaipb.Document_PageAnchor{PageRefs: []*documentaipb.Document_PageAnchor_PageRef{{Page: 0}}}},
+			{Type: "total", TextAnchor: textAnchor(17, 19), PageAnchor: &documentaipb.Document_PageAnchor{PageRefs: []*documentaipb.Document_PageAnchor_PageRef{{Page: 1}}}},
+		},
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("got  %v\nwant %v", got, want)
+	}
+	// The shards are not modified.
+	if !proto.Equal(in[1], shards()[1]) {
+		t.Error("MergeShards modified a shard")
+	}
+
+	if _, err := MergeShards(in[:1]); err == nil {
+		t.Error("got nil, want error for a missing shard")
+	}
+	in[1].ShardInfo.TextOffset = 5
+	if _, err := MergeShards(in); err == nil {
+		t.Error("got nil, want error for a wrong text offset")
+	}
+}
+
+func TestEntityAnchors(t *testing.T) {
+	doc, err := MergeShards(shards())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An entity without page references, with a property.
+	doc.Entities = append(doc.Entities, &documentaipb.Document_Entity{
+		Type:       "line_item",
+		TextAnchor: textAnchor(11, 19),
+		Properties: []*documentaipb.Document_Entity{{Type: "amount", MentionText: "10"}},
+	})
+	var got []string
+	for _, a := range EntityAnchors(doc) {
+		parent := ""
+		if a.Parent != nil {
+			parent = a.Parent.Type + "/"
+		}
+		got = append(got, fmt.Sprintf("%s%s:%s:%d", parent, a.Entity.Type, a.Text, a.PageNumber))
+	}
+	want := []string{"invoice_id:42:1", "total:10:2", "line_item:Total 10:2", "line_item/amount:10:0"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docbatch
+
+import (
+	"strings"
+
+	"cloud.google.com/go/documentai/apiv1/documentaipb"
+)
+
+// An EntityAnchor is where an entity of a document appears.
+type EntityAnchor struct {
+	// Entity is the entity.
+	Entity *documentaipb.Document_Entity
+
+	// Parent is the entity that Entity is a property of, or nil.
+	Parent *documentaipb.Document_Entity
+
+	// Text is the text of the entity in the document, or its mention text
+	// if it has no text anchor.
+	Text string
+
+	// PageNumber is the number of the page that the entity is on, starting
+	// at 1, or 0 if it is not known.
+	PageNumber int32
+
+	// BoundingPoly is the bounding polygon of the entity on the page, or nil
+	// if it is not known.
+	BoundingPoly *documentaipb.BoundingPoly
+}
+
+// EntityAnchors returns the anchors of the entities of doc, and of their
+// properties, in order, with one anchor per page reference of an entity. The
+// page of an entity without page references is the page whose text contains
+// the start of the entity's text, if any.
+func EntityAnchors(doc *documentaipb.Document) []*EntityAnchor {
+	var anchors []*EntityAnchor
+	var add func(e, parent *documentaipb.Document_Entity)
+	add = func(e, parent *documentaipb.Document_Entity) {
+		text := anchorText(doc, e.TextAnchor)
+		if text == "" {
+			text = e.MentionText
+		}
+		refs := e.GetPageAnchor().GetPageRefs()
+		for _, ref := range refs {
+			a := &EntityAnchor{Entity: e, Parent: parent, Text: text, BoundingPoly: ref.BoundingPoly}
+			if ref.Page >= 0 && ref.Page < int64(len(doc.Pages)) {
+				a.PageNumber = doc.Pages[ref.Page].PageNumber
+			}
+			anchors = append(anchors, a)
+		}
+		if len(refs) == 0 {
+			anchors = append(anchors, &EntityAnchor{
+				Entity:     e,
+				Parent:     parent,
+				Text:       text,
+				PageNumber: pageOfText(doc, e.TextAnchor),
+			})
+		}
+		for _, p := range e.Properties {
+			add(p, e)
+		}
+	}
+	for _, e := range doc.Entities {
+		add(e, nil)
+	}
+	return anchors
+}
+
+// anchorText returns the text of a text anchor.
+func anchorText(doc *documentaipb.Document, a *documentaipb.Document_TextAnchor) string {
+	var b strings.Builder
+	for _, s := range a.GetTextSegments() {
+		if s.StartIndex >= 0 && s.StartIndex <= s.EndIndex && s.EndIndex <= int64(len(doc.Text)) {
+			b.WriteString(doc.Text[s.StartIndex:s.EndIndex])
+		}
+	}
+	return b.String()
+}
+
+// pageOfText returns the number of the page whose text contains the start of
+// a text anchor, or 0.
+func pageOfText(doc *documentaipb.Document, a *documentaipb.Document_TextAnchor) int32 {
+	segs := a.GetTextSegments()
+	if len(segs) == 0 {
+		return 0
+	}
+	start := segs[0].StartIndex
+	for _, p := range doc.Pages {
+		for _, s := range p.GetLayout().GetTextAnchor().GetTextSegments() {
+			if s.StartIndex <= start && start < s.EndIndex {
+				return p.PageNumber
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,62 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docbatch_test
+
+import (
+	"context"
+	"fmt"
+
+	documentai "cloud.google.com/go/documentai/apiv1"
+	"cloud.google.com/go/documentai/apiv1/documentaipb"
+	"cloud.google.com/go/documentai/docbatch"
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+func ExampleProcess() {
+	ctx := context.Background()
+	// The endpoint of the client is in the location of the processor.
+	client, err := documentai.NewDocumentProcessorClient(ctx, option.WithEndpoint("us-documentai.googleapis.com:443"))
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+	gcs, err := storage.NewClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer gcs.Close()
+
+	results, err := docbatch.Process(ctx, client, gcs, &docbatch.Config{
+		Processor: "projects/my-project/locations/us/processors/my-processor",
+		Input:     "gs://my-bucket/invoices/",
+		Output:    "gs://my-bucket/invoices-out/",
+		OnProgress: func(md *documentaipb.BatchProcessMetadata) {
+			fmt.Println(md.State)
+		},
+	})
+	if err != nil {
+		// TODO: Handle error.
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Printf("%s: %v\n", r.Input, r.Err)
+			continue
+		}
+		for _, a := range docbatch.EntityAnchors(r.Document) {
+			fmt.Printf("%s: %s = %q on page %d\n", r.Input, a.Entity.Type, a.Text, a.PageNumber)
+		}
+	}
+}
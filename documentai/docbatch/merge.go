@@ -0,0 +1,113 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docbatch
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"cloud.google.com/go/documentai/apiv1/documentaipb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MergeShards merges the shards of a document into one document, which is
+// not sharded. The shards may be in any order. The text anchors of each
+// shard are moved by the text offset of the shard, and its page anchors by
+// the number of pages of the shards before it. The shards are not modified.
+func MergeShards(shards []*documentaipb.Document) (*documentaipb.Document, error) {
+	if len(shards) == 0 {
+		return nil, errors.New("docbatch: no shards")
+	}
+	shards = append([]*documentaipb.Document(nil), shards...)
+	sortShards(shards)
+	if n := shards[0].GetShardInfo().GetShardCount(); n > 0 && int64(len(shards)) != n {
+		return nil, fmt.Errorf("docbatch: got %d shards of a document with %d", len(shards), n)
+	}
+	merged := proto.Clone(shards[0]).(*documentaipb.Document)
+	merged.ShardInfo = nil
+	if len(shards) == 1 {
+		return merged, nil
+	}
+	var text strings.Builder
+	text.WriteString(merged.Text)
+	pages := int64(len(merged.Pages))
+	for i, s := range shards[1:] {
+		if got, want := s.GetShardInfo().GetShardIndex(), int64(i+1); got != want {
+			return nil, fmt.Errorf("docbatch: got shard %d, want shard %d", got, want)
+		}
+		offset := s.GetShardInfo().GetTextOffset()
+		if offset != int64(text.Len()) {
+			return nil, fmt.Errorf("docbatch: shard %d has text offset %d, want %d", i+1, offset, text.Len())
+		}
+		s = proto.Clone(s).(*documentaipb.Document)
+		shiftAnchors(s.ProtoReflect(), offset, pages)
+		text.WriteString(s.Text)
+		pages += int64(len(s.Pages))
+		merged.Pages = append(merged.Pages, s.Pages...)
+		merged.Entities = append(merged.Entities, s.Entities...)
+		merged.EntityRelations = append(merged.EntityRelations, s.EntityRelations...)
+		merged.TextChanges = append(merged.TextChanges, s.TextChanges...)
+	}
+	merged.Text = text.String()
+	return merged, nil
+}
+
+// shiftAnchors moves the text anchors in m, and in the messages it holds, by
+// textOffset, and their page anchors by pageOffset.
+func shiftAnchors(m protoreflect.Message, textOffset, pageOffset int64) {
+	switch a := m.Interface().(type) {
+	case *documentaipb.Document_TextAnchor:
+		for _, s := range a.TextSegments {
+			s.StartIndex += textOffset
+			s.EndIndex += textOffset
+		}
+		return
+	case *documentaipb.Document_PageAnchor_PageRef:
+		a.Page += pageOffset
+		return
+	}
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() == nil {
+			return true
+		}
+		switch {
+		case fd.IsList():
+			l := v.List()
+			for i := 0; i < l.Len(); i++ {
+				shiftAnchors(l.Get(i).Message(), textOffset, pageOffset)
+			}
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+					shiftAnchors(v.Message(), textOffset, pageOffset)
+					return true
+				})
+			}
+		default:
+			shiftAnchors(v.Message(), textOffset, pageOffset)
+		}
+		return true
+	})
+}
+
+// sortShards sorts shards by their shard index.
+func sortShards(shards []*documentaipb.Document) {
+	sort.SliceStable(shards, func(i, j int) bool {
+		return shards[i].GetShardInfo().GetShardIndex() < shards[j].GetShardInfo().GetShardIndex()
+	})
+}
@@ -18,6 +18,10 @@
 // or as per https://cloud.google.com/error-reporting/reference/rest/v1beta1/projects.events/report#ReportedErrorEvent
 // for language specific stacktrace formats.
 //
+// Client.Handler, Client.UnaryServerInterceptor and
+// Client.StreamServerInterceptor return middleware that reports the panics
+// that occur in HTTP and gRPC servers.
+//
 // This package is still experimental and subject to change.
 //
 // See https://cloud.google.com/error-reporting/ for more information.
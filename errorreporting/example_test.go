@@ -18,8 +18,11 @@ import (
 	"context"
 	"errors"
 	"log"
+	"net/http"
+	"time"
 
 	"cloud.google.com/go/errorreporting"
+	"google.golang.org/grpc"
 )
 
 func Example() {
@@ -50,3 +53,40 @@ func Example() {
 func doSomething() error {
 	return errors.New("something went wrong")
 }
+
+func ExampleClient_Handler() {
+	ctx := context.Background()
+	ec, err := errorreporting.NewClient(ctx, "my-gcp-project", errorreporting.Config{
+		ServiceName: "myservice",
+	})
+	if err != nil {
+		// TODO: handle error
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		panic("something went wrong")
+	})
+	// Panics are reported, and the client receives a 500 response.
+	h := ec.Handler(mux, &errorreporting.PanicOptions{
+		User: func(_ context.Context, r *http.Request) string {
+			return r.Header.Get("X-User-ID")
+		},
+		DuplicateInterval: time.Minute,
+	})
+	log.Fatal(http.ListenAndServe(":8080", h))
+}
+
+func ExampleClient_UnaryServerInterceptor() {
+	ctx := context.Background()
+	ec, err := errorreporting.NewClient(ctx, "my-gcp-project", errorreporting.Config{
+		ServiceName: "myservice",
+	})
+	if err != nil {
+		// TODO: handle error
+	}
+	opts := &errorreporting.PanicOptions{DuplicateInterval: time.Minute}
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(ec.UnaryServerInterceptor(opts)),
+		grpc.ChainStreamInterceptor(ec.StreamServerInterceptor(opts)))
+	_ = srv // TODO: Register services and call srv.Serve.
+}
@@ -0,0 +1,254 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errorreporting
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PanicOptions configures the middleware returned by Client.Handler,
+// Client.UnaryServerInterceptor and Client.StreamServerInterceptor.
+type PanicOptions struct {
+	// Repanic makes the middleware panic again with the recovered value after
+	// the panic is reported. net/http recovers panics in handlers, logs them
+	// and closes the connection, so the HTTP server keeps running; gRPC
+	// servers don't, so the program crashes unless another interceptor
+	// recovers the panic. By default, the HTTP middleware responds with
+	// status 500 and the gRPC interceptors return an error with code
+	// Internal.
+	Repanic bool
+
+	// User, if not nil, returns an identifier for the user affected by the
+	// panic. For the HTTP middleware, r is the request being served; for the
+	// gRPC interceptors, r is nil and ctx is the context of the call.
+	User func(ctx context.Context, r *http.Request) string
+
+	// DuplicateInterval, if positive, is the minimum time between two reports
+	// of panics with the same value and location. Duplicates within the
+	// interval are counted, and the count is included in the next report.
+	DuplicateInterval time.Duration
+}
+
+// Handler returns an http.Handler that calls h and reports the panics that
+// occur while serving requests, together with the request's method, URL,
+// user agent, referrer and remote address. opts may be nil.
+//
+// Panics with the value http.ErrAbortHandler are not reported and are
+// propagated, since they are used to abort a response. If h already started
+// writing the response when it panicked, the status can no longer be changed,
+// so the middleware panics with http.ErrAbortHandler after reporting the panic
+// to abort the response instead of sending a truncated one.
+func (c *Client) Handler(h http.Handler, opts *PanicOptions) http.Handler {
+	p := c.newPanicReporter(opts)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{ResponseWriter: w}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			p.report(r.Context(), r, fmt.Errorf("panic: %v", v), v)
+			if rw.wroteHeader {
+				panic(http.ErrAbortHandler)
+			}
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}()
+		h.ServeHTTP(rw, r)
+	})
+}
+
+// responseWriter records whether the response headers were written.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	// Informational responses other than 101 Switching Protocols can be
+	// followed by another status.
+	if code >= 200 || code == http.StatusSwitchingProtocols {
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher if the underlying ResponseWriter does.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter, so that an
+// http.ResponseController can access its other methods.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// UnaryServerInterceptor returns a gRPC interceptor that reports the panics
+// that occur in unary RPC handlers. opts may be nil.
+func (c *Client) UnaryServerInterceptor(opts *PanicOptions) grpc.UnaryServerInterceptor {
+	p := c.newPanicReporter(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (_ interface{}, err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = p.reportRPC(ctx, info.FullMethod, v)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a gRPC interceptor that reports the panics
+// that occur in streaming RPC handlers. opts may be nil.
+func (c *Client) StreamServerInterceptor(opts *PanicOptions) grpc.StreamServerInterceptor {
+	p := c.newPanicReporter(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = p.reportRPC(ss.Context(), info.FullMethod, v)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// panicReporter reports recovered panics according to PanicOptions.
+type panicReporter struct {
+	c    *Client
+	opts PanicOptions
+
+	mu   sync.Mutex
+	seen map[string]*panicRecord // by panic key
+}
+
+type panicRecord struct {
+	reported   time.Time
+	suppressed int
+}
+
+func (c *Client) newPanicReporter(opts *PanicOptions) *panicReporter {
+	p := &panicReporter{c: c, seen: map[string]*panicRecord{}}
+	if opts != nil {
+		p.opts = *opts
+	}
+	return p
+}
+
+// reportRPC reports a panic in the gRPC method, and returns the error for the
+// RPC.
+func (p *panicReporter) reportRPC(ctx context.Context, method string, v interface{}) error {
+	p.report(ctx, nil, fmt.Errorf("panic in %s: %v", method, v), v)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// report reports err, unless it is a duplicate, and panics again with v if
+// the options require it. It must be called by the deferred function that
+// recovered v.
+func (p *panicReporter) report(ctx context.Context, r *http.Request, err error, v interface{}) {
+	key := fmt.Sprintf("%v\n%s", v, panicLocation())
+	if n, ok := p.admit(key); !ok {
+		if p.opts.Repanic {
+			panic(v)
+		}
+		return
+	} else if n > 0 {
+		err = fmt.Errorf("%w (%d similar panics suppressed)", err, n)
+	}
+	e := Entry{Error: err, Req: r, Stack: debug.Stack()}
+	if p.opts.User != nil {
+		e.User = p.opts.User(ctx, r)
+	}
+	p.c.Report(e)
+	if p.opts.Repanic {
+		// Send the report before the program crashes.
+		p.c.Flush()
+		panic(v)
+	}
+}
+
+// admit reports whether the panic with the given key should be reported, and
+// returns the number of duplicates that were suppressed since it was last
+// reported.
+func (p *panicReporter) admit(key string) (int, bool) {
+	if p.opts.DuplicateInterval <= 0 {
+		return 0, true
+	}
+	now := time.Now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	rec, ok := p.seen[key]
+	if ok && now.Sub(rec.reported) < p.opts.DuplicateInterval {
+		rec.suppressed++
+		return 0, false
+	}
+	if !ok {
+		// Forget the panics that would no longer be duplicates, so that the
+		// map doesn't grow without bound. Their suppressed counts are lost.
+		for k, old := range p.seen {
+			if now.Sub(old.reported) >= p.opts.DuplicateInterval {
+				delete(p.seen, k)
+			}
+		}
+		rec = &panicRecord{}
+		p.seen[key] = rec
+	}
+	n := rec.suppressed
+	rec.reported = now
+	rec.suppressed = 0
+	return n, true
+}
+
+// panicLocation returns the location of the function that panicked, as
+// "function file:line". It must be called while a deferred function is
+// recovering from the panic.
+func panicLocation() string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	afterPanic := false
+	for {
+		f, more := frames.Next()
+		if afterPanic && !strings.HasPrefix(f.Function, "runtime.") {
+			return fmt.Sprintf("%s %s:%d", f.Function, f.File, f.Line)
+		}
+		if f.Function == "runtime.gopanic" {
+			afterPanic = true
+		}
+		if !more {
+			return ""
+		}
+	}
+}
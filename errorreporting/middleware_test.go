@@ -0,0 +1,197 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errorreporting
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "cloud.google.com/go/errorreporting/apiv1beta1/errorreportingpb"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recordingClient records all the error reports it receives.
+type recordingClient struct {
+	mu   sync.Mutex
+	reqs []*pb.ReportErrorEventRequest
+}
+
+func (c *recordingClient) ReportErrorEvent(_ context.Context, req *pb.ReportErrorEventRequest, _ ...gax.CallOption) (*pb.ReportErrorEventResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reqs = append(c.reqs, req)
+	return &pb.ReportErrorEventResponse{}, nil
+}
+
+func (c *recordingClient) Close() error { return nil }
+
+func (c *recordingClient) requests() []*pb.ReportErrorEventRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reqs
+}
+
+func newRecordingClient(t *testing.T) (*Client, *recordingClient) {
+	rc := &recordingClient{}
+	newClient = func(ctx context.Context, opts ...option.ClientOption) (client, error) {
+		return rc, nil
+	}
+	c, err := NewClient(context.Background(), "P", defaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, rc
+}
+
+func TestHandler(t *testing.T) {
+	c, rc := newRecordingClient(t)
+	h := c.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/abort" {
+			panic(http.ErrAbortHandler)
+		}
+		panic("boom")
+	}), &PanicOptions{
+		User:              func(_ context.Context, r *http.Request) string { return r.Header.Get("X-User") },
+		DuplicateInterval: time.Hour,
+	})
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("GET", "/path", nil)
+		req.Header.Set("X-User", "alice")
+		req.Header.Set("User-Agent", "test-agent")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("got status %d, want 500", w.Code)
+		}
+	}
+	func() {
+		defer func() {
+			if v := recover(); v != http.ErrAbortHandler {
+				t.Errorf("got panic %v, want http.ErrAbortHandler", v)
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/abort", nil))
+	}()
+	c.Flush()
+
+	reqs := rc.requests()
+	// The second panic is a duplicate, and the abort is not reported.
+	if len(reqs) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reqs))
+	}
+	ev := reqs[0].Event
+	if !strings.HasPrefix(ev.Message, "panic: boom\n") || !strings.Contains(ev.Message, "errorreporting.TestHandler") {
+		t.Errorf("got message %q, want panic and stack", ev.Message)
+	}
+	if got, want := ev.Context.User, "alice"; got != want {
+		t.Errorf("got user %q, want %q", got, want)
+	}
+	if got, want := ev.Context.HttpRequest.Url, "example.com/path"; got != want {
+		t.Errorf("got URL %q, want %q", got, want)
+	}
+	if got, want := ev.Context.HttpRequest.UserAgent, "test-agent"; got != want {
+		t.Errorf("got user agent %q, want %q", got, want)
+	}
+}
+
+func TestHandlerRepanic(t *testing.T) {
+	c, rc := newRecordingClient(t)
+	h := c.Handler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}), &PanicOptions{Repanic: true})
+	func() {
+		defer func() {
+			if v := recover(); v != "boom" {
+				t.Errorf("got panic %v, want boom", v)
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}()
+	// The report is flushed before panicking again.
+	if got := len(rc.requests()); got != 1 {
+		t.Errorf("got %d reports, want 1", got)
+	}
+}
+
+func TestHandlerAfterWrite(t *testing.T) {
+	c, rc := newRecordingClient(t)
+	h := c.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if _, ok := w.(http.Flusher); !ok {
+			t.Error("ResponseWriter is not an http.Flusher")
+		}
+		w.Write([]byte("partial"))
+		panic("boom")
+	}), nil)
+	w := httptest.NewRecorder()
+	func() {
+		defer func() {
+			if v := recover(); v != http.ErrAbortHandler {
+				t.Errorf("got panic %v, want http.ErrAbortHandler", v)
+			}
+		}()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	}()
+	c.Flush()
+	if got, want := w.Body.String(), "partial"; got != want {
+		t.Errorf("got body %q, want %q", got, want)
+	}
+	if got := len(rc.requests()); got != 1 {
+		t.Errorf("got %d reports, want 1", got)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	c, rc := newRecordingClient(t)
+	intercept := c.UnaryServerInterceptor(nil)
+	_, err := intercept(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Service/Method"},
+		func(context.Context, interface{}) (interface{}, error) { panic("boom") })
+	if status.Code(err) != codes.Internal {
+		t.Errorf("got %v, want Internal error", err)
+	}
+	c.Flush()
+	reqs := rc.requests()
+	if len(reqs) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reqs))
+	}
+	if msg := reqs[0].Event.Message; !strings.HasPrefix(msg, "panic in /pkg.Service/Method: boom\n") {
+		t.Errorf("got message %q", msg)
+	}
+}
+
+func TestPanicReporterAdmit(t *testing.T) {
+	p := (&Client{}).newPanicReporter(&PanicOptions{DuplicateInterval: time.Minute})
+	for i, want := range []bool{true, false, false} {
+		if _, ok := p.admit("k"); ok != want {
+			t.Errorf("%d: got %t, want %t", i, ok, want)
+		}
+	}
+	if _, ok := p.admit("other"); !ok {
+		t.Error("got false for a different panic, want true")
+	}
+	p.seen["k"].reported = time.Now().Add(-time.Hour)
+	if n, ok := p.admit("k"); !ok || n != 2 {
+		t.Errorf("got (%d, %t), want (2, true)", n, ok)
+	}
+}
@@ -94,6 +94,69 @@ func (j *BulkWriterJob) setError(e error) {
 	close(j.resultChan)
 }
 
+// BulkWriterOptions configures a BulkWriter created with
+// Client.BulkWriterWithOptions.
+type BulkWriterOptions struct {
+	// MaxPendingWrites, if positive, bounds the number of writes that have been
+	// enqueued but whose results have not been received yet. When the limit is
+	// reached, the methods that enqueue writes block until the results of
+	// earlier writes are received, or until the context of the BulkWriter is
+	// done. This bounds the memory used by a BulkWriter that receives writes
+	// faster than Firestore accepts them.
+	MaxPendingWrites int
+
+	// OnBatchSuccess, if non-nil, is called after each batch of writes has
+	// been sent successfully. Individual writes of the batch may still have
+	// failed; see BulkWriterBatchResult.
+	OnBatchSuccess func(BulkWriterBatchResult)
+
+	// OnBatchError, if non-nil, is called after a batch of writes could not
+	// be sent. The Err field of the result contains the error, which is also
+	// returned by the Results method of every job in the batch.
+	OnBatchError func(BulkWriterBatchResult)
+}
+
+// BulkWriterBatchResult describes the outcome of sending a batch of writes.
+// The batch callbacks of BulkWriterOptions may be called concurrently from
+// multiple goroutines.
+type BulkWriterBatchResult struct {
+	// Writes is the number of writes in the batch.
+	Writes int
+	// Succeeded is the number of writes that were applied.
+	Succeeded int
+	// Retried is the number of writes that failed and were enqueued again.
+	Retried int
+	// Failed is the number of writes that failed permanently.
+	Failed int
+	// Latency is the duration of the request.
+	Latency time.Duration
+	// Err is the error returned by the request, if any.
+	Err error
+}
+
+// BulkWriterStats is a snapshot of the state of a BulkWriter.
+type BulkWriterStats struct {
+	// PendingWrites is the number of writes that have been enqueued but whose
+	// results have not been received yet, including writes that are being
+	// retried.
+	PendingWrites int
+	// InFlightBatches is the number of batches that are currently being sent.
+	InFlightBatches int
+	// InFlightWrites is the number of writes in the batches that are currently
+	// being sent.
+	InFlightWrites int
+	// BlockedCallers is the number of calls that are blocked because of the
+	// rate limit or because MaxPendingWrites was reached.
+	BlockedCallers int
+	// MaxWritesPerSecond is the current rate limit for enqueuing writes.
+	MaxWritesPerSecond float64
+	// Succeeded, Failed and Retried count the writes that succeeded, failed
+	// permanently and were retried since the BulkWriter was created.
+	Succeeded int64
+	Failed    int64
+	Retried   int64
+}
+
 // A BulkWriter supports concurrent writes to multiple documents. The BulkWriter
 // submits document writes in maximum batches of 20 writes per request. Each
 // request can contain many different document writes: create, delete, update,
@@ -114,10 +177,15 @@ type BulkWriter struct {
 	ctx             context.Context  // context for canceling all BulkWriter operations
 	isOpenLock      sync.RWMutex     // guards against setting isOpen concurrently
 	isOpen          bool             // flag that the BulkWriter is closed
+	opts            BulkWriterOptions
+	slots           chan struct{} // one element per pending write, if MaxPendingWrites is set
+
+	statsLock sync.Mutex      // guards stats
+	stats     BulkWriterStats // current state; MaxWritesPerSecond is filled in by Stats
 }
 
 // newBulkWriter creates a new instance of the BulkWriter.
-func newBulkWriter(ctx context.Context, c *Client, database string) *BulkWriter {
+func newBulkWriter(ctx context.Context, c *Client, database string, opts *BulkWriterOptions) *BulkWriter {
 	// Although typically we shouldn't store Context objects, in this case we
 	// need to pass this Context through to the Bundler handler.
 	ctx = withResourceHeader(ctx, c.path())
@@ -138,6 +206,17 @@ func newBulkWriter(ctx context.Context, c *Client, database string) *BulkWriter
 	bw.bundler.HandlerLimit = bw.maxOpsPerSecond
 	bw.bundler.BundleCountThreshold = maxBatchSize
 
+	if opts != nil {
+		bw.opts = *opts
+	}
+	if n := bw.opts.MaxPendingWrites; n > 0 {
+		bw.slots = make(chan struct{}, n)
+		if n < maxBatchSize {
+			// Send batches as soon as no more writes can be enqueued.
+			bw.bundler.BundleCountThreshold = n
+		}
+	}
+
 	return bw
 }
 
@@ -158,6 +237,23 @@ func (bw *BulkWriter) Flush() {
 	bw.bundler.Flush()
 }
 
+// Stats returns a snapshot of the state of the BulkWriter, such as the number
+// of pending writes and the current rate limit. Use it to monitor the progress
+// of a BulkWriter and whether it is throttled.
+func (bw *BulkWriter) Stats() BulkWriterStats {
+	bw.statsLock.Lock()
+	st := bw.stats
+	bw.statsLock.Unlock()
+	st.MaxWritesPerSecond = float64(bw.limiter.Limit())
+	return st
+}
+
+func (bw *BulkWriter) updateStats(f func(st *BulkWriterStats)) {
+	bw.statsLock.Lock()
+	f(&bw.stats)
+	bw.statsLock.Unlock()
+}
+
 // Create adds a document creation write to the queue of writes to send.
 // Note: You cannot write to (Create, Update, Set, or Delete) the same document more than once.
 func (bw *BulkWriter) Create(doc *DocumentRef, datum interface{}) (*BulkWriterJob, error) {
@@ -177,8 +273,7 @@ func (bw *BulkWriter) Create(doc *DocumentRef, datum interface{}) (*BulkWriterJo
 		return nil, fmt.Errorf("firestore: too many document writes sent to bulkwriter")
 	}
 
-	j := bw.write(w[0])
-	return j, nil
+	return bw.write(w[0])
 }
 
 // Delete adds a document deletion write to the queue of writes to send.
@@ -200,8 +295,7 @@ func (bw *BulkWriter) Delete(doc *DocumentRef, preconds ...Precondition) (*BulkW
 		return nil, fmt.Errorf("firestore: too many document writes sent to bulkwriter")
 	}
 
-	j := bw.write(w[0])
-	return j, nil
+	return bw.write(w[0])
 }
 
 // Set adds a document set write to the queue of writes to send.
@@ -223,8 +317,7 @@ func (bw *BulkWriter) Set(doc *DocumentRef, datum interface{}, opts ...SetOption
 		return nil, fmt.Errorf("firestore: too many writes sent to bulkwriter")
 	}
 
-	j := bw.write(w[0])
-	return j, nil
+	return bw.write(w[0])
 }
 
 // Update adds a document update write to the queue of writes to send.
@@ -246,8 +339,7 @@ func (bw *BulkWriter) Update(doc *DocumentRef, updates []Update, preconds ...Pre
 		return nil, fmt.Errorf("firestore: too many writes sent to bulkwriter")
 	}
 
-	j := bw.write(w[0])
-	return j, nil
+	return bw.write(w[0])
 }
 
 // checkConditions determines whether this write attempt is valid. It returns
@@ -272,8 +364,9 @@ func (bw *BulkWriter) checkWriteConditions(doc *DocumentRef) error {
 	return nil
 }
 
-// write packages up write requests into bulkWriterJob objects.
-func (bw *BulkWriter) write(w *pb.Write) *BulkWriterJob {
+// write packages up write requests into bulkWriterJob objects. It blocks
+// while the BulkWriter is throttled.
+func (bw *BulkWriter) write(w *pb.Write) (*BulkWriterJob, error) {
 
 	j := &BulkWriterJob{
 		resultChan: make(chan bulkWriterResult, 1),
@@ -281,12 +374,61 @@ func (bw *BulkWriter) write(w *pb.Write) *BulkWriterJob {
 		ctx:        bw.ctx,
 	}
 
-	bw.limiter.Wait(bw.ctx)
+	bw.updateStats(func(st *BulkWriterStats) { st.BlockedCallers++ })
+	err := bw.acquireSlot()
+	if err == nil {
+		err = bw.limiter.Wait(bw.ctx)
+		if err != nil {
+			bw.releaseSlot()
+		}
+	}
+	bw.updateStats(func(st *BulkWriterStats) {
+		st.BlockedCallers--
+		if err == nil {
+			st.PendingWrites++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
 	// ignore operation size constraints and related errors; can't be inferred at compile time
 	// Bundler is set to accept an unlimited amount of bytes
 	_ = bw.bundler.Add(j, 0)
 
-	return j
+	return j, nil
+}
+
+// acquireSlot blocks until the number of pending writes is below
+// MaxPendingWrites.
+func (bw *BulkWriter) acquireSlot() error {
+	if bw.slots == nil {
+		return nil
+	}
+	select {
+	case bw.slots <- struct{}{}:
+		return nil
+	case <-bw.ctx.Done():
+		return bw.ctx.Err()
+	}
+}
+
+func (bw *BulkWriter) releaseSlot() {
+	if bw.slots != nil {
+		<-bw.slots
+	}
+}
+
+// jobDone records that the result of a job has been received.
+func (bw *BulkWriter) jobDone(failed bool) {
+	bw.releaseSlot()
+	bw.updateStats(func(st *BulkWriterStats) {
+		st.PendingWrites--
+		if failed {
+			st.Failed++
+		} else {
+			st.Succeeded++
+		}
+	})
 }
 
 // send transmits writes to the service and matches response results to job channels.
@@ -308,15 +450,35 @@ func (bw *BulkWriter) send(i interface{}) {
 		Labels:   map[string]string{},
 	}
 
+	bw.updateStats(func(st *BulkWriterStats) {
+		st.InFlightBatches++
+		st.InFlightWrites += len(bwj)
+	})
+	defer bw.updateStats(func(st *BulkWriterStats) {
+		st.InFlightBatches--
+		st.InFlightWrites -= len(bwj)
+	})
+
 	select {
 	case <-bw.ctx.Done():
+		for range bwj {
+			bw.jobDone(true)
+		}
 		return
 	default:
+		start := time.Now()
 		resp, err := bw.vc.BatchWrite(bw.ctx, bwr)
+		result := BulkWriterBatchResult{Writes: len(bwj), Latency: time.Since(start)}
 		if err != nil {
 			// Do we need to be selective about what kind of errors we send?
 			for _, j := range bwj {
 				j.setError(err)
+				bw.jobDone(true)
+			}
+			result.Failed = len(bwj)
+			result.Err = err
+			if bw.opts.OnBatchError != nil {
+				bw.opts.OnBatchError(result)
 			}
 			return
 		}
@@ -330,17 +492,26 @@ func (bw *BulkWriter) send(i interface{}) {
 
 				// Do we need separate retry bundler?
 				if j.attempts < maxRetryAttempts {
+					result.Retried++
+					bw.updateStats(func(st *BulkWriterStats) { st.Retried++ })
 					// ignore operation size constraints and related errors; job size can't be inferred at compile time
 					// Bundler is set to accept an unlimited amount of bytes
 					_ = bw.bundler.Add(j, 0)
 				} else {
+					result.Failed++
 					j.setError(status.Error(codes.Code(s.Code), s.Message))
+					bw.jobDone(true)
 				}
 				continue
 			}
 
+			result.Succeeded++
 			bwj[i].resultChan <- bulkWriterResult{err: nil, result: res}
 			close(bwj[i].resultChan)
+			bw.jobDone(false)
+		}
+		if bw.opts.OnBatchSuccess != nil {
+			bw.opts.OnBatchSuccess(result)
 		}
 	}
 }
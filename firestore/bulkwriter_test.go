@@ -16,11 +16,13 @@ package firestore
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
 	"google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
 )
 
 type bulkwriterTestCase struct {
@@ -251,3 +253,99 @@ func TestBulkWriterErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestBulkWriterCallbacksAndStats(t *testing.T) {
+	c, srv, cleanup := newMock(t)
+	defer cleanup()
+
+	docPrefix := c.Collection("C").Path + "/"
+	deleteReq := func(ids ...string) *pb.BatchWriteRequest {
+		req := &pb.BatchWriteRequest{Database: c.path()}
+		for _, id := range ids {
+			req.Writes = append(req.Writes, &pb.Write{Operation: &pb.Write_Delete{Delete: docPrefix + id}})
+		}
+		return req
+	}
+	okResp := &pb.BatchWriteResponse{
+		WriteResults: []*pb.WriteResult{{UpdateTime: aTimestamp}},
+		Status:       []*status.Status{{Code: int32(codes.OK)}},
+	}
+	srv.addRPC(deleteReq("a"), okResp)
+	srv.addRPC(deleteReq("b"), okResp)
+	srv.addRPC(deleteReq("c"), grpcstatus.Error(codes.PermissionDenied, "denied"))
+
+	var mu sync.Mutex
+	var successes, failures []BulkWriterBatchResult
+	bw := c.BulkWriterWithOptions(context.Background(), &BulkWriterOptions{
+		MaxPendingWrites: 1,
+		OnBatchSuccess: func(r BulkWriterBatchResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			successes = append(successes, r)
+		},
+		OnBatchError: func(r BulkWriterBatchResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			failures = append(failures, r)
+		},
+	})
+	// With at most one pending write, each write is sent in its own batch and
+	// each call waits for the result of the previous write.
+	var jobs []*BulkWriterJob
+	for _, id := range []string{"a", "b", "c"} {
+		j, err := bw.Delete(c.Doc("C/" + id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		jobs = append(jobs, j)
+	}
+	bw.End()
+	for i, j := range jobs {
+		_, err := j.Results()
+		if wantErr := i == 2; (err != nil) != wantErr {
+			t.Errorf("job %d: got error %v, want error: %t", i, err, wantErr)
+		}
+	}
+
+	if got, want := len(successes), 2; got != want {
+		t.Fatalf("got %d successful batches, want %d", got, want)
+	}
+	for _, r := range successes {
+		if r.Writes != 1 || r.Succeeded != 1 || r.Err != nil {
+			t.Errorf("got successful batch %+v, want one succeeded write", r)
+		}
+	}
+	if got, want := len(failures), 1; got != want {
+		t.Fatalf("got %d failed batches, want %d", got, want)
+	}
+	if r := failures[0]; r.Failed != 1 || grpcstatus.Code(r.Err) != codes.PermissionDenied {
+		t.Errorf("got failed batch %+v, want PermissionDenied", r)
+	}
+	st := bw.Stats()
+	if st.PendingWrites != 0 || st.InFlightBatches != 0 || st.BlockedCallers != 0 || st.Succeeded != 2 || st.Failed != 1 {
+		t.Errorf("got stats %+v, want 2 succeeded and 1 failed write and nothing pending", st)
+	}
+	if st.MaxWritesPerSecond != maxWritesPerSecond {
+		t.Errorf("got max writes per second %v, want %v", st.MaxWritesPerSecond, maxWritesPerSecond)
+	}
+}
+
+func TestBulkWriterMaxPendingWritesCanceled(t *testing.T) {
+	c, _, cleanup := newMock(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bw := c.BulkWriterWithOptions(ctx, &BulkWriterOptions{MaxPendingWrites: 1})
+	// Occupy the only slot, so that the next write blocks until the context
+	// is canceled.
+	bw.slots <- struct{}{}
+	done := make(chan error, 1)
+	go func() {
+		_, err := bw.Delete(c.Doc("C/a"))
+		done <- err
+	}()
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
@@ -360,10 +360,17 @@ func (c *Client) Batch() *WriteBatch {
 // The context passed to the BulkWriter remains stored through the lifecycle
 // of the object. This context allows callers to cancel BulkWriter operations.
 func (c *Client) BulkWriter(ctx context.Context) *BulkWriter {
-	bw := newBulkWriter(ctx, c, c.path())
+	bw := newBulkWriter(ctx, c, c.path(), nil)
 	return bw
 }
 
+// BulkWriterWithOptions is like BulkWriter, but configures the BulkWriter with
+// opts, for example to bound the number of pending writes or to observe the
+// progress of the BulkWriter.
+func (c *Client) BulkWriterWithOptions(ctx context.Context, opts *BulkWriterOptions) *BulkWriter {
+	return newBulkWriter(ctx, c, c.path(), opts)
+}
+
 // WithReadOptions specifies constraints for accessing documents from the database,
 // e.g. at what time snapshot to read the documents.
 func (c *Client) WithReadOptions(opts ...ReadOption) *Client {
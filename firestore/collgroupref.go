@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"sync"
 
 	"cloud.google.com/go/firestore/apiv1/firestorepb"
 	"google.golang.org/api/iterator"
@@ -69,6 +70,66 @@ func (cgr CollectionGroupRef) GetPartitionedQueries(ctx context.Context, partiti
 	return queries, nil
 }
 
+// ForEachPartition partitions the collection group like GetPartitionedQueries
+// and calls f for each partition query, using up to workers goroutines. If
+// workers is not positive, all partitions are processed concurrently. The index
+// passed to f identifies the partition; partitions are in document order, so
+// processing them in index order visits the documents of the collection group
+// in the order of their paths.
+//
+// ForEachPartition returns after all calls to f have returned. If a call to f
+// returns an error, the context passed to the remaining calls is canceled, no
+// further partitions are started, and ForEachPartition returns the first error.
+func (cgr CollectionGroupRef) ForEachPartition(ctx context.Context, partitionCount, workers int, f func(ctx context.Context, index int, q Query) error) error {
+	queries, err := cgr.GetPartitionedQueries(ctx, partitionCount)
+	if err != nil {
+		return err
+	}
+	if workers <= 0 || workers > len(queries) {
+		workers = len(queries)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	indexes := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if ctx.Err() != nil {
+					continue
+				}
+				if err := f(ctx, i, queries[i]); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+loop:
+	for i := range queries {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
 // getPartitions returns a slice of queryPartition objects, describing a start
 // and end range to query a subsection of the collection group. partitionCount
 // must be a positive value and the number of returned partitions may be less
@@ -16,7 +16,11 @@ package firestore
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
+
+	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
 )
 
 func TestCGR_TestQueryPartition_ToQuery(t *testing.T) {
@@ -71,3 +75,61 @@ func TestCGR_TestGetPartitions(t *testing.T) {
 		t.Errorf("got %+v, want %+v", got, want)
 	}
 }
+
+func TestCGR_ForEachPartition(t *testing.T) {
+	ctx := context.Background()
+	c, srv, cleanup := newMock(t)
+	defer cleanup()
+
+	cgr := c.CollectionGroup("C")
+	sq, err := cgr.query().OrderBy(DocumentID, Asc).toProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &pb.PartitionQueryRequest{
+		Parent:         c.path() + "/documents",
+		PartitionCount: 3,
+		QueryType:      &pb.PartitionQueryRequest_StructuredQuery{StructuredQuery: sq},
+	}
+	resp := &pb.PartitionQueryResponse{Partitions: []*pb.Cursor{
+		{Values: []*pb.Value{refval(c.path() + "/documents/C/m")}},
+		{Values: []*pb.Value{refval(c.path() + "/documents/C/d")}},
+	}}
+
+	srv.addRPC(req, resp)
+	var mu sync.Mutex
+	got := map[int]Query{}
+	err = cgr.ForEachPartition(ctx, 3, 2, func(_ context.Context, i int, q Query) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got[i] = q
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d partitions, want 3", len(got))
+	}
+	// Partitions are sorted by their split points.
+	if got, want := got[0].endVals, []interface{}{"documents/C/d"}; !testEqual(got, want) {
+		t.Errorf("got end of first partition %v, want %v", got, want)
+	}
+	if got, want := got[2].startVals, []interface{}{"documents/C/m"}; !testEqual(got, want) {
+		t.Errorf("got start of last partition %v, want %v", got, want)
+	}
+
+	srv.addRPC(req, resp)
+	wantErr := errors.New("failed")
+	var calls int
+	err = cgr.ForEachPartition(ctx, 3, 1, func(ctx context.Context, i int, q Query) error {
+		calls++
+		return wantErr
+	})
+	if err != wantErr {
+		t.Errorf("got error %v, want %v", err, wantErr)
+	}
+	if calls != 1 {
+		t.Errorf("got %d calls after an error, want 1", calls)
+	}
+}
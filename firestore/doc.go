@@ -84,6 +84,18 @@ Note that this client supports struct tags beginning with "firestore:" that work
 the tags of the encoding/json package, letting you rename fields, ignore them, or
 omit their values when empty.
 
+If all the documents of a collection have the same structure, wrap the
+collection in a TypedCollection to read and write them as values of a Go type
+directly, without calling DataTo:
+
+	typedStates := firestore.NewTypedCollection[State](states)
+	nyData, err = typedStates.Doc("NewYork").Get(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	nyData.Population = 20.2
+	_, err = typedStates.Doc("NewYork").Update(ctx, nyData, "pop")
+
 To retrieve multiple documents from their references in a single call, use
 Client.GetAll.
 
@@ -171,6 +183,17 @@ build up a query using Select, Where and other methods of Query.
 Supported operators include '<', '<=', '>', '>=', '==', 'in', 'array-contains', and
 'array-contains-any'.
 
+Use WhereEntity with And, Or and Not to combine filters:
+
+	f := firestore.Or(
+		firestore.PropertyFilter{Path: "capital", Operator: "==", Value: "Sacramento"},
+		firestore.Not(firestore.PropertyFilter{Path: "pop", Operator: "<", Value: 20}),
+	)
+	q = states.WhereEntity(f)
+
+CheckFilter reports filters that Firestore cannot execute as a *FilterError, and
+filters that need a composite index as a *CompositeIndexError.
+
 Call the Query's Documents method to get an iterator, and use it like
 the other Google Cloud Client iterators.
 
@@ -192,6 +215,39 @@ as a query.
 
 	iter = client.Collection("States").Documents(ctx)
 
+Use an AggregationQuery to count the documents that match a query, or to compute
+the sum or average of a field, without retrieving the documents. The results can
+be read into a struct with AggregationResult.DataTo, or one by one with the
+Int64 and Float64 methods:
+
+	result, err := states.NewAggregationQuery().
+		WithCount("count").
+		WithAvg("pop", "avg_pop").
+		Get(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	count, err := result.Int64("count")
+
+An AggregationQuery can be run in a transaction with its Transaction method.
+
+# Vector Search
+
+Store embeddings in fields of type Vector64 or Vector32, and use FindNearest to
+find the documents whose embeddings are nearest to a query vector. Vector search
+requires a vector index on the vector field.
+
+When a document is read into a map[string]interface{}, or with
+DocumentSnapshot.Data, its vector fields are returned as Vector64 values.
+Earlier versions of this package returned the map that Firestore uses to
+represent vectors, so code that expects such maps must be updated.
+
+	vq := states.FindNearest("embedding", firestore.Vector32{0.1, 0.7, 0.2}, 5,
+		firestore.DistanceMeasureCosine, &firestore.FindNearestOptions{
+			DistanceResultField: "distance",
+		})
+	iter = vq.Documents(ctx)
+
 # Collection Group Partition Queries
 
 You can partition the documents of a Collection Group allowing for smaller subqueries.
@@ -199,6 +255,16 @@ You can partition the documents of a Collection Group allowing for smaller subqu
 	collectionGroup = client.CollectionGroup("States")
 	partitions, err = collectionGroup.GetPartitionedQueries(ctx, 20)
 
+To read the partitions in parallel, use ForEachPartition, which runs a function
+for each partition query on a pool of worker goroutines:
+
+	err = collectionGroup.ForEachPartition(ctx, 20, 4, func(ctx context.Context, i int, q firestore.Query) error {
+		iter := q.Documents(ctx)
+		defer iter.Stop()
+		// TODO: Process the documents of the partition.
+		return nil
+	})
+
 You can also Serialize/Deserialize queries making it possible to run/stream the
 queries elsewhere; another process or machine for instance.
 
@@ -790,9 +790,18 @@ func iterFetch(pageSize int, pageToken string, pi *iterator.PageInfo, next func(
 // Snapshots returns an iterator over snapshots of the document. Each time the document
 // changes or is added or deleted, a new snapshot will be generated.
 func (d *DocumentRef) Snapshots(ctx context.Context) *DocumentSnapshotIterator {
+	return d.SnapshotsWithOptions(ctx, nil)
+}
+
+// SnapshotsWithOptions is like Snapshots, but configures the listener with
+// opts. Use it to resume a listener from a resume token, or to monitor the
+// state of the listener stream.
+func (d *DocumentRef) SnapshotsWithOptions(ctx context.Context, opts *SnapshotOptions) *DocumentSnapshotIterator {
+	ws := newWatchStreamForDocument(ctx, d)
+	ws.applyOptions(opts)
 	return &DocumentSnapshotIterator{
 		docref: d,
-		ws:     newWatchStreamForDocument(ctx, d),
+		ws:     ws,
 	}
 }
 
@@ -813,19 +822,37 @@ type DocumentSnapshotIterator struct {
 // Next is not expected to return iterator.Done unless it is called after Stop.
 // Rarely, networking issues may also cause iterator.Done to be returned.
 func (it *DocumentSnapshotIterator) Next() (*DocumentSnapshot, error) {
-	btree, _, rt, err := it.ws.nextSnapshot()
-	if err != nil {
-		if err == io.EOF {
-			err = iterator.Done
+	for {
+		btree, changes, rt, resumed, err := it.ws.nextSnapshot()
+		if err != nil {
+			if err == io.EOF {
+				err = iterator.Done
+			}
+			// watchStream's error is sticky, so SnapshotIterator does not need to remember it.
+			return nil, err
 		}
-		// watchStream's error is sticky, so SnapshotIterator does not need to remember it.
-		return nil, err
+		if btree.Len() == 0 {
+			if resumed && len(changes) == 0 {
+				// The document did not change since the resume token.
+				continue
+			}
+			// document deleted
+			return &DocumentSnapshot{Ref: it.docref, ReadTime: rt}, nil
+		}
+		snap, _ := btree.At(0)
+		return snap.(*DocumentSnapshot), nil
 	}
-	if btree.Len() == 0 { // document deleted
-		return &DocumentSnapshot{Ref: it.docref, ReadTime: rt}, nil
+}
+
+// ResumeToken returns the token of the most recent snapshot returned by Next.
+// Pass it as the ResumeToken of SnapshotOptions to create a listener that
+// continues after that snapshot. ResumeToken returns nil if Next has not
+// returned a snapshot yet.
+func (it *DocumentSnapshotIterator) ResumeToken() []byte {
+	if !it.ws.hasReturned {
+		return nil
 	}
-	snap, _ := btree.At(0)
-	return snap.(*DocumentSnapshot), nil
+	return it.ws.resumeToken
 }
 
 // Stop stops receiving snapshots. You should always call Stop when you are done with
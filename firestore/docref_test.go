@@ -26,6 +26,7 @@ import (
 	"google.golang.org/genproto/googleapis/type/latlng"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -381,3 +382,52 @@ func TestDocRef_WithReadOptions(t *testing.T) {
 	}
 
 }
+
+func TestDocSnapshotsResumedUnchanged(t *testing.T) {
+	const dbPath = "projects/projectID/databases/(default)"
+	ctx := context.Background()
+	c, srv, cleanup := newMock(t)
+	defer cleanup()
+
+	it := c.Doc("C/a").SnapshotsWithOptions(ctx, &SnapshotOptions{ResumeToken: []byte("tok1")})
+	defer it.Stop()
+	srv.addRPC(&pb.ListenRequest{
+		Database:     dbPath,
+		TargetChange: &pb.ListenRequest_AddTarget{AddTarget: proto.Clone(it.ws.target).(*pb.Target)},
+	}, []interface{}{
+		// The document did not change since the resume token, so the target
+		// becomes current without it.
+		&pb.ListenResponse{ResponseType: &pb.ListenResponse_TargetChange{TargetChange: &pb.TargetChange{
+			TargetChangeType: pb.TargetChange_CURRENT,
+		}}},
+		&pb.ListenResponse{ResponseType: &pb.ListenResponse_TargetChange{TargetChange: &pb.TargetChange{
+			TargetChangeType: pb.TargetChange_NO_CHANGE,
+			ReadTime:         aTimestamp2,
+			ResumeToken:      []byte("tok2"),
+		}}},
+		&pb.ListenResponse{ResponseType: &pb.ListenResponse_DocumentChange{DocumentChange: &pb.DocumentChange{
+			Document: &pb.Document{
+				Name:       dbPath + "/documents/C/a",
+				CreateTime: aTimestamp,
+				UpdateTime: aTimestamp3,
+				Fields:     map[string]*pb.Value{"f": intval(2)},
+			},
+			TargetIds: []int32{watchTargetID},
+		}}},
+		&pb.ListenResponse{ResponseType: &pb.ListenResponse_TargetChange{TargetChange: &pb.TargetChange{
+			TargetChangeType: pb.TargetChange_NO_CHANGE,
+			ReadTime:         aTimestamp3,
+			ResumeToken:      []byte("tok3"),
+		}}},
+	})
+	snap, err := it.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snap.Exists() {
+		t.Fatal("got a snapshot of a missing document, want the next change of the document")
+	}
+	if got, want := snap.Data()["f"], int64(2); got != want {
+		t.Errorf("got f = %v, want %v", got, want)
+	}
+}
@@ -101,6 +101,9 @@ func (d *DocumentSnapshot) Data() map[string]interface{} {
 //     maps of key type string and any value type are permitted, and are populated
 //     recursively.
 //   - References are converted to *firestore.DocumentRefs.
+//   - Vectors, which Firestore stores as maps whose "__type__" field is
+//     "__vector__", convert to Vector64, not to map[string]interface{}. When
+//     setting a struct field, the field may also be a Vector32.
 //
 // Field names given by struct field tags are observed, as described in
 // DocumentRef.Create.
@@ -19,6 +19,7 @@ package firestore_test
 import (
 	"context"
 	"fmt"
+	"log"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
@@ -483,6 +484,146 @@ func ExampleQuery_Snapshots() {
 	}
 }
 
+func ExampleQuery_SnapshotsWithOptions() {
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, "project-id")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	var token []byte // TODO: Load the token saved by a previous run, if any.
+	iter := client.Collection("Orders").SnapshotsWithOptions(ctx, &firestore.SnapshotOptions{
+		ResumeToken: token,
+		OnStateChange: func(sc firestore.ListenerStateChange) {
+			if sc.State == firestore.ListenerRetrying {
+				log.Printf("listener retrying in %s: %v", sc.Backoff, sc.Err)
+			}
+		},
+	})
+	defer iter.Stop()
+	for {
+		qsnap, err := iter.Next()
+		if err != nil {
+			// TODO: Handle error.
+		}
+		for _, change := range qsnap.Changes {
+			_ = change // TODO: Process the change.
+		}
+		token = qsnap.ResumeToken // TODO: Save the token.
+	}
+}
+
+func ExampleAggregationResult_DataTo() {
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, "project-id")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	result, err := client.Collection("States").NewAggregationQuery().
+		WithCount("count").
+		WithSum("pop", "total_pop").
+		WithAvg("pop", "avg_pop").
+		Get(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+
+	var stats struct {
+		Count    int64    `firestore:"count"`
+		TotalPop float64  `firestore:"total_pop"`
+		AvgPop   *float64 `firestore:"avg_pop"` // nil if there are no states
+	}
+	if err := result.DataTo(&stats); err != nil {
+		// TODO: Handle error.
+	}
+	fmt.Println(stats.Count, stats.TotalPop)
+}
+
+func ExampleQuery_FindNearest() {
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, "project-id")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	threshold := 0.3
+	vq := client.Collection("Recipes").
+		Where("cuisine", "==", "italian").
+		FindNearest("embedding", firestore.Vector32{0.1, 0.7, 0.2}, 10, firestore.DistanceMeasureCosine,
+			&firestore.FindNearestOptions{
+				DistanceThreshold:   &threshold,
+				DistanceResultField: "distance",
+			})
+	docs, err := vq.Documents(ctx).GetAll()
+	if err != nil {
+		// TODO: Handle error.
+	}
+	for _, doc := range docs {
+		fmt.Println(doc.Ref.ID, doc.Data()["distance"])
+	}
+}
+
+func ExampleTypedCollection() {
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, "project-id")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	type State struct {
+		Capital    string  `firestore:"capital"`
+		Population float64 `firestore:"pop"` // in millions
+	}
+	states := firestore.NewTypedCollection[State](client.Collection("States"))
+	if _, err := states.Doc("NewYork").Set(ctx, State{Capital: "Albany", Population: 19.8}); err != nil {
+		// TODO: Handle error.
+	}
+	ny, err := states.Doc("NewYork").Get(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	fmt.Println(ny.Capital)
+
+	large, err := firestore.TypedQuery[State]{Query: states.Ref.Where("pop", ">", 10)}.GetAll(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	fmt.Println(len(large))
+}
+
+func ExampleTypedDoc_Snapshots() {
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, "project-id")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	type State struct {
+		Capital    string  `firestore:"capital"`
+		Population float64 `firestore:"pop"` // in millions
+	}
+	ny := firestore.NewTypedDoc[State](client.Doc("States/NewYork"))
+	iter := ny.Snapshots(ctx)
+	defer iter.Stop()
+	for {
+		snap, err := iter.Next()
+		if err != nil {
+			// TODO: Handle error.
+		}
+		if !snap.Exists() {
+			fmt.Println("deleted")
+			continue
+		}
+		fmt.Println(snap.Data.Population)
+	}
+}
+
 func ExampleDocumentIterator_Next() {
 	ctx := context.Background()
 	client, err := firestore.NewClient(ctx, "project-id")
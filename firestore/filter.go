@@ -0,0 +1,387 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firestore
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
+)
+
+// maxDisjunctions is the maximum number of disjunctions that Firestore allows
+// in the disjunctive normal form of a query filter.
+const maxDisjunctions = 30
+
+// And returns a filter that matches documents that match all of the given
+// filters.
+func And(filters ...EntityFilter) AndFilter {
+	return AndFilter{Filters: filters}
+}
+
+// Or returns a filter that matches documents that match at least one of the
+// given filters.
+func Or(filters ...EntityFilter) OrFilter {
+	return OrFilter{Filters: filters}
+}
+
+// Not returns a filter that matches the documents that do not match the given
+// filter. See NotFilter for details.
+func Not(filter EntityFilter) NotFilter {
+	return NotFilter{Filter: filter}
+}
+
+// NotFilter represents the negation of a filter.
+//
+// Firestore does not support negation directly. A NotFilter is converted to
+// an equivalent filter by negating the operators of its property filters, for
+// example "<" becomes ">=" and "in" becomes "not-in", and by applying De
+// Morgan's laws to composite filters. As Firestore only matches documents that
+// contain the filtered field with a value of a comparable type, documents that
+// do not contain the field match neither a property filter nor its negation.
+//
+// Equality comparisons with nil or NaN are negated to the corresponding "!="
+// comparisons, which match the documents whose field is not null or not NaN.
+// The "array-contains" and "array-contains-any" operators cannot be negated,
+// and using them in a NotFilter results in an error when the filter is added
+// to a query.
+type NotFilter struct {
+	Filter EntityFilter
+}
+
+func (NotFilter) isCompositeFilter() {}
+
+func (f NotFilter) toProto() (*pb.StructuredQuery_Filter, error) {
+	nf, err := negateFilter(f.Filter)
+	if err != nil {
+		return nil, err
+	}
+	return nf.toProto()
+}
+
+var negatedOperators = map[string]string{
+	"==":     "!=",
+	"!=":     "==",
+	"<":      ">=",
+	"<=":     ">",
+	">":      "<=",
+	">=":     "<",
+	"in":     "not-in",
+	"not-in": "in",
+}
+
+// negateFilter returns a filter that matches the documents that do not match
+// f.
+func negateFilter(f EntityFilter) (EntityFilter, error) {
+	switch f := f.(type) {
+	case NotFilter:
+		return f.Filter, nil
+	case AndFilter:
+		filters, err := negateFilters(f.Filters)
+		if err != nil {
+			return nil, err
+		}
+		return OrFilter{Filters: filters}, nil
+	case OrFilter:
+		filters, err := negateFilters(f.Filters)
+		if err != nil {
+			return nil, err
+		}
+		return AndFilter{Filters: filters}, nil
+	case PropertyFilter:
+		ppf, err := f.toPropertyPathFilter()
+		if err != nil {
+			return nil, err
+		}
+		return negateFilter(ppf)
+	case PropertyPathFilter:
+		op, ok := negatedOperators[f.Operator]
+		if !ok {
+			return nil, &FilterError{Reason: fmt.Sprintf("cannot negate operator %q", f.Operator)}
+		}
+		return PropertyPathFilter{Path: f.Path, Operator: op, Value: f.Value}, nil
+	default:
+		return nil, &FilterError{Reason: fmt.Sprintf("cannot negate filter of type %T", f)}
+	}
+}
+
+func negateFilters(filters []EntityFilter) ([]EntityFilter, error) {
+	negated := make([]EntityFilter, len(filters))
+	for i, f := range filters {
+		nf, err := negateFilter(f)
+		if err != nil {
+			return nil, err
+		}
+		negated[i] = nf
+	}
+	return negated, nil
+}
+
+// FilterError is returned by CheckFilter for a filter that Firestore cannot
+// execute.
+type FilterError struct {
+	Reason string
+}
+
+func (e *FilterError) Error() string {
+	return "firestore: invalid filter: " + e.Reason
+}
+
+// CompositeIndexError is returned by CheckFilter for a filter that can only be
+// executed if the database has a composite index on the given fields.
+type CompositeIndexError struct {
+	// Fields contains the fields that must be part of the composite index.
+	Fields []string
+	// Reason describes why a composite index is needed.
+	Reason string
+}
+
+func (e *CompositeIndexError) Error() string {
+	return fmt.Sprintf("firestore: filter requires a composite index on (%s): %s", strings.Join(e.Fields, ", "), e.Reason)
+}
+
+// CheckFilter checks whether Firestore can execute the given filter. It returns
+// a *FilterError if the filter is invalid, for example because it combines
+// operators that cannot be used together in a single query, or because its
+// disjunctive normal form contains more than 30 disjunctions.
+//
+// CheckFilter returns a *CompositeIndexError if the filter is valid, but can
+// only be executed if the database has a composite index. This is the case if
+// the filter contains range or inequality comparisons on more than one field,
+// or combines a range or inequality comparison on one field with a comparison
+// on another field. Queries that only use equality comparisons can be served by
+// single-field indexes. Note that the ordering of a query can also require a
+// composite index; this is not checked by CheckFilter.
+//
+// Use errors.As to distinguish the two types of errors.
+//
+// Query.Where, Query.WherePath and Query.WhereEntity perform the same checks,
+// except for the composite index check, and set the error of the query if
+// they fail.
+func CheckFilter(f EntityFilter) error {
+	if _, err := f.toProto(); err != nil {
+		return err
+	}
+	dnf, err := validateFilter(f)
+	if err != nil {
+		return err
+	}
+	for _, conj := range dnf {
+		if err := checkCompositeIndex(conj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateFilter returns a *FilterError if Firestore cannot execute f, and
+// otherwise the disjunctive normal form of f.
+func validateFilter(f EntityFilter) ([][]fieldComparison, error) {
+	dnf, err := disjunctiveNormalForm(f)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	if err := countOperators(f, counts); err != nil {
+		return nil, err
+	}
+	for _, conj := range dnf {
+		arrayContains := 0
+		for _, c := range conj {
+			if c.op == "array-contains" || c.op == "array-contains-any" {
+				arrayContains++
+			}
+		}
+		if arrayContains > 1 {
+			return nil, &FilterError{Reason: "a disjunction can contain at most one array-contains or array-contains-any filter"}
+		}
+	}
+	if counts["not-in"] > 0 {
+		switch {
+		case counts["not-in"] > 1:
+			return nil, &FilterError{Reason: "a filter can contain at most one not-in operator"}
+		case counts["!="] > 0:
+			return nil, &FilterError{Reason: "not-in cannot be combined with !="}
+		case counts["in"] > 0 || counts["array-contains-any"] > 0:
+			return nil, &FilterError{Reason: "not-in cannot be combined with in or array-contains-any"}
+		case len(dnf) > 1:
+			return nil, &FilterError{Reason: "not-in cannot be combined with an OR filter"}
+		}
+	}
+	return dnf, nil
+}
+
+// countOperators counts the number of times that each operator is used in f.
+func countOperators(f EntityFilter, counts map[string]int) error {
+	switch f := f.(type) {
+	case NotFilter:
+		nf, err := negateFilter(f.Filter)
+		if err != nil {
+			return err
+		}
+		return countOperators(nf, counts)
+	case AndFilter:
+		return countFilterOperators(f.Filters, counts)
+	case OrFilter:
+		return countFilterOperators(f.Filters, counts)
+	case PropertyFilter:
+		counts[f.Operator]++
+	case PropertyPathFilter:
+		counts[f.Operator]++
+	}
+	return nil
+}
+
+func countFilterOperators(filters []EntityFilter, counts map[string]int) error {
+	for _, f := range filters {
+		if err := countOperators(f, counts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldComparison is a comparison of a field with a value in a filter.
+type fieldComparison struct {
+	field string
+	op    string
+}
+
+var inequalityOperators = map[string]bool{
+	"<":      true,
+	"<=":     true,
+	">":      true,
+	">=":     true,
+	"!=":     true,
+	"not-in": true,
+}
+
+func checkCompositeIndex(conj []fieldComparison) error {
+	inequalities := make(map[string]bool)
+	others := make(map[string]bool)
+	for _, c := range conj {
+		if inequalityOperators[c.op] {
+			inequalities[c.field] = true
+		}
+	}
+	for _, c := range conj {
+		if !inequalities[c.field] {
+			others[c.field] = true
+		}
+	}
+	var reason string
+	switch {
+	case len(inequalities) > 1:
+		reason = "range or inequality comparisons on more than one field"
+	case len(inequalities) == 1 && len(others) > 0:
+		reason = "a range or inequality comparison combined with a comparison on another field"
+	default:
+		return nil
+	}
+	var fields []string
+	for f := range others {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	// Fields with an inequality must be last in the index.
+	var ineq []string
+	for f := range inequalities {
+		ineq = append(ineq, f)
+	}
+	sort.Strings(ineq)
+	return &CompositeIndexError{Fields: append(fields, ineq...), Reason: reason}
+}
+
+// disjunctiveNormalForm returns the filter as a list of conjunctions of field
+// comparisons. It returns an error if the result would contain more than
+// maxDisjunctions conjunctions.
+func disjunctiveNormalForm(f EntityFilter) ([][]fieldComparison, error) {
+	switch f := f.(type) {
+	case NotFilter:
+		nf, err := negateFilter(f.Filter)
+		if err != nil {
+			return nil, err
+		}
+		return disjunctiveNormalForm(nf)
+	case OrFilter:
+		if len(f.Filters) == 0 {
+			return nil, &FilterError{Reason: "an OR filter must contain at least one filter"}
+		}
+		var result [][]fieldComparison
+		for _, sub := range f.Filters {
+			dnf, err := disjunctiveNormalForm(sub)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, dnf...)
+			if len(result) > maxDisjunctions {
+				return nil, errTooManyDisjunctions
+			}
+		}
+		return result, nil
+	case AndFilter:
+		if len(f.Filters) == 0 {
+			return nil, &FilterError{Reason: "an AND filter must contain at least one filter"}
+		}
+		result := [][]fieldComparison{nil}
+		for _, sub := range f.Filters {
+			dnf, err := disjunctiveNormalForm(sub)
+			if err != nil {
+				return nil, err
+			}
+			if len(result)*len(dnf) > maxDisjunctions {
+				return nil, errTooManyDisjunctions
+			}
+			var product [][]fieldComparison
+			for _, left := range result {
+				for _, right := range dnf {
+					conj := append(append([]fieldComparison(nil), left...), right...)
+					product = append(product, conj)
+				}
+			}
+			result = product
+		}
+		return result, nil
+	case PropertyFilter:
+		ppf, err := f.toPropertyPathFilter()
+		if err != nil {
+			return nil, err
+		}
+		return disjunctiveNormalForm(ppf)
+	case PropertyPathFilter:
+		c := fieldComparison{field: strings.Join(f.Path, "."), op: f.Operator}
+		n := 1
+		if f.Operator == "in" || f.Operator == "array-contains-any" {
+			// Firestore expands these operators into one disjunction per value.
+			if v := reflect.ValueOf(f.Value); v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+				n = v.Len()
+			}
+			if n > maxDisjunctions {
+				return nil, errTooManyDisjunctions
+			}
+		}
+		result := make([][]fieldComparison, n)
+		for i := range result {
+			result[i] = []fieldComparison{c}
+		}
+		return result, nil
+	default:
+		return nil, &FilterError{Reason: fmt.Sprintf("unsupported filter type %T", f)}
+	}
+}
+
+var errTooManyDisjunctions = &FilterError{Reason: fmt.Sprintf("the filter contains more than %d disjunctions", maxDisjunctions)}
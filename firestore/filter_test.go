@@ -0,0 +1,220 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firestore
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
+
+	"cloud.google.com/go/internal/testutil"
+)
+
+func TestNotFilter(t *testing.T) {
+	for _, test := range []struct {
+		in, want EntityFilter
+	}{
+		{
+			Not(PropertyFilter{"a", "<", 1}),
+			PropertyFilter{"a", ">=", 1},
+		},
+		{
+			Not(PropertyFilter{"a", "in", []int{1, 2}}),
+			PropertyFilter{"a", "not-in", []int{1, 2}},
+		},
+		{
+			Not(Not(PropertyFilter{"a", "==", 1})),
+			PropertyFilter{"a", "==", 1},
+		},
+		{
+			Not(And(PropertyFilter{"a", "==", 1}, PropertyPathFilter{[]string{"b"}, ">", 2})),
+			Or(PropertyFilter{"a", "!=", 1}, PropertyFilter{"b", "<=", 2}),
+		},
+		{
+			Not(Or(PropertyFilter{"a", "!=", 1}, PropertyFilter{"b", ">=", 2})),
+			And(PropertyFilter{"a", "==", 1}, PropertyFilter{"b", "<", 2}),
+		},
+		{
+			Not(PropertyFilter{"a", "==", nil}),
+			PropertyFilter{"a", "!=", nil},
+		},
+		{
+			Not(PropertyFilter{"a", "!=", math.NaN()}),
+			PropertyFilter{"a", "==", math.NaN()},
+		},
+	} {
+		got, err := test.in.toProto()
+		if err != nil {
+			t.Fatalf("%+v: %v", test.in, err)
+		}
+		want, err := test.want.toProto()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !testEqual(got, want) {
+			t.Errorf("%+v:\ngot  %v\nwant %v", test.in, got, want)
+		}
+	}
+
+	for _, in := range []EntityFilter{
+		Not(PropertyFilter{"a", "array-contains", 1}),
+		Not(PropertyFilter{"a", "array-contains-any", []int{1}}),
+		Not(And(PropertyFilter{"a", "==", 1}, PropertyFilter{"b", "array-contains", 2})),
+	} {
+		_, err := in.toProto()
+		var fe *FilterError
+		if !errors.As(err, &fe) {
+			t.Errorf("%+v: got %v, want *FilterError", in, err)
+		}
+	}
+}
+
+func TestCheckFilter(t *testing.T) {
+	manyValues := make([]int, maxDisjunctions+1)
+	for i := range manyValues {
+		manyValues[i] = i
+	}
+	or := func(n int) OrFilter {
+		var filters []EntityFilter
+		for i := 0; i < n; i++ {
+			filters = append(filters, PropertyFilter{"a", "==", i})
+		}
+		return Or(filters...)
+	}
+
+	// Valid filters that do not need a composite index.
+	for _, f := range []EntityFilter{
+		PropertyFilter{"a", ">", 1},
+		And(PropertyFilter{"a", "==", 1}, PropertyFilter{"b", "==", 2}),
+		And(PropertyFilter{"a", ">", 1}, PropertyFilter{"a", "<", 5}),
+		Or(PropertyFilter{"a", ">", 1}, PropertyFilter{"b", "==", 2}),
+		And(PropertyFilter{"tags", "array-contains", "x"}, PropertyFilter{"b", "==", 2}),
+		And(or(5), or(6)),
+	} {
+		if err := CheckFilter(f); err != nil {
+			t.Errorf("%+v: got %v, want nil", f, err)
+		}
+	}
+
+	// Valid filters that need a composite index.
+	for _, test := range []struct {
+		f          EntityFilter
+		wantFields []string
+	}{
+		{
+			And(PropertyFilter{"b", "==", 1}, PropertyFilter{"a", ">", 2}),
+			[]string{"b", "a"},
+		},
+		{
+			And(PropertyFilter{"a", ">", 1}, PropertyFilter{"b", "<", 2}),
+			[]string{"a", "b"},
+		},
+		{
+			Not(Or(PropertyFilter{"a", "!=", 1}, PropertyFilter{"b", "<", 2})),
+			[]string{"a", "b"},
+		},
+	} {
+		err := CheckFilter(test.f)
+		var ie *CompositeIndexError
+		if !errors.As(err, &ie) {
+			t.Errorf("%+v: got %v, want *CompositeIndexError", test.f, err)
+			continue
+		}
+		if !testutil.Equal(ie.Fields, test.wantFields) {
+			t.Errorf("%+v: got fields %v, want %v", test.f, ie.Fields, test.wantFields)
+		}
+	}
+
+	// Invalid filters.
+	for _, f := range []EntityFilter{
+		And(),
+		Or(),
+		And(PropertyFilter{"a", "not-in", []int{1}}, PropertyFilter{"a", "not-in", []int{2}}),
+		And(PropertyFilter{"a", "not-in", []int{1}}, PropertyFilter{"a", "!=", 3}),
+		And(PropertyFilter{"a", "not-in", []int{1}}, PropertyFilter{"b", "in", []int{2}}),
+		Or(PropertyFilter{"a", "not-in", []int{1}}, PropertyFilter{"b", "==", 2}),
+		And(PropertyFilter{"a", "array-contains", 1}, PropertyFilter{"b", "array-contains", 2}),
+		And(PropertyFilter{"a", "array-contains", 1}, PropertyFilter{"b", "array-contains-any", []int{2}}),
+		PropertyFilter{"a", "in", manyValues},
+		And(or(6), or(6)),
+		Or(or(20), or(11)),
+	} {
+		err := CheckFilter(f)
+		var fe *FilterError
+		if !errors.As(err, &fe) {
+			t.Errorf("%+v: got %v, want *FilterError", f, err)
+		}
+	}
+
+	if err := CheckFilter(PropertyFilter{"a", "~", 1}); err == nil {
+		t.Error("got nil for invalid operator, want error")
+	}
+}
+
+func TestWhereEntityNot(t *testing.T) {
+	c := &Client{projectID: "P", databaseID: "DB"}
+	got, err := c.Collection("C").WhereEntity(Not(PropertyFilter{"a", "<", 1})).query().toProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := c.Collection("C").Where("a", ">=", 1).query().toProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !testEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestNotFilterUnary(t *testing.T) {
+	for _, test := range []struct {
+		in   EntityFilter
+		want pb.StructuredQuery_UnaryFilter_Operator
+	}{
+		{Not(PropertyFilter{"a", "==", nil}), pb.StructuredQuery_UnaryFilter_IS_NOT_NULL},
+		{Not(PropertyFilter{"a", "==", math.NaN()}), pb.StructuredQuery_UnaryFilter_IS_NOT_NAN},
+		{Not(PropertyFilter{"a", "!=", nil}), pb.StructuredQuery_UnaryFilter_IS_NULL},
+	} {
+		got, err := test.in.toProto()
+		if err != nil {
+			t.Fatalf("%+v: %v", test.in, err)
+		}
+		if op := got.GetUnaryFilter().GetOp(); op != test.want {
+			t.Errorf("%+v: got operator %v, want %v", test.in, op, test.want)
+		}
+	}
+	if _, err := (PropertyFilter{"a", "<", nil}).toProto(); err == nil {
+		t.Error("got nil for an ordering comparison with nil, want error")
+	}
+}
+
+func TestWhereEntityInvalid(t *testing.T) {
+	c := &Client{projectID: "P", databaseID: "DB"}
+	q := c.Collection("C").WhereEntity(And(
+		PropertyFilter{"a", "array-contains", 1},
+		PropertyFilter{"b", "array-contains", 2},
+	))
+	var fe *FilterError
+	if !errors.As(q.err, &fe) {
+		t.Errorf("got %v, want *FilterError", q.err)
+	}
+	// A filter that needs a composite index is accepted.
+	q = c.Collection("C").WhereEntity(And(PropertyFilter{"a", ">", 1}, PropertyFilter{"b", "<", 2}))
+	if q.err != nil {
+		t.Errorf("got %v, want nil", q.err)
+	}
+}
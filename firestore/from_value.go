@@ -98,6 +98,9 @@ func setReflectFromProtoValue(v reflect.Value, vproto *pb.Value, c *Client) erro
 		}
 		v.Set(reflect.ValueOf(dr))
 		return nil
+
+	case typeOfVector32, typeOfVector64:
+		return setVectorFromProtoValue(v, vproto)
 	}
 
 	switch v.Kind() {
@@ -380,6 +383,13 @@ func createFromProtoValue(vproto *pb.Value, c *Client) (interface{}, error) {
 		return ret, nil
 
 	case *pb.Value_MapValue:
+		if isVectorValue(v.MapValue) {
+			fs, err := vectorFromProtoValue(vproto)
+			if err != nil {
+				return nil, err
+			}
+			return Vector64(fs), nil
+		}
 		fields := v.MapValue.Fields
 		ret := make(map[string]interface{}, len(fields))
 		for k, v := range fields {
@@ -187,6 +187,14 @@ func (s *mockServer) ListDocuments(ctx context.Context, req *pb.ListDocumentsReq
 	return nil, nil
 }
 
+func (s *mockServer) PartitionQuery(_ context.Context, req *pb.PartitionQueryRequest) (*pb.PartitionQueryResponse, error) {
+	res, err := s.popRPC(req)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*pb.PartitionQueryResponse), nil
+}
+
 func (s *mockServer) RunQuery(req *pb.RunQueryRequest, qs pb.Firestore_RunQueryServer) error {
 	res, err := s.popRPC(req)
 	if err != nil {
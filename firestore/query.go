@@ -61,6 +61,9 @@ type Query struct {
 	// readOptions specifies constraints for reading results from the query
 	// e.g. read time
 	readSettings *readSettings
+
+	// findNearest is set for vector queries created with FindNearest.
+	findNearest *pb.StructuredQuery_FindNearest
 }
 
 // DocumentID is the special field name representing the ID of a document
@@ -150,12 +153,19 @@ func (q Query) WherePath(fp FieldPath, op string, value interface{}) Query {
 // PropertyFilter and PropertyPathFilter are supported simple filters
 // AndFilter and OrFilter are supported composite filters
 // Entity filters in multiple calls are joined together by AND
+//
+// If Firestore cannot execute the filter, the query's methods return a
+// *FilterError. See CheckFilter for the checks that are performed.
 func (q Query) WhereEntity(ef EntityFilter) Query {
 	proto, err := ef.toProto()
 	if err != nil {
 		q.err = err
 		return q
 	}
+	if _, err := validateFilter(ef); err != nil {
+		q.err = err
+		return q
+	}
 	q.filters = append(append([]*pb.StructuredQuery_Filter(nil), q.filters...), proto)
 	return q
 }
@@ -475,6 +485,8 @@ func (q Query) fromProto(pbQuery *pb.RunQueryRequest) (Query, error) {
 		q.limit = limit
 	}
 
+	q.findNearest = pbq.GetFindNearest()
+
 	// NOTE: limit to last isn't part of the proto, this is a client-side concept
 	// 	limitToLast            bool
 	return q, q.err
@@ -510,8 +522,9 @@ func (q Query) toProto() (*pb.StructuredQuery, error) {
 			CollectionId:   q.collectionID,
 			AllDescendants: q.allDescendants,
 		}},
-		Offset: q.offset,
-		Limit:  q.limit,
+		Offset:      q.offset,
+		Limit:       q.limit,
+		FindNearest: q.findNearest,
 	}
 	if len(q.selection) > 0 {
 		p.Select = &pb.StructuredQuery_Projection{}
@@ -842,8 +855,12 @@ func (f PropertyPathFilter) toProto() (*pb.StructuredQuery_Filter, error) {
 		return nil, err
 	}
 	if uop, ok := unaryOpFor(f.Value); ok {
-		if f.Operator != "==" {
-			return nil, fmt.Errorf("firestore: must use '==' when comparing %v", f.Value)
+		switch f.Operator {
+		case "==":
+		case "!=":
+			uop = negatedUnaryOperators[uop]
+		default:
+			return nil, fmt.Errorf("firestore: must use '==' or '!=' when comparing %v", f.Value)
 		}
 		ref, err := fref(f.Path)
 		if err != nil {
@@ -918,6 +935,11 @@ func unaryOpFor(value interface{}) (pb.StructuredQuery_UnaryFilter_Operator, boo
 	}
 }
 
+var negatedUnaryOperators = map[pb.StructuredQuery_UnaryFilter_Operator]pb.StructuredQuery_UnaryFilter_Operator{
+	pb.StructuredQuery_UnaryFilter_IS_NULL: pb.StructuredQuery_UnaryFilter_IS_NOT_NULL,
+	pb.StructuredQuery_UnaryFilter_IS_NAN:  pb.StructuredQuery_UnaryFilter_IS_NOT_NAN,
+}
+
 func isNaN(x interface{}) bool {
 	switch x := x.(type) {
 	case float32:
@@ -1148,10 +1170,18 @@ func (it *queryDocumentIterator) stop() {
 // Snapshots returns an iterator over snapshots of the query. Each time the query
 // results change, a new snapshot will be generated.
 func (q Query) Snapshots(ctx context.Context) *QuerySnapshotIterator {
+	return q.SnapshotsWithOptions(ctx, nil)
+}
+
+// SnapshotsWithOptions is like Snapshots, but configures the listener with
+// opts. Use it to resume a listener from a resume token, or to monitor the
+// state of the listener stream.
+func (q Query) SnapshotsWithOptions(ctx context.Context, opts *SnapshotOptions) *QuerySnapshotIterator {
 	ws, err := newWatchStreamForQuery(ctx, q)
 	if err != nil {
 		return &QuerySnapshotIterator{err: err}
 	}
+	ws.applyOptions(opts)
 	return &QuerySnapshotIterator{
 		Query: q,
 		ws:    ws,
@@ -1180,7 +1210,7 @@ func (it *QuerySnapshotIterator) Next() (*QuerySnapshot, error) {
 	if it.err != nil {
 		return nil, it.err
 	}
-	btree, changes, readTime, err := it.ws.nextSnapshot()
+	btree, changes, readTime, _, err := it.ws.nextSnapshot()
 	if err != nil {
 		if err == io.EOF {
 			err = iterator.Done
@@ -1192,12 +1222,24 @@ func (it *QuerySnapshotIterator) Next() (*QuerySnapshot, error) {
 		Documents: &DocumentIterator{
 			iter: (*btreeDocumentIterator)(btree.BeforeIndex(0)), q: &it.Query,
 		},
-		Size:     btree.Len(),
-		Changes:  changes,
-		ReadTime: readTime,
+		Size:        btree.Len(),
+		Changes:     changes,
+		ReadTime:    readTime,
+		ResumeToken: it.ws.resumeToken,
 	}, nil
 }
 
+// ResumeToken returns the token of the most recent snapshot returned by Next.
+// Pass it as the ResumeToken of SnapshotOptions to create a listener that
+// continues after that snapshot. ResumeToken returns nil if Next has not
+// returned a snapshot yet.
+func (it *QuerySnapshotIterator) ResumeToken() []byte {
+	if it.ws == nil || !it.ws.hasReturned {
+		return nil
+	}
+	return it.ws.resumeToken
+}
+
 // Stop stops receiving snapshots. You should always call Stop when you are done with
 // a QuerySnapshotIterator, to free up resources. It is not safe to call Stop
 // concurrently with Next.
@@ -1222,6 +1264,10 @@ type QuerySnapshot struct {
 
 	// The time at which this snapshot was obtained from Firestore.
 	ReadTime time.Time
+
+	// ResumeToken can be used to resume listening after this snapshot. See
+	// SnapshotOptions.ResumeToken.
+	ResumeToken []byte
 }
 
 type btreeDocumentIterator btree.Iterator
@@ -1390,6 +1436,10 @@ func (a *AggregationQuery) Get(ctx context.Context) (AggregationResult, error) {
 	}
 
 	if a.tx != nil {
+		if len(a.tx.writes) > 0 {
+			a.tx.readAfterWrite = true
+			return nil, errReadAfterWrite
+		}
 		req.ConsistencySelector = &pb.RunAggregationQueryRequest_Transaction{
 			Transaction: a.tx.id,
 		}
@@ -1422,4 +1472,75 @@ func (a *AggregationQuery) Get(ctx context.Context) (AggregationResult, error) {
 }
 
 // AggregationResult contains the results of an aggregation query.
+// The keys are the aliases of the aggregations, and the values are of type
+// *firestorepb.Value. Use DataTo, Int64 or Float64 to get the results as Go
+// values.
 type AggregationResult map[string]interface{}
+
+// DataTo uses the aggregation results to populate p, which can be a pointer to
+// a map[string]interface{} or a pointer to a struct. The aliases of the
+// aggregations are used as field names, in the same way as
+// DocumentSnapshot.DataTo uses the names of the fields of a document.
+//
+// The result of a count is an integer. The result of a sum is an integer if all
+// the summed values are integers and the sum does not overflow, and a float
+// otherwise. The result of an average is a float, or null if there were no
+// numeric values to average. Use a pointer field to distinguish a null result
+// from zero.
+func (a AggregationResult) DataTo(p interface{}) error {
+	fields := make(map[string]*pb.Value, len(a))
+	for alias := range a {
+		v, err := a.value(alias)
+		if err != nil {
+			return err
+		}
+		fields[alias] = v
+	}
+	return setFromProtoValue(p, &pb.Value{ValueType: &pb.Value_MapValue{MapValue: &pb.MapValue{Fields: fields}}}, nil)
+}
+
+// Int64 returns the result of the aggregation with the given alias as an
+// int64. It returns an error if the result is not an integer, e.g. if it is
+// the result of an average.
+func (a AggregationResult) Int64(alias string) (int64, error) {
+	v, err := a.value(alias)
+	if err != nil {
+		return 0, err
+	}
+	iv, ok := v.ValueType.(*pb.Value_IntegerValue)
+	if !ok {
+		return 0, fmt.Errorf("firestore: aggregation result %q is %s, not an integer", alias, typeString(v))
+	}
+	return iv.IntegerValue, nil
+}
+
+// Float64 returns the result of the aggregation with the given alias as a
+// float64. Integer results are converted to float64. It returns an error if
+// the result is null, which is the case for the average of a field that has no
+// numeric values.
+func (a AggregationResult) Float64(alias string) (float64, error) {
+	v, err := a.value(alias)
+	if err != nil {
+		return 0, err
+	}
+	switch x := v.ValueType.(type) {
+	case *pb.Value_DoubleValue:
+		return x.DoubleValue, nil
+	case *pb.Value_IntegerValue:
+		return float64(x.IntegerValue), nil
+	default:
+		return 0, fmt.Errorf("firestore: aggregation result %q is %s, not a number", alias, typeString(v))
+	}
+}
+
+func (a AggregationResult) value(alias string) (*pb.Value, error) {
+	r, ok := a[alias]
+	if !ok {
+		return nil, fmt.Errorf("firestore: no aggregation result with alias %q", alias)
+	}
+	v, ok := r.(*pb.Value)
+	if !ok {
+		return nil, fmt.Errorf("firestore: aggregation result %q has unexpected type %T", alias, r)
+	}
+	return v, nil
+}
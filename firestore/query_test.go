@@ -1180,6 +1180,82 @@ func TestAggregationQuery(t *testing.T) {
 	}
 }
 
+func TestAggregationResultTyped(t *testing.T) {
+	ctx := context.Background()
+	c, srv, cleanup := newMock(t)
+	defer cleanup()
+
+	srv.addRPC(nil, []interface{}{
+		&pb.RunAggregationQueryResponse{
+			Result: &pb.AggregationResult{
+				AggregateFields: map[string]*pb.Value{
+					"count": intval(3),
+					"total": intval(12),
+					"avg":   floatval(4),
+					"empty": {ValueType: &pb.Value_NullValue{}},
+				},
+			},
+		},
+	})
+
+	ar, err := c.Collection("coll1").NewAggregationQuery().
+		WithCount("count").
+		WithSum("x", "total").
+		WithAvg("x", "avg").
+		WithAvg("y", "empty").
+		Get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Count int64    `firestore:"count"`
+		Total float64  `firestore:"total"`
+		Avg   float64  `firestore:"avg"`
+		Empty *float64 `firestore:"empty"`
+	}
+	if err := ar.DataTo(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Count != 3 || got.Total != 12 || got.Avg != 4 || got.Empty != nil {
+		t.Errorf("got %+v, want {Count:3 Total:12 Avg:4 Empty:<nil>}", got)
+	}
+
+	if n, err := ar.Int64("count"); err != nil || n != 3 {
+		t.Errorf("Int64(count): got (%v, %v), want (3, nil)", n, err)
+	}
+	if f, err := ar.Float64("total"); err != nil || f != 12 {
+		t.Errorf("Float64(total): got (%v, %v), want (12, nil)", f, err)
+	}
+	if f, err := ar.Float64("avg"); err != nil || f != 4 {
+		t.Errorf("Float64(avg): got (%v, %v), want (4, nil)", f, err)
+	}
+	if _, err := ar.Int64("avg"); err == nil {
+		t.Error("Int64(avg): got nil, want error")
+	}
+	if _, err := ar.Float64("empty"); err == nil {
+		t.Error("Float64(empty): got nil, want error")
+	}
+	if _, err := ar.Int64("missing"); err == nil {
+		t.Error("Int64(missing): got nil, want error")
+	}
+}
+
+func TestAggregationQueryReadAfterWrite(t *testing.T) {
+	ctx := context.Background()
+	c, _, cleanup := newMock(t)
+	defer cleanup()
+
+	tx := &Transaction{c: c, writes: []*pb.Write{{}}}
+	_, err := c.Collection("coll1").NewAggregationQuery().WithCount("count").Transaction(tx).Get(ctx)
+	if err != errReadAfterWrite {
+		t.Fatalf("got %v, want %v", err, errReadAfterWrite)
+	}
+	if !tx.readAfterWrite {
+		t.Error("readAfterWrite not set on transaction")
+	}
+}
+
 func TestWithSum(t *testing.T) {
 	ctx := context.Background()
 	sumAlias := "sum"
@@ -81,6 +81,16 @@ func toProtoValue(v reflect.Value) (pbv *pb.Value, sawTransform bool, err error)
 			return nullValue, false, nil
 		}
 		return &pb.Value{ValueType: &pb.Value_ReferenceValue{ReferenceValue: x.Path}}, false, nil
+	case Vector64:
+		if x == nil {
+			return nullValue, false, nil
+		}
+		return vectorToProtoValue(x), false, nil
+	case Vector32:
+		if x == nil {
+			return nullValue, false, nil
+		}
+		return vectorToProtoValue(vector32ToFloat64s(x)), false, nil
 		// Do not add bool, string, int, etc. to this switch; leave them in the
 		// reflect-based switch below. Moving them here would drop support for
 		// types whose underlying types are those primitives.
@@ -0,0 +1,463 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// TypedDoc is a reference to a document whose contents are represented by
+// the Go type T, which is usually a struct. The fields of T are mapped to the
+// fields of the document in the same way as for DocumentRef.Set and
+// DocumentSnapshot.DataTo, including the "firestore" struct tags.
+type TypedDoc[T any] struct {
+	// Ref is the underlying untyped document reference.
+	Ref *DocumentRef
+}
+
+// NewTypedDoc returns a TypedDoc for the document referred to by ref.
+func NewTypedDoc[T any](ref *DocumentRef) *TypedDoc[T] {
+	return &TypedDoc[T]{Ref: ref}
+}
+
+// Get retrieves the document and returns its contents. If the document does
+// not exist, Get returns an error with code NotFound, like DocumentRef.Get.
+func (d *TypedDoc[T]) Get(ctx context.Context) (T, error) {
+	var data T
+	if d == nil {
+		return data, errNilDocRef
+	}
+	ds, err := d.Ref.Get(ctx)
+	if err != nil {
+		return data, err
+	}
+	err = ds.DataTo(&data)
+	return data, err
+}
+
+// GetSnapshot retrieves the document and returns a snapshot of it, which also
+// contains metadata such as the update time. Unlike Get, GetSnapshot does not
+// return an error if the document does not exist; in that case the Data of the
+// returned snapshot is nil.
+func (d *TypedDoc[T]) GetSnapshot(ctx context.Context) (*TypedDocumentSnapshot[T], error) {
+	if d == nil {
+		return nil, errNilDocRef
+	}
+	ds, err := d.Ref.Get(ctx)
+	if err != nil && (ds == nil || ds.Exists()) {
+		return nil, err
+	}
+	return newTypedDocumentSnapshot[T](ds)
+}
+
+// Create creates the document with the given data. It returns an error if a
+// document with the same ID already exists.
+func (d *TypedDoc[T]) Create(ctx context.Context, data T) (*WriteResult, error) {
+	if d == nil {
+		return nil, errNilDocRef
+	}
+	return d.Ref.Create(ctx, data)
+}
+
+// Set creates or overwrites the document with the given data. See
+// DocumentRef.Set for the options.
+func (d *TypedDoc[T]) Set(ctx context.Context, data T, opts ...SetOption) (*WriteResult, error) {
+	if d == nil {
+		return nil, errNilDocRef
+	}
+	return d.Ref.Set(ctx, data, opts...)
+}
+
+// Update updates the fields of the existing document with the given field
+// paths to the corresponding values in data. Each field path is a single field
+// or a dot-separated sequence of fields, using the Firestore names of the
+// fields, which are determined by the "firestore" struct tags of T. If no
+// field paths are given, all the top-level fields of data are updated; fields
+// with the omitempty option whose value is empty are skipped. Fields with the
+// serverTimestamp option are set to the server time.
+//
+// Update returns an error with code NotFound if the document does not exist.
+// Use Ref.Update to update the document with preconditions or with values of
+// other types.
+func (d *TypedDoc[T]) Update(ctx context.Context, data T, fieldPaths ...string) (*WriteResult, error) {
+	if d == nil {
+		return nil, errNilDocRef
+	}
+	updates, err := typedUpdates(reflect.ValueOf(data), fieldPaths)
+	if err != nil {
+		return nil, err
+	}
+	return d.Ref.Update(ctx, updates)
+}
+
+// Delete deletes the document. If the document doesn't exist, it does nothing
+// and returns no error.
+func (d *TypedDoc[T]) Delete(ctx context.Context, preconds ...Precondition) (*WriteResult, error) {
+	if d == nil {
+		return nil, errNilDocRef
+	}
+	return d.Ref.Delete(ctx, preconds...)
+}
+
+// Snapshots returns an iterator over typed snapshots of the document. Each
+// time the document changes or is added or deleted, a new snapshot will be
+// generated.
+func (d *TypedDoc[T]) Snapshots(ctx context.Context) *TypedDocumentSnapshotIterator[T] {
+	if d == nil {
+		return &TypedDocumentSnapshotIterator[T]{err: errNilDocRef}
+	}
+	return &TypedDocumentSnapshotIterator[T]{it: d.Ref.Snapshots(ctx)}
+}
+
+// typedUpdates returns the updates that set the fields with the given paths to
+// the values in data.
+func typedUpdates(v reflect.Value, fieldPaths []string) ([]Update, error) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, errors.New("firestore: nil document contents")
+		}
+		v = v.Elem()
+	}
+	var fps []FieldPath
+	if len(fieldPaths) == 0 {
+		if v.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("firestore: field paths are required to update a document with a value of type %s", v.Type())
+		}
+		fs, err := fieldCache.Fields(v.Type())
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fs {
+			opts := f.ParsedTag.(tagOptions)
+			if opts.omitEmpty && isEmptyValue(v.FieldByIndex(f.Index)) {
+				continue
+			}
+			fps = append(fps, FieldPath{f.Name})
+		}
+		if len(fps) == 0 {
+			return nil, errors.New("firestore: no fields to update")
+		}
+	}
+	for _, p := range fieldPaths {
+		fp, err := parseDotSeparatedString(p)
+		if err != nil {
+			return nil, err
+		}
+		fps = append(fps, fp)
+	}
+	var updates []Update
+	for _, fp := range fps {
+		val, err := getAtPath(v, fp)
+		if err != nil {
+			return nil, err
+		}
+		if isServerTimestampField(v, fp) {
+			val = ServerTimestamp
+		}
+		updates = append(updates, Update{FieldPath: fp, Value: val})
+	}
+	return updates, nil
+}
+
+// isServerTimestampField reports whether fp refers to a struct field with the
+// serverTimestamp option whose value is zero.
+func isServerTimestampField(v reflect.Value, fp FieldPath) bool {
+	for i, k := range fp {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return false
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return false
+		}
+		fm, err := fieldMap(v.Type())
+		if err != nil {
+			return false
+		}
+		f, ok := fm[k]
+		if !ok {
+			return false
+		}
+		v = v.FieldByIndex(f.Index)
+		if i == len(fp)-1 {
+			if !f.ParsedTag.(tagOptions).serverTimestamp {
+				return false
+			}
+			return (v.Kind() == reflect.Ptr && v.IsNil()) || isEmptyValue(reflect.Indirect(v))
+		}
+	}
+	return false
+}
+
+// TypedCollection is a reference to a collection whose documents are
+// represented by the Go type T. See TypedDoc for how T is mapped to the
+// fields of the documents.
+type TypedCollection[T any] struct {
+	// Ref is the underlying untyped collection reference.
+	Ref *CollectionRef
+}
+
+// NewTypedCollection returns a TypedCollection for the collection referred to
+// by ref.
+func NewTypedCollection[T any](ref *CollectionRef) *TypedCollection[T] {
+	return &TypedCollection[T]{Ref: ref}
+}
+
+// Doc returns a TypedDoc that refers to the document in the collection with
+// the given identifier.
+func (c *TypedCollection[T]) Doc(id string) *TypedDoc[T] {
+	ref := c.Ref.Doc(id)
+	if ref == nil {
+		return nil
+	}
+	return NewTypedDoc[T](ref)
+}
+
+// NewDoc returns a TypedDoc with a uniquely generated ID.
+func (c *TypedCollection[T]) NewDoc() *TypedDoc[T] {
+	return NewTypedDoc[T](c.Ref.NewDoc())
+}
+
+// Add generates a TypedDoc with a unique ID and creates it with the given
+// data.
+func (c *TypedCollection[T]) Add(ctx context.Context, data T) (*TypedDoc[T], *WriteResult, error) {
+	d := c.NewDoc()
+	wr, err := d.Create(ctx, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, wr, nil
+}
+
+// Query returns a TypedQuery for the documents of the collection.
+func (c *TypedCollection[T]) Query() TypedQuery[T] {
+	return TypedQuery[T]{Query: c.Ref.Query}
+}
+
+// Documents returns an iterator over the documents of the collection.
+func (c *TypedCollection[T]) Documents(ctx context.Context) *TypedDocumentIterator[T] {
+	return c.Query().Documents(ctx)
+}
+
+// Snapshots returns an iterator over typed snapshots of the documents of the
+// collection.
+func (c *TypedCollection[T]) Snapshots(ctx context.Context) *TypedQuerySnapshotIterator[T] {
+	return c.Query().Snapshots(ctx)
+}
+
+// TypedQuery is a query whose results are represented by the Go type T.
+// Build the query with the methods of Query, then wrap it in a TypedQuery:
+//
+//	q := firestore.TypedQuery[City]{Query: cities.Where("pop", ">", 1e6)}
+type TypedQuery[T any] struct {
+	Query Query
+}
+
+// Documents returns an iterator over the query's resulting documents.
+func (q TypedQuery[T]) Documents(ctx context.Context) *TypedDocumentIterator[T] {
+	return &TypedDocumentIterator[T]{it: q.Query.Documents(ctx)}
+}
+
+// GetAll returns the contents of all the documents that match the query.
+func (q TypedQuery[T]) GetAll(ctx context.Context) ([]T, error) {
+	return q.Documents(ctx).GetAll()
+}
+
+// Snapshots returns an iterator over typed snapshots of the query's results.
+func (q TypedQuery[T]) Snapshots(ctx context.Context) *TypedQuerySnapshotIterator[T] {
+	return &TypedQuerySnapshotIterator[T]{it: q.Query.Snapshots(ctx)}
+}
+
+// TypedDocumentSnapshot is a snapshot of a document whose contents are
+// represented by the Go type T.
+type TypedDocumentSnapshot[T any] struct {
+	// Snapshot is the underlying untyped snapshot.
+	Snapshot *DocumentSnapshot
+
+	// Data contains the contents of the document, or nil if the document does
+	// not exist.
+	Data *T
+}
+
+// Exists reports whether the snapshot represents an existing document.
+func (s *TypedDocumentSnapshot[T]) Exists() bool {
+	return s.Data != nil
+}
+
+// Ref returns the TypedDoc for the document of the snapshot.
+func (s *TypedDocumentSnapshot[T]) Ref() *TypedDoc[T] {
+	return NewTypedDoc[T](s.Snapshot.Ref)
+}
+
+// UpdateTime returns the time at which the document was last changed.
+func (s *TypedDocumentSnapshot[T]) UpdateTime() time.Time {
+	return s.Snapshot.UpdateTime
+}
+
+func newTypedDocumentSnapshot[T any](ds *DocumentSnapshot) (*TypedDocumentSnapshot[T], error) {
+	s := &TypedDocumentSnapshot[T]{Snapshot: ds}
+	if !ds.Exists() {
+		return s, nil
+	}
+	s.Data = new(T)
+	if err := ds.DataTo(s.Data); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// TypedDocumentIterator is an iterator over the contents of the documents
+// returned by a query.
+type TypedDocumentIterator[T any] struct {
+	it *DocumentIterator
+}
+
+// Next returns the next result. Its second return value is iterator.Done if
+// there are no more results. Once Next returns Done, all subsequent calls will
+// return Done.
+func (it *TypedDocumentIterator[T]) Next() (*TypedDocumentSnapshot[T], error) {
+	ds, err := it.it.Next()
+	if err != nil {
+		return nil, err
+	}
+	return newTypedDocumentSnapshot[T](ds)
+}
+
+// GetAll returns the contents of all the documents remaining from the
+// iterator. It is not necessary to call Stop on the iterator after calling
+// GetAll.
+func (it *TypedDocumentIterator[T]) GetAll() ([]T, error) {
+	docs, err := it.it.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]T, len(docs))
+	for i, ds := range docs {
+		if err := ds.DataTo(&result[i]); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Stop stops the iterator, freeing its resources. Always call Stop when you
+// are done with a TypedDocumentIterator. It is not safe to call Stop
+// concurrently with Next.
+func (it *TypedDocumentIterator[T]) Stop() {
+	it.it.Stop()
+}
+
+// TypedDocumentSnapshotIterator is an iterator over typed snapshots of a
+// document. See DocumentSnapshotIterator for details.
+type TypedDocumentSnapshotIterator[T any] struct {
+	it  *DocumentSnapshotIterator
+	err error // returned by Next if it is nil
+}
+
+// Next blocks until the document changes, then returns the snapshot for the
+// current state of the document. If the document has been deleted, Next
+// returns a snapshot whose Exists method returns false.
+func (it *TypedDocumentSnapshotIterator[T]) Next() (*TypedDocumentSnapshot[T], error) {
+	if it.it == nil {
+		return nil, it.err
+	}
+	ds, err := it.it.Next()
+	if err != nil {
+		return nil, err
+	}
+	return newTypedDocumentSnapshot[T](ds)
+}
+
+// Stop stops receiving snapshots. You should always call Stop when you are
+// done with the iterator. It is not safe to call Stop concurrently with Next.
+func (it *TypedDocumentSnapshotIterator[T]) Stop() {
+	if it.it != nil {
+		it.it.Stop()
+	}
+}
+
+// TypedQuerySnapshot is a snapshot of the results of a TypedQuery.
+type TypedQuerySnapshot[T any] struct {
+	// Documents contains the results of the query.
+	Documents []*TypedDocumentSnapshot[T]
+
+	// Changes contains the changes since the previous snapshot.
+	Changes []TypedDocumentChange[T]
+
+	// ReadTime is the time at which this snapshot was obtained from
+	// Firestore.
+	ReadTime time.Time
+}
+
+// TypedDocumentChange is a change to the results of a TypedQuery. See
+// DocumentChange for the meaning of the fields.
+type TypedDocumentChange[T any] struct {
+	Kind     DocumentChangeKind
+	Doc      *TypedDocumentSnapshot[T]
+	OldIndex int
+	NewIndex int
+}
+
+// TypedQuerySnapshotIterator is an iterator over typed snapshots of the
+// results of a query. See QuerySnapshotIterator for details.
+type TypedQuerySnapshotIterator[T any] struct {
+	it *QuerySnapshotIterator
+}
+
+// Next blocks until the query's results change, then returns a snapshot of the
+// current results.
+func (it *TypedQuerySnapshotIterator[T]) Next() (*TypedQuerySnapshot[T], error) {
+	qs, err := it.it.Next()
+	if err != nil {
+		return nil, err
+	}
+	docs, err := qs.Documents.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	s := &TypedQuerySnapshot[T]{ReadTime: qs.ReadTime}
+	for _, ds := range docs {
+		td, err := newTypedDocumentSnapshot[T](ds)
+		if err != nil {
+			return nil, err
+		}
+		s.Documents = append(s.Documents, td)
+	}
+	for _, ch := range qs.Changes {
+		td, err := newTypedDocumentSnapshot[T](ch.Doc)
+		if err != nil {
+			return nil, err
+		}
+		s.Changes = append(s.Changes, TypedDocumentChange[T]{
+			Kind:     ch.Kind,
+			Doc:      td,
+			OldIndex: ch.OldIndex,
+			NewIndex: ch.NewIndex,
+		})
+	}
+	return s, nil
+}
+
+// Stop stops receiving snapshots. You should always call Stop when you are
+// done with the iterator. It is not safe to call Stop concurrently with Next.
+func (it *TypedQuerySnapshotIterator[T]) Stop() {
+	it.it.Stop()
+}
@@ -0,0 +1,193 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firestore
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type typedCity struct {
+	Name    string    `firestore:"name"`
+	Pop     int       `firestore:"pop,omitempty"`
+	Updated time.Time `firestore:"updated,serverTimestamp"`
+}
+
+func TestTypedDocGet(t *testing.T) {
+	ctx := context.Background()
+	c, srv, cleanup := newMock(t)
+	defer cleanup()
+
+	cities := NewTypedCollection[typedCity](c.Collection("C"))
+	path := "projects/projectID/databases/(default)/documents/C/a"
+	srv.addRPC(&pb.BatchGetDocumentsRequest{
+		Database:  c.path(),
+		Documents: []string{path},
+	}, []interface{}{
+		&pb.BatchGetDocumentsResponse{
+			Result: &pb.BatchGetDocumentsResponse_Found{Found: &pb.Document{
+				Name:       path,
+				CreateTime: aTimestamp,
+				UpdateTime: aTimestamp2,
+				Fields: map[string]*pb.Value{
+					"name":    strval("SF"),
+					"pop":     intval(800),
+					"updated": tsval(aTime),
+				},
+			}},
+			ReadTime: aTimestamp3,
+		},
+	})
+	got, err := cities.Doc("a").Get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (typedCity{Name: "SF", Pop: 800, Updated: aTime}); !testEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	srv.addRPC(&pb.BatchGetDocumentsRequest{
+		Database:  c.path(),
+		Documents: []string{path},
+	}, []interface{}{
+		&pb.BatchGetDocumentsResponse{
+			Result:   &pb.BatchGetDocumentsResponse_Missing{Missing: path},
+			ReadTime: aTimestamp3,
+		},
+	})
+	if _, err := cities.Doc("a").Get(ctx); status.Code(err) != codes.NotFound {
+		t.Errorf("got %v, want NotFound", err)
+	}
+
+	srv.addRPC(&pb.BatchGetDocumentsRequest{
+		Database:  c.path(),
+		Documents: []string{path},
+	}, []interface{}{
+		&pb.BatchGetDocumentsResponse{
+			Result:   &pb.BatchGetDocumentsResponse_Missing{Missing: path},
+			ReadTime: aTimestamp3,
+		},
+	})
+	snap, err := cities.Doc("a").GetSnapshot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Exists() {
+		t.Errorf("got existing snapshot %+v for missing document", snap.Data)
+	}
+}
+
+func TestTypedDocNil(t *testing.T) {
+	ctx := context.Background()
+	var d *TypedDoc[typedCity]
+	if _, err := d.Get(ctx); err != errNilDocRef {
+		t.Errorf("Get: got %v, want errNilDocRef", err)
+	}
+	it := d.Snapshots(ctx)
+	defer it.Stop()
+	if _, err := it.Next(); err != errNilDocRef {
+		t.Errorf("Snapshots: got %v, want errNilDocRef", err)
+	}
+}
+
+func TestTypedUpdates(t *testing.T) {
+	now := time.Now()
+	for _, test := range []struct {
+		data  typedCity
+		paths []string
+		want  []Update
+	}{
+		{
+			data: typedCity{Name: "SF"},
+			want: []Update{
+				{FieldPath: FieldPath{"name"}, Value: "SF"},
+				{FieldPath: FieldPath{"updated"}, Value: ServerTimestamp},
+			},
+		},
+		{
+			data: typedCity{Name: "SF", Pop: 1, Updated: now},
+			want: []Update{
+				{FieldPath: FieldPath{"name"}, Value: "SF"},
+				{FieldPath: FieldPath{"pop"}, Value: 1},
+				{FieldPath: FieldPath{"updated"}, Value: now},
+			},
+		},
+		{
+			data:  typedCity{Name: "SF"},
+			paths: []string{"pop"},
+			want:  []Update{{FieldPath: FieldPath{"pop"}, Value: 0}},
+		},
+	} {
+		got, err := typedUpdates(reflect.ValueOf(test.data), test.paths)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !testEqual(got, test.want) {
+			t.Errorf("%+v, %v: got %+v, want %+v", test.data, test.paths, got, test.want)
+		}
+	}
+
+	if _, err := typedUpdates(reflect.ValueOf(typedCity{}), []string{"Name"}); err == nil {
+		t.Error("got nil, want error for unknown field")
+	}
+	if _, err := typedUpdates(reflect.ValueOf(map[string]int{"a": 1}), nil); err == nil {
+		t.Error("got nil, want error for map without field paths")
+	}
+}
+
+func TestTypedQueryGetAll(t *testing.T) {
+	const dbPath = "projects/projectID/databases/(default)"
+	ctx := context.Background()
+	c, srv, cleanup := newMock(t)
+	defer cleanup()
+
+	doc := func(id, name string) *pb.RunQueryResponse {
+		return &pb.RunQueryResponse{
+			Document: &pb.Document{
+				Name:       dbPath + "/documents/C/" + id,
+				CreateTime: aTimestamp,
+				UpdateTime: aTimestamp,
+				Fields:     map[string]*pb.Value{"name": strval(name)},
+			},
+			ReadTime: aTimestamp,
+		}
+	}
+	srv.addRPC(nil, []interface{}{doc("a", "SF"), doc("b", "LA")})
+	q := TypedQuery[typedCity]{Query: c.Collection("C").Where("pop", ">", 1)}
+	got, err := q.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []typedCity{{Name: "SF"}, {Name: "LA"}}; !testEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	srv.addRPC(nil, []interface{}{doc("a", "SF")})
+	it := NewTypedCollection[typedCity](c.Collection("C")).Documents(ctx)
+	defer it.Stop()
+	snap, err := it.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Ref().Ref.ID != "a" || snap.Data.Name != "SF" {
+		t.Errorf("got %s: %+v, want a: SF", snap.Ref().Ref.ID, snap.Data)
+	}
+}
@@ -0,0 +1,351 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+
+	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	vectorTypeKey           = "__type__"
+	vectorTypeValue         = "__vector__"
+	vectorValueKey          = "value"
+	maxVectorQueryDimension = 2048
+)
+
+var (
+	typeOfVector32 = reflect.TypeOf(Vector32{})
+	typeOfVector64 = reflect.TypeOf(Vector64{})
+)
+
+// Vector64 is an embedding vector of float64s. Use it as the type of a field
+// that is used in a vector search with Query.FindNearest.
+//
+// A Vector64 is stored in Firestore as a vector value, not as an array, so it
+// cannot be used in array operations like ArrayUnion or "array-contains"
+// filters.
+type Vector64 []float64
+
+// Vector32 is an embedding vector of float32s. It is stored in Firestore in
+// the same way as a Vector64; the values are converted to float64.
+type Vector32 []float32
+
+// vectorToProtoValue returns the Firestore representation of a vector, which is
+// a map with a special type key and the vector elements as an array of doubles.
+func vectorToProtoValue(v []float64) *pb.Value {
+	vals := make([]*pb.Value, len(v))
+	for i, f := range v {
+		vals[i] = &pb.Value{ValueType: &pb.Value_DoubleValue{DoubleValue: f}}
+	}
+	return &pb.Value{ValueType: &pb.Value_MapValue{MapValue: &pb.MapValue{
+		Fields: map[string]*pb.Value{
+			vectorTypeKey:  {ValueType: &pb.Value_StringValue{StringValue: vectorTypeValue}},
+			vectorValueKey: {ValueType: &pb.Value_ArrayValue{ArrayValue: &pb.ArrayValue{Values: vals}}},
+		},
+	}}}
+}
+
+func vector32ToFloat64s(v Vector32) []float64 {
+	fs := make([]float64, len(v))
+	for i, f := range v {
+		fs[i] = float64(f)
+	}
+	return fs
+}
+
+// isVectorValue reports whether the map value is the representation of a
+// vector.
+func isVectorValue(m *pb.MapValue) bool {
+	t, ok := m.GetFields()[vectorTypeKey]
+	return ok && t.GetStringValue() == vectorTypeValue
+}
+
+// vectorFromProtoValue returns the elements of a vector value. It returns an
+// error if the value is not a vector.
+func vectorFromProtoValue(v *pb.Value) ([]float64, error) {
+	m := v.GetMapValue()
+	if m == nil || !isVectorValue(m) {
+		return nil, fmt.Errorf("firestore: cannot convert %s to vector", typeString(v))
+	}
+	arr, ok := m.Fields[vectorValueKey].GetValueType().(*pb.Value_ArrayValue)
+	if !ok {
+		return nil, errors.New("firestore: vector value does not contain an array")
+	}
+	fs := make([]float64, len(arr.ArrayValue.Values))
+	for i, e := range arr.ArrayValue.Values {
+		switch x := e.ValueType.(type) {
+		case *pb.Value_DoubleValue:
+			fs[i] = x.DoubleValue
+		case *pb.Value_IntegerValue:
+			fs[i] = float64(x.IntegerValue)
+		default:
+			return nil, fmt.Errorf("firestore: vector element %d has type %s, want double", i, typeString(e))
+		}
+	}
+	return fs, nil
+}
+
+// setVectorFromProtoValue sets v, which must be a Vector32 or a Vector64, to
+// the vector value vproto.
+func setVectorFromProtoValue(v reflect.Value, vproto *pb.Value) error {
+	fs, err := vectorFromProtoValue(vproto)
+	if err != nil {
+		return err
+	}
+	if v.Type() == typeOfVector64 {
+		v.Set(reflect.ValueOf(Vector64(fs)))
+		return nil
+	}
+	v32 := make(Vector32, len(fs))
+	for i, f := range fs {
+		v32[i] = float32(f)
+	}
+	v.Set(reflect.ValueOf(v32))
+	return nil
+}
+
+// DistanceMeasure is the measure that is used to compare vectors in a vector
+// search.
+type DistanceMeasure int32
+
+const (
+	// DistanceMeasureEuclidean is the euclidean distance between two vectors.
+	// Smaller distances mean more similar vectors.
+	DistanceMeasureEuclidean DistanceMeasure = DistanceMeasure(pb.StructuredQuery_FindNearest_EUCLIDEAN)
+	// DistanceMeasureCosine compares vectors based on the angle between them.
+	// The distance is 1 minus the cosine similarity of the vectors, so smaller
+	// distances mean more similar vectors.
+	DistanceMeasureCosine DistanceMeasure = DistanceMeasure(pb.StructuredQuery_FindNearest_COSINE)
+	// DistanceMeasureDotProduct is the dot product of two vectors. Larger
+	// values mean more similar vectors.
+	DistanceMeasureDotProduct DistanceMeasure = DistanceMeasure(pb.StructuredQuery_FindNearest_DOT_PRODUCT)
+)
+
+// distance returns the distance between a and b according to m.
+func (m DistanceMeasure) distance(a, b []float64) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("firestore: cannot compare vectors of dimensions %d and %d", len(a), len(b))
+	}
+	var dot, sumA, sumB, sumDiff float64
+	for i := range a {
+		dot += a[i] * b[i]
+		sumA += a[i] * a[i]
+		sumB += b[i] * b[i]
+		sumDiff += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	switch m {
+	case DistanceMeasureEuclidean:
+		return math.Sqrt(sumDiff), nil
+	case DistanceMeasureCosine:
+		if sumA == 0 || sumB == 0 {
+			return 0, errors.New("firestore: cosine distance is not defined for zero vectors")
+		}
+		return 1 - dot/(math.Sqrt(sumA)*math.Sqrt(sumB)), nil
+	case DistanceMeasureDotProduct:
+		return dot, nil
+	default:
+		return 0, fmt.Errorf("firestore: unknown distance measure %d", m)
+	}
+}
+
+// withinThreshold reports whether the distance d satisfies the threshold t.
+// For dot products larger values are more similar, for the other measures
+// smaller values are more similar.
+func (m DistanceMeasure) withinThreshold(d, t float64) bool {
+	if m == DistanceMeasureDotProduct {
+		return d >= t
+	}
+	return d <= t
+}
+
+// FindNearestOptions are options for a vector search with Query.FindNearest.
+type FindNearestOptions struct {
+	// DistanceThreshold, if non-nil, only returns documents whose distance to
+	// the query vector is within the threshold. For DistanceMeasureEuclidean
+	// and DistanceMeasureCosine, these are the documents with a distance less
+	// than or equal to the threshold. For DistanceMeasureDotProduct, these are
+	// the documents with a distance greater than or equal to the threshold.
+	//
+	// The threshold is applied by the client to the documents that are
+	// returned by Firestore, so a query with a threshold can return fewer
+	// documents than the limit of the query even if more documents exist that
+	// are within the threshold.
+	DistanceThreshold *float64
+
+	// DistanceResultField, if not empty, is the name of a field that is added
+	// to each resulting document and that contains the distance of the
+	// document to the query vector. The field is computed by the client and is
+	// only present in the DocumentSnapshots returned by the query; it is not
+	// stored in the database. The vector field must not be excluded from the
+	// results by Select if DistanceThreshold or DistanceResultField is set.
+	DistanceResultField string
+}
+
+// VectorQuery is a query that finds the nearest neighbors of a vector. Create
+// a VectorQuery with Query.FindNearest.
+type VectorQuery struct {
+	q                   Query
+	vectorField         FieldPath
+	queryVector         []float64
+	measure             DistanceMeasure
+	distanceThreshold   *float64
+	distanceResultField string
+}
+
+// FindNearest returns a query that finds the documents whose vector field is
+// nearest to the query vector according to the distance measure. The results
+// are ordered by distance, the nearest documents first, and contain at most
+// limit documents. The query vector must be a Vector64, a Vector32, a []float64
+// or a []float32. The limit must be positive and at most 1000.
+//
+// The documents are selected from the documents that match the filters of q.
+// Vector search requires a vector index on the vector field and on any fields
+// that are used in the filters of q. A vector query cannot have an order,
+// cursors or an offset.
+//
+// vectorField is a path to a field that contains vectors. It must be a single
+// field or a dot-separated sequence of fields, and must not contain any of the
+// runes "˜*/[]". Use FindNearestPath to use a FieldPath instead.
+func (q Query) FindNearest(vectorField string, queryVector interface{}, limit int, measure DistanceMeasure, options *FindNearestOptions) VectorQuery {
+	fp, err := parseDotSeparatedString(vectorField)
+	if err != nil {
+		q.err = err
+		return VectorQuery{q: q}
+	}
+	return q.FindNearestPath(fp, queryVector, limit, measure, options)
+}
+
+// FindNearestPath is like FindNearest, but the vector field is specified as a
+// FieldPath.
+func (q Query) FindNearestPath(vectorField FieldPath, queryVector interface{}, limit int, measure DistanceMeasure, options *FindNearestOptions) VectorQuery {
+	vq := VectorQuery{q: q, vectorField: vectorField, measure: measure}
+	if options != nil {
+		vq.distanceThreshold = options.DistanceThreshold
+		vq.distanceResultField = options.DistanceResultField
+	}
+	var err error
+	vq.queryVector, err = vectorQueryValue(queryVector)
+	if err != nil {
+		vq.q.err = err
+		return vq
+	}
+	switch {
+	case limit <= 0 || limit > 1000:
+		vq.q.err = fmt.Errorf("firestore: FindNearest limit must be between 1 and 1000, got %d", limit)
+		return vq
+	case measure != DistanceMeasureEuclidean && measure != DistanceMeasureCosine && measure != DistanceMeasureDotProduct:
+		vq.q.err = fmt.Errorf("firestore: invalid distance measure %d", measure)
+		return vq
+	case vq.distanceResultField != "" && fieldPathContains(vectorField, vq.distanceResultField):
+		vq.q.err = fmt.Errorf("firestore: distance result field %q conflicts with vector field %v", vq.distanceResultField, vectorField)
+		return vq
+	}
+	ref, err := fref(vectorField)
+	if err != nil {
+		vq.q.err = err
+		return vq
+	}
+	vq.q.findNearest = &pb.StructuredQuery_FindNearest{
+		VectorField:     ref,
+		QueryVector:     vectorToProtoValue(vq.queryVector),
+		DistanceMeasure: pb.StructuredQuery_FindNearest_DistanceMeasure(measure),
+		Limit:           &wrapperspb.Int32Value{Value: int32(limit)},
+	}
+	return vq
+}
+
+func fieldPathContains(fp FieldPath, name string) bool {
+	return len(fp) > 0 && fp[0] == name
+}
+
+func vectorQueryValue(v interface{}) ([]float64, error) {
+	var fs []float64
+	switch x := v.(type) {
+	case Vector64:
+		fs = x
+	case []float64:
+		fs = x
+	case Vector32:
+		fs = vector32ToFloat64s(x)
+	case []float32:
+		fs = vector32ToFloat64s(x)
+	default:
+		return nil, fmt.Errorf("firestore: query vector must be a Vector64, Vector32, []float64 or []float32, got %T", v)
+	}
+	if len(fs) == 0 || len(fs) > maxVectorQueryDimension {
+		return nil, fmt.Errorf("firestore: query vector must have between 1 and %d dimensions, got %d", maxVectorQueryDimension, len(fs))
+	}
+	return fs, nil
+}
+
+// Documents returns an iterator over the documents that are nearest to the
+// query vector, ordered by distance.
+func (vq VectorQuery) Documents(ctx context.Context) *DocumentIterator {
+	q := vq.q
+	it := &DocumentIterator{
+		iter: newQueryDocumentIterator(withResourceHeader(ctx, q.c.path()), &q, nil, q.readSettings),
+		q:    &q,
+	}
+	if vq.distanceThreshold != nil || vq.distanceResultField != "" {
+		it.iter = &vectorDocIterator{iter: it.iter, vq: vq}
+	}
+	return it
+}
+
+// vectorDocIterator applies the client-side options of a vector query to the
+// documents returned by another iterator.
+type vectorDocIterator struct {
+	iter docIterator
+	vq   VectorQuery
+}
+
+func (it *vectorDocIterator) next() (*DocumentSnapshot, error) {
+	for {
+		ds, err := it.iter.next()
+		if err != nil {
+			return nil, err
+		}
+		v, err := valueAtPath(it.vq.vectorField, ds.proto.GetFields())
+		if err != nil {
+			return nil, err
+		}
+		vec, err := vectorFromProtoValue(v)
+		if err != nil {
+			return nil, err
+		}
+		d, err := it.vq.measure.distance(vec, it.vq.queryVector)
+		if err != nil {
+			return nil, err
+		}
+		if it.vq.distanceThreshold != nil && !it.vq.measure.withinThreshold(d, *it.vq.distanceThreshold) {
+			continue
+		}
+		if it.vq.distanceResultField != "" {
+			ds.proto.Fields[it.vq.distanceResultField] = &pb.Value{ValueType: &pb.Value_DoubleValue{DoubleValue: d}}
+		}
+		return ds, nil
+	}
+}
+
+func (it *vectorDocIterator) stop() {
+	it.iter.stop()
+}
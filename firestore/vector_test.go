@@ -0,0 +1,241 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firestore
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+
+	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func vectorval(fs ...float64) *pb.Value {
+	return vectorToProtoValue(fs)
+}
+
+func TestVectorToProtoValue(t *testing.T) {
+	want := mapval(map[string]*pb.Value{
+		"__type__": strval("__vector__"),
+		"value":    arrayval(floatval(1), floatval(2.5)),
+	})
+	for _, in := range []interface{}{
+		Vector64{1, 2.5},
+		Vector32{1, 2.5},
+		&Vector64{1, 2.5},
+	} {
+		got, _, err := toProtoValue(reflect.ValueOf(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !testEqual(got, want) {
+			t.Errorf("%T: got %v, want %v", in, got, want)
+		}
+	}
+	got, _, err := toProtoValue(reflect.ValueOf(Vector64(nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !testEqual(got, nullValue) {
+		t.Errorf("nil vector: got %v, want null", got)
+	}
+}
+
+func TestVectorFromProtoValue(t *testing.T) {
+	type S struct {
+		V64 Vector64
+		V32 Vector32
+	}
+	var s S
+	err := setFromProtoValue(&s, mapval(map[string]*pb.Value{
+		"V64": vectorval(1, 2),
+		"V32": mapval(map[string]*pb.Value{
+			"__type__": strval("__vector__"),
+			"value":    arrayval(floatval(3), intval(4)),
+		}),
+	}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (S{V64: Vector64{1, 2}, V32: Vector32{3, 4}}); !testEqual(s, want) {
+		t.Errorf("got %+v, want %+v", s, want)
+	}
+
+	got, err := createFromProtoValue(vectorval(1, 2), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Vector64{1, 2}); !testEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+
+	for _, in := range []*pb.Value{
+		arrayval(floatval(1)),
+		mapval(map[string]*pb.Value{"value": arrayval(floatval(1))}),
+		mapval(map[string]*pb.Value{"__type__": strval("__vector__"), "value": floatval(1)}),
+		mapval(map[string]*pb.Value{"__type__": strval("__vector__"), "value": arrayval(strval("x"))}),
+	} {
+		var v Vector64
+		if err := setFromProtoValue(&v, in, nil); err == nil {
+			t.Errorf("%v: got nil, want error", in)
+		}
+	}
+}
+
+func TestDistanceMeasure(t *testing.T) {
+	a, b := []float64{1, 0}, []float64{3, 4}
+	for _, test := range []struct {
+		m    DistanceMeasure
+		want float64
+	}{
+		{DistanceMeasureEuclidean, math.Sqrt(20)},
+		{DistanceMeasureCosine, 1 - 3.0/5},
+		{DistanceMeasureDotProduct, 3},
+	} {
+		got, err := test.m.distance(a, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%d: got %v, want %v", test.m, got, test.want)
+		}
+	}
+	if _, err := DistanceMeasureEuclidean.distance(a, []float64{1}); err == nil {
+		t.Error("got nil, want error for vectors of different dimensions")
+	}
+	if _, err := DistanceMeasureCosine.distance(a, []float64{0, 0}); err == nil {
+		t.Error("got nil, want error for cosine distance with zero vector")
+	}
+}
+
+func TestFindNearestToProto(t *testing.T) {
+	q := (&Client{}).Collection("C").Where("color", "==", "red")
+	vq := q.FindNearest("embedding.v", Vector32{1, 2}, 10, DistanceMeasureCosine, nil)
+	got, err := vq.q.toProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &pb.StructuredQuery{
+		From: []*pb.StructuredQuery_CollectionSelector{{CollectionId: "C"}},
+		Where: &pb.StructuredQuery_Filter{FilterType: &pb.StructuredQuery_Filter_FieldFilter{
+			FieldFilter: &pb.StructuredQuery_FieldFilter{
+				Field: fref1("color"),
+				Op:    pb.StructuredQuery_FieldFilter_EQUAL,
+				Value: strval("red"),
+			},
+		}},
+		FindNearest: &pb.StructuredQuery_FindNearest{
+			VectorField:     &pb.StructuredQuery_FieldReference{FieldPath: "embedding.v"},
+			QueryVector:     vectorval(1, 2),
+			DistanceMeasure: pb.StructuredQuery_FindNearest_COSINE,
+			Limit:           &wrapperspb.Int32Value{Value: 10},
+		},
+	}
+	if !testEqual(got, want) {
+		t.Errorf("got\n%v\nwant\n%v", got, want)
+	}
+
+	// The vector search survives serialization.
+	b, err := vq.q.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dq, err := q.Deserialize(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = dq.toProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !testEqual(got, want) {
+		t.Errorf("after deserialization: got\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestFindNearestErrors(t *testing.T) {
+	q := (&Client{}).Collection("C").Query
+	for _, vq := range []VectorQuery{
+		q.FindNearest("", Vector64{1}, 1, DistanceMeasureEuclidean, nil),
+		q.FindNearest("v", []int{1}, 1, DistanceMeasureEuclidean, nil),
+		q.FindNearest("v", Vector64{}, 1, DistanceMeasureEuclidean, nil),
+		q.FindNearest("v", Vector64{1}, 0, DistanceMeasureEuclidean, nil),
+		q.FindNearest("v", Vector64{1}, 1001, DistanceMeasureEuclidean, nil),
+		q.FindNearest("v", Vector64{1}, 1, DistanceMeasure(0), nil),
+		q.FindNearest("v", Vector64{1}, 1, DistanceMeasureEuclidean, &FindNearestOptions{DistanceResultField: "v"}),
+	} {
+		if _, err := vq.q.toProto(); err == nil {
+			t.Errorf("%+v: got nil, want error", vq)
+		}
+	}
+}
+
+func TestFindNearestDocuments(t *testing.T) {
+	const dbPath = "projects/projectID/databases/(default)"
+	ctx := context.Background()
+	c, srv, cleanup := newMock(t)
+	defer cleanup()
+
+	doc := func(id string, v ...float64) *pb.RunQueryResponse {
+		return &pb.RunQueryResponse{
+			Document: &pb.Document{
+				Name:       dbPath + "/documents/C/" + id,
+				CreateTime: aTimestamp,
+				UpdateTime: aTimestamp,
+				Fields:     map[string]*pb.Value{"v": vectorval(v...)},
+			},
+			ReadTime: aTimestamp,
+		}
+	}
+	threshold := 2.0
+	vq := c.Collection("C").FindNearest("v", Vector64{0, 0}, 3, DistanceMeasureEuclidean, &FindNearestOptions{
+		DistanceThreshold:   &threshold,
+		DistanceResultField: "distance",
+	})
+	sq, err := vq.q.toProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.addRPC(&pb.RunQueryRequest{
+		Parent:    dbPath + "/documents",
+		QueryType: &pb.RunQueryRequest_StructuredQuery{StructuredQuery: sq},
+	}, []interface{}{doc("a", 1, 0), doc("b", 0, 2), doc("c", 3, 4)})
+
+	docs, err := vq.Documents(ctx).GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	var dists []float64
+	for _, d := range docs {
+		var s struct {
+			V        Vector64
+			Distance float64
+		}
+		if err := d.DataTo(&s); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, d.Ref.ID)
+		dists = append(dists, s.Distance)
+	}
+	if want := []string{"a", "b"}; !testEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if want := []float64{1, 2}; !testEqual(dists, want) {
+		t.Errorf("got distances %v, want %v", dists, want)
+	}
+}
@@ -60,6 +60,96 @@ type DocumentChange struct {
 	NewIndex int
 }
 
+// SnapshotOptions configures a snapshot listener created with
+// Query.SnapshotsWithOptions or DocumentRef.SnapshotsWithOptions.
+type SnapshotOptions struct {
+	// ResumeToken, if non-empty, resumes a listener from a token that was
+	// obtained from the ResumeToken method of a previous snapshot iterator for
+	// the same query or document, for example before the process restarted.
+	//
+	// The first snapshot of a resumed listener contains only the documents
+	// that changed after the snapshot that the token belongs to, and later
+	// snapshots add the documents that change afterwards; the documents that
+	// did not change are not read again. As the listener does not know the
+	// complete result set, the indexes of its DocumentChanges refer to the
+	// documents that it has seen, and documents that were deleted or no longer
+	// match the query are reported as DocumentRemoved changes whose Doc does not
+	// exist. A resumed document listener returns its first snapshot when the
+	// document changes. If Firestore cannot resume from the token, it resets
+	// the listener and the next snapshot contains the complete result set, as
+	// for a new listener. The listener is also reset, and reads the complete
+	// result set, if an existence filter sent by Firestore after the first
+	// snapshot does not match the number of documents that it has seen.
+	ResumeToken []byte
+
+	// InitialBackoff and MaxBackoff control the delays between attempts to
+	// reopen the listener stream after a transient error. The delay starts at
+	// InitialBackoff and grows up to MaxBackoff. If zero, the defaults of 1
+	// second and 60 seconds are used.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// OnStateChange, if non-nil, is called when the state of the listener
+	// stream changes, for example to monitor the health of the listener. It is
+	// called synchronously from the Next method of the iterator, and must not
+	// call methods of the iterator.
+	OnStateChange func(ListenerStateChange)
+}
+
+// ListenerState is the state of the stream of a snapshot listener.
+type ListenerState int
+
+const (
+	// ListenerConnected indicates that the listener stream was opened.
+	ListenerConnected ListenerState = iota + 1
+	// ListenerCurrent indicates that the listener is up to date with the
+	// server.
+	ListenerCurrent
+	// ListenerRetrying indicates that the listener stream failed with a
+	// transient error and will be reopened after a backoff delay.
+	ListenerRetrying
+	// ListenerReset indicates that the server reset the listener, so that the
+	// listener reads the complete result set again.
+	ListenerReset
+	// ListenerFailed indicates that the listener stream failed with a
+	// permanent error. The error is returned by the Next method of the
+	// iterator.
+	ListenerFailed
+)
+
+// String returns a readable representation of the state.
+func (s ListenerState) String() string {
+	switch s {
+	case ListenerConnected:
+		return "Connected"
+	case ListenerCurrent:
+		return "Current"
+	case ListenerRetrying:
+		return "Retrying"
+	case ListenerReset:
+		return "Reset"
+	case ListenerFailed:
+		return "Failed"
+	default:
+		return fmt.Sprintf("ListenerState(%d)", int(s))
+	}
+}
+
+// ListenerStateChange describes a change of the state of a snapshot listener
+// stream.
+type ListenerStateChange struct {
+	State ListenerState
+	// Err is the error that caused the change, for ListenerRetrying and
+	// ListenerFailed.
+	Err error
+	// Backoff is the delay before the stream is reopened, for
+	// ListenerRetrying.
+	Backoff time.Duration
+	// ResumeToken is the token from which the stream resumes, for
+	// ListenerCurrent and ListenerRetrying.
+	ResumeToken []byte
+}
+
 // Implementation of realtime updates (a.k.a. watch).
 // This code is closely based on the Node.js implementation,
 // https://github.com/googleapis/nodejs-firestore/blob/master/src/watch.js.
@@ -95,6 +185,11 @@ type watchStream struct {
 	// Map of document name to DocumentSnapshot for accumulated changes for the current snapshot.
 	// A nil value means the document was removed.
 	changeMap map[string]*DocumentSnapshot
+
+	initialBackoff gax.Backoff               // backoff to reset to once the stream is healthy
+	resumeToken    []byte                    // resume token of the most recent consistent state
+	resumed        bool                      // resumed from a token, without the complete result set
+	onStateChange  func(ListenerStateChange) // optional state callback
 }
 
 func newWatchStreamForDocument(ctx context.Context, dr *DocumentRef) *watchStream {
@@ -129,13 +224,14 @@ const btreeDegree = 4
 
 func newWatchStream(ctx context.Context, c *Client, compare func(_, _ *DocumentSnapshot) (int, error), target *pb.Target) *watchStream {
 	w := &watchStream{
-		ctx:       ctx,
-		c:         c,
-		compare:   compare,
-		target:    target,
-		backoff:   defaultBackoff,
-		docMap:    map[string]*DocumentSnapshot{},
-		changeMap: map[string]*DocumentSnapshot{},
+		ctx:            ctx,
+		c:              c,
+		compare:        compare,
+		target:         target,
+		backoff:        defaultBackoff,
+		initialBackoff: defaultBackoff,
+		docMap:         map[string]*DocumentSnapshot{},
+		changeMap:      map[string]*DocumentSnapshot{},
 	}
 	w.docTree = btree.New(btreeDegree, func(a, b interface{}) bool {
 		return w.less(a.(*DocumentSnapshot), b.(*DocumentSnapshot))
@@ -143,6 +239,33 @@ func newWatchStream(ctx context.Context, c *Client, compare func(_, _ *DocumentS
 	return w
 }
 
+// applyOptions configures the stream with opts. It must be called before the
+// first snapshot is requested.
+func (s *watchStream) applyOptions(opts *SnapshotOptions) {
+	if opts == nil {
+		return
+	}
+	if opts.InitialBackoff > 0 {
+		s.initialBackoff.Initial = opts.InitialBackoff
+	}
+	if opts.MaxBackoff > 0 {
+		s.initialBackoff.Max = opts.MaxBackoff
+	}
+	s.backoff = s.initialBackoff
+	if len(opts.ResumeToken) > 0 {
+		s.target.ResumeType = &pb.Target_ResumeToken{ResumeToken: opts.ResumeToken}
+		s.resumeToken = opts.ResumeToken
+		s.resumed = true
+	}
+	s.onStateChange = opts.OnStateChange
+}
+
+func (s *watchStream) notify(change ListenerStateChange) {
+	if s.onStateChange != nil {
+		s.onStateChange(change)
+	}
+}
+
 func (s *watchStream) less(a, b *DocumentSnapshot) bool {
 	c, err := s.compare(a, b)
 	if err != nil {
@@ -152,10 +275,13 @@ func (s *watchStream) less(a, b *DocumentSnapshot) bool {
 	return c < 0
 }
 
+// nextSnapshot also reports whether the snapshot was computed by a stream
+// resumed from a token, which doesn't know the complete result set.
+//
 // Once nextSnapshot returns an error, it will always return the same error.
-func (s *watchStream) nextSnapshot() (*btree.BTree, []DocumentChange, time.Time, error) {
+func (s *watchStream) nextSnapshot() (_ *btree.BTree, _ []DocumentChange, _ time.Time, resumed bool, _ error) {
 	if s.err != nil {
-		return nil, nil, time.Time{}, s.err
+		return nil, nil, time.Time{}, false, s.err
 	}
 	var changes []DocumentChange
 	for {
@@ -164,22 +290,62 @@ func (s *watchStream) nextSnapshot() (*btree.BTree, []DocumentChange, time.Time,
 		}
 		if s.err != nil {
 			_ = s.close() // ignore error
-			return nil, nil, time.Time{}, s.err
+			if s.err != io.EOF {
+				s.notify(ListenerStateChange{State: ListenerFailed, Err: s.err})
+			}
+			return nil, nil, time.Time{}, false, s.err
+		}
+		resumed = s.resumed
+		var removed []DocumentChange
+		if s.resumed {
+			removed = s.unknownRemovals()
 		}
 		var newDocTree *btree.BTree
 		newDocTree, changes = s.computeSnapshot(s.docTree, s.docMap, s.changeMap, s.readTime)
 		if s.err != nil {
-			return nil, nil, time.Time{}, s.err
+			return nil, nil, time.Time{}, false, s.err
 		}
+		changes = append(removed, changes...)
+		s.notify(ListenerStateChange{State: ListenerCurrent, ResumeToken: s.resumeToken})
+		// The target is current, so the stream has caught up from the resume
+		// token. Later existence filters can be checked against the documents
+		// seen so far, and a mismatch resets the listener.
+		s.resumed = false
 		// Only return a snapshot if something has changed, or this is the first snapshot.
-		if !s.hasReturned || newDocTree != s.docTree {
+		if !s.hasReturned || newDocTree != s.docTree || len(removed) > 0 {
 			s.docTree = newDocTree
 			break
 		}
 	}
 	s.changeMap = map[string]*DocumentSnapshot{}
 	s.hasReturned = true
-	return s.docTree, changes, s.readTime, nil
+	return s.docTree, changes, s.readTime, resumed, nil
+}
+
+// unknownRemovals returns the changes for documents that were removed from
+// the results of a resumed stream, but that the stream has not seen before.
+func (s *watchStream) unknownRemovals() []DocumentChange {
+	var names []string
+	for name, doc := range s.changeMap {
+		if doc == nil && s.docMap[name] == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var changes []DocumentChange
+	for _, name := range names {
+		ref, err := pathToDoc(name, s.c)
+		if err != nil {
+			continue
+		}
+		changes = append(changes, DocumentChange{
+			Kind:     DocumentRemoved,
+			Doc:      &DocumentSnapshot{Ref: ref, ReadTime: s.readTime, c: s.c},
+			OldIndex: -1,
+			NewIndex: -1,
+		})
+	}
+	return changes
 }
 
 // Read a message from the stream and handle it. Return true when
@@ -221,8 +387,11 @@ func (s *watchStream) handleNextMessage() bool {
 
 	case *pb.ListenResponse_Filter:
 		s.logf("Filter %d", r.Filter.Count)
-		if int(r.Filter.Count) != s.currentSize() {
+		// A resumed stream does not know the complete result set, so it
+		// cannot verify the count.
+		if !s.resumed && int(r.Filter.Count) != s.currentSize() {
 			s.resetDocs() // Remove all the current results.
+			s.notify(ListenerStateChange{State: ListenerReset})
 			// The filter didn't match; close the stream so it will be re-opened on the next
 			// call to nextSnapshot.
 			_ = s.close() // ignore error
@@ -249,6 +418,7 @@ func (s *watchStream) handleTargetChange(tc *pb.TargetChange) bool {
 			}
 			s.readTime = tc.ReadTime.AsTime()
 			s.target.ResumeType = &pb.Target_ResumeToken{ResumeToken: tc.ResumeToken}
+			s.resumeToken = tc.ResumeToken
 			return true
 		}
 
@@ -281,6 +451,7 @@ func (s *watchStream) handleTargetChange(tc *pb.TargetChange) bool {
 	case pb.TargetChange_RESET:
 		s.logf("TargetReset")
 		s.resetDocs()
+		s.notify(ListenerStateChange{State: ListenerReset})
 
 	default:
 		s.err = fmt.Errorf("firestore: unknown TargetChange type %s", tc.TargetChangeType)
@@ -289,13 +460,15 @@ func (s *watchStream) handleTargetChange(tc *pb.TargetChange) bool {
 	// If we see a resume token and our watch ID is affected, we assume the stream
 	// is now healthy, so we reset our backoff time to the minimum.
 	if tc.ResumeToken != nil && (len(tc.TargetIds) == 0 || hasWatchTargetID(tc.TargetIds)) {
-		s.backoff = defaultBackoff
+		s.backoff = s.initialBackoff
 	}
 	return false // not in a consistent state, keep receiving
 }
 
 func (s *watchStream) resetDocs() {
 	s.target.ResumeType = nil // clear resume token
+	s.resumeToken = nil
+	s.resumed = false
 	s.current = false
 	s.changeMap = map[string]*DocumentSnapshot{}
 	// Mark each document as deleted. If documents are not deleted, they
@@ -472,6 +645,7 @@ func (s *watchStream) recv() (*pb.ListenResponse, error) {
 				// Do not retry if open fails.
 				return nil, err
 			}
+			s.notify(ListenerStateChange{State: ListenerConnected})
 		}
 		res, err := s.lc.Recv()
 		if err == nil || isPermanentWatchError(err) {
@@ -484,6 +658,7 @@ func (s *watchStream) recv() (*pb.ListenResponse, error) {
 		if status.Code(err) == codes.ResourceExhausted {
 			dur = s.backoff.Max
 		}
+		s.notify(ListenerStateChange{State: ListenerRetrying, Err: err, Backoff: dur, ResumeToken: s.resumeToken})
 		if err := sleep(s.ctx, dur); err != nil {
 			return nil, err
 		}
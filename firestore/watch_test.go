@@ -191,7 +191,7 @@ func TestWatchCancel(t *testing.T) {
 		t.Fatal(err)
 	}
 	cancel()
-	_, _, _, err = ws.nextSnapshot()
+	_, _, _, _, err = ws.nextSnapshot()
 	codeEq(t, "cancel before open", codes.Canceled, err)
 
 	request := &pb.ListenRequest{
@@ -216,15 +216,195 @@ func TestWatchCancel(t *testing.T) {
 		t.Fatal(err)
 	}
 	srv.addRPC(request, []interface{}{current, noChange})
-	_, _, _, _ = ws.nextSnapshot()
+	_, _, _, _, _ = ws.nextSnapshot()
 	cancel()
 	// Because of how the mock works, the following results in an EOF on the stream, which
 	// is a non-permanent error that causes a retry. That retry ends up in gax.Sleep, which
 	// finds that the context is done and returns ctx.Err(), which is context.Canceled.
 	// Verify that we transform that context.Canceled into a gRPC Status with code Canceled.
-	_, _, _, err = ws.nextSnapshot()
+	_, _, _, _, err = ws.nextSnapshot()
 	codeEq(t, "cancel from gax.Sleep", codes.Canceled, err)
 
 	// TODO(jba): Test that we get codes.Canceled when canceling an RPC.
 	// We had a test for this in a21236af, but it was flaky for unclear reasons.
 }
+
+func TestWatchResume(t *testing.T) {
+	const dbPath = "projects/projectID/databases/(default)"
+	ctx := context.Background()
+	c, srv, cleanup := newMock(t)
+	defer cleanup()
+
+	var states []ListenerState
+	it := c.Collection("C").SnapshotsWithOptions(ctx, &SnapshotOptions{
+		ResumeToken:   []byte("tok1"),
+		OnStateChange: func(sc ListenerStateChange) { states = append(states, sc.State) },
+	})
+	defer it.Stop()
+	if got := it.ResumeToken(); got != nil {
+		t.Errorf("got resume token %q before first snapshot, want nil", got)
+	}
+	target := proto.Clone(it.ws.target).(*pb.Target)
+	if got, want := target.GetResumeToken(), []byte("tok1"); string(got) != string(want) {
+		t.Fatalf("got target resume token %q, want %q", got, want)
+	}
+	srv.addRPC(&pb.ListenRequest{
+		Database:     dbPath,
+		TargetChange: &pb.ListenRequest_AddTarget{AddTarget: target},
+	}, []interface{}{
+		&pb.ListenResponse{ResponseType: &pb.ListenResponse_DocumentChange{DocumentChange: &pb.DocumentChange{
+			Document: &pb.Document{
+				Name:       dbPath + "/documents/C/a",
+				CreateTime: aTimestamp,
+				UpdateTime: aTimestamp2,
+				Fields:     map[string]*pb.Value{"f": intval(1)},
+			},
+			TargetIds: []int32{watchTargetID},
+		}}},
+		&pb.ListenResponse{ResponseType: &pb.ListenResponse_DocumentDelete{DocumentDelete: &pb.DocumentDelete{
+			Document: dbPath + "/documents/C/b",
+		}}},
+		// The count includes documents that the resumed stream has not seen, so
+		// it must not cause a reset.
+		&pb.ListenResponse{ResponseType: &pb.ListenResponse_Filter{Filter: &pb.ExistenceFilter{Count: 10}}},
+		&pb.ListenResponse{ResponseType: &pb.ListenResponse_TargetChange{TargetChange: &pb.TargetChange{
+			TargetChangeType: pb.TargetChange_CURRENT,
+		}}},
+		&pb.ListenResponse{ResponseType: &pb.ListenResponse_TargetChange{TargetChange: &pb.TargetChange{
+			TargetChangeType: pb.TargetChange_NO_CHANGE,
+			ReadTime:         aTimestamp3,
+			ResumeToken:      []byte("tok2"),
+		}}},
+	})
+	qs, err := it.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(qs.Changes), 2; got != want {
+		t.Fatalf("got %d changes, want %d", got, want)
+	}
+	if ch := qs.Changes[0]; ch.Kind != DocumentRemoved || ch.Doc.Ref.ID != "b" || ch.Doc.Exists() {
+		t.Errorf("got first change %+v, want removal of b", ch)
+	}
+	if ch := qs.Changes[1]; ch.Kind != DocumentAdded || ch.Doc.Ref.ID != "a" || !ch.Doc.Exists() {
+		t.Errorf("got second change %+v, want addition of a", ch)
+	}
+	if got, want := string(qs.ResumeToken), "tok2"; got != want {
+		t.Errorf("got snapshot resume token %q, want %q", got, want)
+	}
+	if got, want := string(it.ResumeToken()), "tok2"; got != want {
+		t.Errorf("got iterator resume token %q, want %q", got, want)
+	}
+	if want := []ListenerState{ListenerConnected, ListenerCurrent}; !testEqual(states, want) {
+		t.Errorf("got states %v, want %v", states, want)
+	}
+	if it.ws.resumed {
+		t.Error("listener still resumed after the target became current")
+	}
+}
+
+func TestWatchResumeFilterAfterCurrent(t *testing.T) {
+	const dbPath = "projects/projectID/databases/(default)"
+	ctx := context.Background()
+	c, srv, cleanup := newMock(t)
+	defer cleanup()
+
+	var states []ListenerState
+	it := c.Collection("C").SnapshotsWithOptions(ctx, &SnapshotOptions{
+		ResumeToken:   []byte("tok1"),
+		OnStateChange: func(sc ListenerStateChange) { states = append(states, sc.State) },
+	})
+	defer it.Stop()
+	target := proto.Clone(it.ws.target).(*pb.Target)
+	docA := &pb.ListenResponse{ResponseType: &pb.ListenResponse_DocumentChange{DocumentChange: &pb.DocumentChange{
+		Document: &pb.Document{
+			Name:       dbPath + "/documents/C/a",
+			CreateTime: aTimestamp,
+			UpdateTime: aTimestamp2,
+			Fields:     map[string]*pb.Value{"f": intval(1)},
+		},
+		TargetIds: []int32{watchTargetID},
+	}}}
+	docB := &pb.ListenResponse{ResponseType: &pb.ListenResponse_DocumentChange{DocumentChange: &pb.DocumentChange{
+		Document: &pb.Document{
+			Name:       dbPath + "/documents/C/b",
+			CreateTime: aTimestamp,
+			UpdateTime: aTimestamp,
+			Fields:     map[string]*pb.Value{"f": intval(2)},
+		},
+		TargetIds: []int32{watchTargetID},
+	}}}
+	current := &pb.ListenResponse{ResponseType: &pb.ListenResponse_TargetChange{TargetChange: &pb.TargetChange{
+		TargetChangeType: pb.TargetChange_CURRENT,
+	}}}
+	noChange := func(tok string) *pb.ListenResponse {
+		return &pb.ListenResponse{ResponseType: &pb.ListenResponse_TargetChange{TargetChange: &pb.TargetChange{
+			TargetChangeType: pb.TargetChange_NO_CHANGE,
+			ReadTime:         aTimestamp3,
+			ResumeToken:      []byte(tok),
+		}}}
+	}
+	srv.addRPC(&pb.ListenRequest{
+		Database:     dbPath,
+		TargetChange: &pb.ListenRequest_AddTarget{AddTarget: target},
+	}, []interface{}{
+		docA, current, noChange("tok2"),
+		// Once current, the listener checks the count, which includes b.
+		&pb.ListenResponse{ResponseType: &pb.ListenResponse_Filter{Filter: &pb.ExistenceFilter{Count: 2}}},
+	})
+	// After the reset, the stream is reopened without a resume token.
+	reopened := proto.Clone(target).(*pb.Target)
+	reopened.ResumeType = nil
+	srv.addRPC(&pb.ListenRequest{
+		Database:     dbPath,
+		TargetChange: &pb.ListenRequest_AddTarget{AddTarget: reopened},
+	}, []interface{}{docA, docB, current, noChange("tok3")})
+
+	if _, err := it.Next(); err != nil {
+		t.Fatal(err)
+	}
+	qs, err := it.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := qs.Size, 2; got != want {
+		t.Errorf("got %d documents, want %d", got, want)
+	}
+	if want := []ListenerState{ListenerConnected, ListenerCurrent, ListenerReset, ListenerConnected, ListenerCurrent}; !testEqual(states, want) {
+		t.Errorf("got states %v, want %v", states, want)
+	}
+}
+
+func TestWatchRetryStateChange(t *testing.T) {
+	ctx := context.Background()
+	c, srv, cleanup := newMock(t)
+	defer cleanup()
+
+	var changes []ListenerStateChange
+	ws := newWatchStream(ctx, c, nil, &pb.Target{})
+	ws.applyOptions(&SnapshotOptions{
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		OnStateChange:  func(sc ListenerStateChange) { changes = append(changes, sc) },
+	})
+	request := &pb.ListenRequest{
+		Database:     "projects/projectID/databases/(default)",
+		TargetChange: &pb.ListenRequest_AddTarget{AddTarget: &pb.Target{}},
+	}
+	response := &pb.ListenResponse{ResponseType: &pb.ListenResponse_DocumentChange{DocumentChange: &pb.DocumentChange{}}}
+	srv.addRPC(request, []interface{}{status.Error(codes.Unavailable, "")})
+	srv.addRPC(request, []interface{}{response})
+	if _, err := ws.recv(); err != nil {
+		t.Fatal(err)
+	}
+	var got []ListenerState
+	for _, ch := range changes {
+		got = append(got, ch.State)
+	}
+	if want := []ListenerState{ListenerConnected, ListenerRetrying, ListenerConnected}; !testEqual(got, want) {
+		t.Fatalf("got states %v, want %v", got, want)
+	}
+	if r := changes[1]; status.Code(r.Err) != codes.Unavailable || r.Backoff > time.Millisecond {
+		t.Errorf("got retry %+v, want Unavailable error with backoff of at most 1ms", r)
+	}
+}
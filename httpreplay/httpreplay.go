@@ -27,6 +27,13 @@
 //     You will get back the recorded responses.
 //  3. Close the Replayer when you're done.
 //
+// Response bodies are recorded in full, including chunked and streamed bodies and
+// their trailers, and are replayed with the same encoding. A request is matched with
+// the first unused recorded request that is equal to it, so repeated requests, like
+// the chunks of a resumable upload or byte-range reads of an object, replay in order.
+// Use the Recorder's methods to remove or clear secrets in headers, query parameters
+// and JSON bodies.
+//
 // This package is EXPERIMENTAL and is subject to change or removal without notice.
 // It requires Go version 1.8 or higher.
 package httpreplay
@@ -96,6 +103,25 @@ func (r *Recorder) ClearQueryParams(patterns ...string) {
 	r.proxy.ClearQueryParams(patterns)
 }
 
+// RemoveResponseHeaders will remove response headers matching patterns from the log.
+// Replayed responses will not have these headers.
+//
+// Pattern is taken literally except for *, which matches any sequence of characters.
+func (r *Recorder) RemoveResponseHeaders(patterns ...string) {
+	r.proxy.RemoveResponseHeaders(patterns)
+}
+
+// ClearBodyFields will replace the value of the fields of JSON objects in request and
+// response bodies whose names match any of the patterns with CLEARED, on both
+// recording and replay. The fields can be at any depth. Use ClearBodyFields for
+// secrets like access tokens, or for values like project IDs that should not be
+// committed with the log. Requests are still matched on the other fields on replay.
+//
+// Pattern is taken literally except for *, which matches any sequence of characters.
+func (r *Recorder) ClearBodyFields(patterns ...string) {
+	r.proxy.ClearBodyFields(patterns)
+}
+
 // Client returns an http.Client to be used for recording. Provide authentication options
 // like option.WithTokenSource as you normally would, or omit them to use Application Default
 // Credentials.
@@ -25,6 +25,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -415,6 +416,98 @@ func TestRemoveAndClear(t *testing.T) {
 	}
 }
 
+func TestStreamingAndBodyFields(t *testing.T) {
+	log.SetOutput(io.Discard)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Trailer", "X-Checksum")
+		// Stream the body in two chunks.
+		fmt.Fprint(w, `{"id_token": "secret",`)
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, `"value": "v"}`)
+		w.Header().Set("X-Checksum", "abc")
+	}))
+	defer srv.Close()
+
+	replayFilename := tempFilename(t, "TestStreamingAndBodyFields*.replay")
+	defer os.Remove(replayFilename)
+
+	ctx := context.Background()
+	post := func(hc *http.Client, body string) (*http.Response, string) {
+		t.Helper()
+		res, err := hc.Post(srv.URL, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res, string(b)
+	}
+
+	rec, err := httpreplay.NewRecorder(replayFilename, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.ClearBodyFields("*_token", "password")
+	hc, err := rec.Client(ctx, option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The recording client sees the real response.
+	if _, got := post(hc, `{"password": "p1", "q": "x"}`); !strings.Contains(got, "secret") {
+		t.Errorf("got %s while recording, want the real response", got)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(replayFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); strings.Contains(s, "secret") || strings.Contains(s, "p1") {
+		t.Errorf("log contains cleared values:\n%s", s)
+	}
+
+	for _, test := range []struct {
+		body        string
+		wantSuccess bool
+	}{
+		{`{"password": "p2", "q": "x"}`, true}, // different cleared field is OK
+		{`{"q": "x"}`, false},                  // missing cleared field
+		{`{"password": "p1", "q": "y"}`, false},
+	} {
+		rep, err := httpreplay.NewReplayer(replayFilename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hc, err := rep.Client(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, got := post(hc, test.body)
+		rep.Close()
+		if (res.StatusCode == 200) != test.wantSuccess {
+			t.Errorf("%s: got %d, wanted success=%t", test.body, res.StatusCode, test.wantSuccess)
+			continue
+		}
+		if !test.wantSuccess {
+			continue
+		}
+		if want := `{"id_token":"CLEARED","value":"v"}`; got != want {
+			t.Errorf("got body %s, want %s", got, want)
+		}
+		if got := res.Trailer.Get("X-Checksum"); got != "abc" {
+			t.Errorf("got trailer %q, want abc", got)
+		}
+		if res.ContentLength != -1 {
+			t.Errorf("got ContentLength %d, want -1 for a chunked response", res.ContentLength)
+		}
+	}
+}
+
 func tempFilename(t *testing.T, pattern string) string {
 	f, err := os.CreateTemp("", pattern)
 	if err != nil {
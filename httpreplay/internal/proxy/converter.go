@@ -16,6 +16,7 @@ package proxy
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"mime"
 	"mime/multipart"
@@ -34,6 +35,10 @@ type Converter struct {
 	RemoveResponseHeaders []tRegexp // remove matching headers in responses
 	ClearParams           []tRegexp // replace matching query params with "CLEARED"
 	RemoveParams          []tRegexp // remove matching query params
+
+	// ClearBodyFields applies to the fields of JSON objects in request and
+	// response bodies, at any depth.
+	ClearBodyFields []tRegexp `json:",omitempty"` // replace values of matching fields with "CLEARED"
 }
 
 // A regexp that can be marshaled to and from text.
@@ -67,6 +72,14 @@ func (c *Converter) registerClearParams(pat string) {
 	c.ClearParams = append(c.ClearParams, pattern(pat))
 }
 
+func (c *Converter) registerRemoveResponseHeaders(pat string) {
+	c.RemoveResponseHeaders = append(c.RemoveResponseHeaders, pattern(pat))
+}
+
+func (c *Converter) registerClearBodyFields(pat string) {
+	c.ClearBodyFields = append(c.ClearBodyFields, pattern(pat))
+}
+
 var (
 	defaultRemoveRequestHeaders = []string{
 		"Authorization", // not only is it secret, but it is probably missing on replay
@@ -140,6 +153,9 @@ func (c *Converter) convertRequest(req *http.Request) (*Request, error) {
 	if err != nil {
 		return nil, err
 	}
+	for i, p := range parts {
+		parts[i] = scrubJSON(p, c.ClearBodyFields)
+	}
 	url2 := *req.URL
 	url2.RawQuery = scrubQuery(url2.RawQuery, c.ClearParams, c.RemoveParams)
 	return &Request{
@@ -188,21 +204,78 @@ func parseRequestBody(contentType string, body []byte) (string, [][]byte, error)
 }
 
 func (c *Converter) convertResponse(res *http.Response) (*Response, error) {
+	// Reading the body to completion also reads chunked and streamed bodies
+	// to their end, and sets the trailers.
 	data, err := snapshotBody(&res.Body)
 	if err != nil {
 		return nil, err
 	}
+	if res.Header.Get("Content-Encoding") == "" {
+		data = scrubJSON(data, c.ClearBodyFields)
+	}
 	return &Response{
-		StatusCode: res.StatusCode,
-		Proto:      res.Proto,
-		ProtoMajor: res.ProtoMajor,
-		ProtoMinor: res.ProtoMinor,
-		Header:     scrubHeaders(res.Header, c.ClearHeaders, c.RemoveResponseHeaders),
-		Body:       data,
-		Trailer:    scrubHeaders(res.Trailer, c.ClearHeaders, c.RemoveResponseHeaders),
+		StatusCode:       res.StatusCode,
+		Proto:            res.Proto,
+		ProtoMajor:       res.ProtoMajor,
+		ProtoMinor:       res.ProtoMinor,
+		Header:           scrubHeaders(res.Header, c.ClearHeaders, c.RemoveResponseHeaders),
+		Body:             data,
+		Trailer:          scrubHeaders(res.Trailer, c.ClearHeaders, c.RemoveResponseHeaders),
+		TransferEncoding: res.TransferEncoding,
 	}, nil
 }
 
+// scrubJSON replaces the values of the fields matching clear in body, if it
+// is a JSON object or array, with "CLEARED". Other bodies, and bodies without
+// matching fields, are returned unchanged.
+func scrubJSON(body []byte, clear []tRegexp) []byte {
+	if len(clear) == 0 {
+		return body
+	}
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return body
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber() // preserve numbers exactly
+	var v interface{}
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return body
+	}
+	if !scrubJSONValue(v, clear) {
+		return body
+	}
+	scrubbed, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return scrubbed
+}
+
+// scrubJSONValue clears matching fields in v, and reports whether it
+// cleared any.
+func scrubJSONValue(v interface{}, clear []tRegexp) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if match(k, clear) {
+				v[k] = "CLEARED"
+				changed = true
+			} else if scrubJSONValue(e, clear) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			if scrubJSONValue(e, clear) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
 func snapshotBody(body *io.ReadCloser) ([]byte, error) {
 	data, err := io.ReadAll(*body)
 	if err != nil {
@@ -116,3 +116,20 @@ func TestScrubQuery(t *testing.T) {
 		}
 	}
 }
+
+func TestScrubJSON(t *testing.T) {
+	clear := []tRegexp{pattern("*token"), pattern("projectId")}
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"not json", "not json"},
+		{`{"a": 1}`, `{"a": 1}`}, // unchanged, including formatting
+		{`{"access_token": "x", "n": 12345678901234567890}`, `{"access_token":"CLEARED","n":12345678901234567890}`},
+		{`[{"b": {"projectId": "p", "c": [1]}}]`, `[{"b":{"c":[1],"projectId":"CLEARED"}}]`},
+		{`{"a": 1} {"token": 2}`, `{"a": 1} {"token": 2}`}, // not a single value
+	} {
+		if got := string(scrubJSON([]byte(test.in), clear)); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.in, got, test.want)
+		}
+	}
+}
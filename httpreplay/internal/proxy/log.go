@@ -70,6 +70,8 @@ type Response struct {
 	Header     http.Header // http.Response.Header
 	Body       []byte      // http.Response.Body, read to completion
 	Trailer    http.Header `json:",omitempty"` // http.Response.Trailer
+
+	TransferEncoding []string `json:",omitempty"` // http.Response.TransferEncoding, e.g. "chunked"
 }
 
 // A Logger maintains a request-response log.
@@ -161,6 +163,16 @@ func toHTTPResponse(lr *Response, req *http.Request) *http.Response {
 		ContentLength: int64(len(lr.Body)),
 	}
 	res.Request = req
+	// Replay chunked responses, and responses with trailers, which require
+	// chunking, with chunked encoding, so that clients see the same framing
+	// and the trailers.
+	if req.Method != "HEAD" && (len(lr.TransferEncoding) > 0 || len(lr.Trailer) > 0) {
+		res.TransferEncoding = []string{"chunked"}
+		res.ContentLength = -1
+		if len(lr.Trailer) > 0 {
+			res.Trailer = lr.Trailer.Clone()
+		}
+	}
 	// For HEAD, set ContentLength to the value of the Content-Length header, or -1
 	// if there isn't one.
 	if req.Method == "HEAD" {
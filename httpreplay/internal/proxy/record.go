@@ -194,6 +194,25 @@ func (p *Proxy) ClearQueryParams(patterns []string) {
 	}
 }
 
+// RemoveResponseHeaders will remove response headers matching patterns from the log.
+// Pattern is taken literally except for *, which matches any sequence of characters.
+func (p *Proxy) RemoveResponseHeaders(patterns []string) {
+	for _, pat := range patterns {
+		p.logger.log.Converter.registerRemoveResponseHeaders(pat)
+	}
+}
+
+// ClearBodyFields will replace the values of matching fields of JSON request and
+// response bodies with CLEARED.
+//
+// This only needs to be called during recording; the patterns will be saved to the
+// log for replay.
+func (p *Proxy) ClearBodyFields(patterns []string) {
+	for _, pat := range patterns {
+		p.logger.log.Converter.registerClearBodyFields(pat)
+	}
+}
+
 // IgnoreHeader will cause h to be ignored during matching on replay.
 // Deprecated: use RemoveRequestHeaders instead.
 func (p *Proxy) IgnoreHeader(h string) {
@@ -0,0 +1,135 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iam
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/type/expr"
+)
+
+// A Condition is a Common Expression Language (CEL) expression for an IAM
+// condition, such as `resource.name.startsWith("projects/_/buckets/b")`. The
+// functions of this package that return a Condition quote their arguments,
+// so that they can't change the meaning of the expression. See
+// https://cloud.google.com/iam/docs/conditions-overview for the attributes
+// that can be used.
+//
+// Conditions can only be used in bindings of policies with version 3; see
+// Handle.V3.
+type Condition string
+
+// Expr returns the condition as an Expr with the given title and
+// description, which can be used as the condition of a Binding.
+func (c Condition) Expr(title, description string) *expr.Expr {
+	return &expr.Expr{
+		Title:       title,
+		Description: description,
+		Expression:  string(c),
+	}
+}
+
+// RequestTimeBefore returns a condition that is true for requests made before
+// t.
+func RequestTimeBefore(t time.Time) Condition {
+	return Condition("request.time < " + timestamp(t))
+}
+
+// RequestTimeAfter returns a condition that is true for requests made at t or
+// later.
+func RequestTimeAfter(t time.Time) Condition {
+	return Condition("request.time >= " + timestamp(t))
+}
+
+// RequestTimeBetween returns a condition that is true for requests made at
+// start or later, and before end.
+func RequestTimeBetween(start, end time.Time) Condition {
+	return And(RequestTimeAfter(start), RequestTimeBefore(end))
+}
+
+// RequestHoursBetween returns a condition that is true for requests made
+// every day from the start hour, included, to the end hour, excluded, in the
+// time zone with the given IANA name, such as "America/New_York". Hours range
+// from 0 to 23; end may be 24.
+func RequestHoursBetween(timeZone string, start, end int) Condition {
+	hours := "request.time.getHours(" + strconv.Quote(timeZone) + ")"
+	return And(
+		Condition(fmt.Sprintf("%s >= %d", hours, start)),
+		Condition(fmt.Sprintf("%s < %d", hours, end)),
+	)
+}
+
+func timestamp(t time.Time) string {
+	return "timestamp(" + strconv.Quote(t.UTC().Format(time.RFC3339Nano)) + ")"
+}
+
+// ResourceNameStartsWith returns a condition that is true for resources whose
+// name starts with prefix, for example "projects/_/buckets/b/objects/logs/".
+func ResourceNameStartsWith(prefix string) Condition {
+	return Condition("resource.name.startsWith(" + strconv.Quote(prefix) + ")")
+}
+
+// ResourceNameEquals returns a condition that is true for the resource with
+// the given name.
+func ResourceNameEquals(name string) Condition {
+	return Condition("resource.name == " + strconv.Quote(name))
+}
+
+// ResourceType returns a condition that is true for resources of the given
+// type, for example "storage.googleapis.com/Object".
+func ResourceType(typ string) Condition {
+	return Condition("resource.type == " + strconv.Quote(typ))
+}
+
+// ResourceService returns a condition that is true for resources of the given
+// service, for example "storage.googleapis.com".
+func ResourceService(service string) Condition {
+	return Condition("resource.service == " + strconv.Quote(service))
+}
+
+// And returns a condition that is true if all the conditions are true. With no
+// conditions, it returns a condition that is always true.
+func And(conds ...Condition) Condition {
+	return join(conds, " && ", "true")
+}
+
+// Or returns a condition that is true if any of the conditions is true. With
+// no conditions, it returns a condition that is always false.
+func Or(conds ...Condition) Condition {
+	return join(conds, " || ", "false")
+}
+
+// Not returns a condition that is true if c is false.
+func Not(c Condition) Condition {
+	return Condition("!(" + string(c) + ")")
+}
+
+// join joins conds with op, or returns empty if there are no conditions.
+func join(conds []Condition, op string, empty Condition) Condition {
+	switch len(conds) {
+	case 0:
+		return empty
+	case 1:
+		return conds[0]
+	}
+	parts := make([]string, len(conds))
+	for i, c := range conds {
+		parts[i] = "(" + string(c) + ")"
+	}
+	return Condition(strings.Join(parts, op))
+}
@@ -0,0 +1,234 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iam
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	pb "cloud.google.com/go/iam/apiv1/iampb"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/genproto/googleapis/type/expr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// A BindingDelta is a member that was added to or removed from a role, with an
+// optional condition.
+type BindingDelta struct {
+	// Added is true if the member was added, and false if it was removed.
+	Added bool
+
+	Role   RoleName
+	Member string
+
+	// Condition is the condition of the binding, or nil.
+	Condition *expr.Expr
+}
+
+// bindingKey identifies the binding of a role with a condition.
+type bindingKey struct {
+	role                           RoleName
+	title, description, expression string
+}
+
+func keyOf(b *pb.Binding) bindingKey {
+	c := b.GetCondition()
+	return bindingKey{RoleName(b.GetRole()), c.GetTitle(), c.GetDescription(), c.GetExpression()}
+}
+
+// memberSets returns the members of each binding, and the conditions of the
+// bindings.
+func memberSets(bindings []*pb.Binding) (map[bindingKey]map[string]bool, map[bindingKey]*expr.Expr) {
+	members := map[bindingKey]map[string]bool{}
+	conds := map[bindingKey]*expr.Expr{}
+	for _, b := range bindings {
+		k := keyOf(b)
+		if members[k] == nil {
+			members[k] = map[string]bool{}
+		}
+		for _, m := range b.GetMembers() {
+			members[k][m] = true
+		}
+		conds[k] = b.GetCondition()
+	}
+	return members, conds
+}
+
+// DiffBindings returns the members that were added or removed in the bindings
+// of after, compared to the ones of before. A member is identified by its
+// role and its condition, so changing the condition of a member is reported
+// as a removal and an addition. The deltas are sorted by role, condition
+// expression and member, with removals first.
+func DiffBindings(before, after []*pb.Binding) []BindingDelta {
+	bm, bconds := memberSets(before)
+	am, aconds := memberSets(after)
+	var deltas []BindingDelta
+	for k, ms := range bm {
+		for m := range ms {
+			if !am[k][m] {
+				deltas = append(deltas, BindingDelta{Added: false, Role: k.role, Member: m, Condition: bconds[k]})
+			}
+		}
+	}
+	for k, ms := range am {
+		for m := range ms {
+			if !bm[k][m] {
+				deltas = append(deltas, BindingDelta{Added: true, Role: k.role, Member: m, Condition: aconds[k]})
+			}
+		}
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		di, dj := deltas[i], deltas[j]
+		if di.Added != dj.Added {
+			return !di.Added
+		}
+		if di.Role != dj.Role {
+			return di.Role < dj.Role
+		}
+		if ei, ej := di.Condition.GetExpression(), dj.Condition.GetExpression(); ei != ej {
+			return ei < ej
+		}
+		return di.Member < dj.Member
+	})
+	return deltas
+}
+
+// ApplyBindingDeltas returns a copy of bindings with the deltas applied.
+// Adding a member that is already present, or removing a member that is not,
+// has no effect. Bindings left without members are removed.
+func ApplyBindingDeltas(bindings []*pb.Binding, deltas []BindingDelta) []*pb.Binding {
+	var out []*pb.Binding
+	index := map[bindingKey]*pb.Binding{}
+	for _, b := range bindings {
+		c := proto.Clone(b).(*pb.Binding)
+		out = append(out, c)
+		if _, ok := index[keyOf(c)]; !ok {
+			index[keyOf(c)] = c
+		}
+	}
+	for _, d := range deltas {
+		k := keyOf(&pb.Binding{Role: string(d.Role), Condition: d.Condition})
+		if d.Added {
+			b := index[k]
+			if b == nil {
+				b = &pb.Binding{Role: string(d.Role)}
+				if d.Condition != nil {
+					b.Condition = proto.Clone(d.Condition).(*expr.Expr)
+				}
+				out = append(out, b)
+				index[k] = b
+			}
+			if memberIndex(d.Member, b) < 0 {
+				b.Members = append(b.Members, d.Member)
+			}
+			continue
+		}
+		// The member may be listed in several bindings with the same key.
+		for _, b := range out {
+			if keyOf(b) == k {
+				if i := memberIndex(d.Member, b); i >= 0 {
+					b.Members = append(b.Members[:i], b.Members[i+1:]...)
+				}
+			}
+		}
+	}
+	var kept []*pb.Binding
+	for _, b := range out {
+		if len(b.Members) > 0 {
+			kept = append(kept, b)
+		}
+	}
+	return kept
+}
+
+// MergeBindings performs a three-way merge of policy bindings: it applies the
+// changes made from base to local to remote, which is the current version of
+// the policy, and returns the resulting bindings. The changes made in remote
+// since base are kept. It can be used when a policy was modified locally
+// from base, and setting it failed because the policy changed concurrently.
+func MergeBindings(base, local, remote []*pb.Binding) []*pb.Binding {
+	return ApplyBindingDeltas(remote, DiffBindings(base, local))
+}
+
+const maxModifyAttempts = 5
+
+var modifyBackoff = gax.Backoff{
+	Initial:    100 * time.Millisecond,
+	Max:        5 * time.Second,
+	Multiplier: 2,
+}
+
+// isConcurrentModification reports whether err is returned by SetIamPolicy
+// when the etag of the policy doesn't match the current one.
+func isConcurrentModification(err error) bool {
+	return status.Code(err) == codes.Aborted
+}
+
+// ErrNoChange can be returned by the function passed to ModifyPolicy to
+// leave the policy unchanged. ModifyPolicy then returns nil.
+var ErrNoChange = errors.New("iam: no change")
+
+// ModifyPolicy reads the resource's policy, calls modify with it, and sets the
+// modified policy. If the policy was changed concurrently, which makes
+// SetPolicy fail with code Aborted, the policy is read again and modify is
+// called again, with backoff, up to 5 times in total. modify must therefore
+// only depend on the policy it is passed. If modify returns an error, the
+// policy is not set; if the error is ErrNoChange, ModifyPolicy returns nil.
+func (h *Handle) ModifyPolicy(ctx context.Context, modify func(*Policy) error) error {
+	return retryModify(ctx, func() error {
+		p, err := h.Policy(ctx)
+		if err != nil {
+			return err
+		}
+		if err := modify(p); err != nil {
+			return err
+		}
+		return h.SetPolicy(ctx, p)
+	})
+}
+
+// ModifyPolicy is like Handle.ModifyPolicy for a Policy3.
+func (h *Handle3) ModifyPolicy(ctx context.Context, modify func(*Policy3) error) error {
+	return retryModify(ctx, func() error {
+		p, err := h.Policy(ctx)
+		if err != nil {
+			return err
+		}
+		if err := modify(p); err != nil {
+			return err
+		}
+		return h.SetPolicy(ctx, p)
+	})
+}
+
+func retryModify(ctx context.Context, attempt func() error) error {
+	bo := modifyBackoff
+	for i := 1; ; i++ {
+		err := attempt()
+		if errors.Is(err, ErrNoChange) {
+			return nil
+		}
+		if !isConcurrentModification(err) || i == maxModifyAttempts {
+			return err
+		}
+		if err := gax.Sleep(ctx, bo.Pause()); err != nil {
+			return err
+		}
+	}
+}
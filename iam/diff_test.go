@@ -0,0 +1,174 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iam
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "cloud.google.com/go/iam/apiv1/iampb"
+	"cloud.google.com/go/internal/testutil"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestConditions(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		got  Condition
+		want string
+	}{
+		{RequestTimeBefore(start), `request.time < timestamp("2024-01-01T00:00:00Z")`},
+		{
+			RequestTimeBetween(start, start.Add(time.Hour)),
+			`(request.time >= timestamp("2024-01-01T00:00:00Z")) && (request.time < timestamp("2024-01-01T01:00:00Z"))`,
+		},
+		{
+			RequestHoursBetween("Europe/Paris", 9, 17),
+			`(request.time.getHours("Europe/Paris") >= 9) && (request.time.getHours("Europe/Paris") < 17)`,
+		},
+		{ResourceNameStartsWith(`a"b`), `resource.name.startsWith("a\"b")`},
+		{
+			Or(ResourceType("storage.googleapis.com/Object"), Not(ResourceService("storage.googleapis.com"))),
+			`(resource.type == "storage.googleapis.com/Object") || (!(resource.service == "storage.googleapis.com"))`,
+		},
+		{And(ResourceNameEquals("n")), `resource.name == "n"`},
+		{And(), `true`},
+		{Or(), `false`},
+	} {
+		if string(test.got) != test.want {
+			t.Errorf("got  %s\nwant %s", test.got, test.want)
+		}
+	}
+	e := ResourceType("t").Expr("title", "desc")
+	if e.Title != "title" || e.Description != "desc" || e.Expression != `resource.type == "t"` {
+		t.Errorf("got %v", e)
+	}
+}
+
+func TestDiffAndMergeBindings(t *testing.T) {
+	cond := ResourceType("t").Expr("only t", "")
+	base := []*pb.Binding{
+		{Role: "roles/viewer", Members: []string{"user:a", "user:b"}},
+		{Role: "roles/editor", Members: []string{"user:c"}, Condition: cond},
+	}
+	local := []*pb.Binding{
+		{Role: "roles/viewer", Members: []string{"user:a", "user:d"}},
+		{Role: "roles/editor", Members: []string{"user:c"}},
+	}
+	want := []BindingDelta{
+		{Added: false, Role: "roles/editor", Member: "user:c", Condition: cond},
+		{Added: false, Role: "roles/viewer", Member: "user:b"},
+		{Added: true, Role: "roles/editor", Member: "user:c"},
+		{Added: true, Role: "roles/viewer", Member: "user:d"},
+	}
+	if got := DiffBindings(base, local); !testutil.Equal(got, want) {
+		t.Errorf("DiffBindings:\ngot  %v\nwant %v", got, want)
+	}
+
+	// The remote policy has changed since base.
+	remote := []*pb.Binding{
+		{Role: "roles/viewer", Members: []string{"user:a", "user:b", "user:e"}},
+		{Role: "roles/editor", Members: []string{"user:c", "user:f"}, Condition: cond},
+	}
+	remoteCopy := proto.Clone(&pb.Policy{Bindings: remote}).(*pb.Policy)
+	wantMerged := []*pb.Binding{
+		{Role: "roles/viewer", Members: []string{"user:a", "user:e", "user:d"}},
+		{Role: "roles/editor", Members: []string{"user:f"}, Condition: cond},
+		{Role: "roles/editor", Members: []string{"user:c"}},
+	}
+	if got := MergeBindings(base, local, remote); !testutil.Equal(got, wantMerged) {
+		t.Errorf("MergeBindings:\ngot  %v\nwant %v", got, wantMerged)
+	}
+	if !testutil.Equal(remote, remoteCopy.Bindings) {
+		t.Error("MergeBindings modified remote")
+	}
+
+	// Removing the last member removes the binding.
+	got := ApplyBindingDeltas(base, []BindingDelta{{Role: "roles/editor", Member: "user:c", Condition: cond}})
+	if want := base[:1]; !testutil.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// fakeClient is a client whose Set fails with Aborted the first failures
+// times.
+type fakeClient struct {
+	policy   *pb.Policy
+	failures int
+	gets     int
+}
+
+func (f *fakeClient) Get(ctx context.Context, resource string) (*pb.Policy, error) {
+	return f.GetWithVersion(ctx, resource, 1)
+}
+
+func (f *fakeClient) GetWithVersion(_ context.Context, _ string, _ int32) (*pb.Policy, error) {
+	f.gets++
+	return proto.Clone(f.policy).(*pb.Policy), nil
+}
+
+func (f *fakeClient) Set(_ context.Context, _ string, p *pb.Policy) error {
+	if f.failures > 0 {
+		f.failures--
+		return status.Error(codes.Aborted, "etag mismatch")
+	}
+	f.policy = p
+	return nil
+}
+
+func (f *fakeClient) Test(context.Context, string, []string) ([]string, error) {
+	return nil, nil
+}
+
+func TestModifyPolicy(t *testing.T) {
+	defer func(bo gax.Backoff) { modifyBackoff = bo }(modifyBackoff)
+	modifyBackoff = gax.Backoff{Initial: time.Millisecond, Max: time.Millisecond}
+	ctx := context.Background()
+
+	f := &fakeClient{policy: &pb.Policy{}, failures: 2}
+	h := InternalNewHandleClient(f, "r")
+	if err := h.ModifyPolicy(ctx, func(p *Policy) error {
+		p.Add("user:a", Viewer)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if f.gets != 3 {
+		t.Errorf("got %d reads, want 3", f.gets)
+	}
+	if got := (&Policy{InternalProto: f.policy}).Members(Viewer); !testutil.Equal(got, []string{"user:a"}) {
+		t.Errorf("got members %v, want [user:a]", got)
+	}
+
+	f.failures = maxModifyAttempts
+	err := h.V3().ModifyPolicy(ctx, func(p *Policy3) error { return nil })
+	if status.Code(err) != codes.Aborted {
+		t.Errorf("got %v, want Aborted after %d attempts", err, maxModifyAttempts)
+	}
+
+	f.failures = 0
+	if err := h.ModifyPolicy(ctx, func(*Policy) error { return ErrNoChange }); err != nil {
+		t.Errorf("got %v, want nil for ErrNoChange", err)
+	}
+	myErr := errors.New("boom")
+	if err := h.ModifyPolicy(ctx, func(*Policy) error { return myErr }); err != myErr {
+		t.Errorf("got %v, want %v", err, myErr)
+	}
+}
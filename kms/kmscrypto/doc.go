@@ -0,0 +1,50 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kmscrypto provides cryptographic operations backed by Cloud KMS
+// keys, built on the Cloud KMS client in cloud.google.com/go/kms/apiv1.
+//
+// Signer and Decrypter implement crypto.Signer and crypto.Decrypter with
+// asymmetric keys, so that keys held in Cloud KMS can be used wherever the
+// standard library accepts a private key, for example to sign x509
+// certificates:
+//
+//	client, err := kms.NewKeyManagementClient(ctx)
+//	if err != nil {
+//		// TODO: Handle error.
+//	}
+//	signer, err := kmscrypto.NewSigner(ctx, client, "projects/p/locations/global/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1")
+//	if err != nil {
+//		// TODO: Handle error.
+//	}
+//	cert, err := x509.CreateCertificate(rand.Reader, template, parent, signer.Public(), signer)
+//
+// Envelope encrypts data of any size, including streams, with data keys that
+// are wrapped with a symmetric key in Cloud KMS:
+//
+//	env, err := kmscrypto.NewEnvelope(client, "projects/p/locations/global/keyRings/r/cryptoKeys/k", nil)
+//	if err != nil {
+//		// TODO: Handle error.
+//	}
+//	w, err := env.NewWriter(ctx, file)
+//
+// MAC signs and verifies data with HMAC keys, and RawCipher encrypts with
+// imported AES-GCM, AES-CBC and AES-CTR keys, keeping the initialization
+// vector with the ciphertext.
+//
+// All the operations verify the CRC32C checksums of the requests and the
+// responses, as recommended in
+// https://cloud.google.com/kms/docs/data-integrity-guidelines, and return an
+// error wrapping ErrIntegrity if a check fails.
+package kmscrypto // import "cloud.google.com/go/kms/kmscrypto"
@@ -0,0 +1,456 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmscrypto
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+// SymmetricClient is the part of the Cloud KMS client used by Envelope. It
+// is implemented by *kms.KeyManagementClient.
+type SymmetricClient interface {
+	Encrypt(ctx context.Context, req *kmspb.EncryptRequest, opts ...gax.CallOption) (*kmspb.EncryptResponse, error)
+	Decrypt(ctx context.Context, req *kmspb.DecryptRequest, opts ...gax.CallOption) (*kmspb.DecryptResponse, error)
+}
+
+// EnvelopeOptions configures an Envelope.
+type EnvelopeOptions struct {
+	// ChunkSize is the size of the chunks of plaintext that are encrypted
+	// separately. Readers returned by Envelope.NewReader only return
+	// authenticated data, so they buffer up to a chunk. The default is 64 KiB.
+	ChunkSize int
+}
+
+const (
+	defaultChunkSize = 64 << 10
+	maxChunkSize     = 16 << 20
+
+	envelopeMagic   = "KMSE"
+	envelopeVersion = 1
+	noncePrefixSize = 7
+	// streamHeaderSize is the size of the magic, the version, the chunk size
+	// and the nonce prefix, which are authenticated with every chunk.
+	streamHeaderSize = len(envelopeMagic) + 1 + 4 + noncePrefixSize
+	dataKeySize      = 32
+)
+
+// Envelope encrypts data with envelope encryption: each message or stream is
+// encrypted with a new AES-256-GCM data key, which is itself encrypted, or
+// wrapped, with a symmetric key in Cloud KMS, the key encryption key. Only the
+// data key is sent to Cloud KMS, so there is no limit on the size of the
+// data.
+//
+// The data is split in chunks that are encrypted separately, so that streams
+// can be decrypted without holding them in memory. The chunks are ordered and
+// the last one is marked, so that chunks can't be reordered, removed or
+// added without detection.
+//
+// The encrypted data starts with a header holding the wrapped data key and
+// the name of the key version that wrapped it. After the key encryption key is
+// rotated, EnvelopeKeyVersion finds the data wrapped with older versions, and
+// Rewrap wraps its data key with the new primary version without decrypting
+// the data.
+//
+// An Envelope is safe for concurrent use.
+type Envelope struct {
+	client    SymmetricClient
+	keyName   string
+	chunkSize int
+}
+
+// NewEnvelope returns an Envelope that wraps data keys with the symmetric
+// key with the given resource name, in the format
+// projects/*/locations/*/keyRings/*/cryptoKeys/*. opts may be nil.
+func NewEnvelope(client SymmetricClient, keyName string, opts *EnvelopeOptions) (*Envelope, error) {
+	e := &Envelope{client: client, keyName: keyName, chunkSize: defaultChunkSize}
+	if opts != nil && opts.ChunkSize != 0 {
+		e.chunkSize = opts.ChunkSize
+	}
+	if e.chunkSize <= 0 || e.chunkSize > maxChunkSize {
+		return nil, fmt.Errorf("kmscrypto: chunk size %d out of range (0, %d]", e.chunkSize, maxChunkSize)
+	}
+	if strings.Contains(keyName, "/cryptoKeyVersions/") {
+		return nil, fmt.Errorf("kmscrypto: %s is a key version; want a key name", keyName)
+	}
+	return e, nil
+}
+
+// Encrypt encrypts plaintext.
+func (e *Envelope) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := e.NewWriter(ctx, &buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decrypt decrypts ciphertext returned by Encrypt or written by a writer
+// returned by NewWriter.
+func (e *Envelope) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	r, err := e.NewReader(ctx, bytes.NewReader(ciphertext))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// NewWriter generates and wraps a data key, writes the header to w, and
+// returns a writer that encrypts the data written to it and writes it to w.
+// The data is complete only once Close returns without error. Close does not
+// close w.
+func (e *Envelope) NewWriter(ctx context.Context, w io.Writer) (io.WriteCloser, error) {
+	dek := make([]byte, dataKeySize)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, err
+	}
+	keyVersion, wrapped, err := e.wrap(ctx, dek)
+	if err != nil {
+		return nil, err
+	}
+	h := header{chunkSize: e.chunkSize, keyVersion: keyVersion, wrappedKey: wrapped}
+	h.noncePrefix = make([]byte, noncePrefixSize)
+	if _, err := rand.Read(h.noncePrefix); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return nil, err
+	}
+	hb := h.marshal()
+	if _, err := w.Write(hb); err != nil {
+		return nil, err
+	}
+	return &envelopeWriter{
+		w:           w,
+		aead:        aead,
+		chunkSize:   h.chunkSize,
+		noncePrefix: h.noncePrefix,
+		aad:         hb[:streamHeaderSize],
+		buf:         make([]byte, 0, h.chunkSize),
+	}, nil
+}
+
+// NewReader reads the header from r, unwraps the data key, and returns a
+// reader that decrypts the rest of r. The reader returns an error if the data
+// was modified or truncated; data it returned before the error was
+// authenticated, but may be incomplete.
+func (e *Envelope) NewReader(ctx context.Context, r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	h, err := readHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := e.unwrap(ctx, h)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return nil, err
+	}
+	return &envelopeReader{
+		r:           br,
+		aead:        aead,
+		noncePrefix: h.noncePrefix,
+		aad:         h.marshal()[:streamHeaderSize],
+		chunk:       make([]byte, h.chunkSize+aead.Overhead()),
+	}, nil
+}
+
+// Rewrap returns ciphertext with its data key wrapped with the primary
+// version of the key encryption key. The data itself is not decrypted.
+func (e *Envelope) Rewrap(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	r := bytes.NewReader(ciphertext)
+	h, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := e.unwrap(ctx, h)
+	if err != nil {
+		return nil, err
+	}
+	if h.keyVersion, h.wrappedKey, err = e.wrap(ctx, dek); err != nil {
+		return nil, err
+	}
+	// The chunks are authenticated with the stream header only, so they are
+	// still valid with the new wrapped key.
+	rest := ciphertext[len(ciphertext)-r.Len():]
+	return append(h.marshal(), rest...), nil
+}
+
+// wrap encrypts a data key with the primary version of the key, and returns
+// the name of the version and the wrapped key.
+func (e *Envelope) wrap(ctx context.Context, dek []byte) (string, []byte, error) {
+	resp, err := e.client.Encrypt(ctx, &kmspb.EncryptRequest{
+		Name:            e.keyName,
+		Plaintext:       dek,
+		PlaintextCrc32C: crc32c(dek),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if err := checkVerified("plaintext", resp.GetVerifiedPlaintextCrc32C()); err != nil {
+		return "", nil, err
+	}
+	if err := checkCRC32C("ciphertext", resp.GetCiphertext(), resp.GetCiphertextCrc32C()); err != nil {
+		return "", nil, err
+	}
+	return resp.GetName(), resp.GetCiphertext(), nil
+}
+
+// unwrap decrypts the data key in h.
+func (e *Envelope) unwrap(ctx context.Context, h *header) ([]byte, error) {
+	if !strings.HasPrefix(h.keyVersion, e.keyName+"/cryptoKeyVersions/") {
+		return nil, fmt.Errorf("kmscrypto: data key was wrapped with %s, not with a version of %s", h.keyVersion, e.keyName)
+	}
+	resp, err := e.client.Decrypt(ctx, &kmspb.DecryptRequest{
+		Name:             e.keyName,
+		Ciphertext:       h.wrappedKey,
+		CiphertextCrc32C: crc32c(h.wrappedKey),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCRC32C("plaintext", resp.GetPlaintext(), resp.GetPlaintextCrc32C()); err != nil {
+		return nil, err
+	}
+	if len(resp.GetPlaintext()) != dataKeySize {
+		return nil, errors.New("kmscrypto: invalid data key")
+	}
+	return resp.GetPlaintext(), nil
+}
+
+// EnvelopeKeyVersion returns the resource name of the key version that
+// wrapped the data key of data encrypted by an Envelope, given the beginning
+// of the data, which must hold the whole header. It can be used to find the
+// data that should be rewrapped after the key is rotated.
+func EnvelopeKeyVersion(ciphertext []byte) (string, error) {
+	h, err := readHeader(bytes.NewReader(ciphertext))
+	if err != nil {
+		return "", err
+	}
+	return h.keyVersion, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// errInvalidEnvelope is returned for data that was not encrypted by an
+// Envelope.
+var errInvalidEnvelope = errors.New("kmscrypto: invalid envelope header")
+
+// header is the header of encrypted data.
+type header struct {
+	chunkSize   int
+	noncePrefix []byte
+	keyVersion  string
+	wrappedKey  []byte
+}
+
+func (h *header) marshal() []byte {
+	b := make([]byte, 0, streamHeaderSize+2+len(h.keyVersion)+4+len(h.wrappedKey))
+	b = append(b, envelopeMagic...)
+	b = append(b, envelopeVersion)
+	b = binary.BigEndian.AppendUint32(b, uint32(h.chunkSize))
+	b = append(b, h.noncePrefix...)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(h.keyVersion)))
+	b = append(b, h.keyVersion...)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(h.wrappedKey)))
+	return append(b, h.wrappedKey...)
+}
+
+func readHeader(r io.Reader) (*header, error) {
+	sh := make([]byte, streamHeaderSize)
+	if _, err := io.ReadFull(r, sh); err != nil {
+		return nil, errInvalidEnvelope
+	}
+	if string(sh[:len(envelopeMagic)]) != envelopeMagic || sh[len(envelopeMagic)] != envelopeVersion {
+		return nil, errInvalidEnvelope
+	}
+	h := &header{
+		chunkSize:   int(binary.BigEndian.Uint32(sh[len(envelopeMagic)+1:])),
+		noncePrefix: sh[streamHeaderSize-noncePrefixSize:],
+	}
+	if h.chunkSize <= 0 || h.chunkSize > maxChunkSize {
+		return nil, errInvalidEnvelope
+	}
+	var n [4]byte
+	if _, err := io.ReadFull(r, n[:2]); err != nil {
+		return nil, errInvalidEnvelope
+	}
+	name := make([]byte, binary.BigEndian.Uint16(n[:2]))
+	if _, err := io.ReadFull(r, name); err != nil {
+		return nil, errInvalidEnvelope
+	}
+	h.keyVersion = string(name)
+	if _, err := io.ReadFull(r, n[:]); err != nil {
+		return nil, errInvalidEnvelope
+	}
+	// Wrapped data keys are much smaller than this.
+	if size := binary.BigEndian.Uint32(n[:]); size > 64<<10 {
+		return nil, errInvalidEnvelope
+	}
+	h.wrappedKey = make([]byte, binary.BigEndian.Uint32(n[:]))
+	if _, err := io.ReadFull(r, h.wrappedKey); err != nil {
+		return nil, errInvalidEnvelope
+	}
+	return h, nil
+}
+
+// chunkNonce returns the nonce of the chunk with the given index: the nonce
+// prefix, the index and whether the chunk is the last one.
+func chunkNonce(prefix []byte, index uint32, last bool) []byte {
+	nonce := make([]byte, 0, noncePrefixSize+5)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, index)
+	if last {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+type envelopeWriter struct {
+	w           io.Writer
+	aead        cipher.AEAD
+	chunkSize   int
+	noncePrefix []byte
+	aad         []byte // the stream header
+	buf         []byte // plaintext of the next chunk
+	index       uint32
+	err         error
+}
+
+func (w *envelopeWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n := 0
+	for len(p) > 0 {
+		// A full chunk is only written once more data arrives, since the last
+		// chunk is encrypted differently.
+		if len(w.buf) == w.chunkSize {
+			if w.err = w.writeChunk(false); w.err != nil {
+				return n, w.err
+			}
+		}
+		m := copy(w.buf[len(w.buf):w.chunkSize], p)
+		w.buf = w.buf[:len(w.buf)+m]
+		p = p[m:]
+		n += m
+	}
+	return n, nil
+}
+
+func (w *envelopeWriter) writeChunk(last bool) error {
+	if w.index == ^uint32(0) {
+		return errors.New("kmscrypto: too many chunks")
+	}
+	ct := w.aead.Seal(nil, chunkNonce(w.noncePrefix, w.index, last), w.buf, w.aad)
+	w.index++
+	w.buf = w.buf[:0]
+	_, err := w.w.Write(ct)
+	return err
+}
+
+// Close writes the last chunk.
+func (w *envelopeWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	w.err = w.writeChunk(true)
+	if w.err == nil {
+		w.err = errors.New("kmscrypto: write after Close")
+		return nil
+	}
+	return w.err
+}
+
+type envelopeReader struct {
+	r           *bufio.Reader
+	aead        cipher.AEAD
+	noncePrefix []byte
+	aad         []byte // the stream header
+	chunk       []byte // buffer for the ciphertext of a chunk
+	plain       []byte // decrypted data not returned yet
+	index       uint32
+	done        bool
+	err         error
+}
+
+func (r *envelopeReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.err = r.readChunk()
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+func (r *envelopeReader) readChunk() error {
+	n, err := io.ReadFull(r.r, r.chunk)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+	last := n < len(r.chunk)
+	if !last {
+		if _, err := r.r.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+	plain, err := r.aead.Open(r.chunk[:0], chunkNonce(r.noncePrefix, r.index, last), r.chunk[:n], r.aad)
+	if err != nil {
+		return errors.New("kmscrypto: message authentication failed; the data was modified or truncated")
+	}
+	r.index++
+	r.plain = plain
+	r.done = last
+	return nil
+}
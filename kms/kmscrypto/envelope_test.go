@@ -0,0 +1,239 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmscrypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+const symmetricKeyName = "projects/p/locations/global/keyRings/r/cryptoKeys/k"
+
+// fakeSymmetricClient encrypts with a local key per key version. The first
+// byte of its ciphertexts is the key version.
+type fakeSymmetricClient struct {
+	keys    map[byte][]byte
+	primary byte
+}
+
+func newFakeSymmetricClient() *fakeSymmetricClient {
+	f := &fakeSymmetricClient{keys: map[byte][]byte{}}
+	f.rotate()
+	return f
+}
+
+// rotate creates a new primary key version.
+func (f *fakeSymmetricClient) rotate() {
+	f.primary++
+	k := make([]byte, 32)
+	rand.Read(k)
+	f.keys[f.primary] = k
+}
+
+func (f *fakeSymmetricClient) Encrypt(_ context.Context, req *kmspb.EncryptRequest, _ ...gax.CallOption) (*kmspb.EncryptResponse, error) {
+	aead, err := newAEAD(f.keys[f.primary])
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	rand.Read(nonce)
+	ct := append([]byte{f.primary}, aead.Seal(nonce, nonce, req.Plaintext, nil)...)
+	return &kmspb.EncryptResponse{
+		Name:                    fmt.Sprintf("%s/cryptoKeyVersions/%d", req.Name, f.primary),
+		Ciphertext:              ct,
+		CiphertextCrc32C:        crc32c(ct),
+		VerifiedPlaintextCrc32C: req.PlaintextCrc32C != nil,
+	}, nil
+}
+
+func (f *fakeSymmetricClient) Decrypt(_ context.Context, req *kmspb.DecryptRequest, _ ...gax.CallOption) (*kmspb.DecryptResponse, error) {
+	aead, err := newAEAD(f.keys[req.Ciphertext[0]])
+	if err != nil {
+		return nil, err
+	}
+	ct := req.Ciphertext[1:]
+	pt, err := aead.Open(nil, ct[:aead.NonceSize()], ct[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, err
+	}
+	return &kmspb.DecryptResponse{Plaintext: pt, PlaintextCrc32C: crc32c(pt), UsedPrimary: req.Ciphertext[0] == f.primary}, nil
+}
+
+func newTestEnvelope(t *testing.T, f *fakeSymmetricClient, chunkSize int) *Envelope {
+	t.Helper()
+	e, err := NewEnvelope(f, symmetricKeyName, &EnvelopeOptions{ChunkSize: chunkSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestEnvelope(t *testing.T) {
+	ctx := context.Background()
+	const chunkSize = 16
+	e := newTestEnvelope(t, newFakeSymmetricClient(), chunkSize)
+	for _, n := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3 * chunkSize, 1000} {
+		pt := make([]byte, n)
+		rand.Read(pt)
+		ct, err := e.Encrypt(ctx, pt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := e.Decrypt(ctx, ct)
+		if err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+		if !bytes.Equal(got, pt) {
+			t.Errorf("%d bytes: got different plaintext", n)
+		}
+
+		// Streams are written in pieces of arbitrary sizes.
+		var buf bytes.Buffer
+		w, err := e.NewWriter(ctx, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for p := pt; len(p) > 0; {
+			m := len(p)
+			if m > 7 {
+				m = 7
+			}
+			if _, err := w.Write(p[:m]); err != nil {
+				t.Fatal(err)
+			}
+			p = p[m:]
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		r, err := e.NewReader(ctx, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err = io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+		if !bytes.Equal(got, pt) {
+			t.Errorf("%d bytes: got different plaintext from the stream", n)
+		}
+	}
+}
+
+func TestEnvelopeTampering(t *testing.T) {
+	ctx := context.Background()
+	const chunkSize = 16
+	e := newTestEnvelope(t, newFakeSymmetricClient(), chunkSize)
+	ct, err := e.Encrypt(ctx, bytes.Repeat([]byte("x"), 3*chunkSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := readHeader(bytes.NewReader(ct))
+	if err != nil {
+		t.Fatal(err)
+	}
+	headerSize := len(h.marshal())
+	chunk := chunkSize + 16
+	body := ct[headerSize:]
+	if len(body) != 3*chunk {
+		t.Fatalf("got %d bytes of chunks, want %d", len(body), 3*chunk)
+	}
+	join := func(parts ...[]byte) []byte {
+		var b []byte
+		b = append(b, ct[:headerSize]...)
+		for _, p := range parts {
+			b = append(b, p...)
+		}
+		return b
+	}
+	flipped := append([]byte(nil), ct...)
+	flipped[len(flipped)-1] ^= 1
+	for name, bad := range map[string][]byte{
+		"modified":        flipped,
+		"truncated":       join(body[:2*chunk]),
+		"last removed":    join(body[:chunk], body[2*chunk:]),
+		"reordered":       join(body[chunk:2*chunk], body[:chunk], body[2*chunk:]),
+		"partial chunk":   join(body[:3*chunk-1]),
+		"appended":        join(body, body[2*chunk:]),
+		"header modified": append(append([]byte(nil), ct[:4]...), append([]byte{2}, ct[5:]...)...),
+	} {
+		if _, err := e.Decrypt(ctx, bad); err == nil {
+			t.Errorf("%s: got nil, want error", name)
+		}
+	}
+}
+
+func TestEnvelopeRewrap(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeSymmetricClient()
+	e := newTestEnvelope(t, f, 0)
+	ct, err := e.Encrypt(ctx, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := EnvelopeKeyVersion(ct); err != nil || got != symmetricKeyName+"/cryptoKeyVersions/1" {
+		t.Errorf("got (%q, %v), want version 1", got, err)
+	}
+
+	f.rotate()
+	ct2, err := e.Rewrap(ctx, ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := EnvelopeKeyVersion(ct2); err != nil || got != symmetricKeyName+"/cryptoKeyVersions/2" {
+		t.Errorf("got (%q, %v), want version 2", got, err)
+	}
+	for _, c := range [][]byte{ct, ct2} {
+		pt, err := e.Decrypt(ctx, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(pt) != "hello" {
+			t.Errorf("got %q, want hello", pt)
+		}
+	}
+
+	other, err := NewEnvelope(f, symmetricKeyName+"2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Decrypt(ctx, ct); err == nil {
+		t.Error("got nil, want error for data wrapped with another key")
+	}
+}
+
+func TestEnvelopeErrors(t *testing.T) {
+	f := newFakeSymmetricClient()
+	for _, opts := range []*EnvelopeOptions{{ChunkSize: -1}, {ChunkSize: maxChunkSize + 1}} {
+		if _, err := NewEnvelope(f, symmetricKeyName, opts); err == nil {
+			t.Errorf("%+v: got nil, want error", opts)
+		}
+	}
+	if _, err := NewEnvelope(f, symmetricKeyName+"/cryptoKeyVersions/1", nil); err == nil {
+		t.Error("got nil, want error for a key version name")
+	}
+	e := newTestEnvelope(t, f, 0)
+	if _, err := e.Decrypt(context.Background(), []byte("not encrypted")); !errors.Is(err, errInvalidEnvelope) {
+		t.Errorf("got %v, want errInvalidEnvelope", err)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmscrypto_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"os"
+	"time"
+
+	kms "cloud.google.com/go/kms/apiv1"
+	"cloud.google.com/go/kms/kmscrypto"
+)
+
+func ExampleNewSigner() {
+	ctx := context.Background()
+	client, err := kms.NewKeyManagementClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	signer, err := kmscrypto.NewSigner(ctx, client, "projects/my-project/locations/global/keyRings/my-ring/cryptoKeys/my-key/cryptoKeyVersions/1")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	// Create a self-signed CA certificate whose private key never leaves
+	// Cloud KMS.
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Example CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	_ = der // TODO: Use der.
+}
+
+func ExampleEnvelope_NewWriter() {
+	ctx := context.Background()
+	client, err := kms.NewKeyManagementClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	env, err := kmscrypto.NewEnvelope(client, "projects/my-project/locations/global/keyRings/my-ring/cryptoKeys/my-key", nil)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	in, err := os.Open("backup.tar")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer in.Close()
+	out, err := os.Create("backup.tar.enc")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer out.Close()
+
+	w, err := env.NewWriter(ctx, out)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	if _, err := io.Copy(w, in); err != nil {
+		// TODO: Handle error.
+	}
+	if err := w.Close(); err != nil {
+		// TODO: Handle error.
+	}
+}
+
+func ExampleMAC_Verify() {
+	ctx := context.Background()
+	client, err := kms.NewKeyManagementClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	m := kmscrypto.NewMAC(client, "projects/my-project/locations/global/keyRings/my-ring/cryptoKeys/my-hmac-key/cryptoKeyVersions/1")
+	var data, mac []byte // TODO: Read the data and its MAC.
+	if err := m.Verify(ctx, data, mac); err != nil {
+		// TODO: Handle error. It is ErrInvalidMAC if the MAC doesn't match.
+	}
+}
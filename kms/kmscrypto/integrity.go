@@ -0,0 +1,61 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmscrypto
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ErrIntegrity is wrapped by the errors returned when a request or a response
+// is corrupted in transit. The operation can be retried.
+var ErrIntegrity = errors.New("kmscrypto: data integrity check failed")
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// crc32c returns the CRC32C checksum of b, as sent in requests.
+func crc32c(b []byte) *wrapperspb.Int64Value {
+	return wrapperspb.Int64(int64(crc32.Checksum(b, crc32cTable)))
+}
+
+// checkCRC32C returns an error if sum is missing or is not the checksum of
+// the field of a response with the given name and contents.
+func checkCRC32C(field string, b []byte, sum *wrapperspb.Int64Value) error {
+	if sum == nil || sum.GetValue() != int64(crc32.Checksum(b, crc32cTable)) {
+		return fmt.Errorf("%w: response %s checksum mismatch", ErrIntegrity, field)
+	}
+	return nil
+}
+
+// checkVerified returns an error if the service did not verify the checksum
+// of the request field with the given name.
+func checkVerified(field string, verified bool) error {
+	if !verified {
+		return fmt.Errorf("%w: request %s checksum not verified", ErrIntegrity, field)
+	}
+	return nil
+}
+
+// checkName returns an error if the key name in a response is not the one
+// of the request.
+func checkName(got, want string) error {
+	if got != want {
+		return fmt.Errorf("%w: got response for key %q, want %q", ErrIntegrity, got, want)
+	}
+	return nil
+}
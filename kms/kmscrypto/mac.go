@@ -0,0 +1,104 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmscrypto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+// MACClient is the part of the Cloud KMS client used by MAC. It is
+// implemented by *kms.KeyManagementClient.
+type MACClient interface {
+	MacSign(ctx context.Context, req *kmspb.MacSignRequest, opts ...gax.CallOption) (*kmspb.MacSignResponse, error)
+	MacVerify(ctx context.Context, req *kmspb.MacVerifyRequest, opts ...gax.CallOption) (*kmspb.MacVerifyResponse, error)
+}
+
+// ErrInvalidMAC is returned by MAC.Verify when the MAC doesn't match the data.
+var ErrInvalidMAC = errors.New("kmscrypto: invalid MAC")
+
+// MAC computes and verifies HMACs with a MAC signing key version in Cloud KMS.
+// A MAC is safe for concurrent use.
+type MAC struct {
+	client MACClient
+	name   string
+}
+
+// NewMAC returns a MAC for the key version with the given resource name, in
+// the format
+// projects/*/locations/*/keyRings/*/cryptoKeys/*/cryptoKeyVersions/*. The key
+// version must have an HMAC algorithm.
+func NewMAC(client MACClient, name string) *MAC {
+	return &MAC{client: client, name: name}
+}
+
+// Sign returns the MAC of data.
+func (m *MAC) Sign(ctx context.Context, data []byte) ([]byte, error) {
+	resp, err := m.client.MacSign(ctx, &kmspb.MacSignRequest{
+		Name:       m.name,
+		Data:       data,
+		DataCrc32C: crc32c(data),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkName(resp.GetName(), m.name); err != nil {
+		return nil, err
+	}
+	if err := checkVerified("data", resp.GetVerifiedDataCrc32C()); err != nil {
+		return nil, err
+	}
+	if err := checkCRC32C("MAC", resp.GetMac(), resp.GetMacCrc32C()); err != nil {
+		return nil, err
+	}
+	return resp.GetMac(), nil
+}
+
+// Verify returns nil if mac is the MAC of data, and ErrInvalidMAC if it is
+// not. The comparison is done by Cloud KMS.
+func (m *MAC) Verify(ctx context.Context, data, mac []byte) error {
+	resp, err := m.client.MacVerify(ctx, &kmspb.MacVerifyRequest{
+		Name:       m.name,
+		Data:       data,
+		DataCrc32C: crc32c(data),
+		Mac:        mac,
+		MacCrc32C:  crc32c(mac),
+	})
+	if err != nil {
+		return err
+	}
+	if err := checkName(resp.GetName(), m.name); err != nil {
+		return err
+	}
+	if err := checkVerified("data", resp.GetVerifiedDataCrc32C()); err != nil {
+		return err
+	}
+	if err := checkVerified("MAC", resp.GetVerifiedMacCrc32C()); err != nil {
+		return err
+	}
+	// VerifiedSuccessIntegrity is a copy of Success that detects a corrupted
+	// response.
+	if resp.GetVerifiedSuccessIntegrity() != resp.GetSuccess() {
+		return fmt.Errorf("%w: response success field corrupted", ErrIntegrity)
+	}
+	if !resp.GetSuccess() {
+		return ErrInvalidMAC
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmscrypto
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+const macKeyName = "projects/p/locations/global/keyRings/r/cryptoKeys/mac/cryptoKeyVersions/1"
+
+// fakeMACClient computes HMAC-SHA256 with a local key. If corrupt is set, it
+// returns responses whose checksums don't match.
+type fakeMACClient struct {
+	key     []byte
+	corrupt bool
+}
+
+func (f *fakeMACClient) mac(data []byte) []byte {
+	h := hmac.New(sha256.New, f.key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func (f *fakeMACClient) MacSign(_ context.Context, req *kmspb.MacSignRequest, _ ...gax.CallOption) (*kmspb.MacSignResponse, error) {
+	mac := f.mac(req.Data)
+	resp := &kmspb.MacSignResponse{Name: req.Name, Mac: mac, MacCrc32C: crc32c(mac), VerifiedDataCrc32C: true}
+	if f.corrupt {
+		resp.Mac = append([]byte{1}, mac...)
+	}
+	return resp, nil
+}
+
+func (f *fakeMACClient) MacVerify(_ context.Context, req *kmspb.MacVerifyRequest, _ ...gax.CallOption) (*kmspb.MacVerifyResponse, error) {
+	ok := hmac.Equal(f.mac(req.Data), req.Mac)
+	return &kmspb.MacVerifyResponse{
+		Name:                     req.Name,
+		Success:                  ok,
+		VerifiedDataCrc32C:       true,
+		VerifiedMacCrc32C:        true,
+		VerifiedSuccessIntegrity: ok != f.corrupt,
+	}, nil
+}
+
+func TestMAC(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeMACClient{key: []byte("key")}
+	m := NewMAC(f, macKeyName)
+	mac, err := m.Sign(ctx, []byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Verify(ctx, []byte("data"), mac); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if err := m.Verify(ctx, []byte("other data"), mac); !errors.Is(err, ErrInvalidMAC) {
+		t.Errorf("got %v, want ErrInvalidMAC", err)
+	}
+
+	f.corrupt = true
+	if _, err := m.Sign(ctx, []byte("data")); !errors.Is(err, ErrIntegrity) {
+		t.Errorf("Sign: got %v, want ErrIntegrity", err)
+	}
+	if err := m.Verify(ctx, []byte("data"), mac); !errors.Is(err, ErrIntegrity) {
+		t.Errorf("Verify: got %v, want ErrIntegrity", err)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmscrypto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+// RawClient is the part of the Cloud KMS client used by RawCipher. It is
+// implemented by *kms.KeyManagementClient.
+type RawClient interface {
+	RawEncrypt(ctx context.Context, req *kmspb.RawEncryptRequest, opts ...gax.CallOption) (*kmspb.RawEncryptResponse, error)
+	RawDecrypt(ctx context.Context, req *kmspb.RawDecryptRequest, opts ...gax.CallOption) (*kmspb.RawDecryptResponse, error)
+}
+
+// RawCipher encrypts and decrypts with a raw encryption key version in Cloud
+// KMS, whose algorithm is AES-GCM, AES-CBC or AES-CTR. Unlike Envelope, which
+// uses keys managed by Cloud KMS, raw encryption produces ciphertexts that can
+// be decrypted with the imported key material outside of Cloud KMS. A
+// RawCipher is safe for concurrent use.
+type RawCipher struct {
+	client RawClient
+	name   string
+}
+
+// NewRawCipher returns a RawCipher for the key version with the given
+// resource name, in the format
+// projects/*/locations/*/keyRings/*/cryptoKeys/*/cryptoKeyVersions/*.
+func NewRawCipher(client RawClient, name string) *RawCipher {
+	return &RawCipher{client: client, name: name}
+}
+
+// A RawCiphertext is the result of raw encryption. The initialization vector
+// and, for AES-GCM, the length of the authentication tag are needed to
+// decrypt it.
+type RawCiphertext struct {
+	// Ciphertext is the encrypted data. For AES-GCM, it ends with the
+	// authentication tag.
+	Ciphertext []byte
+
+	// IV is the initialization vector.
+	IV []byte
+
+	// TagLength is the length in bytes of the AES-GCM authentication tag,
+	// or zero for the other algorithms.
+	TagLength int
+}
+
+var errInvalidRawCiphertext = errors.New("kmscrypto: invalid raw ciphertext")
+
+// MarshalBinary encodes c as a byte slice holding the IV length, the tag
+// length, the IV and the ciphertext, so that it can be stored or sent as a
+// single value.
+func (c *RawCiphertext) MarshalBinary() ([]byte, error) {
+	if len(c.IV) > 255 || c.TagLength < 0 || c.TagLength > 255 {
+		return nil, errInvalidRawCiphertext
+	}
+	b := make([]byte, 0, 2+len(c.IV)+len(c.Ciphertext))
+	b = append(b, byte(len(c.IV)), byte(c.TagLength))
+	b = append(b, c.IV...)
+	return append(b, c.Ciphertext...), nil
+}
+
+// UnmarshalBinary decodes data encoded by MarshalBinary into c.
+func (c *RawCiphertext) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 || len(data) < 2+int(data[0]) {
+		return errInvalidRawCiphertext
+	}
+	n := 2 + int(data[0])
+	*c = RawCiphertext{
+		IV:         append([]byte(nil), data[2:n]...),
+		TagLength:  int(data[1]),
+		Ciphertext: append([]byte(nil), data[n:]...),
+	}
+	return nil
+}
+
+// Encrypt encrypts plaintext with an initialization vector generated by Cloud
+// KMS, which is returned in the ciphertext. aad is the additional
+// authenticated data for AES-GCM, and must be nil for the other algorithms.
+func (r *RawCipher) Encrypt(ctx context.Context, plaintext, aad []byte) (*RawCiphertext, error) {
+	return r.EncryptWithIV(ctx, plaintext, aad, nil)
+}
+
+// EncryptWithIV is like Encrypt with the given initialization vector, which
+// can only be set for AES-CBC and AES-CTR. An IV must never be reused with
+// the same key: prefer Encrypt unless the IV is dictated by a protocol.
+func (r *RawCipher) EncryptWithIV(ctx context.Context, plaintext, aad, iv []byte) (*RawCiphertext, error) {
+	req := &kmspb.RawEncryptRequest{
+		Name:            r.name,
+		Plaintext:       plaintext,
+		PlaintextCrc32C: crc32c(plaintext),
+	}
+	if aad != nil {
+		req.AdditionalAuthenticatedData = aad
+		req.AdditionalAuthenticatedDataCrc32C = crc32c(aad)
+	}
+	if iv != nil {
+		req.InitializationVector = iv
+		req.InitializationVectorCrc32C = crc32c(iv)
+	}
+	resp, err := r.client.RawEncrypt(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkName(resp.GetName(), r.name); err != nil {
+		return nil, err
+	}
+	if err := checkVerified("plaintext", resp.GetVerifiedPlaintextCrc32C()); err != nil {
+		return nil, err
+	}
+	if aad != nil {
+		if err := checkVerified("additional authenticated data", resp.GetVerifiedAdditionalAuthenticatedDataCrc32C()); err != nil {
+			return nil, err
+		}
+	}
+	if iv != nil {
+		if err := checkVerified("initialization vector", resp.GetVerifiedInitializationVectorCrc32C()); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkCRC32C("ciphertext", resp.GetCiphertext(), resp.GetCiphertextCrc32C()); err != nil {
+		return nil, err
+	}
+	if err := checkCRC32C("initialization vector", resp.GetInitializationVector(), resp.GetInitializationVectorCrc32C()); err != nil {
+		return nil, err
+	}
+	return &RawCiphertext{
+		Ciphertext: resp.GetCiphertext(),
+		IV:         resp.GetInitializationVector(),
+		TagLength:  int(resp.GetTagLength()),
+	}, nil
+}
+
+// Decrypt decrypts ct, which was encrypted with the key version, with the
+// same additional authenticated data.
+func (r *RawCipher) Decrypt(ctx context.Context, ct *RawCiphertext, aad []byte) ([]byte, error) {
+	if len(ct.IV) == 0 {
+		return nil, fmt.Errorf("%w: missing initialization vector", errInvalidRawCiphertext)
+	}
+	req := &kmspb.RawDecryptRequest{
+		Name:                       r.name,
+		Ciphertext:                 ct.Ciphertext,
+		CiphertextCrc32C:           crc32c(ct.Ciphertext),
+		InitializationVector:       ct.IV,
+		InitializationVectorCrc32C: crc32c(ct.IV),
+		TagLength:                  int32(ct.TagLength),
+	}
+	if aad != nil {
+		req.AdditionalAuthenticatedData = aad
+		req.AdditionalAuthenticatedDataCrc32C = crc32c(aad)
+	}
+	resp, err := r.client.RawDecrypt(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVerified("ciphertext", resp.GetVerifiedCiphertextCrc32C()); err != nil {
+		return nil, err
+	}
+	if err := checkVerified("initialization vector", resp.GetVerifiedInitializationVectorCrc32C()); err != nil {
+		return nil, err
+	}
+	if aad != nil {
+		if err := checkVerified("additional authenticated data", resp.GetVerifiedAdditionalAuthenticatedDataCrc32C()); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkCRC32C("plaintext", resp.GetPlaintext(), resp.GetPlaintextCrc32C()); err != nil {
+		return nil, err
+	}
+	return resp.GetPlaintext(), nil
+}
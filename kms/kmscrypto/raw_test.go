@@ -0,0 +1,127 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmscrypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+const rawKeyName = "projects/p/locations/global/keyRings/r/cryptoKeys/raw/cryptoKeyVersions/1"
+
+// fakeRawClient encrypts with AES-CTR and a local key. If dropChecksum is set,
+// it doesn't verify the checksums of requests.
+type fakeRawClient struct {
+	key          []byte
+	dropChecksum bool
+}
+
+func (f *fakeRawClient) ctr(iv, in []byte) []byte {
+	b, err := aes.NewCipher(f.key)
+	if err != nil {
+		panic(err)
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(b, iv).XORKeyStream(out, in)
+	return out
+}
+
+func (f *fakeRawClient) RawEncrypt(_ context.Context, req *kmspb.RawEncryptRequest, _ ...gax.CallOption) (*kmspb.RawEncryptResponse, error) {
+	iv := req.InitializationVector
+	if iv == nil {
+		iv = make([]byte, aes.BlockSize)
+		rand.Read(iv)
+	}
+	ct := f.ctr(iv, req.Plaintext)
+	return &kmspb.RawEncryptResponse{
+		Name:                               req.Name,
+		Ciphertext:                         ct,
+		CiphertextCrc32C:                   crc32c(ct),
+		InitializationVector:               iv,
+		InitializationVectorCrc32C:         crc32c(iv),
+		VerifiedPlaintextCrc32C:            !f.dropChecksum,
+		VerifiedInitializationVectorCrc32C: req.InitializationVectorCrc32C != nil,
+	}, nil
+}
+
+func (f *fakeRawClient) RawDecrypt(_ context.Context, req *kmspb.RawDecryptRequest, _ ...gax.CallOption) (*kmspb.RawDecryptResponse, error) {
+	pt := f.ctr(req.InitializationVector, req.Ciphertext)
+	return &kmspb.RawDecryptResponse{
+		Plaintext:                          pt,
+		PlaintextCrc32C:                    crc32c(pt),
+		VerifiedCiphertextCrc32C:           !f.dropChecksum,
+		VerifiedInitializationVectorCrc32C: true,
+	}, nil
+}
+
+func TestRawCipher(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeRawClient{key: make([]byte, 32)}
+	rand.Read(f.key)
+	r := NewRawCipher(f, rawKeyName)
+	pt := []byte("raw plaintext")
+	ct, err := r.Encrypt(ctx, pt, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ct.IV) != aes.BlockSize {
+		t.Errorf("got IV of %d bytes, want %d", len(ct.IV), aes.BlockSize)
+	}
+
+	// The ciphertext survives encoding.
+	b, err := ct.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded RawCiphertext
+	if err := decoded.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.Decrypt(ctx, &decoded, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, pt) {
+		t.Errorf("got %q, want %q", got, pt)
+	}
+
+	iv := bytes.Repeat([]byte{7}, aes.BlockSize)
+	ct, err = r.EncryptWithIV(ctx, pt, nil, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ct.IV, iv) {
+		t.Errorf("got IV %x, want %x", ct.IV, iv)
+	}
+
+	f.dropChecksum = true
+	if _, err := r.Encrypt(ctx, pt, nil); !errors.Is(err, ErrIntegrity) {
+		t.Errorf("Encrypt: got %v, want ErrIntegrity", err)
+	}
+	if _, err := r.Decrypt(ctx, ct, nil); !errors.Is(err, ErrIntegrity) {
+		t.Errorf("Decrypt: got %v, want ErrIntegrity", err)
+	}
+	if err := decoded.UnmarshalBinary([]byte{16, 0, 1}); err == nil {
+		t.Error("UnmarshalBinary: got nil, want error for a truncated IV")
+	}
+}
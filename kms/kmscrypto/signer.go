@@ -0,0 +1,306 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmscrypto
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+// AsymmetricClient is the part of the Cloud KMS client used by Signer and
+// Decrypter. It is implemented by *kms.KeyManagementClient.
+type AsymmetricClient interface {
+	GetPublicKey(ctx context.Context, req *kmspb.GetPublicKeyRequest, opts ...gax.CallOption) (*kmspb.PublicKey, error)
+	AsymmetricSign(ctx context.Context, req *kmspb.AsymmetricSignRequest, opts ...gax.CallOption) (*kmspb.AsymmetricSignResponse, error)
+	AsymmetricDecrypt(ctx context.Context, req *kmspb.AsymmetricDecryptRequest, opts ...gax.CallOption) (*kmspb.AsymmetricDecryptResponse, error)
+}
+
+type algorithmKind int
+
+const (
+	signPKCS1 algorithmKind = iota + 1
+	signPSS
+	signRawPKCS1 // signs data that is already hashed and prefixed
+	signECDSA
+	signEd25519 // signs the data, not a digest
+	decryptOAEP
+)
+
+// algorithm describes an asymmetric key algorithm.
+type algorithm struct {
+	kind algorithmKind
+	hash crypto.Hash // zero for signRawPKCS1 and signEd25519
+}
+
+// algorithms are the supported algorithms. EC_SIGN_SECP256K1_SHA256 isn't
+// one of them, because crypto/x509 cannot parse secp256k1 public keys.
+var algorithms = map[kmspb.CryptoKeyVersion_CryptoKeyVersionAlgorithm]algorithm{
+	kmspb.CryptoKeyVersion_RSA_SIGN_PSS_2048_SHA256:     {signPSS, crypto.SHA256},
+	kmspb.CryptoKeyVersion_RSA_SIGN_PSS_3072_SHA256:     {signPSS, crypto.SHA256},
+	kmspb.CryptoKeyVersion_RSA_SIGN_PSS_4096_SHA256:     {signPSS, crypto.SHA256},
+	kmspb.CryptoKeyVersion_RSA_SIGN_PSS_4096_SHA512:     {signPSS, crypto.SHA512},
+	kmspb.CryptoKeyVersion_RSA_SIGN_PKCS1_2048_SHA256:   {signPKCS1, crypto.SHA256},
+	kmspb.CryptoKeyVersion_RSA_SIGN_PKCS1_3072_SHA256:   {signPKCS1, crypto.SHA256},
+	kmspb.CryptoKeyVersion_RSA_SIGN_PKCS1_4096_SHA256:   {signPKCS1, crypto.SHA256},
+	kmspb.CryptoKeyVersion_RSA_SIGN_PKCS1_4096_SHA512:   {signPKCS1, crypto.SHA512},
+	kmspb.CryptoKeyVersion_RSA_SIGN_RAW_PKCS1_2048:      {signRawPKCS1, 0},
+	kmspb.CryptoKeyVersion_RSA_SIGN_RAW_PKCS1_3072:      {signRawPKCS1, 0},
+	kmspb.CryptoKeyVersion_RSA_SIGN_RAW_PKCS1_4096:      {signRawPKCS1, 0},
+	kmspb.CryptoKeyVersion_EC_SIGN_P256_SHA256:          {signECDSA, crypto.SHA256},
+	kmspb.CryptoKeyVersion_EC_SIGN_P384_SHA384:          {signECDSA, crypto.SHA384},
+	kmspb.CryptoKeyVersion_EC_SIGN_ED25519:              {signEd25519, 0},
+	kmspb.CryptoKeyVersion_RSA_DECRYPT_OAEP_2048_SHA256: {decryptOAEP, crypto.SHA256},
+	kmspb.CryptoKeyVersion_RSA_DECRYPT_OAEP_3072_SHA256: {decryptOAEP, crypto.SHA256},
+	kmspb.CryptoKeyVersion_RSA_DECRYPT_OAEP_4096_SHA256: {decryptOAEP, crypto.SHA256},
+	kmspb.CryptoKeyVersion_RSA_DECRYPT_OAEP_4096_SHA512: {decryptOAEP, crypto.SHA512},
+	kmspb.CryptoKeyVersion_RSA_DECRYPT_OAEP_2048_SHA1:   {decryptOAEP, crypto.SHA1},
+	kmspb.CryptoKeyVersion_RSA_DECRYPT_OAEP_3072_SHA1:   {decryptOAEP, crypto.SHA1},
+	kmspb.CryptoKeyVersion_RSA_DECRYPT_OAEP_4096_SHA1:   {decryptOAEP, crypto.SHA1},
+}
+
+// asymmetricKey is a key version whose public key was fetched.
+type asymmetricKey struct {
+	client AsymmetricClient
+	name   string
+	alg    algorithm
+	pub    crypto.PublicKey
+}
+
+// newAsymmetricKey fetches the public key of the named key version, and
+// checks that the algorithm of the key version is one of the given kinds.
+func newAsymmetricKey(ctx context.Context, client AsymmetricClient, name string, kinds ...algorithmKind) (*asymmetricKey, error) {
+	pk, err := client.GetPublicKey(ctx, &kmspb.GetPublicKeyRequest{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkName(pk.GetName(), name); err != nil {
+		return nil, err
+	}
+	if err := checkCRC32C("PEM", []byte(pk.GetPem()), pk.GetPemCrc32C()); err != nil {
+		return nil, err
+	}
+	alg, ok := algorithms[pk.GetAlgorithm()]
+	if !ok || !containsKind(kinds, alg.kind) {
+		return nil, fmt.Errorf("kmscrypto: unsupported algorithm %v for key %s", pk.GetAlgorithm(), name)
+	}
+	block, _ := pem.Decode([]byte(pk.GetPem()))
+	if block == nil {
+		return nil, fmt.Errorf("kmscrypto: invalid PEM public key for key %s", name)
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("kmscrypto: parsing public key of key %s: %w", name, err)
+	}
+	return &asymmetricKey{client: client, name: name, alg: alg, pub: pub}, nil
+}
+
+func containsKind(kinds []algorithmKind, k algorithmKind) bool {
+	for _, kind := range kinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Signer is a crypto.Signer backed by an asymmetric signing key version in
+// Cloud KMS. The public key is fetched once, by NewSigner. A Signer is safe
+// for concurrent use.
+type Signer struct {
+	key *asymmetricKey
+}
+
+// NewSigner returns a Signer for the key version with the given resource
+// name, in the format
+// projects/*/locations/*/keyRings/*/cryptoKeys/*/cryptoKeyVersions/*. The key
+// version must have an RSA, ECDSA or Ed25519 signing algorithm; the
+// secp256k1 curve is not supported.
+func NewSigner(ctx context.Context, client AsymmetricClient, name string) (*Signer, error) {
+	k, err := newAsymmetricKey(ctx, client, name, signPKCS1, signPSS, signRawPKCS1, signECDSA, signEd25519)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{key: k}, nil
+}
+
+// Public returns the public key of the key version, which is an
+// *rsa.PublicKey, an *ecdsa.PublicKey or an ed25519.PublicKey.
+func (s *Signer) Public() crypto.PublicKey {
+	return s.key.pub
+}
+
+// Sign is like SignContext with a background context. It implements
+// crypto.Signer. rand is not used.
+func (s *Signer) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return s.SignContext(context.Background(), digest, opts)
+}
+
+// SignContext signs digest with the key version.
+//
+// opts.HashFunc() must be the hash function of the key version's algorithm,
+// and digest must be the hash of the message, except for Ed25519 keys, where
+// digest is the message and opts.HashFunc() must be zero, and for raw
+// PKCS#1 keys, where digest is signed as is. RSA-PSS keys require an
+// *rsa.PSSOptions, with a salt length equal to the length of the hash.
+func (s *Signer) SignContext(ctx context.Context, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	req := &kmspb.AsymmetricSignRequest{Name: s.key.name}
+	if err := s.checkOpts(opts); err != nil {
+		return nil, err
+	}
+	switch s.key.alg.kind {
+	case signEd25519, signRawPKCS1:
+		req.Data = digest
+		req.DataCrc32C = crc32c(digest)
+	default:
+		d, err := digestProto(s.key.alg.hash, digest)
+		if err != nil {
+			return nil, err
+		}
+		req.Digest = d
+		req.DigestCrc32C = crc32c(digest)
+	}
+	resp, err := s.key.client.AsymmetricSign(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkName(resp.GetName(), s.key.name); err != nil {
+		return nil, err
+	}
+	if req.Data != nil {
+		err = checkVerified("data", resp.GetVerifiedDataCrc32C())
+	} else {
+		err = checkVerified("digest", resp.GetVerifiedDigestCrc32C())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCRC32C("signature", resp.GetSignature(), resp.GetSignatureCrc32C()); err != nil {
+		return nil, err
+	}
+	return resp.GetSignature(), nil
+}
+
+// checkOpts returns an error if opts don't match the key's algorithm.
+func (s *Signer) checkOpts(opts crypto.SignerOpts) error {
+	alg := s.key.alg
+	var h crypto.Hash
+	if opts != nil {
+		h = opts.HashFunc()
+	}
+	pss, isPSS := opts.(*rsa.PSSOptions)
+	switch {
+	case alg.kind == signRawPKCS1:
+		return nil
+	case h != alg.hash:
+		return fmt.Errorf("kmscrypto: got hash %v, want %v for key %s", h, alg.hash, s.key.name)
+	case alg.kind == signPSS && !isPSS:
+		return fmt.Errorf("kmscrypto: RSA-PSS key %s requires *rsa.PSSOptions", s.key.name)
+	case alg.kind != signPSS && isPSS:
+		return fmt.Errorf("kmscrypto: key %s does not use RSA-PSS", s.key.name)
+	case isPSS && pss.SaltLength != rsa.PSSSaltLengthEqualsHash && pss.SaltLength != h.Size():
+		return errors.New("kmscrypto: the salt length of RSA-PSS signatures must be the length of the hash")
+	}
+	return nil
+}
+
+// digestProto returns the Digest message for a digest computed with h.
+func digestProto(h crypto.Hash, digest []byte) (*kmspb.Digest, error) {
+	if len(digest) != h.Size() {
+		return nil, fmt.Errorf("kmscrypto: got digest of %d bytes, want %d for %v", len(digest), h.Size(), h)
+	}
+	switch h {
+	case crypto.SHA256:
+		return &kmspb.Digest{Digest: &kmspb.Digest_Sha256{Sha256: digest}}, nil
+	case crypto.SHA384:
+		return &kmspb.Digest{Digest: &kmspb.Digest_Sha384{Sha384: digest}}, nil
+	case crypto.SHA512:
+		return &kmspb.Digest{Digest: &kmspb.Digest_Sha512{Sha512: digest}}, nil
+	}
+	return nil, fmt.Errorf("kmscrypto: unsupported hash %v", h)
+}
+
+// Decrypter is a crypto.Decrypter backed by an asymmetric RSA-OAEP
+// decryption key version in Cloud KMS. The public key is fetched once, by
+// NewDecrypter. A Decrypter is safe for concurrent use.
+type Decrypter struct {
+	key *asymmetricKey
+}
+
+// NewDecrypter returns a Decrypter for the key version with the given
+// resource name, in the format
+// projects/*/locations/*/keyRings/*/cryptoKeys/*/cryptoKeyVersions/*. The key
+// version must have an RSA decryption algorithm.
+func NewDecrypter(ctx context.Context, client AsymmetricClient, name string) (*Decrypter, error) {
+	k, err := newAsymmetricKey(ctx, client, name, decryptOAEP)
+	if err != nil {
+		return nil, err
+	}
+	return &Decrypter{key: k}, nil
+}
+
+// Public returns the public key of the key version, which is an
+// *rsa.PublicKey. Data encrypted with rsa.EncryptOAEP, using the hash of the
+// key version's algorithm and no label, can be decrypted by the Decrypter.
+func (d *Decrypter) Public() crypto.PublicKey {
+	return d.key.pub
+}
+
+// Decrypt is like DecryptContext with a background context. It implements
+// crypto.Decrypter. rand is not used.
+func (d *Decrypter) Decrypt(_ io.Reader, ciphertext []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	return d.DecryptContext(context.Background(), ciphertext, opts)
+}
+
+// DecryptContext decrypts ciphertext with the key version. opts may be nil,
+// or an *rsa.OAEPOptions with the hash of the key version's algorithm and no
+// label.
+func (d *Decrypter) DecryptContext(ctx context.Context, ciphertext []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	switch o := opts.(type) {
+	case nil:
+	case *rsa.OAEPOptions:
+		if o.Hash != d.key.alg.hash {
+			return nil, fmt.Errorf("kmscrypto: got hash %v, want %v for key %s", o.Hash, d.key.alg.hash, d.key.name)
+		}
+		if len(o.Label) > 0 {
+			return nil, errors.New("kmscrypto: Cloud KMS does not support OAEP labels")
+		}
+	default:
+		return nil, fmt.Errorf("kmscrypto: unsupported decrypter options %T", opts)
+	}
+	resp, err := d.key.client.AsymmetricDecrypt(ctx, &kmspb.AsymmetricDecryptRequest{
+		Name:             d.key.name,
+		Ciphertext:       ciphertext,
+		CiphertextCrc32C: crc32c(ciphertext),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVerified("ciphertext", resp.GetVerifiedCiphertextCrc32C()); err != nil {
+		return nil, err
+	}
+	if err := checkCRC32C("plaintext", resp.GetPlaintext(), resp.GetPlaintextCrc32C()); err != nil {
+		return nil, err
+	}
+	return resp.GetPlaintext(), nil
+}
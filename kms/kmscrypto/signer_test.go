@@ -0,0 +1,238 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmscrypto
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+const keyName = "projects/p/locations/global/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1"
+
+// fakeAsymmetricClient implements the asymmetric operations with a local
+// private key.
+type fakeAsymmetricClient struct {
+	alg     kmspb.CryptoKeyVersion_CryptoKeyVersionAlgorithm
+	priv    crypto.Signer
+	corrupt bool // corrupt the responses
+}
+
+func (f *fakeAsymmetricClient) GetPublicKey(_ context.Context, req *kmspb.GetPublicKeyRequest, _ ...gax.CallOption) (*kmspb.PublicKey, error) {
+	der, err := x509.MarshalPKIXPublicKey(f.priv.Public())
+	if err != nil {
+		return nil, err
+	}
+	p := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	return &kmspb.PublicKey{Name: req.Name, Pem: p, PemCrc32C: crc32c([]byte(p)), Algorithm: f.alg}, nil
+}
+
+func (f *fakeAsymmetricClient) AsymmetricSign(_ context.Context, req *kmspb.AsymmetricSignRequest, _ ...gax.CallOption) (*kmspb.AsymmetricSignResponse, error) {
+	alg := algorithms[f.alg]
+	var (
+		sig []byte
+		err error
+	)
+	switch alg.kind {
+	case signEd25519:
+		sig, err = f.priv.Sign(rand.Reader, req.Data, crypto.Hash(0))
+	case signPSS:
+		sig, err = f.priv.Sign(rand.Reader, req.Digest.GetSha256(), &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: alg.hash})
+	default:
+		sig, err = f.priv.Sign(rand.Reader, req.Digest.GetSha256(), alg.hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp := &kmspb.AsymmetricSignResponse{
+		Name:                 req.Name,
+		Signature:            sig,
+		SignatureCrc32C:      crc32c(sig),
+		VerifiedDigestCrc32C: req.DigestCrc32C != nil,
+		VerifiedDataCrc32C:   req.DataCrc32C != nil,
+	}
+	if f.corrupt {
+		resp.Signature = append([]byte{0}, sig...)
+	}
+	return resp, nil
+}
+
+func (f *fakeAsymmetricClient) AsymmetricDecrypt(_ context.Context, req *kmspb.AsymmetricDecryptRequest, _ ...gax.CallOption) (*kmspb.AsymmetricDecryptResponse, error) {
+	pt, err := rsa.DecryptOAEP(sha256.New(), nil, f.priv.(*rsa.PrivateKey), req.Ciphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &kmspb.AsymmetricDecryptResponse{
+		Plaintext:                pt,
+		PlaintextCrc32C:          crc32c(pt),
+		VerifiedCiphertextCrc32C: !f.corrupt,
+	}, nil
+}
+
+func TestSigner(t *testing.T) {
+	ctx := context.Background()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("hello")
+	digest := sha256.Sum256(msg)
+	pss := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+
+	for _, test := range []struct {
+		alg    kmspb.CryptoKeyVersion_CryptoKeyVersionAlgorithm
+		priv   crypto.Signer
+		data   []byte
+		opts   crypto.SignerOpts
+		verify func(pub crypto.PublicKey, sig []byte) bool
+	}{
+		{
+			kmspb.CryptoKeyVersion_RSA_SIGN_PKCS1_2048_SHA256, rsaKey, digest[:], crypto.SHA256,
+			func(pub crypto.PublicKey, sig []byte) bool {
+				return rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), crypto.SHA256, digest[:], sig) == nil
+			},
+		},
+		{
+			kmspb.CryptoKeyVersion_RSA_SIGN_PSS_2048_SHA256, rsaKey, digest[:], pss,
+			func(pub crypto.PublicKey, sig []byte) bool {
+				return rsa.VerifyPSS(pub.(*rsa.PublicKey), crypto.SHA256, digest[:], sig, pss) == nil
+			},
+		},
+		{
+			kmspb.CryptoKeyVersion_EC_SIGN_P256_SHA256, ecKey, digest[:], crypto.SHA256,
+			func(pub crypto.PublicKey, sig []byte) bool {
+				return ecdsa.VerifyASN1(pub.(*ecdsa.PublicKey), digest[:], sig)
+			},
+		},
+		{
+			kmspb.CryptoKeyVersion_EC_SIGN_ED25519, edKey, msg, crypto.Hash(0),
+			func(pub crypto.PublicKey, sig []byte) bool {
+				return ed25519.Verify(pub.(ed25519.PublicKey), msg, sig)
+			},
+		},
+	} {
+		s, err := NewSigner(ctx, &fakeAsymmetricClient{alg: test.alg, priv: test.priv}, keyName)
+		if err != nil {
+			t.Fatalf("%v: %v", test.alg, err)
+		}
+		var _ crypto.Signer = s
+		sig, err := s.Sign(rand.Reader, test.data, test.opts)
+		if err != nil {
+			t.Fatalf("%v: %v", test.alg, err)
+		}
+		if !test.verify(s.Public(), sig) {
+			t.Errorf("%v: signature does not verify", test.alg)
+		}
+	}
+}
+
+func TestSignerErrors(t *testing.T) {
+	ctx := context.Background()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte("hello"))
+
+	f := &fakeAsymmetricClient{alg: kmspb.CryptoKeyVersion_RSA_SIGN_PSS_2048_SHA256, priv: rsaKey}
+	s, err := NewSigner(ctx, f, keyName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, opts := range []crypto.SignerOpts{
+		crypto.SHA256, // not PSS
+		&rsa.PSSOptions{SaltLength: 10, Hash: crypto.SHA256},
+		&rsa.PSSOptions{Hash: crypto.SHA512},
+	} {
+		if _, err := s.Sign(nil, digest[:], opts); err == nil {
+			t.Errorf("%v: got nil, want error", opts)
+		}
+	}
+
+	f.corrupt = true
+	_, err = s.Sign(nil, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256})
+	if !errors.Is(err, ErrIntegrity) {
+		t.Errorf("got %v, want ErrIntegrity", err)
+	}
+
+	f.alg = kmspb.CryptoKeyVersion_RSA_DECRYPT_OAEP_2048_SHA256
+	if _, err := NewSigner(ctx, f, keyName); err == nil {
+		t.Error("got nil, want error for a decryption key")
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f = &fakeAsymmetricClient{alg: kmspb.CryptoKeyVersion_EC_SIGN_SECP256K1_SHA256, priv: ecKey}
+	if _, err := NewSigner(ctx, f, keyName); err == nil || !strings.Contains(err.Error(), "unsupported algorithm") {
+		t.Errorf("got %v, want an unsupported algorithm error for secp256k1", err)
+	}
+}
+
+func TestDecrypter(t *testing.T) {
+	ctx := context.Background()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeAsymmetricClient{alg: kmspb.CryptoKeyVersion_RSA_DECRYPT_OAEP_2048_SHA256, priv: rsaKey}
+	d, err := NewDecrypter(ctx, f, keyName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var _ crypto.Decrypter = d
+	ct, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, d.Public().(*rsa.PublicKey), []byte("secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, opts := range []crypto.DecrypterOpts{nil, &rsa.OAEPOptions{Hash: crypto.SHA256}} {
+		pt, err := d.Decrypt(nil, ct, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := string(pt), "secret"; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+	if _, err := d.Decrypt(nil, ct, &rsa.OAEPOptions{Hash: crypto.SHA256, Label: []byte("l")}); err == nil {
+		t.Error("got nil, want error for an OAEP label")
+	}
+	f.corrupt = true
+	if _, err := d.Decrypt(nil, ct, nil); !errors.Is(err, ErrIntegrity) {
+		t.Errorf("got %v, want ErrIntegrity", err)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"sync"
+	"time"
+
+	logpb "cloud.google.com/go/logging/apiv2/loggingpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// BufferOverflowPolicy determines what Logger.Log does with an entry when the
+// Logger's buffer is full. The size of the buffer is limited by the
+// BufferedByteLimit and BufferedEntryLimit options.
+type BufferOverflowPolicy int
+
+const (
+	// DropNewestOnOverflow discards the entry passed to Log and reports
+	// ErrOverflow to the client's OnError function. This is the default.
+	DropNewestOnOverflow BufferOverflowPolicy = iota
+
+	// DropOldestOnOverflow discards the oldest buffered entries to make room
+	// for the entry passed to Log. Entries that are already being written to
+	// the logging service are not discarded; if they fill the buffer, the new
+	// entry is discarded as with DropNewestOnOverflow.
+	DropOldestOnOverflow
+
+	// BlockOnOverflow makes Log block until enough buffered entries have been
+	// written to make room for the entry passed to it. Log stops blocking, and
+	// discards the entry, when the client is closed.
+	BlockOnOverflow
+)
+
+// BufferStats describes the state of a Logger's buffer and the outcome of its
+// writes to the logging service. It can be used to monitor the health of a
+// Logger.
+type BufferStats struct {
+	// BufferedEntries and BufferedBytes are the number and size of the
+	// entries that have been passed to Log and not yet written, including the
+	// entries that are being written.
+	BufferedEntries int
+	BufferedBytes   int
+
+	// DroppedEntries is the number of entries discarded because the buffer
+	// was full.
+	DroppedEntries int64
+
+	// WrittenEntries is the number of entries written successfully, and
+	// FailedEntries the number of entries whose write failed.
+	WrittenEntries int64
+	FailedEntries  int64
+
+	// LastWriteTime is the time of the last successful write.
+	LastWriteTime time.Time
+
+	// LastError is the error of the last failed write, and LastErrorTime its
+	// time. They are not cleared by later successful writes; compare
+	// LastErrorTime with LastWriteTime to tell whether the Logger recovered.
+	LastError     error
+	LastErrorTime time.Time
+}
+
+// entryBuffer accounts for the entries buffered by a Logger's bundler and
+// enforces its buffer limits and overflow policy.
+type entryBuffer struct {
+	maxEntries int // no limit if zero
+	maxBytes   int // no limit if zero
+	policy     BufferOverflowPolicy
+
+	mu     sync.Mutex
+	cond   *sync.Cond // signaled when buffered entries are written or dropped
+	closed bool
+	stats  BufferStats
+	// queue holds the entries that were added to the bundler, oldest first.
+	// Entries that are no longer in pending are removed lazily.
+	queue []*logpb.LogEntry
+	// pending maps the entries that were not yet handed to a write to their
+	// size.
+	pending map[*logpb.LogEntry]int
+	// dropped contains the entries that were discarded while in the bundler.
+	dropped map[*logpb.LogEntry]bool
+}
+
+func newEntryBuffer(maxEntries, maxBytes int, policy BufferOverflowPolicy) *entryBuffer {
+	b := &entryBuffer{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		policy:     policy,
+		pending:    map[*logpb.LogEntry]int{},
+		dropped:    map[*logpb.LogEntry]bool{},
+	}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// add makes room for ent according to the overflow policy and passes it to
+// bundle. It returns ErrOverflow if ent was discarded.
+func (b *entryBuffer) add(ent *logpb.LogEntry, size int, bundle func(item interface{}, size int) error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for !b.fits(size) {
+		switch {
+		case b.policy == DropOldestOnOverflow && b.dropOldest():
+		case b.policy == BlockOnOverflow && !b.closed:
+			b.cond.Wait()
+		default:
+			b.stats.DroppedEntries++
+			return ErrOverflow
+		}
+	}
+	if err := bundle(ent, size); err != nil {
+		return err
+	}
+	b.stats.BufferedEntries++
+	b.stats.BufferedBytes += size
+	b.queue = append(b.queue, ent)
+	b.pending[ent] = size
+	return nil
+}
+
+// fits reports whether an entry of the given size fits into the buffer. An
+// empty buffer accepts any entry. It requires that b.mu is locked.
+func (b *entryBuffer) fits(size int) bool {
+	if b.stats.BufferedEntries == 0 {
+		return true
+	}
+	return (b.maxEntries <= 0 || b.stats.BufferedEntries < b.maxEntries) &&
+		(b.maxBytes <= 0 || b.stats.BufferedBytes+size <= b.maxBytes)
+}
+
+// dropOldest discards the oldest entry that is not being written, and reports
+// whether there was one. It requires that b.mu is locked.
+func (b *entryBuffer) dropOldest() bool {
+	for len(b.queue) > 0 {
+		ent := b.queue[0]
+		b.queue[0] = nil
+		b.queue = b.queue[1:]
+		size, ok := b.pending[ent]
+		if !ok {
+			continue
+		}
+		delete(b.pending, ent)
+		b.dropped[ent] = true
+		// The bundler keeps a reference to the entry until it is handed to a
+		// write, so release its contents now.
+		proto.Reset(ent)
+		b.stats.BufferedEntries--
+		b.stats.BufferedBytes -= size
+		b.stats.DroppedEntries++
+		return true
+	}
+	return false
+}
+
+// take is called with the entries of a bundle that is about to be written. It
+// returns the entries that were not dropped, and their total size.
+func (b *entryBuffer) take(entries []*logpb.LogEntry) ([]*logpb.LogEntry, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	kept := entries[:0]
+	size := 0
+	for _, ent := range entries {
+		if b.dropped[ent] {
+			delete(b.dropped, ent)
+			continue
+		}
+		size += b.pending[ent]
+		delete(b.pending, ent)
+		kept = append(kept, ent)
+	}
+	for len(b.queue) > 0 {
+		if _, ok := b.pending[b.queue[0]]; ok {
+			break
+		}
+		b.queue[0] = nil
+		b.queue = b.queue[1:]
+	}
+	return kept, size
+}
+
+// done records the outcome of writing n entries of the given total size.
+func (b *entryBuffer) done(n, size int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stats.BufferedEntries -= n
+	b.stats.BufferedBytes -= size
+	if err != nil {
+		b.stats.FailedEntries += int64(n)
+		b.stats.LastError = err
+		b.stats.LastErrorTime = now()
+	} else {
+		b.stats.WrittenEntries += int64(n)
+		b.stats.LastWriteTime = now()
+	}
+	b.cond.Broadcast()
+}
+
+// close makes calls to add that are blocked, or would block, discard their
+// entries.
+func (b *entryBuffer) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	b.cond.Broadcast()
+}
+
+func (b *entryBuffer) snapshot() BufferStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
+
+// BufferStats returns statistics about the entries buffered by the Logger and
+// its writes to the logging service. Entries written with LogSync, and entries
+// redirected with RedirectAsJSON, are not included.
+func (l *Logger) BufferStats() BufferStats {
+	return l.buffer.snapshot()
+}
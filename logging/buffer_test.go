@@ -0,0 +1,115 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	logpb "cloud.google.com/go/logging/apiv2/loggingpb"
+)
+
+// fakeBundle records the entries that entryBuffer.add passes to the bundler.
+type fakeBundle struct {
+	entries []*logpb.LogEntry
+}
+
+func (f *fakeBundle) add(item interface{}, _ int) error {
+	f.entries = append(f.entries, item.(*logpb.LogEntry))
+	return nil
+}
+
+func textEntry(s string) *logpb.LogEntry {
+	return &logpb.LogEntry{Payload: &logpb.LogEntry_TextPayload{TextPayload: s}}
+}
+
+func TestEntryBufferDropNewest(t *testing.T) {
+	var fb fakeBundle
+	b := newEntryBuffer(2, 0, DropNewestOnOverflow)
+	for _, s := range []string{"a", "b"} {
+		if err := b.add(textEntry(s), 10, fb.add); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.add(textEntry("c"), 10, fb.add); err != ErrOverflow {
+		t.Fatalf("got %v, want ErrOverflow", err)
+	}
+	ents, size := b.take(fb.entries)
+	if len(ents) != 2 || size != 20 {
+		t.Errorf("got %d entries of size %d, want 2 of size 20", len(ents), size)
+	}
+	b.done(len(ents), size, nil)
+	got := b.snapshot()
+	if got.BufferedEntries != 0 || got.BufferedBytes != 0 || got.DroppedEntries != 1 || got.WrittenEntries != 2 {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestEntryBufferDropOldest(t *testing.T) {
+	var fb fakeBundle
+	b := newEntryBuffer(0, 25, DropOldestOnOverflow)
+	for _, s := range []string{"a", "b", "c"} {
+		if err := b.add(textEntry(s), 10, fb.add); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// "a" was dropped to make room for "c".
+	ents, size := b.take(fb.entries)
+	if len(ents) != 2 || ents[0].GetTextPayload() != "b" || ents[1].GetTextPayload() != "c" || size != 20 {
+		t.Fatalf("got %v of size %d, want [b c] of size 20", ents, size)
+	}
+	// Entries being written are not dropped, so the new entry is.
+	if err := b.add(textEntry("d"), 10, fb.add); err != ErrOverflow {
+		t.Errorf("got %v, want ErrOverflow", err)
+	}
+	writeErr := errors.New("write failed")
+	b.done(len(ents), size, writeErr)
+	got := b.snapshot()
+	if got.BufferedEntries != 0 || got.DroppedEntries != 2 || got.FailedEntries != 2 || got.LastError != writeErr {
+		t.Errorf("got %+v", got)
+	}
+	if len(b.queue) != 0 || len(b.pending) != 0 || len(b.dropped) != 0 {
+		t.Errorf("buffer not empty: queue %d, pending %d, dropped %d", len(b.queue), len(b.pending), len(b.dropped))
+	}
+}
+
+func TestEntryBufferBlock(t *testing.T) {
+	var fb fakeBundle
+	b := newEntryBuffer(1, 0, BlockOnOverflow)
+	if err := b.add(textEntry("a"), 10, fb.add); err != nil {
+		t.Fatal(err)
+	}
+	errc := make(chan error)
+	go func() { errc <- b.add(textEntry("b"), 10, func(interface{}, int) error { return nil }) }()
+	select {
+	case err := <-errc:
+		t.Fatalf("add returned %v before the buffer had room", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	ents, size := b.take(fb.entries)
+	b.done(len(ents), size, nil)
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+
+	// Closing the buffer unblocks add, which discards its entry.
+	go func() { errc <- b.add(textEntry("c"), 10, fb.add) }()
+	time.Sleep(10 * time.Millisecond)
+	b.close()
+	if err := <-errc; err != ErrOverflow {
+		t.Errorf("got %v, want ErrOverflow", err)
+	}
+}
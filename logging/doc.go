@@ -52,6 +52,28 @@ You should call Client.Close before your program exits to flush any buffered log
 		// TODO: Handle error.
 	}
 
+# Buffer Limits
+
+A Logger buffers at most BufferedByteLimit bytes of entries, and optionally at
+most BufferedEntryLimit entries. When the buffer is full, Log discards the new
+entry and reports ErrOverflow by default. Use the OnBufferOverflow option to
+discard the oldest buffered entries instead, or to make Log block until there
+is room. Logger.BufferStats reports how many entries were buffered, written,
+dropped or failed to be written.
+
+	lg := client.Logger("my-log",
+		logging.BufferedEntryLimit(10000),
+		logging.OnBufferOverflow(logging.DropOldestOnOverflow))
+
+# Redacting Entries
+
+The EntryHooks option adds functions that are called with every entry before
+it is buffered or written, so that sensitive data can be scrubbed in one place.
+A hook can modify the entry, or discard it by returning false. RedactLabels and
+TruncatePayload return common hooks.
+
+	lg := client.Logger("my-log", logging.EntryHooks(logging.RedactLabels("email")))
+
 # Synchronous Logging
 
 For critical errors, you may want to send your log entries immediately.
@@ -111,6 +133,15 @@ You may want use a standard log.Logger in your program.
 	stdlg := lg.StandardLogger(logging.Info)
 	stdlg.Println("some info")
 
+# Structured Logging with slog
+
+With Go 1.21 and later, NewSlogHandler returns a log/slog handler that writes
+records as entries to a Logger. Record attributes become fields of the entry's
+JSON payload, and the record's level determines the entry's severity.
+
+	slogger := slog.New(logging.NewSlogHandler(lg, nil))
+	slogger.InfoContext(ctx, "user signed in", "user", id)
+
 # Log Levels
 
 An Entry may have one of a number of severity levels associated with it.
@@ -128,6 +159,17 @@ running from a Google Cloud Platform VM, select "GCE VM Instance". Otherwise, se
 "Google Project" and then the project ID. Logs for organizations, folders and billing
 accounts can be viewed on the command line with the "gcloud logging read" command.
 
+# Correlating Logs with Traces
+
+An entry's Trace, SpanID and TraceSampled fields correlate it with a trace. If
+they are not set, they are populated from the entry's HTTPRequest: from the
+OpenTelemetry span in the request's context, or else from its W3C traceparent
+or X-Cloud-Trace-Context header. Use Logger.LogContext, or pass the context to
+LogSync, to populate them from the OpenTelemetry span in a context.Context
+when the entry has no HTTPRequest.
+
+	lg.LogContext(ctx, logging.Entry{Payload: "handled request"})
+
 # Grouping Logs by Request
 
 To group all the log entries written during a single HTTP request, create two
@@ -241,3 +241,85 @@ func ExampleContextFunc() {
 	}))
 	_ = lg // TODO: Use lg
 }
+
+// This example shows how to bound the memory used by a Logger, and how to
+// monitor whether its entries are being written.
+func ExampleLogger_BufferStats() {
+	ctx := context.Background()
+	client, err := logging.NewClient(ctx, "my-project")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	lg := client.Logger("my-log",
+		logging.BufferedEntryLimit(10000),
+		logging.BufferedByteLimit(16<<20),
+		logging.OnBufferOverflow(logging.DropOldestOnOverflow))
+	lg.Log(logging.Entry{Payload: "something happened"})
+
+	stats := lg.BufferStats()
+	if stats.DroppedEntries > 0 || stats.LastErrorTime.After(stats.LastWriteTime) {
+		fmt.Printf("logging is unhealthy: %d entries dropped, last error: %v\n",
+			stats.DroppedEntries, stats.LastError)
+	}
+}
+
+// This example shows how to scrub entries in one place before they are written.
+func ExampleEntryHooks() {
+	ctx := context.Background()
+	client, err := logging.NewClient(ctx, "my-project")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	dropHealthChecks := func(e *logging.Entry) bool {
+		return e.HTTPRequest == nil || e.HTTPRequest.Request.URL.Path != "/healthz"
+	}
+	lg := client.Logger("my-log", logging.EntryHooks(
+		dropHealthChecks,
+		logging.RedactLabels("email", "phone"),
+		logging.TruncatePayload(64<<10),
+	))
+	lg.Log(logging.Entry{Payload: "user signed in", Labels: map[string]string{"email": "alice@example.com"}})
+}
+
+func ExampleLogger_LogContext() {
+	ctx := context.Background()
+	client, err := logging.NewClient(ctx, "my-project")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	lg := client.Logger("my-log")
+	// If ctx contains an OpenTelemetry span, for example one created by
+	// instrumented HTTP or gRPC middleware, the entry is correlated with it.
+	lg.LogContext(ctx, logging.Entry{Payload: "something happened"})
+}
+
+func ExampleLogger_HTTPHandler() {
+	ctx := context.Background()
+	client, err := logging.NewClient(ctx, "my-project")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	lg := client.Logger("access")
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Hello world!\n")
+	})
+	http.Handle("/", lg.HTTPHandler(h))
+}
+
+func ExampleAuditPayload() {
+	ctx := context.Background()
+	client, err := logging.NewClient(ctx, "my-project")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	lg := client.Logger("audit")
+	lg.Log(logging.Entry{
+		Severity: logging.Notice,
+		Payload: logging.AuditPayload{
+			ServiceName:  "inventory",
+			MethodName:   "DeleteItem",
+			ResourceName: "items/42",
+			Principal:    "alice@example.com",
+		},
+	})
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import "unicode/utf8"
+
+// RedactedValue replaces the values redacted by RedactLabels.
+const RedactedValue = "[REDACTED]"
+
+// An EntryHook is called with each entry passed to Logger.Log or
+// Logger.LogSync, before the entry is buffered or written. It can modify the
+// entry, for example to redact or truncate parts of it. If it returns false,
+// the entry is discarded.
+//
+// The entry is a copy of the one passed to the Logger, but it shares maps and
+// pointers such as Labels, HTTPRequest and the Payload with it. A hook should
+// replace them rather than modify them in place.
+//
+// Hooks may be called concurrently.
+type EntryHook func(e *Entry) bool
+
+// EntryHooks adds hooks that are called, in order, with each entry written by
+// the Logger. If a hook discards the entry, the following hooks are not called.
+func EntryHooks(hooks ...EntryHook) LoggerOption { return entryHooks(hooks) }
+
+type entryHooks []EntryHook
+
+func (h entryHooks) set(l *Logger) { l.hooks = append(l.hooks, h...) }
+
+// runHooks calls the Logger's hooks with e, and reports whether e should be
+// written.
+func (l *Logger) runHooks(e *Entry) bool {
+	for _, h := range l.hooks {
+		if !h(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// RedactLabels returns an EntryHook that replaces the values of the entry
+// labels with the given keys with RedactedValue.
+func RedactLabels(keys ...string) EntryHook {
+	return func(e *Entry) bool {
+		var labels map[string]string
+		for _, k := range keys {
+			if _, ok := e.Labels[k]; !ok {
+				continue
+			}
+			if labels == nil {
+				labels = make(map[string]string, len(e.Labels))
+				for k, v := range e.Labels {
+					labels[k] = v
+				}
+			}
+			labels[k] = RedactedValue
+		}
+		if labels != nil {
+			e.Labels = labels
+		}
+		return true
+	}
+}
+
+// TruncatePayload returns an EntryHook that truncates string payloads to at
+// most n bytes, without splitting UTF-8 encoded characters. Other payloads
+// are not changed. If n is not positive, the hook does nothing.
+func TruncatePayload(n int) EntryHook {
+	return func(e *Entry) bool {
+		s, ok := e.Payload.(string)
+		if !ok || n <= 0 || len(s) <= n {
+			return true
+		}
+		i := n
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+		e.Payload = s[:i]
+		return true
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"cloud.google.com/go/internal/testutil"
+	"cloud.google.com/go/logging"
+)
+
+func TestEntryHooks(t *testing.T) {
+	var buf bytes.Buffer
+	dropDebug := func(e *logging.Entry) bool { return e.Severity != logging.Debug }
+	lg := client.Logger("hooks", logging.RedirectAsJSON(&buf), logging.EntryHooks(
+		dropDebug,
+		logging.RedactLabels("email", "missing"),
+		logging.TruncatePayload(2),
+	))
+
+	labels := map[string]string{"email": "a@b.c", "user": "a"}
+	lg.Log(logging.Entry{Severity: logging.Debug, Payload: "dropped"})
+	if err := lg.LogSync(context.Background(), logging.Entry{Payload: "héllo", Labels: labels}); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Message string
+		Labels  map[string]string `json:"logging.googleapis.com/labels"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("%v: %s", err, buf.Bytes())
+	}
+	// "é" is two bytes long, so it is not split and is dropped whole.
+	if want := "h"; got.Message != want {
+		t.Errorf("got message %q, want %q", got.Message, want)
+	}
+	if want := map[string]string{"email": logging.RedactedValue, "user": "a"}; !testutil.Equal(got.Labels, want) {
+		t.Errorf("got labels %v, want %v", got.Labels, want)
+	}
+	if labels["email"] != "a@b.c" {
+		t.Errorf("the caller's labels were modified: %v", labels)
+	}
+}
+
+func TestTruncatePayloadNonPositive(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		e := &logging.Entry{Payload: "hello"}
+		if !logging.TruncatePayload(n)(e) || e.Payload != "hello" {
+			t.Errorf("TruncatePayload(%d): got payload %v, want it unchanged", n, e.Payload)
+		}
+	}
+}
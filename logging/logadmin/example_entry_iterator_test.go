@@ -64,3 +64,36 @@ func ExampleEntryIterator_Next() {
 		fmt.Println(entry)
 	}
 }
+
+func ExampleClient_TailEntries() {
+	ctx := context.Background()
+	client, err := logadmin.NewClient(ctx, "my-project")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	it := client.TailEntries(ctx, logadmin.Filter(`logName = "projects/my-project/logs/my-log"`))
+	for {
+		entry, err := it.Next()
+		if err != nil {
+			// TODO: Handle error.
+			break
+		}
+		fmt.Println(entry.Timestamp, entry.Payload)
+	}
+}
+
+func ExampleTailIterator_Entries() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	client, err := logadmin.NewClient(ctx, "my-project")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	it := client.TailEntries(ctx, logadmin.Filter("severity >= ERROR"))
+	for entry := range it.Entries() {
+		fmt.Println(entry.Timestamp, entry.Payload)
+	}
+	if err := it.Err(); err != nil && err != context.DeadlineExceeded {
+		// TODO: Handle error.
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logadmin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"cloud.google.com/go/logging"
+	vkit "cloud.google.com/go/logging/apiv2"
+	logpb "cloud.google.com/go/logging/apiv2/loggingpb"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	durpb "google.golang.org/protobuf/types/known/durationpb"
+)
+
+// tailOption is implemented by the EntriesOptions that apply only to
+// TailEntries.
+type tailOption interface {
+	setTail(*logpb.TailLogEntriesRequest)
+}
+
+// TailBufferWindow sets the amount of time the service buffers entries before
+// sending them to TailEntries, so that it can send them in timestamp order.
+// It must be between 0 and 60 seconds; the service's default is 2 seconds.
+// Entries ignores this option.
+func TailBufferWindow(d time.Duration) EntriesOption { return tailBufferWindow(d) }
+
+type tailBufferWindow time.Duration
+
+func (tailBufferWindow) set(*logpb.ListLogEntriesRequest) {}
+
+func (w tailBufferWindow) setTail(r *logpb.TailLogEntriesRequest) {
+	r.BufferWindow = durpb.New(time.Duration(w))
+}
+
+// TailEntries returns a TailIterator for reading log entries as they are
+// ingested. By default, the log entries will be restricted to those from the
+// project passed to NewClient. This may be overridden by passing a ProjectIDs
+// or ResourceNames option. The Filter and TailBufferWindow options are also
+// supported; other options are ignored. Unlike Entries, TailEntries does not
+// add a default timestamp filter. Requires ReadScope or AdminScope.
+//
+// The iterator reconnects when the stream is closed by the service or fails
+// with a transient error, and resumes from the timestamp of the last entry it
+// received. It stops when ctx is done.
+func (c *Client) TailEntries(ctx context.Context, opts ...EntriesOption) *TailIterator {
+	return &TailIterator{
+		ctx:    ctx,
+		client: c.lClient,
+		req:    tailLogEntriesRequest(c.parent, opts),
+	}
+}
+
+func tailLogEntriesRequest(parent string, opts []EntriesOption) *logpb.TailLogEntriesRequest {
+	// Apply the options shared with Entries to a list request, so they have
+	// the same meaning.
+	lreq := &logpb.ListLogEntriesRequest{
+		ResourceNames: []string{parent},
+	}
+	req := &logpb.TailLogEntriesRequest{}
+	for _, opt := range opts {
+		if t, ok := opt.(tailOption); ok {
+			t.setTail(req)
+		} else {
+			opt.set(lreq)
+		}
+	}
+	req.ResourceNames = lreq.ResourceNames
+	req.Filter = lreq.Filter
+	return req
+}
+
+// A TailIterator iterates over log entries as they are ingested.
+//
+// The methods of a TailIterator must not be called concurrently.
+type TailIterator struct {
+	ctx     context.Context
+	client  *vkit.Client
+	req     *logpb.TailLogEntriesRequest
+	stream  logpb.LoggingServiceV2_TailLogEntriesClient
+	backoff gax.Backoff
+	items   []*logging.Entry
+	err     error
+
+	// last is the latest timestamp of the entries received, and lastIDs the
+	// insert IDs of the entries received with that timestamp. They are used
+	// to resume the stream without returning entries twice.
+	last    time.Time
+	lastIDs map[string]bool
+
+	suppressed int64
+}
+
+// Next returns the next entry, blocking until one is ingested. It returns an
+// error only when the iterator's context is done or the stream fails with an
+// error that is not transient. Once Next returns an error, all subsequent calls
+// will return the same error.
+func (it *TailIterator) Next() (*logging.Entry, error) {
+	for len(it.items) == 0 {
+		if it.err != nil {
+			return nil, it.err
+		}
+		it.receive()
+	}
+	e := it.items[0]
+	it.items = it.items[1:]
+	return e, nil
+}
+
+// Suppressed returns the number of entries that the service did not send,
+// because of rate limits or because they were not read fast enough.
+func (it *TailIterator) Suppressed() int64 { return it.suppressed }
+
+// Entries returns a channel on which the iterator's entries are sent. The
+// channel is closed when Next would return an error, which is then available
+// from Err. Next must not be called after Entries.
+//
+// Entries starts a goroutine that reads from the stream until the iterator's
+// context is done or the stream fails, even if the channel is no longer read.
+// Callers that stop reading before the channel is closed must cancel the
+// context passed to TailEntries to release the goroutine and the stream.
+func (it *TailIterator) Entries() <-chan *logging.Entry {
+	c := make(chan *logging.Entry)
+	go func() {
+		defer close(c)
+		for {
+			e, err := it.Next()
+			if err != nil {
+				return
+			}
+			select {
+			case c <- e:
+			case <-it.ctx.Done():
+				it.err = it.ctx.Err()
+				return
+			}
+		}
+	}()
+	return c
+}
+
+// Err returns the error that ended the iteration. It must only be called after
+// Next returned an error, or after the channel returned by Entries was closed.
+func (it *TailIterator) Err() error { return it.err }
+
+// receive reads the next response from the stream, opening it if needed, and
+// adds its entries to it.items. On error, it either waits before the next
+// attempt or sets it.err.
+func (it *TailIterator) receive() {
+	if it.stream == nil {
+		stream, err := it.open()
+		if err != nil {
+			it.retry(err)
+			return
+		}
+		it.stream = stream
+	}
+	res, err := it.stream.Recv()
+	if err != nil {
+		it.stream = nil
+		it.retry(err)
+		return
+	}
+	it.backoff = gax.Backoff{}
+	for _, s := range res.SuppressionInfo {
+		it.suppressed += int64(s.SuppressedCount)
+	}
+	for _, le := range res.Entries {
+		if it.seen(le) {
+			continue
+		}
+		e, err := fromLogEntry(le)
+		if err != nil {
+			it.err = err
+			return
+		}
+		it.items = append(it.items, e)
+	}
+}
+
+func (it *TailIterator) open() (logpb.LoggingServiceV2_TailLogEntriesClient, error) {
+	stream, err := it.client.TailLogEntries(it.ctx)
+	if err != nil {
+		return nil, err
+	}
+	req := it.req
+	if !it.last.IsZero() {
+		req = &logpb.TailLogEntriesRequest{
+			ResourceNames: it.req.ResourceNames,
+			Filter:        resumeFilter(it.req.Filter, it.last),
+			BufferWindow:  it.req.BufferWindow,
+		}
+	}
+	if err := stream.Send(req); err != nil && err != io.EOF {
+		// On io.EOF, the error is returned by Recv.
+		return nil, err
+	}
+	return stream, nil
+}
+
+// resumeFilter restricts filter to the entries at or after t.
+func resumeFilter(filter string, t time.Time) string {
+	ts := fmt.Sprintf(`timestamp >= %q`, t.UTC().Format(time.RFC3339Nano))
+	if filter == "" {
+		return ts
+	}
+	return fmt.Sprintf("(%s) AND %s", filter, ts)
+}
+
+// seen records le's timestamp and insert ID, and reports whether an entry with
+// the same ones was already received.
+func (it *TailIterator) seen(le *logpb.LogEntry) bool {
+	if le.Timestamp == nil {
+		return false
+	}
+	t := le.Timestamp.AsTime()
+	switch {
+	case t.After(it.last) || it.lastIDs == nil:
+		it.last = t
+		it.lastIDs = map[string]bool{}
+	case t.Before(it.last):
+		return false
+	}
+	if le.InsertId == "" {
+		return false
+	}
+	if it.lastIDs[le.InsertId] {
+		return true
+	}
+	it.lastIDs[le.InsertId] = true
+	return false
+}
+
+// retry waits before the stream is reopened if err is transient, and sets
+// it.err otherwise.
+func (it *TailIterator) retry(err error) {
+	if ctxErr := it.ctx.Err(); ctxErr != nil {
+		it.err = ctxErr
+		return
+	}
+	if err != io.EOF {
+		switch status.Code(err) {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.ResourceExhausted, codes.Aborted:
+		default:
+			it.err = err
+			return
+		}
+	}
+	if err := gax.Sleep(it.ctx, it.backoff.Pause()); err != nil {
+		it.err = err
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logadmin
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/internal/testutil"
+	logpb "cloud.google.com/go/logging/apiv2/loggingpb"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	durpb "google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// tailServer serves the responses of each of its streams in turn. A stream
+// ends with an Unavailable error after its responses have been sent, except
+// the last one, which stays open.
+type tailServer struct {
+	logpb.UnimplementedLoggingServiceV2Server
+
+	mu       sync.Mutex
+	streams  [][]*logpb.TailLogEntriesResponse
+	requests []*logpb.TailLogEntriesRequest
+}
+
+func (s *tailServer) TailLogEntries(stream logpb.LoggingServiceV2_TailLogEntriesServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	i := len(s.requests)
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+	for _, res := range s.streams[i] {
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+	}
+	if i < len(s.streams)-1 {
+		return status.Error(codes.Unavailable, "stream reset")
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+func tailEntry(id string, t time.Time) *logpb.LogEntry {
+	return &logpb.LogEntry{
+		LogName:   "projects/P/logs/L",
+		InsertId:  id,
+		Timestamp: timestamppb.New(t),
+		Payload:   &logpb.LogEntry_TextPayload{TextPayload: id},
+	}
+}
+
+func TestTailEntries(t *testing.T) {
+	t1 := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	t2 := t1.Add(time.Second)
+	ts := &tailServer{streams: [][]*logpb.TailLogEntriesResponse{
+		{{Entries: []*logpb.LogEntry{tailEntry("a", t1), tailEntry("b", t1)}}},
+		// "b" is sent again, because the stream resumes at t1.
+		{{Entries: []*logpb.LogEntry{tailEntry("b", t1), tailEntry("c", t2)}}},
+	}}
+	srv, err := testutil.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logpb.RegisterLoggingServiceV2Server(srv.Gsrv, ts)
+	srv.Start()
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conn, err := grpc.Dial(srv.Addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient(ctx, "P", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	it := c.TailEntries(ctx, Filter("severity>=ERROR"), TailBufferWindow(time.Second))
+	var got []string
+	for e := range it.Entries() {
+		got = append(got, e.Payload.(string))
+		if len(got) == 3 {
+			cancel()
+		}
+	}
+	if want := []string{"a", "b", "c"}; !testutil.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if err := it.Err(); err != context.Canceled {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+
+	wantReqs := []*logpb.TailLogEntriesRequest{
+		{
+			ResourceNames: []string{"projects/P"},
+			Filter:        "severity>=ERROR",
+			BufferWindow:  durpb.New(time.Second),
+		},
+		{
+			ResourceNames: []string{"projects/P"},
+			Filter:        `(severity>=ERROR) AND timestamp >= "2024-01-02T03:04:05.000000006Z"`,
+			BufferWindow:  durpb.New(time.Second),
+		},
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if !testutil.Equal(ts.requests, wantReqs) {
+		t.Errorf("got requests %v, want %v", ts.requests, wantReqs)
+	}
+}
+
+func TestTailEntriesPermanentError(t *testing.T) {
+	srv, err := testutil.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The tail RPC is not implemented.
+	logpb.RegisterLoggingServiceV2Server(srv.Gsrv, &logpb.UnimplementedLoggingServiceV2Server{})
+	srv.Start()
+	defer srv.Close()
+
+	ctx := context.Background()
+	conn, err := grpc.Dial(srv.Addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient(ctx, "P", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	it := c.TailEntries(ctx)
+	if _, err := it.Next(); status.Code(err) != codes.Unimplemented {
+		t.Errorf("got %v, want Unimplemented", err)
+	}
+}
+
+func TestTailEntriesCancelWhileBlocked(t *testing.T) {
+	t1 := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	ts := &tailServer{streams: [][]*logpb.TailLogEntriesResponse{
+		{{Entries: []*logpb.LogEntry{tailEntry("a", t1), tailEntry("b", t1)}}},
+	}}
+	srv, err := testutil.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logpb.RegisterLoggingServiceV2Server(srv.Gsrv, ts)
+	srv.Start()
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conn, err := grpc.Dial(srv.Addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient(ctx, "P", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	it := c.TailEntries(ctx)
+	ch := it.Entries()
+	<-ch
+	// Stop reading while the goroutine is sending "b" or waiting for the
+	// stream, and check that cancelling the context closes the channel.
+	cancel()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				if err := it.Err(); err != context.Canceled {
+					t.Errorf("got %v, want context.Canceled", err)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("channel not closed after the context was cancelled")
+		}
+	}
+}
@@ -94,7 +94,8 @@ type entryByteLimit int
 func (e entryByteLimit) set(l *Logger) { l.bundler.BundleByteLimit = int(e) }
 
 // BufferedByteLimit is the maximum number of bytes that the Logger will keep
-// in memory before returning ErrOverflow. This option limits the total memory
+// in memory before applying its BufferOverflowPolicy, which by default discards
+// new entries and reports ErrOverflow. This option limits the total memory
 // consumption of the Logger (but note that each Logger has its own, separate
 // limit). It is possible to reach BufferedByteLimit even if it is larger than
 // EntryByteThreshold or EntryByteLimit, because calls triggered by the latter
@@ -107,6 +108,23 @@ type bufferedByteLimit int
 
 func (b bufferedByteLimit) set(l *Logger) { l.bundler.BufferedByteLimit = int(b) }
 
+// BufferedEntryLimit is the maximum number of entries that the Logger will keep
+// in memory before applying its BufferOverflowPolicy. It applies in addition to
+// BufferedByteLimit. The default is zero, which means no limit.
+func BufferedEntryLimit(n int) LoggerOption { return bufferedEntryLimit(n) }
+
+type bufferedEntryLimit int
+
+func (b bufferedEntryLimit) set(l *Logger) { l.bufferedEntryLimit = int(b) }
+
+// OnBufferOverflow sets what the Logger does when Log is called while its buffer
+// is full. The default is DropNewestOnOverflow.
+func OnBufferOverflow(p BufferOverflowPolicy) LoggerOption { return overflowPolicy(p) }
+
+type overflowPolicy BufferOverflowPolicy
+
+func (p overflowPolicy) set(l *Logger) { l.overflowPolicy = BufferOverflowPolicy(p) }
+
 // ContextFunc is a function that will be called to obtain a context.Context for the
 // WriteLogEntries RPC executed in the background for calls to Logger.Log. The
 // default is a function that always returns context.Background. The second return
@@ -32,6 +32,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"regexp"
 	"runtime"
@@ -122,7 +123,8 @@ var (
 	detectResourceInternal = detectResource
 
 	// ErrOverflow signals that the number of buffered entries for a Logger
-	// exceeds its BufferLimit.
+	// exceeds its BufferedEntryLimit or BufferedByteLimit, and the entry was
+	// discarded.
 	ErrOverflow = bundler.ErrOverflow
 
 	// ErrOversizedEntry signals that an entry's size exceeds the maximum number of
@@ -276,6 +278,7 @@ type Logger struct {
 	logName    string // "projects/{projectID}/logs/{logID}"
 	stdLoggers map[Severity]*log.Logger
 	bundler    *bundler.Bundler
+	buffer     *entryBuffer
 
 	// Options
 	commonResource         *mrpb.MonitoredResource
@@ -284,6 +287,9 @@ type Logger struct {
 	populateSourceLocation int
 	partialSuccess         bool
 	redirectOutputWriter   io.Writer
+	bufferedEntryLimit     int
+	overflowPolicy         BufferOverflowPolicy
+	hooks                  []EntryHook
 }
 
 type loggerRetryer struct {
@@ -337,7 +343,12 @@ func (c *Client) Logger(logID string, opts ...LoggerOption) *Logger {
 		redirectOutputWriter:   nil,
 	}
 	l.bundler = bundler.NewBundler(&logpb.LogEntry{}, func(entries interface{}) {
-		l.writeLogEntries(entries.([]*logpb.LogEntry))
+		ents, size := l.buffer.take(entries.([]*logpb.LogEntry))
+		if len(ents) == 0 {
+			return
+		}
+		err := l.writeLogEntries(ents)
+		l.buffer.done(len(ents), size, err)
 	})
 	l.bundler.DelayThreshold = DefaultDelayThreshold
 	l.bundler.BundleCountThreshold = DefaultEntryCountThreshold
@@ -347,6 +358,10 @@ func (c *Client) Logger(logID string, opts ...LoggerOption) *Logger {
 	for _, opt := range opts {
 		opt.set(l)
 	}
+	// The buffer enforces the byte limit instead of the bundler, so that the
+	// overflow policy applies to it.
+	l.buffer = newEntryBuffer(l.bufferedEntryLimit, l.bundler.BufferedByteLimit, l.overflowPolicy)
+	l.bundler.BufferedByteLimit = math.MaxInt
 	l.stdLoggers = map[Severity]*log.Logger{}
 	for s := range severityName {
 		e := Entry{Severity: s}
@@ -359,6 +374,7 @@ func (c *Client) Logger(logID string, opts ...LoggerOption) *Logger {
 	go func() {
 		defer c.loggers.Done()
 		<-c.donec
+		l.buffer.close()
 		l.bundler.Flush()
 	}()
 	return l
@@ -696,7 +712,14 @@ func jsonValueToStructValue(v interface{}) *structpb.Value {
 // LogSync logs the Entry synchronously without any buffering. Because LogSync is slow
 // and will block, it is intended primarily for debugging or critical errors.
 // Prefer Log for most uses.
+//
+// If e has no trace information, it is correlated with the OpenTelemetry span
+// in ctx, if any, as with LogContext.
 func (l *Logger) LogSync(ctx context.Context, e Entry) error {
+	populateTraceInfoFromContext(ctx, &e, l.client.parent)
+	if !l.runHooks(&e) {
+		return nil
+	}
 	ent, err := toLogEntryInternal(e, l, l.client.parent, 1)
 	if err != nil {
 		return err
@@ -721,12 +744,26 @@ func (l *Logger) LogSync(ctx context.Context, e Entry) error {
 	return err
 }
 
-// Log buffers the Entry for output to the logging service. It never blocks.
+// Log buffers the Entry for output to the logging service. It never blocks,
+// unless the Logger's buffer is full and its BufferOverflowPolicy is
+// BlockOnOverflow.
 func (l *Logger) Log(e Entry) {
 	l.logInternal(e, 1)
 }
 
+// LogContext buffers the Entry for output to the logging service, like Log.
+// If e has no trace information, either set explicitly or extracted from the
+// headers of e.HTTPRequest, it is correlated with the OpenTelemetry span in
+// ctx, if any, by setting its Trace, SpanID and TraceSampled fields.
+func (l *Logger) LogContext(ctx context.Context, e Entry) {
+	populateTraceInfoFromContext(ctx, &e, l.client.parent)
+	l.logInternal(e, 1)
+}
+
 func (l *Logger) logInternal(e Entry, skipLevels int) {
+	if !l.runHooks(&e) {
+		return
+	}
 	ent, err := toLogEntryInternal(e, l, l.client.parent, skipLevels+1)
 	if err != nil {
 		l.client.error(err)
@@ -744,7 +781,7 @@ func (l *Logger) logInternal(e Entry, skipLevels int) {
 		return
 	}
 	for _, ent = range entries {
-		if err := l.bundler.Add(ent, proto.Size(ent)); err != nil {
+		if err := l.buffer.add(ent, proto.Size(ent), l.bundler.Add); err != nil {
 			l.client.error(err)
 		}
 	}
@@ -761,7 +798,7 @@ func (l *Logger) Flush() error {
 	return l.client.extractErrorInfo()
 }
 
-func (l *Logger) writeLogEntries(entries []*logpb.LogEntry) {
+func (l *Logger) writeLogEntries(entries []*logpb.LogEntry) error {
 	partialSuccess := l.partialSuccess
 	if len(entries) > 1 {
 		partialSuccess = partialSuccess || hasInstrumentation(entries)
@@ -784,6 +821,7 @@ func (l *Logger) writeLogEntries(entries []*logpb.LogEntry) {
 	if afterCall != nil {
 		afterCall()
 	}
+	return err
 }
 
 // StandardLogger returns a *log.Logger for the provided severity.
@@ -847,6 +885,26 @@ func populateTraceInfo(e *Entry, req *http.Request) bool {
 	return false
 }
 
+// populateTraceInfoFromContext sets e's trace fields from the OpenTelemetry
+// span in ctx, unless e already has trace information. The trace is qualified
+// with parent, because toLogEntryInternal only does so for the trace
+// information it populates itself.
+func populateTraceInfoFromContext(ctx context.Context, e *Entry, parent string) {
+	if e.Trace != "" {
+		return
+	}
+	if probe := (Entry{HTTPRequest: e.HTTPRequest}); populateTraceInfo(&probe, nil) {
+		return
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	e.Trace = fmt.Sprintf("%s/traces/%s", parent, sc.TraceID())
+	e.SpanID = sc.SpanID().String()
+	e.TraceSampled = e.TraceSampled || sc.IsSampled()
+}
+
 // As per format described at https://www.w3.org/TR/trace-context/#traceparent-header-field-values
 var validTraceParentExpression = regexp.MustCompile(`^(00)-([a-fA-F\d]{32})-([a-f\d]{16})-([a-fA-F\d]{2})$`)
 
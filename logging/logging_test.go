@@ -17,6 +17,7 @@
 package logging_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -1832,3 +1833,66 @@ func ExampleRedirectAsJSON_withStdout() {
 	logger := client.Logger("redirect-to-stdout", logging.RedirectAsJSON(os.Stdout))
 	logger.Log(logging.Entry{Severity: logging.Debug, Payload: "redirected log"})
 }
+
+func TestLogContextTrace(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+	spanTrace := "projects/" + testProjectID + "/traces/4bf92f3577b34da6a3ce929d0e0e4736"
+	headerRequest := &http.Request{
+		URL:    &url.URL{Scheme: "http"},
+		Header: http.Header{"Traceparent": {"00-105445aa7843bc8bf206b12000100012-000000000000004a-00"}},
+	}
+
+	for _, test := range []struct {
+		name       string
+		in         logging.Entry
+		wantTrace  string
+		wantSpanID string
+	}{
+		{
+			name:       "span from context",
+			in:         logging.Entry{Payload: "p"},
+			wantTrace:  spanTrace,
+			wantSpanID: "00f067aa0ba902b7",
+		},
+		{
+			name:       "explicit trace takes precedence",
+			in:         logging.Entry{Payload: "p", Trace: "projects/P/traces/abc", SpanID: "1"},
+			wantTrace:  "projects/P/traces/abc",
+			wantSpanID: "1",
+		},
+		{
+			name:       "traceparent header takes precedence",
+			in:         logging.Entry{Payload: "p", HTTPRequest: &logging.HTTPRequest{Request: headerRequest}},
+			wantTrace:  "projects/" + testProjectID + "/traces/105445aa7843bc8bf206b12000100012",
+			wantSpanID: "000000000000004a",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			lg := client.Logger("log-context", logging.RedirectAsJSON(&buf))
+			lg.LogContext(ctx, test.in)
+			if err := lg.LogSync(ctx, test.in); err != nil {
+				t.Fatal(err)
+			}
+			dec := json.NewDecoder(&buf)
+			for i := 0; i < 2; i++ {
+				var got struct {
+					Trace  string `json:"logging.googleapis.com/trace"`
+					SpanID string `json:"logging.googleapis.com/spanId"`
+				}
+				if err := dec.Decode(&got); err != nil {
+					t.Fatal(err)
+				}
+				if got.Trace != test.wantTrace || got.SpanID != test.wantSpanID {
+					t.Errorf("entry %d: got trace %q, span %q; want %q, %q", i, got.Trace, got.SpanID, test.wantTrace, test.wantSpanID)
+				}
+			}
+		})
+	}
+}
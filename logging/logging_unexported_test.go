@@ -151,7 +151,9 @@ func TestLoggerCreation(t *testing.T) {
 		if got, want := gotLogger.bundler.BundleByteLimit, test.wantBundler.BundleByteLimit; got != want {
 			t.Errorf("%v: BundleByteLimit: got %v, want %v", test.options, got, want)
 		}
-		if got, want := gotLogger.bundler.BufferedByteLimit, test.wantBundler.BufferedByteLimit; got != want {
+		// The buffered byte limit is enforced by the Logger's buffer rather than
+		// the bundler.
+		if got, want := gotLogger.buffer.maxBytes, test.wantBundler.BufferedByteLimit; got != want {
 			t.Errorf("%v: BufferedByteLimit: got %v, want %v", test.options, got, want)
 		}
 	}
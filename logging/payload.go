@@ -0,0 +1,244 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// NewHTTPRequestEntry returns an entry for an HTTP request served with the
+// given status, response size in bytes and latency. The entry's severity is
+// Error for 5xx statuses, Warning for 4xx statuses and Info otherwise.
+//
+// The entry's HTTPRequest is filled from r, including the request size, when
+// r.ContentLength is known, and the client IP address from r.RemoteAddr. As
+// for any entry with an HTTPRequest, the trace is taken from the request
+// headers when the entry is written. The payload is a summary of the request,
+// such as "GET /path 200".
+func NewHTTPRequestEntry(r *http.Request, status int, responseSize int64, latency time.Duration) Entry {
+	hr := &HTTPRequest{
+		Request:      r,
+		Status:       status,
+		ResponseSize: responseSize,
+		Latency:      latency,
+	}
+	if r.ContentLength > 0 {
+		hr.RequestSize = r.ContentLength
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		hr.RemoteIP = host
+	}
+	return Entry{
+		Severity:    statusSeverity(status),
+		Payload:     fmt.Sprintf("%s %s %d", r.Method, fixUTF8(r.URL.Path), status),
+		HTTPRequest: hr,
+	}
+}
+
+// statusSeverity returns the severity of an entry for an HTTP request served
+// with the given status.
+func statusSeverity(status int) Severity {
+	switch {
+	case status >= 500:
+		return Error
+	case status >= 400:
+		return Warning
+	default:
+		return Info
+	}
+}
+
+// HTTPHandler returns an http.Handler that calls h and logs an access log
+// entry, created with NewHTTPRequestEntry, for each request once h returns.
+// Requests are logged with Log, so the entries are buffered.
+//
+// If h panics, the request is logged with status 500, unless h already wrote
+// a status, and the panic is propagated.
+func (l *Logger) HTTPHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &accessLogWriter{ResponseWriter: w}
+		defer func() {
+			v := recover()
+			status := rw.status
+			if status == 0 {
+				status = http.StatusOK
+				if v != nil {
+					status = http.StatusInternalServerError
+				}
+			}
+			l.Log(NewHTTPRequestEntry(r, status, rw.size, time.Since(start)))
+			if v != nil {
+				panic(v)
+			}
+		}()
+		h.ServeHTTP(rw, r)
+	})
+}
+
+// accessLogWriter records the status and the size of a response.
+type accessLogWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (w *accessLogWriter) WriteHeader(code int) {
+	// Informational responses other than 101 Switching Protocols are followed
+	// by the final status.
+	if w.status == 0 && (code >= 200 || code == http.StatusSwitchingProtocols) {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *accessLogWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher if the underlying ResponseWriter does.
+func (w *accessLogWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter, so that an
+// http.ResponseController can access its other methods.
+func (w *accessLogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// AuditPayload is a structured payload for audit-style entries, which record
+// who did what to which resource. Its JSON field names follow the ones of
+// Cloud Audit Logs, so the entries can be queried in the same way, for example
+// with the filter jsonPayload.methodName="Delete".
+type AuditPayload struct {
+	// ServiceName is the name of the service that performed the operation.
+	ServiceName string `json:"serviceName,omitempty"`
+
+	// MethodName is the name of the operation.
+	MethodName string `json:"methodName,omitempty"`
+
+	// ResourceName is the name of the resource that the operation was
+	// performed on.
+	ResourceName string `json:"resourceName,omitempty"`
+
+	// Principal identifies the user or service account that requested the
+	// operation, usually by email.
+	Principal string `json:"-"`
+
+	// Status is the outcome of the operation. It is omitted if empty.
+	Status string `json:"status,omitempty"`
+
+	// Request and Response hold details about the operation. They must
+	// marshal into JSON.
+	Request  interface{} `json:"request,omitempty"`
+	Response interface{} `json:"response,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. The principal is written as
+// authenticationInfo.principalEmail, as in Cloud Audit Logs.
+func (p AuditPayload) MarshalJSON() ([]byte, error) {
+	type payload AuditPayload // without the MarshalJSON method
+	type authInfo struct {
+		PrincipalEmail string `json:"principalEmail"`
+	}
+	v := struct {
+		payload
+		AuthenticationInfo *authInfo `json:"authenticationInfo,omitempty"`
+	}{payload: payload(p)}
+	if p.Principal != "" {
+		v.AuthenticationInfo = &authInfo{PrincipalEmail: p.Principal}
+	}
+	return json.Marshal(v)
+}
+
+// CloudEvent is an event in the format of the CloudEvents specification,
+// version 1.0. See https://cloudevents.io.
+type CloudEvent struct {
+	// ID identifies the event. Together with Source, it must be unique.
+	ID string
+
+	// Source identifies the context in which the event happened, as a URI
+	// reference.
+	Source string
+
+	// Type is the type of the event, for example
+	// "com.example.object.deleted".
+	Type string
+
+	// Subject, if not empty, is the subject of the event in the context of
+	// Source.
+	Subject string
+
+	// Time, if not zero, is the time when the event happened.
+	Time time.Time
+
+	// DataContentType, if not empty, is the media type of Data.
+	DataContentType string
+
+	// Data is the payload of the event. It must marshal into JSON.
+	Data interface{}
+}
+
+// MarshalJSON implements json.Marshaler, using the attribute names of the
+// JSON format of CloudEvents.
+func (ev CloudEvent) MarshalJSON() ([]byte, error) {
+	v := struct {
+		SpecVersion     string      `json:"specversion"`
+		ID              string      `json:"id"`
+		Source          string      `json:"source"`
+		Type            string      `json:"type"`
+		Subject         string      `json:"subject,omitempty"`
+		Time            *time.Time  `json:"time,omitempty"`
+		DataContentType string      `json:"datacontenttype,omitempty"`
+		Data            interface{} `json:"data,omitempty"`
+	}{
+		SpecVersion:     "1.0",
+		ID:              ev.ID,
+		Source:          ev.Source,
+		Type:            ev.Type,
+		Subject:         ev.Subject,
+		DataContentType: ev.DataContentType,
+		Data:            ev.Data,
+	}
+	if !ev.Time.IsZero() {
+		t := ev.Time.UTC()
+		v.Time = &t
+	}
+	return json.Marshal(v)
+}
+
+// NewCloudEventEntry returns an entry whose payload is ev. The entry's
+// timestamp is the time of the event, if it is set.
+func NewCloudEventEntry(ev CloudEvent) Entry {
+	return Entry{
+		Timestamp: ev.Time,
+		Payload:   ev,
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/internal/testutil"
+	"cloud.google.com/go/logging"
+)
+
+func TestNewHTTPRequestEntry(t *testing.T) {
+	r := httptest.NewRequest("POST", "/items?id=1", strings.NewReader("body"))
+	r.RemoteAddr = "10.0.0.1:1234"
+	e := logging.NewHTTPRequestEntry(r, http.StatusNotFound, 12, time.Second)
+	if got, want := e.Severity, logging.Warning; got != want {
+		t.Errorf("got severity %v, want %v", got, want)
+	}
+	if got, want := e.Payload, "POST /items 404"; got != want {
+		t.Errorf("got payload %q, want %q", got, want)
+	}
+	if e.HTTPRequest.Request != r {
+		t.Error("the entry does not have the request")
+	}
+	got := *e.HTTPRequest
+	got.Request = nil
+	want := logging.HTTPRequest{
+		RequestSize:  4,
+		Status:       http.StatusNotFound,
+		ResponseSize: 12,
+		Latency:      time.Second,
+		RemoteIP:     "10.0.0.1",
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestHTTPHandler(t *testing.T) {
+	var buf bytes.Buffer
+	lg := client.Logger("access", logging.RedirectAsJSON(&buf))
+	h := lg.HTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/panic" {
+			panic("boom")
+		}
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, "hello")
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+	func() {
+		defer func() {
+			if v := recover(); v != "boom" {
+				t.Errorf("got panic %v, want boom", v)
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/panic", nil))
+	}()
+
+	type entry struct {
+		Message     string
+		Severity    string
+		HTTPRequest struct {
+			RequestMethod string
+			Status        int
+			ResponseSize  string
+		}
+	}
+	var got []entry
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var e entry
+		if err := dec.Decode(&e); err != nil {
+			t.Fatal(err)
+		}
+		if e.HTTPRequest.RequestMethod != "" {
+			got = append(got, e)
+		}
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d access log entries, want 2: %+v", len(got), got)
+	}
+	if e := got[0]; e.Message != "GET /ok 201" || e.Severity != "INFO" || e.HTTPRequest.Status != 201 || e.HTTPRequest.ResponseSize != "5" {
+		t.Errorf("got %+v, want a 201 response of 5 bytes", e)
+	}
+	if e := got[1]; e.Message != "GET /panic 500" || e.Severity != "ERROR" || e.HTTPRequest.Status != 500 {
+		t.Errorf("got %+v, want a 500 response", e)
+	}
+}
+
+func TestAuditPayload(t *testing.T) {
+	b, err := json.Marshal(logging.AuditPayload{
+		ServiceName:  "store",
+		MethodName:   "DeleteItem",
+		ResourceName: "items/1",
+		Principal:    "a@example.com",
+		Request:      map[string]string{"id": "1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"serviceName":        "store",
+		"methodName":         "DeleteItem",
+		"resourceName":       "items/1",
+		"authenticationInfo": map[string]interface{}{"principalEmail": "a@example.com"},
+		"request":            map[string]interface{}{"id": "1"},
+	}
+	if !testutil.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCloudEvent(t *testing.T) {
+	tm := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	e := logging.NewCloudEventEntry(logging.CloudEvent{
+		ID:     "1",
+		Source: "//storage.googleapis.com/buckets/b",
+		Type:   "google.cloud.storage.object.v1.finalized",
+		Time:   tm,
+		Data:   map[string]int{"size": 3},
+	})
+	if !e.Timestamp.Equal(tm) {
+		t.Errorf("got timestamp %v, want %v", e.Timestamp, tm)
+	}
+	b, err := json.Marshal(e.Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"specversion":"1.0","id":"1","source":"//storage.googleapis.com/buckets/b","type":"google.cloud.storage.object.v1.finalized","time":"2024-05-01T12:00:00Z","data":{"size":3}}`
+	if got := string(b); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+
+	logpb "cloud.google.com/go/logging/apiv2/loggingpb"
+)
+
+// SlogLabelsKey is the key of the slog group whose attributes are written as
+// the labels of a log entry by the handler returned by NewSlogHandler, rather
+// than as part of its payload:
+//
+//	logger.Info("request served", slog.Group(logging.SlogLabelsKey, "tenant", tenant))
+const SlogLabelsKey = "logging.googleapis.com/labels"
+
+// SlogHandlerOptions are options for the handler returned by NewSlogHandler.
+type SlogHandlerOptions struct {
+	// Level is the minimum level of the records that are logged. If nil, the
+	// handler logs records of level slog.LevelInfo and above.
+	Level slog.Leveler
+
+	// AddSource sets the source location of each entry to the location of
+	// the call that created the record.
+	AddSource bool
+
+	// Labels are added to the labels of every entry. Labels from the record's
+	// SlogLabelsKey group take precedence.
+	Labels map[string]string
+}
+
+// NewSlogHandler returns a slog.Handler that writes records as entries to the
+// given Logger. Entries are buffered like the ones written with Logger.Log.
+//
+// The entry's payload is a JSON object with the record's message under the
+// key "message" and its attributes as fields; attribute groups become nested
+// objects. The record's level is mapped to a Severity: slog.LevelDebug to
+// Debug, slog.LevelInfo to Info, slog.LevelWarn to Warning and slog.LevelError
+// to Error. Levels between slog.LevelInfo and slog.LevelWarn map to Notice,
+// and levels above slog.LevelError map to Critical, Alert and Emergency in
+// steps of 4.
+//
+// If the context passed to the slog.Logger contains an OpenTelemetry span, the
+// entry is correlated with it by setting the entry's Trace, SpanID and
+// TraceSampled fields.
+func NewSlogHandler(logger *Logger, opts *SlogHandlerOptions) slog.Handler {
+	h := &slogHandler{logger: logger}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Level == nil {
+		h.opts.Level = slog.LevelInfo
+	}
+	return h
+}
+
+type slogHandler struct {
+	logger *Logger
+	opts   SlogHandlerOptions
+	// goas contains the groups and attributes added with WithGroup and
+	// WithAttrs, in order.
+	goas []groupOrAttrs
+}
+
+// groupOrAttrs holds either a group name or a list of attributes.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: attrs})
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name})
+}
+
+func (h *slogHandler) with(goa groupOrAttrs) *slogHandler {
+	h2 := *h
+	h2.goas = make([]groupOrAttrs, len(h.goas)+1)
+	copy(h2.goas, h.goas)
+	h2.goas[len(h.goas)] = goa
+	return &h2
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	labels := make(map[string]string, len(h.opts.Labels))
+	for k, v := range h.opts.Labels {
+		labels[k] = v
+	}
+	payload := map[string]interface{}{"message": r.Message}
+	cur := payload
+	for _, goa := range h.goas {
+		if goa.group != "" {
+			m := map[string]interface{}{}
+			cur[goa.group] = m
+			cur = m
+			continue
+		}
+		for _, a := range goa.attrs {
+			addSlogAttr(cur, labels, a)
+		}
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addSlogAttr(cur, labels, a)
+		return true
+	})
+
+	e := Entry{
+		Timestamp: r.Time,
+		Severity:  slogLevelToSeverity(r.Level),
+		Payload:   payload,
+	}
+	if len(labels) > 0 {
+		e.Labels = labels
+	}
+	populateTraceInfoFromContext(ctx, &e, h.logger.client.parent)
+	if h.opts.AddSource && r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		e.SourceLocation = &logpb.LogEntrySourceLocation{
+			File:     f.File,
+			Line:     int64(f.Line),
+			Function: f.Function,
+		}
+	}
+	h.logger.Log(e)
+	return nil
+}
+
+// addSlogAttr adds a to m, or to labels if a is the SlogLabelsKey group.
+func addSlogAttr(m map[string]interface{}, labels map[string]string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if a.Key == SlogLabelsKey && v.Kind() == slog.KindGroup {
+		for _, la := range v.Group() {
+			labels[la.Key] = la.Value.Resolve().String()
+		}
+		return
+	}
+	switch {
+	case a.Equal(slog.Attr{}):
+		// Ignore empty attributes, as slog.Handler implementations should.
+	case v.Kind() == slog.KindGroup:
+		attrs := v.Group()
+		if len(attrs) == 0 {
+			return
+		}
+		if a.Key == "" {
+			// Inline groups without a key.
+			for _, ga := range attrs {
+				addSlogAttr(m, labels, ga)
+			}
+			return
+		}
+		g := map[string]interface{}{}
+		for _, ga := range attrs {
+			addSlogAttr(g, labels, ga)
+		}
+		m[a.Key] = g
+	default:
+		m[a.Key] = slogValueToInterface(v)
+	}
+}
+
+// slogValueToInterface converts v to a value that marshals to JSON as
+// expected.
+func slogValueToInterface(v slog.Value) interface{} {
+	switch v.Kind() {
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindTime:
+		return v.Time()
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case error:
+			return x.Error()
+		case fmt.Stringer:
+			return x.String()
+		}
+		return v.Any()
+	default:
+		return v.Any()
+	}
+}
+
+// slogLevelToSeverity maps a slog level to the closest Severity.
+func slogLevelToSeverity(l slog.Level) Severity {
+	switch {
+	case l < slog.LevelInfo:
+		return Debug
+	case l == slog.LevelInfo:
+		return Info
+	case l < slog.LevelWarn:
+		return Notice
+	case l < slog.LevelError:
+		return Warning
+	case l < slog.LevelError+4:
+		return Error
+	case l < slog.LevelError+8:
+		return Critical
+	case l < slog.LevelError+12:
+		return Alert
+	default:
+		return Emergency
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/internal/testutil"
+	"cloud.google.com/go/logging"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func ExampleNewSlogHandler() {
+	ctx := context.Background()
+	client, err := logging.NewClient(ctx, "my-project")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	lg := client.Logger("my-log")
+	logger := slog.New(logging.NewSlogHandler(lg, &logging.SlogHandlerOptions{
+		AddSource: true,
+	}))
+	logger.InfoContext(ctx, "user signed in",
+		"user", "alice",
+		slog.Group(logging.SlogLabelsKey, "tenant", "acme"))
+}
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	lg := client.Logger("slog-handler", logging.RedirectAsJSON(&buf))
+	h := logging.NewSlogHandler(lg, &logging.SlogHandlerOptions{
+		Level:     slog.LevelDebug,
+		AddSource: true,
+		Labels:    map[string]string{"env": "test", "tenant": "default"},
+	})
+	logger := slog.New(h).With("service", "api").WithGroup("req")
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+	logger.WarnContext(ctx, "slow request",
+		"path", "/a",
+		"err", errors.New("boom"),
+		slog.Group(logging.SlogLabelsKey, "tenant", "t1"),
+		slog.Group("user", "id", 7))
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("%v: %s", err, buf.Bytes())
+	}
+	want := map[string]interface{}{
+		"message": map[string]interface{}{
+			"message": "slow request",
+			"service": "api",
+			"req": map[string]interface{}{
+				"path": "/a",
+				"err":  "boom",
+				"user": map[string]interface{}{"id": float64(7)},
+			},
+		},
+		"severity":                              "WARNING",
+		"logging.googleapis.com/labels":         map[string]interface{}{"env": "test", "tenant": "t1"},
+		"logging.googleapis.com/trace":          "projects/" + testProjectID + "/traces/4bf92f3577b34da6a3ce929d0e0e4736",
+		"logging.googleapis.com/spanId":         "00f067aa0ba902b7",
+		"logging.googleapis.com/trace_sampled":  true,
+		"logging.googleapis.com/sourceLocation": got["logging.googleapis.com/sourceLocation"],
+		"timestamp":                             got["timestamp"],
+	}
+	if diff := testutil.Diff(got, want); diff != "" {
+		t.Errorf("got(-), want(+):\n%s", diff)
+	}
+	src, _ := got["logging.googleapis.com/sourceLocation"].(map[string]interface{})
+	if file, _ := src["file"].(string); !strings.HasSuffix(file, "slog_test.go") {
+		t.Errorf("got source location %v, want slog_test.go", src)
+	}
+}
+
+func TestSlogHandlerSeverity(t *testing.T) {
+	var buf bytes.Buffer
+	lg := client.Logger("slog-handler", logging.RedirectAsJSON(&buf))
+	logger := slog.New(logging.NewSlogHandler(lg, nil))
+	ctx := context.Background()
+	for _, test := range []struct {
+		level slog.Level
+		want  string // empty if not logged
+	}{
+		{slog.LevelDebug, ""},
+		{slog.LevelInfo, "INFO"},
+		{slog.LevelInfo + 2, "NOTICE"},
+		{slog.LevelWarn, "WARNING"},
+		{slog.LevelError, "ERROR"},
+		{slog.LevelError + 4, "CRITICAL"},
+		{slog.LevelError + 8, "ALERT"},
+		{slog.LevelError + 12, "EMERGENCY"},
+	} {
+		buf.Reset()
+		logger.Log(ctx, test.level, "m")
+		if test.want == "" {
+			if buf.Len() != 0 {
+				t.Errorf("%v: got %s, want no entry", test.level, buf.Bytes())
+			}
+			continue
+		}
+		var got struct{ Severity string }
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("%v: %v", test.level, err)
+		}
+		if got.Severity != test.want {
+			t.Errorf("%v: got severity %s, want %s", test.level, got.Severity, test.want)
+		}
+	}
+}
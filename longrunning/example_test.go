@@ -112,3 +112,25 @@ func ExampleOperation_Delete() {
 		// TODO: Handle err.
 	}
 }
+
+func ExampleWaitTyped() {
+	op, err := bestMomentInHistory()
+	if err != nil {
+		// TODO: Handle err.
+	}
+	ts, err := WaitTyped[*timestamppb.Timestamp](context.TODO(), op, &WaitOptions{
+		Progress: func(op *Operation) {
+			var meta durationpb.Duration
+			if err := op.Metadata(&meta); err == nil {
+				fmt.Println("estimated time left:", meta.AsDuration())
+			}
+		},
+		CancelOnDone: true,
+	})
+	if err != nil {
+		// TODO: Handle err.
+	}
+	fmt.Println(ts.AsTime().Format(time.RFC3339Nano))
+	// Output:
+	// 2009-11-10T23:00:00Z
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package longrunning
+
+import (
+	"context"
+	"time"
+
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// cancelTimeout bounds the cancellation of an operation by WaitTyped, which
+// happens after the caller's context is done.
+const cancelTimeout = 10 * time.Second
+
+// WaitOptions configures WaitTyped.
+type WaitOptions struct {
+	// Backoff is the polling backoff. If zero, polling starts after one
+	// second, and the interval doubles up to DefaultWaitInterval.
+	Backoff gax.Backoff
+
+	// Progress, if not nil, is called after each poll of an operation that
+	// is not done, for example to report the progress found in its
+	// metadata with Operation.Metadata.
+	Progress func(op *Operation)
+
+	// CancelOnDone requests the cancellation of the operation with
+	// Operation.Cancel when ctx is done before the operation. If ctx has a
+	// deadline that would pass before the next poll, the operation is
+	// cancelled right away instead of sleeping until the deadline.
+	CancelOnDone bool
+
+	// CallOptions are passed to the polling calls.
+	CallOptions []gax.CallOption
+}
+
+// WaitTyped blocks until op is completed, and returns its response as a T,
+// which must be the pointer type of a generated message, such as
+// *durationpb.Duration. opts may be nil.
+//
+// If the operation completes with an error, WaitTyped returns it; see
+// Operation.Poll for error-handling information. If ctx is done first,
+// WaitTyped returns ctx.Err().
+func WaitTyped[T proto.Message](ctx context.Context, op *Operation, opts *WaitOptions) (T, error) {
+	return waitTyped[T](ctx, op, opts, gax.Sleep, time.Now)
+}
+
+// waitTyped implements WaitTyped, taking sleeper and clock arguments for
+// testing.
+func waitTyped[T proto.Message](ctx context.Context, op *Operation, opts *WaitOptions, sl sleeper, now func() time.Time) (T, error) {
+	var zero T
+	if opts == nil {
+		opts = &WaitOptions{}
+	}
+	bo := opts.Backoff
+	if bo.Initial == 0 && bo.Max == 0 && bo.Multiplier == 0 {
+		bo = gax.Backoff{Initial: time.Second, Max: DefaultWaitInterval}
+	}
+	// Create a new message of type T; ProtoReflect works on nil pointers of
+	// generated messages.
+	resp := zero.ProtoReflect().New().Interface().(T)
+	cancel := func(err error) (T, error) {
+		if opts.CancelOnDone {
+			cctx, done := context.WithTimeout(context.Background(), cancelTimeout)
+			defer done()
+			op.Cancel(cctx)
+		}
+		return zero, err
+	}
+	for {
+		if err := op.Poll(ctx, protoadapt.MessageV1Of(resp), opts.CallOptions...); err != nil {
+			if ctx.Err() != nil {
+				return cancel(ctx.Err())
+			}
+			return zero, err
+		}
+		if op.Done() {
+			return resp, nil
+		}
+		if opts.Progress != nil {
+			opts.Progress(op)
+		}
+		pause := bo.Pause()
+		if d, ok := ctx.Deadline(); ok && opts.CancelOnDone && d.Before(now().Add(pause)) {
+			return cancel(context.DeadlineExceeded)
+		}
+		if err := sl(ctx, pause); err != nil {
+			return cancel(err)
+		}
+	}
+}
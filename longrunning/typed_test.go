@@ -0,0 +1,127 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package longrunning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "cloud.google.com/go/longrunning/autogen/longrunningpb"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// cancelService is a getterService that records cancellations.
+type cancelService struct {
+	getterService
+	cancels int
+}
+
+func (s *cancelService) CancelOperation(context.Context, *pb.CancelOperationRequest, ...gax.CallOption) error {
+	s.cancels++
+	return nil
+}
+
+func pendingOperation(t *testing.T, progress float64) *pb.Operation {
+	t.Helper()
+	meta, err := anypb.New(wrapperspb.Double(progress))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pb.Operation{Name: "foo", Metadata: meta}
+}
+
+func TestWaitTyped(t *testing.T) {
+	want := durationpb.New(42 * time.Second)
+	respAny, err := anypb.New(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &cancelService{getterService: getterService{
+		results: []*pb.Operation{
+			pendingOperation(t, 0.25),
+			pendingOperation(t, 0.5),
+			{Name: "foo", Done: true, Result: &pb.Operation_Response{Response: respAny}},
+		},
+	}}
+	op := &Operation{c: s, proto: &pb.Operation{Name: "foo"}}
+	var progress []float64
+	opts := &WaitOptions{
+		Backoff: gax.Backoff{Initial: time.Second, Max: time.Second},
+		Progress: func(op *Operation) {
+			var p wrapperspb.DoubleValue
+			if err := op.Metadata(&p); err != nil {
+				t.Error(err)
+			}
+			progress = append(progress, p.Value)
+		},
+	}
+	got, err := waitTyped[*durationpb.Duration](context.Background(), op, opts, s.sleeper(), time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if len(progress) != 2 || progress[0] != 0.25 || progress[1] != 0.5 {
+		t.Errorf("got progress %v, want [0.25 0.5]", progress)
+	}
+	if s.cancels != 0 {
+		t.Errorf("got %d cancellations, want 0", s.cancels)
+	}
+}
+
+func TestWaitTypedCancelOnDone(t *testing.T) {
+	s := &cancelService{getterService: getterService{
+		results: []*pb.Operation{pendingOperation(t, 0)},
+	}}
+	op := &Operation{c: s, proto: &pb.Operation{Name: "foo"}}
+	opts := &WaitOptions{CancelOnDone: true}
+	deadline := time.Now().Add(time.Hour)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	// The clock says the deadline is now, so it passes before the next
+	// poll, and the operation is cancelled without waiting.
+	_, err := waitTyped[*durationpb.Duration](ctx, op, opts, s.sleeper(), func() time.Time { return deadline })
+	if err != context.DeadlineExceeded {
+		t.Errorf("got %v, want context.DeadlineExceeded", err)
+	}
+	if got := len(s.getTimes); got != 1 {
+		t.Errorf("got %d polls, want 1", got)
+	}
+	if s.clock != 0 {
+		t.Errorf("slept %v, want no sleep", s.clock)
+	}
+	if s.cancels != 1 {
+		t.Errorf("got %d cancellations, want 1", s.cancels)
+	}
+
+	// Without CancelOnDone, the operation is left running.
+	s = &cancelService{getterService: getterService{results: []*pb.Operation{pendingOperation(t, 0)}}}
+	op = &Operation{c: s, proto: &pb.Operation{Name: "foo"}}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	sl := func(ctx context.Context, _ time.Duration) error { return ctx.Err() }
+	if _, err := waitTyped[*durationpb.Duration](ctx, op, nil, sl, time.Now); err != context.Canceled {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	if s.cancels != 0 {
+		t.Errorf("got %d cancellations, want 0", s.cancels)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricwriter_test
+
+import (
+	"context"
+	"time"
+
+	monitoring "cloud.google.com/go/monitoring/apiv3/v2"
+	"cloud.google.com/go/monitoring/metricwriter"
+)
+
+func ExampleNewWriter() {
+	ctx := context.Background()
+	client, err := monitoring.NewMetricClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	w, err := metricwriter.NewWriter(ctx, client, "my-project", &metricwriter.Config{
+		Interval: 30 * time.Second,
+	})
+	if err != nil {
+		// TODO: Handle error.
+	}
+	// Close writes the last values.
+	defer w.Close(ctx)
+
+	requests := w.Counter("myapp/requests")
+	latency := w.Distribution("myapp/latency_ms", []float64{1, 5, 10, 50, 100, 500, 1000})
+	queueSize := w.Gauge("myapp/queue_size")
+
+	start := time.Now()
+	// TODO: Handle a request.
+	labels := map[string]string{"method": "GET"}
+	requests.Add(1, labels)
+	latency.Record(float64(time.Since(start).Milliseconds()), labels)
+	queueSize.Set(12, nil)
+}
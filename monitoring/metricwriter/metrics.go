@@ -0,0 +1,123 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricwriter
+
+import (
+	"sort"
+
+	distributionpb "google.golang.org/genproto/googleapis/api/distribution"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+)
+
+// A Counter is a cumulative INT64 metric, whose time series start when their
+// first value is added.
+type Counter struct {
+	w          *Writer
+	metricType string
+}
+
+// Counter returns a counter of a metric type. A metric type without a
+// domain, such as "myapp/requests", is a custom metric type, under
+// custom.googleapis.com.
+func (w *Writer) Counter(metricType string) *Counter {
+	return &Counter{w: w, metricType: metricTypeOf(metricType)}
+}
+
+// Add adds n to the time series of the labels, which may be nil.
+func (c *Counter) Add(n int64, labels map[string]string) {
+	c.w.update(c.metricType, labels, metricpb.MetricDescriptor_CUMULATIVE, metricpb.MetricDescriptor_INT64, func(s *series) {
+		s.intValue += n
+	})
+}
+
+// A Gauge is a GAUGE DOUBLE metric, whose time series are written with
+// their last value.
+type Gauge struct {
+	w          *Writer
+	metricType string
+}
+
+// Gauge returns a gauge of a metric type, which is named as for Counter.
+func (w *Writer) Gauge(metricType string) *Gauge {
+	return &Gauge{w: w, metricType: metricTypeOf(metricType)}
+}
+
+// Set sets the value of the time series of the labels, which may be nil.
+func (g *Gauge) Set(v float64, labels map[string]string) {
+	g.w.update(g.metricType, labels, metricpb.MetricDescriptor_GAUGE, metricpb.MetricDescriptor_DOUBLE, func(s *series) {
+		s.doubleValue = v
+	})
+}
+
+// A Distribution is a cumulative DISTRIBUTION metric with explicit bucket
+// bounds, such as a latency histogram.
+type Distribution struct {
+	w          *Writer
+	metricType string
+	bounds     []float64
+}
+
+// Distribution returns a distribution of a metric type, which is named as
+// for Counter. Its buckets are separated by bounds, which are sorted: the
+// first bucket holds the values less than bounds[0], and the last the
+// values at least bounds[len(bounds)-1].
+func (w *Writer) Distribution(metricType string, bounds []float64) *Distribution {
+	b := append([]float64(nil), bounds...)
+	sort.Float64s(b)
+	return &Distribution{w: w, metricType: metricTypeOf(metricType), bounds: b}
+}
+
+// Record records a value in the time series of the labels, which may be nil.
+func (d *Distribution) Record(v float64, labels map[string]string) {
+	d.w.update(d.metricType, labels, metricpb.MetricDescriptor_CUMULATIVE, metricpb.MetricDescriptor_DISTRIBUTION, func(s *series) {
+		if s.dist == nil {
+			s.dist = &distribution{bounds: d.bounds, counts: make([]int64, len(d.bounds)+1)}
+		}
+		s.dist.record(v)
+	})
+}
+
+// distribution accumulates the values of a distribution time series.
+type distribution struct {
+	bounds []float64
+	counts []int64
+	count  int64
+	mean   float64
+	m2     float64 // the sum of squared deviations from the mean
+}
+
+func (d *distribution) record(v float64) {
+	// A value equal to a bound is in the bucket that starts there.
+	d.counts[sort.Search(len(d.bounds), func(i int) bool { return d.bounds[i] > v })]++
+	// Welford's algorithm.
+	d.count++
+	delta := v - d.mean
+	d.mean += delta / float64(d.count)
+	d.m2 += delta * (v - d.mean)
+}
+
+func (d *distribution) proto() *distributionpb.Distribution {
+	return &distributionpb.Distribution{
+		Count:                 d.count,
+		Mean:                  d.mean,
+		SumOfSquaredDeviation: d.m2,
+		BucketOptions: &distributionpb.Distribution_BucketOptions{
+			Options: &distributionpb.Distribution_BucketOptions_ExplicitBuckets{
+				ExplicitBuckets: &distributionpb.Distribution_BucketOptions_Explicit{Bounds: d.bounds},
+			},
+		},
+		BucketCounts: append([]int64(nil), d.counts...),
+	}
+}
@@ -0,0 +1,355 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metricwriter writes custom metrics to Cloud Monitoring with the
+// client in cloud.google.com/go/monitoring/apiv3/v2, for programs that don't
+// export them through OpenTelemetry.
+//
+// A Writer aggregates the values recorded by its counters, gauges and
+// distributions in memory, and writes them periodically with batched
+// CreateTimeSeries calls, writing each time series at most once every
+// MinSamplePeriod as Cloud Monitoring requires:
+//
+//	w, err := metricwriter.NewWriter(ctx, client, "my-project", nil)
+//	if err != nil {
+//		// TODO: Handle error.
+//	}
+//	defer w.Close(ctx)
+//	requests := w.Counter("custom.googleapis.com/myapp/requests")
+//	requests.Add(1, map[string]string{"method": "GET"})
+//
+// A metric type must be used with one kind of metric only.
+package metricwriter // import "cloud.google.com/go/monitoring/metricwriter"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	monitoring "cloud.google.com/go/monitoring/apiv3/v2"
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	gax "github.com/googleapis/gax-go/v2"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	"google.golang.org/genproto/googleapis/api/monitoredres"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// MinSamplePeriod is the minimum time between two points of a time
+	// series.
+	MinSamplePeriod = 10 * time.Second
+
+	// MaxTimeSeriesPerRequest is the maximum number of time series in a
+	// CreateTimeSeries request.
+	MaxTimeSeriesPerRequest = 200
+
+	defaultInterval = time.Minute
+
+	customPrefix = "custom.googleapis.com/"
+)
+
+// Config configures a Writer.
+type Config struct {
+	// Resource is the monitored resource of the time series. The default is
+	// the global resource of the project.
+	Resource *monitoredres.MonitoredResource
+
+	// Interval is how often the metrics are written. It must be at least
+	// MinSamplePeriod. The default is one minute.
+	Interval time.Duration
+
+	// OnError is called with the errors of the periodic writes. The default
+	// logs them.
+	OnError func(error)
+}
+
+// timeSeriesCreator is implemented by *monitoring.MetricClient.
+type timeSeriesCreator interface {
+	CreateTimeSeries(ctx context.Context, req *monitoringpb.CreateTimeSeriesRequest, opts ...gax.CallOption) error
+}
+
+// A Writer writes the values of its metrics to Cloud Monitoring
+// periodically. Its methods are safe for concurrent use.
+type Writer struct {
+	client   timeSeriesCreator
+	project  string
+	resource *monitoredres.MonitoredResource
+	onError  func(error)
+	now      func() time.Time
+	after    func(time.Duration) <-chan time.Time
+
+	mu     sync.Mutex
+	series map[string]*series
+	closed bool
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// series is a time series, identified by its metric type and labels.
+type series struct {
+	key        string
+	metricType string
+	labels     map[string]string
+	kind       metricpb.MetricDescriptor_MetricKind
+	valueType  metricpb.MetricDescriptor_ValueType
+	start      time.Time // the start of cumulative series
+	lastWrite  time.Time
+	dirty      bool // the value changed since it was last written
+
+	intValue    int64
+	doubleValue float64
+	dist        *distribution
+}
+
+// NewWriter returns a Writer of the metrics of a project, which writes them
+// every cfg.Interval until Close is called. The writes use ctx. cfg may be
+// nil.
+func NewWriter(ctx context.Context, client *monitoring.MetricClient, projectID string, cfg *Config) (*Writer, error) {
+	w, interval, err := newWriter(client, projectID, cfg)
+	if err != nil {
+		return nil, err
+	}
+	go w.run(ctx, interval)
+	return w, nil
+}
+
+func newWriter(client timeSeriesCreator, projectID string, cfg *Config) (*Writer, time.Duration, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	if projectID == "" {
+		return nil, 0, errors.New("metricwriter: project ID is required")
+	}
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = defaultInterval
+	}
+	if interval < MinSamplePeriod {
+		return nil, 0, fmt.Errorf("metricwriter: Interval %v is less than %v", interval, MinSamplePeriod)
+	}
+	w := &Writer{
+		client:   client,
+		project:  "projects/" + projectID,
+		resource: cfg.Resource,
+		onError:  cfg.OnError,
+		now:      time.Now,
+		after:    time.After,
+		series:   map[string]*series{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if w.resource == nil {
+		w.resource = &monitoredres.MonitoredResource{
+			Type:   "global",
+			Labels: map[string]string{"project_id": projectID},
+		}
+	}
+	if w.onError == nil {
+		w.onError = func(err error) { log.Printf("metricwriter: %v", err) }
+	}
+	return w, interval, nil
+}
+
+func (w *Writer) run(ctx context.Context, interval time.Duration) {
+	defer close(w.done)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if err := w.Flush(ctx); err != nil {
+				w.onError(err)
+			}
+		case <-w.stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// update calls f with the series of a metric type and labels, creating it if
+// needed, unless the Writer is closed.
+func (w *Writer) update(metricType string, labels map[string]string, kind metricpb.MetricDescriptor_MetricKind, valueType metricpb.MetricDescriptor_ValueType, f func(*series)) {
+	key := seriesKey(metricType, labels)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	s := w.series[key]
+	if s == nil {
+		l := make(map[string]string, len(labels))
+		for k, v := range labels {
+			l[k] = v
+		}
+		s = &series{
+			key:        key,
+			metricType: metricType,
+			labels:     l,
+			kind:       kind,
+			valueType:  valueType,
+			start:      w.now(),
+		}
+		w.series[key] = s
+	}
+	f(s)
+	s.dirty = true
+}
+
+func seriesKey(metricType string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(metricType)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\x00%s=%s", k, labels[k])
+	}
+	return b.String()
+}
+
+// metricTypeOf returns the metric type of name, adding the custom metric
+// prefix if it has no domain.
+func metricTypeOf(name string) string {
+	if i := strings.Index(name, "/"); i > 0 && strings.Contains(name[:i], ".") {
+		return name
+	}
+	return customPrefix + strings.TrimPrefix(name, "/")
+}
+
+// Flush writes the time series that changed since they were last written,
+// except those written less than MinSamplePeriod ago, which are written by a
+// later call. Time series whose write fails are written again by the next
+// call.
+func (w *Writer) Flush(ctx context.Context) error {
+	_, err := w.flush(ctx)
+	return err
+}
+
+// flush writes the due time series, and returns when the next of the others
+// is due, or zero if there are none.
+func (w *Writer) flush(ctx context.Context) (time.Time, error) {
+	w.mu.Lock()
+	now := w.now()
+	var due []*series
+	var next time.Time
+	for _, s := range w.series {
+		if !s.dirty {
+			continue
+		}
+		if t := s.lastWrite.Add(MinSamplePeriod); !s.lastWrite.IsZero() && now.Before(t) {
+			if next.IsZero() || t.Before(next) {
+				next = t
+			}
+			continue
+		}
+		due = append(due, s)
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].key < due[j].key })
+	ts := make([]*monitoringpb.TimeSeries, len(due))
+	prevWrites := make([]time.Time, len(due))
+	for i, s := range due {
+		ts[i] = w.timeSeries(s, now)
+		prevWrites[i] = s.lastWrite
+		s.dirty = false
+		s.lastWrite = now
+	}
+	w.mu.Unlock()
+
+	var errs []error
+	for start := 0; start < len(ts); start += MaxTimeSeriesPerRequest {
+		end := start + MaxTimeSeriesPerRequest
+		if end > len(ts) {
+			end = len(ts)
+		}
+		err := w.client.CreateTimeSeries(ctx, &monitoringpb.CreateTimeSeriesRequest{
+			Name:       w.project,
+			TimeSeries: ts[start:end],
+		})
+		if err == nil {
+			continue
+		}
+		errs = append(errs, err)
+		w.mu.Lock()
+		for i := start; i < end; i++ {
+			due[i].dirty = true
+			due[i].lastWrite = prevWrites[i]
+		}
+		w.mu.Unlock()
+	}
+	return next, errors.Join(errs...)
+}
+
+// timeSeries returns the point of s at now. It must be called with w.mu held.
+func (w *Writer) timeSeries(s *series, now time.Time) *monitoringpb.TimeSeries {
+	interval := &monitoringpb.TimeInterval{EndTime: timestamppb.New(now)}
+	if s.kind == metricpb.MetricDescriptor_CUMULATIVE {
+		// The end of a cumulative interval must be after its start.
+		if !now.After(s.start) {
+			interval.EndTime = timestamppb.New(s.start.Add(time.Millisecond))
+		}
+		interval.StartTime = timestamppb.New(s.start)
+	}
+	value := &monitoringpb.TypedValue{}
+	switch s.valueType {
+	case metricpb.MetricDescriptor_INT64:
+		value.Value = &monitoringpb.TypedValue_Int64Value{Int64Value: s.intValue}
+	case metricpb.MetricDescriptor_DOUBLE:
+		value.Value = &monitoringpb.TypedValue_DoubleValue{DoubleValue: s.doubleValue}
+	case metricpb.MetricDescriptor_DISTRIBUTION:
+		value.Value = &monitoringpb.TypedValue_DistributionValue{DistributionValue: s.dist.proto()}
+	}
+	return &monitoringpb.TimeSeries{
+		Metric:     &metricpb.Metric{Type: s.metricType, Labels: s.labels},
+		Resource:   w.resource,
+		MetricKind: s.kind,
+		ValueType:  s.valueType,
+		Points:     []*monitoringpb.Point{{Interval: interval, Value: value}},
+	}
+}
+
+// Close stops the periodic writes and writes the time series that changed
+// since they were last written, waiting for those written less than
+// MinSamplePeriod ago to be due, unless ctx is done first. Values recorded
+// after Close are dropped.
+func (w *Writer) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return errors.New("metricwriter: Writer is closed")
+	}
+	w.closed = true
+	w.mu.Unlock()
+	close(w.stop)
+	<-w.done
+	for {
+		next, err := w.flush(ctx)
+		if err != nil || next.IsZero() {
+			return err
+		}
+		select {
+		case <-w.after(next.Sub(w.now())):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
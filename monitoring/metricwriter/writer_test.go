@@ -0,0 +1,248 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricwriter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	gax "github.com/googleapis/gax-go/v2"
+	distributionpb "google.golang.org/genproto/googleapis/api/distribution"
+	"google.golang.org/protobuf/proto"
+)
+
+type fakeCreator struct {
+	mu       sync.Mutex
+	requests []*monitoringpb.CreateTimeSeriesRequest
+	fail     bool
+}
+
+func (f *fakeCreator) CreateTimeSeries(ctx context.Context, req *monitoringpb.CreateTimeSeriesRequest, _ ...gax.CallOption) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail {
+		return errors.New("write failed")
+	}
+	f.requests = append(f.requests, req)
+	return nil
+}
+
+// take returns the time series written since the last call.
+func (f *fakeCreator) take() []*monitoringpb.TimeSeries {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var ts []*monitoringpb.TimeSeries
+	for _, r := range f.requests {
+		ts = append(ts, r.TimeSeries...)
+	}
+	f.requests = nil
+	return ts
+}
+
+// fakeClock is the time of a Writer, which moves forward only when the
+// Writer waits.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.Advance(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.Now()
+	return ch
+}
+
+func newTestWriter(t *testing.T, f *fakeCreator) (*Writer, *fakeClock) {
+	t.Helper()
+	w, _, err := newWriter(f, "p", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	w.now = clock.Now
+	w.after = clock.After
+	go w.run(context.Background(), time.Hour)
+	return w, clock
+}
+
+func TestWriter(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeCreator{}
+	w, clock := newTestWriter(t, f)
+	start := clock.Now()
+
+	c := w.Counter("myapp/requests")
+	c.Add(1, map[string]string{"method": "GET"})
+	c.Add(2, map[string]string{"method": "GET"})
+	c.Add(5, map[string]string{"method": "POST"})
+	g := w.Gauge("example.com/queue_size")
+	g.Set(3, nil)
+	g.Set(4, nil)
+	d := w.Distribution("myapp/latency", []float64{10, 1})
+	for _, v := range []float64{0.5, 1, 2, 3, 20} {
+		d.Record(v, nil)
+	}
+	clock.Advance(time.Second)
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	ts := f.take()
+	var got []string
+	for _, s := range ts {
+		p := s.Points[0]
+		startTime := "none"
+		if p.Interval.StartTime != nil {
+			startTime = p.Interval.StartTime.AsTime().Sub(start).String()
+		}
+		got = append(got, fmt.Sprintf("%s %v %s %s %s", s.Metric.Type, s.Metric.Labels, s.MetricKind, s.ValueType, startTime))
+		if !p.Interval.EndTime.AsTime().Equal(start.Add(time.Second)) {
+			t.Errorf("%s: got end time %v", s.Metric.Type, p.Interval.EndTime.AsTime())
+		}
+		if s.Resource.Type != "global" || s.Resource.Labels["project_id"] != "p" {
+			t.Errorf("%s: got resource %v", s.Metric.Type, s.Resource)
+		}
+	}
+	want := []string{
+		"custom.googleapis.com/myapp/latency map[] CUMULATIVE DISTRIBUTION 0s",
+		"custom.googleapis.com/myapp/requests map[method:GET] CUMULATIVE INT64 0s",
+		"custom.googleapis.com/myapp/requests map[method:POST] CUMULATIVE INT64 0s",
+		"example.com/queue_size map[] GAUGE DOUBLE none",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got time series\n%q\nwant\n%q", got, want)
+	}
+	wantDist := &distributionpb.Distribution{
+		Count:                 5,
+		Mean:                  5.3,
+		SumOfSquaredDeviation: 273.8,
+		BucketOptions: &distributionpb.Distribution_BucketOptions{
+			Options: &distributionpb.Distribution_BucketOptions_ExplicitBuckets{
+				ExplicitBuckets: &distributionpb.Distribution_BucketOptions_Explicit{Bounds: []float64{1, 10}},
+			},
+		},
+		BucketCounts: []int64{1, 3, 1},
+	}
+	gotDist := ts[0].Points[0].Value.GetDistributionValue()
+	// Compare the floats approximately.
+	if math.Abs(gotDist.Mean-wantDist.Mean) > 1e-9 || math.Abs(gotDist.SumOfSquaredDeviation-wantDist.SumOfSquaredDeviation) > 1e-9 {
+		t.Errorf("got mean %v and sum of squared deviation %v, want %v and %v",
+			gotDist.Mean, gotDist.SumOfSquaredDeviation, wantDist.Mean, wantDist.SumOfSquaredDeviation)
+	}
+	gotDist.Mean, gotDist.SumOfSquaredDeviation = wantDist.Mean, wantDist.SumOfSquaredDeviation
+	if !proto.Equal(gotDist, wantDist) {
+		t.Errorf("got distribution %v, want %v", gotDist, wantDist)
+	}
+	if got := ts[1].Points[0].Value.GetInt64Value(); got != 3 {
+		t.Errorf("got count %d, want 3", got)
+	}
+	if got := ts[3].Points[0].Value.GetDoubleValue(); got != 4 {
+		t.Errorf("got gauge %v, want 4", got)
+	}
+
+	// Unchanged series aren't written, and changed ones wait for
+	// MinSamplePeriod.
+	c.Add(1, map[string]string{"method": "GET"})
+	clock.Advance(MinSamplePeriod - time.Second)
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ts := f.take(); len(ts) != 0 {
+		t.Fatalf("got %d time series, want none", len(ts))
+	}
+	clock.Advance(time.Second)
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	ts = f.take()
+	if len(ts) != 1 || ts[0].Points[0].Value.GetInt64Value() != 4 {
+		t.Fatalf("got %v, want the GET counter at 4", ts)
+	}
+	if got := ts[0].Points[0].Interval.StartTime.AsTime(); !got.Equal(start) {
+		t.Errorf("got start time %v, want %v", got, start)
+	}
+}
+
+func TestWriterRetriesFailedWrites(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeCreator{fail: true}
+	w, clock := newTestWriter(t, f)
+	g := w.Gauge("g")
+	for i := 0; i < MaxTimeSeriesPerRequest+1; i++ {
+		g.Set(1, map[string]string{"i": fmt.Sprint(i)})
+	}
+	if err := w.Flush(ctx); err == nil {
+		t.Fatal("got nil, want error")
+	}
+	// The failed series are written at once by the next flush.
+	f.fail = false
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(f.requests))
+	}
+	if n := len(f.take()); n != MaxTimeSeriesPerRequest+1 {
+		t.Fatalf("got %d time series, want %d", n, MaxTimeSeriesPerRequest+1)
+	}
+
+	// Close waits for the series written recently.
+	g.Set(2, map[string]string{"i": "0"})
+	before := clock.Now()
+	if err := w.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := clock.Now().Sub(before); got != MinSamplePeriod {
+		t.Errorf("Close waited %v, want %v", got, MinSamplePeriod)
+	}
+	ts := f.take()
+	if len(ts) != 1 || ts[0].Points[0].Value.GetDoubleValue() != 2 {
+		t.Errorf("got %v, want the gauge at 2", ts)
+	}
+	g.Set(3, nil)
+	if err := w.Close(ctx); err == nil {
+		t.Error("got nil, want error closing twice")
+	}
+	if len(w.series) != MaxTimeSeriesPerRequest+1 {
+		t.Error("a value was recorded after Close")
+	}
+}
+
+func TestNewWriterErrors(t *testing.T) {
+	if _, _, err := newWriter(&fakeCreator{}, "", nil); err == nil {
+		t.Error("got nil, want error for the project")
+	}
+	if _, _, err := newWriter(&fakeCreator{}, "p", &Config{Interval: time.Second}); err == nil {
+		t.Error("got nil, want error for the interval")
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profiler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "google.golang.org/genproto/googleapis/devtools/cloudprofiler/v2"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ProfileType is a type of profile that can be captured with CaptureProfile.
+type ProfileType int
+
+const (
+	// CPUProfile is a CPU profile.
+	CPUProfile ProfileType = iota + 1
+	// HeapProfile is a profile of the in-use heap.
+	HeapProfile
+	// HeapAllocProfile is a profile of the heap allocations.
+	HeapAllocProfile
+	// GoroutineProfile is a profile of the goroutines, shown as a "threads"
+	// profile in the profiler UI.
+	GoroutineProfile
+	// MutexProfile is a profile of mutex contention, shown as a "contention"
+	// profile in the profiler UI. It requires Config.MutexProfiling.
+	MutexProfile
+)
+
+var profileTypeProtos = map[ProfileType]pb.ProfileType{
+	CPUProfile:       pb.ProfileType_CPU,
+	HeapProfile:      pb.ProfileType_HEAP,
+	HeapAllocProfile: pb.ProfileType_HEAP_ALLOC,
+	GoroutineProfile: pb.ProfileType_THREADS,
+	MutexProfile:     pb.ProfileType_CONTENTION,
+}
+
+var (
+	agentMu     sync.Mutex
+	activeAgent *agent // set by Start
+)
+
+func setActiveAgent(a *agent) {
+	agentMu.Lock()
+	defer agentMu.Unlock()
+	activeAgent = a
+}
+
+// CaptureProfile collects a profile of the given type and uploads it
+// immediately, outside of the schedule of the profiling agent. It can be used
+// to capture profiles during an incident. Start must have been called
+// successfully before.
+//
+// CPU, heap allocation and mutex profiles cover the given duration, and
+// CaptureProfile blocks until it has elapsed; the duration is ignored for
+// other profile types. Profile types disabled in the Config can be captured,
+// except for mutex profiles, which require Config.MutexProfiling.
+//
+// Only one CPU profile can be collected at a time, so CaptureProfile returns
+// an error if the agent is collecting one.
+func CaptureProfile(ctx context.Context, pt ProfileType, duration time.Duration) error {
+	agentMu.Lock()
+	a := activeAgent
+	agentMu.Unlock()
+	if a == nil {
+		return errors.New("profiler: CaptureProfile called before Start")
+	}
+	ppt, ok := profileTypeProtos[pt]
+	if !ok {
+		return fmt.Errorf("profiler: unknown profile type %d", pt)
+	}
+	if pt == MutexProfile && !mutexEnabled {
+		return errors.New("profiler: mutex profiling is not enabled; set Config.MutexProfiling")
+	}
+	var d *durationpb.Duration
+	switch pt {
+	case CPUProfile, HeapAllocProfile, MutexProfile:
+		if duration <= 0 {
+			return fmt.Errorf("profiler: duration must be positive, got %v", duration)
+		}
+		d = durationpb.New(duration)
+	}
+	return a.captureAndUpload(withXGoogHeader(ctx), ppt, d)
+}
+
+// captureAndUpload collects a profile of type pt and uploads it as an offline
+// profile.
+func (a *agent) captureAndUpload(ctx context.Context, pt pb.ProfileType, duration *durationpb.Duration) error {
+	var prof bytes.Buffer
+	if err := collectProfile(ctx, pt, duration.AsDuration(), &prof); err != nil {
+		return fmt.Errorf("profiler: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		// The profile was cut short.
+		return err
+	}
+	req := &pb.CreateOfflineProfileRequest{
+		Parent: "projects/" + a.deployment.ProjectId,
+		Profile: &pb.Profile{
+			ProfileType:  pt,
+			Deployment:   a.deployment,
+			Duration:     duration,
+			ProfileBytes: prof.Bytes(),
+			Labels:       a.profileLabels,
+		},
+	}
+	debugLog("uploading on-demand %v profile", pt)
+	if _, err := a.client.CreateOfflineProfile(ctx, req); err != nil {
+		return fmt.Errorf("profiler: uploading profile: %w", err)
+	}
+	return nil
+}
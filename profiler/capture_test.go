@@ -0,0 +1,108 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profiler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/profiler/mocks"
+	"cloud.google.com/go/profiler/testdata"
+	"github.com/golang/mock/gomock"
+	pb "google.golang.org/genproto/googleapis/devtools/cloudprofiler/v2"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestCaptureProfile(t *testing.T) {
+	oldStartCPUProfile, oldStopCPUProfile, oldWriteHeapProfile, oldSleep, oldMutexEnabled := startCPUProfile, stopCPUProfile, writeHeapProfile, sleep, mutexEnabled
+	defer func() {
+		startCPUProfile, stopCPUProfile, writeHeapProfile, sleep, mutexEnabled = oldStartCPUProfile, oldStopCPUProfile, oldWriteHeapProfile, oldSleep, oldMutexEnabled
+		setActiveAgent(nil)
+	}()
+	ctx := context.Background()
+
+	if err := CaptureProfile(ctx, HeapProfile, 0); err == nil {
+		t.Error("got nil, want error before Start")
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mpc := mocks.NewMockProfilerServiceClient(ctrl)
+	a := createTestAgent(mpc)
+	setActiveAgent(a)
+
+	var heapCollected, heapUploaded bytes.Buffer
+	testdata.HeapProfileCollected1.Write(&heapCollected)
+	testdata.HeapProfileUploaded.Write(&heapUploaded)
+	writeHeapProfile = func(w io.Writer) error {
+		_, err := w.Write(heapCollected.Bytes())
+		return err
+	}
+	startCPUProfile = func(w io.Writer) error {
+		_, err := w.Write([]byte{1})
+		return err
+	}
+	stopCPUProfile = func() {}
+	var gotSleep time.Duration
+	sleep = func(_ context.Context, d time.Duration) error {
+		gotSleep = d
+		return nil
+	}
+
+	for _, test := range []struct {
+		pt           ProfileType
+		duration     time.Duration
+		wantType     pb.ProfileType
+		wantDuration *durationpb.Duration
+		wantBytes    []byte
+	}{
+		{HeapProfile, time.Minute, pb.ProfileType_HEAP, nil, heapUploaded.Bytes()},
+		{CPUProfile, 5 * time.Second, pb.ProfileType_CPU, durationpb.New(5 * time.Second), []byte{1}},
+	} {
+		gotSleep = 0
+		mpc.EXPECT().CreateOfflineProfile(gomock.Any(), gomock.Eq(&pb.CreateOfflineProfileRequest{
+			Parent: "projects/" + testProjectID,
+			Profile: &pb.Profile{
+				ProfileType:  test.wantType,
+				Deployment:   a.deployment,
+				Duration:     test.wantDuration,
+				ProfileBytes: test.wantBytes,
+				Labels:       a.profileLabels,
+			},
+		})).Times(1).Return(&pb.Profile{}, nil)
+		if err := CaptureProfile(ctx, test.pt, test.duration); err != nil {
+			t.Fatalf("%v: %v", test.wantType, err)
+		}
+		if want := test.wantDuration.AsDuration(); gotSleep != want {
+			t.Errorf("%v: slept for %v, want %v", test.wantType, gotSleep, want)
+		}
+	}
+
+	mutexEnabled = false
+	if err := CaptureProfile(ctx, MutexProfile, time.Second); err == nil {
+		t.Error("got nil, want error for a mutex profile without Config.MutexProfiling")
+	}
+	if err := CaptureProfile(ctx, CPUProfile, 0); err == nil {
+		t.Error("got nil, want error for a CPU profile without duration")
+	}
+	startCPUProfile = func(io.Writer) error { return errors.New("cpu profiling already in use") }
+	if err := CaptureProfile(ctx, CPUProfile, time.Second); err == nil {
+		t.Error("got nil, want error when a CPU profile is being collected")
+	}
+}
@@ -137,6 +137,11 @@ type Config struct {
 	// MutexProfiling enables mutex profiling. It defaults to false.
 	// Note that mutex profiling is not supported by Go versions older
 	// than Go 1.8.
+	//
+	// Start sets the fraction of the mutex contention events that are
+	// profiled to 1/100. Call runtime.SetMutexProfileFraction after Start
+	// to sample more finely while investigating an issue; the new rate
+	// applies to the scheduled profiles and to CaptureProfile.
 	MutexProfiling bool
 
 	// When true, collecting the CPU profiles is disabled.
@@ -266,6 +271,7 @@ func start(cfg Config, options ...option.ClientOption) error {
 		debugLog("failed to start the profiling agent: %v", err)
 		return err
 	}
+	setActiveAgent(a)
 	go pollProfilerService(withXGoogHeader(ctx), a)
 	return nil
 }
@@ -382,51 +388,52 @@ func (a *agent) profileAndUpload(ctx context.Context, p *pb.Profile) {
 		return
 	}
 
+	if err := collectProfile(ctx, pt, p.Duration.AsDuration(), &prof); err != nil {
+		debugLog("%v", err)
+		return
+	}
+
+	p.ProfileBytes = prof.Bytes()
+	p.Labels = a.profileLabels
+	req := pb.UpdateProfileRequest{Profile: p}
+
+	// Upload profile, discard profile in case of error.
+	debugLog("start uploading profile")
+	if _, err := a.client.UpdateProfile(ctx, &req); err != nil {
+		debugLog("failed to upload profile: %v", err)
+	}
+}
+
+// collectProfile writes a profile of type pt to prof. CPU, allocation and
+// contention profiles cover the given duration.
+func collectProfile(ctx context.Context, pt pb.ProfileType, duration time.Duration, prof *bytes.Buffer) error {
 	switch pt {
 	case pb.ProfileType_CPU:
-		duration := p.Duration.AsDuration()
-		if err := startCPUProfile(&prof); err != nil {
-			debugLog("failed to start CPU profile: %v", err)
-			return
+		if err := startCPUProfile(prof); err != nil {
+			return fmt.Errorf("failed to start CPU profile: %w", err)
 		}
 		sleep(ctx, duration)
 		stopCPUProfile()
 	case pb.ProfileType_HEAP:
-		if err := heapProfile(&prof); err != nil {
-			debugLog("failed to write heap profile: %v", err)
-			return
+		if err := heapProfile(prof); err != nil {
+			return fmt.Errorf("failed to write heap profile: %w", err)
 		}
 	case pb.ProfileType_HEAP_ALLOC:
-		duration := p.Duration.AsDuration()
-		if err := deltaAllocProfile(ctx, duration, config.AllocForceGC, &prof); err != nil {
-			debugLog("failed to collect allocation profile: %v", err)
-			return
+		if err := deltaAllocProfile(ctx, duration, config.AllocForceGC, prof); err != nil {
+			return fmt.Errorf("failed to collect allocation profile: %w", err)
 		}
 	case pb.ProfileType_THREADS:
-		if err := pprof.Lookup("goroutine").WriteTo(&prof, 0); err != nil {
-			debugLog("failed to collect goroutine profile: %v", err)
-			return
+		if err := pprof.Lookup("goroutine").WriteTo(prof, 0); err != nil {
+			return fmt.Errorf("failed to collect goroutine profile: %w", err)
 		}
 	case pb.ProfileType_CONTENTION:
-		duration := p.Duration.AsDuration()
-		if err := deltaMutexProfile(ctx, duration, &prof); err != nil {
-			debugLog("failed to collect mutex profile: %v", err)
-			return
+		if err := deltaMutexProfile(ctx, duration, prof); err != nil {
+			return fmt.Errorf("failed to collect mutex profile: %w", err)
 		}
 	default:
-		debugLog("unexpected profile type: %v", pt)
-		return
-	}
-
-	p.ProfileBytes = prof.Bytes()
-	p.Labels = a.profileLabels
-	req := pb.UpdateProfileRequest{Profile: p}
-
-	// Upload profile, discard profile in case of error.
-	debugLog("start uploading profile")
-	if _, err := a.client.UpdateProfile(ctx, &req); err != nil {
-		debugLog("failed to upload profile: %v", err)
+		return fmt.Errorf("unexpected profile type: %v", pt)
 	}
+	return nil
 }
 
 // deltaMutexProfile writes mutex profile changes over a time period specified
@@ -949,6 +949,7 @@ func TestAgentWithServer(t *testing.T) {
 	oldDialGRPC, oldConfig, oldProfilingDone := dialGRPC, config, profilingDone
 	defer func() {
 		dialGRPC, config, profilingDone = oldDialGRPC, oldConfig, oldProfilingDone
+		setActiveAgent(nil)
 	}()
 
 	profilingDone = make(chan bool)
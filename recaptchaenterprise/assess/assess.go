@@ -0,0 +1,255 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package assess decides whether to allow the requests of a web backend
+// from their reCAPTCHA tokens, with the client in
+// cloud.google.com/go/recaptchaenterprise/v2/apiv1.
+//
+// A Checker creates an assessment of a token and evaluates it against a
+// Policy:
+//
+//	checker := assess.NewChecker(client, assess.Config{
+//		Project: "my-project",
+//		SiteKey: "my-site-key",
+//		Policy:  assess.Policy{MinScore: 0.5},
+//	})
+//	d, err := checker.Check(ctx, token, "login", nil)
+//	if err != nil {
+//		// TODO: Handle error.
+//	}
+//	if !d.Allowed() {
+//		// TODO: Reject the request, because of d.Verdict.
+//	}
+package assess // import "cloud.google.com/go/recaptchaenterprise/v2/assess"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	recaptchaenterprise "cloud.google.com/go/recaptchaenterprise/v2/apiv1"
+	"cloud.google.com/go/recaptchaenterprise/v2/apiv1/recaptchaenterprisepb"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/protobuf/proto"
+)
+
+// A Policy decides which assessments are allowed.
+type Policy struct {
+	// MinScore is the minimum score of an allowed assessment, from 0.0,
+	// which is very likely a bot, to 1.0, which is very likely a human. The
+	// default, 0, allows any score.
+	MinScore float32
+
+	// DenyReasons are the reasons of the risk analysis that deny an
+	// assessment whatever its score.
+	DenyReasons []recaptchaenterprisepb.RiskAnalysis_ClassificationReason
+
+	// AllowReasons, if not nil, are the only reasons of the risk analysis
+	// that an allowed assessment may have.
+	AllowReasons []recaptchaenterprisepb.RiskAnalysis_ClassificationReason
+}
+
+// A Verdict is the outcome of the evaluation of an assessment.
+type Verdict int
+
+const (
+	// Allow means that the assessment is allowed.
+	Allow Verdict = iota
+
+	// InvalidToken means that the token is invalid, for example because it
+	// expired or was already used.
+	InvalidToken
+
+	// ActionMismatch means that the action of the token isn't the expected
+	// one.
+	ActionMismatch
+
+	// LowScore means that the score is lower than the minimum of the
+	// policy. It is a guess of the risk analysis, not a confirmed fraud, so
+	// it should not be reported as fraudulent with Checker.Annotate.
+	LowScore
+
+	// DeniedReason means that the risk analysis has a reason that the
+	// policy denies.
+	DeniedReason
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case Allow:
+		return "allow"
+	case InvalidToken:
+		return "invalid token"
+	case ActionMismatch:
+		return "action mismatch"
+	case LowScore:
+		return "low score"
+	case DeniedReason:
+		return "denied reason"
+	default:
+		return fmt.Sprintf("Verdict(%d)", int(v))
+	}
+}
+
+// A Decision is the decision of a policy about an assessment.
+type Decision struct {
+	// Verdict is the outcome of the evaluation.
+	Verdict Verdict
+
+	// Score is the score of the risk analysis.
+	Score float32
+
+	// Reason is the reason that denied the assessment, if Verdict is
+	// DeniedReason.
+	Reason recaptchaenterprisepb.RiskAnalysis_ClassificationReason
+
+	// InvalidReason is the reason that the token is invalid, if Verdict is
+	// InvalidToken.
+	InvalidReason recaptchaenterprisepb.TokenProperties_InvalidReason
+
+	// Assessment is the assessment.
+	Assessment *recaptchaenterprisepb.Assessment
+}
+
+// Allowed reports whether the assessment is allowed.
+func (d *Decision) Allowed() bool {
+	return d.Verdict == Allow
+}
+
+// Evaluate evaluates an assessment, whose token is expected to be for
+// action, or for any action if action is empty.
+func (p *Policy) Evaluate(a *recaptchaenterprisepb.Assessment, action string) *Decision {
+	d := &Decision{Assessment: a, Score: a.GetRiskAnalysis().GetScore()}
+	tp := a.GetTokenProperties()
+	switch {
+	case !tp.GetValid():
+		d.Verdict = InvalidToken
+		d.InvalidReason = tp.GetInvalidReason()
+	case action != "" && tp.GetAction() != action:
+		d.Verdict = ActionMismatch
+	default:
+		for _, r := range a.GetRiskAnalysis().GetReasons() {
+			if contains(p.DenyReasons, r) || (p.AllowReasons != nil && !contains(p.AllowReasons, r)) {
+				d.Verdict = DeniedReason
+				d.Reason = r
+				return d
+			}
+		}
+		if d.Score < p.MinScore {
+			d.Verdict = LowScore
+		}
+	}
+	return d
+}
+
+func contains(rs []recaptchaenterprisepb.RiskAnalysis_ClassificationReason, r recaptchaenterprisepb.RiskAnalysis_ClassificationReason) bool {
+	for _, x := range rs {
+		if x == r {
+			return true
+		}
+	}
+	return false
+}
+
+// Config configures a Checker.
+type Config struct {
+	// Project is the ID of the project of the assessments. Required.
+	Project string
+
+	// SiteKey is the key of the site that the tokens are for. Required.
+	SiteKey string
+
+	// Policy is the policy that evaluates the assessments.
+	Policy Policy
+
+	// Annotate enables the annotation of the assessments by Check: allowed
+	// assessments are annotated as legitimate, and those denied because of
+	// a reason of DenyReasons or AllowReasons as fraudulent. Assessments
+	// denied with LowScore are not annotated, because a low score is not
+	// evidence of fraud; annotating them as fraudulent would train the model
+	// on its own guesses. Use Checker.Annotate to report the outcomes that
+	// are confirmed later instead.
+	Annotate bool
+}
+
+// assessmentClient is implemented by *recaptchaenterprise.Client.
+type assessmentClient interface {
+	CreateAssessment(ctx context.Context, req *recaptchaenterprisepb.CreateAssessmentRequest, opts ...gax.CallOption) (*recaptchaenterprisepb.Assessment, error)
+	AnnotateAssessment(ctx context.Context, req *recaptchaenterprisepb.AnnotateAssessmentRequest, opts ...gax.CallOption) (*recaptchaenterprisepb.AnnotateAssessmentResponse, error)
+}
+
+// A Checker checks tokens. It is safe for concurrent use.
+type Checker struct {
+	client assessmentClient
+	cfg    Config
+}
+
+// NewChecker returns a Checker that uses client.
+func NewChecker(client *recaptchaenterprise.Client, cfg Config) *Checker {
+	return &Checker{client: client, cfg: cfg}
+}
+
+// Check creates an assessment of a token, which is expected to be for
+// action, or for any action if action is empty, and evaluates it against
+// the policy. The event, if not nil, holds more information about the
+// request, such as the user agent and IP address of the user; its token,
+// site key and expected action are set by Check.
+//
+// If Config.Annotate is set, the assessment is then annotated as described
+// there. If only the annotation fails, Check returns the decision with the
+// error.
+func (c *Checker) Check(ctx context.Context, token, action string, event *recaptchaenterprisepb.Event) (*Decision, error) {
+	if c.cfg.Project == "" || c.cfg.SiteKey == "" {
+		return nil, errors.New("assess: Config.Project and Config.SiteKey are required")
+	}
+	ev := &recaptchaenterprisepb.Event{}
+	if event != nil {
+		ev = proto.Clone(event).(*recaptchaenterprisepb.Event)
+	}
+	ev.Token = token
+	ev.SiteKey = c.cfg.SiteKey
+	ev.ExpectedAction = action
+	a, err := c.client.CreateAssessment(ctx, &recaptchaenterprisepb.CreateAssessmentRequest{
+		Parent:     "projects/" + c.cfg.Project,
+		Assessment: &recaptchaenterprisepb.Assessment{Event: ev},
+	})
+	if err != nil {
+		return nil, err
+	}
+	d := c.cfg.Policy.Evaluate(a, action)
+	if !c.cfg.Annotate {
+		return d, nil
+	}
+	switch d.Verdict {
+	case Allow:
+		return d, c.Annotate(ctx, d, recaptchaenterprisepb.AnnotateAssessmentRequest_LEGITIMATE)
+	case DeniedReason:
+		return d, c.Annotate(ctx, d, recaptchaenterprisepb.AnnotateAssessmentRequest_FRAUDULENT)
+	}
+	return d, nil
+}
+
+// Annotate annotates the assessment of a decision, for example as
+// legitimate once the user completed a purchase, or as fraudulent once a
+// chargeback confirmed the fraud.
+func (c *Checker) Annotate(ctx context.Context, d *Decision, annotation recaptchaenterprisepb.AnnotateAssessmentRequest_Annotation) error {
+	name := d.Assessment.GetName()
+	if _, err := c.client.AnnotateAssessment(ctx, &recaptchaenterprisepb.AnnotateAssessmentRequest{
+		Name:       name,
+		Annotation: annotation,
+	}); err != nil {
+		return fmt.Errorf("assess: annotating %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assess
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "cloud.google.com/go/recaptchaenterprise/v2/apiv1/recaptchaenterprisepb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+func assessment(valid bool, action string, score float32, reasons ...pb.RiskAnalysis_ClassificationReason) *pb.Assessment {
+	return &pb.Assessment{
+		Name:            "projects/p/assessments/a1",
+		TokenProperties: &pb.TokenProperties{Valid: valid, Action: action},
+		RiskAnalysis:    &pb.RiskAnalysis{Score: score, Reasons: reasons},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	p := &Policy{
+		MinScore:     0.5,
+		DenyReasons:  []pb.RiskAnalysis_ClassificationReason{pb.RiskAnalysis_AUTOMATION},
+		AllowReasons: []pb.RiskAnalysis_ClassificationReason{pb.RiskAnalysis_AUTOMATION, pb.RiskAnalysis_LOW_CONFIDENCE_SCORE},
+	}
+	for _, test := range []struct {
+		a          *pb.Assessment
+		action     string
+		want       Verdict
+		wantReason pb.RiskAnalysis_ClassificationReason
+	}{
+		{assessment(true, "login", 0.9), "login", Allow, 0},
+		{assessment(true, "login", 0.9), "", Allow, 0},
+		{assessment(false, "login", 0.9), "login", InvalidToken, 0},
+		{assessment(true, "signup", 0.9), "login", ActionMismatch, 0},
+		{assessment(true, "login", 0.3), "login", LowScore, 0},
+		{assessment(true, "login", 0.5, pb.RiskAnalysis_LOW_CONFIDENCE_SCORE), "login", Allow, 0},
+		{assessment(true, "login", 0.9, pb.RiskAnalysis_AUTOMATION), "login", DeniedReason, pb.RiskAnalysis_AUTOMATION},
+		{assessment(true, "login", 0.9, pb.RiskAnalysis_TOO_MUCH_TRAFFIC), "login", DeniedReason, pb.RiskAnalysis_TOO_MUCH_TRAFFIC},
+	} {
+		d := p.Evaluate(test.a, test.action)
+		if d.Verdict != test.want || d.Reason != test.wantReason {
+			t.Errorf("%v, %q: got %v (%v), want %v (%v)", test.a.RiskAnalysis, test.action, d.Verdict, d.Reason, test.want, test.wantReason)
+		}
+		if d.Allowed() != (test.want == Allow) {
+			t.Errorf("%v: got Allowed() = %t", test.a.RiskAnalysis, d.Allowed())
+		}
+	}
+}
+
+type fakeClient struct {
+	assessment  *pb.Assessment
+	created     *pb.CreateAssessmentRequest
+	annotated   *pb.AnnotateAssessmentRequest
+	annotateErr error
+}
+
+func (f *fakeClient) CreateAssessment(_ context.Context, req *pb.CreateAssessmentRequest, _ ...gax.CallOption) (*pb.Assessment, error) {
+	f.created = req
+	return f.assessment, nil
+}
+
+func (f *fakeClient) AnnotateAssessment(_ context.Context, req *pb.AnnotateAssessmentRequest, _ ...gax.CallOption) (*pb.AnnotateAssessmentResponse, error) {
+	f.annotated = req
+	return &pb.AnnotateAssessmentResponse{}, f.annotateErr
+}
+
+func TestCheck(t *testing.T) {
+	policy := Policy{MinScore: 0.5, DenyReasons: []pb.RiskAnalysis_ClassificationReason{pb.RiskAnalysis_AUTOMATION}}
+	for _, test := range []struct {
+		a        *pb.Assessment
+		annotate bool
+		want     pb.AnnotateAssessmentRequest_Annotation
+	}{
+		{assessment(true, "login", 0.9), false, pb.AnnotateAssessmentRequest_ANNOTATION_UNSPECIFIED},
+		{assessment(true, "login", 0.9, pb.RiskAnalysis_AUTOMATION), false, pb.AnnotateAssessmentRequest_ANNOTATION_UNSPECIFIED},
+		{assessment(true, "login", 0.9), true, pb.AnnotateAssessmentRequest_LEGITIMATE},
+		{assessment(true, "login", 0.9, pb.RiskAnalysis_AUTOMATION), true, pb.AnnotateAssessmentRequest_FRAUDULENT},
+		{assessment(true, "login", 0.1), true, pb.AnnotateAssessmentRequest_ANNOTATION_UNSPECIFIED},
+		{assessment(false, "", 0), true, pb.AnnotateAssessmentRequest_ANNOTATION_UNSPECIFIED},
+	} {
+		f := &fakeClient{assessment: test.a}
+		c := &Checker{client: f, cfg: Config{Project: "p", SiteKey: "key", Policy: policy, Annotate: test.annotate}}
+		event := &pb.Event{UserAgent: "ua", UserIpAddress: "1.2.3.4"}
+		if _, err := c.Check(context.Background(), "tok", "login", event); err != nil {
+			t.Fatal(err)
+		}
+		ev := f.created.Assessment.Event
+		if f.created.Parent != "projects/p" || ev.Token != "tok" || ev.SiteKey != "key" || ev.ExpectedAction != "login" || ev.UserAgent != "ua" {
+			t.Errorf("got request %v", f.created)
+		}
+		if event.Token != "" {
+			t.Error("the event was modified")
+		}
+		if got := f.annotated.GetAnnotation(); got != test.want {
+			t.Errorf("%v, annotate %t: got annotation %v, want %v", test.a.RiskAnalysis, test.annotate, got, test.want)
+		}
+		if f.annotated != nil && f.annotated.Name != test.a.Name {
+			t.Errorf("annotated %q, want %q", f.annotated.Name, test.a.Name)
+		}
+	}
+}
+
+func TestCheckAnnotationError(t *testing.T) {
+	errAnnotate := errors.New("annotate")
+	f := &fakeClient{assessment: assessment(true, "login", 0.9), annotateErr: errAnnotate}
+	c := &Checker{client: f, cfg: Config{Project: "p", SiteKey: "key", Annotate: true}}
+	d, err := c.Check(context.Background(), "tok", "login", nil)
+	if !errors.Is(err, errAnnotate) {
+		t.Errorf("got error %v, want %v", err, errAnnotate)
+	}
+	if d == nil || !d.Allowed() {
+		t.Errorf("got decision %v, want an allowed decision", d)
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	f := &fakeClient{assessment: assessment(true, "login", 0.1)}
+	c := &Checker{client: f, cfg: Config{Project: "p", SiteKey: "key", Policy: Policy{MinScore: 0.5}}}
+	d, err := c.Check(context.Background(), "tok", "login", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Annotate(context.Background(), d, pb.AnnotateAssessmentRequest_LEGITIMATE); err != nil {
+		t.Fatal(err)
+	}
+	if f.annotated.GetName() != d.Assessment.Name || f.annotated.GetAnnotation() != pb.AnnotateAssessmentRequest_LEGITIMATE {
+		t.Errorf("got annotation request %v", f.annotated)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assess_test
+
+import (
+	"context"
+	"net/http"
+
+	recaptchaenterprise "cloud.google.com/go/recaptchaenterprise/v2/apiv1"
+	"cloud.google.com/go/recaptchaenterprise/v2/apiv1/recaptchaenterprisepb"
+	"cloud.google.com/go/recaptchaenterprise/v2/assess"
+)
+
+func ExampleChecker_Check() {
+	ctx := context.Background()
+	client, err := recaptchaenterprise.NewClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	checker := assess.NewChecker(client, assess.Config{
+		Project: "my-project",
+		SiteKey: "my-site-key",
+		Policy: assess.Policy{
+			MinScore:    0.5,
+			DenyReasons: []recaptchaenterprisepb.RiskAnalysis_ClassificationReason{recaptchaenterprisepb.RiskAnalysis_AUTOMATION},
+		},
+	})
+	http.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		d, err := checker.Check(r.Context(), r.FormValue("g-recaptcha-response"), "login", &recaptchaenterprisepb.Event{
+			UserAgent:     r.UserAgent(),
+			UserIpAddress: r.RemoteAddr,
+		})
+		if err != nil && d == nil {
+			http.Error(w, "reCAPTCHA is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if !d.Allowed() {
+			http.Error(w, d.Verdict.String(), http.StatusForbidden)
+			return
+		}
+		// TODO: Log in the user.
+	})
+}
@@ -93,6 +93,17 @@ RPCs are written to the replay file. If requests are modified by the callbacks d
 recording, it is important to perform the same modifications to the requests when
 replaying, or RPC matching on replay will fail.
 
+The callbacks are run for the messages of unary and streaming RPCs alike. For streams,
+the Recorder's callback sees each message sent and received, and the Replayer's sees
+each message sent.
+
+To keep credentials, project IDs and other sensitive data out of replay files that are
+checked into public repositories, use ReplaceStrings and ClearFields:
+
+	scrub := rpcreplay.ReplaceStrings(projectID, "PROJECT", token, "TOKEN")
+	rec.BeforeFunc = scrub // when recording
+	rep.BeforeFunc = scrub // when replaying
+
 A common way to analyze and modify the various messages is to use a type switch.
 
 	// Assume these types implement proto.Message.
@@ -130,10 +141,10 @@ depending on the order of such RPCs probably has a race condition: since both th
 recorded sequence of RPCs and the sequence during replay are valid orderings, the
 program should behave the same under both.
 
-The same is not true of streaming RPCs. The replayer matches streams only by method
-name, since it has no other information at the time the stream is opened. Two streams
-with the same method name that are started concurrently may replay in the wrong
-order.
+The same is not true of streaming RPCs. The replayer matches streams by method name
+and the first message sent, since it has no other information at the time the stream
+is opened. Two streams with the same method name and first message that are started
+concurrently may replay in the wrong order.
 
 # Other Replayer Differences
 
@@ -146,7 +157,11 @@ one goroutine publishes and another subscribes, during replay the Subscribe call
 finish before the Publish call begins.
 
 For streaming RPCs, the Replayer delivers the result of Send and Recv calls in
-the order they were recorded. No attempt is made to match message contents.
+the order they were recorded. A Recv does not return until the Sends recorded before
+it have been made, so a program that sends and receives on a bidirectional stream from
+different goroutines sees the recorded ordering. Recv fails if CloseSend is called
+before those Sends. Apart from the first, no attempt is made to match the contents of
+messages sent.
 
 At present, this package does not record or replay stream headers and trailers, or
 the result of the CloseSend method.
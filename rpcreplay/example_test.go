@@ -50,3 +50,22 @@ func ExampleNewReplayer() {
 	}
 	_ = conn // TODO: use connection
 }
+
+func ExampleReplaceStrings() {
+	const projectID = "my-project"
+	scrub := rpcreplay.ReplaceStrings(projectID, "PROJECT")
+
+	rec, err := rpcreplay.NewRecorder("service.replay", nil)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	rec.BeforeFunc = scrub
+	// ...
+
+	// When replaying, scrub the requests the same way so they match.
+	rep, err := rpcreplay.NewReplayer("service.replay")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	rep.BeforeFunc = scrub
+}
@@ -48,9 +48,11 @@ type Recorder struct {
 	// written to the replay file. It does not modify messages sent to the service.
 	// It is run once before a request is written to the replay file, and once before a response
 	// is written to the replay file.
+	// For streaming RPCs, it is run before each message sent or received is written.
 	// The function is called with the method name and the message that triggered the callback.
 	// If the function returns an error, the error will be returned to the client.
-	// This is only executed for unary RPCs; streaming RPCs are not supported.
+	//
+	// See ReplaceStrings and ClearFields for functions that scrub sensitive data.
 	BeforeFunc func(string, proto.Message) error
 }
 
@@ -183,6 +185,7 @@ func (r *Recorder) interceptStream(ctx context.Context, desc *grpc.StreamDesc, c
 	return &recClientStream{
 		ctx:      ctx,
 		rec:      r,
+		method:   method,
 		cstream:  cstream,
 		refIndex: refIndex,
 	}, serr
@@ -194,6 +197,7 @@ func (r *Recorder) interceptStream(ctx context.Context, desc *grpc.StreamDesc, c
 type recClientStream struct {
 	ctx      context.Context
 	rec      *Recorder
+	method   string
 	cstream  grpc.ClientStream
 	refIndex int
 }
@@ -206,7 +210,11 @@ func (rcs *recClientStream) SendMsg(m interface{}) error {
 		kind:     pb.Entry_SEND,
 		refIndex: rcs.refIndex,
 	}
-	e.msg.set(m, serr)
+	msg, err := rcs.before(m)
+	if err != nil {
+		return err
+	}
+	e.msg.set(msg, serr)
 	if _, err := rcs.rec.writeEntry(e); err != nil {
 		return err
 	}
@@ -219,13 +227,33 @@ func (rcs *recClientStream) RecvMsg(m interface{}) error {
 		kind:     pb.Entry_RECV,
 		refIndex: rcs.refIndex,
 	}
-	e.msg.set(m, serr)
+	var msg interface{}
+	if serr == nil {
+		var err error
+		if msg, err = rcs.before(m); err != nil {
+			return err
+		}
+	}
+	e.msg.set(msg, serr)
 	if _, err := rcs.rec.writeEntry(e); err != nil {
 		return err
 	}
 	return serr
 }
 
+// before returns the message to record for m, after running the Recorder's
+// BeforeFunc on a copy of it.
+func (rcs *recClientStream) before(m interface{}) (interface{}, error) {
+	if rcs.rec.BeforeFunc == nil || m == nil {
+		return m, nil
+	}
+	msg := proto.Clone(m.(proto.Message))
+	if err := rcs.rec.BeforeFunc(rcs.method, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
 func (rcs *recClientStream) Header() (metadata.MD, error) {
 	// TODO(jba): record.
 	return rcs.cstream.Header()
@@ -251,9 +279,9 @@ type Replayer struct {
 	streams []*stream
 	// BeforeFunc defines a function that can inspect and modify requests before they
 	// are matched for responses from the replay file.
+	// For streaming RPCs, it is run on a copy of each message sent.
 	// The function is called with the method name and the message that triggered the callback.
 	// If the function returns an error, the error will be returned to the client.
-	// This is only executed for unary RPCs; streaming RPCs are not supported.
 	BeforeFunc func(string, proto.Message) error
 }
 
@@ -272,6 +300,7 @@ type stream struct {
 	createErr   error // error from create call
 	sends       []message
 	recvs       []message
+	recvAfter   []int // for each recv, the number of sends recorded before it
 }
 
 // NewReplayer creates a Replayer that reads from filename.
@@ -351,6 +380,7 @@ func (rep *Replayer) read(r io.Reader) error {
 				return fmt.Errorf("replayer: no stream for recv #%d", i)
 			}
 			s.recvs = append(s.recvs, e.msg)
+			s.recvAfter = append(s.recvAfter, len(s.sends))
 
 		default:
 			return fmt.Errorf("replayer: unknown kind %s", e.kind)
@@ -436,34 +466,56 @@ func (rep *Replayer) interceptUnary(_ context.Context, method string, req, res i
 
 func (rep *Replayer) interceptStream(ctx context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, method string, _ grpc.Streamer, _ ...grpc.CallOption) (grpc.ClientStream, error) {
 	rep.log("create-stream %s", method)
-	return &repClientStream{ctx: ctx, rep: rep, method: method}, nil
+	return &repClientStream{ctx: ctx, rep: rep, method: method, changed: make(chan struct{})}, nil
 }
 
+// A repClientStream replays a recorded stream. Sends and receives are
+// delivered in the order they were recorded: a receive does not complete
+// until the sends recorded before it have been made, so that goroutines
+// sending and receiving on a bidirectional stream observe the recorded
+// ordering.
 type repClientStream struct {
 	ctx    context.Context
 	rep    *Replayer
 	method string
-	str    *stream
+
+	mu      sync.Mutex
+	str     *stream
+	nsent   int           // number of sends replayed
+	nrecvd  int           // number of receives replayed
+	closed  bool          // CloseSend was called
+	changed chan struct{} // closed and replaced when the fields above change
 }
 
 func (rcs *repClientStream) Context() context.Context { return rcs.ctx }
 
 func (rcs *repClientStream) SendMsg(req interface{}) error {
+	mreq := req.(proto.Message)
+	if rcs.rep.BeforeFunc != nil {
+		mreq = proto.Clone(mreq)
+		if err := rcs.rep.BeforeFunc(rcs.method, mreq); err != nil {
+			return err
+		}
+	}
+	rcs.mu.Lock()
+	defer rcs.mu.Unlock()
 	if rcs.str == nil {
-		if err := rcs.setStream(rcs.method, req.(proto.Message)); err != nil {
+		if err := rcs.setStream(rcs.method, mreq); err != nil {
 			return err
 		}
 	}
-	if len(rcs.str.sends) == 0 {
+	if rcs.nsent >= len(rcs.str.sends) {
 		return fmt.Errorf("replayer: no more sends for stream %s, created at index %d",
 			rcs.str.method, rcs.str.createIndex)
 	}
 	// TODO(jba): Do not assume that the sends happen in the same order on replay.
-	msg := rcs.str.sends[0]
-	rcs.str.sends = rcs.str.sends[1:]
+	msg := rcs.str.sends[rcs.nsent]
+	rcs.nsent++
+	rcs.notify()
 	return msg.err
 }
 
+// setStream must be called with rcs.mu held.
 func (rcs *repClientStream) setStream(method string, req proto.Message) error {
 	str := rcs.rep.extractStream(method, req)
 	if str == nil {
@@ -473,22 +525,63 @@ func (rcs *repClientStream) setStream(method string, req proto.Message) error {
 		return str.createErr
 	}
 	rcs.str = str
+	rcs.notify()
 	return nil
 }
 
+// notify wakes up a RecvMsg waiting for the state of rcs to change.
+// It must be called with rcs.mu held.
+func (rcs *repClientStream) notify() {
+	close(rcs.changed)
+	rcs.changed = make(chan struct{})
+}
+
+// wait releases rcs.mu until the state of rcs changes or the stream's context
+// is done. It must be called with rcs.mu held.
+func (rcs *repClientStream) wait() error {
+	ch := rcs.changed
+	rcs.mu.Unlock()
+	defer rcs.mu.Lock()
+	select {
+	case <-ch:
+		return nil
+	case <-rcs.ctx.Done():
+		return status.FromContextError(rcs.ctx.Err()).Err()
+	}
+}
+
 func (rcs *repClientStream) RecvMsg(m interface{}) error {
-	if rcs.str == nil {
-		// Receive before send; fall back to matching stream by method only.
-		if err := rcs.setStream(rcs.method, nil); err != nil {
+	rcs.mu.Lock()
+	defer rcs.mu.Unlock()
+	for rcs.str == nil {
+		if rcs.closed || !rcs.rep.sendsFirst(rcs.method) {
+			// Receive before send; fall back to matching stream by method only.
+			if err := rcs.setStream(rcs.method, nil); err != nil {
+				return err
+			}
+			break
+		}
+		// Every stream recorded for the method began with a send; wait for
+		// it, so that the stream can be matched by its first request.
+		if err := rcs.wait(); err != nil {
 			return err
 		}
 	}
-	if len(rcs.str.recvs) == 0 {
+	if rcs.nrecvd >= len(rcs.str.recvs) {
 		return fmt.Errorf("replayer: no more receives for stream %s, created at index %d",
 			rcs.str.method, rcs.str.createIndex)
 	}
-	msg := rcs.str.recvs[0]
-	rcs.str.recvs = rcs.str.recvs[1:]
+	for rcs.nsent < rcs.str.recvAfter[rcs.nrecvd] {
+		if rcs.closed {
+			return fmt.Errorf("replayer: stream %s, created at index %d: receive #%d was recorded after %d sends, but CloseSend was called after %d",
+				rcs.str.method, rcs.str.createIndex, rcs.nrecvd+1, rcs.str.recvAfter[rcs.nrecvd], rcs.nsent)
+		}
+		if err := rcs.wait(); err != nil {
+			return err
+		}
+	}
+	msg := rcs.str.recvs[rcs.nrecvd]
+	rcs.nrecvd++
 	if msg.err != nil {
 		return msg.err
 	}
@@ -507,6 +600,10 @@ func (rcs *repClientStream) Trailer() metadata.MD {
 }
 
 func (rcs *repClientStream) CloseSend() error {
+	rcs.mu.Lock()
+	defer rcs.mu.Unlock()
+	rcs.closed = true
+	rcs.notify()
 	return nil
 }
 
@@ -527,6 +624,24 @@ func (rep *Replayer) extractCall(method string, req proto.Message) *call {
 	return nil
 }
 
+// sendsFirst reports whether there are unreplayed streams for method, and
+// each of them began with a send.
+func (rep *Replayer) sendsFirst(method string) bool {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	found := false
+	for _, stream := range rep.streams {
+		if stream == nil || stream.method != method {
+			continue
+		}
+		if stream.createErr != nil || len(stream.sends) == 0 || (len(stream.recvAfter) > 0 && stream.recvAfter[0] == 0) {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
 // extractStream find the first stream in the list with the same method and the same
 // first request sent. If req is nil, that means a receive occurred before a send, so
 // it matches only on method.
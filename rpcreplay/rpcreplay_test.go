@@ -21,6 +21,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/internal/testutil"
 	ipb "cloud.google.com/go/rpcreplay/proto/intstore"
@@ -622,3 +623,119 @@ func TestOutOfOrderStreamReplay(t *testing.T) {
 	buf = record(t, func(t *testing.T, conn *grpc.ClientConn) { run(t, conn, 1, 2) })
 	replay(t, buf, func(t *testing.T, conn *grpc.ClientConn) { run(t, conn, 2, 1) })
 }
+
+func TestBidiStreamReplay(t *testing.T) {
+	// Check that stream messages are scrubbed, and that a receive on a
+	// bidirectional stream waits for the sends recorded before it.
+	items := []*ipb.Item{
+		{Name: "secret-a", Value: 1},
+		{Name: "secret-b", Value: 2},
+	}
+	scrub := ReplaceStrings("secret", "X")
+	// Record the stream with each receive following a send.
+	run := func(t *testing.T, conn *grpc.ClientConn) {
+		stream, err := ipb.NewIntStoreClient(conn).StreamChat(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range items {
+			if err := stream.Send(item); err != nil {
+				t.Fatal(err)
+			}
+			got, err := stream.Recv()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(got, item, protocmp.Transform()) {
+				t.Errorf("got %v, want %v", got, item)
+			}
+		}
+		if err := stream.CloseSend(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := stream.Recv(); err != io.EOF {
+			t.Fatalf("got %v, want io.EOF", err)
+		}
+	}
+
+	srv := newIntStoreServer()
+	defer srv.stop()
+
+	buf := &bytes.Buffer{}
+	rec, err := NewRecorderWriter(buf, initialState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.BeforeFunc = scrub
+	conn, err := grpc.Dial(srv.Addr, append([]grpc.DialOption{grpc.WithInsecure()}, rec.DialOptions()...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run(t, conn)
+	conn.Close()
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("secret")) {
+		t.Fatal("replay file contains unscrubbed data")
+	}
+
+	rep, err := NewReplayerReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rep.Close()
+	rep.BeforeFunc = scrub
+	if len(rep.streams) != 1 {
+		t.Fatalf("got %d streams, want 1", len(rep.streams))
+	}
+	str := rep.streams[0]
+	for i, want := range []*ipb.Item{{Name: "X-a", Value: 1}, {Name: "X-b", Value: 2}} {
+		if got := str.sends[i].msg; !cmp.Equal(got, want, protocmp.Transform()) {
+			t.Errorf("send #%d: got %v, want %v", i, got, want)
+		}
+		if got := str.recvs[i].msg; !cmp.Equal(got, want, protocmp.Transform()) {
+			t.Errorf("recv #%d: got %v, want %v", i, got, want)
+		}
+	}
+	if got, want := str.recvAfter, []int{1, 2, 2}; !cmp.Equal(got, want) {
+		t.Errorf("got recvAfter %v, want %v", got, want)
+	}
+
+	// On replay, receive in a separate goroutine that starts before anything
+	// is sent.
+	conn, err = rep.Connection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream, err := ipb.NewIntStoreClient(conn).StreamChat(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recvd := make(chan error, 1)
+	go func() {
+		_, err := stream.Recv()
+		recvd <- err
+	}()
+	select {
+	case err := <-recvd:
+		t.Fatalf("Recv returned %v before any Send", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := stream.Send(&ipb.Item{Name: "secret-a", Value: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-recvd; err != nil {
+		t.Fatal(err)
+	}
+	// The second receive was recorded after the second send.
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err == nil {
+		t.Error("got nil, want error receiving after CloseSend without a matching send")
+	}
+}
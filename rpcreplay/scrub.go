@@ -0,0 +1,119 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcreplay
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ReplaceStrings returns a function, suitable for the BeforeFunc field of a
+// Recorder or Replayer, that replaces old strings with new ones in every
+// string field of a message, including those of nested messages, repeated
+// fields and map values. The replacements are made as by strings.NewReplacer;
+// it panics if given an odd number of arguments.
+//
+// For example, to keep a project ID and an access token out of a replay file:
+//
+//	rec.BeforeFunc = rpcreplay.ReplaceStrings(projectID, "PROJECT", token, "TOKEN")
+//
+// The same function should be used when replaying, so that requests match
+// those recorded.
+func ReplaceStrings(oldnew ...string) func(string, proto.Message) error {
+	r := strings.NewReplacer(oldnew...)
+	return func(_ string, msg proto.Message) error {
+		walkFields(msg.ProtoReflect(), func(m protoreflect.Message, fd protoreflect.FieldDescriptor) {
+			switch {
+			case fd.IsMap():
+				if fd.MapValue().Kind() != protoreflect.StringKind {
+					return
+				}
+				mp := m.Mutable(fd).Map()
+				var keys []protoreflect.MapKey
+				mp.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+					keys = append(keys, k)
+					return true
+				})
+				for _, k := range keys {
+					mp.Set(k, protoreflect.ValueOfString(r.Replace(mp.Get(k).String())))
+				}
+			case fd.Kind() != protoreflect.StringKind:
+			case fd.IsList():
+				l := m.Mutable(fd).List()
+				for i := 0; i < l.Len(); i++ {
+					l.Set(i, protoreflect.ValueOfString(r.Replace(l.Get(i).String())))
+				}
+			default:
+				m.Set(fd, protoreflect.ValueOfString(r.Replace(m.Get(fd).String())))
+			}
+		})
+		return nil
+	}
+}
+
+// ClearFields returns a function, suitable for the BeforeFunc field of a
+// Recorder or Replayer, that clears the named fields of a message and of the
+// messages nested in it. A name is either the name of a field as it appears in
+// its .proto file, like "page_token", which matches the field in any message,
+// or its full name, like "google.pubsub.v1.PullRequest.subscription".
+func ClearFields(names ...string) func(string, proto.Message) error {
+	cleared := map[string]bool{}
+	for _, n := range names {
+		cleared[n] = true
+	}
+	return func(_ string, msg proto.Message) error {
+		walkFields(msg.ProtoReflect(), func(m protoreflect.Message, fd protoreflect.FieldDescriptor) {
+			if cleared[string(fd.Name())] || cleared[string(fd.FullName())] {
+				m.Clear(fd)
+			}
+		})
+		return nil
+	}
+}
+
+// walkFields calls f for each populated field of m and, unless f clears it,
+// for the populated fields of the messages it holds.
+func walkFields(m protoreflect.Message, f func(protoreflect.Message, protoreflect.FieldDescriptor)) {
+	var fds []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		fds = append(fds, fd)
+		return true
+	})
+	for _, fd := range fds {
+		f(m, fd)
+		if !m.Has(fd) || fd.Message() == nil {
+			continue
+		}
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Message() == nil {
+				continue
+			}
+			m.Get(fd).Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+				walkFields(v.Message(), f)
+				return true
+			})
+		case fd.IsList():
+			l := m.Get(fd).List()
+			for i := 0; i < l.Len(); i++ {
+				walkFields(l.Get(i).Message(), f)
+			}
+		default:
+			walkFields(m.Get(fd).Message(), f)
+		}
+	}
+}
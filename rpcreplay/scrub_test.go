@@ -0,0 +1,93 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcreplay
+
+import (
+	"testing"
+
+	ipb "cloud.google.com/go/rpcreplay/proto/intstore"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestReplaceStrings(t *testing.T) {
+	msg, err := structpb.NewStruct(map[string]interface{}{
+		"name": "projects/my-proj/topics/t",
+		"list": []interface{}{"my-proj", 1, "other"},
+		"nested": map[string]interface{}{
+			"token": "Bearer s3cret",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := structpb.NewStruct(map[string]interface{}{
+		"name": "projects/PROJECT/topics/t",
+		"list": []interface{}{"PROJECT", 1, "other"},
+		"nested": map[string]interface{}{
+			"token": "Bearer TOKEN",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ReplaceStrings("my-proj", "PROJECT", "s3cret", "TOKEN")("m", msg); err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(msg, want, protocmp.Transform()) {
+		t.Errorf("got %v, want %v", msg, want)
+	}
+}
+
+func TestClearFields(t *testing.T) {
+	item := &ipb.Item{Name: "n", Value: 1}
+	if err := ClearFields("name")("m", item); err != nil {
+		t.Fatal(err)
+	}
+	if want := (&ipb.Item{Value: 1}); !cmp.Equal(item, want, protocmp.Transform()) {
+		t.Errorf("got %v, want %v", item, want)
+	}
+
+	item = &ipb.Item{Name: "n", Value: 1}
+	if err := ClearFields("intstore.Item.value", "other.Item.name")("m", item); err != nil {
+		t.Fatal(err)
+	}
+	if want := (&ipb.Item{Name: "n"}); !cmp.Equal(item, want, protocmp.Transform()) {
+		t.Errorf("got %v, want %v", item, want)
+	}
+
+	// Nested messages.
+	msg, err := structpb.NewStruct(map[string]interface{}{
+		"a": "x",
+		"b": map[string]interface{}{"c": "y", "d": 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ClearFields("string_value")("m", msg); err != nil {
+		t.Fatal(err)
+	}
+	want := &structpb.Struct{Fields: map[string]*structpb.Value{
+		"a": {},
+		"b": structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+			"c": {},
+			"d": structpb.NewNumberValue(1),
+		}}),
+	}}
+	if !cmp.Equal(msg, want, protocmp.Transform()) {
+		t.Errorf("got %v, want %v", msg, want)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package deploy provides the operations of deploy scripts on top of the
+// Cloud Run admin clients in cloud.google.com/go/run/apiv2.
+//
+// ExecuteJob runs a job and waits for its execution to complete, reporting
+// its progress and, optionally, tailing its logs:
+//
+//	exec, err := deploy.ExecuteJob(ctx, jobsClient, &runpb.RunJobRequest{
+//		Name: "projects/my-project/locations/us-central1/jobs/migrate",
+//	}, &deploy.JobConfig{
+//		OnProgress: func(e *runpb.Execution) {
+//			log.Printf("%d/%d tasks succeeded", e.SucceededCount, e.TaskCount)
+//		},
+//	})
+//
+// Rollout moves the traffic of a service to a revision in steps, verifying
+// each step and moving the traffic back if a verification fails:
+//
+//	svc, err := deploy.Rollout(ctx, servicesClient, "projects/my-project/locations/us-central1/services/api", "api-00042-xyz", &deploy.RolloutConfig{
+//		Steps:    []int32{5, 25, 100},
+//		Interval: 5 * time.Minute,
+//		Verify: func(ctx context.Context, percent int32) error {
+//			// TODO: Check the error rate of the revision.
+//			return nil
+//		},
+//	})
+package deploy // import "cloud.google.com/go/run/deploy"
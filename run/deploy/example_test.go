@@ -0,0 +1,77 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy_test
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	run "cloud.google.com/go/run/apiv2"
+	"cloud.google.com/go/run/apiv2/runpb"
+	"cloud.google.com/go/run/deploy"
+)
+
+func ExampleExecuteJob() {
+	ctx := context.Background()
+	client, err := run.NewJobsClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	exec, err := deploy.ExecuteJob(ctx, client, &runpb.RunJobRequest{
+		Name: "projects/my-project/locations/us-central1/jobs/migrate",
+	}, &deploy.JobConfig{
+		OnProgress: func(e *runpb.Execution) {
+			log.Printf("%d/%d tasks succeeded", e.SucceededCount, e.TaskCount)
+		},
+		TailLogs: func(ctx context.Context, filter string) {
+			// TODO: Read the log entries matching filter with the Cloud
+			// Logging client until ctx is done.
+		},
+	})
+	var ee *deploy.ExecutionError
+	if errors.As(err, &ee) {
+		log.Fatalf("%v; logs at %s", err, ee.Execution.LogUri)
+	}
+	if err != nil {
+		// TODO: Handle error.
+	}
+	log.Printf("execution %s completed", exec.Name)
+}
+
+func ExampleRollout() {
+	ctx := context.Background()
+	client, err := run.NewServicesClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	_, err = deploy.Rollout(ctx, client, "projects/my-project/locations/us-central1/services/api", "api-00042-xyz", &deploy.RolloutConfig{
+		Steps:    []int32{5, 25, 100},
+		Interval: 5 * time.Minute,
+		Verify: func(ctx context.Context, percent int32) error {
+			// TODO: Check the error rate of the revision, and return an
+			// error to move the traffic back.
+			return nil
+		},
+	})
+	if err != nil {
+		// TODO: Handle error.
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	run "cloud.google.com/go/run/apiv2"
+	"cloud.google.com/go/run/apiv2/runpb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+const defaultPollInterval = 10 * time.Second
+
+// logGracePeriod is how long logs are tailed after an execution completes,
+// as log entries are ingested with a delay.
+var logGracePeriod = 10 * time.Second
+
+// JobConfig configures ExecuteJob.
+type JobConfig struct {
+	// PollInterval is the time between two checks of the execution. The
+	// default is 10 seconds.
+	PollInterval time.Duration
+
+	// OnProgress, if set, is called with the execution when its task counts
+	// change.
+	OnProgress func(*runpb.Execution)
+
+	// TailLogs, if set, is called in its own goroutine once the execution
+	// starts, with the Cloud Logging filter of the log entries of the
+	// execution. It should read them, with the Cloud Logging client, until
+	// ctx is done, which happens shortly after the execution completes.
+	// ExecuteJob waits for it to return.
+	TailLogs func(ctx context.Context, filter string)
+}
+
+// An ExecutionError is returned by ExecuteJob when tasks of the execution
+// failed or were cancelled.
+type ExecutionError struct {
+	// Execution is the completed execution.
+	Execution *runpb.Execution
+
+	// Err is the error of the operation of the execution, if any.
+	Err error
+}
+
+func (e *ExecutionError) Error() string {
+	x := e.Execution
+	msg := fmt.Sprintf("deploy: execution %s: %d of %d tasks failed, %d cancelled", x.Name, x.FailedCount, x.TaskCount, x.CancelledCount)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ExecutionError) Unwrap() error {
+	return e.Err
+}
+
+// jobOperation is implemented by *run.RunJobOperation.
+type jobOperation interface {
+	Poll(ctx context.Context, opts ...gax.CallOption) (*runpb.Execution, error)
+	Metadata() (*runpb.Execution, error)
+	Done() bool
+}
+
+// ExecuteJob runs a job and waits for the execution to complete. It returns
+// the execution, and an *ExecutionError if tasks of the execution failed or
+// were cancelled. cfg may be nil.
+func ExecuteJob(ctx context.Context, client *run.JobsClient, req *runpb.RunJobRequest, cfg *JobConfig) (*runpb.Execution, error) {
+	op, err := client.RunJob(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return waitExecution(ctx, op, cfg)
+}
+
+func waitExecution(ctx context.Context, op jobOperation, cfg *JobConfig) (*runpb.Execution, error) {
+	if cfg == nil {
+		cfg = &JobConfig{}
+	}
+	interval := cfg.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	var wg sync.WaitGroup
+	logsCtx, cancelLogs := context.WithCancel(ctx)
+	stopLogs := func() {
+		cancelLogs()
+		wg.Wait()
+	}
+	defer stopLogs()
+	tailing := false
+
+	var last *runpb.Execution
+	for {
+		exec, err := op.Poll(ctx)
+		if op.Done() {
+			if exec == nil {
+				// The operation failed: its metadata is the execution.
+				exec, _ = op.Metadata()
+			}
+			if cfg.OnProgress != nil && exec != nil && progressed(last, exec) {
+				cfg.OnProgress(exec)
+			}
+			if tailing && exec != nil {
+				select {
+				case <-time.After(logGracePeriod):
+				case <-ctx.Done():
+				}
+			}
+			if exec != nil && (err != nil || exec.FailedCount > 0 || exec.CancelledCount > 0) {
+				return exec, &ExecutionError{Execution: exec, Err: err}
+			}
+			return exec, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		if md, err := op.Metadata(); err == nil && md != nil {
+			if !tailing && cfg.TailLogs != nil && md.Name != "" {
+				tailing = true
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					cfg.TailLogs(logsCtx, LogFilter(md.Name))
+				}()
+			}
+			if cfg.OnProgress != nil && progressed(last, md) {
+				cfg.OnProgress(md)
+			}
+			last = md
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// progressed reports whether the task counts of an execution changed.
+func progressed(prev, cur *runpb.Execution) bool {
+	return prev == nil ||
+		prev.RunningCount != cur.RunningCount ||
+		prev.SucceededCount != cur.SucceededCount ||
+		prev.FailedCount != cur.FailedCount ||
+		prev.CancelledCount != cur.CancelledCount ||
+		prev.RetriedCount != cur.RetriedCount
+}
+
+// LogFilter returns the Cloud Logging filter of the log entries of an
+// execution, whose name has the form
+// projects/{project}/locations/{location}/jobs/{job}/executions/{execution}.
+func LogFilter(execution string) string {
+	parts := strings.Split(execution, "/")
+	get := func(key string) string {
+		for i := 0; i+1 < len(parts); i += 2 {
+			if parts[i] == key {
+				return parts[i+1]
+			}
+		}
+		return ""
+	}
+	return fmt.Sprintf(`resource.type="cloud_run_job" AND resource.labels.location=%q AND resource.labels.job_name=%q AND labels."run.googleapis.com/execution_name"=%q`,
+		get("locations"), get("jobs"), get("executions"))
+}
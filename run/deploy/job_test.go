@@ -0,0 +1,120 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/run/apiv2/runpb"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const execName = "projects/p/locations/us-central1/jobs/j/executions/j-abc"
+
+// fakeOperation returns the executions in turn as its metadata, and is done
+// with the last one, failing with err if it is set.
+type fakeOperation struct {
+	executions []*runpb.Execution
+	err        error
+	polls      int
+}
+
+func (op *fakeOperation) Poll(ctx context.Context, _ ...gax.CallOption) (*runpb.Execution, error) {
+	op.polls++
+	if !op.Done() {
+		return nil, nil
+	}
+	if op.err != nil {
+		return nil, op.err
+	}
+	return op.executions[len(op.executions)-1], nil
+}
+
+func (op *fakeOperation) Metadata() (*runpb.Execution, error) {
+	return op.executions[op.polls-1], nil
+}
+
+func (op *fakeOperation) Done() bool {
+	return op.polls >= len(op.executions)
+}
+
+func execution(succeeded, failed int32) *runpb.Execution {
+	return &runpb.Execution{Name: execName, TaskCount: 3, SucceededCount: succeeded, FailedCount: failed}
+}
+
+func TestWaitExecution(t *testing.T) {
+	defer func(d time.Duration) { logGracePeriod = d }(logGracePeriod)
+	logGracePeriod = 0
+
+	op := &fakeOperation{executions: []*runpb.Execution{execution(0, 0), execution(0, 0), execution(1, 0), execution(3, 0)}}
+	var progress []int32
+	var filter string
+	logsDone := false
+	cfg := &JobConfig{
+		PollInterval: time.Millisecond,
+		OnProgress:   func(e *runpb.Execution) { progress = append(progress, e.SucceededCount) },
+		TailLogs: func(ctx context.Context, f string) {
+			filter = f
+			<-ctx.Done()
+			logsDone = true
+		},
+	}
+	exec, err := waitExecution(context.Background(), op, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exec.SucceededCount != 3 {
+		t.Errorf("got %d tasks succeeded, want 3", exec.SucceededCount)
+	}
+	if want := []int32{0, 1, 3}; len(progress) != len(want) || progress[0] != 0 || progress[1] != 1 || progress[2] != 3 {
+		t.Errorf("got progress %v, want %v", progress, want)
+	}
+	if want := LogFilter(execName); filter != want {
+		t.Errorf("got filter %q, want %q", filter, want)
+	}
+	if !logsDone {
+		t.Error("ExecuteJob returned before the logs were tailed")
+	}
+}
+
+func TestWaitExecutionFailure(t *testing.T) {
+	// Failed tasks are an error even if the operation succeeds.
+	op := &fakeOperation{executions: []*runpb.Execution{execution(0, 0), execution(2, 1)}}
+	_, err := waitExecution(context.Background(), op, &JobConfig{PollInterval: time.Millisecond})
+	var ee *ExecutionError
+	if !errors.As(err, &ee) || ee.Execution.FailedCount != 1 {
+		t.Errorf("got %v, want an ExecutionError with 1 failed task", err)
+	}
+
+	opErr := status.Error(codes.Aborted, "task failed")
+	op = &fakeOperation{executions: []*runpb.Execution{execution(0, 0), execution(2, 1)}, err: opErr}
+	exec, err := waitExecution(context.Background(), op, &JobConfig{PollInterval: time.Millisecond})
+	if !errors.As(err, &ee) || !errors.Is(err, opErr) || exec == nil {
+		t.Errorf("got %v, %v, want the execution and an ExecutionError wrapping %v", exec, err, opErr)
+	}
+}
+
+func TestLogFilter(t *testing.T) {
+	got := LogFilter(execName)
+	want := `resource.type="cloud_run_job" AND resource.labels.location="us-central1" AND resource.labels.job_name="j" AND labels."run.googleapis.com/execution_name"="j-abc"`
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	run "cloud.google.com/go/run/apiv2"
+	"cloud.google.com/go/run/apiv2/runpb"
+	"google.golang.org/protobuf/proto"
+)
+
+var defaultSteps = []int32{10, 50, 100}
+
+// RolloutConfig configures Rollout.
+type RolloutConfig struct {
+	// Steps are the increasing percentages of the traffic sent to the
+	// revision, the last of which is 100. The default is 10, 50 and 100.
+	Steps []int32
+
+	// Interval is the time to wait after each step before verifying it.
+	Interval time.Duration
+
+	// Verify, if set, is called after each step and its Interval, with the
+	// percentage of the step. If it returns an error, the traffic is set
+	// back to what it was before Rollout, and Rollout returns the error.
+	Verify func(ctx context.Context, percent int32) error
+}
+
+// services is implemented by *run.ServicesClient, through servicesClient.
+type services interface {
+	getService(ctx context.Context, name string) (*runpb.Service, error)
+	updateService(ctx context.Context, svc *runpb.Service) (*runpb.Service, error)
+}
+
+type servicesClient struct {
+	c *run.ServicesClient
+}
+
+func (s servicesClient) getService(ctx context.Context, name string) (*runpb.Service, error) {
+	return s.c.GetService(ctx, &runpb.GetServiceRequest{Name: name})
+}
+
+func (s servicesClient) updateService(ctx context.Context, svc *runpb.Service) (*runpb.Service, error) {
+	op, err := s.c.UpdateService(ctx, &runpb.UpdateServiceRequest{Service: svc})
+	if err != nil {
+		return nil, err
+	}
+	return op.Wait(ctx)
+}
+
+// Rollout sends the traffic of a service to one of its revisions gradually,
+// following cfg.Steps. At each step, the revision gets the percentage of the
+// step, and the revisions that were serving traffic before Rollout share the
+// rest in their original proportions. Tags are kept. If cfg.Verify fails, the
+// traffic is sent back to the revisions that served it before Rollout, even
+// if a newer revision became the latest one in the meantime.
+//
+// The service has the form
+// projects/{project}/locations/{location}/services/{service}, and the
+// revision is the name of the revision or its short name. cfg may be nil. It
+// returns the service after the last step.
+func Rollout(ctx context.Context, client *run.ServicesClient, service, revision string, cfg *RolloutConfig) (*runpb.Service, error) {
+	return rollout(ctx, servicesClient{client}, service, revision, cfg)
+}
+
+func rollout(ctx context.Context, client services, service, revision string, cfg *RolloutConfig) (*runpb.Service, error) {
+	if cfg == nil {
+		cfg = &RolloutConfig{}
+	}
+	steps := cfg.Steps
+	if len(steps) == 0 {
+		steps = defaultSteps
+	}
+	for i, p := range steps {
+		if p <= 0 || p > 100 || (i > 0 && p <= steps[i-1]) {
+			return nil, fmt.Errorf("deploy: steps %v are not increasing percentages", steps)
+		}
+	}
+	if steps[len(steps)-1] != 100 {
+		return nil, fmt.Errorf("deploy: the last step is %d%%, not 100%%", steps[len(steps)-1])
+	}
+	revision = path.Base(revision)
+
+	svc, err := client.getService(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	original, err := statusTraffic(svc)
+	if err != nil {
+		return nil, err
+	}
+	base := baseTraffic(original, revision)
+	if len(base) == 0 {
+		return nil, fmt.Errorf("deploy: no revision of %s other than %s serves traffic", svc.Name, revision)
+	}
+	for _, p := range steps {
+		svc = proto.Clone(svc).(*runpb.Service)
+		svc.Traffic = split(base, revision, p)
+		if svc, err = client.updateService(ctx, svc); err != nil {
+			return nil, fmt.Errorf("deploy: setting %d%% of the traffic to %s: %w", p, revision, err)
+		}
+		if cfg.Interval > 0 {
+			select {
+			case <-time.After(cfg.Interval):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		if cfg.Verify == nil {
+			continue
+		}
+		if err := cfg.Verify(ctx, p); err != nil {
+			err = fmt.Errorf("deploy: verifying %d%% of the traffic to %s: %w", p, revision, err)
+			svc = proto.Clone(svc).(*runpb.Service)
+			svc.Traffic = original
+			if _, rerr := client.updateService(ctx, svc); rerr != nil {
+				err = errors.Join(err, fmt.Errorf("deploy: rolling back: %w", rerr))
+			}
+			return nil, err
+		}
+	}
+	return svc, nil
+}
+
+// statusTraffic returns the traffic targets that name the revisions serving
+// traffic, and the tags of the service, from the traffic status of the
+// service. Unlike the traffic of the service, they don't follow the latest
+// revision, so they can restore the traffic as it was.
+func statusTraffic(svc *runpb.Service) ([]*runpb.TrafficTarget, error) {
+	var targets []*runpb.TrafficTarget
+	for _, s := range svc.TrafficStatuses {
+		if s.Percent == 0 && s.Tag == "" {
+			continue
+		}
+		if s.Revision == "" && s.Type == runpb.TrafficTargetAllocationType_TRAFFIC_TARGET_ALLOCATION_TYPE_LATEST && s.Percent > 0 {
+			return nil, fmt.Errorf("deploy: the latest revision of %s serves traffic, but its traffic status has no revision", svc.Name)
+		}
+		targets = append(targets, &runpb.TrafficTarget{
+			Type:     runpb.TrafficTargetAllocationType_TRAFFIC_TARGET_ALLOCATION_TYPE_REVISION,
+			Revision: s.Revision,
+			Percent:  s.Percent,
+			Tag:      s.Tag,
+		})
+	}
+	return targets, nil
+}
+
+// baseTraffic returns the targets other than revision, and the tags, from
+// the traffic targets of statusTraffic, or nil if no other revision serves
+// traffic.
+func baseTraffic(targets []*runpb.TrafficTarget, revision string) []*runpb.TrafficTarget {
+	var base []*runpb.TrafficTarget
+	var total int32
+	for _, t := range targets {
+		t = proto.Clone(t).(*runpb.TrafficTarget)
+		if t.Revision == revision {
+			t.Percent = 0
+		}
+		if t.Percent == 0 && t.Tag == "" {
+			continue
+		}
+		total += t.Percent
+		base = append(base, t)
+	}
+	if total == 0 {
+		return nil
+	}
+	return base
+}
+
+// split returns the traffic targets that send percent of the traffic to
+// revision, and the rest to the base targets, in proportion to their
+// percentages, rounded by the largest remainder method.
+func split(base []*runpb.TrafficTarget, revision string, percent int32) []*runpb.TrafficTarget {
+	var total int32
+	for _, t := range base {
+		total += t.Percent
+	}
+	rest := 100 - percent
+	targets := []*runpb.TrafficTarget{{
+		Type:     runpb.TrafficTargetAllocationType_TRAFFIC_TARGET_ALLOCATION_TYPE_REVISION,
+		Revision: revision,
+		Percent:  percent,
+	}}
+	remainders := make([]int32, len(base))
+	var assigned int32
+	for i, t := range base {
+		t = proto.Clone(t).(*runpb.TrafficTarget)
+		remainders[i] = t.Percent * rest % total
+		t.Percent = t.Percent * rest / total
+		assigned += t.Percent
+		targets = append(targets, t)
+	}
+	order := make([]int, len(base))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return remainders[order[i]] > remainders[order[j]] })
+	for _, i := range order[:rest-assigned] {
+		targets[1+i].Percent++
+	}
+	var out []*runpb.TrafficTarget
+	for _, t := range targets {
+		if t.Percent > 0 || t.Tag != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
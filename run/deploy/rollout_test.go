@@ -0,0 +1,138 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/run/apiv2/runpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeServices records the traffic of each update.
+type fakeServices struct {
+	svc     *runpb.Service
+	updates []string
+}
+
+func (f *fakeServices) getService(ctx context.Context, name string) (*runpb.Service, error) {
+	return proto.Clone(f.svc).(*runpb.Service), nil
+}
+
+func (f *fakeServices) updateService(ctx context.Context, svc *runpb.Service) (*runpb.Service, error) {
+	f.updates = append(f.updates, trafficString(svc.Traffic))
+	f.svc = proto.Clone(svc).(*runpb.Service)
+	return svc, nil
+}
+
+func trafficString(ts []*runpb.TrafficTarget) string {
+	var parts []string
+	for _, t := range ts {
+		s := fmt.Sprintf("%s=%d", t.Revision, t.Percent)
+		if t.Type == runpb.TrafficTargetAllocationType_TRAFFIC_TARGET_ALLOCATION_TYPE_LATEST {
+			s = fmt.Sprintf("LATEST=%d", t.Percent)
+		}
+		if t.Tag != "" {
+			s += "#" + t.Tag
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
+
+func newFakeServices() *fakeServices {
+	return &fakeServices{svc: &runpb.Service{
+		Name: "projects/p/locations/l/services/s",
+		Traffic: []*runpb.TrafficTarget{
+			{Type: runpb.TrafficTargetAllocationType_TRAFFIC_TARGET_ALLOCATION_TYPE_LATEST, Percent: 100},
+			{Type: runpb.TrafficTargetAllocationType_TRAFFIC_TARGET_ALLOCATION_TYPE_REVISION, Revision: "s-3", Tag: "next"},
+		},
+		TrafficStatuses: []*runpb.TrafficTargetStatus{
+			{Type: runpb.TrafficTargetAllocationType_TRAFFIC_TARGET_ALLOCATION_TYPE_LATEST, Revision: "s-1", Percent: 67},
+			{Type: runpb.TrafficTargetAllocationType_TRAFFIC_TARGET_ALLOCATION_TYPE_REVISION, Revision: "s-2", Percent: 33},
+			{Type: runpb.TrafficTargetAllocationType_TRAFFIC_TARGET_ALLOCATION_TYPE_REVISION, Revision: "s-3", Tag: "next"},
+		},
+	}}
+}
+
+func TestRollout(t *testing.T) {
+	f := newFakeServices()
+	var verified []int32
+	cfg := &RolloutConfig{
+		Steps: []int32{10, 50, 100},
+		Verify: func(ctx context.Context, percent int32) error {
+			verified = append(verified, percent)
+			return nil
+		},
+	}
+	if _, err := rollout(context.Background(), f, f.svc.Name, f.svc.Name+"/revisions/s-3", cfg); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"s-3=10 s-1=60 s-2=30 s-3=0#next",
+		"s-3=50 s-1=34 s-2=16 s-3=0#next",
+		"s-3=100 s-3=0#next",
+	}
+	if fmt.Sprint(f.updates) != fmt.Sprint(want) {
+		t.Errorf("got updates\n%q\nwant\n%q", f.updates, want)
+	}
+	if fmt.Sprint(verified) != "[10 50 100]" {
+		t.Errorf("got verified steps %v", verified)
+	}
+}
+
+func TestRolloutRollsBack(t *testing.T) {
+	f := newFakeServices()
+	verifyErr := errors.New("error rate too high")
+	cfg := &RolloutConfig{
+		Verify: func(ctx context.Context, percent int32) error {
+			if percent == 50 {
+				return verifyErr
+			}
+			return nil
+		},
+	}
+	_, err := rollout(context.Background(), f, f.svc.Name, "s-3", cfg)
+	if !errors.Is(err, verifyErr) {
+		t.Fatalf("got %v, want %v", err, verifyErr)
+	}
+	// The rollback names the revisions that served the traffic, rather than
+	// the latest revision, which is s-3 once it is deployed.
+	if got, want := f.updates[len(f.updates)-1], "s-1=67 s-2=33 s-3=0#next"; got != want {
+		t.Errorf("got traffic %q after the rollback, want %q", got, want)
+	}
+	if len(f.updates) != 3 {
+		t.Errorf("got %d updates, want 3", len(f.updates))
+	}
+}
+
+func TestRolloutErrors(t *testing.T) {
+	for _, steps := range [][]int32{{50, 10, 100}, {10, 50}, {0, 100}} {
+		f := newFakeServices()
+		if _, err := rollout(context.Background(), f, f.svc.Name, "s-3", &RolloutConfig{Steps: steps}); err == nil {
+			t.Errorf("steps %v: got nil, want error", steps)
+		}
+	}
+	// Nothing else serves traffic.
+	f := newFakeServices()
+	f.svc.TrafficStatuses = []*runpb.TrafficTargetStatus{{Revision: "s-3", Percent: 100}}
+	if _, err := rollout(context.Background(), f, f.svc.Name, "s-3", nil); err == nil {
+		t.Error("got nil, want error")
+	}
+}
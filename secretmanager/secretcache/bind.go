@@ -0,0 +1,191 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// Bind sets the fields of the struct pointed to by dst from the secrets named
+// in their "secret" tags:
+//
+//	type Config struct {
+//		Password string `secret:"projects/${PROJECT}/secrets/db-password"`
+//		TLSKey   []byte `secret:"projects/my-project/secrets/tls-key/versions/3"`
+//		OAuth    OAuth  `secret:"projects/my-project/secrets/oauth,json"`
+//	}
+//
+// The name in the tag is a secret version name. If it is a secret name, the
+// latest version is used. References to environment variables, such as
+// ${PROJECT}, are replaced by their values, as with os.ExpandEnv.
+//
+// A field of type string or []byte is set to the payload of the secret. With
+// the "json" option, the payload is decoded as JSON into a field of any type.
+// With the "optional" option, a secret that can't be accessed leaves the
+// field unchanged instead of making Bind fail. Untagged fields of struct type
+// are bound recursively; other untagged fields are ignored.
+//
+// Bind reads the secrets through the cache, so binding again later updates
+// the fields when the cached versions expire.
+func (c *Cache) Bind(ctx context.Context, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("secretcache: Bind needs a non-nil pointer to a struct, got %T", dst)
+	}
+	return c.bindStruct(ctx, v.Elem())
+}
+
+func (c *Cache) bindStruct(ctx context.Context, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, ok := sf.Tag.Lookup("secret")
+		if !ok {
+			if sf.Type.Kind() == reflect.Struct {
+				if err := c.bindStruct(ctx, v.Field(i)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := c.bindField(ctx, v.Field(i), sf, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cache) bindField(ctx context.Context, f reflect.Value, sf reflect.StructField, tag string) error {
+	name, opts, _ := strings.Cut(tag, ",")
+	var asJSON, optional bool
+	for _, o := range strings.Split(opts, ",") {
+		switch o {
+		case "":
+		case "json":
+			asJSON = true
+		case "optional":
+			optional = true
+		default:
+			return fmt.Errorf("secretcache: field %s: unknown option %q in secret tag", sf.Name, o)
+		}
+	}
+	if !asJSON && f.Kind() != reflect.String && f.Type() != bytesType {
+		return fmt.Errorf("secretcache: field %s has type %s; it must be a string or []byte, or use the json option", sf.Name, sf.Type)
+	}
+	name = versionName(os.ExpandEnv(name))
+	data, err := c.Get(ctx, name)
+	if err != nil {
+		if optional && ctx.Err() == nil {
+			return nil
+		}
+		return fmt.Errorf("secretcache: field %s: %w", sf.Name, err)
+	}
+	switch {
+	case asJSON:
+		// Decode into a new value, so that fields missing from the JSON
+		// don't keep values of a previous version.
+		p := reflect.New(sf.Type)
+		if err := json.Unmarshal(data, p.Interface()); err != nil {
+			return fmt.Errorf("secretcache: field %s: decoding %s: %w", sf.Name, name, err)
+		}
+		f.Set(p.Elem())
+	case f.Kind() == reflect.String:
+		f.SetString(string(data))
+	default:
+		f.SetBytes(append([]byte(nil), data...))
+	}
+	return nil
+}
+
+// versionName returns the name of the latest version of the secret if name is
+// the name of a secret, and name otherwise.
+func versionName(name string) string {
+	if strings.Contains(name, "/versions/") {
+		return name
+	}
+	return strings.TrimSuffix(name, "/") + "/versions/latest"
+}
+
+// SetEnv sets each environment variable in vars to the payload of the secret
+// named by its value, as in the tags of Bind. It can be used to configure
+// code that reads its secrets from the environment. No variable is set if a
+// secret can't be accessed.
+func (c *Cache) SetEnv(ctx context.Context, vars map[string]string) error {
+	values := make(map[string]string, len(vars))
+	for k, name := range vars {
+		data, err := c.Get(ctx, versionName(os.ExpandEnv(name)))
+		if err != nil {
+			return fmt.Errorf("secretcache: variable %s: %w", k, err)
+		}
+		values[k] = string(data)
+	}
+	for k, v := range values {
+		if err := os.Setenv(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Watch binds a new value of type T, which must be a struct type, with
+// Cache.Bind, every interval until ctx is done, and calls f with it when it
+// differs from the previous one. f is first called with the initial value. If
+// binding fails, f is called with the error, and the previous value remains
+// current. Watch blocks until ctx is done and then returns ctx.Err().
+//
+// The secrets are read through the cache, so a change is seen once the
+// cached version expires or is invalidated, at the next interval.
+func Watch[T any](ctx context.Context, c *Cache, interval time.Duration, f func(*T, error)) error {
+	if interval <= 0 {
+		return errors.New("secretcache: Watch needs a positive interval")
+	}
+	var last *T
+	bind := func() {
+		v := new(T)
+		if err := c.Bind(ctx, v); err != nil {
+			if ctx.Err() == nil {
+				f(nil, err)
+			}
+			return
+		}
+		if last == nil || !reflect.DeepEqual(last, v) {
+			last = v
+			f(v, nil)
+		}
+	}
+	bind()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			bind()
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretcache
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/internal/testutil"
+)
+
+type oauth struct {
+	ClientID string `json:"client_id"`
+	Secret   string `json:"secret"`
+}
+
+type config struct {
+	Password string `secret:"projects/${SECRETCACHE_PROJECT}/secrets/password"`
+	Key      []byte `secret:"projects/p/secrets/key/versions/3"`
+	OAuth    oauth  `secret:"projects/p/secrets/oauth,json"`
+	Missing  string `secret:"projects/p/secrets/missing,optional"`
+	Nested   struct {
+		Token string `secret:"projects/p/secrets/token"`
+	}
+	Plain string
+}
+
+func newBindClient() *fakeClient {
+	return newFakeClient(map[string]string{
+		"projects/p/secrets/password/versions/latest": "pw",
+		"projects/p/secrets/key/versions/3":           "key",
+		"projects/p/secrets/oauth/versions/latest":    `{"client_id": "id", "secret": "s"}`,
+		"projects/p/secrets/token/versions/latest":    "tok",
+	})
+}
+
+func TestBind(t *testing.T) {
+	t.Setenv("SECRETCACHE_PROJECT", "p")
+	c := New(newBindClient(), nil)
+	got := config{Missing: "default", Plain: "plain"}
+	if err := c.Bind(context.Background(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := config{
+		Password: "pw",
+		Key:      []byte("key"),
+		OAuth:    oauth{ClientID: "id", Secret: "s"},
+		Missing:  "default",
+		Plain:    "plain",
+	}
+	want.Nested.Token = "tok"
+	if !testutil.Equal(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	c := New(newBindClient(), nil)
+	ctx := context.Background()
+	var s string
+	for _, dst := range []interface{}{
+		nil,
+		&s,
+		config{},
+		&struct {
+			N int `secret:"projects/p/secrets/key"`
+		}{},
+		&struct {
+			S string `secret:"projects/p/secrets/key,bogus"`
+		}{},
+		&struct {
+			S string `secret:"projects/p/secrets/missing"`
+		}{},
+		&struct {
+			O oauth `secret:"projects/p/secrets/password,json"`
+		}{},
+	} {
+		if err := c.Bind(ctx, dst); err == nil {
+			t.Errorf("%T: got nil, want error", dst)
+		}
+	}
+}
+
+func TestSetEnv(t *testing.T) {
+	t.Setenv("SECRETCACHE_PW", "")
+	t.Setenv("SECRETCACHE_KEY", "")
+	c := New(newBindClient(), nil)
+	ctx := context.Background()
+	err := c.SetEnv(ctx, map[string]string{
+		"SECRETCACHE_PW":  "projects/p/secrets/password",
+		"SECRETCACHE_KEY": "projects/p/secrets/missing",
+	})
+	if err == nil {
+		t.Fatal("got nil, want error")
+	}
+	if got := os.Getenv("SECRETCACHE_PW"); got != "" {
+		t.Errorf("got %q, want no variable set after an error", got)
+	}
+	if err := c.SetEnv(ctx, map[string]string{"SECRETCACHE_PW": "projects/p/secrets/password"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("SECRETCACHE_PW"); got != "pw" {
+		t.Errorf("got %q, want pw", got)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	type tokenConfig struct {
+		Token string `secret:"projects/p/secrets/token"`
+	}
+	f := newBindClient()
+	c := New(f, &Options{TTL: time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	values := make(chan string, 10)
+	done := make(chan error)
+	go func() {
+		done <- Watch(ctx, c, time.Millisecond, func(cfg *tokenConfig, err error) {
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			values <- cfg.Token
+		})
+	}()
+	if got := <-values; got != "tok" {
+		t.Errorf("got %q, want tok", got)
+	}
+	f.set("projects/p/secrets/token/versions/latest", "tok2")
+	if got := <-values; got != "tok2" {
+		t.Errorf("got %q, want tok2", got)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	// The value didn't change, so f was called only once per value.
+	if len(values) != 0 {
+		t.Errorf("got %d extra calls", len(values))
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secretcache provides a client-side cache of secret versions
+// accessed with the Secret Manager API.
+//
+// A Cache accesses each secret version once and serves it from memory until
+// its time to live expires, so that services can read secrets on every request
+// without calling Secret Manager each time:
+//
+//	client, err := secretmanager.NewClient(ctx)
+//	if err != nil {
+//		// TODO: Handle error.
+//	}
+//	cache := secretcache.New(client, &secretcache.Options{TTL: 10 * time.Minute})
+//	password, err := cache.Get(ctx, "projects/my-project/secrets/db-password/versions/latest")
+//
+// Cached versions can be invalidated as soon as a secret changes by passing
+// the Pub/Sub notifications of the secret to Cache.HandleNotification. See
+// https://cloud.google.com/secret-manager/docs/event-notifications.
+//
+// Cache.Bind sets the fields of a configuration struct from the secrets named
+// in their tags, and Watch binds it again periodically to pick up new
+// versions.
+package secretcache // import "cloud.google.com/go/secretmanager/secretcache"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+// Client is the part of the Secret Manager client used by a Cache. It is
+// implemented by *secretmanager.Client.
+type Client interface {
+	AccessSecretVersion(ctx context.Context, req *secretmanagerpb.AccessSecretVersionRequest, opts ...gax.CallOption) (*secretmanagerpb.AccessSecretVersionResponse, error)
+}
+
+// Options configures a Cache.
+type Options struct {
+	// TTL is how long an accessed secret version is cached. The default is
+	// 5 minutes.
+	TTL time.Duration
+
+	// Jitter, if positive, shortens the time to live of each cached version by
+	// a random duration of up to Jitter, so that the versions accessed at the
+	// same time, for example when a service starts, are not all refreshed at
+	// the same time.
+	Jitter time.Duration
+
+	// RefreshBefore, if positive, enables background refreshes: a version that
+	// is read less than RefreshBefore before it expires is refreshed in the
+	// background, while the cached value keeps being returned. Versions that
+	// are read regularly are then never accessed synchronously after the first
+	// time. If a background refresh fails, the cached value is used until it
+	// expires.
+	RefreshBefore time.Duration
+
+	// Timeout is the timeout of the calls to Secret Manager. The calls are
+	// shared by the concurrent Get calls for a version, so they don't use the
+	// context of any of them. The default is 30 seconds.
+	Timeout time.Duration
+}
+
+const (
+	defaultTTL     = 5 * time.Minute
+	defaultTimeout = 30 * time.Second
+)
+
+// A Cache caches the payloads of secret versions. It is safe for concurrent
+// use.
+type Cache struct {
+	client Client
+	opts   Options
+
+	mu      sync.Mutex
+	entries map[string]*entry // by version name
+	loads   map[string]*load  // by version name
+	gens    map[string]uint64 // by version name, incremented by Invalidate
+}
+
+// entry is a cached version.
+type entry struct {
+	data      []byte
+	expires   time.Time
+	refreshAt time.Time // zero if the entry is not refreshed in the background
+}
+
+// load is a call to Secret Manager in progress.
+type load struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// New returns a Cache that accesses secret versions with client. opts may be
+// nil.
+func New(client Client, opts *Options) *Cache {
+	c := &Cache{
+		client:  client,
+		entries: map[string]*entry{},
+		loads:   map[string]*load{},
+		gens:    map[string]uint64{},
+	}
+	if opts != nil {
+		c.opts = *opts
+	}
+	if c.opts.TTL <= 0 {
+		c.opts.TTL = defaultTTL
+	}
+	if c.opts.Timeout <= 0 {
+		c.opts.Timeout = defaultTimeout
+	}
+	return c
+}
+
+// Get returns the payload of the secret version with the given resource name,
+// in the format projects/*/secrets/*/versions/*. Aliases such as "latest" are
+// cached under the alias, so a new version is only seen once the cached one
+// expires or is invalidated.
+//
+// Concurrent calls for a version that is not cached share a single call to
+// Secret Manager. If ctx is done before it returns, Get returns ctx.Err().
+func (c *Cache) Get(ctx context.Context, name string) ([]byte, error) {
+	now := time.Now()
+	c.mu.Lock()
+	if e, ok := c.entries[name]; ok && now.Before(e.expires) {
+		if !e.refreshAt.IsZero() && !now.Before(e.refreshAt) && c.loads[name] == nil {
+			// Refresh at most once per entry.
+			e.refreshAt = time.Time{}
+			c.startLoad(name)
+		}
+		c.mu.Unlock()
+		return append([]byte(nil), e.data...), nil
+	}
+	l := c.loads[name]
+	if l == nil {
+		l = c.startLoad(name)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-l.done:
+		if l.err != nil {
+			return nil, l.err
+		}
+		return append([]byte(nil), l.data...), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// startLoad starts accessing the named version. c.mu must be held.
+func (c *Cache) startLoad(name string) *load {
+	l := &load{done: make(chan struct{})}
+	c.loads[name] = l
+	gen := c.gens[name]
+	go func() {
+		data, err := c.access(name)
+		now := time.Now()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.loads, name)
+		// Don't cache versions that were invalidated while they were
+		// accessed, since they may be stale.
+		if err == nil && c.gens[name] == gen {
+			c.entries[name] = c.newEntry(data, now)
+		}
+		l.data, l.err = data, err
+		close(l.done)
+	}()
+	return l
+}
+
+func (c *Cache) newEntry(data []byte, now time.Time) *entry {
+	ttl := c.opts.TTL
+	if c.opts.Jitter > 0 {
+		ttl -= time.Duration(rand.Int63n(int64(c.opts.Jitter)))
+	}
+	e := &entry{data: data, expires: now.Add(ttl)}
+	if c.opts.RefreshBefore > 0 {
+		e.refreshAt = e.expires.Add(-c.opts.RefreshBefore)
+	}
+	return e
+}
+
+// errChecksum is returned when the checksum of an accessed payload doesn't
+// match its data.
+var errChecksum = errors.New("secretcache: payload checksum mismatch")
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// access accesses the named version and verifies its checksum.
+func (c *Cache) access(name string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.opts.Timeout)
+	defer cancel()
+	resp, err := c.client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	p := resp.GetPayload()
+	if p != nil && p.DataCrc32C != nil && int64(crc32.Checksum(p.GetData(), crc32cTable)) != p.GetDataCrc32C() {
+		return nil, fmt.Errorf("%w for %s", errChecksum, name)
+	}
+	return p.GetData(), nil
+}
+
+// Invalidate removes the named version from the cache, so that it is accessed
+// again by the next call to Get.
+func (c *Cache) Invalidate(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.invalidate(name)
+}
+
+// invalidate removes the named version. c.mu must be held.
+func (c *Cache) invalidate(name string) {
+	delete(c.entries, name)
+	c.gens[name]++
+}
+
+// HandleNotification invalidates the cached versions of the secret that a
+// Secret Manager Pub/Sub notification is about, given the attributes of the
+// notification message, and reports whether it was a notification about a
+// secret. It can be called from the function passed to
+// pubsub.Subscription.Receive:
+//
+//	err := sub.Receive(ctx, func(ctx context.Context, m *pubsub.Message) {
+//		cache.HandleNotification(m.Attributes)
+//		m.Ack()
+//	})
+//
+// Notifications name secrets by project number, while versions are often
+// accessed by project ID, so all the cached versions of secrets with the same
+// secret ID are invalidated, regardless of their project.
+func (c *Cache) HandleNotification(attrs map[string]string) bool {
+	if attrs["eventType"] == "" || attrs["eventType"] == "TOPIC_CONFIGURED" {
+		return false
+	}
+	secretID := secretIDOf(attrs["secretId"])
+	if secretID == "" {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name := range c.entries {
+		if secretIDOf(name) == secretID {
+			c.invalidate(name)
+		}
+	}
+	for name := range c.loads {
+		if secretIDOf(name) == secretID {
+			c.invalidate(name)
+		}
+	}
+	return true
+}
+
+// secretIDOf returns the secret ID in the resource name of a secret or a
+// secret version, or "" if name is not such a resource name.
+func secretIDOf(name string) string {
+	parts := strings.Split(name, "/")
+	if parts[0] != "projects" {
+		return ""
+	}
+	// Regional secrets have a locations/* segment before secrets/*.
+	for i := 2; i+1 < len(parts); i += 2 {
+		if parts[i] == "secrets" {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,208 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretcache
+
+import (
+	"context"
+	"errors"
+	"hash/crc32"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+// fakeClient returns the payloads in data, and counts the calls per version.
+type fakeClient struct {
+	mu    sync.Mutex
+	data  map[string]string
+	calls map[string]int
+	block chan struct{} // if not nil, calls wait until it is closed
+}
+
+func newFakeClient(data map[string]string) *fakeClient {
+	return &fakeClient{data: data, calls: map[string]int{}}
+}
+
+func (f *fakeClient) AccessSecretVersion(ctx context.Context, req *secretmanagerpb.AccessSecretVersionRequest, _ ...gax.CallOption) (*secretmanagerpb.AccessSecretVersionResponse, error) {
+	if f.block != nil {
+		<-f.block
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[req.Name]++
+	d, ok := f.data[req.Name]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	crc := int64(crc32.Checksum([]byte(d), crc32cTable))
+	return &secretmanagerpb.AccessSecretVersionResponse{
+		Name:    req.Name,
+		Payload: &secretmanagerpb.SecretPayload{Data: []byte(d), DataCrc32C: &crc},
+	}, nil
+}
+
+func (f *fakeClient) set(name, data string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.data[name] = data
+}
+
+func (f *fakeClient) count(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[name]
+}
+
+const version = "projects/p/secrets/s/versions/latest"
+
+func get(t *testing.T, c *Cache, name string) string {
+	t.Helper()
+	b, err := c.Get(context.Background(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestGet(t *testing.T) {
+	f := newFakeClient(map[string]string{version: "v1"})
+	c := New(f, nil)
+	for i := 0; i < 3; i++ {
+		if got := get(t, c, version); got != "v1" {
+			t.Errorf("got %q, want v1", got)
+		}
+	}
+	if got := f.count(version); got != 1 {
+		t.Errorf("got %d calls, want 1", got)
+	}
+
+	f.set(version, "v2")
+	c.Invalidate(version)
+	if got := get(t, c, version); got != "v2" {
+		t.Errorf("got %q after Invalidate, want v2", got)
+	}
+
+	if _, err := c.Get(context.Background(), "projects/p/secrets/missing/versions/1"); err == nil {
+		t.Error("got nil, want error for a missing version")
+	}
+}
+
+func TestGetExpired(t *testing.T) {
+	f := newFakeClient(map[string]string{version: "v1"})
+	c := New(f, &Options{TTL: time.Millisecond})
+	get(t, c, version)
+	time.Sleep(5 * time.Millisecond)
+	get(t, c, version)
+	if got := f.count(version); got != 2 {
+		t.Errorf("got %d calls, want 2", got)
+	}
+}
+
+func TestGetConcurrent(t *testing.T) {
+	f := newFakeClient(map[string]string{version: "v1"})
+	f.block = make(chan struct{})
+	c := New(f, nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			get(t, c, version)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(f.block)
+	wg.Wait()
+	if got := f.count(version); got != 1 {
+		t.Errorf("got %d calls, want 1", got)
+	}
+}
+
+func TestGetContextDone(t *testing.T) {
+	f := newFakeClient(map[string]string{version: "v1"})
+	f.block = make(chan struct{})
+	defer close(f.block)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := New(f, nil).Get(ctx, version); err != context.Canceled {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
+
+func TestRefreshBefore(t *testing.T) {
+	f := newFakeClient(map[string]string{version: "v1"})
+	c := New(f, &Options{TTL: time.Hour, RefreshBefore: time.Hour})
+	get(t, c, version)
+	f.set(version, "v2")
+	// The entry is due for a refresh, so the cached value is returned while
+	// it is refreshed.
+	if got := get(t, c, version); got != "v1" {
+		t.Errorf("got %q, want the cached v1", got)
+	}
+	got := ""
+	for i := 0; i < 100 && got != "v2"; i++ {
+		time.Sleep(time.Millisecond)
+		got = get(t, c, version)
+	}
+	if got != "v2" {
+		t.Errorf("got %q after the refresh, want v2", got)
+	}
+	if got := f.count(version); got != 2 {
+		t.Errorf("got %d calls, want 2", got)
+	}
+}
+
+func TestHandleNotification(t *testing.T) {
+	other := "projects/p/secrets/other/versions/1"
+	regional := "projects/p/locations/us-east1/secrets/s/versions/2"
+	f := newFakeClient(map[string]string{version: "v1", other: "o", regional: "r"})
+	c := New(f, nil)
+	for _, name := range []string{version, other, regional} {
+		get(t, c, name)
+	}
+	if c.HandleNotification(map[string]string{"eventType": "TOPIC_CONFIGURED", "secretId": "projects/123/secrets/s"}) {
+		t.Error("got true for TOPIC_CONFIGURED, want false")
+	}
+	if !c.HandleNotification(map[string]string{"eventType": "SECRET_VERSION_ADD", "secretId": "projects/123/secrets/s"}) {
+		t.Error("got false for SECRET_VERSION_ADD, want true")
+	}
+	for _, name := range []string{version, other, regional} {
+		get(t, c, name)
+	}
+	for name, want := range map[string]int{version: 2, other: 1, regional: 2} {
+		if got := f.count(name); got != want {
+			t.Errorf("%s: got %d calls, want %d", name, got, want)
+		}
+	}
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	bad := int64(1)
+	c := New(clientFunc(func(*secretmanagerpb.AccessSecretVersionRequest) *secretmanagerpb.AccessSecretVersionResponse {
+		return &secretmanagerpb.AccessSecretVersionResponse{Payload: &secretmanagerpb.SecretPayload{Data: []byte("x"), DataCrc32C: &bad}}
+	}), nil)
+	if _, err := c.Get(context.Background(), version); !errors.Is(err, errChecksum) {
+		t.Errorf("got %v, want checksum error", err)
+	}
+}
+
+type clientFunc func(*secretmanagerpb.AccessSecretVersionRequest) *secretmanagerpb.AccessSecretVersionResponse
+
+func (f clientFunc) AccessSecretVersion(_ context.Context, req *secretmanagerpb.AccessSecretVersionRequest, _ ...gax.CallOption) (*secretmanagerpb.AccessSecretVersionResponse, error) {
+	return f(req), nil
+}
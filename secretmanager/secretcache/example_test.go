@@ -0,0 +1,89 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretcache_test
+
+import (
+	"context"
+	"time"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/secretcache"
+)
+
+func ExampleNew() {
+	ctx := context.Background()
+	client, err := secretmanager.NewClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	cache := secretcache.New(client, &secretcache.Options{
+		TTL:           10 * time.Minute,
+		Jitter:        time.Minute,
+		RefreshBefore: 2 * time.Minute,
+	})
+	password, err := cache.Get(ctx, "projects/my-project/secrets/db-password/versions/latest")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	_ = password // TODO: Use password.
+}
+
+func ExampleCache_Bind() {
+	ctx := context.Background()
+	client, err := secretmanager.NewClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	var config struct {
+		DBPassword string `secret:"projects/${PROJECT}/secrets/db-password"`
+		OAuth      struct {
+			ClientID     string `json:"client_id"`
+			ClientSecret string `json:"client_secret"`
+		} `secret:"projects/my-project/secrets/oauth,json"`
+	}
+	cache := secretcache.New(client, nil)
+	if err := cache.Bind(ctx, &config); err != nil {
+		// TODO: Handle error.
+	}
+	// TODO: Use config.
+}
+
+func ExampleWatch() {
+	ctx := context.Background()
+	client, err := secretmanager.NewClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	type Config struct {
+		APIKey string `secret:"projects/my-project/secrets/api-key"`
+	}
+	cache := secretcache.New(client, &secretcache.Options{TTL: time.Minute})
+	err = secretcache.Watch(ctx, cache, time.Minute, func(c *Config, err error) {
+		if err != nil {
+			// TODO: Handle error.
+			return
+		}
+		_ = c.APIKey // TODO: Use the new configuration.
+	})
+	if err != nil {
+		// TODO: Handle error.
+	}
+}
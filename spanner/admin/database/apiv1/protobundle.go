@@ -0,0 +1,123 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ProtoDescriptors returns the serialized FileDescriptorSet that contains the
+// files that define the given message and enum types, including all the files
+// that these files depend on. The result can be used as the ProtoDescriptors
+// of a CreateDatabaseRequest or an UpdateDatabaseDdlRequest that creates or
+// alters a proto bundle with the same types.
+//
+// The descriptors of generated Go types can be obtained with
+// (&pb.MyMessage{}).ProtoReflect().Descriptor() and pb.MyEnum(0).Descriptor().
+func ProtoDescriptors(types ...protoreflect.Descriptor) ([]byte, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]bool)
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, t := range types {
+		if err := validateProtoBundleType(t); err != nil {
+			return nil, err
+		}
+		add(t.ParentFile())
+	}
+	return proto.Marshal(set)
+}
+
+// CreateProtoBundleStatement returns a CREATE PROTO BUNDLE statement for the
+// given message and enum types. Nested types must be specified explicitly if
+// they are used as the type of a column.
+func CreateProtoBundleStatement(types ...protoreflect.Descriptor) (string, error) {
+	names, err := protoBundleTypeNames(types)
+	if err != nil {
+		return "", err
+	}
+	if names == "" {
+		return "", fmt.Errorf("database: a proto bundle must contain at least one type")
+	}
+	return fmt.Sprintf("CREATE PROTO BUNDLE (%s)", names), nil
+}
+
+// AlterProtoBundleStatement returns an ALTER PROTO BUNDLE statement that
+// inserts, updates and deletes the given message and enum types from the proto
+// bundle of a database. Any of the lists may be empty, but at least one type
+// must be specified. The ProtoDescriptors of the UpdateDatabaseDdlRequest that
+// executes the statement must contain all the inserted and updated types.
+func AlterProtoBundleStatement(insert, update, del []protoreflect.Descriptor) (string, error) {
+	var b strings.Builder
+	b.WriteString("ALTER PROTO BUNDLE")
+	for _, clause := range []struct {
+		keyword string
+		types   []protoreflect.Descriptor
+	}{
+		{"INSERT", insert},
+		{"UPDATE", update},
+		{"DELETE", del},
+	} {
+		names, err := protoBundleTypeNames(clause.types)
+		if err != nil {
+			return "", err
+		}
+		if names != "" {
+			fmt.Fprintf(&b, " %s (%s)", clause.keyword, names)
+		}
+	}
+	if len(insert)+len(update)+len(del) == 0 {
+		return "", fmt.Errorf("database: at least one type must be inserted, updated or deleted")
+	}
+	return b.String(), nil
+}
+
+func protoBundleTypeNames(types []protoreflect.Descriptor) (string, error) {
+	names := make([]string, len(types))
+	for i, t := range types {
+		if err := validateProtoBundleType(t); err != nil {
+			return "", err
+		}
+		names[i] = string(t.FullName())
+	}
+	return strings.Join(names, ", "), nil
+}
+
+func validateProtoBundleType(t protoreflect.Descriptor) error {
+	switch t.(type) {
+	case protoreflect.MessageDescriptor, protoreflect.EnumDescriptor:
+		return nil
+	case nil:
+		return fmt.Errorf("database: nil proto descriptor")
+	default:
+		return fmt.Errorf("database: %s is not a message or enum type", t.FullName())
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"testing"
+
+	"cloud.google.com/go/spanner/admin/database/apiv1/databasepb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestProtoDescriptors(t *testing.T) {
+	b, err := ProtoDescriptors(
+		(&databasepb.Database{}).ProtoReflect().Descriptor(),
+		databasepb.DatabaseDialect(0).Descriptor(),
+		(&structpb.Struct{}).ProtoReflect().Descriptor(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(b, set); err != nil {
+		t.Fatal(err)
+	}
+	// The set must be self-contained and every file must be included only once.
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		t.Fatalf("invalid descriptor set: %v", err)
+	}
+	if g, w := files.NumFiles(), len(set.File); g != w {
+		t.Fatalf("file count mismatch\nGot: %d\nWant: %d", g, w)
+	}
+	for _, name := range []protoreflect.FullName{
+		"google.spanner.admin.database.v1.Database",
+		"google.spanner.admin.database.v1.DatabaseDialect",
+		"google.protobuf.Struct",
+	} {
+		if _, err := files.FindDescriptorByName(name); err != nil {
+			t.Errorf("descriptor %s not found: %v", name, err)
+		}
+	}
+
+	if _, err := ProtoDescriptors((&databasepb.Database{}).ProtoReflect().Descriptor().Fields().Get(0)); err == nil {
+		t.Fatal("missing error for field descriptor")
+	}
+}
+
+func TestCreateProtoBundleStatement(t *testing.T) {
+	got, err := CreateProtoBundleStatement(
+		(&databasepb.Database{}).ProtoReflect().Descriptor(),
+		databasepb.DatabaseDialect(0).Descriptor(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "CREATE PROTO BUNDLE (google.spanner.admin.database.v1.Database, google.spanner.admin.database.v1.DatabaseDialect)"
+	if got != want {
+		t.Errorf("statement mismatch\nGot: %q\nWant: %q", got, want)
+	}
+	if _, err := CreateProtoBundleStatement(); err == nil {
+		t.Fatal("missing error for empty proto bundle")
+	}
+}
+
+func TestAlterProtoBundleStatement(t *testing.T) {
+	database := (&databasepb.Database{}).ProtoReflect().Descriptor()
+	dialect := databasepb.DatabaseDialect(0).Descriptor()
+	backup := (&databasepb.Backup{}).ProtoReflect().Descriptor()
+
+	for _, test := range []struct {
+		insert, update, del []protoreflect.Descriptor
+		want                string
+	}{
+		{
+			insert: []protoreflect.Descriptor{database},
+			want:   "ALTER PROTO BUNDLE INSERT (google.spanner.admin.database.v1.Database)",
+		},
+		{
+			update: []protoreflect.Descriptor{database, dialect},
+			del:    []protoreflect.Descriptor{backup},
+			want:   "ALTER PROTO BUNDLE UPDATE (google.spanner.admin.database.v1.Database, google.spanner.admin.database.v1.DatabaseDialect) DELETE (google.spanner.admin.database.v1.Backup)",
+		},
+		{
+			insert: []protoreflect.Descriptor{backup},
+			update: []protoreflect.Descriptor{dialect},
+			del:    []protoreflect.Descriptor{database},
+			want:   "ALTER PROTO BUNDLE INSERT (google.spanner.admin.database.v1.Backup) UPDATE (google.spanner.admin.database.v1.DatabaseDialect) DELETE (google.spanner.admin.database.v1.Database)",
+		},
+	} {
+		got, err := AlterProtoBundleStatement(test.insert, test.update, test.del)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("statement mismatch\nGot: %q\nWant: %q", got, test.want)
+		}
+	}
+	if _, err := AlterProtoBundleStatement(nil, nil, nil); err == nil {
+		t.Fatal("missing error for empty alter statement")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/gob"
 	"log"
+	"sync"
 	"time"
 
 	"cloud.google.com/go/internal/trace"
@@ -160,7 +161,7 @@ func (t *BatchReadOnlyTransaction) PartitionReadUsingIndexWithOptions(ctx contex
 		Index:               index,
 		Columns:             columns,
 		KeySet:              kset,
-		RequestOptions:      createRequestOptions(readOptions.Priority, readOptions.RequestTag, ""),
+		RequestOptions:      withContextRequestOptions(ctx, createRequestOptions(readOptions.Priority, readOptions.RequestTag, "")),
 		DataBoostEnabled:    readOptions.DataBoostEnabled,
 		DirectedReadOptions: readOptions.DirectedReadOptions,
 	}
@@ -228,7 +229,7 @@ func (t *BatchReadOnlyTransaction) partitionQuery(ctx context.Context, statement
 		Params:              params,
 		ParamTypes:          paramTypes,
 		QueryOptions:        qOpts.Options,
-		RequestOptions:      createRequestOptions(qOpts.Priority, qOpts.RequestTag, ""),
+		RequestOptions:      withContextRequestOptions(ctx, createRequestOptions(qOpts.Priority, qOpts.RequestTag, "")),
 		DataBoostEnabled:    qOpts.DataBoostEnabled,
 		DirectedReadOptions: qOpts.DirectedReadOptions,
 	}
@@ -389,6 +390,95 @@ func (t *BatchReadOnlyTransaction) Execute(ctx context.Context, p *Partition) *R
 		t.release)
 }
 
+// ExecutePartitions runs the given partitions using at most maxWorkers
+// concurrent goroutines and calls f for every row that is returned. f may be
+// called concurrently from multiple goroutines, and the order in which rows are
+// delivered is undefined. If maxWorkers is less than or equal to zero, all
+// partitions are executed concurrently.
+//
+// If f or the execution of any partition returns an error, no new partitions
+// are started, the context that is passed to the partitions that are still
+// running is cancelled, and the first error is returned.
+//
+// ExecutePartitions is a convenience for running all partitions in a single
+// process. Use DistributePartitions to spread the partitions across multiple
+// processes or machines. Set DataBoostEnabled in the QueryOptions or
+// ReadOptions that are used to create the partitions to execute them on
+// Spanner Data Boost independent compute resources.
+func (t *BatchReadOnlyTransaction) ExecutePartitions(ctx context.Context, partitions []*Partition, maxWorkers int, f func(p *Partition, r *Row) error) error {
+	if maxWorkers <= 0 || maxWorkers > len(partitions) {
+		maxWorkers = len(partitions)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	work := make(chan *Partition)
+	for i := 0; i < maxWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range work {
+				if ctx.Err() != nil {
+					continue
+				}
+				iter := t.Execute(ctx, p)
+				err := iter.Do(func(r *Row) error {
+					return f(p, r)
+				})
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					cancel()
+				}
+			}
+		}()
+	}
+loop:
+	for _, p := range partitions {
+		select {
+		case work <- p:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// DistributePartitions splits the given partitions into n groups of roughly
+// equal size. Each group can be serialized and sent to a separate worker
+// process, which can then execute the partitions using
+// BatchReadOnlyTransaction.Execute or BatchReadOnlyTransaction.ExecutePartitions
+// on a transaction that has been re-created with
+// Client.BatchReadOnlyTransactionFromID.
+//
+// Partitions are assigned to groups in a round-robin fashion. If n is larger
+// than the number of partitions, the trailing groups are empty. n must be
+// greater than zero.
+func DistributePartitions(partitions []*Partition, n int) [][]*Partition {
+	if n <= 0 {
+		return nil
+	}
+	groups := make([][]*Partition, n)
+	for i, p := range partitions {
+		groups[i%n] = append(groups[i%n], p)
+	}
+	return groups
+}
+
 // MarshalBinary implements BinaryMarshaler.
 func (tid BatchReadOnlyTransactionID) MarshalBinary() (data []byte, err error) {
 	var buf bytes.Buffer
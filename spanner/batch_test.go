@@ -18,6 +18,7 @@ package spanner
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -242,3 +243,140 @@ func TestPartitionQuery_Parallel(t *testing.T) {
 		t.Errorf("Row count mismatch\nGot: %d\nWant: %d", g, w)
 	}
 }
+
+func TestPartitionQuery_DataBoost(t *testing.T) {
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	txn, err := client.BatchReadOnlyTransaction(ctx, StrongRead())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer txn.Cleanup(ctx)
+	ps, err := txn.PartitionQueryWithOptions(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), PartitionOptions{0, 3}, QueryOptions{DataBoostEnabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range ps {
+		server.TestSpanner.PutPartitionResult(p.pt, server.CreateSingleRowSingersResult(int64(i)))
+	}
+	drainRequestsFromServer(server.TestSpanner)
+	if err := txn.ExecutePartitions(ctx, ps, 0, func(p *Partition, r *Row) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if sqlReq, ok := req.(*sppb.ExecuteSqlRequest); ok {
+			count++
+			if !sqlReq.DataBoostEnabled {
+				t.Errorf("DataBoostEnabled mismatch for partition %v\nGot: false\nWant: true", sqlReq.PartitionToken)
+			}
+		}
+	}
+	if g, w := count, len(ps); g != w {
+		t.Errorf("ExecuteSqlRequest count mismatch\nGot: %d\nWant: %d", g, w)
+	}
+}
+
+func TestExecutePartitions(t *testing.T) {
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	txn, err := client.BatchReadOnlyTransaction(ctx, StrongRead())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer txn.Cleanup(ctx)
+	ps, err := txn.PartitionQuery(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), PartitionOptions{0, 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range ps {
+		server.TestSpanner.PutPartitionResult(p.pt, server.CreateSingleRowSingersResult(int64(i)))
+	}
+
+	for _, workers := range []int{0, 1, 3, 100} {
+		var (
+			mu    sync.Mutex
+			total int64
+		)
+		err := txn.ExecutePartitions(ctx, ps, workers, func(p *Partition, r *Row) error {
+			mu.Lock()
+			defer mu.Unlock()
+			total++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("workers=%d: %v", workers, err)
+		}
+		if g, w := total, SelectSingerIDAlbumIDAlbumTitleFromAlbumsRowCount; g != w {
+			t.Errorf("workers=%d: row count mismatch\nGot: %d\nWant: %d", workers, g, w)
+		}
+	}
+}
+
+func TestExecutePartitions_Error(t *testing.T) {
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	txn, err := client.BatchReadOnlyTransaction(ctx, StrongRead())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer txn.Cleanup(ctx)
+	ps, err := txn.PartitionQuery(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), PartitionOptions{0, 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range ps {
+		server.TestSpanner.PutPartitionResult(p.pt, server.CreateSingleRowSingersResult(int64(i)))
+	}
+
+	want := errors.New("test error")
+	err = txn.ExecutePartitions(ctx, ps, 2, func(p *Partition, r *Row) error {
+		return want
+	})
+	if !errors.Is(err, want) {
+		t.Fatalf("error mismatch\nGot: %v\nWant: %v", err, want)
+	}
+}
+
+func TestDistributePartitions(t *testing.T) {
+	t.Parallel()
+	ps := make([]*Partition, 7)
+	for i := range ps {
+		ps[i] = &Partition{pt: []byte{byte(i)}}
+	}
+	for _, test := range []struct {
+		n    int
+		want []int
+	}{
+		{1, []int{7}},
+		{2, []int{4, 3}},
+		{3, []int{3, 2, 2}},
+		{10, []int{1, 1, 1, 1, 1, 1, 1, 0, 0, 0}},
+	} {
+		groups := DistributePartitions(ps, test.n)
+		if g, w := len(groups), len(test.want); g != w {
+			t.Fatalf("n=%d: group count mismatch\nGot: %d\nWant: %d", test.n, g, w)
+		}
+		seen := make(map[byte]bool)
+		for i, g := range groups {
+			if got, want := len(g), test.want[i]; got != want {
+				t.Errorf("n=%d: size of group %d mismatch\nGot: %d\nWant: %d", test.n, i, got, want)
+			}
+			for _, p := range g {
+				seen[p.pt[0]] = true
+			}
+		}
+		if g, w := len(seen), len(ps); g != w {
+			t.Errorf("n=%d: distributed partition count mismatch\nGot: %d\nWant: %d", test.n, g, w)
+		}
+	}
+	if groups := DistributePartitions(ps, 0); groups != nil {
+		t.Errorf("got %v for zero groups, want nil", groups)
+	}
+}
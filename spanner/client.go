@@ -111,6 +111,7 @@ type Client struct {
 	disableRouteToLeader bool
 	dro                  *sppb.DirectedReadOptions
 	otConfig             *openTelemetryConfig
+	onAbortedRetry       func(context.Context, TransactionRetryInfo)
 }
 
 // DatabaseName returns the full name of a database, e.g.,
@@ -119,6 +120,12 @@ func (c *Client) DatabaseName() string {
 	return c.sc.database
 }
 
+// DatabaseRole returns the database role that is used by the Client, or an
+// empty string if the Client uses the default role of the principal.
+func (c *Client) DatabaseRole() string {
+	return c.sc.databaseRole
+}
+
 // ClientID returns the id of the Client. This is not recommended for customer applications and used internally for testing.
 func (c *Client) ClientID() string {
 	return c.sc.id
@@ -298,6 +305,23 @@ type ClientConfig struct {
 
 	// DatabaseRole specifies the role to be assumed for all operations on the
 	// database by this client.
+	//
+	// The role is set as the creator role of all sessions that are created by
+	// the session pool of the client, which means that it is used for all
+	// reads, queries, DML statements and mutations that are executed by the
+	// client. The principal that is used by the client must have the
+	// spanner.databaseRoles.use permission on the role. Access to the tables,
+	// columns, views and change streams of the database is then determined by
+	// the fine-grained access control privileges that have been granted to the
+	// role. Operations on objects that the role does not have access to fail
+	// with a PermissionDenied error.
+	//
+	// Use a separate Client for each database role that an application needs.
+	// The role cannot be changed after the client has been created, as it is
+	// assigned to the sessions in the pool.
+	//
+	// See https://cloud.google.com/spanner/docs/fgac-about for more
+	// information about fine-grained access control.
 	DatabaseRole string
 
 	// DisableRouteToLeader specifies if all the requests of type read-write and PDML
@@ -331,6 +355,33 @@ type ClientConfig struct {
 	DirectedReadOptions *sppb.DirectedReadOptions
 
 	OpenTelemetryMeterProvider metric.MeterProvider
+
+	// OnAbortedRetry is called each time a read/write transaction of this
+	// client is aborted by Spanner and is about to be retried. It is called
+	// before the client backs off, and can be used to log or record
+	// information about transactions that are frequently aborted due to lock
+	// contention. Use a transaction tag to identify the transaction. The
+	// function is called synchronously and should not block.
+	OnAbortedRetry func(ctx context.Context, info TransactionRetryInfo)
+}
+
+// TransactionRetryInfo contains information about a read/write transaction
+// that was aborted and will be retried.
+type TransactionRetryInfo struct {
+	// Attempt is the number of attempts that have been made so far, including
+	// the attempt that was aborted.
+	Attempt int
+	// Backoff is the time that the client will wait before the next attempt.
+	Backoff time.Duration
+	// Err is the error that caused the transaction to be aborted.
+	Err error
+	// TransactionTag is the transaction tag of the transaction, if any.
+	TransactionTag string
+	// Reason and Metadata contain the reason and metadata of the ErrorInfo
+	// that was returned by Spanner, if any. These can contain information
+	// about the transaction that caused the conflict.
+	Reason   string
+	Metadata map[string]string
 }
 
 type openTelemetryConfig struct {
@@ -346,6 +397,11 @@ type openTelemetryConfig struct {
 	releasedSessionsCount   metric.Int64Counter
 	gfeLatency              metric.Int64Histogram
 	gfeHeaderMissingCount   metric.Int64Counter
+	getSessionWaitTime      metric.Float64Histogram
+	numWaitersCount         metric.Int64ObservableGauge
+	leakedSessionsCount     metric.Int64ObservableCounter
+	abortedRetriesCount     metric.Int64Counter
+	transactionAttempts     metric.Int64Histogram
 }
 
 func contextWithOutgoingMetadata(ctx context.Context, md metadata.MD, disableRouteToLeader bool) context.Context {
@@ -480,6 +536,7 @@ func newClientWithConfig(ctx context.Context, database string, config ClientConf
 		disableRouteToLeader: config.DisableRouteToLeader,
 		dro:                  config.DirectedReadOptions,
 		otConfig:             otConfig,
+		onAbortedRetry:       config.OnAbortedRetry,
 	}
 	return c, nil
 }
@@ -576,6 +633,33 @@ func (c *Client) Close() {
 	c.sc.close()
 }
 
+// SessionPoolStatus returns a snapshot of the current state of the session
+// pool of the client. Enable SessionPoolConfig.TrackSessionHandles to include
+// the stack traces of the goroutines that checked out the sessions that are
+// currently in use.
+func (c *Client) SessionPoolStatus() SessionPoolStatus {
+	if c.idleSessions == nil {
+		return SessionPoolStatus{}
+	}
+	return c.idleSessions.status()
+}
+
+// SetSessionPoolLimits changes the MinOpened and MaxOpened settings of the
+// session pool of the client without the need to re-create the client.
+//
+// If minOpened is larger than the current number of opened sessions, the
+// session pool immediately starts to create the missing sessions. If maxOpened
+// is smaller than the current number of opened sessions, sessions that are in
+// use are not affected, and the pool shrinks gradually as sessions are
+// returned to the pool. Requests that are waiting for a session will not
+// create new sessions while the number of opened sessions exceeds maxOpened.
+func (c *Client) SetSessionPoolLimits(minOpened, maxOpened uint64) error {
+	if c.idleSessions == nil {
+		return errInvalidSessionPool
+	}
+	return c.idleSessions.setSizeLimits(minOpened, maxOpened)
+}
+
 // Single provides a read-only snapshot transaction optimized for the case
 // where only a single read or query is needed.  This is more efficient than
 // using ReadOnlyTransaction() for a single read or query.
@@ -801,12 +885,31 @@ func (c *Client) rwTransaction(ctx context.Context, f func(context.Context, *Rea
 		sh      *sessionHandle
 		t       *ReadWriteTransaction
 		attempt = 0
+		txOpts  = withContextTransactionOptions(ctx, c.txo.merge(options))
 	)
 	defer func() {
 		if sh != nil {
 			sh.recycle()
 		}
+		recordTransactionAttemptsOT(ctx, c.otConfig, attempt)
 	}()
+	onAborted := func(delay time.Duration, err error) {
+		recordAbortedRetryOT(ctx, c.otConfig)
+		if c.onAbortedRetry == nil {
+			return
+		}
+		info := TransactionRetryInfo{
+			Attempt:        attempt,
+			Backoff:        delay,
+			Err:            err,
+			TransactionTag: txOpts.TransactionTag,
+		}
+		if errorInfo := extractErrorInfo(err); errorInfo != nil {
+			info.Reason = errorInfo.GetReason()
+			info.Metadata = errorInfo.GetMetadata()
+		}
+		c.onAbortedRetry(ctx, info)
+	}
 	err = runWithRetryOnAbortedOrFailedInlineBeginOrSessionNotFound(ctx, func(ctx context.Context) error {
 		var (
 			err error
@@ -844,7 +947,7 @@ func (c *Client) rwTransaction(ctx context.Context, f func(context.Context, *Rea
 		t.txReadOnly.ro = c.ro
 		t.txReadOnly.disableRouteToLeader = c.disableRouteToLeader
 		t.wb = []*Mutation{}
-		t.txOpts = c.txo.merge(options)
+		t.txOpts = txOpts
 		t.ct = c.ct
 		t.otConfig = c.otConfig
 
@@ -853,7 +956,7 @@ func (c *Client) rwTransaction(ctx context.Context, f func(context.Context, *Rea
 
 		resp, err = t.runInTransaction(ctx, f)
 		return err
-	})
+	}, onAborted)
 	return resp, err
 }
 
@@ -936,7 +1039,8 @@ func (c *Client) Apply(ctx context.Context, ms []*Mutation, opts ...ApplyOption)
 		}, TransactionOptions{CommitPriority: ao.priority, TransactionTag: ao.transactionTag, ExcludeTxnFromChangeStreams: ao.excludeTxnFromChangeStreams})
 		return resp.CommitTs, err
 	}
-	t := &writeOnlyTransaction{sp: c.idleSessions, commitPriority: ao.priority, transactionTag: ao.transactionTag, disableRouteToLeader: c.disableRouteToLeader, excludeTxnFromChangeStreams: ao.excludeTxnFromChangeStreams}
+	txOpts := withContextTransactionOptions(ctx, TransactionOptions{CommitPriority: ao.priority, TransactionTag: ao.transactionTag})
+	t := &writeOnlyTransaction{sp: c.idleSessions, commitPriority: txOpts.CommitPriority, transactionTag: txOpts.TransactionTag, disableRouteToLeader: c.disableRouteToLeader, excludeTxnFromChangeStreams: ao.excludeTxnFromChangeStreams}
 	return t.applyAtLeastOnce(ctx, ms...)
 }
 
@@ -1099,6 +1203,8 @@ func (c *Client) BatchWriteWithOptions(ctx context.Context, mgs []*MutationGroup
 	}()
 
 	opts = c.bwo.merge(opts)
+	ctxOpts := requestOptionsFromContext(ctx)
+	opts = BatchWriteOptions{Priority: ctxOpts.Priority, TransactionTag: ctxOpts.TransactionTag}.merge(opts)
 
 	mgsPb, err := mutationGroupsProto(mgs)
 	if err != nil {
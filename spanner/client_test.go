@@ -1541,6 +1541,54 @@ func TestClient_ReadWriteTransactionCommitAborted(t *testing.T) {
 	}
 }
 
+func TestClient_ReadWriteTransaction_OnAbortedRetry(t *testing.T) {
+	t.Parallel()
+	var infos []TransactionRetryInfo
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		SessionPoolConfig: DefaultSessionPoolConfig,
+		OnAbortedRetry: func(ctx context.Context, info TransactionRetryInfo) {
+			infos = append(infos, info)
+		},
+	})
+	defer teardown()
+	server.TestSpanner.PutExecutionTime(MethodCommitTransaction, SimulatedExecutionTime{
+		Errors: []error{
+			status.Error(codes.Aborted, "Transaction aborted"),
+			status.Error(codes.Aborted, "Transaction aborted"),
+		},
+	})
+	ctx := context.Background()
+	var attempts int
+	_, err := client.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		attempts++
+		_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		return err
+	}, TransactionOptions{TransactionTag: "test-tag"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := attempts, 3; g != w {
+		t.Fatalf("attempt count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := len(infos), 2; g != w {
+		t.Fatalf("retry info count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	for i, info := range infos {
+		if g, w := info.Attempt, i+1; g != w {
+			t.Errorf("attempt mismatch\nGot: %v\nWant: %v", g, w)
+		}
+		if g, w := info.TransactionTag, "test-tag"; g != w {
+			t.Errorf("transaction tag mismatch\nGot: %v\nWant: %v", g, w)
+		}
+		if g, w := ErrCode(info.Err), codes.Aborted; g != w {
+			t.Errorf("error code mismatch\nGot: %v\nWant: %v", g, w)
+		}
+		if info.Backoff <= 0 {
+			t.Errorf("got non-positive backoff %v", info.Backoff)
+		}
+	}
+}
+
 func TestClient_ReadWriteTransaction_BufferedWriteBeforeAbortedFirstSqlStatement(t *testing.T) {
 	ctx := context.Background()
 	server, client, teardown := setupMockedTestServer(t)
@@ -2466,6 +2514,9 @@ func TestClient_SessionContainsDatabaseRole(t *testing.T) {
 	if g, w := resp.CreatorRole, "test"; g != w {
 		t.Fatalf("database role mismatch.\nGot: %v\nWant: %v", g, w)
 	}
+	if g, w := client.DatabaseRole(), "test"; g != w {
+		t.Fatalf("client database role mismatch.\nGot: %v\nWant: %v", g, w)
+	}
 }
 
 func TestClient_SessionNotFound(t *testing.T) {
@@ -210,6 +210,19 @@ like NullString:
 	    fmt.Println("column is NULL")
 	}
 
+PROTO and ENUM columns can be read into the generated Go types of the
+corresponding proto message and enum. Use NullProtoMessage and NullProtoEnum
+for columns that may contain NULL:
+
+	var info pb.SingerInfo
+	var genre pb.Genre
+	err = row.Columns(&info, &genre)
+
+The same types can be used in mutations and as query parameters. The proto
+bundle of a database can be created and altered with the helper functions
+ProtoDescriptors, CreateProtoBundleStatement and AlterProtoBundleStatement in
+the cloud.google.com/go/spanner/admin/database/apiv1 package.
+
 # Multiple Reads
 
 To perform more than one read in a transaction, use ReadOnlyTransaction:
@@ -309,6 +322,15 @@ of the transaction:
 	    return txn.BufferWrite([]*spanner.Mutation{m})
 	})
 
+The deadline of the context that is passed in to ReadWriteTransaction applies
+to the entire transaction, including all retries. Set the Timeout field of
+QueryOptions or ReadOptions to limit the time that a single query, DML
+statement or read may take. A statement that exceeds its timeout fails with a
+DeadlineExceeded error, and the transaction can continue with other statements,
+even if the statement that timed out was the first one of the transaction:
+
+	_, err := txn.UpdateWithOptions(ctx, stmt, spanner.QueryOptions{Timeout: 5 * time.Second})
+
 # Structs
 
 Cloud Spanner STRUCT (aka STRUCT) values
@@ -335,6 +357,30 @@ NULL and empty array values respectively of the corresponding StructType. A
 slice of pointers to a Go struct type can be used to specify an array of
 NULL-able STRUCT values.
 
+# Spanner Graph
+
+GQL queries are executed in the same way as SQL queries. Spanner Graph returns
+graph elements and paths as JSON values. Use GraphNode, GraphEdge and GraphPath
+to decode these values:
+
+	stmt := spanner.NewStatement(`GRAPH FinGraph
+	    MATCH p = (person:Person)-[owns:Owns]->(account:Account)
+	    RETURN SAFE_TO_JSON(person) AS person, SAFE_TO_JSON(owns) AS owns, SAFE_TO_JSON(p) AS path`)
+	err := client.Single().Query(ctx, stmt).Do(func(r *spanner.Row) error {
+	    var person spanner.GraphNode
+	    var owns spanner.GraphEdge
+	    var path spanner.GraphPath
+	    if err := r.Columns(&person, &owns, &path); err != nil {
+	        return err
+	    }
+	    var name string
+	    if err := person.Property("name", &name); err != nil {
+	        return err
+	    }
+	    fmt.Println(name, owns.DestinationNodeIdentifier, path.Len())
+	    return nil
+	})
+
 # DML and Partitioned DML
 
 Spanner supports DML statements like INSERT, UPDATE and DELETE. Use
@@ -347,6 +393,24 @@ For large databases, it may be more efficient to partition the DML statement.
 Use client.PartitionedUpdate to run a DML statement in this way. Not all DML
 statements can be partitioned.
 
+# Fine-Grained Access Control
+
+By default, a client has all the privileges that are granted to the IAM
+principal that is used to create the client. Set ClientConfig.DatabaseRole to
+let the client assume a database role instead. The sessions of the client are
+then created with that role, and all operations that are executed by the client
+are limited to the privileges that have been granted to the role:
+
+	client, err := spanner.NewClientWithConfig(ctx, "projects/P/instances/I/databases/D", spanner.ClientConfig{
+	    DatabaseRole: "hr_manager",
+	})
+	if err != nil {
+	    // TODO: Handle error.
+	}
+
+The IAM principal must have permission to use the role. Create a separate client
+for each role that is used by an application.
+
 # Tracing
 
 This client has been instrumented to use OpenCensus tracing
@@ -55,6 +55,21 @@ func ExampleNewClientWithConfig() {
 	client.Close() // Close client when done.
 }
 
+func ExampleNewClientWithConfig_databaseRole() {
+	ctx := context.Background()
+	const myDB = "projects/my-project/instances/my-instance/database/my-db"
+	// The client only has access to the tables and columns that the role
+	// hr_manager has been granted access to.
+	client, err := spanner.NewClientWithConfig(ctx, myDB, spanner.ClientConfig{
+		DatabaseRole: "hr_manager",
+	})
+	if err != nil {
+		// TODO: Handle error.
+	}
+	_ = client     // TODO: Use client.
+	client.Close() // Close client when done.
+}
+
 func ExampleClient_Single() {
 	ctx := context.Background()
 	client, err := spanner.NewClient(ctx, myDB)
@@ -232,6 +247,22 @@ func ExampleInsertStruct() {
 	_ = m // TODO: use with Client.Apply or in a ReadWriteTransaction.
 }
 
+func ExampleNewInsertBuilder() {
+	id, err := spanner.NewUUID()
+	if err != nil {
+		// TODO: Handle error.
+	}
+	m, err := spanner.NewInsertBuilder("Users").
+		Key("id", id).
+		Set("name", "alice").
+		Set("email", "a@example.com").
+		Build()
+	if err != nil {
+		// TODO: Handle error.
+	}
+	_ = m // TODO: use with Client.Apply or in a ReadWriteTransaction.
+}
+
 func ExampleDelete() {
 	m := spanner.Delete("Users", spanner.Key{"alice"})
 	_ = m // TODO: use with Client.Apply or in a ReadWriteTransaction.
@@ -290,6 +321,32 @@ func ExampleRowIterator_Do() {
 	}
 }
 
+func ExampleRowIterator_Statistics() {
+	ctx := context.Background()
+	client, err := spanner.NewClient(ctx, myDB)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	// Execute a low priority query with a request tag, and request the
+	// execution statistics and query plan of the query.
+	mode := sppb.ExecuteSqlRequest_PROFILE
+	iter := client.Single().QueryWithOptions(ctx, spanner.NewStatement("SELECT FirstName FROM Singers"), spanner.QueryOptions{
+		Mode:       &mode,
+		Priority:   sppb.RequestOptions_PRIORITY_LOW,
+		RequestTag: "app=concert,env=dev,action=select",
+	})
+	err = iter.Do(func(r *spanner.Row) error {
+		// TODO: Process the row.
+		return nil
+	})
+	if err != nil {
+		// TODO: Handle error.
+	}
+	stats := iter.Statistics()
+	fmt.Printf("Returned %d rows in %v\n", stats.RowsReturned, stats.ElapsedTime)
+	fmt.Println(spanner.QueryPlanSummary(iter.QueryPlan))
+}
+
 func ExampleRow_Size() {
 	ctx := context.Background()
 	client, err := spanner.NewClient(ctx, myDB)
@@ -660,6 +717,36 @@ func ExampleClient_BatchReadOnlyTransaction() {
 	wg.Wait()
 }
 
+func ExampleBatchReadOnlyTransaction_ExecutePartitions() {
+	ctx := context.Background()
+	client, err := spanner.NewClient(ctx, myDB)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+	txn, err := client.BatchReadOnlyTransaction(ctx, spanner.StrongRead())
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer txn.Close()
+
+	stmt := spanner.Statement{SQL: "SELECT * FROM Singers;"}
+	// Execute the partitions on Data Boost to avoid affecting the workload
+	// of the instance.
+	partitions, err := txn.PartitionQueryWithOptions(ctx, stmt, spanner.PartitionOptions{}, spanner.QueryOptions{DataBoostEnabled: true})
+	if err != nil {
+		// TODO: Handle error.
+	}
+	// Execute the partitions using at most 8 concurrent goroutines.
+	err = txn.ExecutePartitions(ctx, partitions, 8, func(p *spanner.Partition, row *spanner.Row) error {
+		// TODO: Process the row. This function can be called concurrently.
+		return nil
+	})
+	if err != nil {
+		// TODO: Handle error.
+	}
+}
+
 func ExampleCommitTimestamp() {
 	ctx := context.Background()
 	client, err := spanner.NewClient(ctx, myDB)
@@ -0,0 +1,240 @@
+/*
+Copyright 2024 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	graphElementKindNode = "node"
+	graphElementKindEdge = "edge"
+)
+
+// graphElement is the JSON representation of a graph element that is returned
+// by Spanner Graph.
+type graphElement struct {
+	Kind                      string                     `json:"kind"`
+	Identifier                string                     `json:"identifier"`
+	Labels                    []string                   `json:"labels"`
+	Properties                map[string]json.RawMessage `json:"properties"`
+	SourceNodeIdentifier      string                     `json:"source_node_identifier"`
+	DestinationNodeIdentifier string                     `json:"destination_node_identifier"`
+}
+
+// GraphNode is a node in a Spanner Graph. A GraphNode can be decoded from a
+// JSON column that contains a node, for example the result of
+// SAFE_TO_JSON(n) for a node variable n in a GQL query.
+type GraphNode struct {
+	// Identifier uniquely identifies the node in the graph.
+	Identifier string
+	// Labels contains the labels of the node.
+	Labels []string
+	// Properties contains the JSON encoded properties of the node. Use
+	// Property to decode a single property into a Go value.
+	Properties map[string]json.RawMessage
+}
+
+// DecodeSpanner implements the Decoder interface.
+func (n *GraphNode) DecodeSpanner(input interface{}) error {
+	e, err := decodeGraphElement(input, graphElementKindNode)
+	if err != nil || e == nil {
+		return err
+	}
+	*n = e.node()
+	return nil
+}
+
+// Property decodes the property with the given name into v. It returns an
+// error if the node does not have a property with the given name.
+func (n *GraphNode) Property(name string, v interface{}) error {
+	return graphProperty(n.Properties, n.Identifier, name, v)
+}
+
+// HasLabel returns true if the node has the given label.
+func (n *GraphNode) HasLabel(label string) bool {
+	return hasGraphLabel(n.Labels, label)
+}
+
+// GraphEdge is an edge in a Spanner Graph. A GraphEdge can be decoded from a
+// JSON column that contains an edge, for example the result of
+// SAFE_TO_JSON(e) for an edge variable e in a GQL query.
+type GraphEdge struct {
+	// Identifier uniquely identifies the edge in the graph.
+	Identifier string
+	// Labels contains the labels of the edge.
+	Labels []string
+	// Properties contains the JSON encoded properties of the edge. Use
+	// Property to decode a single property into a Go value.
+	Properties map[string]json.RawMessage
+	// SourceNodeIdentifier is the identifier of the source node of the edge.
+	SourceNodeIdentifier string
+	// DestinationNodeIdentifier is the identifier of the destination node of
+	// the edge.
+	DestinationNodeIdentifier string
+}
+
+// DecodeSpanner implements the Decoder interface.
+func (e *GraphEdge) DecodeSpanner(input interface{}) error {
+	ge, err := decodeGraphElement(input, graphElementKindEdge)
+	if err != nil || ge == nil {
+		return err
+	}
+	*e = ge.edge()
+	return nil
+}
+
+// Property decodes the property with the given name into v. It returns an
+// error if the edge does not have a property with the given name.
+func (e *GraphEdge) Property(name string, v interface{}) error {
+	return graphProperty(e.Properties, e.Identifier, name, v)
+}
+
+// HasLabel returns true if the edge has the given label.
+func (e *GraphEdge) HasLabel(label string) bool {
+	return hasGraphLabel(e.Labels, label)
+}
+
+// GraphPath is a path in a Spanner Graph. A GraphPath can be decoded from a
+// JSON column that contains a path, for example the result of SAFE_TO_JSON(p)
+// for a path variable p in a GQL query.
+//
+// A path always starts and ends with a node, and the nodes and edges of the
+// path alternate. Edges[i] connects Nodes[i] and Nodes[i+1].
+type GraphPath struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// DecodeSpanner implements the Decoder interface.
+func (p *GraphPath) DecodeSpanner(input interface{}) error {
+	s, ok, err := graphJSONString(input)
+	if err != nil || !ok {
+		return err
+	}
+	var elements []graphElement
+	if err := jsonUnmarshal([]byte(s), &elements); err != nil {
+		return spannerErrorf(codes.InvalidArgument, "failed to decode graph path: %v", err)
+	}
+	path := GraphPath{}
+	for i, e := range elements {
+		wantKind := graphElementKindNode
+		if i%2 == 1 {
+			wantKind = graphElementKindEdge
+		}
+		if e.Kind != wantKind {
+			return spannerErrorf(codes.InvalidArgument, "failed to decode graph path: element %d has kind %q, want %q", i, e.Kind, wantKind)
+		}
+		if e.Kind == graphElementKindNode {
+			path.Nodes = append(path.Nodes, e.node())
+		} else {
+			path.Edges = append(path.Edges, e.edge())
+		}
+	}
+	if len(elements) > 0 && len(elements)%2 == 0 {
+		return spannerErrorf(codes.InvalidArgument, "failed to decode graph path: path must end with a node")
+	}
+	*p = path
+	return nil
+}
+
+// Len returns the number of edges in the path.
+func (p *GraphPath) Len() int {
+	return len(p.Edges)
+}
+
+// graphJSONString returns the JSON string in the given input value. The
+// boolean return value is false if the input is a NULL value.
+func graphJSONString(input interface{}) (string, bool, error) {
+	switch v := input.(type) {
+	case string:
+		return v, true, nil
+	case *string:
+		if v == nil {
+			return "", false, nil
+		}
+		return *v, true, nil
+	default:
+		return "", false, spannerErrorf(codes.InvalidArgument, "graph elements can only be decoded from JSON values, got %T", input)
+	}
+}
+
+func decodeGraphElement(input interface{}, kind string) (*graphElement, error) {
+	s, ok, err := graphJSONString(input)
+	if err != nil || !ok {
+		return nil, err
+	}
+	e := &graphElement{}
+	if err := jsonUnmarshal([]byte(s), e); err != nil {
+		return nil, spannerErrorf(codes.InvalidArgument, "failed to decode graph %s: %v", kind, err)
+	}
+	if e.Kind != kind {
+		return nil, spannerErrorf(codes.InvalidArgument, "failed to decode graph %s: got graph element of kind %q", kind, e.Kind)
+	}
+	return e, nil
+}
+
+func (e *graphElement) node() GraphNode {
+	return GraphNode{
+		Identifier: e.Identifier,
+		Labels:     e.Labels,
+		Properties: e.Properties,
+	}
+}
+
+func (e *graphElement) edge() GraphEdge {
+	return GraphEdge{
+		Identifier:                e.Identifier,
+		Labels:                    e.Labels,
+		Properties:                e.Properties,
+		SourceNodeIdentifier:      e.SourceNodeIdentifier,
+		DestinationNodeIdentifier: e.DestinationNodeIdentifier,
+	}
+}
+
+func graphProperty(properties map[string]json.RawMessage, identifier, name string, v interface{}) error {
+	raw, ok := properties[name]
+	if !ok {
+		return spannerErrorf(codes.NotFound, "graph element %s does not have property %q", identifier, name)
+	}
+	if err := jsonUnmarshal(raw, v); err != nil {
+		return spannerErrorf(codes.InvalidArgument, "failed to decode property %q: %v", name, err)
+	}
+	return nil
+}
+
+func hasGraphLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// String implements fmt.Stringer.
+func (n GraphNode) String() string {
+	return fmt.Sprintf("(%s %v)", n.Identifier, n.Labels)
+}
+
+// String implements fmt.Stringer.
+func (e GraphEdge) String() string {
+	return fmt.Sprintf("[%s %v: %s -> %s]", e.Identifier, e.Labels, e.SourceNodeIdentifier, e.DestinationNodeIdentifier)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2024 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"encoding/json"
+	"testing"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"google.golang.org/grpc/codes"
+	proto3 "google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	testGraphNodeJSON    = `{"identifier":"mUZpbkdyYXBoLlBlcnNvbgB4kQI=","kind":"node","labels":["Person"],"properties":{"id":1,"name":"Alex"}}`
+	testGraphEdgeJSON    = `{"destination_node_identifier":"mUZpbkdyYXBoLkFjY291bnQAeJEO","identifier":"mUZpbkdyYXBoLlBlcnNvbk93bkFjY291bnQAeJECkQ6ZRmluR3JhcGguUGVyc29uAHiRAplGaW5HcmFwaC5BY2NvdW50AHiRDg==","kind":"edge","labels":["Owns"],"properties":{"account_id":7,"id":1},"source_node_identifier":"mUZpbkdyYXBoLlBlcnNvbgB4kQI="}`
+	testGraphAccountJSON = `{"identifier":"mUZpbkdyYXBoLkFjY291bnQAeJEO","kind":"node","labels":["Account"],"properties":{"id":7,"is_blocked":false}}`
+)
+
+func TestGraphNodeDecodeSpanner(t *testing.T) {
+	t.Parallel()
+	var n GraphNode
+	if err := n.DecodeSpanner(testGraphNodeJSON); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := n.Identifier, "mUZpbkdyYXBoLlBlcnNvbgB4kQI="; g != w {
+		t.Fatalf("identifier mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if !n.HasLabel("Person") || n.HasLabel("Account") {
+		t.Fatalf("unexpected labels: %v", n.Labels)
+	}
+	var name string
+	if err := n.Property("name", &name); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := name, "Alex"; g != w {
+		t.Fatalf("name mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	var id int64
+	if err := n.Property("id", &id); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := id, int64(1); g != w {
+		t.Fatalf("id mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if err := n.Property("birthday", &name); ErrCode(err) != codes.NotFound {
+		t.Fatalf("error mismatch for unknown property\nGot: %v", err)
+	}
+
+	// A NULL value leaves the node unchanged.
+	if err := n.DecodeSpanner((*string)(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if n.Identifier == "" {
+		t.Fatal("node was cleared by NULL value")
+	}
+	// An edge cannot be decoded into a node.
+	if err := n.DecodeSpanner(testGraphEdgeJSON); err == nil {
+		t.Fatal("missing error for decoding edge into node")
+	}
+	if err := n.DecodeSpanner(int64(1)); err == nil {
+		t.Fatal("missing error for decoding non-JSON value into node")
+	}
+}
+
+func TestGraphEdgeDecodeSpanner(t *testing.T) {
+	t.Parallel()
+	var e GraphEdge
+	if err := e.DecodeSpanner(testGraphEdgeJSON); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := e.SourceNodeIdentifier, "mUZpbkdyYXBoLlBlcnNvbgB4kQI="; g != w {
+		t.Fatalf("source mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := e.DestinationNodeIdentifier, "mUZpbkdyYXBoLkFjY291bnQAeJEO"; g != w {
+		t.Fatalf("destination mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if !e.HasLabel("Owns") {
+		t.Fatalf("unexpected labels: %v", e.Labels)
+	}
+	var accountID int64
+	if err := e.Property("account_id", &accountID); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := accountID, int64(7); g != w {
+		t.Fatalf("account_id mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if err := e.DecodeSpanner(testGraphNodeJSON); err == nil {
+		t.Fatal("missing error for decoding node into edge")
+	}
+}
+
+func TestGraphPathDecodeSpanner(t *testing.T) {
+	t.Parallel()
+	var p GraphPath
+	if err := p.DecodeSpanner("[" + testGraphNodeJSON + "," + testGraphEdgeJSON + "," + testGraphAccountJSON + "]"); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := p.Len(), 1; g != w {
+		t.Fatalf("path length mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := len(p.Nodes), 2; g != w {
+		t.Fatalf("node count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if p.Edges[0].SourceNodeIdentifier != p.Nodes[0].Identifier || p.Edges[0].DestinationNodeIdentifier != p.Nodes[1].Identifier {
+		t.Fatalf("edge does not connect the nodes of the path: %v", p)
+	}
+
+	for _, invalid := range []string{
+		"[" + testGraphEdgeJSON + "]",
+		"[" + testGraphNodeJSON + "," + testGraphEdgeJSON + "]",
+		"[" + testGraphNodeJSON + "," + testGraphAccountJSON + "]",
+		testGraphNodeJSON,
+	} {
+		if err := p.DecodeSpanner(invalid); err == nil {
+			t.Errorf("missing error for invalid path %s", invalid)
+		}
+	}
+}
+
+func TestGraphRowColumns(t *testing.T) {
+	t.Parallel()
+	row := &Row{
+		fields: []*sppb.StructType_Field{
+			mkField("person", jsonType()),
+			mkField("owns", jsonType()),
+			mkField("path", jsonType()),
+			mkField("missing", jsonType()),
+		},
+		vals: []*proto3.Value{
+			stringProto(testGraphNodeJSON),
+			stringProto(testGraphEdgeJSON),
+			stringProto("[" + testGraphNodeJSON + "," + testGraphEdgeJSON + "," + testGraphAccountJSON + "]"),
+			nullProto(),
+		},
+	}
+	var person, missing GraphNode
+	var owns GraphEdge
+	var path GraphPath
+	if err := row.Columns(&person, &owns, &path, &missing); err != nil {
+		t.Fatal(err)
+	}
+	if !person.HasLabel("Person") || !owns.HasLabel("Owns") || path.Len() != 1 {
+		t.Fatalf("unexpected decoded values: %v, %v, %v", person, owns, path)
+	}
+	if missing.Identifier != "" {
+		t.Fatalf("got %v for NULL node, want empty node", missing)
+	}
+
+	var s struct {
+		Person GraphNode `spanner:"person"`
+		Owns   GraphEdge `spanner:"owns"`
+		Path   GraphPath `spanner:"path"`
+	}
+	row.fields, row.vals = row.fields[:3], row.vals[:3]
+	if err := row.ToStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if !testEqual(s.Person, person) || !testEqual(s.Owns, owns) || !testEqual(s.Path, path) {
+		t.Fatalf("struct mismatch\nGot: %v\nWant: %v, %v, %v", s, person, owns, path)
+	}
+	if g, w := s.Person.Properties["name"], json.RawMessage(`"Alex"`); string(g) != string(w) {
+		t.Fatalf("name property mismatch\nGot: %s\nWant: %s", g, w)
+	}
+}
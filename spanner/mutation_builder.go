@@ -0,0 +1,192 @@
+/*
+Copyright 2024 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/bits"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"google.golang.org/grpc/codes"
+)
+
+// randReader is the source of randomness for NewUUID. It can be replaced in
+// tests.
+var randReader io.Reader = rand.Reader
+
+// NewUUID returns a new random (version 4) UUID in its canonical string form,
+// e.g. "7f1b0e6c-3b9a-4d5e-8f21-0c6a9b1d2e3f". Random UUIDs are a good choice
+// for primary keys, as they distribute writes evenly across the key space
+// instead of concentrating them at the end of the table. The value can be
+// stored in a STRING(36) column.
+func NewUUID() (string, error) {
+	var u [16]byte
+	if _, err := io.ReadFull(randReader, u[:]); err != nil {
+		return "", err
+	}
+	u[6] = (u[6] & 0x0f) | 0x40 // Version 4
+	u[8] = (u[8] & 0x3f) | 0x80 // Variant is 10
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:]), nil
+}
+
+// BitReverse returns the bit-reversed value of a positive sequential value.
+// The bits of the 63 least significant bits of v are reversed, so that the
+// result is always a positive INT64 value. Consecutive input values are mapped
+// to values that are spread out over the entire positive INT64 range. This is
+// the same transformation that is applied by a bit-reversed sequence in
+// Spanner, and can be used to convert existing sequential keys, e.g. keys that
+// are migrated from another database, to keys that do not cause hotspots.
+//
+// BitReverse is its own inverse for values in the range [0, math.MaxInt64]:
+// BitReverse(BitReverse(v)) == v.
+func BitReverse(v int64) int64 {
+	return int64(bits.Reverse64(uint64(v) << 1))
+}
+
+// BitReversedSequenceStatement returns a CREATE SEQUENCE statement for a
+// bit-reversed positive sequence with the given name. Use
+// GET_NEXT_SEQUENCE_VALUE(SEQUENCE <name>) as the default value of an INT64
+// primary key column to let Spanner generate keys that are evenly distributed.
+//
+// Values in the range [skipRangeMin, skipRangeMax] are never generated by the
+// sequence. This can be used to prevent conflicts with existing keys. The skip
+// range is omitted if both values are zero.
+func BitReversedSequenceStatement(name string, skipRangeMin, skipRangeMax int64) string {
+	if skipRangeMin == 0 && skipRangeMax == 0 {
+		return fmt.Sprintf("CREATE SEQUENCE %s OPTIONS (sequence_kind = 'bit_reversed_positive')", name)
+	}
+	return fmt.Sprintf("CREATE SEQUENCE %s OPTIONS (sequence_kind = 'bit_reversed_positive', skip_range_min = %d, skip_range_max = %d)", name, skipRangeMin, skipRangeMax)
+}
+
+// MutationBuilder builds an insert, update, insert-or-update or replace
+// Mutation column by column. The primary key columns of the row are specified
+// with Key, and the other columns with Set. Build validates the mutation and
+// returns the first error that was encountered.
+//
+// In addition to basic validation, Build rejects mutations where the first
+// primary key column is a timestamp or a date, as keys that start with a
+// monotonically increasing value cause all writes to be sent to the same
+// split. Call AllowHotspot to disable this check.
+type MutationBuilder struct {
+	op           op
+	table        string
+	columns      []string
+	values       []interface{}
+	numKeys      int
+	allowHotspot bool
+	err          error
+}
+
+// NewInsertBuilder returns a MutationBuilder for an Insert mutation.
+func NewInsertBuilder(table string) *MutationBuilder {
+	return &MutationBuilder{op: opInsert, table: table}
+}
+
+// NewUpdateBuilder returns a MutationBuilder for an Update mutation.
+func NewUpdateBuilder(table string) *MutationBuilder {
+	return &MutationBuilder{op: opUpdate, table: table}
+}
+
+// NewInsertOrUpdateBuilder returns a MutationBuilder for an InsertOrUpdate
+// mutation.
+func NewInsertOrUpdateBuilder(table string) *MutationBuilder {
+	return &MutationBuilder{op: opInsertOrUpdate, table: table}
+}
+
+// NewReplaceBuilder returns a MutationBuilder for a Replace mutation.
+func NewReplaceBuilder(table string) *MutationBuilder {
+	return &MutationBuilder{op: opReplace, table: table}
+}
+
+// Key sets the value of the next primary key column. Key columns must be
+// specified in the order in which they are defined in the primary key of the
+// table, and before any other columns are set.
+func (b *MutationBuilder) Key(column string, value interface{}) *MutationBuilder {
+	if b.err == nil && len(b.columns) > b.numKeys {
+		b.err = spannerErrorf(codes.InvalidArgument, "key column %q of table %s must be set before non-key columns", column, b.table)
+	}
+	b.add(column, value)
+	b.numKeys++
+	return b
+}
+
+// Set sets the value of a non-key column.
+func (b *MutationBuilder) Set(column string, value interface{}) *MutationBuilder {
+	b.add(column, value)
+	return b
+}
+
+// AllowHotspot disables the check for primary keys that start with a
+// monotonically increasing value.
+func (b *MutationBuilder) AllowHotspot() *MutationBuilder {
+	b.allowHotspot = true
+	return b
+}
+
+func (b *MutationBuilder) add(column string, value interface{}) {
+	if b.err != nil {
+		return
+	}
+	if column == "" {
+		b.err = spannerErrorf(codes.InvalidArgument, "empty column name for table %s", b.table)
+		return
+	}
+	for _, c := range b.columns {
+		if c == column {
+			b.err = spannerErrorf(codes.InvalidArgument, "column %q of table %s is set more than once", column, b.table)
+			return
+		}
+	}
+	b.columns = append(b.columns, column)
+	b.values = append(b.values, value)
+}
+
+// Build returns the Mutation, or the first error that was encountered while
+// building it.
+func (b *MutationBuilder) Build() (*Mutation, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.table == "" {
+		return nil, spannerErrorf(codes.InvalidArgument, "empty table name")
+	}
+	if b.numKeys == 0 {
+		return nil, spannerErrorf(codes.InvalidArgument, "no key columns set for table %s", b.table)
+	}
+	if !b.allowHotspot && isMonotonicKeyValue(b.values[0]) {
+		return nil, spannerErrorf(codes.InvalidArgument, "first key column %q of table %s is a timestamp or date, which can cause hotspots; use a UUID or bit-reversed sequence value as the first key column, or call AllowHotspot", b.columns[0], b.table)
+	}
+	return &Mutation{
+		op:      b.op,
+		table:   b.table,
+		columns: append([]string(nil), b.columns...),
+		values:  append([]interface{}(nil), b.values...),
+	}, nil
+}
+
+// isMonotonicKeyValue returns true if v is of a type that is typically
+// assigned monotonically increasing values.
+func isMonotonicKeyValue(v interface{}) bool {
+	switch v.(type) {
+	case time.Time, *time.Time, NullTime, civil.Date, *civil.Date, NullDate:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestNewUUID(t *testing.T) {
+	t.Parallel()
+	re := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		u, err := NewUUID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !re.MatchString(u) {
+			t.Fatalf("invalid UUID: %q", u)
+		}
+		if seen[u] {
+			t.Fatalf("duplicate UUID: %q", u)
+		}
+		seen[u] = true
+	}
+}
+
+func TestNewUUID_Error(t *testing.T) {
+	old := randReader
+	defer func() { randReader = old }()
+	randReader = bytes.NewReader(nil)
+	if _, err := NewUUID(); err == nil {
+		t.Fatal("missing error for failing random source")
+	}
+}
+
+func TestBitReverse(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		in, want int64
+	}{
+		{0, 0},
+		{1, 1 << 62},
+		{2, 1 << 61},
+		{3, 3 << 61},
+		{1 << 62, 1},
+		{math.MaxInt64, math.MaxInt64},
+	} {
+		if got := BitReverse(test.in); got != test.want {
+			t.Errorf("BitReverse(%d) mismatch\nGot: %d\nWant: %d", test.in, got, test.want)
+		}
+	}
+	for _, v := range []int64{0, 1, 42, 1000000, math.MaxInt64 - 1} {
+		r := BitReverse(v)
+		if r < 0 {
+			t.Errorf("BitReverse(%d) returned negative value %d", v, r)
+		}
+		if got := BitReverse(r); got != v {
+			t.Errorf("BitReverse(BitReverse(%d)) mismatch\nGot: %d\nWant: %d", v, got, v)
+		}
+	}
+}
+
+func TestBitReversedSequenceStatement(t *testing.T) {
+	t.Parallel()
+	if g, w := BitReversedSequenceStatement("SingerIdSequence", 0, 0), "CREATE SEQUENCE SingerIdSequence OPTIONS (sequence_kind = 'bit_reversed_positive')"; g != w {
+		t.Errorf("statement mismatch\nGot: %q\nWant: %q", g, w)
+	}
+	if g, w := BitReversedSequenceStatement("SingerIdSequence", 1, 1000), "CREATE SEQUENCE SingerIdSequence OPTIONS (sequence_kind = 'bit_reversed_positive', skip_range_min = 1, skip_range_max = 1000)"; g != w {
+		t.Errorf("statement mismatch\nGot: %q\nWant: %q", g, w)
+	}
+}
+
+func TestMutationBuilder(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		b    *MutationBuilder
+		want *Mutation
+	}{
+		{
+			NewInsertBuilder("Singers").Key("SingerId", "a1").Set("Name", "Alice"),
+			Insert("Singers", []string{"SingerId", "Name"}, []interface{}{"a1", "Alice"}),
+		},
+		{
+			NewUpdateBuilder("Albums").Key("SingerId", int64(1)).Key("AlbumId", int64(2)).Set("Title", "Go"),
+			Update("Albums", []string{"SingerId", "AlbumId", "Title"}, []interface{}{int64(1), int64(2), "Go"}),
+		},
+		{
+			NewInsertOrUpdateBuilder("Singers").Key("SingerId", "a1"),
+			InsertOrUpdate("Singers", []string{"SingerId"}, []interface{}{"a1"}),
+		},
+		{
+			NewReplaceBuilder("Events").Key("EventTime", time.Unix(0, 0)).AllowHotspot(),
+			Replace("Events", []string{"EventTime"}, []interface{}{time.Unix(0, 0)}),
+		},
+	} {
+		got, err := test.b.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !testEqual(got, test.want) {
+			t.Errorf("mutation mismatch\nGot: %+v\nWant: %+v", got, test.want)
+		}
+	}
+}
+
+func TestMutationBuilder_Invalid(t *testing.T) {
+	t.Parallel()
+	for _, b := range []*MutationBuilder{
+		NewInsertBuilder("").Key("Id", "a"),
+		NewInsertBuilder("Singers").Set("Name", "Alice"),
+		NewInsertBuilder("Singers").Key("Id", "a").Set("Name", "Alice").Key("Version", 1),
+		NewInsertBuilder("Singers").Key("Id", "a").Set("Id", "b"),
+		NewInsertBuilder("Singers").Key("Id", "a").Set("", "b"),
+		NewInsertBuilder("Events").Key("EventTime", time.Now()),
+		NewInsertBuilder("Events").Key("EventDate", NullDate{}),
+	} {
+		if _, err := b.Build(); ErrCode(err) != codes.InvalidArgument {
+			t.Errorf("error code mismatch for %v\nGot: %v\nWant: %v", b.columns, ErrCode(err), codes.InvalidArgument)
+		}
+	}
+}
@@ -181,6 +181,57 @@ func initializeMetricInstruments(config *openTelemetryConfig, logger *log.Logger
 		logf(logger, "Error during registering instrument for metric spanner/gfe_header_missing_count, error: %v", err)
 	}
 	config.gfeHeaderMissingCount = gfeHeaderMissingCountInstrument
+
+	getSessionWaitTimeInstrument, err := meter.Float64Histogram(
+		metricsPrefix+"get_session_wait_time",
+		metric.WithDescription("The time it took to check out a session from the session pool."),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		logf(logger, "Error during registering instrument for metric spanner/get_session_wait_time, error: %v", err)
+	}
+	config.getSessionWaitTime = getSessionWaitTimeInstrument
+
+	numWaitersCountInstrument, err := meter.Int64ObservableGauge(
+		metricsPrefix+"num_get_session_waiters",
+		metric.WithDescription("The number of requests that are waiting for a session to become available."),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		logf(logger, "Error during registering instrument for metric spanner/num_get_session_waiters, error: %v", err)
+	}
+	config.numWaitersCount = numWaitersCountInstrument
+
+	leakedSessionsCountInstrument, err := meter.Int64ObservableCounter(
+		metricsPrefix+"num_leaked_sessions_removed",
+		metric.WithDescription("The number of sessions that were removed from the session pool because they were deemed to be leaked."),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		logf(logger, "Error during registering instrument for metric spanner/num_leaked_sessions_removed, error: %v", err)
+	}
+	config.leakedSessionsCount = leakedSessionsCountInstrument
+
+	abortedRetriesCountInstrument, err := meter.Int64Counter(
+		metricsPrefix+"num_aborted_transaction_retries",
+		metric.WithDescription("The number of times that a read/write transaction was retried after it was aborted by Spanner."),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		logf(logger, "Error during registering instrument for metric spanner/num_aborted_transaction_retries, error: %v", err)
+	}
+	config.abortedRetriesCount = abortedRetriesCountInstrument
+
+	transactionAttemptsInstrument, err := meter.Int64Histogram(
+		metricsPrefix+"transaction_attempts",
+		metric.WithDescription("The number of attempts that were needed to execute a read/write transaction."),
+		metric.WithUnit("1"),
+		metric.WithExplicitBucketBoundaries(1, 2, 3, 4, 5, 10, 20, 50, 100),
+	)
+	if err != nil {
+		logf(logger, "Error during registering instrument for metric spanner/transaction_attempts, error: %v", err)
+	}
+	config.transactionAttempts = transactionAttemptsInstrument
 }
 
 func registerSessionPoolOTMetrics(pool *sessionPool) error {
@@ -203,6 +254,8 @@ func registerSessionPoolOTMetrics(pool *sessionPool) error {
 			o.ObserveInt64(otConfig.sessionsCount, int64(pool.numInUse), metric.WithAttributes(attributesInUseSessions...))
 			o.ObserveInt64(otConfig.sessionsCount, int64(pool.numSessions), metric.WithAttributes(attributesAvailableSessions...))
 			o.ObserveInt64(otConfig.maxInUseSessionsCount, int64(pool.maxNumInUse), metric.WithAttributes(attributes...))
+			o.ObserveInt64(otConfig.numWaitersCount, int64(pool.numWaiters), metric.WithAttributes(attributes...))
+			o.ObserveInt64(otConfig.leakedSessionsCount, int64(pool.numOfLeakedSessionsRemoved), metric.WithAttributes(attributes...))
 
 			return nil
 		},
@@ -210,6 +263,8 @@ func registerSessionPoolOTMetrics(pool *sessionPool) error {
 		otConfig.maxAllowedSessionsCount,
 		otConfig.sessionsCount,
 		otConfig.maxInUseSessionsCount,
+		otConfig.numWaitersCount,
+		otConfig.leakedSessionsCount,
 	)
 	pool.otConfig.otMetricRegistration = reg
 	return err
@@ -253,3 +308,17 @@ func recordGFELatencyMetricsOT(ctx context.Context, md metadata.MD, keyMethod st
 	}
 	return nil
 }
+
+func recordAbortedRetryOT(ctx context.Context, otConfig *openTelemetryConfig) {
+	if !IsOpenTelemetryMetricsEnabled() || otConfig == nil || otConfig.abortedRetriesCount == nil {
+		return
+	}
+	otConfig.abortedRetriesCount.Add(ctx, 1, metric.WithAttributes(otConfig.attributeMap...))
+}
+
+func recordTransactionAttemptsOT(ctx context.Context, otConfig *openTelemetryConfig, attempts int) {
+	if !IsOpenTelemetryMetricsEnabled() || otConfig == nil || otConfig.transactionAttempts == nil || attempts == 0 {
+		return
+	}
+	otConfig.transactionAttempts.Record(ctx, int64(attempts), metric.WithAttributes(otConfig.attributeMap...))
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2024 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+)
+
+// QueryStatistics contains the execution statistics of a query in a typed
+// form. Statistics that are not returned by Spanner, or that could not be
+// parsed, are left at their zero value. All statistics, including those that
+// do not have a corresponding field in this struct, are available in Raw.
+type QueryStatistics struct {
+	// QueryText is the SQL text of the query.
+	QueryText string
+	// ElapsedTime is the wall time that it took to execute the query.
+	ElapsedTime time.Duration
+	// CPUTime is the CPU time that it took to execute the query.
+	CPUTime time.Duration
+	// QueryPlanCreationTime is the time that it took to create the query plan.
+	QueryPlanCreationTime time.Duration
+	// RowsReturned is the number of rows that was returned by the query.
+	RowsReturned int64
+	// RowsScanned is the number of rows that was scanned by the query.
+	RowsScanned int64
+	// DeletedRowsScanned is the number of deleted rows that was scanned by the
+	// query.
+	DeletedRowsScanned int64
+	// OptimizerVersion is the version of the query optimizer that was used.
+	OptimizerVersion string
+	// OptimizerStatisticsPackage is the optimizer statistics package that was
+	// used.
+	OptimizerStatisticsPackage string
+	// Raw contains all statistics as returned by Spanner.
+	Raw map[string]interface{}
+}
+
+// Statistics returns the execution statistics of the query in a typed form.
+// The statistics are available after RowIterator.Next returns iterator.Done if
+// the query was executed with QueryWithStats, or with QueryWithOptions and
+// Mode set to PROFILE. Statistics returns nil if no statistics are available.
+func (r *RowIterator) Statistics() *QueryStatistics {
+	if r.QueryStats == nil {
+		return nil
+	}
+	return newQueryStatistics(r.QueryStats)
+}
+
+func newQueryStatistics(m map[string]interface{}) *QueryStatistics {
+	qs := &QueryStatistics{Raw: m}
+	qs.QueryText = statsString(m, "query_text")
+	qs.ElapsedTime = statsDuration(m, "elapsed_time")
+	qs.CPUTime = statsDuration(m, "cpu_time")
+	qs.QueryPlanCreationTime = statsDuration(m, "query_plan_creation_time")
+	qs.RowsReturned = statsInt(m, "rows_returned")
+	qs.RowsScanned = statsInt(m, "rows_scanned")
+	qs.DeletedRowsScanned = statsInt(m, "deleted_rows_scanned")
+	qs.OptimizerVersion = statsString(m, "optimizer_version")
+	qs.OptimizerStatisticsPackage = statsString(m, "optimizer_statistics_package")
+	return qs
+}
+
+func statsString(m map[string]interface{}, key string) string {
+	switch v := m[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+func statsInt(m map[string]interface{}, key string) int64 {
+	n, err := strconv.ParseFloat(statsString(m, key), 64)
+	if err != nil {
+		return 0
+	}
+	return int64(n)
+}
+
+// statsDuration parses a duration in the format that is used by Spanner for
+// query statistics, e.g. "1.25 msecs".
+func statsDuration(m map[string]interface{}, key string) time.Duration {
+	return parseStatsDuration(statsString(m, key))
+}
+
+func parseStatsDuration(s string) time.Duration {
+	parts := strings.Fields(s)
+	if len(parts) != 2 {
+		return 0
+	}
+	v, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0
+	}
+	var unit time.Duration
+	switch parts[1] {
+	case "usec", "usecs":
+		unit = time.Microsecond
+	case "msec", "msecs":
+		unit = time.Millisecond
+	case "sec", "secs":
+		unit = time.Second
+	case "min", "mins":
+		unit = time.Minute
+	default:
+		return 0
+	}
+	return time.Duration(v * float64(unit))
+}
+
+// QueryPlanSummary returns a human readable summary of the relational
+// operators in the given query plan. Each operator is printed on a separate
+// line and indented according to its depth in the plan. The execution
+// statistics of the operators are included if the plan was returned for a
+// query that was executed with QueryWithStats.
+func QueryPlanSummary(plan *sppb.QueryPlan) string {
+	if plan == nil || len(plan.PlanNodes) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	// A valid plan is a tree, but a malformed plan could link a node to one
+	// of its ancestors, so each node is only visited once.
+	visited := make(map[int32]bool)
+	var visit func(index int32, depth int)
+	visit = func(index int32, depth int) {
+		if index < 0 || int(index) >= len(plan.PlanNodes) || visited[index] {
+			return
+		}
+		visited[index] = true
+		node := plan.PlanNodes[index]
+		if node.Kind != sppb.PlanNode_RELATIONAL {
+			return
+		}
+		fmt.Fprintf(&b, "%s%s", strings.Repeat("  ", depth), node.DisplayName)
+		if md := formatPlanNodeStruct(node.Metadata); md != "" {
+			fmt.Fprintf(&b, " (%s)", md)
+		}
+		if stats := formatPlanNodeExecutionStats(node.ExecutionStats); stats != "" {
+			fmt.Fprintf(&b, " [%s]", stats)
+		}
+		b.WriteString("\n")
+		for _, link := range node.ChildLinks {
+			visit(link.ChildIndex, depth+1)
+		}
+	}
+	visit(plan.PlanNodes[0].Index, 0)
+	return b.String()
+}
+
+func formatPlanNodeStruct(s *structpb.Struct) string {
+	if s == nil {
+		return ""
+	}
+	var keys []string
+	for k, v := range s.Fields {
+		if _, ok := v.Kind.(*structpb.Value_StructValue); ok {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s: %v", k, s.Fields[k].AsInterface()))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatPlanNodeExecutionStats formats the total number of rows and the total
+// latency of a plan node. Spanner returns these as nested structs in the form
+// {"rows": {"total": "3", "unit": "rows"}}.
+func formatPlanNodeExecutionStats(s *structpb.Struct) string {
+	if s == nil {
+		return ""
+	}
+	var parts []string
+	for _, key := range []string{"rows", "latency"} {
+		v, ok := s.Fields[key]
+		if !ok || v.GetStructValue() == nil {
+			continue
+		}
+		fields := v.GetStructValue().Fields
+		total := fields["total"].GetStringValue()
+		if total == "" {
+			continue
+		}
+		if unit := fields["unit"].GetStringValue(); unit != "" {
+			total = total + " " + unit
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", key, total))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"testing"
+	"time"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestParseStatsDuration(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		in   string
+		want time.Duration
+	}{
+		{"1.5 msecs", 1500 * time.Microsecond},
+		{"12 usecs", 12 * time.Microsecond},
+		{"2 secs", 2 * time.Second},
+		{"1 mins", time.Minute},
+		{"0 msecs", 0},
+		{"", 0},
+		{"foo msecs", 0},
+		{"1 days", 0},
+	} {
+		if got := parseStatsDuration(test.in); got != test.want {
+			t.Errorf("parseStatsDuration(%q) mismatch\nGot: %v\nWant: %v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestRowIteratorStatistics(t *testing.T) {
+	t.Parallel()
+	raw := map[string]interface{}{
+		"query_text":               "SELECT * FROM Singers",
+		"elapsed_time":             "1.25 msecs",
+		"cpu_time":                 "0.5 msecs",
+		"query_plan_creation_time": "100 usecs",
+		"rows_returned":            "3",
+		"rows_scanned":             "10",
+		"deleted_rows_scanned":     "2",
+		"optimizer_version":        "6",
+		"remote_server_calls":      "0/0",
+	}
+	iter := &RowIterator{QueryStats: raw}
+	got := iter.Statistics()
+	want := &QueryStatistics{
+		QueryText:             "SELECT * FROM Singers",
+		ElapsedTime:           1250 * time.Microsecond,
+		CPUTime:               500 * time.Microsecond,
+		QueryPlanCreationTime: 100 * time.Microsecond,
+		RowsReturned:          3,
+		RowsScanned:           10,
+		DeletedRowsScanned:    2,
+		OptimizerVersion:      "6",
+		Raw:                   raw,
+	}
+	if !testEqual(got, want) {
+		t.Fatalf("statistics mismatch\nGot: %+v\nWant: %+v", got, want)
+	}
+	if got := (&RowIterator{}).Statistics(); got != nil {
+		t.Fatalf("got %+v for iterator without stats, want nil", got)
+	}
+}
+
+func TestQueryPlanSummary(t *testing.T) {
+	t.Parallel()
+	mustStruct := func(m map[string]interface{}) *structpb.Struct {
+		s, err := structpb.NewStruct(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	plan := &sppb.QueryPlan{
+		PlanNodes: []*sppb.PlanNode{
+			{
+				Index:       0,
+				Kind:        sppb.PlanNode_RELATIONAL,
+				DisplayName: "Distributed Union",
+				ChildLinks:  []*sppb.PlanNode_ChildLink{{ChildIndex: 1}, {ChildIndex: 3}},
+				ExecutionStats: mustStruct(map[string]interface{}{
+					"rows":    map[string]interface{}{"total": "2", "unit": "rows"},
+					"latency": map[string]interface{}{"total": "0.5", "unit": "msecs"},
+				}),
+			},
+			{
+				Index:       1,
+				Kind:        sppb.PlanNode_RELATIONAL,
+				DisplayName: "Scan",
+				Metadata: mustStruct(map[string]interface{}{
+					"scan_type":   "TableScan",
+					"scan_target": "Singers",
+				}),
+				ChildLinks: []*sppb.PlanNode_ChildLink{{ChildIndex: 2}},
+			},
+			{
+				Index:       2,
+				Kind:        sppb.PlanNode_SCALAR,
+				DisplayName: "Reference",
+			},
+			{
+				Index:       3,
+				Kind:        sppb.PlanNode_RELATIONAL,
+				DisplayName: "Serialize Result",
+			},
+		},
+	}
+	want := "Distributed Union [rows: 2 rows, latency: 0.5 msecs]\n" +
+		"  Scan (scan_target: Singers, scan_type: TableScan)\n" +
+		"  Serialize Result\n"
+	if got := QueryPlanSummary(plan); got != want {
+		t.Fatalf("summary mismatch\nGot:\n%s\nWant:\n%s", got, want)
+	}
+	if got := QueryPlanSummary(nil); got != "" {
+		t.Fatalf("got %q for nil plan, want empty string", got)
+	}
+}
+
+func TestQueryPlanSummary_Cycle(t *testing.T) {
+	t.Parallel()
+	plan := &sppb.QueryPlan{
+		PlanNodes: []*sppb.PlanNode{
+			{
+				Index:       0,
+				Kind:        sppb.PlanNode_RELATIONAL,
+				DisplayName: "Union",
+				ChildLinks:  []*sppb.PlanNode_ChildLink{{ChildIndex: 1}},
+			},
+			{
+				Index:       1,
+				Kind:        sppb.PlanNode_RELATIONAL,
+				DisplayName: "Scan",
+				ChildLinks:  []*sppb.PlanNode_ChildLink{{ChildIndex: 0}, {ChildIndex: 1}},
+			},
+		},
+	}
+	want := "Union\n  Scan\n"
+	if got := QueryPlanSummary(plan); got != want {
+		t.Fatalf("summary mismatch\nGot:\n%s\nWant:\n%s", got, want)
+	}
+}
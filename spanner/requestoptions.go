@@ -0,0 +1,111 @@
+/*
+Copyright 2024 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+)
+
+// RequestOptions contains the request options that apply to the operations
+// that are called with a context returned by WithRequestOptions.
+type RequestOptions struct {
+	// Priority is the RPC priority of reads, queries and DML statements, and
+	// the commit priority of read/write transactions and mutations.
+	Priority sppb.RequestOptions_Priority
+
+	// RequestTag is the request tag of reads, queries and DML statements.
+	RequestTag string
+
+	// TransactionTag is the transaction tag of read/write transactions and
+	// mutations.
+	TransactionTag string
+}
+
+type requestOptionsKey struct{}
+
+// WithRequestOptions returns a copy of ctx that carries opts. The operations
+// that are called with the returned context, or with a context derived from
+// it, use the priority and tags of opts, unless they are set in the options of
+// the operation, such as QueryOptions, ReadOptions, TransactionOptions,
+// BatchWriteOptions and ApplyOption values, or in the default options of the
+// Client.
+//
+// This makes it possible to set the priority and tags of all the operations of
+// a background job, or of a read/write transaction, without passing options
+// to each call:
+//
+//	ctx = spanner.WithRequestOptions(ctx, spanner.RequestOptions{
+//		Priority:       sppb.RequestOptions_PRIORITY_LOW,
+//		TransactionTag: "nightly-cleanup",
+//	})
+//	_, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, txn *spanner.ReadWriteTransaction) error {
+//		// All statements and the commit have low priority.
+//		_, err := txn.Update(spanner.WithRequestOptions(ctx, spanner.RequestOptions{RequestTag: "delete-expired"}), stmt)
+//		return err
+//	})
+//
+// The transaction tag and the commit priority of a read/write transaction are
+// taken from the context that is passed to ReadWriteTransaction, Apply or
+// NewReadWriteStmtBasedTransaction. The fields of opts that are not set are
+// inherited from the options that were set on ctx by an outer call to
+// WithRequestOptions.
+func WithRequestOptions(ctx context.Context, opts RequestOptions) context.Context {
+	outer := requestOptionsFromContext(ctx)
+	if opts.Priority == sppb.RequestOptions_PRIORITY_UNSPECIFIED {
+		opts.Priority = outer.Priority
+	}
+	if opts.RequestTag == "" {
+		opts.RequestTag = outer.RequestTag
+	}
+	if opts.TransactionTag == "" {
+		opts.TransactionTag = outer.TransactionTag
+	}
+	return context.WithValue(ctx, requestOptionsKey{}, opts)
+}
+
+func requestOptionsFromContext(ctx context.Context) RequestOptions {
+	opts, _ := ctx.Value(requestOptionsKey{}).(RequestOptions)
+	return opts
+}
+
+// withContextRequestOptions sets the priority and the request tag of ro that
+// are not set to the ones of the RequestOptions in ctx, and returns ro.
+func withContextRequestOptions(ctx context.Context, ro *sppb.RequestOptions) *sppb.RequestOptions {
+	opts := requestOptionsFromContext(ctx)
+	if ro.Priority == sppb.RequestOptions_PRIORITY_UNSPECIFIED {
+		ro.Priority = opts.Priority
+	}
+	if ro.RequestTag == "" {
+		ro.RequestTag = opts.RequestTag
+	}
+	return ro
+}
+
+// withContextTransactionOptions returns to with the commit priority and the
+// transaction tag of the RequestOptions in ctx, if they are not set.
+func withContextTransactionOptions(ctx context.Context, to TransactionOptions) TransactionOptions {
+	opts := requestOptionsFromContext(ctx)
+	if to.CommitPriority == sppb.RequestOptions_PRIORITY_UNSPECIFIED {
+		to.CommitPriority = opts.Priority
+	}
+	if to.TransactionTag == "" {
+		to.TransactionTag = opts.TransactionTag
+	}
+	return to
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"testing"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	. "cloud.google.com/go/spanner/internal/testutil"
+)
+
+func TestWithRequestOptions_ReadWriteTransaction(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	ctx := WithRequestOptions(context.Background(), RequestOptions{
+		Priority:       sppb.RequestOptions_PRIORITY_LOW,
+		TransactionTag: "tx-tag",
+	})
+	_, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		ctx = WithRequestOptions(ctx, RequestOptions{RequestTag: "request-tag"})
+		iter := tx.Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+		iter.Next()
+		iter.Stop()
+
+		iter = tx.Read(ctx, "FOO", AllKeys(), []string{"BAR"})
+		iter.Next()
+		iter.Stop()
+
+		if _, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo)); err != nil {
+			return err
+		}
+		if _, err := tx.BatchUpdate(ctx, []Statement{NewStatement(UpdateBarSetFoo)}); err != nil {
+			return err
+		}
+		checkRequestsForExpectedRequestOptions(t, server.TestSpanner, 4, &sppb.RequestOptions{
+			Priority:       sppb.RequestOptions_PRIORITY_LOW,
+			RequestTag:     "request-tag",
+			TransactionTag: "tx-tag",
+		})
+
+		// The options of a statement take precedence.
+		if _, err := tx.UpdateWithOptions(ctx, NewStatement(UpdateBarSetFoo), QueryOptions{Priority: sppb.RequestOptions_PRIORITY_HIGH, RequestTag: "update-tag"}); err != nil {
+			return err
+		}
+		checkRequestsForExpectedRequestOptions(t, server.TestSpanner, 1, &sppb.RequestOptions{
+			Priority:       sppb.RequestOptions_PRIORITY_HIGH,
+			RequestTag:     "update-tag",
+			TransactionTag: "tx-tag",
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCommitForExpectedRequestOptions(t, server.TestSpanner, &sppb.RequestOptions{
+		Priority:       sppb.RequestOptions_PRIORITY_LOW,
+		TransactionTag: "tx-tag",
+	})
+}
+
+func TestWithRequestOptions_Apply(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	ctx := WithRequestOptions(context.Background(), RequestOptions{
+		Priority:       sppb.RequestOptions_PRIORITY_MEDIUM,
+		TransactionTag: "tx-tag",
+	})
+	ms := []*Mutation{Insert("foo", []string{"col1"}, []interface{}{"val1"})}
+	for _, opts := range [][]ApplyOption{nil, {ApplyAtLeastOnce()}} {
+		if _, err := client.Apply(ctx, ms, opts...); err != nil {
+			t.Fatal(err)
+		}
+		checkCommitForExpectedRequestOptions(t, server.TestSpanner, &sppb.RequestOptions{
+			Priority:       sppb.RequestOptions_PRIORITY_MEDIUM,
+			TransactionTag: "tx-tag",
+		})
+	}
+
+	// The options of Apply take precedence.
+	if _, err := client.Apply(ctx, ms, Priority(sppb.RequestOptions_PRIORITY_HIGH), TransactionTag("apply-tag")); err != nil {
+		t.Fatal(err)
+	}
+	checkCommitForExpectedRequestOptions(t, server.TestSpanner, &sppb.RequestOptions{
+		Priority:       sppb.RequestOptions_PRIORITY_HIGH,
+		TransactionTag: "apply-tag",
+	})
+}
+
+func TestWithRequestOptions_SingleUse(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	ctx := WithRequestOptions(context.Background(), RequestOptions{
+		Priority:       sppb.RequestOptions_PRIORITY_LOW,
+		RequestTag:     "request-tag",
+		TransactionTag: "ignored",
+	})
+	iter := client.Single().Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	iter.Next()
+	iter.Stop()
+	// Read-only transactions have no transaction tag.
+	checkRequestsForExpectedRequestOptions(t, server.TestSpanner, 1, &sppb.RequestOptions{
+		Priority:   sppb.RequestOptions_PRIORITY_LOW,
+		RequestTag: "request-tag",
+	})
+}
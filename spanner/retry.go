@@ -84,7 +84,10 @@ func (r *spannerRetryer) Retry(err error) (time.Duration, bool) {
 // returned by Cloud Spanner, or if none is returned, the calculated delay with
 // a minimum of 10ms and maximum of 32s. There is no delay before the retry if
 // the error was Session not found or failed inline begin transaction.
-func runWithRetryOnAbortedOrFailedInlineBeginOrSessionNotFound(ctx context.Context, f func(context.Context) error) error {
+//
+// If onAborted is not nil, it is called with the backoff delay and the error
+// before each retry after an Aborted error.
+func runWithRetryOnAbortedOrFailedInlineBeginOrSessionNotFound(ctx context.Context, f func(context.Context) error, onAborted func(delay time.Duration, err error)) error {
 	retryer := onCodes(DefaultRetryBackoff, codes.Aborted, codes.Internal)
 	funcWithRetry := func(ctx context.Context) error {
 		for {
@@ -122,6 +125,9 @@ func runWithRetryOnAbortedOrFailedInlineBeginOrSessionNotFound(ctx context.Conte
 			if !shouldRetry {
 				return err
 			}
+			if onAborted != nil && status.Code(retryErr) == codes.Aborted {
+				onAborted(delay, retryErr)
+			}
 			trace.TracePrintf(ctx, nil, "Backing off after ABORTED for %s, then retrying", delay)
 			if err := gax.Sleep(ctx, delay); err != nil {
 				return err
@@ -154,3 +160,24 @@ func ExtractRetryDelay(err error) (time.Duration, bool) {
 	}
 	return 0, false
 }
+
+// extractErrorInfo extracts the ErrorInfo details from a *spanner.Error or a
+// gRPC status error if present.
+func extractErrorInfo(err error) *errdetails.ErrorInfo {
+	var se *Error
+	var s *status.Status
+	if errorAs(err, &se) {
+		s = status.Convert(se.Unwrap())
+	} else {
+		s = status.Convert(err)
+	}
+	if s == nil {
+		return nil
+	}
+	for _, detail := range s.Details() {
+		if errorInfo, ok := detail.(*errdetails.ErrorInfo); ok {
+			return errorInfo
+		}
+	}
+	return nil
+}
@@ -89,3 +89,61 @@ func TestRetryerRespectsServerDelay(t *testing.T) {
 		t.Fatalf("Retry delay mismatch:\ngot: %v\nwant: %v", maxSeenDelay, serverDelay)
 	}
 }
+
+func TestExtractErrorInfo(t *testing.T) {
+	t.Parallel()
+	s := status.New(codes.Aborted, "transaction was aborted")
+	s, err := s.WithDetails(&edpb.ErrorInfo{
+		Reason:   "CONFLICT",
+		Metadata: map[string]string{"table": "Singers"},
+	})
+	if err != nil {
+		t.Fatalf("Error setting error details: %v", err)
+	}
+	info := extractErrorInfo(toSpannerErrorWithCommitInfo(s.Err(), true))
+	if info == nil {
+		t.Fatal("missing error info")
+	}
+	if g, w := info.GetReason(), "CONFLICT"; g != w {
+		t.Fatalf("reason mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if info := extractErrorInfo(status.Error(codes.Aborted, "")); info != nil {
+		t.Fatalf("got unexpected error info: %v", info)
+	}
+}
+
+func TestRunWithRetryOnAborted_OnAbortedCallback(t *testing.T) {
+	t.Parallel()
+	s := status.New(codes.Aborted, "transaction was aborted")
+	s, err := s.WithDetails(&edpb.RetryInfo{
+		RetryDelay: durationpb.New(time.Millisecond),
+	})
+	if err != nil {
+		t.Fatalf("Error setting retry details: %v", err)
+	}
+	errs := []error{
+		toSpannerErrorWithCommitInfo(s.Err(), true),
+		spannerErrorf(codes.NotFound, "Session not found"),
+		toSpannerErrorWithCommitInfo(s.Err(), true),
+	}
+	var delays []time.Duration
+	err = runWithRetryOnAbortedOrFailedInlineBeginOrSessionNotFound(context.Background(), func(ctx context.Context) error {
+		if len(errs) == 0 {
+			return nil
+		}
+		err := errs[0]
+		errs = errs[1:]
+		return err
+	}, func(delay time.Duration, err error) {
+		if ErrCode(err) != codes.Aborted {
+			t.Errorf("unexpected error code for callback: %v", err)
+		}
+		delays = append(delays, delay)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := delays, []time.Duration{time.Millisecond, time.Millisecond}; !testEqual(g, w) {
+		t.Fatalf("delays mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
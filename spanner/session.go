@@ -513,6 +513,52 @@ var DefaultSessionPoolConfig = SessionPoolConfig{
 	},
 }
 
+// SessionPoolStatus is a snapshot of the state of the session pool of a
+// Client. It can be used to diagnose session exhaustion and session leaks.
+type SessionPoolStatus struct {
+	// MinOpened is the current minimum number of opened sessions that the
+	// session pool tries to maintain.
+	MinOpened uint64
+	// MaxOpened is the current maximum number of opened sessions that is
+	// allowed by the session pool.
+	MaxOpened uint64
+	// NumOpened is the number of sessions that are currently opened, including
+	// sessions that are being created.
+	NumOpened uint64
+	// NumInUse is the number of sessions that are currently checked out of the
+	// session pool.
+	NumInUse uint64
+	// NumIdle is the number of sessions that are currently available in the
+	// session pool.
+	NumIdle uint64
+	// NumWaiters is the number of requests that are currently waiting for a
+	// session to become available.
+	NumWaiters uint64
+	// NumLeakedSessionsRemoved is the number of sessions that have been removed
+	// from the session pool because they were deemed to be leaked. This is
+	// only non-zero if ActionOnInactiveTransaction is Close or WarnAndClose.
+	NumLeakedSessionsRemoved uint64
+	// CheckedOutSessions contains the sessions that are currently checked out
+	// of the session pool. This list is only filled if TrackSessionHandles is
+	// enabled or ActionOnInactiveTransaction is set to any other value than
+	// NoAction.
+	CheckedOutSessions []CheckedOutSession
+}
+
+// CheckedOutSession contains information about a session that is currently
+// checked out of the session pool.
+type CheckedOutSession struct {
+	// ID is the name of the session.
+	ID string
+	// CheckoutTime is the time that the session was checked out of the pool.
+	CheckoutTime time.Time
+	// LastUseTime is the time that the session was last used.
+	LastUseTime time.Time
+	// Stack is the stack trace of the goroutine that checked out the session.
+	// It is only set if TrackSessionHandles is enabled.
+	Stack []byte
+}
+
 // errMinOpenedGTMapOpened returns error for SessionPoolConfig.MaxOpened < SessionPoolConfig.MinOpened when SessionPoolConfig.MaxOpened is set.
 func errMinOpenedGTMaxOpened(maxOpened, minOpened uint64) error {
 	return spannerErrorf(codes.InvalidArgument,
@@ -723,6 +769,12 @@ func (p *sessionPool) recordOTStat(ctx context.Context, m metric.Int64Counter, v
 	}
 }
 
+func (p *sessionPool) recordOTWaitTime(ctx context.Context, d time.Duration) {
+	if p.otConfig.getSessionWaitTime != nil {
+		p.otConfig.getSessionWaitTime.Record(ctx, float64(d)/float64(time.Millisecond), metric.WithAttributes(p.otConfig.attributeMap...))
+	}
+}
+
 func (p *sessionPool) getRatioOfSessionsInUseLocked() float64 {
 	maxSessions := p.MaxOpened
 	if maxSessions == 0 {
@@ -796,6 +848,62 @@ func (p *sessionPool) removeLongRunningSessions() {
 	}
 }
 
+// status returns a snapshot of the current state of the session pool.
+func (p *sessionPool) status() SessionPoolStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st := SessionPoolStatus{
+		MinOpened:                p.MinOpened,
+		MaxOpened:                p.MaxOpened,
+		NumOpened:                p.numOpened,
+		NumInUse:                 p.numInUse,
+		NumIdle:                  uint64(p.idleList.Len()),
+		NumWaiters:               p.numWaiters,
+		NumLeakedSessionsRemoved: p.numOfLeakedSessionsRemoved,
+	}
+	for element := p.trackedSessionHandles.Front(); element != nil; element = element.Next() {
+		sh := element.Value.(*sessionHandle)
+		sh.mu.Lock()
+		if sh.session != nil {
+			st.CheckedOutSessions = append(st.CheckedOutSessions, CheckedOutSession{
+				ID:           sh.session.getID(),
+				CheckoutTime: sh.checkoutTime,
+				LastUseTime:  sh.lastUseTime,
+				Stack:        sh.stack,
+			})
+		}
+		sh.mu.Unlock()
+	}
+	return st
+}
+
+// setSizeLimits changes the MinOpened and MaxOpened values of the session
+// pool. The pool will immediately start to create new sessions if the current
+// number of opened sessions is less than minOpened. Sessions that exceed
+// maxOpened are removed by the maintainer when they are returned to the pool.
+func (p *sessionPool) setSizeLimits(minOpened, maxOpened uint64) error {
+	if minOpened > maxOpened && maxOpened > 0 {
+		return errMinOpenedGTMaxOpened(maxOpened, minOpened)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.valid {
+		return errInvalidSessionPool
+	}
+	p.MinOpened = minOpened
+	p.MaxOpened = maxOpened
+	p.recordStat(context.Background(), MaxAllowedSessionsCount, int64(maxOpened))
+	if p.numOpened < minOpened {
+		if err := p.growPoolLocked(minOpened-p.numOpened, true); err != nil {
+			return err
+		}
+	}
+	// Notify any waiters, as they might now be allowed to create new sessions.
+	close(p.mayGetSession)
+	p.mayGetSession = make(chan struct{})
+	return nil
+}
+
 func (p *sessionPool) initPool(numSessions uint64) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -1034,6 +1142,12 @@ func (p *sessionPool) isHealthy(s *session) bool {
 // any, it tries to allocate a new one.
 func (p *sessionPool) take(ctx context.Context) (*sessionHandle, error) {
 	trace.TracePrintf(ctx, nil, "Acquiring a session")
+	start := time.Now()
+	if p.otConfig != nil {
+		// Record the wait time on every path, including timeouts and errors,
+		// which are most common when the pool is exhausted.
+		defer func() { p.recordOTWaitTime(ctx, time.Since(start)) }()
+	}
 	for {
 		var s *session
 
@@ -1070,7 +1184,10 @@ func (p *sessionPool) take(ctx context.Context) (*sessionHandle, error) {
 		// No session available. Start the creation of a new batch of sessions
 		// if that is allowed, and then wait for a session to come available.
 		if p.numWaiters >= p.createReqs {
-			numSessions := minUint64(p.MaxOpened-p.numOpened, p.incStep)
+			var numSessions uint64
+			if p.MaxOpened == 0 || p.numOpened < p.MaxOpened {
+				numSessions = minUint64(p.MaxOpened-p.numOpened, p.incStep)
+			}
 			if err := p.growPoolLocked(numSessions, false); err != nil {
 				p.mu.Unlock()
 				return nil, err
@@ -1537,6 +1654,7 @@ func (hc *healthChecker) maintainer() {
 		currSessionsOpened := hc.pool.numOpened
 		maxIdle := hc.pool.MaxIdle
 		minOpened := hc.pool.MinOpened
+		maxOpened := hc.pool.MaxOpened
 
 		// Reset the start time for recording the maximum number of sessions
 		// in the pool.
@@ -1566,13 +1684,18 @@ func (hc *healthChecker) maintainer() {
 
 		// Grow or shrink pool if needed.
 		// The number of sessions in the pool should be in the range
-		// [Config.MinOpened, Config.MaxIdle+maxSessionsInUseDuringWindow]
+		// [Config.MinOpened, min(Config.MaxIdle+maxSessionsInUseDuringWindow, Config.MaxOpened)]
+		shrinkToNumSessions := maxIdle + maxSessionsInUseDuringWindow
+		if maxOpened > 0 && shrinkToNumSessions > maxOpened {
+			// MaxOpened may have been lowered after the pool was created.
+			shrinkToNumSessions = maxOpened
+		}
 		if currSessionsOpened < minOpened {
 			if err := hc.growPoolInBatch(ctx, minOpened); err != nil {
 				logf(hc.pool.sc.logger, "failed to grow pool: %v", err)
 			}
-		} else if maxIdle+maxSessionsInUseDuringWindow < currSessionsOpened {
-			hc.shrinkPool(ctx, maxIdle+maxSessionsInUseDuringWindow)
+		} else if shrinkToNumSessions < currSessionsOpened {
+			hc.shrinkPool(ctx, shrinkToNumSessions)
 		}
 
 		select {
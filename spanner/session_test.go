@@ -1022,6 +1022,124 @@ func TestMinOpenedSessions(t *testing.T) {
 	}
 }
 
+// TestSetSessionPoolLimits_IncreaseMaxOpened tests that waiters can proceed
+// when MaxOpened is increased.
+func TestSetSessionPoolLimits_IncreaseMaxOpened(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	_, client, teardown := setupMockedTestServerWithConfig(t,
+		ClientConfig{
+			SessionPoolConfig: SessionPoolConfig{
+				MaxOpened: 1,
+			},
+		})
+	defer teardown()
+	sp := client.idleSessions
+
+	sh1, err := sp.take(ctx)
+	if err != nil {
+		t.Fatalf("cannot take session from session pool: %v", err)
+	}
+	defer sh1.recycle()
+
+	errc := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		sh2, err := sp.take(ctx)
+		if err == nil {
+			sh2.recycle()
+		}
+		errc <- err
+	}()
+	waitFor(t, func() error {
+		if st := client.SessionPoolStatus(); st.NumWaiters != 1 {
+			return fmt.Errorf("waiters mismatch\nGot: %d\nWant: 1", st.NumWaiters)
+		}
+		return nil
+	})
+	if err := client.SetSessionPoolLimits(0, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("session retrieval after increasing MaxOpened returned error %v, want nil", err)
+	}
+	if g, w := client.SessionPoolStatus().MaxOpened, uint64(2); g != w {
+		t.Fatalf("MaxOpened mismatch\nGot: %d\nWant: %d", g, w)
+	}
+}
+
+// TestSetSessionPoolLimits_IncreaseMinOpened tests that the pool creates new
+// sessions when MinOpened is increased.
+func TestSetSessionPoolLimits_IncreaseMinOpened(t *testing.T) {
+	t.Parallel()
+	_, client, teardown := setupMockedTestServerWithConfig(t,
+		ClientConfig{
+			SessionPoolConfig: SessionPoolConfig{
+				MinOpened: 0,
+				MaxOpened: 100,
+			},
+		})
+	defer teardown()
+
+	if err := client.SetSessionPoolLimits(25, 100); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() error {
+		if st := client.SessionPoolStatus(); st.NumIdle != 25 {
+			return fmt.Errorf("idle sessions mismatch\nGot: %d\nWant: 25", st.NumIdle)
+		}
+		return nil
+	})
+	if err, want := client.SetSessionPoolLimits(10, 5), errMinOpenedGTMaxOpened(5, 10); !testEqual(err, want) {
+		t.Fatalf("error mismatch\nGot: %v\nWant: %v", err, want)
+	}
+}
+
+// TestSessionPoolStatus tests that the status of the session pool includes the
+// checked out sessions.
+func TestSessionPoolStatus(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	_, client, teardown := setupMockedTestServerWithConfig(t,
+		ClientConfig{
+			SessionPoolConfig: SessionPoolConfig{
+				MinOpened:           1,
+				MaxOpened:           10,
+				TrackSessionHandles: true,
+			},
+		})
+	defer teardown()
+	sp := client.idleSessions
+
+	sh, err := sp.take(ctx)
+	if err != nil {
+		t.Fatalf("cannot take session from session pool: %v", err)
+	}
+	st := client.SessionPoolStatus()
+	if g, w := st.NumInUse, uint64(1); g != w {
+		t.Fatalf("in use mismatch\nGot: %d\nWant: %d", g, w)
+	}
+	if g, w := len(st.CheckedOutSessions), 1; g != w {
+		t.Fatalf("checked out sessions mismatch\nGot: %d\nWant: %d", g, w)
+	}
+	co := st.CheckedOutSessions[0]
+	if g, w := co.ID, sh.getID(); g != w {
+		t.Fatalf("session id mismatch\nGot: %s\nWant: %s", g, w)
+	}
+	if !strings.Contains(string(co.Stack), "TestSessionPoolStatus") {
+		t.Fatalf("stack trace does not contain test function:\n%s", co.Stack)
+	}
+	sh.recycle()
+	st = client.SessionPoolStatus()
+	if g, w := st.NumInUse, uint64(0); g != w {
+		t.Fatalf("in use mismatch\nGot: %d\nWant: %d", g, w)
+	}
+	if g, w := len(st.CheckedOutSessions), 0; g != w {
+		t.Fatalf("checked out sessions mismatch\nGot: %d\nWant: %d", g, w)
+	}
+}
+
 // TestMaxBurst tests max burst constraint.
 func TestMaxBurst(t *testing.T) {
 	t.Parallel()
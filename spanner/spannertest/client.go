@@ -0,0 +1,99 @@
+/*
+Copyright 2024 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spannertest
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/spanner"
+	"cloud.google.com/go/spanner/spansql"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// DatabaseName is the name of the database that is simulated by a Server.
+// The Server accepts any database name, but this name is used by
+// Server.NewClient.
+const DatabaseName = "projects/fake-proj/instances/fake-instance/databases/fake-db"
+
+// ApplyDDL parses the given DDL statements and applies them to the database.
+// It stops at the first statement that cannot be parsed or applied.
+func (s *Server) ApplyDDL(stmts ...string) error {
+	ddl := &spansql.DDL{}
+	for _, stmt := range stmts {
+		ds, err := spansql.ParseDDLStmt(stmt)
+		if err != nil {
+			return fmt.Errorf("bad DDL statement %q: %v", stmt, err)
+		}
+		ddl.List = append(ddl.List, ds)
+	}
+	return s.UpdateDDL(ddl)
+}
+
+// NewClient returns a spanner.Client that is connected to the Server. The
+// client is created with the same client API as a client for the real Cloud
+// Spanner, and can be used for reads, queries, DML, mutations and
+// transactions. Any given options are applied after the options that connect
+// the client to the Server.
+//
+// The caller must close the client when it is no longer needed.
+func (s *Server) NewClient(ctx context.Context, opts ...option.ClientOption) (*spanner.Client, error) {
+	opts = append([]option.ClientOption{
+		option.WithEndpoint(s.Addr),
+		option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		option.WithoutAuthentication(),
+	}, opts...)
+	return spanner.NewClient(ctx, DatabaseName, opts...)
+}
+
+// NewTestClient starts a new in-memory Server, applies the given DDL
+// statements to it, and returns a spanner.Client that is connected to the
+// Server. The returned function closes both the client and the Server.
+//
+// This is a convenient way to write unit tests for code that uses a
+// spanner.Client without starting the Cloud Spanner emulator:
+//
+//	client, cleanup, err := spannertest.NewTestClient(ctx,
+//		`CREATE TABLE Singers (
+//			SingerId INT64 NOT NULL,
+//			Name STRING(MAX),
+//		) PRIMARY KEY (SingerId)`)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer cleanup()
+func NewTestClient(ctx context.Context, ddl ...string) (*spanner.Client, func(), error) {
+	srv, err := NewServer("localhost:0")
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := srv.ApplyDDL(ddl...); err != nil {
+		srv.Close()
+		return nil, nil, err
+	}
+	client, err := srv.NewClient(ctx)
+	if err != nil {
+		srv.Close()
+		return nil, nil, err
+	}
+	return client, func() {
+		client.Close()
+		srv.Close()
+	}, nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spannertest
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+)
+
+func TestNewTestClient(t *testing.T) {
+	ctx := context.Background()
+	client, cleanup, err := NewTestClient(ctx,
+		`CREATE TABLE Singers (
+			SingerId INT64 NOT NULL,
+			Name STRING(MAX),
+		) PRIMARY KEY (SingerId)`)
+	if err != nil {
+		t.Fatalf("NewTestClient: %v", err)
+	}
+	defer cleanup()
+
+	if _, err := client.Apply(ctx, []*spanner.Mutation{
+		spanner.Insert("Singers", []string{"SingerId", "Name"}, []interface{}{1, "Alice"}),
+	}); err != nil {
+		t.Fatalf("Applying mutations: %v", err)
+	}
+	if _, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *spanner.ReadWriteTransaction) error {
+		_, err := tx.Update(ctx, spanner.NewStatement(`INSERT INTO Singers (SingerId, Name) VALUES (2, "Bob")`))
+		return err
+	}); err != nil {
+		t.Fatalf("Executing DML in transaction: %v", err)
+	}
+
+	var names []string
+	err = client.Single().Query(ctx, spanner.NewStatement("SELECT Name FROM Singers ORDER BY SingerId")).Do(func(r *spanner.Row) error {
+		var name string
+		if err := r.Columns(&name); err != nil {
+			return err
+		}
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Querying: %v", err)
+	}
+	if len(names) != 2 || names[0] != "Alice" || names[1] != "Bob" {
+		t.Errorf("Got names %v, want [Alice Bob]", names)
+	}
+}
+
+func TestServerApplyDDL_Invalid(t *testing.T) {
+	srv, err := NewServer("localhost:0")
+	if err != nil {
+		t.Fatalf("Starting in-memory fake: %v", err)
+	}
+	defer srv.Close()
+	if err := srv.ApplyDDL("CREATE TABLE"); err == nil {
+		t.Error("ApplyDDL with an invalid statement did not fail")
+	}
+	if err := srv.ApplyDDL("DROP TABLE Unknown"); err == nil {
+		t.Error("ApplyDDL for an unknown table did not fail")
+	}
+}
@@ -41,6 +41,13 @@ variable and use the regular spanner.NewClient:
 	client, err := spanner.NewClient(ctx, db)
 	...
 
+For unit tests, NewTestClient starts a Server, applies a schema to it and
+returns a client that is connected to it:
+
+	client, cleanup, err := spannertest.NewTestClient(ctx, ddlStatements...)
+	...
+	defer cleanup()
+
 The same server also supports database admin operations for use with
 the cloud.google.com/go/spanner/admin/database/apiv1 package. This only
 simulates the existence of a single database; its name is ignored.
@@ -32,6 +32,8 @@ import (
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
 	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	structpb "google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -143,6 +145,40 @@ func TestOTMetrics_SessionPool(t *testing.T) {
 				},
 			},
 		},
+		{
+			"GetSessionWaitersCount",
+			metricdata.Metrics{
+				Name:        "spanner/num_get_session_waiters",
+				Description: "The number of requests that are waiting for a session to become available.",
+				Unit:        "1",
+				Data: metricdata.Gauge[int64]{
+					DataPoints: []metricdata.DataPoint[int64]{
+						{
+							Attributes: attribute.NewSet(getAttributes(client.ClientID())...),
+							Value:      0,
+						},
+					},
+				},
+			},
+		},
+		{
+			"LeakedSessionsRemovedCount",
+			metricdata.Metrics{
+				Name:        "spanner/num_leaked_sessions_removed",
+				Description: "The number of sessions that were removed from the session pool because they were deemed to be leaked.",
+				Unit:        "1",
+				Data: metricdata.Sum[int64]{
+					DataPoints: []metricdata.DataPoint[int64]{
+						{
+							Attributes: attribute.NewSet(getAttributes(client.ClientID())...),
+							Value:      0,
+						},
+					},
+					Temporality: metricdata.CumulativeTemporality,
+					IsMonotonic: true,
+				},
+			},
+		},
 		{
 			"ReleasedSessionsCount",
 			metricdata.Metrics{
@@ -253,6 +289,84 @@ func TestOTMetrics_SessionPool_GetSessionTimeoutsCount(t *testing.T) {
 	validateOTMetric(ctx1, t, te, expectedMetricData.Name, expectedMetricData)
 }
 
+func TestOTMetrics_SessionPool_GetSessionWaitTimeOnTimeout(t *testing.T) {
+	ctx1 := context.Background()
+	te := newOpenTelemetryTestExporter(false, false)
+	t.Cleanup(func() {
+		te.Unregister(ctx1)
+	})
+	spanner.EnableOpenTelemetryMetrics()
+	server, client, teardown := setupMockedTestServerWithConfig(t, spanner.ClientConfig{OpenTelemetryMeterProvider: te.mp})
+	defer teardown()
+
+	server.TestSpanner.PutExecutionTime(stestutil.MethodBatchCreateSession,
+		stestutil.SimulatedExecutionTime{
+			MinimumExecutionTime: 2 * time.Millisecond,
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Millisecond)
+	defer cancel()
+	client.Single().ReadRow(ctx, "Users", spanner.Key{"alice"}, []string{"email"})
+
+	rm, err := te.metrics(ctx1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var count uint64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "spanner/get_session_wait_time" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+				count += dp.Count
+			}
+		}
+	}
+	if count != 1 {
+		t.Errorf("got %d get_session_wait_time measurements, want 1 for the timed out request", count)
+	}
+}
+
+func TestOTMetrics_AbortedTransactionRetries(t *testing.T) {
+	ctx := context.Background()
+	te := newOpenTelemetryTestExporter(false, false)
+	t.Cleanup(func() {
+		te.Unregister(ctx)
+	})
+	spanner.EnableOpenTelemetryMetrics()
+	server, client, teardown := setupMockedTestServerWithConfig(t, spanner.ClientConfig{OpenTelemetryMeterProvider: te.mp})
+	defer teardown()
+
+	server.TestSpanner.PutExecutionTime(stestutil.MethodCommitTransaction,
+		stestutil.SimulatedExecutionTime{
+			Errors: []error{status.Error(codes.Aborted, "Transaction aborted")},
+		})
+	if _, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *spanner.ReadWriteTransaction) error {
+		_, err := tx.Update(ctx, spanner.NewStatement(stestutil.UpdateBarSetFoo))
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedMetricData := metricdata.Metrics{
+		Name:        "spanner/num_aborted_transaction_retries",
+		Description: "The number of times that a read/write transaction was retried after it was aborted by Spanner.",
+		Unit:        "1",
+		Data: metricdata.Sum[int64]{
+			DataPoints: []metricdata.DataPoint[int64]{
+				{
+					Attributes: attribute.NewSet(getAttributes(client.ClientID())...),
+					Value:      1,
+				},
+			},
+			Temporality: metricdata.CumulativeTemporality,
+			IsMonotonic: true,
+		},
+	}
+	validateOTMetric(ctx, t, te, expectedMetricData.Name, expectedMetricData)
+}
+
 func TestOTMetrics_GFELatency(t *testing.T) {
 	ctx := context.Background()
 	te := newOpenTelemetryTestExporter(false, false)
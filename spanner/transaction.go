@@ -185,6 +185,11 @@ type ReadOptions struct {
 
 	// An option to control the order in which rows are returned from a read.
 	OrderBy sppb.ReadRequest_OrderBy
+
+	// Timeout is the maximum time that the read may take, including the time
+	// that it takes to iterate over all rows in the result. See
+	// QueryOptions.Timeout for details. Zero means no timeout.
+	Timeout time.Duration
 }
 
 // merge combines two ReadOptions that the input parameter will have higher
@@ -198,6 +203,7 @@ func (ro ReadOptions) merge(opts ReadOptions) ReadOptions {
 		DataBoostEnabled:    ro.DataBoostEnabled,
 		DirectedReadOptions: ro.DirectedReadOptions,
 		OrderBy:             ro.OrderBy,
+		Timeout:             ro.Timeout,
 	}
 	if opts.Index != "" {
 		merged.Index = opts.Index
@@ -220,6 +226,9 @@ func (ro ReadOptions) merge(opts ReadOptions) ReadOptions {
 	if opts.OrderBy != sppb.ReadRequest_ORDER_BY_UNSPECIFIED {
 		merged.OrderBy = opts.OrderBy
 	}
+	if opts.Timeout > 0 {
+		merged.Timeout = opts.Timeout
+	}
 	return merged
 }
 
@@ -228,6 +237,14 @@ func (ro ReadOptions) merge(opts ReadOptions) ReadOptions {
 func (t *txReadOnly) ReadWithOptions(ctx context.Context, table string, keys KeySet, columns []string, opts *ReadOptions) (ri *RowIterator) {
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/spanner.Read")
 	defer func() { trace.EndSpan(ctx, ri.err) }()
+	timeout := t.ro.Timeout
+	if opts != nil && opts.Timeout > 0 {
+		timeout = opts.Timeout
+	}
+	parent := ctx
+	ctx, cancel := withStatementTimeout(ctx, timeout)
+	stmtCtx := ctx
+	defer func() { cancelOnStop(ri, cancel) }()
 	var (
 		sh  *sessionHandle
 		ts  *sppb.TransactionSelector
@@ -293,15 +310,14 @@ func (t *txReadOnly) ReadWithOptions(ctx context.Context, table string, keys Key
 					KeySet:              kset,
 					ResumeToken:         resumeToken,
 					Limit:               int64(limit),
-					RequestOptions:      createRequestOptions(prio, requestTag, t.txOpts.TransactionTag),
+					RequestOptions:      withContextRequestOptions(ctx, createRequestOptions(prio, requestTag, t.txOpts.TransactionTag)),
 					DataBoostEnabled:    dataBoostEnabled,
 					DirectedReadOptions: directedReadOptions,
 					OrderBy:             orderBy,
 				})
 			if err != nil {
 				if _, ok := t.getTransactionSelector().GetSelector().(*sppb.TransactionSelector_Begin); ok {
-					t.setTransactionID(nil)
-					return client, errInlineBeginTransactionFailed()
+					return client, t.inlineBeginFailed(stmtCtx, parent, err)
 				}
 				return client, err
 			}
@@ -319,7 +335,7 @@ func (t *txReadOnly) ReadWithOptions(ctx context.Context, table string, keys Key
 		t.replaceSessionFunc,
 		setTransactionID,
 		t.setTimestamp,
-		t.release,
+		t.statementRelease(stmtCtx, parent),
 	)
 }
 
@@ -423,6 +439,18 @@ type QueryOptions struct {
 	// from the allowed tracking change streams(with DDL option allow_txn_exclusion=true). Setting
 	// this value for any sql/dml requests other than partitioned udpate will receive an error.
 	ExcludeTxnFromChangeStreams bool
+
+	// Timeout is the maximum time that a single query or DML statement may
+	// take. The statement is cancelled with a DeadlineExceeded error if it
+	// does not finish within this time. For queries, the timeout includes the
+	// time that it takes to iterate over all rows in the result. The timeout
+	// only applies to the statement. When used in a read/write transaction,
+	// the transaction can continue with other statements after a statement
+	// timed out. Any deadline on the context that is passed in to the
+	// statement or the transaction still applies. Zero means no timeout.
+	//
+	// Timeout is ignored for partitioned DML statements.
+	Timeout time.Duration
 }
 
 // merge combines two QueryOptions that the input parameter will have higher
@@ -436,6 +464,7 @@ func (qo QueryOptions) merge(opts QueryOptions) QueryOptions {
 		DataBoostEnabled:            qo.DataBoostEnabled,
 		DirectedReadOptions:         qo.DirectedReadOptions,
 		ExcludeTxnFromChangeStreams: qo.ExcludeTxnFromChangeStreams || opts.ExcludeTxnFromChangeStreams,
+		Timeout:                     qo.Timeout,
 	}
 	if opts.Mode != nil {
 		merged.Mode = opts.Mode
@@ -452,11 +481,76 @@ func (qo QueryOptions) merge(opts QueryOptions) QueryOptions {
 	if opts.DirectedReadOptions != nil {
 		merged.DirectedReadOptions = opts.DirectedReadOptions
 	}
+	if opts.Timeout > 0 {
+		merged.Timeout = opts.Timeout
+	}
 	proto.Merge(merged.Options, qo.Options)
 	proto.Merge(merged.Options, opts.Options)
 	return merged
 }
 
+// withStatementTimeout returns a context with the given statement timeout. The
+// returned context is the given context if the timeout is zero.
+func withStatementTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// cancelOnStop makes ri call cancel when it is stopped, so that the statement
+// timeout applies while the rows are read.
+func cancelOnStop(ri *RowIterator, cancel context.CancelFunc) {
+	if ri.cancel == nil {
+		cancel()
+		return
+	}
+	streamCancel := ri.cancel
+	ri.cancel = func() {
+		streamCancel()
+		cancel()
+	}
+}
+
+// statementTimedOut reports whether ctx, which was derived from parent by
+// withStatementTimeout, exceeded the statement timeout while parent is still
+// active.
+func statementTimedOut(ctx, parent context.Context) bool {
+	return ctx != parent && ctx.Err() == context.DeadlineExceeded && parent.Err() == nil
+}
+
+// inlineBeginFailed handles the failure of a statement that tried to begin a
+// read/write transaction inline, and returns the error for the statement. If
+// the statement exceeded its timeout, the error is returned as a
+// DeadlineExceeded error and the next statement begins the transaction.
+// Otherwise, the transaction is retried with an explicit BeginTransaction.
+func (t *txReadOnly) inlineBeginFailed(ctx, parent context.Context, err error) error {
+	if rw, ok := t.txReadEnv.(*ReadWriteTransaction); ok && statementTimedOut(ctx, parent) {
+		rw.resetInlineBegin()
+		return ToSpannerError(err)
+	}
+	t.setTransactionID(nil)
+	return errInlineBeginTransactionFailed()
+}
+
+// statementRelease returns the function that releases the transaction at the
+// end of a streaming statement that runs with ctx, which was derived from
+// parent by withStatementTimeout. It is like release, except that a statement
+// that began a read/write transaction inline and timed out before receiving
+// the transaction ID does not cause the transaction to be retried.
+func (t *txReadOnly) statementRelease(ctx, parent context.Context) func(error) {
+	rw, ok := t.txReadEnv.(*ReadWriteTransaction)
+	if !ok || ctx == parent {
+		return t.release
+	}
+	return func(err error) {
+		if statementTimedOut(ctx, parent) {
+			rw.resetInlineBegin()
+		}
+		rw.release(err)
+	}
+}
+
 func createRequestOptions(prio sppb.RequestOptions_Priority, requestTag, transactionTag string) (ro *sppb.RequestOptions) {
 	ro = &sppb.RequestOptions{}
 	if prio != sppb.RequestOptions_PRIORITY_UNSPECIFIED {
@@ -484,6 +578,7 @@ func (t *txReadOnly) Query(ctx context.Context, statement Statement) *RowIterato
 		Options:             t.qo.Options,
 		Priority:            t.qo.Priority,
 		DirectedReadOptions: t.qo.DirectedReadOptions,
+		Timeout:             t.qo.Timeout,
 	})
 }
 
@@ -504,6 +599,7 @@ func (t *txReadOnly) QueryWithStats(ctx context.Context, statement Statement) *R
 		Options:             t.qo.Options,
 		Priority:            t.qo.Priority,
 		DirectedReadOptions: t.qo.DirectedReadOptions,
+		Timeout:             t.qo.Timeout,
 	})
 }
 
@@ -515,6 +611,7 @@ func (t *txReadOnly) AnalyzeQuery(ctx context.Context, statement Statement) (*sp
 		Options:             t.qo.Options,
 		Priority:            t.qo.Priority,
 		DirectedReadOptions: t.qo.DirectedReadOptions,
+		Timeout:             t.qo.Timeout,
 	})
 	defer iter.Stop()
 	for {
@@ -535,6 +632,10 @@ func (t *txReadOnly) AnalyzeQuery(ctx context.Context, statement Statement) (*sp
 func (t *txReadOnly) query(ctx context.Context, statement Statement, options QueryOptions) (ri *RowIterator) {
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/spanner.Query")
 	defer func() { trace.EndSpan(ctx, ri.err) }()
+	parent := ctx
+	ctx, cancel := withStatementTimeout(ctx, options.Timeout)
+	stmtCtx := ctx
+	defer func() { cancelOnStop(ri, cancel) }()
 	req, sh, err := t.prepareExecuteSQL(ctx, statement, options)
 	if err != nil {
 		return &RowIterator{err: err}
@@ -558,8 +659,7 @@ func (t *txReadOnly) query(ctx context.Context, statement Statement, options Que
 			client, err := client.ExecuteStreamingSql(ctx, req)
 			if err != nil {
 				if _, ok := req.Transaction.GetSelector().(*sppb.TransactionSelector_Begin); ok {
-					t.setTransactionID(nil)
-					return client, errInlineBeginTransactionFailed()
+					return client, t.inlineBeginFailed(stmtCtx, parent, err)
 				}
 				return client, err
 			}
@@ -577,7 +677,7 @@ func (t *txReadOnly) query(ctx context.Context, statement Statement, options Que
 		t.replaceSessionFunc,
 		setTransactionID,
 		t.setTimestamp,
-		t.release)
+		t.statementRelease(stmtCtx, parent))
 }
 
 func (t *txReadOnly) prepareExecuteSQL(ctx context.Context, stmt Statement, options QueryOptions) (*sppb.ExecuteSqlRequest, *sessionHandle, error) {
@@ -608,7 +708,7 @@ func (t *txReadOnly) prepareExecuteSQL(ctx context.Context, stmt Statement, opti
 		Params:              params,
 		ParamTypes:          paramTypes,
 		QueryOptions:        options.Options,
-		RequestOptions:      createRequestOptions(options.Priority, options.RequestTag, t.txOpts.TransactionTag),
+		RequestOptions:      withContextRequestOptions(ctx, createRequestOptions(options.Priority, options.RequestTag, t.txOpts.TransactionTag)),
 		DataBoostEnabled:    options.DataBoostEnabled,
 		DirectedReadOptions: options.DirectedReadOptions,
 	}
@@ -1127,6 +1227,7 @@ func (t *ReadWriteTransaction) Update(ctx context.Context, stmt Statement) (rowC
 		Mode:     &mode,
 		Options:  t.qo.Options,
 		Priority: t.qo.Priority,
+		Timeout:  t.qo.Timeout,
 	})
 }
 
@@ -1144,6 +1245,9 @@ func (t *ReadWriteTransaction) UpdateWithOptions(ctx context.Context, stmt State
 func (t *ReadWriteTransaction) update(ctx context.Context, stmt Statement, opts QueryOptions) (rowCount int64, err error) {
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/spanner.Update")
 	defer func() { trace.EndSpan(ctx, err) }()
+	parent := ctx
+	ctx, cancel := withStatementTimeout(ctx, opts.Timeout)
+	defer cancel()
 	req, sh, err := t.prepareExecuteSQL(ctx, stmt, opts)
 	if err != nil {
 		return 0, err
@@ -1167,8 +1271,7 @@ func (t *ReadWriteTransaction) update(ctx context.Context, stmt Statement, opts
 	}
 	if err != nil {
 		if hasInlineBeginTransaction {
-			t.setTransactionID(nil)
-			return 0, errInlineBeginTransactionFailed()
+			return 0, t.inlineBeginFailed(ctx, parent, err)
 		}
 		return 0, ToSpannerError(err)
 	}
@@ -1219,6 +1322,9 @@ func (t *ReadWriteTransaction) BatchUpdateWithOptions(ctx context.Context, stmts
 func (t *ReadWriteTransaction) batchUpdateWithOptions(ctx context.Context, stmts []Statement, opts QueryOptions) (_ []int64, err error) {
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/spanner.BatchUpdate")
 	defer func() { trace.EndSpan(ctx, err) }()
+	parent := ctx
+	ctx, cancel := withStatementTimeout(ctx, opts.Timeout)
+	defer cancel()
 
 	sh, ts, err := t.acquire(ctx)
 	if err != nil {
@@ -1263,7 +1369,7 @@ func (t *ReadWriteTransaction) batchUpdateWithOptions(ctx context.Context, stmts
 		Transaction:    ts,
 		Statements:     sppbStmts,
 		Seqno:          atomic.AddInt64(&t.sequenceNumber, 1),
-		RequestOptions: createRequestOptions(opts.Priority, opts.RequestTag, t.txOpts.TransactionTag),
+		RequestOptions: withContextRequestOptions(ctx, createRequestOptions(opts.Priority, opts.RequestTag, t.txOpts.TransactionTag)),
 	}, gax.WithGRPCOptions(grpc.Header(&md)))
 
 	if getGFELatencyMetricsFlag() && md != nil && t.ct != nil {
@@ -1276,8 +1382,7 @@ func (t *ReadWriteTransaction) batchUpdateWithOptions(ctx context.Context, stmts
 	}
 	if err != nil {
 		if hasInlineBeginTransaction {
-			t.setTransactionID(nil)
-			return nil, errInlineBeginTransactionFailed()
+			return nil, t.inlineBeginFailed(ctx, parent, err)
 		}
 		return nil, ToSpannerError(err)
 	}
@@ -1416,6 +1521,23 @@ func (t *ReadWriteTransaction) setTransactionID(tx transactionID) {
 	t.txReadyOrClosed = make(chan struct{})
 }
 
+// resetInlineBegin reverts the transaction to its initial state after the
+// statement that tried to begin it inline timed out, so that the next
+// statement begins the transaction. It does nothing if the transaction has
+// been begun or closed.
+func (t *ReadWriteTransaction) resetInlineBegin() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.state != txInit || t.tx != nil {
+		return
+	}
+	t.state = txNew
+	// Wake up the operations waiting for the transaction ID, so that one of
+	// them begins the transaction.
+	close(t.txReadyOrClosed)
+	t.txReadyOrClosed = make(chan struct{})
+}
+
 // release implements txReadEnv.release.
 func (t *ReadWriteTransaction) release(err error) {
 	t.mu.Lock()
@@ -1761,7 +1883,7 @@ func NewReadWriteStmtBasedTransactionWithOptions(ctx context.Context, c *Client,
 	t.txReadOnly.qo = c.qo
 	t.txReadOnly.ro = c.ro
 	t.txReadOnly.disableRouteToLeader = c.disableRouteToLeader
-	t.txOpts = c.txo.merge(options)
+	t.txOpts = withContextTransactionOptions(ctx, c.txo.merge(options))
 	t.ct = c.ct
 	t.otConfig = c.otConfig
 
@@ -746,3 +746,144 @@ func newAbortedErrorWithMinimalRetryDelay() error {
 	st, _ = st.WithDetails(retry)
 	return st.Err()
 }
+
+func TestReadWriteTransaction_StatementTimeout(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	var attempts int
+	_, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		attempts++
+		if _, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo)); err != nil {
+			return err
+		}
+		server.TestSpanner.PutExecutionTime(MethodExecuteSql, SimulatedExecutionTime{MinimumExecutionTime: 200 * time.Millisecond})
+		server.TestSpanner.PutExecutionTime(MethodExecuteBatchDml, SimulatedExecutionTime{MinimumExecutionTime: 200 * time.Millisecond})
+		_, err := tx.UpdateWithOptions(ctx, NewStatement(UpdateBarSetFoo), QueryOptions{Timeout: 10 * time.Millisecond})
+		if g, w := ErrCode(err), codes.DeadlineExceeded; g != w {
+			t.Errorf("error code mismatch\nGot: %v\nWant: %v", g, w)
+		}
+		_, err = tx.BatchUpdateWithOptions(ctx, []Statement{NewStatement(UpdateBarSetFoo)}, QueryOptions{Timeout: 10 * time.Millisecond})
+		if g, w := ErrCode(err), codes.DeadlineExceeded; g != w {
+			t.Errorf("error code mismatch\nGot: %v\nWant: %v", g, w)
+		}
+		server.TestSpanner.PutExecutionTime(MethodExecuteSql, SimulatedExecutionTime{})
+		server.TestSpanner.PutExecutionTime(MethodExecuteBatchDml, SimulatedExecutionTime{})
+		// The transaction can continue after a statement timed out.
+		_, err = tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := attempts, 1; g != w {
+		t.Fatalf("attempt count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestReadWriteTransaction_FirstStatementTimeout(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	for _, test := range []struct {
+		name   string
+		method string
+		run    func(ctx context.Context, tx *ReadWriteTransaction) error
+	}{
+		{
+			"Update",
+			MethodExecuteSql,
+			func(ctx context.Context, tx *ReadWriteTransaction) error {
+				_, err := tx.UpdateWithOptions(ctx, NewStatement(UpdateBarSetFoo), QueryOptions{Timeout: 10 * time.Millisecond})
+				return err
+			},
+		},
+		{
+			"Query",
+			MethodExecuteStreamingSql,
+			func(ctx context.Context, tx *ReadWriteTransaction) error {
+				iter := tx.QueryWithOptions(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), QueryOptions{Timeout: 10 * time.Millisecond})
+				defer iter.Stop()
+				_, err := iter.Next()
+				return err
+			},
+		},
+		{
+			"Read",
+			MethodStreamingRead,
+			func(ctx context.Context, tx *ReadWriteTransaction) error {
+				iter := tx.ReadWithOptions(ctx, "Albums", AllKeys(), []string{"SingerId", "AlbumId", "AlbumTitle"}, &ReadOptions{Timeout: 10 * time.Millisecond})
+				defer iter.Stop()
+				_, err := iter.Next()
+				return err
+			},
+		},
+	} {
+		var attempts int
+		_, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+			attempts++
+			// The first statement begins the transaction inline. It times out
+			// instead of causing the transaction to be retried.
+			server.TestSpanner.PutExecutionTime(test.method, SimulatedExecutionTime{MinimumExecutionTime: 200 * time.Millisecond})
+			err := test.run(ctx, tx)
+			server.TestSpanner.PutExecutionTime(test.method, SimulatedExecutionTime{})
+			if g, w := ErrCode(err), codes.DeadlineExceeded; g != w {
+				t.Errorf("%s: error code mismatch\nGot: %v\nWant: %v", test.name, g, w)
+			}
+			// The next statement begins the transaction.
+			_, err = tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+			return err
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if g, w := attempts, 1; g != w {
+			t.Errorf("%s: attempt count mismatch\nGot: %v\nWant: %v", test.name, g, w)
+		}
+	}
+}
+
+func TestQueryWithOptions_StatementTimeout(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	server.TestSpanner.PutExecutionTime(MethodExecuteStreamingSql, SimulatedExecutionTime{MinimumExecutionTime: 200 * time.Millisecond})
+	iter := client.Single().QueryWithOptions(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), QueryOptions{Timeout: 10 * time.Millisecond})
+	defer iter.Stop()
+	_, err := iter.Next()
+	if g, w := ErrCode(err), codes.DeadlineExceeded; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestReadWithOptions_StatementTimeout(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	server.TestSpanner.PutExecutionTime(MethodStreamingRead, SimulatedExecutionTime{MinimumExecutionTime: 200 * time.Millisecond})
+	iter := client.Single().ReadWithOptions(ctx, "Albums", AllKeys(), []string{"SingerId"}, &ReadOptions{Timeout: 10 * time.Millisecond})
+	defer iter.Stop()
+	_, err := iter.Next()
+	if g, w := ErrCode(err), codes.DeadlineExceeded; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestQueryOptions_MergeTimeout(t *testing.T) {
+	t.Parallel()
+	base := QueryOptions{Timeout: time.Second}
+	if g, w := base.merge(QueryOptions{}).Timeout, time.Second; g != w {
+		t.Errorf("timeout mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := base.merge(QueryOptions{Timeout: time.Millisecond}).Timeout, time.Millisecond; g != w {
+		t.Errorf("timeout mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
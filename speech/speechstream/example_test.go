@@ -0,0 +1,56 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speechstream_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	speech "cloud.google.com/go/speech/apiv1"
+	"cloud.google.com/go/speech/apiv1/speechpb"
+	"cloud.google.com/go/speech/speechstream"
+)
+
+func ExampleRecognize() {
+	ctx := context.Background()
+	client, err := speech.NewClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	// Raw 16-bit audio at 16kHz, for example from a microphone.
+	audio := os.Stdin
+	results := speechstream.Recognize(ctx, client, audio, &speechstream.Config{
+		Streaming: &speechpb.StreamingRecognitionConfig{
+			Config: &speechpb.RecognitionConfig{
+				Encoding:        speechpb.RecognitionConfig_LINEAR16,
+				SampleRateHertz: 16000,
+				LanguageCode:    "en-US",
+			},
+			InterimResults: true,
+		},
+	})
+	for r := range results {
+		if r.Err != nil {
+			// TODO: Handle error.
+			break
+		}
+		if r.Result.IsFinal && len(r.Result.Alternatives) > 0 {
+			fmt.Printf("%v: %s\n", r.Result.ResultEndTime.AsDuration(), r.Result.Alternatives[0].Transcript)
+		}
+	}
+}
@@ -0,0 +1,442 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package speechstream recognizes speech from audio of any length read from
+// an io.Reader, such as a file or a microphone, with the StreamingRecognize
+// method of the client in cloud.google.com/go/speech/apiv1.
+//
+// Recognize sends the audio in requests within the size limit of the service,
+// and delivers the results on a channel as they arrive:
+//
+//	results := speechstream.Recognize(ctx, client, mic, &speechstream.Config{
+//		Streaming: &speechpb.StreamingRecognitionConfig{
+//			Config: &speechpb.RecognitionConfig{
+//				Encoding:        speechpb.RecognitionConfig_LINEAR16,
+//				SampleRateHertz: 16000,
+//				LanguageCode:    "en-US",
+//			},
+//			InterimResults: true,
+//		},
+//	})
+//	for r := range results {
+//		if r.Err != nil {
+//			// TODO: Handle error.
+//		}
+//		// TODO: Use r.Result.
+//	}
+//
+// A stream of the service lasts at most about five minutes. For the
+// uncompressed LINEAR16 and MULAW encodings, Recognize restarts the stream
+// before the limit, and when it fails with a transient error, resending the
+// audio that has no final result yet, so that recognition continues without
+// gaps. The times in the results are relative to the beginning of the audio
+// across restarts. Audio in other encodings is recognized in a single stream.
+package speechstream // import "cloud.google.com/go/speech/speechstream"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	speech "cloud.google.com/go/speech/apiv1"
+	"cloud.google.com/go/speech/apiv1/speechpb"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	// MaxChunkSize is the maximum size of the audio sent in one request.
+	MaxChunkSize = 25 * 1024
+
+	// defaultStreamLimit leaves a margin below the service limit of five
+	// minutes per stream.
+	defaultStreamLimit = 290 * time.Second
+
+	// maxRestarts is the number of restarts after transient errors, without
+	// a final result in between, after which Recognize gives up.
+	maxRestarts = 3
+)
+
+// Config configures Recognize.
+type Config struct {
+	// Streaming is the configuration of the streams. Its Config field must
+	// describe the encoding and sample rate of the audio. Required.
+	Streaming *speechpb.StreamingRecognitionConfig
+
+	// ChunkSize is the maximum size of the audio sent in one request. Each
+	// read from the audio source is sent as soon as it returns, so small
+	// reads give results with a lower latency. The default and maximum is
+	// MaxChunkSize.
+	ChunkSize int
+
+	// StreamLimit is the duration after which a stream is restarted, for the
+	// encodings that allow it. The default is 290 seconds.
+	StreamLimit time.Duration
+}
+
+// Result is a result of Recognize. Exactly one of its fields is set.
+type Result struct {
+	// Result is an interim or final result, whose times are relative to the
+	// beginning of the audio.
+	Result *speechpb.StreamingRecognitionResult
+
+	// Err is the error that ended recognition. It is delivered last.
+	Err error
+}
+
+// Recognize recognizes the speech in the audio read from r, and returns a
+// channel on which it delivers the results. The channel is closed once all
+// the audio has been recognized, after a Result with an error, or when ctx is
+// done. The caller must receive from the channel until it is closed, or
+// cancel ctx.
+func Recognize(ctx context.Context, client *speech.Client, r io.Reader, cfg *Config) <-chan Result {
+	return recognize(ctx, client, r, cfg)
+}
+
+// streamingClient is implemented by *speech.Client.
+type streamingClient interface {
+	StreamingRecognize(ctx context.Context, opts ...gax.CallOption) (speechpb.Speech_StreamingRecognizeClient, error)
+}
+
+func recognize(ctx context.Context, client streamingClient, r io.Reader, cfg *Config) <-chan Result {
+	out := make(chan Result)
+	go func() {
+		defer close(out)
+		rc, err := newRecognizer(client, cfg)
+		if err == nil {
+			rc.out = out
+			err = rc.run(ctx, r)
+		}
+		if err != nil && ctx.Err() == nil {
+			select {
+			case out <- Result{Err: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return out
+}
+
+type recognizer struct {
+	client      streamingClient
+	streaming   *speechpb.StreamingRecognitionConfig
+	chunkSize   int
+	streamLimit time.Duration
+	// bytesPerSecond is the rate of the audio, or zero if the encoding
+	// doesn't allow restarts.
+	bytesPerSecond int64
+	// frameSize is the size of a sample in all channels.
+	frameSize int64
+	out       chan<- Result
+
+	mu sync.Mutex
+	// pending holds the audio sent from the offset base on which there is no
+	// final result yet. It is only kept when restarts are possible.
+	pending []byte
+	base    int64
+	// eof is set once the audio source is exhausted.
+	eof bool
+
+	// failures counts the streams that failed since the last final result.
+	failures int
+}
+
+func newRecognizer(client streamingClient, cfg *Config) (*recognizer, error) {
+	rc := &recognizer{
+		client:      client,
+		streaming:   cfg.Streaming,
+		chunkSize:   cfg.ChunkSize,
+		streamLimit: cfg.StreamLimit,
+	}
+	if rc.streaming.GetConfig() == nil {
+		return nil, errors.New("speechstream: Config.Streaming.Config is required")
+	}
+	if rc.chunkSize == 0 {
+		rc.chunkSize = MaxChunkSize
+	}
+	if rc.chunkSize < 0 || rc.chunkSize > MaxChunkSize {
+		return nil, fmt.Errorf("speechstream: chunk size %d is not between 1 and %d", rc.chunkSize, MaxChunkSize)
+	}
+	if rc.streamLimit <= 0 {
+		rc.streamLimit = defaultStreamLimit
+	}
+	c := rc.streaming.Config
+	var sampleSize int64
+	switch c.Encoding {
+	case speechpb.RecognitionConfig_LINEAR16:
+		sampleSize = 2
+	case speechpb.RecognitionConfig_MULAW:
+		sampleSize = 1
+	}
+	channels := int64(c.AudioChannelCount)
+	if channels == 0 {
+		channels = 1
+	}
+	// A single utterance stream ends by itself and is never restarted.
+	if sampleSize > 0 && c.SampleRateHertz > 0 && !rc.streaming.SingleUtterance {
+		rc.frameSize = sampleSize * channels
+		rc.bytesPerSecond = rc.frameSize * int64(c.SampleRateHertz)
+	}
+	return rc, nil
+}
+
+func (rc *recognizer) restartable() bool { return rc.bytesPerSecond > 0 }
+
+func (rc *recognizer) run(ctx context.Context, r io.Reader) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	audio := make(chan []byte)
+	readErr := make(chan error, 1)
+	go rc.read(ctx, r, audio, readErr)
+
+	for {
+		restart, err := rc.stream(ctx, audio)
+		if err != nil {
+			return err
+		}
+		select {
+		case err := <-readErr:
+			return fmt.Errorf("speechstream: reading audio: %w", err)
+		default:
+		}
+		if !restart {
+			return nil
+		}
+		if rc.failures > maxRestarts {
+			return errors.New("speechstream: too many stream restarts without results")
+		}
+	}
+}
+
+// read sends the audio read from r on audio, which it closes at the end.
+func (rc *recognizer) read(ctx context.Context, r io.Reader, audio chan<- []byte, readErr chan<- error) {
+	defer close(audio)
+	for {
+		buf := make([]byte, rc.chunkSize)
+		n, err := r.Read(buf)
+		if n > 0 {
+			select {
+			case audio <- buf[:n]:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			readErr <- err
+			return
+		}
+	}
+}
+
+// stream runs one stream. It reports whether the recognition must continue in
+// a new stream.
+func (rc *recognizer) stream(ctx context.Context, audio <-chan []byte) (restart bool, err error) {
+	sctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s, err := rc.client.StreamingRecognize(sctx)
+	if err != nil {
+		return false, err
+	}
+	if err := s.Send(&speechpb.StreamingRecognizeRequest{
+		StreamingRequest: &speechpb.StreamingRecognizeRequest_StreamingConfig{StreamingConfig: rc.streaming},
+	}); err != nil {
+		return rc.failed(sctx, s, err)
+	}
+
+	// The stream starts with the audio that has no final result yet.
+	rc.mu.Lock()
+	start := rc.base
+	replay := append([]byte(nil), rc.pending...)
+	eof := rc.eof
+	rc.mu.Unlock()
+	offset := rc.duration(start)
+
+	// limited is closed if the sender ends the stream because of its limit.
+	limited := make(chan struct{})
+	sendErr := make(chan error, 1)
+	go func() {
+		sendErr <- rc.send(sctx, s, replay, eof, audio, limited)
+	}()
+
+	for {
+		res, err := s.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			cancel()
+			<-sendErr
+			return rc.failed(ctx, nil, err)
+		}
+		if res.Error != nil {
+			cancel()
+			<-sendErr
+			return rc.failed(ctx, nil, status.ErrorProto(res.Error))
+		}
+		for _, result := range res.Results {
+			shift(result, offset)
+			if result.IsFinal {
+				rc.failures = 0
+				rc.finalize(start, result.ResultEndTime.AsDuration()-offset)
+			}
+			select {
+			case rc.out <- Result{Result: result}:
+			case <-ctx.Done():
+				return false, ctx.Err()
+			}
+		}
+	}
+	// The service ended the stream, either after CloseSend or by itself for
+	// a single utterance.
+	cancel()
+	if err := <-sendErr; err != nil && sctx.Err() == nil {
+		return false, err
+	}
+	select {
+	case <-limited:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// failed returns what stream returns after err: a restart if err is transient
+// and the stream can be restarted, err otherwise.
+func (rc *recognizer) failed(ctx context.Context, s speechpb.Speech_StreamingRecognizeClient, err error) (bool, error) {
+	if s != nil {
+		// Get the status of the stream, as Send only returns io.EOF.
+		for err == io.EOF {
+			_, err = s.Recv()
+		}
+	}
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	switch status.Code(err) {
+	case codes.OutOfRange, codes.Unavailable, codes.Aborted, codes.DeadlineExceeded:
+		if rc.restartable() {
+			rc.failures++
+			return true, nil
+		}
+	}
+	return false, err
+}
+
+// send sends replay and then the audio, until the audio is exhausted or the
+// stream reaches its limit. It closes limited in the latter case.
+func (rc *recognizer) send(ctx context.Context, s speechpb.Speech_StreamingRecognizeClient, replay []byte, eof bool, audio <-chan []byte, limited chan<- struct{}) error {
+	sendAudio := func(b []byte) error {
+		return s.Send(&speechpb.StreamingRecognizeRequest{
+			StreamingRequest: &speechpb.StreamingRecognizeRequest_AudioContent{AudioContent: b},
+		})
+	}
+	for len(replay) > 0 {
+		n := len(replay)
+		if n > rc.chunkSize {
+			n = rc.chunkSize
+		}
+		if err := sendAudio(replay[:n]); err != nil {
+			return err
+		}
+		replay = replay[n:]
+	}
+	if eof {
+		return s.CloseSend()
+	}
+	var limit <-chan time.Time
+	if rc.restartable() {
+		t := time.NewTimer(rc.streamLimit)
+		defer t.Stop()
+		limit = t.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-limit:
+			close(limited)
+			return s.CloseSend()
+		case b, ok := <-audio:
+			if !ok {
+				rc.mu.Lock()
+				rc.eof = true
+				rc.mu.Unlock()
+				return s.CloseSend()
+			}
+			if rc.restartable() {
+				rc.mu.Lock()
+				rc.pending = append(rc.pending, b...)
+				rc.mu.Unlock()
+			}
+			if err := sendAudio(b); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// finalize drops the pending audio up to end, the end of a final result in
+// the stream that started at the offset start.
+func (rc *recognizer) finalize(start int64, end time.Duration) {
+	if !rc.restartable() {
+		return
+	}
+	off := start + int64(end.Seconds()*float64(rc.bytesPerSecond))
+	off -= off % rc.frameSize
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if n := off - rc.base; n > 0 {
+		if n > int64(len(rc.pending)) {
+			n = int64(len(rc.pending))
+		}
+		rc.pending = append([]byte(nil), rc.pending[n:]...)
+		rc.base += n
+	}
+}
+
+// duration returns the duration of the audio up to the offset off.
+func (rc *recognizer) duration(off int64) time.Duration {
+	if off == 0 {
+		return 0
+	}
+	return time.Duration(float64(off) / float64(rc.bytesPerSecond) * float64(time.Second))
+}
+
+// shift adds offset to the times of result.
+func shift(result *speechpb.StreamingRecognitionResult, offset time.Duration) {
+	if offset == 0 {
+		return
+	}
+	add := func(d *durationpb.Duration) *durationpb.Duration {
+		if d == nil {
+			return nil
+		}
+		return durationpb.New(d.AsDuration() + offset)
+	}
+	result.ResultEndTime = add(result.ResultEndTime)
+	for _, alt := range result.Alternatives {
+		for _, w := range alt.Words {
+			w.StartTime = add(w.StartTime)
+			w.EndTime = add(w.EndTime)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speechstream
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	speech "cloud.google.com/go/speech/apiv1"
+	"cloud.google.com/go/speech/apiv1/speechpb"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// The test audio is LINEAR16 at 8kHz.
+const bytesPerSecond = 16000
+
+var linear16 = &speechpb.StreamingRecognitionConfig{
+	Config: &speechpb.RecognitionConfig{
+		Encoding:        speechpb.RecognitionConfig_LINEAR16,
+		SampleRateHertz: 8000,
+		LanguageCode:    "en-US",
+	},
+}
+
+// fakeServer answers each stream with a final result at the end of the audio
+// it received. If failAfter is positive, the first stream fails after
+// receiving that much audio, with a final result at that point.
+type fakeServer struct {
+	speechpb.UnimplementedSpeechServer
+	failAfter int
+
+	mu       sync.Mutex
+	received []int // the audio received by each stream
+}
+
+func (s *fakeServer) StreamingRecognize(stream speechpb.Speech_StreamingRecognizeServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if req.GetStreamingConfig() == nil {
+		return status.Error(codes.InvalidArgument, "no config")
+	}
+	s.mu.Lock()
+	n := len(s.received)
+	s.received = append(s.received, 0)
+	s.mu.Unlock()
+	size := 0
+	final := func() error {
+		return stream.Send(&speechpb.StreamingRecognizeResponse{
+			Results: []*speechpb.StreamingRecognitionResult{{
+				IsFinal:       true,
+				ResultEndTime: durationpb.New(time.Duration(size) * time.Second / bytesPerSecond),
+				Alternatives:  []*speechpb.SpeechRecognitionAlternative{{Transcript: "hello"}},
+			}},
+		})
+	}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(req.GetAudioContent()) > MaxChunkSize {
+			return status.Error(codes.InvalidArgument, "chunk too large")
+		}
+		size += len(req.GetAudioContent())
+		s.mu.Lock()
+		s.received[n] = size
+		s.mu.Unlock()
+		if n == 0 && s.failAfter > 0 && size >= s.failAfter {
+			size = s.failAfter
+			if err := final(); err != nil {
+				return err
+			}
+			return status.Error(codes.OutOfRange, "stream too long")
+		}
+	}
+	if size == 0 {
+		return nil
+	}
+	return final()
+}
+
+func newClient(t *testing.T, srv speechpb.SpeechServer) *speech.Client {
+	t.Helper()
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gsrv := grpc.NewServer()
+	speechpb.RegisterSpeechServer(gsrv, srv)
+	go gsrv.Serve(lis)
+	t.Cleanup(gsrv.Stop)
+	client, err := speech.NewClient(context.Background(),
+		option.WithEndpoint(lis.Addr().String()),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func endTimes(t *testing.T, results <-chan Result) []time.Duration {
+	t.Helper()
+	var ends []time.Duration
+	for r := range results {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		ends = append(ends, r.Result.ResultEndTime.AsDuration())
+	}
+	return ends
+}
+
+func TestRecognizeRestartsAfterError(t *testing.T) {
+	srv := &fakeServer{failAfter: bytesPerSecond}
+	client := newClient(t, srv)
+	audio := make([]byte, 3*bytesPerSecond)
+	ends := endTimes(t, Recognize(context.Background(), client, bytes.NewReader(audio), &Config{Streaming: linear16, ChunkSize: 4000}))
+
+	if want := []time.Duration{time.Second, 3 * time.Second}; !equal(ends, want) {
+		t.Errorf("got result end times %v, want %v", ends, want)
+	}
+	// The second stream starts at the end of the final result of the first.
+	if got := srv.received[1]; got != 2*bytesPerSecond {
+		t.Errorf("second stream received %d bytes, want %d", got, 2*bytesPerSecond)
+	}
+}
+
+func TestRecognizeRestartsAtLimit(t *testing.T) {
+	srv := &fakeServer{}
+	client := newClient(t, srv)
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write(make([]byte, bytesPerSecond))
+		time.Sleep(300 * time.Millisecond)
+		pw.Write(make([]byte, bytesPerSecond))
+		pw.Close()
+	}()
+	cfg := &Config{Streaming: linear16, StreamLimit: 100 * time.Millisecond}
+	ends := endTimes(t, Recognize(context.Background(), client, pr, cfg))
+	if want := []time.Duration{time.Second, 2 * time.Second}; !equal(ends, want) {
+		t.Errorf("got result end times %v, want %v", ends, want)
+	}
+}
+
+func TestRecognizeWithoutRestarts(t *testing.T) {
+	// FLAC streams can't be restarted, so the error is returned.
+	srv := &fakeServer{failAfter: 100}
+	client := newClient(t, srv)
+	flac := &speechpb.StreamingRecognitionConfig{Config: &speechpb.RecognitionConfig{
+		Encoding:     speechpb.RecognitionConfig_FLAC,
+		LanguageCode: "en-US",
+	}}
+	var err error
+	for r := range Recognize(context.Background(), client, bytes.NewReader(make([]byte, 1000)), &Config{Streaming: flac}) {
+		if r.Err != nil {
+			err = r.Err
+		}
+	}
+	if status.Code(err) != codes.OutOfRange {
+		t.Errorf("got %v, want OutOfRange", err)
+	}
+
+	for r := range Recognize(context.Background(), client, bytes.NewReader(nil), &Config{Streaming: linear16, ChunkSize: MaxChunkSize + 1}) {
+		if r.Err == nil {
+			t.Error("got a result, want an error for the chunk size")
+		}
+	}
+}
+
+func equal(a, b []time.Duration) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ttsstream_test
+
+import (
+	"context"
+	"os"
+
+	texttospeech "cloud.google.com/go/texttospeech/apiv1"
+	"cloud.google.com/go/texttospeech/apiv1/texttospeechpb"
+	"cloud.google.com/go/texttospeech/ttsstream"
+)
+
+func ExampleSynthesize() {
+	ctx := context.Background()
+	client, err := texttospeech.NewClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	book, err := os.ReadFile("book.txt")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	// Write the audio to stdout, for example to pipe it to a player.
+	err = ttsstream.Synthesize(ctx, client, os.Stdout, string(book), &ttsstream.Config{
+		Voice: &texttospeechpb.VoiceSelectionParams{LanguageCode: "en-US"},
+		Audio: &texttospeechpb.AudioConfig{AudioEncoding: texttospeechpb.AudioEncoding_OGG_OPUS},
+	})
+	if err != nil {
+		// TODO: Handle error.
+	}
+}
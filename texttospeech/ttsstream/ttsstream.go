@@ -0,0 +1,224 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ttsstream synthesizes text of any length with the client in
+// cloud.google.com/go/texttospeech/apiv1, and writes the audio to an
+// io.Writer as it is synthesized, so that it can be piped to a player.
+//
+// Synthesize splits the text at sentence boundaries into requests within the
+// size limit of the service, and joins the audio of the responses into a
+// single file:
+//
+//	err := ttsstream.Synthesize(ctx, client, w, longText, &ttsstream.Config{
+//		Voice: &texttospeechpb.VoiceSelectionParams{LanguageCode: "en-US"},
+//		Audio: &texttospeechpb.AudioConfig{AudioEncoding: texttospeechpb.AudioEncoding_OGG_OPUS},
+//	})
+//
+// An AudioWriter does the joining for callers that make the requests
+// themselves.
+package ttsstream // import "cloud.google.com/go/texttospeech/ttsstream"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	texttospeech "cloud.google.com/go/texttospeech/apiv1"
+	"cloud.google.com/go/texttospeech/apiv1/texttospeechpb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+const (
+	// MaxRequestBytes is the maximum size of the text of a request.
+	MaxRequestBytes = 5000
+
+	defaultConcurrency = 3
+)
+
+// Config configures Synthesize.
+type Config struct {
+	// Voice is the voice of the speech. Required.
+	Voice *texttospeechpb.VoiceSelectionParams
+
+	// Audio is the configuration of the audio. Its AudioEncoding is required.
+	Audio *texttospeechpb.AudioConfig
+
+	// MaxBytes is the maximum size of the text of a request. The default and
+	// maximum is MaxRequestBytes.
+	MaxBytes int
+
+	// Concurrency is the maximum number of requests in flight. The audio of a
+	// request is written as soon as the audio before it is. The default is 3.
+	Concurrency int
+}
+
+// synthesizer is implemented by *texttospeech.Client.
+type synthesizer interface {
+	SynthesizeSpeech(ctx context.Context, req *texttospeechpb.SynthesizeSpeechRequest, opts ...gax.CallOption) (*texttospeechpb.SynthesizeSpeechResponse, error)
+}
+
+// Synthesize synthesizes text, which is plain text rather than SSML, and
+// writes the audio to w as a single file in the encoding of cfg.Audio. The
+// text is split with SplitSentences.
+func Synthesize(ctx context.Context, client *texttospeech.Client, w io.Writer, text string, cfg *Config) error {
+	return synthesize(ctx, client, w, text, cfg)
+}
+
+func synthesize(ctx context.Context, client synthesizer, w io.Writer, text string, cfg *Config) error {
+	if cfg.Voice == nil || cfg.Audio == nil {
+		return errors.New("ttsstream: Config.Voice and Config.Audio are required")
+	}
+	maxBytes := cfg.MaxBytes
+	if maxBytes == 0 {
+		maxBytes = MaxRequestBytes
+	}
+	if maxBytes < 0 || maxBytes > MaxRequestBytes {
+		return fmt.Errorf("ttsstream: MaxBytes %d is not between 1 and %d", maxBytes, MaxRequestBytes)
+	}
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	aw, err := NewAudioWriter(w, cfg.Audio.AudioEncoding)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		audio []byte
+		err   error
+	}
+	// Each part has a channel for its result, and the channels are queued in
+	// order, so that at most concurrency parts are synthesized ahead of the
+	// one being written.
+	queue := make(chan chan result, concurrency-1)
+	go func() {
+		defer close(queue)
+		for _, part := range SplitSentences(text, maxBytes) {
+			c := make(chan result, 1)
+			select {
+			case queue <- c:
+			case <-ctx.Done():
+				return
+			}
+			go func(part string) {
+				res, err := client.SynthesizeSpeech(ctx, &texttospeechpb.SynthesizeSpeechRequest{
+					Input:       &texttospeechpb.SynthesisInput{InputSource: &texttospeechpb.SynthesisInput_Text{Text: part}},
+					Voice:       cfg.Voice,
+					AudioConfig: cfg.Audio,
+				})
+				c <- result{res.GetAudioContent(), err}
+			}(part)
+		}
+	}()
+	for c := range queue {
+		r := <-c
+		if r.err != nil {
+			return r.err
+		}
+		if err := aw.Add(r.audio); err != nil {
+			return err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return aw.Close()
+}
+
+// SplitSentences splits text into parts of at most maxBytes bytes, each made
+// of whole sentences where possible. A sentence ends with a period, a
+// question mark or an exclamation mark followed by a space, or with a line
+// break. Sentences longer than maxBytes are split between words, or between
+// characters if a word is longer than maxBytes. Joining the parts gives the
+// text back.
+func SplitSentences(text string, maxBytes int) []string {
+	var parts []string
+	var cur strings.Builder
+	for _, s := range sentences(text) {
+		if cur.Len() > 0 && cur.Len()+len(s) > maxBytes {
+			parts = append(parts, cur.String())
+			cur.Reset()
+		}
+		for len(s) > maxBytes {
+			i := splitPoint(s, maxBytes)
+			parts = append(parts, s[:i])
+			s = s[i:]
+		}
+		cur.WriteString(s)
+	}
+	if strings.TrimSpace(cur.String()) != "" {
+		parts = append(parts, cur.String())
+	} else if len(parts) > 0 {
+		// Keep trailing space with the last part.
+		parts[len(parts)-1] += cur.String()
+	}
+	return parts
+}
+
+// sentences splits text after the end of each sentence, including the spaces
+// that follow it.
+func sentences(text string) []string {
+	var ss []string
+	start := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+		end := r == '\n'
+		if strings.ContainsRune(".!?。！？", r) {
+			next, _ := utf8.DecodeRuneInString(text[i:])
+			end = i == len(text) || unicode.IsSpace(next) || r > unicode.MaxASCII
+		}
+		if !end {
+			continue
+		}
+		for i < len(text) {
+			r, size := utf8.DecodeRuneInString(text[i:])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			i += size
+		}
+		ss = append(ss, text[start:i])
+		start = i
+	}
+	if start < len(text) {
+		ss = append(ss, text[start:])
+	}
+	return ss
+}
+
+// splitPoint returns where to split s, which is longer than max: after the
+// last space before max, or at the last rune boundary before it.
+func splitPoint(s string, max int) int {
+	if i := strings.LastIndexFunc(s[:max], unicode.IsSpace); i > 0 {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		return i + size
+	}
+	i := max
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	if i == 0 {
+		// max is smaller than the first rune.
+		_, i = utf8.DecodeRuneInString(s)
+	}
+	return i
+}
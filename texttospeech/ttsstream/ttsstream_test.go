@@ -0,0 +1,238 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ttsstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/texttospeech/apiv1/texttospeechpb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+func TestSplitSentences(t *testing.T) {
+	for _, test := range []struct {
+		text string
+		max  int
+		want []string
+	}{
+		{"One. Two! Three?", 100, []string{"One. Two! Three?"}},
+		{"One. Two! Three?", 9, []string{"One. ", "Two! ", "Three?"}},
+		{"One. Two. Three.", 11, []string{"One. Two. ", "Three."}},
+		{"Line one\nLine two", 10, []string{"Line one\n", "Line two"}},
+		{"v1.2 is out. Yes.", 14, []string{"v1.2 is out. ", "Yes."}},
+		{"a very long sentence", 8, []string{"a very ", "long ", "sentence"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"日本語。です。", 12, []string{"日本語。", "です。"}},
+		{"日本語", 4, []string{"日", "本", "語"}},
+		{"", 10, nil},
+	} {
+		got := SplitSentences(test.text, test.max)
+		if !equal(got, test.want) {
+			t.Errorf("SplitSentences(%q, %d) = %q, want %q", test.text, test.max, got, test.want)
+		}
+		if strings.Join(got, "") != test.text {
+			t.Errorf("SplitSentences(%q, %d): parts don't join to the text", test.text, test.max)
+		}
+		for _, p := range got {
+			if len(p) > test.max {
+				t.Errorf("SplitSentences(%q, %d): part %q is too long", test.text, test.max, p)
+			}
+		}
+	}
+}
+
+// fakeSynthesizer returns a WAV file whose samples are the bytes of the text.
+type fakeSynthesizer struct {
+	mu       sync.Mutex
+	inFlight int
+	max      int
+	fail     string
+}
+
+func (f *fakeSynthesizer) SynthesizeSpeech(ctx context.Context, req *texttospeechpb.SynthesizeSpeechRequest, _ ...gax.CallOption) (*texttospeechpb.SynthesizeSpeechResponse, error) {
+	f.mu.Lock()
+	f.inFlight++
+	if f.inFlight > f.max {
+		f.max = f.inFlight
+	}
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+	// Make the responses arrive out of order.
+	time.Sleep(time.Duration(rand.Intn(5)) * time.Millisecond)
+	text := req.Input.GetText()
+	if f.fail != "" && strings.Contains(text, f.fail) {
+		return nil, errors.New("synthesis failed")
+	}
+	return &texttospeechpb.SynthesizeSpeechResponse{AudioContent: makeWAV([]byte(text))}, nil
+}
+
+func TestSynthesize(t *testing.T) {
+	ctx := context.Background()
+	var text strings.Builder
+	for i := 0; i < 50; i++ {
+		text.WriteString("This is a sentence. ")
+	}
+	cfg := &Config{
+		Voice:       &texttospeechpb.VoiceSelectionParams{LanguageCode: "en-US"},
+		Audio:       &texttospeechpb.AudioConfig{AudioEncoding: texttospeechpb.AudioEncoding_LINEAR16},
+		MaxBytes:    45,
+		Concurrency: 4,
+	}
+	f := &fakeSynthesizer{}
+	var buf bytes.Buffer
+	if err := synthesize(ctx, f, &buf, text.String(), cfg); err != nil {
+		t.Fatal(err)
+	}
+	_, data, err := parseWAV(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != text.String() {
+		t.Errorf("got audio %q, want the text in order", data)
+	}
+	if f.max > 4 {
+		t.Errorf("got %d requests in flight, want at most 4", f.max)
+	}
+
+	f = &fakeSynthesizer{fail: "sentence"}
+	if err := synthesize(ctx, f, &buf, text.String(), cfg); err == nil {
+		t.Error("got nil, want error")
+	}
+}
+
+func makeWAV(data []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(36+len(data)))
+	b.WriteString("WAVEfmt ")
+	for _, v := range []interface{}{uint32(16), uint16(1), uint16(1), uint32(24000), uint32(48000), uint16(2), uint16(16)} {
+		binary.Write(&b, binary.LittleEndian, v)
+	}
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(len(data)))
+	b.Write(data)
+	return b.Bytes()
+}
+
+func TestAudioWriterWAV(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out.wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	aw, err := NewAudioWriter(f, texttospeechpb.AudioEncoding_LINEAR16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"abcd", "efgh"} {
+		if err := aw.Add(makeWAV([]byte(s))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := aw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The sizes are set, as the file is an io.WriteSeeker.
+	if want := makeWAV([]byte("abcdefgh")); !bytes.Equal(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+
+	aw, _ = NewAudioWriter(&bytes.Buffer{}, texttospeechpb.AudioEncoding_LINEAR16)
+	if err := aw.Add([]byte("not a wav")); err == nil {
+		t.Error("got nil, want error")
+	}
+}
+
+// makeOggPage returns an Ogg page with one packet.
+func makeOggPage(flags byte, granule int64, serial, seq uint32, packet string) []byte {
+	p := []byte("OggS\x00")
+	p = append(p, flags)
+	p = binary.LittleEndian.AppendUint64(p, uint64(granule))
+	p = binary.LittleEndian.AppendUint32(p, serial)
+	p = binary.LittleEndian.AppendUint32(p, seq)
+	p = append(p, 0, 0, 0, 0, 1, byte(len(packet)))
+	p = append(p, packet...)
+	setOggCRC(p)
+	return p
+}
+
+// makeOgg returns an Ogg Opus stream with the given audio packets, each on a
+// page whose granule position is 100 more than the previous one.
+func makeOgg(serial uint32, packets ...string) []byte {
+	b := makeOggPage(oggBOS, 0, serial, 0, "OpusHead")
+	b = append(b, makeOggPage(0, 0, serial, 1, "OpusTags")...)
+	for i, p := range packets {
+		var flags byte
+		if i == len(packets)-1 {
+			flags = oggEOS
+		}
+		b = append(b, makeOggPage(flags, int64(100*(i+1)), serial, uint32(2+i), p)...)
+	}
+	return b
+}
+
+func TestAudioWriterOgg(t *testing.T) {
+	var buf bytes.Buffer
+	aw, err := NewAudioWriter(&buf, texttospeechpb.AudioEncoding_OGG_OPUS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := aw.Add(makeOgg(7, "a1", "a2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := aw.Add(makeOgg(9, "b1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := aw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := makeOggPage(oggBOS, 0, 7, 0, "OpusHead")
+	want = append(want, makeOggPage(0, 0, 7, 1, "OpusTags")...)
+	want = append(want, makeOggPage(0, 100, 7, 2, "a1")...)
+	want = append(want, makeOggPage(0, 200, 7, 3, "a2")...)
+	want = append(want, makeOggPage(oggEOS, 300, 7, 4, "b1")...)
+	if got := buf.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,291 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ttsstream
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/texttospeech/apiv1/texttospeechpb"
+)
+
+// An AudioWriter joins the audio content of several SynthesizeSpeech
+// responses into a single audio file, which it writes as the content is
+// added.
+//
+// LINEAR16, MULAW and ALAW content is written as one WAV file. As its length
+// is not known in advance, the sizes in the WAV header are set to their
+// maximum, which players read as a stream, and are set to the actual sizes by
+// Close if the underlying writer is an io.WriteSeeker. OGG_OPUS content is
+// written as one Ogg stream. MP3 content is written as is.
+type AudioWriter struct {
+	w        io.Writer
+	encoding texttospeechpb.AudioEncoding
+	n        int // the number of contents added
+
+	// WAV
+	format   []byte // the fmt chunk of the first content
+	dataSize int64
+
+	// Ogg
+	serial   uint32
+	seq      uint32
+	granule  int64  // the granule position at the end of the previous contents
+	lastPage []byte // the last page, written by Close with the EOS flag
+	err      error
+}
+
+// NewAudioWriter returns an AudioWriter that writes audio in the given
+// encoding to w.
+func NewAudioWriter(w io.Writer, encoding texttospeechpb.AudioEncoding) (*AudioWriter, error) {
+	switch encoding {
+	case texttospeechpb.AudioEncoding_LINEAR16, texttospeechpb.AudioEncoding_MULAW, texttospeechpb.AudioEncoding_ALAW,
+		texttospeechpb.AudioEncoding_OGG_OPUS, texttospeechpb.AudioEncoding_MP3:
+		return &AudioWriter{w: w, encoding: encoding}, nil
+	default:
+		return nil, fmt.Errorf("ttsstream: unsupported audio encoding %v", encoding)
+	}
+}
+
+// Add writes the audio content of a SynthesizeSpeech response, which must be
+// in the encoding of the AudioWriter.
+func (aw *AudioWriter) Add(content []byte) error {
+	if aw.err != nil {
+		return aw.err
+	}
+	var err error
+	switch aw.encoding {
+	case texttospeechpb.AudioEncoding_OGG_OPUS:
+		err = aw.addOgg(content)
+	case texttospeechpb.AudioEncoding_MP3:
+		_, err = aw.w.Write(content)
+	default:
+		err = aw.addWAV(content)
+	}
+	aw.n++
+	if err != nil {
+		aw.err = err
+	}
+	return err
+}
+
+// Close finishes the audio file. It doesn't close the underlying writer.
+func (aw *AudioWriter) Close() error {
+	if aw.err != nil {
+		return aw.err
+	}
+	switch aw.encoding {
+	case texttospeechpb.AudioEncoding_OGG_OPUS:
+		if aw.lastPage != nil {
+			aw.lastPage[5] |= oggEOS
+			setOggCRC(aw.lastPage)
+			_, aw.err = aw.w.Write(aw.lastPage)
+			aw.lastPage = nil
+		}
+	case texttospeechpb.AudioEncoding_LINEAR16, texttospeechpb.AudioEncoding_MULAW, texttospeechpb.AudioEncoding_ALAW:
+		if ws, ok := aw.w.(io.WriteSeeker); ok && aw.format != nil {
+			aw.err = aw.fixWAVSizes(ws)
+		}
+	}
+	if aw.err == nil {
+		aw.err = errors.New("ttsstream: AudioWriter is closed")
+		return nil
+	}
+	return aw.err
+}
+
+// parseWAV returns the fmt chunk and the samples of a WAV file.
+func parseWAV(b []byte) (format, data []byte, err error) {
+	if len(b) < 12 || string(b[:4]) != "RIFF" || string(b[8:12]) != "WAVE" {
+		return nil, nil, errors.New("ttsstream: audio content is not a WAV file")
+	}
+	b = b[12:]
+	for len(b) >= 8 {
+		id, size := string(b[:4]), int(binary.LittleEndian.Uint32(b[4:8]))
+		body := b[8:]
+		if id == "data" {
+			// The size of the data chunk may not be set by streaming
+			// encoders, so the rest of the file is used.
+			if size < len(body) {
+				body = body[:size]
+			}
+			if format == nil {
+				return nil, nil, errors.New("ttsstream: WAV file has no fmt chunk")
+			}
+			return format, body, nil
+		}
+		if size > len(body) {
+			break
+		}
+		if id == "fmt " {
+			format = b[:8+size]
+		}
+		// Chunks are padded to an even size.
+		b = body[size+size%2:]
+	}
+	return nil, nil, errors.New("ttsstream: WAV file has no data chunk")
+}
+
+func (aw *AudioWriter) addWAV(content []byte) error {
+	format, data, err := parseWAV(content)
+	if err != nil {
+		return err
+	}
+	if aw.format == nil {
+		aw.format = append([]byte(nil), format...)
+		var h bytes.Buffer
+		h.WriteString("RIFF")
+		binary.Write(&h, binary.LittleEndian, uint32(0xFFFFFFFF))
+		h.WriteString("WAVE")
+		h.Write(aw.format)
+		h.WriteString("data")
+		binary.Write(&h, binary.LittleEndian, uint32(0xFFFFFFFF))
+		if _, err := aw.w.Write(h.Bytes()); err != nil {
+			return err
+		}
+	} else if !bytes.Equal(format, aw.format) {
+		return errors.New("ttsstream: audio contents have different formats")
+	}
+	aw.dataSize += int64(len(data))
+	_, err = aw.w.Write(data)
+	return err
+}
+
+func (aw *AudioWriter) fixWAVSizes(ws io.WriteSeeker) error {
+	headerSize := int64(12 + len(aw.format) + 8)
+	end, err := ws.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	start := end - aw.dataSize - headerSize
+	if start < 0 || 4+int64(len(aw.format))+8+aw.dataSize > 0xFFFFFFFF {
+		// The data was not written at the current position, or is too
+		// large for the sizes of the header.
+		return nil
+	}
+	put := func(off int64, v int64) error {
+		if _, err := ws.Seek(start+off, io.SeekStart); err != nil {
+			return err
+		}
+		return binary.Write(ws, binary.LittleEndian, uint32(v))
+	}
+	if err := put(4, headerSize-8+aw.dataSize); err != nil {
+		return err
+	}
+	if err := put(headerSize-4, aw.dataSize); err != nil {
+		return err
+	}
+	_, err = ws.Seek(end, io.SeekStart)
+	return err
+}
+
+// Flags of the header type of an Ogg page.
+const (
+	oggBOS = 0x02
+	oggEOS = 0x04
+)
+
+// addOgg appends the pages of an Ogg Opus stream to the output stream. The
+// header pages of the streams after the first are dropped, and the serial
+// number, sequence number and granule position of their pages are rewritten.
+func (aw *AudioWriter) addOgg(content []byte) error {
+	first := aw.n == 0
+	inHeaders := true
+	var granule int64
+	for len(content) > 0 {
+		page, rest, err := nextOggPage(content)
+		if err != nil {
+			return err
+		}
+		content = rest
+		g := int64(binary.LittleEndian.Uint64(page[6:14]))
+		// The header pages, OpusHead and OpusTags, have a granule position of
+		// zero.
+		if g == 0 && inHeaders && !first {
+			continue
+		}
+		inHeaders = false
+		page = append([]byte(nil), page...)
+		if first && aw.seq == 0 {
+			aw.serial = binary.LittleEndian.Uint32(page[14:18])
+		}
+		if g != -1 {
+			granule = g
+			binary.LittleEndian.PutUint64(page[6:14], uint64(aw.granule+g))
+		}
+		page[5] &^= oggEOS
+		binary.LittleEndian.PutUint32(page[14:18], aw.serial)
+		binary.LittleEndian.PutUint32(page[18:22], aw.seq)
+		aw.seq++
+		setOggCRC(page)
+		if aw.lastPage != nil {
+			if _, err := aw.w.Write(aw.lastPage); err != nil {
+				return err
+			}
+		}
+		aw.lastPage = page
+	}
+	aw.granule += granule
+	return nil
+}
+
+// nextOggPage splits the first Ogg page from b.
+func nextOggPage(b []byte) (page, rest []byte, err error) {
+	if len(b) < 27 || string(b[:4]) != "OggS" {
+		return nil, nil, errors.New("ttsstream: audio content is not an Ogg stream")
+	}
+	nsegs := int(b[26])
+	if len(b) < 27+nsegs {
+		return nil, nil, errors.New("ttsstream: truncated Ogg page")
+	}
+	size := 27 + nsegs
+	for _, s := range b[27 : 27+nsegs] {
+		size += int(s)
+	}
+	if len(b) < size {
+		return nil, nil, errors.New("ttsstream: truncated Ogg page")
+	}
+	return b[:size], b[size:], nil
+}
+
+var oggCRCTable = func() *[256]uint32 {
+	var t [256]uint32
+	for i := range t {
+		r := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		t[i] = r
+	}
+	return &t
+}()
+
+// setOggCRC sets the checksum of an Ogg page, which is a CRC-32 with the
+// polynomial 0x04c11db7, computed with the checksum field set to zero.
+func setOggCRC(page []byte) {
+	binary.LittleEndian.PutUint32(page[22:26], 0)
+	var crc uint32
+	for _, b := range page {
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^b]
+	}
+	binary.LittleEndian.PutUint32(page[22:26], crc)
+}
@@ -0,0 +1,552 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	translateapi "cloud.google.com/go/translate/apiv3"
+	"cloud.google.com/go/translate/apiv3/translatepb"
+	"golang.org/x/text/language"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AdvancedClient is a client for the Advanced (v3) edition of the Translation
+// API. It translates documents, manages glossaries and runs batch translation
+// jobs. For other features, use the generated client in
+// cloud.google.com/go/translate/apiv3.
+type AdvancedClient struct {
+	c      *translateapi.TranslationClient
+	parent string
+}
+
+// NewAdvancedClient constructs a new AdvancedClient for the given project and
+// location. Glossaries, batch translation and custom models need a regional
+// location, such as "us-central1"; other features also work with "global".
+func NewAdvancedClient(ctx context.Context, projectID, location string, opts ...option.ClientOption) (*AdvancedClient, error) {
+	if projectID == "" || location == "" {
+		return nil, errors.New("translate: NewAdvancedClient needs a project ID and a location")
+	}
+	c, err := translateapi.NewTranslationClient(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &AdvancedClient{
+		c:      c,
+		parent: fmt.Sprintf("projects/%s/locations/%s", projectID, location),
+	}, nil
+}
+
+// Close closes any resources held by the client.
+// Close should be called when the client is no longer needed.
+// It need not be called at program exit.
+func (c *AdvancedClient) Close() error { return c.c.Close() }
+
+// glossaryName returns the resource name of the glossary with the given ID,
+// or id itself if it is already a resource name.
+func (c *AdvancedClient) glossaryName(id string) string {
+	if strings.HasPrefix(id, "projects/") {
+		return id
+	}
+	return c.parent + "/glossaries/" + id
+}
+
+func (c *AdvancedClient) glossaryConfig(id string) *translatepb.TranslateTextGlossaryConfig {
+	if id == "" {
+		return nil
+	}
+	return &translatepb.TranslateTextGlossaryConfig{Glossary: c.glossaryName(id)}
+}
+
+// A Document is the input to AdvancedClient.TranslateDocument. Exactly one of
+// Content and GCSURI must be set.
+type Document struct {
+	// Content is the content of the document.
+	Content []byte
+
+	// GCSURI is the location of the document in Cloud Storage, of the form
+	// "gs://bucket/object".
+	GCSURI string
+
+	// MIMEType is the type of the document, such as "application/pdf" or
+	// "application/vnd.openxmlformats-officedocument.wordprocessingml.document".
+	// It is required for Content. For a document in Cloud Storage, it is
+	// inferred from the extension of the object name if empty.
+	MIMEType string
+}
+
+// DocumentOptions contains options for AdvancedClient.TranslateDocument.
+type DocumentOptions struct {
+	// Source is the language of the document. If empty, the service detects
+	// it.
+	Source language.Tag
+
+	// Glossary is the ID or resource name of a glossary to use. When set,
+	// the translation is returned both with and without the glossary.
+	Glossary string
+
+	// Model is the resource name of the model to use. If empty, the general
+	// model is used.
+	Model string
+
+	// OutputURIPrefix, if set, is a Cloud Storage location of the form
+	// "gs://bucket/prefix/" where the translated document is written
+	// instead of being returned.
+	OutputURIPrefix string
+
+	// NativePDFOnly restricts translation of PDF files to those that contain
+	// text, rather than scanned images.
+	NativePDFOnly bool
+}
+
+// DocumentTranslation is the result of translating a document.
+type DocumentTranslation struct {
+	// Content is the translated document. It is empty if the document was
+	// written to Cloud Storage.
+	Content []byte
+
+	// GlossaryContent is the document translated with the glossary, if one
+	// was requested.
+	GlossaryContent []byte
+
+	// MIMEType is the type of the translated document.
+	MIMEType string
+
+	// DetectedSource is the language detected for the document, if no source
+	// language was given.
+	DetectedSource language.Tag
+
+	// Model is the model used for translation.
+	Model string
+}
+
+// TranslateDocument translates a document, such as a PDF or DOCX file, into the
+// target language, preserving its formatting.
+func (c *AdvancedClient) TranslateDocument(ctx context.Context, doc *Document, target language.Tag, opts *DocumentOptions) (*DocumentTranslation, error) {
+	if doc == nil || (len(doc.Content) == 0) == (doc.GCSURI == "") {
+		return nil, errors.New("translate: document needs exactly one of Content and GCSURI")
+	}
+	if opts == nil {
+		opts = &DocumentOptions{}
+	}
+	in := &translatepb.DocumentInputConfig{MimeType: doc.MIMEType}
+	if doc.GCSURI != "" {
+		in.Source = &translatepb.DocumentInputConfig_GcsSource{
+			GcsSource: &translatepb.GcsSource{InputUri: doc.GCSURI},
+		}
+	} else {
+		if doc.MIMEType == "" {
+			return nil, errors.New("translate: document content needs a MIMEType")
+		}
+		in.Source = &translatepb.DocumentInputConfig_Content{Content: doc.Content}
+	}
+	req := &translatepb.TranslateDocumentRequest{
+		Parent:                   c.parent,
+		TargetLanguageCode:       target.String(),
+		DocumentInputConfig:      in,
+		Model:                    opts.Model,
+		GlossaryConfig:           c.glossaryConfig(opts.Glossary),
+		IsTranslateNativePdfOnly: opts.NativePDFOnly,
+	}
+	if opts.Source != language.Und {
+		req.SourceLanguageCode = opts.Source.String()
+	}
+	if opts.OutputURIPrefix != "" {
+		req.DocumentOutputConfig = &translatepb.DocumentOutputConfig{
+			Destination: &translatepb.DocumentOutputConfig_GcsDestination{
+				GcsDestination: &translatepb.GcsDestination{OutputUriPrefix: opts.OutputURIPrefix},
+			},
+		}
+	}
+	res, err := c.c.TranslateDocument(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	dt := &DocumentTranslation{Model: res.Model}
+	if t := res.DocumentTranslation; t != nil {
+		// The service returns a single translated document.
+		if len(t.ByteStreamOutputs) > 0 {
+			dt.Content = t.ByteStreamOutputs[0]
+		}
+		dt.MIMEType = t.MimeType
+		if t.DetectedLanguageCode != "" {
+			dt.DetectedSource, err = language.Parse(t.DetectedLanguageCode)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if t := res.GlossaryDocumentTranslation; t != nil && len(t.ByteStreamOutputs) > 0 {
+		dt.GlossaryContent = t.ByteStreamOutputs[0]
+	}
+	return dt, nil
+}
+
+// A Glossary holds custom translations of terms. A glossary is either
+// unidirectional, translating from Source to Target, or an equivalent term set
+// for Languages.
+type Glossary struct {
+	// Name is the resource name of the glossary. It is set by the service.
+	Name string
+
+	// DisplayName is the name of the glossary for display.
+	DisplayName string
+
+	// Source and Target are the languages of a unidirectional glossary.
+	Source, Target language.Tag
+
+	// Languages are the languages of an equivalent term set glossary.
+	Languages []language.Tag
+
+	// InputURI is the location in Cloud Storage of the CSV, TSV or TMX file
+	// with the terms of the glossary, of the form "gs://bucket/object".
+	InputURI string
+
+	// EntryCount is the number of entries in the glossary. It is set by the
+	// service.
+	EntryCount int
+
+	// SubmitTime and EndTime are the times the creation of the glossary was
+	// requested and finished. They are set by the service.
+	SubmitTime, EndTime time.Time
+}
+
+func (g *Glossary) toProto(name string) (*translatepb.Glossary, error) {
+	if g.InputURI == "" {
+		return nil, errors.New("translate: glossary needs an InputURI")
+	}
+	pg := &translatepb.Glossary{
+		Name:        name,
+		DisplayName: g.DisplayName,
+		InputConfig: &translatepb.GlossaryInputConfig{
+			Source: &translatepb.GlossaryInputConfig_GcsSource{
+				GcsSource: &translatepb.GcsSource{InputUri: g.InputURI},
+			},
+		},
+	}
+	switch {
+	case g.Source != language.Und && g.Target != language.Und && len(g.Languages) == 0:
+		pg.Languages = &translatepb.Glossary_LanguagePair{
+			LanguagePair: &translatepb.Glossary_LanguageCodePair{
+				SourceLanguageCode: g.Source.String(),
+				TargetLanguageCode: g.Target.String(),
+			},
+		}
+	case g.Source == language.Und && g.Target == language.Und && len(g.Languages) > 1:
+		set := &translatepb.Glossary_LanguageCodesSet{}
+		for _, l := range g.Languages {
+			set.LanguageCodes = append(set.LanguageCodes, l.String())
+		}
+		pg.Languages = &translatepb.Glossary_LanguageCodesSet_{LanguageCodesSet: set}
+	default:
+		return nil, errors.New("translate: glossary needs either Source and Target, or at least two Languages")
+	}
+	return pg, nil
+}
+
+func glossaryFromProto(pg *translatepb.Glossary) (*Glossary, error) {
+	g := &Glossary{
+		Name:        pg.Name,
+		DisplayName: pg.DisplayName,
+		InputURI:    pg.GetInputConfig().GetGcsSource().GetInputUri(),
+		EntryCount:  int(pg.EntryCount),
+		SubmitTime:  timeFromProto(pg.SubmitTime),
+		EndTime:     timeFromProto(pg.EndTime),
+	}
+	var err error
+	if p := pg.GetLanguagePair(); p != nil {
+		if g.Source, err = language.Parse(p.SourceLanguageCode); err != nil {
+			return nil, err
+		}
+		if g.Target, err = language.Parse(p.TargetLanguageCode); err != nil {
+			return nil, err
+		}
+	}
+	for _, code := range pg.GetLanguageCodesSet().GetLanguageCodes() {
+		tag, err := language.Parse(code)
+		if err != nil {
+			return nil, err
+		}
+		g.Languages = append(g.Languages, tag)
+	}
+	return g, nil
+}
+
+func timeFromProto(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// CreateGlossary creates a glossary with the given ID from the terms in
+// g.InputURI, and waits for the service to finish processing it. The Name,
+// EntryCount, SubmitTime and EndTime fields of g are ignored.
+func (c *AdvancedClient) CreateGlossary(ctx context.Context, id string, g *Glossary) (*Glossary, error) {
+	pg, err := g.toProto(c.glossaryName(id))
+	if err != nil {
+		return nil, err
+	}
+	op, err := c.c.CreateGlossary(ctx, &translatepb.CreateGlossaryRequest{
+		Parent:   c.parent,
+		Glossary: pg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	res, err := op.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return glossaryFromProto(res)
+}
+
+// Glossary returns the glossary with the given ID or resource name.
+func (c *AdvancedClient) Glossary(ctx context.Context, id string) (*Glossary, error) {
+	pg, err := c.c.GetGlossary(ctx, &translatepb.GetGlossaryRequest{Name: c.glossaryName(id)})
+	if err != nil {
+		return nil, err
+	}
+	return glossaryFromProto(pg)
+}
+
+// DeleteGlossary deletes the glossary with the given ID or resource name, and
+// waits for the deletion to finish.
+func (c *AdvancedClient) DeleteGlossary(ctx context.Context, id string) error {
+	op, err := c.c.DeleteGlossary(ctx, &translatepb.DeleteGlossaryRequest{Name: c.glossaryName(id)})
+	if err != nil {
+		return err
+	}
+	_, err = op.Wait(ctx)
+	return err
+}
+
+// Glossaries returns an iterator over the glossaries of the client's project
+// and location.
+func (c *AdvancedClient) Glossaries(ctx context.Context) *GlossaryIterator {
+	return &GlossaryIterator{it: c.c.ListGlossaries(ctx, &translatepb.ListGlossariesRequest{Parent: c.parent})}
+}
+
+// A GlossaryIterator is an iterator over Glossaries.
+type GlossaryIterator struct {
+	it *translateapi.GlossaryIterator
+}
+
+// Next returns the next glossary. Its second return value is iterator.Done if
+// there are no more results. Once Next returns Done, all subsequent calls will
+// return Done.
+func (it *GlossaryIterator) Next() (*Glossary, error) {
+	pg, err := it.it.Next()
+	if err != nil {
+		return nil, err
+	}
+	return glossaryFromProto(pg)
+}
+
+// PageInfo supports pagination. See the google.golang.org/api/iterator package for details.
+func (it *GlossaryIterator) PageInfo() *iterator.PageInfo { return it.it.PageInfo() }
+
+// A BatchJob describes a batch translation of files in Cloud Storage.
+type BatchJob struct {
+	// InputURIs are the files to translate, of the form "gs://bucket/object".
+	// A URI ending in "/" or "*" is a prefix that names all the files under
+	// it.
+	InputURIs []string
+
+	// OutputURIPrefix is the Cloud Storage location where the translated
+	// files are written, of the form "gs://bucket/prefix/". It must be empty.
+	OutputURIPrefix string
+
+	// Source is the language of the input files.
+	Source language.Tag
+
+	// Targets are the languages to translate into.
+	Targets []language.Tag
+
+	// Glossary is the ID or resource name of a glossary to use for every
+	// target language.
+	Glossary string
+
+	// Model is the resource name of the model to use for every target
+	// language. If empty, the general model is used.
+	Model string
+
+	// MIMEType is the type of the input files for BatchTranslateText,
+	// "text/plain" or "text/html". If empty, it is inferred from the file
+	// extension. It is ignored by BatchTranslateDocuments.
+	MIMEType string
+}
+
+func (c *AdvancedClient) batchParams(job *BatchJob) (source string, targets []string, models map[string]string, glossaries map[string]*translatepb.TranslateTextGlossaryConfig, err error) {
+	if len(job.InputURIs) == 0 || job.OutputURIPrefix == "" {
+		return "", nil, nil, nil, errors.New("translate: batch job needs InputURIs and an OutputURIPrefix")
+	}
+	if job.Source == language.Und || len(job.Targets) == 0 {
+		return "", nil, nil, nil, errors.New("translate: batch job needs a Source and Targets")
+	}
+	for _, t := range job.Targets {
+		code := t.String()
+		targets = append(targets, code)
+		if job.Model != "" {
+			if models == nil {
+				models = map[string]string{}
+			}
+			models[code] = job.Model
+		}
+		if job.Glossary != "" {
+			if glossaries == nil {
+				glossaries = map[string]*translatepb.TranslateTextGlossaryConfig{}
+			}
+			glossaries[code] = c.glossaryConfig(job.Glossary)
+		}
+	}
+	return job.Source.String(), targets, models, glossaries, nil
+}
+
+// BatchTranslateText starts translating the text or HTML files of job. Use the
+// returned BatchOperation to wait for the translation to finish.
+func (c *AdvancedClient) BatchTranslateText(ctx context.Context, job *BatchJob) (*BatchOperation, error) {
+	source, targets, models, glossaries, err := c.batchParams(job)
+	if err != nil {
+		return nil, err
+	}
+	req := &translatepb.BatchTranslateTextRequest{
+		Parent:              c.parent,
+		SourceLanguageCode:  source,
+		TargetLanguageCodes: targets,
+		Models:              models,
+		Glossaries:          glossaries,
+		OutputConfig: &translatepb.OutputConfig{
+			Destination: &translatepb.OutputConfig_GcsDestination{
+				GcsDestination: &translatepb.GcsDestination{OutputUriPrefix: job.OutputURIPrefix},
+			},
+		},
+	}
+	for _, uri := range job.InputURIs {
+		req.InputConfigs = append(req.InputConfigs, &translatepb.InputConfig{
+			MimeType: job.MIMEType,
+			Source: &translatepb.InputConfig_GcsSource{
+				GcsSource: &translatepb.GcsSource{InputUri: uri},
+			},
+		})
+	}
+	op, err := c.c.BatchTranslateText(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &BatchOperation{
+		name: op.Name(),
+		done: op.Done,
+		wait: func(ctx context.Context) (*BatchResult, error) {
+			res, err := op.Wait(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return &BatchResult{
+				TotalCharacters:      res.TotalCharacters,
+				TranslatedCharacters: res.TranslatedCharacters,
+				FailedCharacters:     res.FailedCharacters,
+				SubmitTime:           timeFromProto(res.SubmitTime),
+				EndTime:              timeFromProto(res.EndTime),
+			}, nil
+		},
+	}, nil
+}
+
+// BatchTranslateDocuments starts translating the documents of job, such as
+// PDF or DOCX files. Use the returned BatchOperation to wait for the
+// translation to finish.
+func (c *AdvancedClient) BatchTranslateDocuments(ctx context.Context, job *BatchJob) (*BatchOperation, error) {
+	source, targets, models, glossaries, err := c.batchParams(job)
+	if err != nil {
+		return nil, err
+	}
+	req := &translatepb.BatchTranslateDocumentRequest{
+		Parent:              c.parent,
+		SourceLanguageCode:  source,
+		TargetLanguageCodes: targets,
+		Models:              models,
+		Glossaries:          glossaries,
+		OutputConfig: &translatepb.BatchDocumentOutputConfig{
+			Destination: &translatepb.BatchDocumentOutputConfig_GcsDestination{
+				GcsDestination: &translatepb.GcsDestination{OutputUriPrefix: job.OutputURIPrefix},
+			},
+		},
+	}
+	for _, uri := range job.InputURIs {
+		req.InputConfigs = append(req.InputConfigs, &translatepb.BatchDocumentInputConfig{
+			Source: &translatepb.BatchDocumentInputConfig_GcsSource{
+				GcsSource: &translatepb.GcsSource{InputUri: uri},
+			},
+		})
+	}
+	op, err := c.c.BatchTranslateDocument(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &BatchOperation{
+		name: op.Name(),
+		done: op.Done,
+		wait: func(ctx context.Context) (*BatchResult, error) {
+			res, err := op.Wait(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return &BatchResult{
+				TotalCharacters:      res.TotalCharacters,
+				TranslatedCharacters: res.TranslatedCharacters,
+				FailedCharacters:     res.FailedCharacters,
+				TotalPages:           res.TotalPages,
+				TranslatedPages:      res.TranslatedPages,
+				FailedPages:          res.FailedPages,
+				SubmitTime:           timeFromProto(res.SubmitTime),
+				EndTime:              timeFromProto(res.EndTime),
+			}, nil
+		},
+	}, nil
+}
+
+// A BatchOperation is a batch translation in progress.
+type BatchOperation struct {
+	name string
+	done func() bool
+	wait func(context.Context) (*BatchResult, error)
+}
+
+// Name returns the name of the long-running operation. It can be used to
+// resume the operation with the generated client.
+func (op *BatchOperation) Name() string { return op.name }
+
+// Done reports whether the translation is known to have finished. It does not
+// poll the service.
+func (op *BatchOperation) Done() bool { return op.done() }
+
+// Wait blocks until the translation finishes or ctx is done, and returns its
+// result.
+func (op *BatchOperation) Wait(ctx context.Context) (*BatchResult, error) { return op.wait(ctx) }
+
+// BatchResult is the result of a batch translation. The page counts are only
+// set for documents.
+type BatchResult struct {
+	TotalCharacters, TranslatedCharacters, FailedCharacters int64
+	TotalPages, TranslatedPages, FailedPages                int64
+	SubmitTime, EndTime                                     time.Time
+}
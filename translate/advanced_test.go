@@ -0,0 +1,255 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translate
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/internal/testutil"
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"cloud.google.com/go/translate/apiv3/translatepb"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/text/language"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+var tagComparer = cmp.Comparer(func(a, b language.Tag) bool { return a == b })
+
+type fakeTranslationServer struct {
+	translatepb.UnimplementedTranslationServiceServer
+	reqs       []proto.Message
+	glossaries []*translatepb.Glossary
+}
+
+func done(res proto.Message) (*longrunningpb.Operation, error) {
+	a, err := anypb.New(res)
+	if err != nil {
+		return nil, err
+	}
+	return &longrunningpb.Operation{
+		Name:   "operations/op",
+		Done:   true,
+		Result: &longrunningpb.Operation_Response{Response: a},
+	}, nil
+}
+
+func (s *fakeTranslationServer) TranslateDocument(_ context.Context, req *translatepb.TranslateDocumentRequest) (*translatepb.TranslateDocumentResponse, error) {
+	s.reqs = append(s.reqs, req)
+	res := &translatepb.TranslateDocumentResponse{
+		DocumentTranslation: &translatepb.DocumentTranslation{
+			ByteStreamOutputs:    [][]byte{[]byte("translated")},
+			MimeType:             "application/pdf",
+			DetectedLanguageCode: "fr",
+		},
+		Model: "general/nmt",
+	}
+	if req.GlossaryConfig != nil {
+		res.GlossaryDocumentTranslation = &translatepb.DocumentTranslation{ByteStreamOutputs: [][]byte{[]byte("glossary")}}
+	}
+	return res, nil
+}
+
+func (s *fakeTranslationServer) CreateGlossary(_ context.Context, req *translatepb.CreateGlossaryRequest) (*longrunningpb.Operation, error) {
+	s.reqs = append(s.reqs, req)
+	g := proto.Clone(req.Glossary).(*translatepb.Glossary)
+	g.EntryCount = 2
+	s.glossaries = append(s.glossaries, g)
+	return done(g)
+}
+
+func (s *fakeTranslationServer) ListGlossaries(_ context.Context, req *translatepb.ListGlossariesRequest) (*translatepb.ListGlossariesResponse, error) {
+	s.reqs = append(s.reqs, req)
+	return &translatepb.ListGlossariesResponse{Glossaries: s.glossaries}, nil
+}
+
+func (s *fakeTranslationServer) DeleteGlossary(_ context.Context, req *translatepb.DeleteGlossaryRequest) (*longrunningpb.Operation, error) {
+	s.reqs = append(s.reqs, req)
+	return done(&translatepb.DeleteGlossaryResponse{Name: req.Name})
+}
+
+func (s *fakeTranslationServer) BatchTranslateDocument(_ context.Context, req *translatepb.BatchTranslateDocumentRequest) (*longrunningpb.Operation, error) {
+	s.reqs = append(s.reqs, req)
+	return done(&translatepb.BatchTranslateDocumentResponse{TotalPages: 3, TranslatedPages: 2, FailedPages: 1})
+}
+
+func newFakeAdvancedClient(t *testing.T) (*AdvancedClient, *fakeTranslationServer) {
+	srv, err := testutil.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeTranslationServer{}
+	translatepb.RegisterTranslationServiceServer(srv.Gsrv, fake)
+	srv.Start()
+	t.Cleanup(srv.Close)
+	conn, err := grpc.Dial(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	c, err := NewAdvancedClient(context.Background(), "p", "us-central1", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, fake
+}
+
+func TestTranslateDocument(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newFakeAdvancedClient(t)
+
+	got, err := c.TranslateDocument(ctx, &Document{Content: []byte("doc"), MIMEType: "application/pdf"}, language.English,
+		&DocumentOptions{Glossary: "g"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &DocumentTranslation{
+		Content:         []byte("translated"),
+		GlossaryContent: []byte("glossary"),
+		MIMEType:        "application/pdf",
+		DetectedSource:  language.French,
+		Model:           "general/nmt",
+	}
+	if !testutil.Equal(got, want, tagComparer) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	wantReq := &translatepb.TranslateDocumentRequest{
+		Parent:             "projects/p/locations/us-central1",
+		TargetLanguageCode: "en",
+		DocumentInputConfig: &translatepb.DocumentInputConfig{
+			MimeType: "application/pdf",
+			Source:   &translatepb.DocumentInputConfig_Content{Content: []byte("doc")},
+		},
+		GlossaryConfig: &translatepb.TranslateTextGlossaryConfig{Glossary: "projects/p/locations/us-central1/glossaries/g"},
+	}
+	if !cmp.Equal(fake.reqs[0], wantReq, protocmp.Transform()) {
+		t.Errorf("got request %v, want %v", fake.reqs[0], wantReq)
+	}
+
+	for _, doc := range []*Document{
+		nil,
+		{},
+		{Content: []byte("x")},
+		{Content: []byte("x"), GCSURI: "gs://b/o", MIMEType: "text/plain"},
+	} {
+		if _, err := c.TranslateDocument(ctx, doc, language.English, nil); err == nil {
+			t.Errorf("%+v: got nil, want error", doc)
+		}
+	}
+}
+
+func TestGlossaries(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newFakeAdvancedClient(t)
+
+	g, err := c.CreateGlossary(ctx, "g", &Glossary{
+		Source:   language.English,
+		Target:   language.German,
+		InputURI: "gs://b/terms.csv",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Glossary{
+		Name:       "projects/p/locations/us-central1/glossaries/g",
+		Source:     language.English,
+		Target:     language.German,
+		InputURI:   "gs://b/terms.csv",
+		EntryCount: 2,
+	}
+	if !testutil.Equal(g, want, tagComparer) {
+		t.Errorf("got %+v, want %+v", g, want)
+	}
+	if _, err := c.CreateGlossary(ctx, "g2", &Glossary{
+		Languages: []language.Tag{language.English, language.German},
+		InputURI:  "gs://b/terms.csv",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range []*Glossary{
+		{Source: language.English, Target: language.German},
+		{Source: language.English, InputURI: "gs://b/t.csv"},
+		{Languages: []language.Tag{language.English}, InputURI: "gs://b/t.csv"},
+	} {
+		if _, err := c.CreateGlossary(ctx, "bad", bad); err == nil {
+			t.Errorf("%+v: got nil, want error", bad)
+		}
+	}
+
+	var names []string
+	it := c.Glossaries(ctx)
+	for {
+		g, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, g.Name)
+	}
+	if len(names) != 2 || len(fake.glossaries[1].GetLanguageCodesSet().GetLanguageCodes()) != 2 {
+		t.Errorf("got glossaries %v, %v", names, fake.glossaries)
+	}
+
+	if err := c.DeleteGlossary(ctx, "projects/p/locations/us-central1/glossaries/g"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fake.reqs[len(fake.reqs)-1].(*translatepb.DeleteGlossaryRequest).Name; got != want.Name {
+		t.Errorf("deleted %q, want %q", got, want.Name)
+	}
+}
+
+func TestBatchTranslateDocuments(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newFakeAdvancedClient(t)
+
+	if _, err := c.BatchTranslateDocuments(ctx, &BatchJob{InputURIs: []string{"gs://b/in/"}}); err == nil {
+		t.Error("got nil, want error for missing output and languages")
+	}
+	op, err := c.BatchTranslateDocuments(ctx, &BatchJob{
+		InputURIs:       []string{"gs://b/in/"},
+		OutputURIPrefix: "gs://b/out/",
+		Source:          language.English,
+		Targets:         []language.Tag{language.French, language.German},
+		Glossary:        "g",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !op.Done() || op.Name() != "operations/op" {
+		t.Errorf("got operation %q, done %t", op.Name(), op.Done())
+	}
+	res, err := op.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TotalPages != 3 || res.TranslatedPages != 2 || res.FailedPages != 1 {
+		t.Errorf("got %+v", res)
+	}
+	req := fake.reqs[0].(*translatepb.BatchTranslateDocumentRequest)
+	if got, want := req.TargetLanguageCodes, []string{"fr", "de"}; !testutil.Equal(got, want) {
+		t.Errorf("got targets %v, want %v", got, want)
+	}
+	if len(req.Glossaries) != 2 || req.Models != nil {
+		t.Errorf("got glossaries %v and models %v", req.Glossaries, req.Models)
+	}
+}
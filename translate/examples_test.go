@@ -79,3 +79,48 @@ func ExampleClient_SupportedLanguages() {
 	}
 	fmt.Println(langs)
 }
+
+func ExampleAdvancedClient_TranslateDocument() {
+	ctx := context.Background()
+	client, err := translate.NewAdvancedClient(ctx, "my-project", "global")
+	if err != nil {
+		// TODO: handle error.
+	}
+	defer client.Close()
+	res, err := client.TranslateDocument(ctx,
+		&translate.Document{GCSURI: "gs://my-bucket/report.pdf"}, language.German, nil)
+	if err != nil {
+		// TODO: handle error.
+	}
+	_ = res.Content // TODO: Use the translated PDF.
+}
+
+func ExampleAdvancedClient_BatchTranslateDocuments() {
+	ctx := context.Background()
+	client, err := translate.NewAdvancedClient(ctx, "my-project", "us-central1")
+	if err != nil {
+		// TODO: handle error.
+	}
+	defer client.Close()
+	if _, err := client.CreateGlossary(ctx, "product-names", &translate.Glossary{
+		Languages: []language.Tag{language.English, language.French, language.German},
+		InputURI:  "gs://my-bucket/glossary.csv",
+	}); err != nil {
+		// TODO: handle error.
+	}
+	op, err := client.BatchTranslateDocuments(ctx, &translate.BatchJob{
+		InputURIs:       []string{"gs://my-bucket/docs/"},
+		OutputURIPrefix: "gs://my-bucket/translated/",
+		Source:          language.English,
+		Targets:         []language.Tag{language.French, language.German},
+		Glossary:        "product-names",
+	})
+	if err != nil {
+		// TODO: handle error.
+	}
+	res, err := op.Wait(ctx)
+	if err != nil {
+		// TODO: handle error.
+	}
+	fmt.Printf("translated %d of %d pages\n", res.TranslatedPages, res.TotalPages)
+}
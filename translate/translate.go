@@ -17,6 +17,9 @@
 // PLEASE NOTE: We recommend using the new v3 client for new projects:
 // https://cloud.google.com/go/translate/apiv3.
 //
+// AdvancedClient uses the v3 API to translate documents, manage glossaries and
+// run batch translation jobs.
+//
 // See https://cloud.google.com/translation for details.
 package translate
 
@@ -0,0 +1,80 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package visionbatch_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	vision "cloud.google.com/go/vision/v2/apiv1"
+	"cloud.google.com/go/vision/v2/apiv1/visionpb"
+	"cloud.google.com/go/vision/v2/visionbatch"
+)
+
+func ExampleAnnotateImages() {
+	ctx := context.Background()
+	client, err := vision.NewImageAnnotatorClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	paths, err := filepath.Glob("photos/*.jpg")
+	if err != nil {
+		// TODO: Handle error.
+	}
+	results, err := visionbatch.AnnotateImages(ctx, client, paths, &visionbatch.Config{
+		Features: []*visionpb.Feature{{Type: visionpb.Feature_LABEL_DETECTION, MaxResults: 5}},
+	})
+	if err != nil {
+		// TODO: Handle error.
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Printf("%s: %v\n", r.Source, r.Err)
+			continue
+		}
+		for _, l := range r.Response.LabelAnnotations {
+			fmt.Printf("%s: %s\n", r.Source, l.Description)
+		}
+	}
+}
+
+func ExampleAnnotateFiles() {
+	ctx := context.Background()
+	client, err := vision.NewImageAnnotatorClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	results, err := visionbatch.AnnotateFiles(ctx, client, []string{"gs://my-bucket/invoice.pdf"}, &visionbatch.Config{
+		Features: []*visionpb.Feature{{Type: visionpb.Feature_DOCUMENT_TEXT_DETECTION}},
+		Pages:    []int32{1, 2, 3},
+	})
+	if err != nil {
+		// TODO: Handle error.
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			// TODO: Handle the failure of r.Source.
+			continue
+		}
+		for _, p := range r.Response.Responses {
+			fmt.Println(p.GetFullTextAnnotation().GetText())
+		}
+	}
+}
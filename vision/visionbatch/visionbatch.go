@@ -0,0 +1,379 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package visionbatch annotates many images or files with the client in
+// cloud.google.com/go/vision/v2/apiv1, sending batches of them concurrently.
+//
+// AnnotateImages groups the images into BatchAnnotateImages requests within
+// the limits of the service, sends a bounded number of them at a time, and
+// returns a result for each image, in order:
+//
+//	results, err := visionbatch.AnnotateImages(ctx, client, []string{"gs://bucket/a.jpg", "b.png"}, &visionbatch.Config{
+//		Features: []*visionpb.Feature{{Type: visionpb.Feature_LABEL_DETECTION}},
+//	})
+//	if err != nil {
+//		// TODO: Handle error.
+//	}
+//	for _, r := range results {
+//		if r.Err != nil {
+//			// TODO: Handle the failure of r.Source.
+//			continue
+//		}
+//		// TODO: Use r.Response.
+//	}
+//
+// The failure of an image doesn't stop the others: its error is in its
+// result. AnnotateFiles does the same for PDF, TIFF and GIF files with
+// BatchAnnotateFiles.
+package visionbatch // import "cloud.google.com/go/vision/v2/visionbatch"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	vision "cloud.google.com/go/vision/v2/apiv1"
+	"cloud.google.com/go/vision/v2/apiv1/visionpb"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// MaxImagesPerRequest is the maximum number of images in a
+	// BatchAnnotateImages request.
+	MaxImagesPerRequest = 16
+
+	// maxRequestContentBytes bounds the image content of a request, below
+	// the limit of the service on the size of a request, leaving room for
+	// the rest of the request and the base64 encoding of JSON requests.
+	maxRequestContentBytes = 7 << 20
+
+	defaultConcurrency = 8
+)
+
+// Config configures AnnotateImages and AnnotateFiles.
+type Config struct {
+	// Features are the features to detect. Required.
+	Features []*visionpb.Feature
+
+	// ImageContext is the context of each image. Optional.
+	ImageContext *visionpb.ImageContext
+
+	// Parent is the project and location that process the images, as in
+	// BatchAnnotateImagesRequest. Optional.
+	Parent string
+
+	// BatchSize is the maximum number of images in a BatchAnnotateImages
+	// request. The default and maximum is MaxImagesPerRequest. Fewer are
+	// sent when the content of local images would make a request too large.
+	// It is not used by AnnotateFiles, as the service takes one file per
+	// request.
+	BatchSize int
+
+	// Concurrency is the maximum number of requests in flight. The default
+	// is 8.
+	Concurrency int
+
+	// Pages are the pages of each file to annotate, as in
+	// AnnotateFileRequest. The default is the first and last 5 pages. It is
+	// only used by AnnotateFiles.
+	Pages []int32
+}
+
+// A Result is the annotation of one image.
+type Result struct {
+	// Source is the path or URI of the image.
+	Source string
+
+	// Response is the response for the image. It is nil if Err is set.
+	Response *visionpb.AnnotateImageResponse
+
+	// Err is the error annotating the image: the error reading it, the error
+	// of the request it was in, or the error of its response, as returned by
+	// status.ErrorProto.
+	Err error
+}
+
+// A FileResult is the annotation of one file.
+type FileResult struct {
+	// Source is the path or URI of the file.
+	Source string
+
+	// Response is the response for the file. It is nil if Err is set. The
+	// responses of its pages may have errors of their own.
+	Response *visionpb.AnnotateFileResponse
+
+	// Err is the error annotating the file.
+	Err error
+}
+
+// Failed returns the results that have an error.
+func Failed(results []*Result) []*Result {
+	var failed []*Result
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// annotator is implemented by *vision.ImageAnnotatorClient.
+type annotator interface {
+	BatchAnnotateImages(ctx context.Context, req *visionpb.BatchAnnotateImagesRequest, opts ...gax.CallOption) (*visionpb.BatchAnnotateImagesResponse, error)
+	BatchAnnotateFiles(ctx context.Context, req *visionpb.BatchAnnotateFilesRequest, opts ...gax.CallOption) (*visionpb.BatchAnnotateFilesResponse, error)
+}
+
+// AnnotateImages annotates images, each a local path or a URI. Local images
+// are read as they are sent. A gs:// URI names an object in Cloud Storage,
+// and an http:// or https:// URI is fetched by the service.
+//
+// It returns a result for each source, in order. It returns an error only if
+// cfg is invalid or ctx is done. In the latter case, the results of the
+// sources that were not annotated have the error of ctx.
+func AnnotateImages(ctx context.Context, client *vision.ImageAnnotatorClient, sources []string, cfg *Config) ([]*Result, error) {
+	return annotateImages(ctx, client, sources, cfg)
+}
+
+func annotateImages(ctx context.Context, client annotator, sources []string, cfg *Config) ([]*Result, error) {
+	if len(cfg.Features) == 0 {
+		return nil, errors.New("visionbatch: Config.Features is required")
+	}
+	batchSize := cfg.BatchSize
+	if batchSize == 0 {
+		batchSize = MaxImagesPerRequest
+	}
+	if batchSize < 0 || batchSize > MaxImagesPerRequest {
+		return nil, fmt.Errorf("visionbatch: BatchSize %d is not between 1 and %d", batchSize, MaxImagesPerRequest)
+	}
+	results := make([]*Result, len(sources))
+	for i, s := range sources {
+		results[i] = &Result{Source: s}
+	}
+	err := run(ctx, cfg.Concurrency, batches(results, batchSize), func(batch []*Result) {
+		annotateBatch(ctx, client, batch, cfg)
+	})
+	if err != nil {
+		for _, r := range results {
+			if r.Response == nil && r.Err == nil {
+				r.Err = err
+			}
+		}
+	}
+	return results, err
+}
+
+// batches groups results into batches of at most size images, and of at most
+// maxRequestContentBytes of local content. The error of a local image that
+// can't be read is set here.
+func batches(results []*Result, size int) [][]*Result {
+	var bs [][]*Result
+	var cur []*Result
+	var curBytes int64
+	for _, r := range results {
+		var n int64
+		if !isURI(r.Source) {
+			fi, err := os.Stat(r.Source)
+			if err != nil {
+				r.Err = err
+				continue
+			}
+			n = fi.Size()
+		}
+		if len(cur) == size || (len(cur) > 0 && curBytes+n > maxRequestContentBytes) {
+			bs = append(bs, cur)
+			cur, curBytes = nil, 0
+		}
+		cur = append(cur, r)
+		curBytes += n
+	}
+	if len(cur) > 0 {
+		bs = append(bs, cur)
+	}
+	return bs
+}
+
+func annotateBatch(ctx context.Context, client annotator, batch []*Result, cfg *Config) {
+	req := &visionpb.BatchAnnotateImagesRequest{Parent: cfg.Parent}
+	var sent []*Result
+	for _, r := range batch {
+		img, err := image(r.Source)
+		if err != nil {
+			r.Err = err
+			continue
+		}
+		req.Requests = append(req.Requests, &visionpb.AnnotateImageRequest{
+			Image:        img,
+			Features:     cfg.Features,
+			ImageContext: cfg.ImageContext,
+		})
+		sent = append(sent, r)
+	}
+	if len(sent) == 0 {
+		return
+	}
+	res, err := client.BatchAnnotateImages(ctx, req)
+	if err == nil && len(res.Responses) != len(sent) {
+		err = fmt.Errorf("visionbatch: got %d responses for %d images", len(res.Responses), len(sent))
+	}
+	for i, r := range sent {
+		switch {
+		case err != nil:
+			r.Err = err
+		case res.Responses[i].GetError().GetCode() != 0:
+			r.Err = status.ErrorProto(res.Responses[i].Error)
+		default:
+			r.Response = res.Responses[i]
+		}
+	}
+}
+
+// AnnotateFiles annotates PDF, TIFF and GIF files, each a local path or a
+// gs:// URI, with one BatchAnnotateFiles request per file. The type of a
+// file is given by its extension.
+//
+// It returns a result for each source, in order. It returns an error only if
+// cfg is invalid or ctx is done. In the latter case, the results of the
+// sources that were not annotated have the error of ctx.
+func AnnotateFiles(ctx context.Context, client *vision.ImageAnnotatorClient, sources []string, cfg *Config) ([]*FileResult, error) {
+	return annotateFiles(ctx, client, sources, cfg)
+}
+
+func annotateFiles(ctx context.Context, client annotator, sources []string, cfg *Config) ([]*FileResult, error) {
+	if len(cfg.Features) == 0 {
+		return nil, errors.New("visionbatch: Config.Features is required")
+	}
+	results := make([]*FileResult, len(sources))
+	batches := make([][]*FileResult, len(sources))
+	for i, s := range sources {
+		results[i] = &FileResult{Source: s}
+		batches[i] = results[i : i+1]
+	}
+	err := run(ctx, cfg.Concurrency, batches, func(batch []*FileResult) {
+		r := batch[0]
+		in, err := inputConfig(r.Source)
+		if err != nil {
+			r.Err = err
+			return
+		}
+		res, err := client.BatchAnnotateFiles(ctx, &visionpb.BatchAnnotateFilesRequest{
+			Parent: cfg.Parent,
+			Requests: []*visionpb.AnnotateFileRequest{{
+				InputConfig:  in,
+				Features:     cfg.Features,
+				ImageContext: cfg.ImageContext,
+				Pages:        cfg.Pages,
+			}},
+		})
+		switch {
+		case err != nil:
+			r.Err = err
+		case len(res.Responses) != 1:
+			r.Err = fmt.Errorf("visionbatch: got %d responses for 1 file", len(res.Responses))
+		case res.Responses[0].GetError().GetCode() != 0:
+			r.Err = status.ErrorProto(res.Responses[0].Error)
+		default:
+			r.Response = res.Responses[0]
+		}
+	})
+	if err != nil {
+		for _, r := range results {
+			if r.Response == nil && r.Err == nil {
+				r.Err = err
+			}
+		}
+	}
+	return results, err
+}
+
+// run calls f on each batch, from at most concurrency goroutines at a time,
+// and waits for them to return. It stops starting batches when ctx is done,
+// and returns ctx.Err().
+func run[T any](ctx context.Context, concurrency int, batches [][]T, f func([]T)) error {
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, b := range batches {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(b []T) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			f(b)
+		}(b)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+func isURI(source string) bool {
+	for _, p := range []string{"gs://", "http://", "https://"} {
+		if strings.HasPrefix(source, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// image returns the Image of a source, reading it if it is local.
+func image(source string) (*visionpb.Image, error) {
+	if isURI(source) {
+		return &visionpb.Image{Source: &visionpb.ImageSource{ImageUri: source}}, nil
+	}
+	b, err := os.ReadFile(source)
+	if err != nil {
+		return nil, err
+	}
+	return &visionpb.Image{Content: b}, nil
+}
+
+// inputConfig returns the InputConfig of a file, reading it if it is local.
+func inputConfig(source string) (*visionpb.InputConfig, error) {
+	var mimeType string
+	switch strings.ToLower(filepath.Ext(source)) {
+	case ".pdf":
+		mimeType = "application/pdf"
+	case ".tif", ".tiff":
+		mimeType = "image/tiff"
+	case ".gif":
+		mimeType = "image/gif"
+	default:
+		return nil, fmt.Errorf("visionbatch: %s is not a PDF, TIFF or GIF file", source)
+	}
+	if strings.HasPrefix(source, "gs://") {
+		return &visionpb.InputConfig{GcsSource: &visionpb.GcsSource{Uri: source}, MimeType: mimeType}, nil
+	}
+	if isURI(source) {
+		return nil, fmt.Errorf("visionbatch: %s is not a local path or a gs:// URI", source)
+	}
+	b, err := os.ReadFile(source)
+	if err != nil {
+		return nil, err
+	}
+	return &visionpb.InputConfig{Content: b, MimeType: mimeType}, nil
+}
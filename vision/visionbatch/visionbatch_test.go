@@ -0,0 +1,262 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package visionbatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/vision/v2/apiv1/visionpb"
+	gax "github.com/googleapis/gax-go/v2"
+	statuspb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeAnnotator labels each image with its URI or content. Images whose
+// source contains "bad" get an error response, and requests with an image
+// whose source contains "fail" fail.
+type fakeAnnotator struct {
+	mu       sync.Mutex
+	inFlight int
+	max      int
+	requests []int // the number of images in each request
+}
+
+func (f *fakeAnnotator) enter() func() {
+	f.mu.Lock()
+	f.inFlight++
+	if f.inFlight > f.max {
+		f.max = f.inFlight
+	}
+	f.mu.Unlock()
+	return func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}
+}
+
+func (f *fakeAnnotator) BatchAnnotateImages(ctx context.Context, req *visionpb.BatchAnnotateImagesRequest, _ ...gax.CallOption) (*visionpb.BatchAnnotateImagesResponse, error) {
+	defer f.enter()()
+	f.mu.Lock()
+	f.requests = append(f.requests, len(req.Requests))
+	f.mu.Unlock()
+	res := &visionpb.BatchAnnotateImagesResponse{}
+	for _, r := range req.Requests {
+		src := r.Image.GetSource().GetImageUri()
+		if src == "" {
+			src = string(r.Image.Content)
+		}
+		if strings.Contains(src, "fail") {
+			return nil, status.Error(codes.Unavailable, "request failed")
+		}
+		if strings.Contains(src, "bad") {
+			res.Responses = append(res.Responses, &visionpb.AnnotateImageResponse{
+				Error: &statuspb.Status{Code: int32(codes.InvalidArgument), Message: "bad image"},
+			})
+			continue
+		}
+		res.Responses = append(res.Responses, &visionpb.AnnotateImageResponse{
+			LabelAnnotations: []*visionpb.EntityAnnotation{{Description: src}},
+		})
+	}
+	return res, nil
+}
+
+func (f *fakeAnnotator) BatchAnnotateFiles(ctx context.Context, req *visionpb.BatchAnnotateFilesRequest, _ ...gax.CallOption) (*visionpb.BatchAnnotateFilesResponse, error) {
+	defer f.enter()()
+	res := &visionpb.BatchAnnotateFilesResponse{}
+	for _, r := range req.Requests {
+		in := r.InputConfig
+		src := in.GetGcsSource().GetUri()
+		if src == "" {
+			src = string(in.Content)
+		}
+		fr := &visionpb.AnnotateFileResponse{InputConfig: in, TotalPages: 1}
+		if strings.Contains(src, "bad") {
+			fr.Error = &statuspb.Status{Code: int32(codes.InvalidArgument), Message: "bad file"}
+		} else {
+			fr.Responses = []*visionpb.AnnotateImageResponse{{
+				FullTextAnnotation: &visionpb.TextAnnotation{Text: in.MimeType + " " + src},
+			}}
+		}
+		res.Responses = append(res.Responses, fr)
+	}
+	return res, nil
+}
+
+var labels = &Config{Features: []*visionpb.Feature{{Type: visionpb.Feature_LABEL_DETECTION}}}
+
+func TestAnnotateImages(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, "local.png")
+	if err := os.WriteFile(local, []byte("local content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var sources []string
+	for i := 0; i < 100; i++ {
+		sources = append(sources, fmt.Sprintf("gs://bucket/%d.jpg", i))
+	}
+	sources[10] = local
+	sources[20] = "gs://bucket/bad.jpg"
+	sources[30] = filepath.Join(dir, "missing.png")
+
+	f := &fakeAnnotator{}
+	cfg := *labels
+	cfg.Concurrency = 3
+	results, err := annotateImages(context.Background(), f, sources, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(sources) {
+		t.Fatalf("got %d results, want %d", len(results), len(sources))
+	}
+	for i, r := range results {
+		if r.Source != sources[i] {
+			t.Errorf("result %d: got source %q, want %q", i, r.Source, sources[i])
+		}
+		switch i {
+		case 20:
+			if status.Code(r.Err) != codes.InvalidArgument {
+				t.Errorf("result %d: got %v, want InvalidArgument", i, r.Err)
+			}
+		case 30:
+			if !errors.Is(r.Err, os.ErrNotExist) {
+				t.Errorf("result %d: got %v, want ErrNotExist", i, r.Err)
+			}
+		default:
+			want := sources[i]
+			if i == 10 {
+				want = "local content"
+			}
+			if r.Err != nil {
+				t.Errorf("result %d: %v", i, r.Err)
+			} else if got := r.Response.LabelAnnotations[0].Description; got != want {
+				t.Errorf("result %d: got label %q, want %q", i, got, want)
+			}
+		}
+	}
+	if got := len(Failed(results)); got != 2 {
+		t.Errorf("got %d failed results, want 2", got)
+	}
+	for _, n := range f.requests {
+		if n > MaxImagesPerRequest {
+			t.Errorf("got a request with %d images", n)
+		}
+	}
+	if f.max > 3 {
+		t.Errorf("got %d requests in flight, want at most 3", f.max)
+	}
+}
+
+func TestAnnotateImagesRequestError(t *testing.T) {
+	sources := []string{"gs://b/0", "gs://b/1", "gs://b/fail", "gs://b/3"}
+	cfg := *labels
+	cfg.BatchSize = 2
+	results, err := annotateImages(context.Background(), &fakeAnnotator{}, sources, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The error fails the images of its request only.
+	for i, r := range results {
+		if wantErr := i >= 2; (r.Err != nil) != wantErr {
+			t.Errorf("result %d: got error %v, want error: %t", i, r.Err, wantErr)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err = annotateImages(ctx, &fakeAnnotator{}, sources, &cfg)
+	if err != context.Canceled {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	for i, r := range results {
+		if r.Err != context.Canceled {
+			t.Errorf("result %d: got %v, want context.Canceled", i, r.Err)
+		}
+	}
+
+	for _, cfg := range []*Config{{}, {Features: labels.Features, BatchSize: MaxImagesPerRequest + 1}} {
+		if _, err := annotateImages(context.Background(), &fakeAnnotator{}, sources, cfg); err == nil {
+			t.Errorf("%+v: got nil, want error", cfg)
+		}
+	}
+}
+
+func TestBatches(t *testing.T) {
+	dir := t.TempDir()
+	big := filepath.Join(dir, "big.png")
+	if err := os.WriteFile(big, make([]byte, maxRequestContentBytes/2+1), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var results []*Result
+	for _, s := range []string{big, "gs://b/1", big, big, "gs://b/4", "gs://b/5"} {
+		results = append(results, &Result{Source: s})
+	}
+	var got [][]string
+	for _, b := range batches(results, 3) {
+		var sources []string
+		for _, r := range b {
+			sources = append(sources, filepath.Base(r.Source))
+		}
+		got = append(got, sources)
+	}
+	want := [][]string{{"big.png", "1"}, {"big.png"}, {"big.png", "4", "5"}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got batches %v, want %v", got, want)
+	}
+}
+
+func TestAnnotateFiles(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, "local.TIF")
+	if err := os.WriteFile(local, []byte("local content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sources := []string{"gs://bucket/a.pdf", local, "gs://bucket/bad.gif", "gs://bucket/a.jpg", "https://example.com/a.pdf"}
+	f := &fakeAnnotator{}
+	cfg := *labels
+	cfg.Concurrency = 2
+	results, err := annotateFiles(context.Background(), f, sources, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"application/pdf gs://bucket/a.pdf", "image/tiff local content"} {
+		r := results[i]
+		if r.Err != nil {
+			t.Errorf("result %d: %v", i, r.Err)
+		} else if got := r.Response.Responses[0].FullTextAnnotation.Text; got != want {
+			t.Errorf("result %d: got %q, want %q", i, got, want)
+		}
+	}
+	if status.Code(results[2].Err) != codes.InvalidArgument {
+		t.Errorf("got %v, want InvalidArgument", results[2].Err)
+	}
+	for _, r := range results[3:] {
+		if r.Err == nil {
+			t.Errorf("%s: got nil, want error", r.Source)
+		}
+	}
+	if f.max > 2 {
+		t.Errorf("got %d requests in flight, want at most 2", f.max)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package launch_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	executions "cloud.google.com/go/workflows/executions/apiv1"
+	"cloud.google.com/go/workflows/executions/apiv1/executionspb"
+	"cloud.google.com/go/workflows/executions/launch"
+)
+
+func ExampleRun() {
+	ctx := context.Background()
+	client, err := executions.NewClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	type Order struct {
+		ID    string `json:"id"`
+		Items int    `json:"items"`
+	}
+	type Receipt struct {
+		Total float64 `json:"total"`
+	}
+	receipt, err := launch.Run[Receipt](ctx, client, "projects/my-project/locations/us-central1/workflows/checkout",
+		Order{ID: "42", Items: 3}, &launch.Config{
+			CallLogLevel: executionspb.Execution_LOG_ERRORS_ONLY,
+		})
+	var ee *launch.ExecutionError
+	if errors.As(err, &ee) {
+		var raised struct {
+			Message string `json:"message"`
+		}
+		if err := ee.Decode(&raised); err == nil {
+			fmt.Println("checkout failed:", raised.Message)
+		}
+		return
+	}
+	if err != nil {
+		// TODO: Handle error.
+	}
+	fmt.Println(receipt.Total)
+}
+
+func ExampleWait() {
+	ctx := context.Background()
+	client, err := executions.NewClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer client.Close()
+
+	exec, err := launch.Start(ctx, client, "projects/my-project/locations/us-central1/workflows/report", nil, nil)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	// TODO: Store exec.Name, and wait for the execution later.
+	exec, err = launch.Wait(ctx, client, exec.Name, &launch.Config{
+		OnPoll: func(e *executionspb.Execution) {
+			fmt.Println(e.State)
+		},
+	})
+	if err != nil {
+		// TODO: Handle error.
+	}
+	var result map[string]any
+	if err := launch.DecodeResult(exec, &result); err != nil {
+		// TODO: Handle error.
+	}
+}
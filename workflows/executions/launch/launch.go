@@ -0,0 +1,197 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package launch starts workflow executions with Go values as their
+// arguments, and waits for their results, using the client in
+// cloud.google.com/go/workflows/executions/apiv1.
+//
+// Arguments and results are converted to and from JSON with encoding/json:
+//
+//	type Order struct {
+//		ID    string `json:"id"`
+//		Items int    `json:"items"`
+//	}
+//	type Receipt struct {
+//		Total float64 `json:"total"`
+//	}
+//
+//	receipt, err := launch.Run[Receipt](ctx, client, "projects/my-project/locations/us-central1/workflows/checkout", Order{ID: "42", Items: 3}, nil)
+//	var ee *launch.ExecutionError
+//	if errors.As(err, &ee) {
+//		// TODO: Handle the failure of the workflow, whose error is in ee.
+//	}
+package launch // import "cloud.google.com/go/workflows/executions/launch"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	executions "cloud.google.com/go/workflows/executions/apiv1"
+	"cloud.google.com/go/workflows/executions/apiv1/executionspb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+// Config configures the executions started and waited for.
+type Config struct {
+	// CallLogLevel is the level of call logging of the execution.
+	CallLogLevel executionspb.Execution_CallLogLevel
+
+	// Labels are the labels of the execution.
+	Labels map[string]string
+
+	// Backoff is the backoff between two checks of the state of the
+	// execution. The default starts at one second, and doubles up to 30
+	// seconds.
+	Backoff *gax.Backoff
+
+	// OnPoll, if set, is called with the execution each time its state is
+	// checked. The execution has the fields of the BASIC view.
+	OnPoll func(*executionspb.Execution)
+}
+
+// An ExecutionError is returned when an execution doesn't succeed.
+type ExecutionError struct {
+	// Execution is the finished execution.
+	Execution *executionspb.Execution
+}
+
+func (e *ExecutionError) Error() string {
+	x := e.Execution
+	msg := fmt.Sprintf("launch: execution %s %s", x.Name, x.State)
+	if p := x.GetError().GetPayload(); p != "" {
+		msg += ": " + p
+	} else if d := x.GetStateError().GetDetails(); d != "" {
+		msg += ": " + d
+	}
+	return msg
+}
+
+// Decode decodes the payload of the error of the execution, which is the
+// JSON encoding of the error raised by the workflow, into the value pointed
+// to by dst.
+func (e *ExecutionError) Decode(dst any) error {
+	p := e.Execution.GetError().GetPayload()
+	if p == "" {
+		return errors.New("launch: the execution has no error payload")
+	}
+	return json.Unmarshal([]byte(p), dst)
+}
+
+// executionsClient is implemented by *executions.Client.
+type executionsClient interface {
+	CreateExecution(ctx context.Context, req *executionspb.CreateExecutionRequest, opts ...gax.CallOption) (*executionspb.Execution, error)
+	GetExecution(ctx context.Context, req *executionspb.GetExecutionRequest, opts ...gax.CallOption) (*executionspb.Execution, error)
+}
+
+// Start starts an execution of a workflow, of the form
+// projects/{project}/locations/{location}/workflows/{workflow}, with the
+// JSON encoding of arg as its argument, or no argument if arg is nil. cfg
+// may be nil.
+func Start(ctx context.Context, client *executions.Client, workflow string, arg any, cfg *Config) (*executionspb.Execution, error) {
+	return start(ctx, client, workflow, arg, cfg)
+}
+
+func start(ctx context.Context, client executionsClient, workflow string, arg any, cfg *Config) (*executionspb.Execution, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	exec := &executionspb.Execution{CallLogLevel: cfg.CallLogLevel, Labels: cfg.Labels}
+	if arg != nil {
+		b, err := json.Marshal(arg)
+		if err != nil {
+			return nil, fmt.Errorf("launch: encoding argument: %w", err)
+		}
+		exec.Argument = string(b)
+	}
+	return client.CreateExecution(ctx, &executionspb.CreateExecutionRequest{Parent: workflow, Execution: exec})
+}
+
+// Wait waits for an execution to finish, and returns it. It returns an
+// *ExecutionError if the execution doesn't succeed. cfg may be nil.
+func Wait(ctx context.Context, client *executions.Client, execution string, cfg *Config) (*executionspb.Execution, error) {
+	return wait(ctx, client, execution, cfg)
+}
+
+func wait(ctx context.Context, client executionsClient, execution string, cfg *Config) (*executionspb.Execution, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	bo := gax.Backoff{Initial: time.Second, Max: 30 * time.Second, Multiplier: 2}
+	if cfg.Backoff != nil {
+		bo = *cfg.Backoff
+	}
+	for {
+		exec, err := client.GetExecution(ctx, &executionspb.GetExecutionRequest{Name: execution, View: executionspb.ExecutionView_BASIC})
+		if err != nil {
+			return nil, err
+		}
+		if cfg.OnPoll != nil {
+			cfg.OnPoll(exec)
+		}
+		if !active(exec.State) {
+			break
+		}
+		if err := gax.Sleep(ctx, bo.Pause()); err != nil {
+			return nil, err
+		}
+	}
+	exec, err := client.GetExecution(ctx, &executionspb.GetExecutionRequest{Name: execution, View: executionspb.ExecutionView_FULL})
+	if err != nil {
+		return nil, err
+	}
+	if exec.State != executionspb.Execution_SUCCEEDED {
+		return exec, &ExecutionError{Execution: exec}
+	}
+	return exec, nil
+}
+
+func active(s executionspb.Execution_State) bool {
+	return s == executionspb.Execution_ACTIVE || s == executionspb.Execution_QUEUED
+}
+
+// Run starts an execution of a workflow as Start does, waits for it to
+// finish, and decodes its result into a value of type R.
+func Run[R any](ctx context.Context, client *executions.Client, workflow string, arg any, cfg *Config) (R, error) {
+	return run[R](ctx, client, workflow, arg, cfg)
+}
+
+func run[R any](ctx context.Context, client executionsClient, workflow string, arg any, cfg *Config) (R, error) {
+	var r R
+	exec, err := start(ctx, client, workflow, arg, cfg)
+	if err != nil {
+		return r, err
+	}
+	exec, err = wait(ctx, client, exec.Name, cfg)
+	if err != nil {
+		return r, err
+	}
+	err = DecodeResult(exec, &r)
+	return r, err
+}
+
+// DecodeResult decodes the result of a succeeded execution, which is the
+// JSON encoding of the value returned by the workflow, into the value
+// pointed to by dst.
+func DecodeResult(exec *executionspb.Execution, dst any) error {
+	if exec.State != executionspb.Execution_SUCCEEDED {
+		return &ExecutionError{Execution: exec}
+	}
+	if err := json.Unmarshal([]byte(exec.Result), dst); err != nil {
+		return fmt.Errorf("launch: decoding result: %w", err)
+	}
+	return nil
+}
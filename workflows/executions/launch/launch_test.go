@@ -0,0 +1,156 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package launch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/workflows/executions/apiv1/executionspb"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/protobuf/proto"
+)
+
+type order struct {
+	ID    string `json:"id"`
+	Items int    `json:"items"`
+}
+
+type receipt struct {
+	Total float64 `json:"total"`
+}
+
+// fakeClient runs executions that are active for polls polls, and then
+// return the number of items of their argument times 10, or fail with
+// errPayload if it is set.
+type fakeClient struct {
+	polls      int
+	errPayload string
+
+	created *executionspb.Execution
+	views   []executionspb.ExecutionView
+}
+
+func (f *fakeClient) CreateExecution(ctx context.Context, req *executionspb.CreateExecutionRequest, _ ...gax.CallOption) (*executionspb.Execution, error) {
+	f.created = proto.Clone(req.Execution).(*executionspb.Execution)
+	f.created.Name = req.Parent + "/executions/e1"
+	f.created.State = executionspb.Execution_ACTIVE
+	return f.created, nil
+}
+
+func (f *fakeClient) GetExecution(ctx context.Context, req *executionspb.GetExecutionRequest, _ ...gax.CallOption) (*executionspb.Execution, error) {
+	if req.Name != f.created.Name {
+		return nil, fmt.Errorf("unknown execution %q", req.Name)
+	}
+	f.views = append(f.views, req.View)
+	exec := &executionspb.Execution{Name: req.Name, State: executionspb.Execution_ACTIVE}
+	if f.polls > 0 {
+		f.polls--
+		return exec, nil
+	}
+	exec.State = executionspb.Execution_SUCCEEDED
+	if f.errPayload != "" {
+		exec.State = executionspb.Execution_FAILED
+	}
+	if req.View == executionspb.ExecutionView_FULL {
+		if f.errPayload != "" {
+			exec.Error = &executionspb.Execution_Error{Payload: f.errPayload}
+		} else {
+			var o order
+			if err := json.Unmarshal([]byte(f.created.Argument), &o); err != nil {
+				return nil, err
+			}
+			exec.Result = fmt.Sprintf(`{"total": %d}`, 10*o.Items)
+		}
+	}
+	return exec, nil
+}
+
+var fast = &Config{Backoff: &gax.Backoff{Initial: time.Millisecond, Max: time.Millisecond}}
+
+func TestRun(t *testing.T) {
+	f := &fakeClient{polls: 2}
+	var states []executionspb.Execution_State
+	cfg := *fast
+	cfg.Labels = map[string]string{"team": "shop"}
+	cfg.OnPoll = func(e *executionspb.Execution) { states = append(states, e.State) }
+	r, err := run[receipt](context.Background(), f, "projects/p/locations/l/workflows/w", order{ID: "42", Items: 3}, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Total != 30 {
+		t.Errorf("got total %v, want 30", r.Total)
+	}
+	if got, want := f.created.Argument, `{"id":"42","items":3}`; got != want {
+		t.Errorf("got argument %s, want %s", got, want)
+	}
+	if f.created.Labels["team"] != "shop" {
+		t.Errorf("got labels %v", f.created.Labels)
+	}
+	if want := "[ACTIVE ACTIVE SUCCEEDED]"; fmt.Sprint(states) != want {
+		t.Errorf("got states %v, want %s", states, want)
+	}
+	if want := "[BASIC BASIC BASIC FULL]"; fmt.Sprint(f.views) != want {
+		t.Errorf("got views %v, want %s", f.views, want)
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	f := &fakeClient{errPayload: `{"message": "out of stock", "code": 409}`}
+	_, err := run[receipt](context.Background(), f, "projects/p/locations/l/workflows/w", order{}, fast)
+	var ee *ExecutionError
+	if !errors.As(err, &ee) {
+		t.Fatalf("got %v, want an ExecutionError", err)
+	}
+	var payload struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	}
+	if err := ee.Decode(&payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Message != "out of stock" || payload.Code != 409 {
+		t.Errorf("got payload %+v", payload)
+	}
+}
+
+func TestStartWithoutArgument(t *testing.T) {
+	f := &fakeClient{}
+	if _, err := start(context.Background(), f, "w", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if f.created.Argument != "" {
+		t.Errorf("got argument %q, want none", f.created.Argument)
+	}
+	if _, err := start(context.Background(), f, "w", make(chan int), nil); err == nil {
+		t.Error("got nil, want error for an argument that can't be encoded")
+	}
+}
+
+func TestWaitContext(t *testing.T) {
+	f := &fakeClient{polls: 1000}
+	if _, err := start(context.Background(), f, "w", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := wait(ctx, f, f.created.Name, fast); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want DeadlineExceeded", err)
+	}
+}